@@ -0,0 +1,181 @@
+package main
+
+import (
+	"fmt"
+	"kuake_sdk/sdk"
+	"os"
+	"path"
+	"strings"
+)
+
+// aria2Task 提交给 aria2 的单个文件
+type aria2Task struct {
+	Path   string `json:"path,omitempty"`   // 远程路径（--fid 时为空）
+	Fid    string `json:"fid"`              // 文件ID
+	Out    string `json:"out"`              // aria2 保存目录下的相对路径
+	GID    string `json:"gid,omitempty"`    // aria2 任务 gid，提交失败时为空
+	Status string `json:"status,omitempty"` // --aria2-wait 时任务的最终状态
+	Error  string `json:"error,omitempty"`  // 失败原因
+}
+
+// downloadViaAria2 不在本进程下载，而是把文件逐个通过 aria2.addUri 提交给 aria2（带上下载链接需要的请求头和输出文件名）
+// paths 为远端路径（目录需配合 --recursive，按 dir/<目录名>/... 保存）；fid 不为空时只提交该文件
+// dir 为 aria2 主机上的保存目录，为空时使用 aria2 的默认目录；flags.aria2Wait 时逐个轮询任务直到结束
+// Data 包含 aria2（RPC 地址）、dir、tasks（每个文件一条）、submitted（成功提交数）、failed（失败的任务）；有失败时结果为失败（退出码非 0）
+func downloadViaAria2(client *sdk.QuarkClient, paths []string, fid, dir string, flags downloadFlags) *CLIResult {
+	aria2 := sdk.NewAria2Client(flags.aria2URL, flags.aria2Secret)
+	tasks := make([]*aria2Task, 0, len(paths))
+	submit := func(task *aria2Task) {
+		tasks = append(tasks, task)
+		if task.Error == "" {
+			gid, info, err := client.AddToAria2(aria2, task.Fid, dir, task.Out)
+			if err != nil {
+				task.Error = err.Error()
+			} else {
+				task.GID = gid
+				if task.Out == "" {
+					task.Out = info.FileName
+				}
+			}
+		}
+		name := task.Path
+		if name == "" {
+			name = task.Fid
+		}
+		if task.Error != "" {
+			fmt.Fprintf(os.Stderr, "[%d] failed %s: %s\n", len(tasks), name, task.Error)
+			return
+		}
+		fmt.Fprintf(os.Stderr, "[%d] submitted %s -> gid %s\n", len(tasks), name, task.GID)
+	}
+
+	if fid != "" {
+		submit(&aria2Task{Fid: fid})
+	}
+	for _, remotePath := range paths {
+		collectAria2Tasks(client, remotePath, flags.recursive, submit)
+	}
+
+	if flags.aria2Wait {
+		for _, task := range tasks {
+			if task.GID == "" {
+				continue
+			}
+			status, err := aria2.Wait(task.GID, sdk.ARIA2_POLL_INTERVAL, aria2Progress(task))
+			if isStderrTTY() {
+				fmt.Fprintln(os.Stderr)
+			}
+			if status != nil {
+				task.Status = status.Status
+			}
+			if err != nil {
+				task.Error = err.Error()
+			}
+		}
+	}
+
+	failed := make([]*aria2Task, 0)
+	submitted := 0
+	for _, task := range tasks {
+		if task.Error != "" {
+			failed = append(failed, task)
+		}
+		if task.GID != "" {
+			submitted++
+		}
+	}
+	data := map[string]interface{}{
+		"aria2":     flags.aria2URL,
+		"dir":       dir,
+		"tasks":     tasks,
+		"submitted": submitted,
+		"failed":    failed,
+	}
+	if len(failed) > 0 {
+		return &CLIResult{
+			Success: false,
+			Code:    "DOWNLOAD_PARTIAL_FAILED",
+			Message: fmt.Sprintf("%d of %d files failed via aria2", len(failed), len(tasks)),
+			Data:    data,
+		}
+	}
+	message := fmt.Sprintf("%d files submitted to aria2", submitted)
+	if flags.aria2Wait {
+		message = fmt.Sprintf("%d files downloaded by aria2", submitted)
+	}
+	return &CLIResult{
+		Success: true,
+		Code:    "OK",
+		Message: message,
+		Data:    data,
+	}
+}
+
+// collectAria2Tasks 解析 remotePath 并对其中每个文件调用 submit；目录需 recursive，Out 为 <目录名>/<相对路径>
+func collectAria2Tasks(client *sdk.QuarkClient, remotePath string, recursive bool, submit func(*aria2Task)) {
+	fileInfo, err := client.GetFileInfo(remotePath)
+	if err != nil {
+		submit(&aria2Task{Path: remotePath, Error: fmt.Sprintf("failed to get file info: %v", err)})
+		return
+	}
+	if !fileInfo.Success {
+		submit(&aria2Task{Path: remotePath, Error: fileInfo.Message})
+		return
+	}
+	fid, _ := fileInfo.Data["fid"].(string)
+	if isDir, _ := fileInfo.Data["dir"].(bool); !isDir {
+		fileName, _ := fileInfo.Data["file_name"].(string)
+		submit(&aria2Task{Path: remotePath, Fid: fid, Out: fileName})
+		return
+	}
+	if !recursive {
+		submit(&aria2Task{Path: remotePath, Fid: fid, Error: "cannot download directory (use --recursive)"})
+		return
+	}
+
+	dirPath, _ := fileInfo.Data["path"].(string)
+	if dirPath == "" {
+		dirPath = remotePath
+	}
+	base := path.Base(dirPath)
+	if base == "/" {
+		base = ""
+	}
+	response, err := client.Walk(dirPath, 0, func(file sdk.QuarkFileInfo) error {
+		if file.IsDirectory {
+			return nil // aria2 会自动创建输出路径中的目录
+		}
+		out := path.Join(base, strings.TrimPrefix(file.Path, strings.TrimSuffix(dirPath, "/")+"/"))
+		if out == "." || path.IsAbs(out) || out == ".." || strings.HasPrefix(out, "../") {
+			submit(&aria2Task{Path: file.Path, Fid: file.Fid, Error: "unsafe local path"})
+			return nil
+		}
+		submit(&aria2Task{Path: file.Path, Fid: file.Fid, Out: out})
+		return nil
+	})
+	if err != nil {
+		submit(&aria2Task{Path: remotePath, Fid: fid, Error: err.Error()})
+	} else if !response.Success {
+		submit(&aria2Task{Path: remotePath, Fid: fid, Error: response.Message})
+	}
+}
+
+// aria2Progress 返回在 stderr 显示 aria2 任务进度的回调，stderr 不是终端时只在状态变化时输出一行
+func aria2Progress(task *aria2Task) func(*sdk.Aria2Status) {
+	lastStatus := ""
+	return func(s *sdk.Aria2Status) {
+		if isStderrTTY() {
+			percent := 0.0
+			if s.TotalLength > 0 {
+				percent = float64(s.CompletedLength) * 100 / float64(s.TotalLength)
+			}
+			fmt.Fprintf(os.Stderr, "\r%s [%s] %.1f%% (%s / %s) %s/s    ", task.Out, s.Status, percent,
+				formatSize(s.CompletedLength), formatSize(s.TotalLength), formatSize(s.DownloadSpeed))
+			return
+		}
+		if s.Status != lastStatus {
+			lastStatus = s.Status
+			fmt.Fprintf(os.Stderr, "%s [%s]\n", task.Out, s.Status)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"kuake_sdk/sdk"
+	"os"
+)
+
+// errCatTooLarge 实际内容超过 --max-size（文件信息中的大小不准确时由 limitWriter 兜底）
+var errCatTooLarge = errors.New("file content exceeds --max-size")
+
+// limitWriter 最多写入 limit 字节，超出时返回 errCatTooLarge；limit <= 0 表示不限制
+type limitWriter struct {
+	w       *os.File
+	limit   int64
+	written int64
+}
+
+func (lw *limitWriter) Write(p []byte) (int, error) {
+	if lw.limit > 0 && lw.written+int64(len(p)) > lw.limit {
+		return 0, errCatTooLarge
+	}
+	n, err := lw.w.Write(p)
+	lw.written += int64(n)
+	return n, err
+}
+
+// handleCat 处理 cat 命令：将远端文件内容写到 stdout
+// 用法: cat <path> [--max-size S] | cat --fid <fid> [--max-size S]
+// 成功时返回 nil（stdout 只有文件内容），失败时返回结果由调用方写到 stderr；终端下进度输出到 stderr
+func handleCat(client *sdk.QuarkClient, args []string) *CLIResult {
+	filePath := ""
+	fileFid := ""
+	maxSize := int64(sdk.DEFAULT_CAT_MAX_SIZE)
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--fid":
+			if i+1 >= len(args) || args[i+1] == "" {
+				return &CLIResult{
+					Success: false,
+					Code:    "INVALID_ARGS",
+					Message: "missing value for --fid",
+				}
+			}
+			fileFid = args[i+1]
+			i++
+		case "--max-size":
+			if i+1 >= len(args) {
+				return &CLIResult{
+					Success: false,
+					Code:    "INVALID_ARGS",
+					Message: "missing value for --max-size",
+				}
+			}
+			size, err := parseSizeArg(args[i+1])
+			if err != nil {
+				return &CLIResult{
+					Success: false,
+					Code:    "INVALID_ARGS",
+					Message: fmt.Sprintf("invalid --max-size value: %v", err),
+				}
+			}
+			maxSize = size
+			i++
+		default:
+			filePath = args[i]
+		}
+	}
+	if filePath == "" && fileFid == "" {
+		return &CLIResult{
+			Success: false,
+			Code:    "INVALID_ARGS",
+			Message: `Usage: cat <path> | cat --fid <fid> [--max-size S] (path must be quoted, e.g., cat "/notes/todo.txt")`,
+		}
+	}
+
+	var fileInfo *sdk.StandardResponse
+	var err error
+	if fileFid != "" {
+		fileInfo, err = client.GetFileInfoByFid(fileFid)
+	} else {
+		fileInfo, err = client.GetFileInfo(filePath)
+	}
+	if err != nil {
+		return &CLIResult{
+			Success: false,
+			Message: fmt.Sprintf("failed to get file info: %v", err),
+		}
+	}
+	if !fileInfo.Success {
+		return &CLIResult{
+			Success: false,
+			Code:    fileInfo.Code,
+			Message: fileInfo.Message,
+		}
+	}
+	if isDir, _ := fileInfo.Data["dir"].(bool); isDir {
+		return &CLIResult{
+			Success: false,
+			Code:    "INVALID_FILE_TYPE",
+			Message: "cannot cat directory",
+		}
+	}
+	fid, _ := fileInfo.Data["fid"].(string)
+	size, _ := fileInfo.Data["size"].(int64)
+	if maxSize > 0 && size > maxSize {
+		return &CLIResult{
+			Success: false,
+			Code:    "FILE_TOO_LARGE",
+			Message: fmt.Sprintf("file size %s exceeds --max-size %s", formatSize(size), formatSize(maxSize)),
+			Data:    map[string]interface{}{"fid": fid, "size": size, "max_size": maxSize},
+		}
+	}
+
+	var progress func(*sdk.DownloadProgress)
+	if isStderrTTY() {
+		progress = func(p *sdk.DownloadProgress) {
+			fmt.Fprintf(os.Stderr, "\rDownloaded %s", formatSize(p.Downloaded))
+		}
+	}
+	err = client.DownloadToWriter(fid, &limitWriter{w: os.Stdout, limit: maxSize}, progress)
+	if progress != nil {
+		fmt.Fprintln(os.Stderr)
+	}
+	if errors.Is(err, errCatTooLarge) {
+		return &CLIResult{
+			Success: false,
+			Code:    "FILE_TOO_LARGE",
+			Message: fmt.Sprintf("file content exceeds --max-size %s, output truncated", formatSize(maxSize)),
+		}
+	}
+	if err != nil {
+		return &CLIResult{
+			Success: false,
+			Message: fmt.Sprintf("cat failed: %v", err),
+		}
+	}
+	return nil
+}
+
+// isStderrTTY 判断 stderr 是否为终端
+func isStderrTTY() bool {
+	stat, err := os.Stderr.Stat()
+	if err != nil {
+		return false
+	}
+	return (stat.Mode() & os.ModeCharDevice) != 0
+}
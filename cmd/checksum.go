@@ -0,0 +1,146 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"kuake_sdk/sdk"
+)
+
+// checksum 结果中 method 的取值
+const (
+	checksumMethodMetadata = "metadata" // 来自服务端元数据，未下载文件内容
+	checksumMethodDownload = "download" // --download：流式下载文件内容计算
+)
+
+// handleChecksum 处理 checksum 命令：获取远端文件的 md5/sha1
+// 用法: checksum <path> [--download] | checksum --fid <fid> [--download]
+// 优先使用服务端元数据中的哈希；拿不到时返回 HASH_UNAVAILABLE，指定 --download 时改为流式下载计算（不落盘）
+// Data 包含 path、fid、file_name、size、md5、sha1、method（metadata 或 download）
+func handleChecksum(client *sdk.QuarkClient, args []string) *CLIResult {
+	filePath := ""
+	fileFid := ""
+	download := false
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--fid":
+			if i+1 >= len(args) || args[i+1] == "" {
+				return &CLIResult{
+					Success: false,
+					Code:    "INVALID_ARGS",
+					Message: "missing value for --fid",
+				}
+			}
+			fileFid = args[i+1]
+			i++
+		case "--download":
+			download = true
+		default:
+			filePath = args[i]
+		}
+	}
+	if filePath == "" && fileFid == "" {
+		return &CLIResult{
+			Success: false,
+			Code:    "INVALID_ARGS",
+			Message: `Usage: checksum <path> | checksum --fid <fid> [--download] (path must be quoted, e.g., checksum "/backup/db.tar")`,
+		}
+	}
+
+	var fileInfo *sdk.StandardResponse
+	var err error
+	if fileFid != "" {
+		fileInfo, err = client.GetFileInfoByFid(fileFid)
+	} else {
+		fileInfo, err = client.GetFileInfo(filePath)
+	}
+	if err != nil {
+		return &CLIResult{
+			Success: false,
+			Message: fmt.Sprintf("failed to get file info: %v", err),
+		}
+	}
+	if !fileInfo.Success {
+		return &CLIResult{
+			Success: false,
+			Code:    fileInfo.Code,
+			Message: fileInfo.Message,
+		}
+	}
+	if isDir, _ := fileInfo.Data["dir"].(bool); isDir {
+		return &CLIResult{
+			Success: false,
+			Code:    "INVALID_FILE_TYPE",
+			Message: "cannot checksum directory",
+		}
+	}
+	fid, _ := fileInfo.Data["fid"].(string)
+	fileName, _ := fileInfo.Data["file_name"].(string)
+	size, _ := fileInfo.Data["size"].(int64)
+	data := map[string]interface{}{
+		"path":      filePath,
+		"fid":       fid,
+		"file_name": fileName,
+		"size":      size,
+	}
+
+	method := checksumMethodMetadata
+	md5Hex, sha1Hex, err := client.GetFileHash(fid)
+	if errors.Is(err, sdk.ErrHashUnavailable) && download {
+		method = checksumMethodDownload
+		md5Hex, sha1Hex, err = computeChecksum(client, fid, fileName)
+	}
+	if errors.Is(err, sdk.ErrHashUnavailable) {
+		return &CLIResult{
+			Success: false,
+			Code:    "HASH_UNAVAILABLE",
+			Message: "file hash is not available from server metadata (use --download to compute it by streaming the file)",
+			Data:    data,
+		}
+	}
+	if err != nil {
+		return &CLIResult{
+			Success: false,
+			Message: fmt.Sprintf("checksum failed: %v", err),
+			Data:    data,
+		}
+	}
+	data["md5"] = md5Hex
+	data["sha1"] = sha1Hex
+	data["method"] = method
+	return &CLIResult{
+		Success: true,
+		Code:    "OK",
+		Message: fmt.Sprintf("checksum from %s", method),
+		Data:    data,
+	}
+}
+
+// computeChecksum 流式下载 fid 计算哈希，按 --progress 在 stderr 显示进度
+func computeChecksum(client *sdk.QuarkClient, fid, fileName string) (string, string, error) {
+	var opts sdk.DownloadOptions
+	if cliProgress == progressJSON {
+		progress := newJSONProgress("download", fileName)
+		var last sdk.DownloadProgress
+		opts.Progress = func(p *sdk.DownloadProgress) {
+			last = *p
+			if !p.Done {
+				progress.update("", p.Downloaded, p.Total, p.Speed, p.ETA)
+			}
+		}
+		md5Hex, sha1Hex, err := client.ComputeFileHash(fid, opts)
+		progress.done(last.Downloaded, last.Total, doneSpeed(&last), doneElapsed(&last), false, err)
+		return md5Hex, sha1Hex, err
+	}
+
+	progress := newTextProgress()
+	opts.Progress = func(p *sdk.DownloadProgress) {
+		line := fmt.Sprintf("Hashed %s", formatSize(p.Downloaded))
+		if p.Total > 0 {
+			line = fmt.Sprintf("Hashed %s / %s (%.1f%%)", formatSize(p.Downloaded), formatSize(p.Total), float64(p.Downloaded)*100/float64(p.Total))
+		}
+		progress.update(line, p.Downloaded, p.Total)
+	}
+	md5Hex, sha1Hex, err := client.ComputeFileHash(fid, opts)
+	progress.finish("")
+	return md5Hex, sha1Hex, err
+}
@@ -0,0 +1,302 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"kuake_sdk/sdk"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// downloadMultiple 将多个远端路径下载到同一本地目录 destDir（不存在时创建）
+// 所有文件（包括目录下的文件）进入同一个下载任务队列，--workers 控制同时下载的文件数，失败的文件按重试策略自动重新入队；
+// 单个路径失败不影响其它路径，每个文件结束时在 stderr 输出一行结果，并显示所有文件的总进度
+// 目录需配合 --recursive，按 download --recursive 的规则保存为 destDir/<目录名>/...
+// 重跑同一命令时跳过断点状态文件中已完成的文件（--restart 忽略），数量记在 resumed 中
+// Data 包含 dest、results（每个文件一条，按完成顺序）、downloaded（成功数）、resumed、failed（失败的文件）；有失败时结果为失败（退出码非 0）
+func downloadMultiple(client *sdk.QuarkClient, paths []string, destDir string, flags downloadFlags) *CLIResult {
+	if destDir == "" {
+		destDir = "."
+	}
+	if err := os.MkdirAll(destDir, 0755); err != nil {
+		return &CLIResult{
+			Success: false,
+			Code:    "LOCAL_DIR_ERROR",
+			Message: fmt.Sprintf("failed to create dest directory: %v", err),
+		}
+	}
+
+	results := make([]sdk.DownloadResult, 0, len(paths))
+	failed := make([]sdk.DownloadResult, 0)
+	var mu sync.Mutex
+	progress := newBatchProgress("download")
+	opts := flags.dirOptions()
+	opts.Recursive = flags.recursive
+	if opts.Workers < 1 {
+		opts.Workers = 1
+	}
+	opts.OnFile = func(result sdk.DownloadResult) {
+		mu.Lock()
+		defer mu.Unlock()
+		results = append(results, result)
+		if result.Error != "" {
+			failed = append(failed, result)
+		}
+		progress.fileDone(result)
+	}
+	opts.OnProgress = progress.update
+	response, err := client.DownloadPaths(paths, destDir, opts)
+	progress.finish()
+	if err != nil {
+		return &CLIResult{
+			Success: false,
+			Message: fmt.Sprintf("download failed: %v", err),
+		}
+	}
+	if !response.Success {
+		return &CLIResult{
+			Success: false,
+			Code:    response.Code,
+			Message: response.Message,
+		}
+	}
+
+	downloaded := len(results) - len(failed)
+	data := map[string]interface{}{
+		"dest":       destDir,
+		"results":    results,
+		"downloaded": downloaded,
+		"resumed":    response.Data["resumed"],
+		"failed":     failed,
+	}
+	if len(failed) > 0 || response.Code != "OK" {
+		return &CLIResult{
+			Success: false,
+			Code:    "DOWNLOAD_PARTIAL_FAILED",
+			Message: fmt.Sprintf("%d files downloaded, %d failed", downloaded, len(failed)),
+			Data:    data,
+		}
+	}
+	return &CLIResult{
+		Success: true,
+		Code:    "OK",
+		Message: fmt.Sprintf("%d files downloaded to %s", downloaded, destDir),
+		Data:    data,
+	}
+}
+
+// manifestEntry 清单文件中的一行下载任务
+type manifestEntry struct {
+	Line      int    // 行号（从 1 开始）
+	Text      string // 原始行内容，写入 --failed-out 时原样输出
+	Path      string // 远端路径
+	LocalPath string // 本地路径（dest 下），解析失败时为空
+	Error     string // 解析错误，非空时不下载直接记为失败
+}
+
+// parseDownloadManifest 解析下载清单：每行一个远端路径，或 "远端路径<TAB>本地相对路径"；空行和 # 开头的注释行忽略
+// 未指定本地相对路径时保存为 dest/<远端文件名>；本地相对路径不能是绝对路径或跳出 dest
+func parseDownloadManifest(content, destDir string) []manifestEntry {
+	entries := make([]manifestEntry, 0)
+	for i, raw := range strings.Split(content, "\n") {
+		text := strings.TrimRight(raw, "\r")
+		trimmed := strings.TrimSpace(text)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		entry := manifestEntry{Line: i + 1, Text: text}
+		remotePath, localRel := trimmed, ""
+		if tab := strings.Index(text, "\t"); tab >= 0 {
+			remotePath = strings.TrimSpace(text[:tab])
+			localRel = strings.TrimSpace(text[tab+1:])
+		}
+		entry.Path = remotePath
+		switch {
+		case remotePath == "":
+			entry.Error = "missing remote path"
+		case localRel == "":
+			name := path.Base(remotePath)
+			if name == "" || name == "." || name == "/" {
+				entry.Error = "cannot determine local file name"
+			} else {
+				entry.LocalPath = filepath.Join(destDir, name)
+			}
+		default:
+			cleaned := filepath.Clean(filepath.FromSlash(localRel))
+			if filepath.IsAbs(cleaned) || cleaned == "." || cleaned == ".." || strings.HasPrefix(cleaned, ".."+string(filepath.Separator)) {
+				entry.Error = fmt.Sprintf("unsafe local path: %s", localRel)
+			} else {
+				entry.LocalPath = filepath.Join(destDir, cleaned)
+			}
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// manifestExecutor 在 TaskQueue 中执行清单下载任务（实现 sdk.TaskExecutor）
+// 任务结果为 "downloaded" 或 "skipped"（本地已存在同样大小的文件），失败时返回错误
+type manifestExecutor struct {
+	client *sdk.QuarkClient
+	flags  downloadFlags
+	total  int
+	mu     sync.Mutex
+	done   int
+}
+
+func (e *manifestExecutor) Execute(task *sdk.Task) (interface{}, error) {
+	entry, _ := task.Params["entry"].(manifestEntry)
+	status, err := e.download(entry)
+
+	e.mu.Lock()
+	e.done++
+	switch {
+	case err != nil:
+		fmt.Fprintf(os.Stderr, "[%d/%d] failed %s: %v\n", e.done, e.total, entry.Path, err)
+	case status == "skipped":
+		fmt.Fprintf(os.Stderr, "[%d/%d] skipped %s (already exists)\n", e.done, e.total, entry.Path)
+	default:
+		fmt.Fprintf(os.Stderr, "[%d/%d] downloaded %s -> %s\n", e.done, e.total, entry.Path, entry.LocalPath)
+	}
+	e.mu.Unlock()
+	return status, err
+}
+
+func (e *manifestExecutor) download(entry manifestEntry) (string, error) {
+	if entry.Error != "" {
+		return "", errors.New(entry.Error)
+	}
+	fileInfo, err := e.client.GetFileInfo(entry.Path)
+	if err != nil {
+		return "", fmt.Errorf("failed to get file info: %w", err)
+	}
+	if !fileInfo.Success {
+		return "", errors.New(fileInfo.Message)
+	}
+	if isDir, _ := fileInfo.Data["dir"].(bool); isDir {
+		return "", errors.New("cannot download directory")
+	}
+	fid, _ := fileInfo.Data["fid"].(string)
+	if fid == "" {
+		return "", errors.New("file info does not contain valid fid")
+	}
+	size, _ := fileInfo.Data["size"].(int64)
+	if stat, err := os.Stat(entry.LocalPath); err == nil && stat.Mode().IsRegular() && stat.Size() == size {
+		return "skipped", nil
+	}
+	if err := os.MkdirAll(filepath.Dir(entry.LocalPath), 0755); err != nil {
+		return "", fmt.Errorf("create local dir: %w", err)
+	}
+	_, err = e.client.DownloadFileToPath(fid, entry.LocalPath, filepath.Base(entry.LocalPath), e.flags.fileOptions(fileInfo.Data))
+	if errors.Is(err, sdk.ErrDownloadSkipped) {
+		return "skipped", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return "downloaded", nil
+}
+
+// downloadFromManifest 按清单文件批量下载到 destDir，用 TaskQueue 控制并发（workers 个文件同时下载）
+// 本地已存在同样大小的文件时跳过；failedOut 非空时把失败的行原样写入该文件，可直接作为 --from-file 重跑
+// Data 包含 dest、stats（total/downloaded/failed/skipped）、failed（失败的行及原因）、failed_out；有失败时结果为失败（退出码非 0）
+func downloadFromManifest(client *sdk.QuarkClient, manifestPath, destDir, failedOut string, flags downloadFlags) *CLIResult {
+	content, err := os.ReadFile(manifestPath)
+	if err != nil {
+		return &CLIResult{
+			Success: false,
+			Code:    "INVALID_ARGS",
+			Message: fmt.Sprintf("failed to read --from-file: %v", err),
+		}
+	}
+	if destDir == "" {
+		destDir = "."
+	}
+	entries := parseDownloadManifest(string(content), destDir)
+	workers := flags.workers
+	if workers < 1 {
+		workers = sdk.DEFAULT_DOWNLOAD_WORKERS
+	}
+
+	// 先加入全部任务再启动队列，避免队列空闲时 Wait 提前返回
+	queue := sdk.NewTaskQueue(workers)
+	tasks := make([]*sdk.Task, 0, len(entries))
+	for _, entry := range entries {
+		tasks = append(tasks, queue.AddTask(sdk.TaskTypeDownload, map[string]interface{}{"entry": entry}))
+	}
+	if len(tasks) > 0 {
+		queue.Start(&manifestExecutor{client: client, flags: flags, total: len(tasks)})
+		queue.Wait()
+		queue.Stop()
+	}
+
+	downloaded, skipped := 0, 0
+	failed := make([]map[string]interface{}, 0)
+	failedLines := make([]string, 0)
+	for i, task := range tasks {
+		if task.Status == sdk.TaskStatusCompleted {
+			if task.Result == "skipped" {
+				skipped++
+			} else {
+				downloaded++
+			}
+			continue
+		}
+		message := "task not completed"
+		if task.Error != nil {
+			message = task.Error.Error()
+		}
+		failed = append(failed, map[string]interface{}{
+			"line":       entries[i].Line,
+			"path":       entries[i].Path,
+			"local_path": entries[i].LocalPath,
+			"error":      message,
+		})
+		failedLines = append(failedLines, entries[i].Text)
+	}
+
+	data := map[string]interface{}{
+		"dest": destDir,
+		"stats": map[string]interface{}{
+			"total":      len(tasks),
+			"downloaded": downloaded,
+			"failed":     len(failed),
+			"skipped":    skipped,
+		},
+		"failed": failed,
+	}
+	if failedOut != "" {
+		output := ""
+		if len(failedLines) > 0 {
+			output = strings.Join(failedLines, "\n") + "\n"
+		}
+		if err := os.WriteFile(failedOut, []byte(output), 0644); err != nil {
+			return &CLIResult{
+				Success: false,
+				Code:    "OUTPUT_WRITE_ERROR",
+				Message: fmt.Sprintf("failed to write --failed-out file: %v", err),
+				Data:    data,
+			}
+		}
+		data["failed_out"] = failedOut
+	}
+
+	message := fmt.Sprintf("%d downloaded, %d failed, %d skipped", downloaded, len(failed), skipped)
+	if len(failed) > 0 {
+		return &CLIResult{
+			Success: false,
+			Code:    "DOWNLOAD_PARTIAL_FAILED",
+			Message: message,
+			Data:    data,
+		}
+	}
+	return &CLIResult{
+		Success: true,
+		Code:    "OK",
+		Message: message,
+		Data:    data,
+	}
+}
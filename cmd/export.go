@@ -0,0 +1,287 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"kuake_sdk/sdk"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// treeExporter 顺序深度优先遍历目录并把结果边遍历边写入 JSON 文件
+// 同一时刻只在内存中保留当前路径上各级目录的一页条目，不缓存整棵树
+type treeExporter struct {
+	client   *sdk.QuarkClient
+	maxDepth int                      // 最大深度，<= 0 表示不限深度
+	nested   bool                     // true 写嵌套的目录树（tree），false 写先序遍历的条目数组（list --recursive）
+	w        *bufio.Writer            // 输出文件
+	files    int                      // 已写入的文件数
+	dirs     int                      // 已写入的目录数
+	failed   []map[string]interface{} // 列目录失败的子目录及原因
+	first    bool                     // 数组模式下下一个条目是否为第一个（不需要逗号）
+	writeErr error                    // 第一个写入错误，出现后停止遍历
+}
+
+// exportTreeJSON 遍历 dirPath 子树并写入 outPath：nested 为 true 时写目录树（与 tree 命令输出结构一致），
+// 否则写条目数组（与 list --recursive 的 list 一致）；stdout 只返回 summary（文件数、目录数、耗时）
+// 写文件失败时返回 OUTPUT_WRITE_ERROR 并删除未写完的文件
+func exportTreeJSON(client *sdk.QuarkClient, dirPath string, maxDepth int, nested bool, outPath string) *CLIResult {
+	startTime := time.Now()
+
+	// 根目录的 fid 固定为 "0"，其余路径先解析出目录 fid
+	rootFid, rootPath := "0", "/"
+	if dirPath != "" && dirPath != "/" {
+		rootInfo, err := client.GetFileInfo(dirPath, true)
+		if err != nil {
+			return &CLIResult{
+				Success: false,
+				Message: err.Error(),
+			}
+		}
+		if !rootInfo.Success {
+			return &CLIResult{
+				Success: false,
+				Code:    rootInfo.Code,
+				Message: rootInfo.Message,
+			}
+		}
+		if isDir, _ := rootInfo.Data["dir"].(bool); !isDir {
+			return &CLIResult{
+				Success: false,
+				Code:    "NOT_A_DIRECTORY",
+				Message: fmt.Sprintf("not a directory: %s", dirPath),
+			}
+		}
+		rootFid, _ = rootInfo.Data["fid"].(string)
+		rootPath, _ = rootInfo.Data["path"].(string)
+		if rootPath == "" {
+			rootPath = dirPath
+		}
+	}
+
+	file, err := os.Create(outPath)
+	if err != nil {
+		return &CLIResult{
+			Success: false,
+			Code:    "OUTPUT_WRITE_ERROR",
+			Message: fmt.Sprintf("failed to create output file: %v", err),
+		}
+	}
+	exporter := &treeExporter{
+		client:   client,
+		maxDepth: maxDepth,
+		nested:   nested,
+		w:        bufio.NewWriter(file),
+		failed:   make([]map[string]interface{}, 0),
+		first:    true,
+	}
+
+	var failResult *CLIResult
+	if nested {
+		rootName := ""
+		if rootPath != "/" {
+			rootName = filepath.Base(rootPath)
+		}
+		root := sdk.TreeNode{Fid: rootFid, Name: rootName, Path: rootPath, IsDirectory: true}
+		failResult = exporter.writeDir(root, 0, true)
+		exporter.write("\n")
+	} else {
+		exporter.write("[")
+		failResult = exporter.walkDir(rootFid, rootPath, 0, true)
+		exporter.write("]\n")
+	}
+	if exporter.writeErr == nil {
+		exporter.writeErr = exporter.w.Flush()
+	}
+	if closeErr := file.Close(); exporter.writeErr == nil {
+		exporter.writeErr = closeErr
+	}
+
+	if exporter.writeErr != nil || failResult != nil {
+		// 删除半成品文件，避免被误当作完整结果使用
+		os.Remove(outPath)
+		if exporter.writeErr != nil {
+			return &CLIResult{
+				Success: false,
+				Code:    "OUTPUT_WRITE_ERROR",
+				Message: fmt.Sprintf("failed to write output file: %v", exporter.writeErr),
+			}
+		}
+		return failResult
+	}
+
+	elapsed := time.Since(startTime)
+	code, message := "OK", fmt.Sprintf("exported %d files and %d directories to %s", exporter.files, exporter.dirs, outPath)
+	if len(exporter.failed) > 0 {
+		code = "PARTIAL_SUCCESS"
+		message = fmt.Sprintf("%s, %d directories failed", message, len(exporter.failed))
+	}
+	return &CLIResult{
+		Success: true,
+		Code:    code,
+		Message: message,
+		Data: map[string]interface{}{
+			"out":        outPath,
+			"files":      exporter.files,
+			"dirs":       exporter.dirs,
+			"failed":     exporter.failed,
+			"elapsed":    elapsed.Round(time.Millisecond).String(),
+			"elapsed_ms": elapsed.Milliseconds(),
+		},
+	}
+}
+
+// writeDir 写入一个目录节点（嵌套模式）：先写节点字段，再边列目录边写 children，列目录失败时追加 error
+func (e *treeExporter) writeDir(node sdk.TreeNode, depth int, isRoot bool) *CLIResult {
+	header, err := marshalJSON(node)
+	if err != nil {
+		e.writeErr = err
+		return nil
+	}
+	// 去掉末尾的 "}"，在节点内继续追加 children / error
+	e.write(string(header[:len(header)-1]))
+	if node.Truncated {
+		e.write("}")
+		return nil
+	}
+
+	e.write(`,"children":[`)
+	first := true
+	failResult := e.listDir(node.Fid, node.Path, depth, isRoot, func(file sdk.QuarkFileInfo) *CLIResult {
+		if !first {
+			e.write(",")
+		}
+		first = false
+		child := sdk.TreeNode{
+			Fid:         file.Fid,
+			Name:        file.Name,
+			Path:        file.Path,
+			Size:        file.Size,
+			IsDirectory: file.IsDirectory,
+			ModifyTime:  file.ModifyTime,
+		}
+		if !file.IsDirectory {
+			e.files++
+			e.writeValue(child)
+			return nil
+		}
+		e.dirs++
+		child.Truncated = e.maxDepth > 0 && depth+1 >= e.maxDepth
+		return e.writeDir(child, depth+1, false)
+	})
+	e.write("]")
+	if errMessage, ok := e.lastFailure(node.Fid); ok {
+		e.write(`,"error":`)
+		e.writeValue(errMessage)
+	}
+	e.write("}")
+	return failResult
+}
+
+// walkDir 先序遍历目录并把条目逐个写入数组（数组模式）
+func (e *treeExporter) walkDir(fid, dirPath string, depth int, isRoot bool) *CLIResult {
+	return e.listDir(fid, dirPath, depth, isRoot, func(file sdk.QuarkFileInfo) *CLIResult {
+		if !e.first {
+			e.write(",")
+		}
+		e.first = false
+		e.writeValue(file)
+		if !file.IsDirectory {
+			e.files++
+			return nil
+		}
+		e.dirs++
+		if e.maxDepth > 0 && depth+1 >= e.maxDepth {
+			return nil
+		}
+		return e.walkDir(file.Fid, file.Path, depth+1, false)
+	})
+}
+
+// listDir 按页列出目录并对每个条目调用 visit；起始目录失败时返回失败结果，子目录失败时记录到 failed 并继续
+func (e *treeExporter) listDir(fid, dirPath string, depth int, isRoot bool, visit func(file sdk.QuarkFileInfo) *CLIResult) *CLIResult {
+	var failResult *CLIResult
+	response, err := e.client.ListByFidPages(fid, dirPath, sdk.ListOptions{}, func(files []sdk.QuarkFileInfo) bool {
+		for _, file := range files {
+			if failResult = visit(file); failResult != nil || e.writeErr != nil {
+				return false
+			}
+		}
+		return true
+	})
+	if failResult != nil {
+		return failResult
+	}
+
+	code, message := "", ""
+	if err != nil {
+		code, message = "LIST_REQUEST_ERROR", err.Error()
+	} else if !response.Success {
+		code, message = response.Code, response.Message
+	}
+	if code == "" {
+		return nil
+	}
+	if isRoot {
+		return &CLIResult{
+			Success: false,
+			Code:    code,
+			Message: message,
+		}
+	}
+	e.failed = append(e.failed, map[string]interface{}{
+		"fid":     fid,
+		"path":    dirPath,
+		"code":    code,
+		"message": message,
+	})
+	return nil
+}
+
+// lastFailure 返回刚列出失败的目录的错误信息
+func (e *treeExporter) lastFailure(fid string) (string, bool) {
+	if len(e.failed) == 0 {
+		return "", false
+	}
+	last := e.failed[len(e.failed)-1]
+	if last["fid"] != fid {
+		return "", false
+	}
+	message, _ := last["message"].(string)
+	return message, true
+}
+
+// write 写入原始文本，出现写入错误后忽略后续写入
+func (e *treeExporter) write(text string) {
+	if e.writeErr != nil {
+		return
+	}
+	_, e.writeErr = e.w.WriteString(text)
+}
+
+// writeValue 将值编码为紧凑 JSON 后写入
+func (e *treeExporter) writeValue(v interface{}) {
+	if e.writeErr != nil {
+		return
+	}
+	data, err := marshalJSON(v)
+	if err != nil {
+		e.writeErr = err
+		return
+	}
+	e.write(string(data))
+}
+
+// marshalJSON 编码为紧凑 JSON，不转义 HTML 字符（与 outputJSON 一致），不带末尾换行
+func marshalJSON(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimRight(buf.Bytes(), "\n"), nil
+}
@@ -0,0 +1,19 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// uploadSignalContext 返回收到 SIGINT/SIGTERM 时取消的 context：上传据此停止正在进行的分片、保存断点续传状态并返回 INTERRUPTED
+// 收到第一个信号后恢复默认处理，再按一次 Ctrl+C 直接退出；结束时调用返回的 stop
+func uploadSignalContext() (context.Context, context.CancelFunc) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	return ctx, stop
+}
@@ -4,23 +4,49 @@ import (
 	"bufio"
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"kuake_sdk/sdk"
+	"mime"
 	"os"
+	"path"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 )
 
 const (
-	ExitSuccess = 0
-	ExitError   = 1
+	ExitSuccess    = 0
+	ExitError      = 1
+	ExitQueryError = 3 // exists 命令：无法确定是否存在（网络、认证、参数错误等）
+
+	ExitInterrupted = 130 // upload 被 Ctrl+C/SIGTERM 中断（结果 code 为 INTERRUPTED），已保存断点续传状态
 )
 
+// initErrorExitCode 客户端初始化失败（配置或 cookie 缺失、损坏）时的退出码：
+// exists 的退出码 1 表示不存在，初始化失败属于无法确定，使用 ExitQueryError
+func initErrorExitCode(command string) int {
+	if command == "exists" {
+		return ExitQueryError
+	}
+	return ExitError
+}
+
 // Version 版本号，与编译产物名称一致
 var Version = "v1.4.0"
 
+// cliDefaults 命令默认值（合并配置文件 defaults 段与内置默认值），在 main 中初始化
+var cliDefaults = (*sdk.Config)(nil).EffectiveDefaults()
+
+// cliTransfer 配置文件中的传输配置，在 main 中初始化
+var cliTransfer sdk.TransferConfig
+
+// cliSync 配置文件中的目录同步配置（sync.ignore），在 main 中初始化
+var cliSync sdk.SyncConfig
+
 type CLIResult struct {
 	Success bool                   `json:"success"`
 	Code    string                 `json:"code,omitempty"`
@@ -49,6 +75,29 @@ func main() {
 			continue
 		}
 
+		// 检查是否是进度输出格式参数（--progress=json 或 --progress json）
+		if arg == "--progress" || strings.HasPrefix(arg, "--progress=") {
+			value := strings.TrimPrefix(arg, "--progress=")
+			if arg == "--progress" {
+				value = ""
+				if i+1 < len(os.Args) {
+					value = os.Args[i+1]
+					skipNext = true
+				}
+			}
+			mode, err := parseProgressMode(value)
+			if err != nil {
+				outputJSON(&CLIResult{
+					Success: false,
+					Code:    "INVALID_ARGS",
+					Message: err.Error(),
+				})
+				os.Exit(ExitError)
+			}
+			cliProgress = mode
+			continue
+		}
+
 		// 检查是否是配置文件参数
 		if arg == "-c" || arg == "--config" {
 			if i+1 < len(os.Args) {
@@ -117,6 +166,31 @@ func main() {
 		os.Exit(ExitError)
 	}
 
+	// 优先级：cookies 参数 > 环境变量 KUAKE_COOKIE（OpenClaw 标准配置方式）> 配置文件
+	if cookies == "" {
+		cookies = os.Getenv("KUAKE_COOKIE")
+	}
+	if cookies != "" {
+		cookies = normalizeCookie(cookies)
+	}
+
+	// config 命令只读写配置文件，不需要初始化客户端
+	if command == "config" {
+		result := handleConfig(configPath, cookies, args)
+		outputJSON(result)
+		if !result.Success {
+			os.Exit(ExitError)
+		}
+		os.Exit(ExitSuccess)
+	}
+
+	// 读取命令默认值：配置文件不存在或无法解析时使用内置默认值
+	if cfg, err := sdk.ReadConfig(configPath); err == nil {
+		cliDefaults = cfg.EffectiveDefaults()
+		cliTransfer = cfg.Transfer
+		cliSync = cfg.Sync
+	}
+
 	// 创建客户端
 	var client *sdk.QuarkClient
 	defer func() {
@@ -126,31 +200,11 @@ func main() {
 				Code:    "INIT_ERROR",
 				Message: fmt.Sprintf("Failed to initialize client: %v", r),
 			})
-			os.Exit(ExitError)
+			os.Exit(initErrorExitCode(command))
 		}
 	}()
-	// 优先级：cookies 参数 > 环境变量 KUAKE_COOKIE > 配置文件
 	if cookies != "" {
-		// 如果传入的值不包含 __pus=，自动添加前缀
-		if !strings.Contains(cookies, "__pus=") {
-			cookies = "__pus=" + cookies
-		}
-		// 如果末尾没有分号，添加分号
-		if !strings.HasSuffix(cookies, ";") {
-			cookies = cookies + ";"
-		}
 		client = sdk.NewQuarkClient(configPath, cookies)
-	} else if envCookie := os.Getenv("KUAKE_COOKIE"); envCookie != "" {
-		// 从环境变量读取（OpenClaw 标准配置方式）
-		// 如果传入的值不包含 __pus=，自动添加前缀
-		if !strings.Contains(envCookie, "__pus=") {
-			envCookie = "__pus=" + envCookie
-		}
-		// 如果末尾没有分号，添加分号
-		if !strings.HasSuffix(envCookie, ";") {
-			envCookie = envCookie + ";"
-		}
-		client = sdk.NewQuarkClient(configPath, envCookie)
 	} else {
 		client = sdk.NewQuarkClient(configPath)
 	}
@@ -164,10 +218,40 @@ func main() {
 		result = handleList(client, args)
 	case "info":
 		result = handleInfo(client, args)
+	case "tree":
+		result = handleTree(client, args)
+	case "du":
+		result = handleDu(client, args)
+	case "search":
+		result = handleSearch(client, args)
+	case "find":
+		result = handleFind(client, args)
+	case "recent":
+		result = handleRecent(client, args)
+	case "exists":
+		// exists 用退出码表达结果：0 存在，1 不存在，3 无法确定
+		existsResult, exitCode := handleExists(client, args)
+		outputJSON(existsResult)
+		os.Exit(exitCode)
 	case "download":
 		result = handleDownload(client, args)
+	case "cat":
+		// cat 的 stdout 只输出文件内容，失败结果写到 stderr
+		if catResult := handleCat(client, args); catResult != nil {
+			outputJSONTo(os.Stderr, catResult)
+			os.Exit(ExitError)
+		}
+		os.Exit(ExitSuccess)
+	case "checksum":
+		result = handleChecksum(client, args)
+	case "stream":
+		result = handleStream(client, args)
 	case "upload":
 		result = handleUpload(client, args)
+	case "upload-abort":
+		result = handleUploadAbort(client, args)
+	case "upload-state":
+		result = handleUploadState(client, args)
 	case "create":
 		result = handleCreateFolder(client, args)
 	case "move":
@@ -186,6 +270,10 @@ func main() {
 		result = handleShareList(client, args)
 	case "share-save":
 		result = handleShareSave(client, args)
+	case "share-detail":
+		result = handleShareDetail(client, args)
+	case "share-download":
+		result = handleShareDownload(client, args)
 	case "help", "-h", "--help":
 		printUsage()
 		os.Exit(ExitSuccess)
@@ -216,12 +304,26 @@ func main() {
 	outputJSON(result)
 
 	// 根据结果设置退出码
+	if result.Code == "INTERRUPTED" {
+		os.Exit(ExitInterrupted)
+	}
 	if !result.Success {
 		os.Exit(ExitError)
 	}
 	os.Exit(ExitSuccess)
 }
 
+// normalizeCookie 补全 cookie 值：缺少 __pus= 前缀时自动添加，末尾没有分号时添加分号
+func normalizeCookie(cookies string) string {
+	if !strings.Contains(cookies, "__pus=") {
+		cookies = "__pus=" + cookies
+	}
+	if !strings.HasSuffix(cookies, ";") {
+		cookies = cookies + ";"
+	}
+	return cookies
+}
+
 func printUsage() {
 	fmt.Fprintf(os.Stderr, `Quark Cloud Drive CLI Tool
 
@@ -232,34 +334,238 @@ Usage:
 Options:
   -c, --config <path>          Specify config file path (default: config.json)
   -cookies, --cookies <value>  Specify cookie value directly (automatically adds __pus= prefix, bypasses config file)
+  --progress text|json         Progress on stderr for upload/download: text (default; a line every 5%%/10s
+                               when stderr is not a terminal) or one JSON event per
+                               line every 500ms (type=progress: downloaded, total, speed, eta, file; type=done at the end)
   -v, --version                Show version information
 
 Commands:
   user                        Get user information
-  list [path] [--stream]     List directory (default: "/")
-                              Use --stream to output one JSON per line for pipeline mode
-  info <path>                 Get file/folder info (supports pipe mode)
-  download <path> [dest]      Get file download URL, or download to local file if dest given (supports pipe mode)
-  upload <file> <dest> [--max_upload_parallel N]
-                              Upload file (all parameters must be quoted)
+  list [path] [--stream] [--format json|stream|table|csv] [--csv <file>] [--recursive] [--max-depth N]
+       [--sort file_name|updated_at|size] [--order asc|desc]
+       [--dirs-only|--files-only] [--name-contains <text>] [--page N] [--limit N]
+       [--category video|audio|image|document]
+  list --fid <fid> [options]  List directory by fid, skipping path resolution (entries have empty path, use pdir_fid/fid to drill down)
+                              List directory (default: "/")
+                              Use --stream to output one JSON per line for pipeline mode (NDJSON, written page by page,
+                              followed by a summary line with "type": "summary")
+                              Use --recursive to list the whole subtree, --max-depth N to limit depth
+                              Use --recursive --out <file> to stream entries into a JSON array file (summary on stdout)
+                              Use --sort/--order to change ordering (directories always first)
+                              Use --dirs-only/--files-only/--name-contains to filter entries
+                              Use --page/--limit to fetch a single page instead of all entries
+                              Use --category to list only videos, audio, images or documents (server-side filter)
+                              Use --format table for an aligned human-readable table (TSV when not a terminal)
+                              Use --format csv to print CSV, or --csv <file> to export CSV (path,fid,size,dir,ctime,mtime)
+  info <path> [--format json|table] [--with-share] [--icase]
+                              Get file/folder info (supports pipe mode)
+                              Use --with-share to include shared/share_id/share_url
+                              Use --icase to match the path case-insensitively (AMBIGUOUS_PATH lists candidates)
+  info --fid <fid>            Get file/folder info by fid
+  tree [path] [--max-depth N] [--out <file>]
+                              Output directory tree as nested JSON (children arrays)
+                              Use --out to stream the tree into a JSON file while walking; stdout only gets
+                              a summary (files, dirs, elapsed). The partial file is removed on write errors
+  du [path] [--depth 1]       Sum up total size, file count and directory count of a directory
+                              Use --depth 1 to also report each first-level subdirectory
+  find [path] [--newer-than T] [--older-than T] [--min-size S] [--max-size S] [--max-depth N] [--stream]
+                              Recursively find entries by modification time and size
+                              T: Go duration (72h), days (7d) or date (2006-01-02 / RFC3339)
+                              S: bytes or with K/M/G/T suffix (e.g., 100M); size filters match files only
+  recent [N] [--path <dir>]    List the N most recently modified files (default: 50) with full paths
+                              Scans the subtree of --path (default: "/")
+  search <keyword> [--page N] [--size N]
+                              Search files by name across the drive (entries include fid and pdir_fid)
+  exists <path> [--icase] | --fid <fid>
+                              Check existence via exit code (0 exists, 1 not exists, 3 error)
+  download <path> [dest] [--recursive] [--workers N] [--connections N]
+  download --fid <fid> [dest] Download by fid without resolving the path (saved under the name returned by the API)
+  download <path> [path2] ... --dest <dir>
+                              Download several remote paths into one directory, one result per file
+                              (sequential by default, --workers N to run N at a time); all files, including
+                              those under directories, share one worker pool. Failures are listed in "failed"
+                              and the remaining files are still downloaded
+  download --from-file <list> [dest] [--workers N] [--failed-out <file>]
+                              Download every file listed in <list>: one remote path per line, or
+                              "remote<TAB>local/relative/path"; blank lines and # comments are ignored.
+                              Files already present locally with the same size are skipped. --failed-out
+                              writes the failed lines to <file> so they can be retried with --from-file
+                              Get file download URL, or download to local file if dest given (supports pipe mode)
+                              Use --recursive to download a directory as dest/<dir>/... (--workers N files at a time,
+                              default 4); the result lists downloaded and failed files, exit code 1 if any failed
+                              Directory and multi-path downloads show a total line (files done/total, bytes) and
+                              requeue files that still fail on network errors or verification (up to 2 times)
+                              They keep .kuake-download-state.json in the target directory; rerunning the same
+                              command skips files it records as complete, and the file is removed once everything
+                              succeeded. Use --restart to ignore it and download everything again
+                              Use --connections N to download each file in N parallel ranges (falls back to one
+                              connection when the server does not support Range)
+                              Use --range start-end (or start-) to download only that byte range of one file into
+                              dest (end inclusive); fails with RANGE_NOT_SUPPORTED if the server ignores Range
+                              dest defaults to defaults.download_dir in config when set
+                              Downloaded files (and directories with --recursive) get the remote modification
+                              time as mtime; use --no-preserve-mtime to keep the download time
+                              Data is written to <file>.kuake-tmp and renamed into place once complete; when the
+                              local file exists, --on-conflict fail (default, FILE_EXISTS), overwrite, skip (exit
+                              code 0), or rename ("name (1).ext", "name (2).ext"); applied per file with --recursive
+                              --verify checks the size (and md5/sha1 when the API returns them) after download;
+                              on mismatch the file is deleted and the result is VERIFY_FAILED
+                              Free space on the target disk is checked before writing (INSUFFICIENT_DISK_SPACE);
+                              use --no-space-check to skip it (e.g., sparse or compressed file systems)
+                              --limit-rate R caps the total download speed in bytes/s (K/M/G suffixes, e.g., 5M),
+                              shared by all files downloaded at the same time; 0 means no limit
+  download <path> --print-headers
+                              Print download_url plus the Cookie/User-Agent/Referer headers an external
+                              downloader (aria2, IDM, curl) must send; the output contains your login cookie
+  download <path> --print-cmd curl|wget
+                              Print a ready-to-run curl/wget command (all headers, shell-quoted) in Data.command
+  download <path> [dir] --aria2 <rpc-url> [--aria2-secret S] [--aria2-wait]
+                              Submit the file to aria2 via aria2.addUri (with the required headers and file
+                              name) instead of downloading locally; dir is on the aria2 host. Works with
+                              --recursive, --dest and --fid (one task per file); --aria2-wait polls until done
+  cat <path> [--max-size S]   Write remote file content to stdout (errors go to stderr as JSON)
+  cat --fid <fid>             Refuses files larger than --max-size (default 100M, 0 = no limit)
+  checksum <path> [--download] | checksum --fid <fid> [--download]
+                              Print the md5/sha1 of a remote file from server metadata (method: metadata);
+                              when the server has no hash, --download streams the file and hashes it without
+                              writing to disk (method: download), otherwise the result is HASH_UNAVAILABLE
+  stream <path> [--quality Q] | stream --fid <fid> [--quality Q]
+                              Print a play_url for mpv/VLC plus the headers the player must send. Uses the
+                              best transcoded quality by default (Data.qualities lists them); Q is 4k, 2k,
+                              super, high, normal, low or original (the file itself, also the fallback when the
+                              file has no transcodes). The headers contain your login cookie
+  upload <file> <dest> [--max_upload_parallel N] [--policy skip|overwrite|rsync]
+         [--on-conflict skip|overwrite|rename|fail] [--no-resume] [--rapid-only] [--recursive [--workers N]]
+         [--min-speed R] [--min-speed-window D] [--limit-rate R] [--no-preserve-mtime] [--verify] [--dry-run]
+         [--sanitize-names] [--parents] [--content-type T] [--links follow|skip|error]
+         [--include P] [--exclude P] [--ignore-file F]
+                              Upload file (all parameters must be quoted); <file> "-" reads stdin (buffered in
+                              a temp file under TMPDIR, removed afterwards), dest must then include the file name.
+                              An interrupted upload of the same file to the same dest resumes from the parts
+                              already uploaded, unless the local file changed (size or mtime); --no-resume
+                              discards the saved state and starts over.
+                              Data.rapid tells whether the server already had the file (instant upload).
+                              --rapid-only only tries the instant upload: when the server doesn't have the file
+                              nothing is uploaded and the result is RAPID_UPLOAD_MISS
+                              --on-conflict checks the dest first (overrides --policy): skip (SKIPPED when the
+                              remote file has the same size, otherwise overwrite), overwrite (delete the remote
+                              file, then upload), rename ("name (1).ext") or fail (FILE_EXISTS);
+                              Data.conflict_action is none, skipped, overwritten, renamed or failed
+                              --recursive uploads the contents of a local directory into dest, creating the same
+                              remote folders (--workers N files at a time, default 2, independent of the part
+                              parallelism inside each file); stderr shows one line per file and the total
+                              progress. A failed file doesn't stop the others, Data lists uploaded, skipped and
+                              failed files (UPLOAD_PARTIAL_FAILED when any failed); with --on-conflict skip only
+                              new or changed files are uploaded
+                              --min-speed R (K/M/G suffixes, e.g., 512K) cancels and resends a part whose average
+                              speed over --min-speed-window D (default 30s, counted from the first byte sent) is
+                              below R; defaults to transfer.upload_min_speed / transfer.upload_speed_window
+                              --limit-rate R caps the total upload speed in bytes/s (K/M/G suffixes, e.g., 10M,
+                              0 = unlimited), shared by parallel parts and --recursive workers
+                              The remote file keeps the local creation time and mtime (list shows the local
+                              mtime); --no-preserve-mtime records the upload time instead
+                              --verify looks the file up again after the upload and compares its size (and
+                              md5/sha1 when the server returns them); on mismatch the result is VERIFY_FAILED
+                              and the resume state is kept. Data.hash_verified tells whether hashes were compared
+                              --dry-run only lists the dest folders to check for conflicts and prints the plan
+                              without creating or uploading anything: Data.actions (create_dir, upload, skip,
+                              overwrite or fail per folder/file, following --policy/--on-conflict), Data.counts
+                              and Data.total_bytes (bytes that would be sent); works with and without --recursive
+                              A file name with control characters (e.g. a tab), invalid UTF-8 or more than 255
+                              bytes fails with INVALID_FILE_NAME naming the offending character; --sanitize-names
+                              (or transfer.sanitize_names) replaces such characters with "_" and truncates long
+                              names keeping the extension, Data.original_name holds the local name. With
+                              --recursive the check applies to each file
+                              The dest folder must already exist (PARENT_NOT_FOUND otherwise); --parents creates
+                              missing folders level by level. With --recursive dest itself is created, its
+                              parent must exist unless --parents is given
+                              The MIME type sent to the server follows the dest extension; without a known
+                              extension it is detected from the first 512 bytes of the content (so files without
+                              a standard extension can still be previewed on the web). --content-type T sets it
+                              explicitly (single file, stdin and --from-url only)
+                              --links (with --recursive) sets how symbolic links are handled: skip (default;
+                              listed in Data.skipped with a reason), follow (upload the target file or enter the
+                              target folder; a link back to a folder already walked is skipped as a cycle), or
+                              error (the link is counted as a failed file). A broken link with follow fails
+                              that entry only, the other files are still uploaded
+                              --include P / --exclude P (with --recursive, repeatable) filter the files by glob
+                              pattern relative to the local folder ("*.jpg", "**/cache/*", "node_modules/" for
+                              folders only; a pattern without "/" matches the name at any depth). Rules are
+                              checked in the given order and the first match decides; when no rule matches,
+                              folders are entered and files are uploaded only if no --include was given. An
+                              excluded folder is skipped with everything below it. Data.excluded lists the
+                              excluded entries; --dry-run shows them as exclude actions and the matching rule
+                              of every file in "rule"
+                              With --recursive, a .kuakeignore file in the local folder (or any subfolder, for
+                              that subtree) excludes files with .gitignore syntax ("!keep.log" re-includes,
+                              "build/" matches folders only), on top of the config sync.ignore and the rules of
+                              --ignore-file F (repeatable); they apply before --include/--exclude
+  upload --from-url <url> <dest> [upload options]
+                              Fetch an http(s) URL and upload it to dest (dest "/" uses the file name from
+                              Content-Disposition or the URL). When the source sends Content-Length the data is
+                              uploaded part by part while it downloads, nothing is written to disk; otherwise it is
+                              first fetched into a temp file under TMPDIR. stderr shows the fetched bytes next to
+                              the upload progress. Data.streamed and Data.fetched describe the transfer; a streamed
+                              upload cannot be resumed, an interrupted one has to start over
+  upload --from-file <list> [--workers N] [--failed-out <file>] [upload options]
+                              Upload every file listed in <list>: "local<TAB>remote" per line (remote as the
+                              upload dest); blank lines and # comments are ignored. --workers N files at a time
+                              (default 2); one result per line in Data.results (uploaded, skipped or failed).
+                              --failed-out writes the failed lines to <file> so they can be retried with --from-file
+  upload --hash-only --md5 <md5> --sha1 <sha1> --size <bytes> --name <name> [--parents] <dest_dir>
+                              Instant upload from known hashes, no local file needed: creates <dest_dir>/<name>
+                              when the server already has a file with these hashes, otherwise RAPID_UPLOAD_MISS
+  upload-abort [--all | <state-id>]
+                              Without arguments, list the unfinished uploads saved for resuming (id, file_path,
+                              dest_path, uploaded_parts). With an id or --all, abort their multipart uploads on
+                              OSS and delete the saved state (UPLOAD_ABORT_FAILED when any abort fails)
+  upload-state list | upload-state purge --older-than D
+                              List the saved resume states (source file, dest, uploaded_parts/total_parts, last
+                              saved time) or delete the ones saved before D (7d, 72h or a date; local files only,
+                              use upload-abort to also abort them on OSS). The directory is env KUAKE_STATE_DIR,
+                              then transfer.upload_state_dir, default kuake/upload_state under the user cache dir
   create <name> <pdir>        Create folder (use "/" for root)
   move <src> <dest>           Move file/folder
   copy <src> <dest>           Copy file/folder
   rename <path> <newName>     Rename file/folder
   delete <path>               Delete file/folder (supports pipe mode)
-  share <path> <days> <passcode>  Create share link
-                                days: 0=permanent, 1/7/30=days
-                                passcode: "true" or "false"
+  share <path> [days] [passcode]  Create share link
+                                days: 0=permanent, 1/7/30=days (default: defaults.share_days or 7)
+                                passcode: "true" or "false" (default: defaults.share_passcode or false)
   share-delete <share_id_or_path>...  Delete share(s) by share ID(s) or file path(s)
   share-list [page] [size] [orderField] [orderType]  Get my share list
                                 page: page number (default: 1)
                                 size: page size (default: 50)
                                 orderField: sort field (default: "created_at")
                                 orderType: "asc" or "desc" (default: "desc")
-  share-save <share_link> [passcode] [dest_dir]  Save shared files to your drive
+  share-save <share_link> [passcode] [dest_dir] [--select G] [--fids F] [--no-wait]
+                                Save shared files to your drive
                                 share_link: share link (e.g., "https://pan.quark.cn/s/xxx")
                                 passcode: extraction code (optional, auto-extracted from link if present)
                                 dest_dir: destination directory (default: "/")
+                                --select <glob> (repeatable) saves only matching items, searched recursively: a
+                                pattern without "/" matches the name, with "/" the path inside the share ("**"
+                                allowed); a matching folder is saved whole. --fids fid1,fid2 selects items by fid.
+                                Selected items from subfolders are saved directly into dest_dir; Data.selected
+                                lists them (SHARE_NO_MATCH when nothing matches)
+                                The save runs as a server task: share-save waits until it finishes (up to 10
+                                minutes) and returns Data.saved_fids, the top-level fids saved into your drive;
+                                if the task fails (e.g. not enough space) the result is SAVE_TASK_FAILED with the
+                                reason in Data.error. --no-wait returns right after submitting (task_status pending)
+  share-detail <share_link> [passcode] [--dir <fid>] [--page N]
+                              List the files in a share link without saving them (50 per page, folders first):
+                              name, size, fid, share_fid_token and dir. --dir lists a subfolder by its fid (from
+                              an earlier share-detail); Data.has_more tells whether --page N+1 has more
+  share-download <share_link> [passcode] [local_dir] [--workers N] [--on-conflict P]
+                              Download everything in a share link to local_dir (default: defaults.download_dir
+                              or "."). The share page has no direct links, so files are saved to a temporary
+                              "/.kuake-share-*" directory, downloaded, then the directory is deleted (Data.method
+                              is temp_save; Data.cleanup_error is set if the deletion failed)
+  config show [--effective]   Show config file (tokens masked); --effective shows merged defaults
+  config get <key>            Get config value by dotted key (e.g., transfer.upload_parallel)
+  config set <key> <value>    Validate and save config value (e.g., config set defaults.share_days 30)
+  config unset <key>          Remove config value (falls back to built-in default)
+  config check [--offline]    Diagnose config file, tokens, network and proxy (exit code 1 if any check fails)
   version                     Show version information
   help                           Show help
 
@@ -281,6 +587,8 @@ Examples:
   kuake share-list 1 50 "created_at" "desc"
   kuake share-save "https://pan.quark.cn/s/xxx"
   kuake share-save "https://pan.quark.cn/s/xxx" "1234" "/folder"
+  kuake share-save "https://pan.quark.cn/s/xxx" "/videos" --select "S01E01*" --select "*.srt"
+  kuake share-detail "https://pan.quark.cn/s/xxx" "1234"
   
   # Using -cookies parameter (bypasses config file, only cookie value needed):
   kuake -cookies "your_cookie_value_here" user
@@ -306,16 +614,24 @@ Pipeline Mode:
 Notes:
   - All path parameters must be quoted
   - Root directory is "/"
-  - Upload parallel can be set by --max_upload_parallel or env KUAKE_UPLOAD_PARALLEL (1-16, default 4)
+  - Upload parallel: --max_upload_parallel > config transfer.upload_parallel > env KUAKE_UPLOAD_PARALLEL (1-16),
+    defaults to the server's part_thread
   - Results output as JSON to stdout
-  - Exit code: 0=success, 1=failure
-  - When using -cookies, the config file is not read, improving efficiency and avoiding inconsistencies
+  - Exit code: 0=success, 1=failure, 130=upload interrupted by Ctrl+C/SIGTERM (code INTERRUPTED,
+    upload state saved; rerun the same command to resume)
+  - When using -cookies, access tokens in the config file are not used (the defaults section still applies)
+  - Command defaults priority: command line > config "defaults" section > built-in defaults
   - In pipe mode, each input line should be a JSON object with "path" or "fid" field
   - Use --stream with list command to output one JSON per line for pipeline processing
 `)
 }
 
 func outputJSON(result *CLIResult) {
+	outputJSONTo(os.Stdout, result)
+}
+
+// outputJSONTo 将结果以格式化 JSON 写入 w（cat 等命令的 stdout 只输出文件内容，结果写到 stderr）
+func outputJSONTo(w io.Writer, result *CLIResult) {
 	var buf bytes.Buffer
 	encoder := json.NewEncoder(&buf)
 	encoder.SetEscapeHTML(false) // 禁用 HTML 转义，避免 < > 被转义为 \u003c \u003e
@@ -329,8 +645,8 @@ func outputJSON(result *CLIResult) {
 	if len(output) > 0 && output[len(output)-1] == '\n' {
 		output = output[:len(output)-1]
 	}
-	// 写入 stdout，捕获 broken pipe 错误
-	if _, err := fmt.Println(output); err != nil {
+	// 写入输出，捕获 broken pipe 错误
+	if _, err := fmt.Fprintln(w, output); err != nil {
 		// 忽略 broken pipe 错误（管道接收端已关闭）
 		if strings.Contains(err.Error(), "broken pipe") {
 			// 静默退出，这是正常的管道行为
@@ -389,6 +705,19 @@ func extractPathFromJSON(jsonStr string) (string, string, error) {
 	return path, fid, nil
 }
 
+// isStreamSummary 判断输入行是否为流式输出的 summary 行（data.type 为 "summary"）
+func isStreamSummary(line string) bool {
+	var result struct {
+		Data struct {
+			Type string `json:"type"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal([]byte(line), &result); err != nil {
+		return false
+	}
+	return result.Data.Type == "summary"
+}
+
 // processStdinLines 从 stdin 逐行读取并处理
 // processor 函数接收 path 和 fid，返回处理结果
 func processStdinLines(processor func(path, fid string) *CLIResult) {
@@ -407,6 +736,10 @@ func processStdinLines(processor func(path, fid string) *CLIResult) {
 			path = line
 			fid = ""
 		}
+		if path == "" && fid == "" && isStreamSummary(line) {
+			// list --stream 的最后一行汇总信息，不是待处理的条目
+			continue
+		}
 
 		if path == "" && fid == "" {
 			outputJSON(&CLIResult{
@@ -479,6 +812,25 @@ func outputStreamJSON(result *CLIResult) {
 	os.Stdout.WriteString("\n")
 }
 
+// streamFileData 将文件信息转换为流式输出中单行 JSON 的 data 字段
+func streamFileData(qfi sdk.QuarkFileInfo) map[string]interface{} {
+	return map[string]interface{}{
+		"fid":          qfi.Fid,
+		"file_name":    qfi.Name,
+		"path":         qfi.Path,
+		"pdir_fid":     qfi.PdirFid,
+		"size":         qfi.Size,
+		"ctime":        qfi.CreateTime,
+		"mtime":        qfi.ModifyTime,
+		"dir":          qfi.IsDirectory,
+		"download_url": qfi.DownloadURL,
+		"created_at":   qfi.CreatedAt,
+		"updated_at":   qfi.UpdatedAt,
+		"l_created_at": qfi.LCreatedAt,
+		"l_updated_at": qfi.LUpdatedAt,
+	}
+}
+
 // handleUserInfo 处理获取用户信息命令
 func handleUserInfo(client *sdk.QuarkClient) *CLIResult {
 	response, err := client.GetUserInfo()
@@ -507,23 +859,79 @@ func handleUserInfo(client *sdk.QuarkClient) *CLIResult {
 
 // handleUpload 处理上传文件命令
 func handleUpload(client *sdk.QuarkClient, args []string) *CLIResult {
-	if len(args) < 2 {
+	// --from-url <url> <dest> 从直链拉取上传，取出 URL 后只剩 <dest> 一个位置参数
+	sourceURL := ""
+	for i, arg := range args {
+		if arg == "--from-url" && i+1 < len(args) {
+			sourceURL = args[i+1]
+			args = append(append([]string{}, args[:i]...), args[i+2:]...)
+			break
+		}
+	}
+	// --from-file 按清单批量上传，没有 <file> <dest> 位置参数，选项从第一个参数开始
+	optStart := 2
+	if sourceURL != "" {
+		optStart = 1
+	}
+	for _, arg := range args {
+		if arg == "--hash-only" {
+			return handleUploadByHash(client, args)
+		}
+		if arg == "--from-file" {
+			optStart = 0
+		}
+	}
+	if len(args) < optStart {
 		return &CLIResult{
 			Success: false,
 			Code:    "INVALID_ARGS",
-			Message: `Usage: upload <file> <dest> [--max_upload_parallel N] [--policy skip|overwrite|rsync] (all parameters must be quoted)`,
+			Message: `Usage: upload <file> <dest> [--max_upload_parallel N] [--policy skip|overwrite|rsync] [--on-conflict skip|overwrite|rename|fail] [--no-resume] [--rapid-only] [--no-preserve-mtime] [--verify] [--sanitize-names] [--parents] [--content-type T] [--recursive [--workers N] [--links follow|skip|error] [--include P] [--exclude P] [--ignore-file F]] [--limit-rate R] [--dry-run] | upload --from-url <url> <dest> | upload --from-file <list> [--workers N] [--failed-out <file>] (all parameters must be quoted)`,
 		}
 	}
 
-	filePath := args[0]
-	destPath := args[1]
-	var uploadParallel string
-	opts := &sdk.UploadOptions{
-		Policy: sdk.UploadPolicySkip, // 默认跳过
+	var filePath, destPath string
+	switch optStart {
+	case 2:
+		filePath, destPath = args[0], args[1]
+	case 1:
+		destPath = args[0]
 	}
+	manifestPath, failedOut := "", ""
+	opts := &sdk.UploadOptions{
+		Policy:   sdk.UploadPolicy(cliDefaults.ConflictPolicy), // 默认取配置 defaults.conflict_policy，未配置时跳过
+		Parallel: cliTransfer.UploadParallel,                   // 默认取配置 transfer.upload_parallel，未配置时由 SDK 读取环境变量或服务端 part_thread
 
-	for i := 2; i < len(args); i++ {
+		SanitizeNames: cliTransfer.SanitizeNames, // 默认取配置 transfer.sanitize_names
+	}
+	recursive := false
+	dryRun := false
+	workers := 0
+	minSpeed := int64(-1) // -1 表示沿用配置 transfer.upload_min_speed
+	var minSpeedWindow time.Duration
+	var ignoreFiles []string
+
+	for i := optStart; i < len(args); i++ {
 		switch args[i] {
+		case "--from-file", "--failed-out":
+			if i+1 >= len(args) {
+				return &CLIResult{
+					Success: false,
+					Code:    "INVALID_ARGS",
+					Message: fmt.Sprintf("missing value for %s", args[i]),
+				}
+			}
+			if args[i] == "--from-file" {
+				manifestPath = args[i+1]
+			} else {
+				failedOut = args[i+1]
+			}
+			i++
+		case "--from-url":
+			return &CLIResult{
+				Success: false,
+				Code:    "INVALID_ARGS",
+				Message: "missing value for --from-url (usage: upload --from-url <url> <dest>)",
+			}
 		case "--max_upload_parallel", "--max-upload-parallel", "--upload-parallel":
 			if i+1 >= len(args) {
 				return &CLIResult{
@@ -534,14 +942,14 @@ func handleUpload(client *sdk.QuarkClient, args []string) *CLIResult {
 			}
 			value := strings.TrimSpace(args[i+1])
 			parallel, err := strconv.Atoi(value)
-			if err != nil || parallel < 1 {
+			if err != nil || parallel < sdk.MIN_UPLOAD_PARALLEL || parallel > sdk.MAX_UPLOAD_PARALLEL {
 				return &CLIResult{
 					Success: false,
 					Code:    "INVALID_ARGS",
-					Message: "invalid --max_upload_parallel, must be integer >= 1",
+					Message: fmt.Sprintf("invalid --max_upload_parallel, must be an integer between %d and %d", sdk.MIN_UPLOAD_PARALLEL, sdk.MAX_UPLOAD_PARALLEL),
 				}
 			}
-			uploadParallel = strconv.Itoa(parallel)
+			opts.Parallel = parallel
 			i++
 		case "--policy":
 			if i+1 >= len(args) {
@@ -561,6 +969,164 @@ func handleUpload(client *sdk.QuarkClient, args []string) *CLIResult {
 			}
 			opts.Policy = sdk.UploadPolicy(policyArg)
 			i++
+		case "--no-resume":
+			opts.NoResume = true
+		case "--rapid-only":
+			opts.RapidOnly = true
+		case "--no-preserve-mtime":
+			opts.NoPreserveMtime = true
+		case "--verify":
+			opts.Verify = true
+		case "--sanitize-names":
+			opts.SanitizeNames = true
+		case "--parents":
+			opts.CreateParents = true
+		case "--ignore-file":
+			if i+1 >= len(args) {
+				return &CLIResult{
+					Success: false,
+					Code:    "INVALID_ARGS",
+					Message: "missing file for --ignore-file",
+				}
+			}
+			ignoreFiles = append(ignoreFiles, args[i+1])
+			i++
+		case "--include", "--exclude":
+			if i+1 >= len(args) {
+				return &CLIResult{
+					Success: false,
+					Code:    "INVALID_ARGS",
+					Message: fmt.Sprintf("missing pattern for %s", args[i]),
+				}
+			}
+			opts.Filters = append(opts.Filters, sdk.UploadFilterRule{Exclude: args[i] == "--exclude", Pattern: args[i+1]})
+			i++
+		case "--links":
+			if i+1 >= len(args) {
+				return &CLIResult{
+					Success: false,
+					Code:    "INVALID_ARGS",
+					Message: "missing value for --links",
+				}
+			}
+			switch policy := sdk.UploadLinkPolicy(args[i+1]); policy {
+			case sdk.UploadLinksFollow, sdk.UploadLinksSkip, sdk.UploadLinksError:
+				opts.Links = policy
+			default:
+				return &CLIResult{
+					Success: false,
+					Code:    "INVALID_ARGS",
+					Message: fmt.Sprintf("invalid --links value %q, must be follow, skip or error", args[i+1]),
+				}
+			}
+			i++
+		case "--content-type":
+			if i+1 >= len(args) {
+				return &CLIResult{
+					Success: false,
+					Code:    "INVALID_ARGS",
+					Message: "missing value for --content-type",
+				}
+			}
+			if _, _, err := mime.ParseMediaType(args[i+1]); err != nil || !strings.Contains(args[i+1], "/") {
+				return &CLIResult{
+					Success: false,
+					Code:    "INVALID_ARGS",
+					Message: fmt.Sprintf("invalid --content-type %q, must be a MIME type such as text/markdown", args[i+1]),
+				}
+			}
+			opts.ContentType = args[i+1]
+			i++
+		case "--dry-run":
+			dryRun = true
+		case "--min-speed", "--min-speed-window":
+			if i+1 >= len(args) {
+				return &CLIResult{
+					Success: false,
+					Code:    "INVALID_ARGS",
+					Message: fmt.Sprintf("missing value for %s", args[i]),
+				}
+			}
+			if args[i] == "--min-speed" {
+				speed, err := parseSizeArg(args[i+1])
+				if err != nil {
+					return &CLIResult{
+						Success: false,
+						Code:    "INVALID_ARGS",
+						Message: fmt.Sprintf("invalid --min-speed: %v", err),
+					}
+				}
+				minSpeed = speed
+			} else {
+				window, err := time.ParseDuration(args[i+1])
+				if err != nil || window <= 0 {
+					return &CLIResult{
+						Success: false,
+						Code:    "INVALID_ARGS",
+						Message: "invalid --min-speed-window, must be a positive duration (e.g., 30s)",
+					}
+				}
+				minSpeedWindow = window
+			}
+			i++
+		case "--limit-rate":
+			if i+1 >= len(args) {
+				return &CLIResult{
+					Success: false,
+					Code:    "INVALID_ARGS",
+					Message: "missing value for --limit-rate",
+				}
+			}
+			rate, err := parseSizeArg(args[i+1])
+			if err != nil {
+				return &CLIResult{
+					Success: false,
+					Code:    "INVALID_ARGS",
+					Message: fmt.Sprintf("invalid --limit-rate value: %v", err),
+				}
+			}
+			// 并发分片以及 --recursive 时并发上传的文件共享一个限速器
+			opts.RateLimiter = sdk.NewRateLimiter(rate)
+			i++
+		case "--recursive", "-r":
+			recursive = true
+		case "--workers":
+			if i+1 >= len(args) {
+				return &CLIResult{
+					Success: false,
+					Code:    "INVALID_ARGS",
+					Message: "missing value for --workers",
+				}
+			}
+			n, err := strconv.Atoi(args[i+1])
+			if err != nil || n < 1 || n > sdk.MAX_UPLOAD_WORKERS {
+				return &CLIResult{
+					Success: false,
+					Code:    "INVALID_ARGS",
+					Message: fmt.Sprintf("invalid --workers value, must be an integer between 1 and %d", sdk.MAX_UPLOAD_WORKERS),
+				}
+			}
+			workers = n
+			i++
+		case "--on-conflict":
+			if i+1 >= len(args) {
+				return &CLIResult{
+					Success: false,
+					Code:    "INVALID_ARGS",
+					Message: "missing value for --on-conflict",
+				}
+			}
+			switch policy := sdk.UploadConflictPolicy(args[i+1]); policy {
+			case sdk.UploadConflictSkip, sdk.UploadConflictOverwrite, sdk.UploadConflictRename, sdk.UploadConflictFail:
+				opts.OnConflict = policy
+			default:
+				return &CLIResult{
+					Success: false,
+					Code:    "INVALID_ARGS",
+					Message: fmt.Sprintf("invalid --on-conflict value %q, must be skip, overwrite, rename or fail", args[i+1]),
+				}
+			}
+			i++
 		default:
 			return &CLIResult{
 				Success: false,
@@ -570,141 +1136,1317 @@ func handleUpload(client *sdk.QuarkClient, args []string) *CLIResult {
 		}
 	}
 
-	if uploadParallel != "" {
-		_ = os.Setenv("KUAKE_UPLOAD_PARALLEL", uploadParallel)
-	}
-
-	// 进度回调，显示上传进度、速度和剩余时间
-	progressCallback := func(progress *sdk.UploadProgress) {
-		if progress == nil {
-			return
-		}
-		// 输出到 stderr，避免干扰 JSON 输出
-		if progress.SpeedStr == "秒传（文件已存在）" {
-			// 秒传情况，显示特殊提示
-			fmt.Fprintf(os.Stderr, "\r上传进度: %d%% | %s", progress.Progress, progress.SpeedStr)
-		} else {
-			fmt.Fprintf(os.Stderr, "\r上传进度: %d%% | 速度: %s | 剩余: %s",
-				progress.Progress, progress.SpeedStr, progress.RemainingStr)
-		}
-		if progress.Progress == 100 {
-			fmt.Fprintf(os.Stderr, "\n")
+	if minSpeed >= 0 || minSpeedWindow > 0 {
+		speed := minSpeed
+		if speed < 0 {
+			speed = (&sdk.Config{Transfer: cliTransfer}).EffectiveUploadMinSpeed()
 		}
+		client.SetUploadMinSpeed(speed, minSpeedWindow)
 	}
-
-	response, err := client.UploadFile(filePath, destPath, progressCallback, opts)
-	if err != nil {
+	if opts.ContentType != "" && (recursive || manifestPath != "") {
 		return &CLIResult{
 			Success: false,
-			Message: err.Error(),
+			Code:    "INVALID_ARGS",
+			Message: "--content-type applies to a single file and cannot be combined with --recursive or --from-file",
 		}
 	}
 
-	if !response.Success {
+	if opts.Links != "" && !recursive {
 		return &CLIResult{
 			Success: false,
-			Code:    response.Code,
-			Message: response.Message,
-		}
-	}
-
-	return &CLIResult{
-		Success: true,
-		Code:    response.Code,
-		Message: response.Message,
-		Data:    response.Data,
-	}
-}
-
-// handleList 处理列出目录命令
-func handleList(client *sdk.QuarkClient, args []string) *CLIResult {
-	dirPath := "/"
-	streamMode := false
-	
-	// 解析参数，支持 --stream 选项
-	var filteredArgs []string
-	for i, arg := range args {
-		if arg == "--stream" || arg == "-s" {
-			streamMode = true
-		} else if i == 0 {
-			dirPath = arg
-		} else {
-			filteredArgs = append(filteredArgs, arg)
+			Code:    "INVALID_ARGS",
+			Message: "--links requires --recursive",
 		}
 	}
-
-	response, err := client.List(dirPath)
-	if err != nil {
+	if len(opts.Filters) > 0 && !recursive {
 		return &CLIResult{
 			Success: false,
-			Message: err.Error(),
+			Code:    "INVALID_ARGS",
+			Message: "--include/--exclude require --recursive",
 		}
 	}
-
-	if !response.Success {
+	if len(ignoreFiles) > 0 && !recursive {
 		return &CLIResult{
 			Success: false,
-			Code:    response.Code,
-			Message: response.Message,
+			Code:    "INVALID_ARGS",
+			Message: "--ignore-file requires --recursive",
 		}
 	}
-
-	// 流式模式：每行输出一个文件的 JSON
-	if streamMode {
-		// 从 response.Data 中提取 list 数组
-		// response.Data 的类型是 map[string]interface{}，但 list 字段的实际类型是 []sdk.QuarkFileInfo
-		if quarkFileInfos, ok := response.Data["list"].([]sdk.QuarkFileInfo); ok {
-			// 将 QuarkFileInfo 转换为 map[string]interface{} 并逐行输出
-			for _, qfi := range quarkFileInfos {
-				fileInfo := map[string]interface{}{
-					"fid":          qfi.Fid,
-					"file_name":    qfi.Name,
-					"path":         qfi.Path,
-					"size":         qfi.Size,
-					"ctime":        qfi.CreateTime,
-					"mtime":        qfi.ModifyTime,
-					"dir":          qfi.IsDirectory,
-					"download_url": qfi.DownloadURL,
-					"created_at":   qfi.CreatedAt,
-					"updated_at":   qfi.UpdatedAt,
-					"l_created_at": qfi.LCreatedAt,
-					"l_updated_at": qfi.LUpdatedAt,
-				}
-				fileResult := &CLIResult{
-					Success: true,
-					Code:    response.Code,
-					Message: "OK",
-					Data:    fileInfo,
+	if recursive {
+		// 配置 sync.ignore 在前，--ignore-file 的规则在后（同一路径冲突时优先）；本地目录下的 .kuakeignore 由 SDK 遍历时叠加
+		var patterns []string
+		for _, ignoreFile := range ignoreFiles {
+			lines, err := sdk.ReadIgnoreFile(ignoreFile)
+			if err != nil {
+				return &CLIResult{
+					Success: false,
+					Code:    "INVALID_ARGS",
+					Message: fmt.Sprintf("failed to read --ignore-file: %v", err),
 				}
-				outputStreamJSON(fileResult)
 			}
-			// 流式模式下不返回结果，已经逐行输出
-			return nil
+			patterns = append(patterns, lines...)
 		}
-		// 如果无法提取 list，回退到普通模式
+		ignore, err := (&sdk.Config{Sync: cliSync}).IgnoreMatcher(patterns...)
+		if err != nil {
+			return &CLIResult{
+				Success: false,
+				Code:    "INVALID_ARGS",
+				Message: err.Error(),
+			}
+		}
+		opts.Ignore = ignore
 	}
 
-	return &CLIResult{
-		Success: true,
-		Code:    response.Code,
-		Message: response.Message,
-		Data:    response.Data,
-	}
+	// --from-url：边拉取边上传，不写本地文件（长度未知时先写临时文件）
+	if sourceURL != "" {
+		if manifestPath != "" || recursive || dryRun || workers > 0 || opts.RapidOnly {
+			return &CLIResult{
+				Success: false,
+				Code:    "INVALID_ARGS",
+				Message: "--from-url cannot be combined with --from-file, --recursive, --workers, --dry-run or --rapid-only",
+			}
+		}
+		ctx, stop := uploadSignalContext()
+		defer stop()
+		opts.Context = ctx
+		return uploadFromURL(client, sourceURL, destPath, opts)
+	}
+
+	// --from-file：按清单批量上传，每行 "本地路径<TAB>远端路径"
+	if manifestPath != "" {
+		if recursive || dryRun {
+			return &CLIResult{
+				Success: false,
+				Code:    "INVALID_ARGS",
+				Message: "--from-file cannot be combined with --recursive or --dry-run",
+			}
+		}
+		ctx, stop := uploadSignalContext()
+		defer stop()
+		opts.Context = ctx
+		return uploadFromManifest(client, manifestPath, failedOut, workers, *opts)
+	}
+	if failedOut != "" {
+		return &CLIResult{
+			Success: false,
+			Code:    "INVALID_ARGS",
+			Message: "--failed-out requires --from-file",
+		}
+	}
+
+	// --dry-run：只列出目标目录判断冲突，输出计划动作，不创建目录也不上传
+	if dryRun {
+		if filePath == "-" {
+			return &CLIResult{
+				Success: false,
+				Code:    "INVALID_ARGS",
+				Message: "--dry-run cannot be used when uploading from stdin",
+			}
+		}
+		if info, err := os.Stat(filePath); err == nil && info.IsDir() != recursive {
+			message := fmt.Sprintf("%s is a directory (use --recursive)", filePath)
+			if recursive {
+				message = fmt.Sprintf("%s is not a directory (--recursive requires a directory)", filePath)
+			}
+			return &CLIResult{
+				Success: false,
+				Code:    "INVALID_ARGS",
+				Message: message,
+			}
+		}
+		response, err := client.PlanUpload(filePath, destPath, *opts)
+		return uploadResult(response, err)
+	}
+
+	// Ctrl+C/SIGTERM：取消正在上传的分片并保存断点续传状态，输出 INTERRUPTED 结果后以 ExitInterrupted 退出
+	ctx, stop := uploadSignalContext()
+	defer stop()
+	opts.Context = ctx
+
+	if recursive {
+		if filePath == "-" {
+			return &CLIResult{
+				Success: false,
+				Code:    "INVALID_ARGS",
+				Message: "--recursive cannot be used when uploading from stdin",
+			}
+		}
+		return uploadDirectory(client, filePath, destPath, sdk.UploadDirOptions{Workers: workers, Upload: *opts})
+	}
+	if workers > 0 {
+		return &CLIResult{
+			Success: false,
+			Code:    "INVALID_ARGS",
+			Message: "--workers requires --recursive",
+		}
+	}
+	if info, err := os.Stat(filePath); err == nil && info.IsDir() && filePath != "-" {
+		return &CLIResult{
+			Success: false,
+			Code:    "INVALID_ARGS",
+			Message: fmt.Sprintf("%s is a directory (use --recursive)", filePath),
+		}
+	}
+
+	// --progress=json：输出与下载相同格式的 JSON 事件
+	if cliProgress == progressJSON {
+		events := newJSONProgress("upload", uploadSourceName(filePath))
+		var last sdk.UploadProgress
+		response, err := uploadSource(client, filePath, destPath, func(progress *sdk.UploadProgress) {
+			if progress != nil {
+				last = *progress
+				eta := progress.Remaining
+				if progress.Speed <= 0 {
+					eta = -1
+				}
+				events.update("", progress.Uploaded, progress.Total, progress.Speed, eta)
+			}
+		}, opts)
+		if err == nil && !response.Success {
+			err = errors.New(response.Message)
+		}
+		// 最后一次回调（已上传 == 总大小）的 Speed 为整体平均速度
+		var speed float64
+		var elapsed time.Duration
+		if last.Total > 0 && last.Uploaded == last.Total {
+			speed, elapsed = last.Speed, last.Elapsed
+		}
+		events.done(last.Uploaded, last.Total, speed, elapsed, false, err)
+		return uploadResult(response, err)
+	}
+
+	// 进度回调，显示上传进度、速度和剩余时间（输出到 stderr，避免干扰 JSON 输出；非终端时按 5% 或 10 秒输出一行）
+	textProgress := newTextProgress()
+	progressCallback := func(progress *sdk.UploadProgress) {
+		if progress == nil {
+			return
+		}
+		line := fmt.Sprintf("上传进度: %d%% | 速度: %s | 剩余: %s", progress.Progress, progress.SpeedStr, progress.RemainingStr)
+		if progress.Rapid {
+			// 秒传情况，显示特殊提示
+			textProgress.finish("上传进度: 100% | 秒传（文件已存在）")
+			return
+		}
+		if progress.Progress == 100 {
+			if progress.Speed > 0 {
+				line = fmt.Sprintf("上传进度: 100%% | 平均速度: %s | 耗时: %s", progress.SpeedStr, progress.Elapsed.Round(time.Second))
+			}
+			textProgress.finish(line)
+			return
+		}
+		textProgress.update(line, int64(progress.Progress), 100)
+	}
+
+	response, err := uploadSource(client, filePath, destPath, progressCallback, opts)
+	return uploadResult(response, err)
+}
+
+// uploadSource 上传本地文件 filePath；filePath 为 "-" 时读取 stdin（先写入临时文件，见 sdk.UploadFromReader）
+func uploadSource(client *sdk.QuarkClient, filePath, destPath string, progressCallback func(*sdk.UploadProgress), opts *sdk.UploadOptions) (*sdk.StandardResponse, error) {
+	if filePath == "-" {
+		return client.UploadFromReader(os.Stdin, destPath, progressCallback, opts)
+	}
+	return client.UploadFile(filePath, destPath, progressCallback, opts)
+}
+
+// uploadSourceName 返回进度事件中显示的源文件名
+func uploadSourceName(filePath string) string {
+	if filePath == "-" {
+		return "stdin"
+	}
+	return filepath.Base(filePath)
+}
+
+// uploadDirectory 递归上传本地目录到 destPath，每个文件结束时在 stderr 输出一行结果，并显示所有文件的总进度
+// 有文件失败时结果为失败（退出码非 0），Data 中列出成功、跳过与失败的文件
+func uploadDirectory(client *sdk.QuarkClient, localDir, destPath string, opts sdk.UploadDirOptions) *CLIResult {
+	progress := newBatchProgress("upload")
+	opts.OnFile = progress.uploadFileDone
+	opts.OnProgress = progress.uploadUpdate
+	response, err := client.UploadDir(localDir, destPath, opts)
+	progress.finish()
+	if ctx := opts.Upload.Context; ctx != nil && ctx.Err() != nil {
+		// 已完成的文件保留，中断时正在上传的文件保存了断点续传状态，重新执行相同命令时继续
+		var data map[string]interface{}
+		if response != nil {
+			data = response.Data
+		}
+		return &CLIResult{
+			Success: false,
+			Code:    "INTERRUPTED",
+			Message: "上传已中断，重新执行相同的上传命令可继续上传",
+			Data:    data,
+		}
+	}
+	if err != nil {
+		return &CLIResult{
+			Success: false,
+			Message: fmt.Sprintf("upload failed: %v", err),
+		}
+	}
+	if !response.Success {
+		return &CLIResult{
+			Success: false,
+			Code:    response.Code,
+			Message: response.Message,
+		}
+	}
+	if response.Code != "OK" {
+		return &CLIResult{
+			Success: false,
+			Code:    "UPLOAD_PARTIAL_FAILED",
+			Message: response.Message,
+			Data:    response.Data,
+		}
+	}
+	return &CLIResult{
+		Success: true,
+		Code:    "OK",
+		Message: response.Message,
+		Data:    response.Data,
+	}
+}
+
+// handleUploadByHash 处理 upload --hash-only：用已知的 md5/sha1/大小秒传到目标目录，不读本地文件
+func handleUploadByHash(client *sdk.QuarkClient, args []string) *CLIResult {
+	usage := `Usage: upload --hash-only --md5 <md5> --sha1 <sha1> --size <bytes> --name <name> [--parents] <dest_dir>`
+	var md5Hash, sha1Hash, name, destPath string
+	size := int64(-1)
+	opts := &sdk.UploadOptions{}
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--hash-only":
+		case "--parents":
+			opts.CreateParents = true
+		case "--md5", "--sha1", "--size", "--name":
+			if i+1 >= len(args) {
+				return &CLIResult{
+					Success: false,
+					Code:    "INVALID_ARGS",
+					Message: fmt.Sprintf("missing value for %s", args[i]),
+				}
+			}
+			value := strings.TrimSpace(args[i+1])
+			switch args[i] {
+			case "--md5":
+				md5Hash = value
+			case "--sha1":
+				sha1Hash = value
+			case "--name":
+				name = value
+			case "--size":
+				n, err := strconv.ParseInt(value, 10, 64)
+				if err != nil || n < 0 {
+					return &CLIResult{
+						Success: false,
+						Code:    "INVALID_ARGS",
+						Message: "invalid --size, must be integer >= 0",
+					}
+				}
+				size = n
+			}
+			i++
+		default:
+			if strings.HasPrefix(args[i], "--") || destPath != "" {
+				return &CLIResult{
+					Success: false,
+					Code:    "INVALID_ARGS",
+					Message: fmt.Sprintf("unknown upload --hash-only option: %s", args[i]),
+				}
+			}
+			destPath = args[i]
+		}
+	}
+	if md5Hash == "" || sha1Hash == "" || size < 0 || name == "" || destPath == "" {
+		return &CLIResult{
+			Success: false,
+			Code:    "INVALID_ARGS",
+			Message: usage,
+		}
+	}
+
+	response, err := client.UploadByHash(name, destPath, size, md5Hash, sha1Hash, opts)
+	return uploadResult(response, err)
+}
+
+// uploadResult 将 UploadFile 的返回值转为 CLIResult
+func uploadResult(response *sdk.StandardResponse, err error) *CLIResult {
+	if err != nil {
+		return &CLIResult{
+			Success: false,
+			Message: err.Error(),
+		}
+	}
+
+	if !response.Success {
+		// RAPID_UPLOAD_MISS 等失败结果的 Data 中带有 rapid、md5、sha1
+		return &CLIResult{
+			Success: false,
+			Code:    response.Code,
+			Message: response.Message,
+			Data:    response.Data,
+		}
+	}
+
+	return &CLIResult{
+		Success: true,
+		Code:    response.Code,
+		Message: response.Message,
+		Data:    response.Data,
+	}
+}
+
+// handleList 处理列出目录命令
+func handleList(client *sdk.QuarkClient, args []string) *CLIResult {
+	dirPath := "/"
+	// 默认输出格式取配置 defaults.list_output
+	streamMode := cliDefaults.ListOutput == "stream"
+	tableMode := false
+	csvMode := false
+	csvFile := ""
+	outFile := ""
+	recursive := false
+	maxDepth := 0
+	var listOpts sdk.ListOptions
+	dirsOnly := false
+	filesOnly := false
+	nameContains := ""
+	dirFid := ""
+
+	// 解析参数，支持 --stream、--format、--recursive、--max-depth、--sort、--order 和过滤选项
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch arg {
+		case "--stream", "-s":
+			streamMode = true
+		case "--recursive", "-r":
+			recursive = true
+		case "--max-depth":
+			if i+1 >= len(args) {
+				return &CLIResult{
+					Success: false,
+					Code:    "INVALID_ARGS",
+					Message: "missing value for --max-depth",
+				}
+			}
+			depth, err := strconv.Atoi(args[i+1])
+			if err != nil || depth < 1 {
+				return &CLIResult{
+					Success: false,
+					Code:    "INVALID_ARGS",
+					Message: "invalid --max-depth value, must be a positive integer",
+				}
+			}
+			maxDepth = depth
+			recursive = true
+			i++
+		case "--sort":
+			if i+1 >= len(args) {
+				return &CLIResult{
+					Success: false,
+					Code:    "INVALID_ARGS",
+					Message: "missing value for --sort (file_name/updated_at/size)",
+				}
+			}
+			switch args[i+1] {
+			case "file_name", "updated_at", "size":
+				listOpts.SortBy = args[i+1]
+			default:
+				return &CLIResult{
+					Success: false,
+					Code:    "INVALID_ARGS",
+					Message: "invalid --sort value, must be 'file_name', 'updated_at', or 'size'",
+				}
+			}
+			i++
+		case "--page", "--limit":
+			if i+1 >= len(args) {
+				return &CLIResult{
+					Success: false,
+					Code:    "INVALID_ARGS",
+					Message: fmt.Sprintf("missing value for %s", arg),
+				}
+			}
+			n, err := strconv.Atoi(args[i+1])
+			if err != nil || n < 1 {
+				return &CLIResult{
+					Success: false,
+					Code:    "INVALID_ARGS",
+					Message: fmt.Sprintf("invalid %s value, must be a positive integer", arg),
+				}
+			}
+			if arg == "--page" {
+				listOpts.Page = n
+			} else {
+				listOpts.Limit = n
+			}
+			i++
+		case "--fid":
+			if i+1 >= len(args) || args[i+1] == "" {
+				return &CLIResult{
+					Success: false,
+					Code:    "INVALID_ARGS",
+					Message: "missing value for --fid",
+				}
+			}
+			dirFid = args[i+1]
+			i++
+		case "--category":
+			if i+1 >= len(args) {
+				return &CLIResult{
+					Success: false,
+					Code:    "INVALID_ARGS",
+					Message: "missing value for --category (video/audio/image/document)",
+				}
+			}
+			if _, ok := sdk.LIST_CATEGORIES[args[i+1]]; !ok {
+				return &CLIResult{
+					Success: false,
+					Code:    "INVALID_ARGS",
+					Message: "invalid --category value, must be 'video', 'audio', 'image', or 'document'",
+				}
+			}
+			listOpts.Category = args[i+1]
+			i++
+		case "--dirs-only":
+			dirsOnly = true
+		case "--files-only":
+			filesOnly = true
+		case "--name-contains":
+			if i+1 >= len(args) {
+				return &CLIResult{
+					Success: false,
+					Code:    "INVALID_ARGS",
+					Message: "missing value for --name-contains",
+				}
+			}
+			nameContains = args[i+1]
+			i++
+		case "--order":
+			if i+1 >= len(args) {
+				return &CLIResult{
+					Success: false,
+					Code:    "INVALID_ARGS",
+					Message: "missing value for --order (asc/desc)",
+				}
+			}
+			switch args[i+1] {
+			case "asc", "desc":
+				listOpts.Order = args[i+1]
+			default:
+				return &CLIResult{
+					Success: false,
+					Code:    "INVALID_ARGS",
+					Message: "invalid --order value, must be 'asc' or 'desc'",
+				}
+			}
+			i++
+		case "--format":
+			if i+1 >= len(args) {
+				return &CLIResult{
+					Success: false,
+					Code:    "INVALID_ARGS",
+					Message: "missing value for --format (json/stream/table/csv)",
+				}
+			}
+			switch args[i+1] {
+			case "json":
+				streamMode, tableMode, csvMode = false, false, false
+			case "stream":
+				streamMode, tableMode, csvMode = true, false, false
+			case "table":
+				streamMode, tableMode, csvMode = false, true, false
+			case "csv":
+				streamMode, tableMode, csvMode = false, false, true
+			default:
+				return &CLIResult{
+					Success: false,
+					Code:    "INVALID_ARGS",
+					Message: "invalid --format value, must be 'json', 'stream', 'table' or 'csv'",
+				}
+			}
+			i++
+		case "--csv":
+			if i+1 >= len(args) || args[i+1] == "" {
+				return &CLIResult{
+					Success: false,
+					Code:    "INVALID_ARGS",
+					Message: "missing file path for --csv",
+				}
+			}
+			csvFile = args[i+1]
+			i++
+		case "--out":
+			if i+1 >= len(args) || args[i+1] == "" {
+				return &CLIResult{
+					Success: false,
+					Code:    "INVALID_ARGS",
+					Message: "missing file path for --out",
+				}
+			}
+			outFile = args[i+1]
+			i++
+		default:
+			if i == 0 {
+				dirPath = arg
+			}
+		}
+	}
+
+	if recursive && (listOpts.Page > 0 || listOpts.Limit > 0) {
+		return &CLIResult{
+			Success: false,
+			Code:    "INVALID_ARGS",
+			Message: "--page/--limit cannot be used with --recursive",
+		}
+	}
+	if recursive && listOpts.Category != "" {
+		return &CLIResult{
+			Success: false,
+			Code:    "INVALID_ARGS",
+			Message: "--category cannot be used with --recursive",
+		}
+	}
+	if dirsOnly && filesOnly {
+		return &CLIResult{
+			Success: false,
+			Code:    "INVALID_ARGS",
+			Message: "--dirs-only and --files-only are mutually exclusive",
+		}
+	}
+
+	if dirFid != "" && recursive {
+		return &CLIResult{
+			Success: false,
+			Code:    "INVALID_ARGS",
+			Message: "--fid cannot be used with --recursive",
+		}
+	}
+
+	// --out 边遍历边写入 JSON 文件，仅支持递归列目录
+	if outFile != "" {
+		if !recursive {
+			return &CLIResult{
+				Success: false,
+				Code:    "INVALID_ARGS",
+				Message: "--out requires --recursive",
+			}
+		}
+		if tableMode || csvMode || csvFile != "" || dirsOnly || filesOnly || nameContains != "" {
+			return &CLIResult{
+				Success: false,
+				Code:    "INVALID_ARGS",
+				Message: "--out cannot be used with --format table|csv, --csv or filters",
+			}
+		}
+		return exportTreeJSON(client, dirPath, maxDepth, false, outFile)
+	}
+
+	// 流式模式下自动翻页时边拉取边输出，不在内存中保留整个目录
+	if streamMode && !tableMode && !csvMode && csvFile == "" && !recursive && listOpts.Page == 0 && listOpts.Limit == 0 {
+		return streamListPages(client, dirPath, dirFid, listOpts, dirsOnly, filesOnly, nameContains)
+	}
+
+	var response *sdk.StandardResponse
+	var err error
+	if dirFid != "" {
+		response, err = client.ListByFid(dirFid, listOpts)
+	} else if recursive {
+		response, err = client.ListRecursive(dirPath, maxDepth, listOpts)
+	} else {
+		response, err = client.List(dirPath, listOpts)
+	}
+	if err != nil {
+		return &CLIResult{
+			Success: false,
+			Message: err.Error(),
+		}
+	}
+
+	if !response.Success {
+		return &CLIResult{
+			Success: false,
+			Code:    response.Code,
+			Message: response.Message,
+		}
+	}
+
+	// 过滤条目：Data 中 total 保持服务端总数，filtered 为过滤后的数量
+	if dirsOnly || filesOnly || nameContains != "" {
+		if files, ok := response.Data["list"].([]sdk.QuarkFileInfo); ok {
+			filtered := filterFileList(files, dirsOnly, filesOnly, nameContains)
+			response.Data["list"] = filtered
+			response.Data["filtered"] = len(filtered)
+		}
+	}
+
+	// CSV 导出：--csv 写入文件并返回汇总结果，--format csv 输出到 stdout
+	if csvFile != "" || csvMode {
+		files, _ := response.Data["list"].([]sdk.QuarkFileInfo)
+		if csvFile != "" {
+			if err := writeFileCSV(csvFile, files); err != nil {
+				return &CLIResult{
+					Success: false,
+					Code:    "CSV_WRITE_ERROR",
+					Message: fmt.Sprintf("failed to write csv file: %v", err),
+				}
+			}
+			data := map[string]interface{}{
+				"file":  csvFile,
+				"count": len(files),
+			}
+			if failed, ok := response.Data["failed"]; ok {
+				data["failed"] = failed
+			}
+			return &CLIResult{
+				Success: true,
+				Code:    response.Code,
+				Message: fmt.Sprintf("exported %d entries to %s", len(files), csvFile),
+				Data:    data,
+			}
+		}
+		if err := renderFileCSV(os.Stdout, files); err != nil {
+			return &CLIResult{
+				Success: false,
+				Code:    "CSV_WRITE_ERROR",
+				Message: fmt.Sprintf("failed to write csv: %v", err),
+			}
+		}
+		return nil
+	}
+
+	// 表格模式：输出对齐的表格（非终端时为 TSV），递归列出失败的子目录输出到 stderr
+	if tableMode {
+		if files, ok := response.Data["list"].([]sdk.QuarkFileInfo); ok {
+			renderFileTable(os.Stdout, files, recursive)
+			if failed, ok := response.Data["failed"].([]map[string]interface{}); ok {
+				for _, f := range failed {
+					fmt.Fprintf(os.Stderr, "failed to list %v: %v\n", f["path"], f["message"])
+				}
+			}
+			return nil
+		}
+	}
+
+	// 流式模式：每行输出一个文件的 JSON
+	if streamMode {
+		// 从 response.Data 中提取 list 数组
+		// response.Data 的类型是 map[string]interface{}，但 list 字段的实际类型是 []sdk.QuarkFileInfo
+		if quarkFileInfos, ok := response.Data["list"].([]sdk.QuarkFileInfo); ok {
+			// 将 QuarkFileInfo 转换为 map[string]interface{} 并逐行输出
+			for _, qfi := range quarkFileInfos {
+				fileResult := &CLIResult{
+					Success: true,
+					Code:    response.Code,
+					Message: "OK",
+					Data:    streamFileData(qfi),
+				}
+				outputStreamJSON(fileResult)
+			}
+			// 递归模式下列目录失败的子目录逐行输出失败结果
+			if failed, ok := response.Data["failed"].([]map[string]interface{}); ok {
+				for _, f := range failed {
+					code, _ := f["code"].(string)
+					message, _ := f["message"].(string)
+					outputStreamJSON(&CLIResult{
+						Success: false,
+						Code:    code,
+						Message: message,
+						Data:    map[string]interface{}{"fid": f["fid"], "path": f["path"]},
+					})
+				}
+			}
+			// 流式模式下不返回结果，已经逐行输出
+			return nil
+		}
+		// 如果无法提取 list，回退到普通模式
+	}
+
+	return &CLIResult{
+		Success: true,
+		Code:    response.Code,
+		Message: response.Message,
+		Data:    response.Data,
+	}
+}
+
+// streamListPages 按页列出目录并把每页条目逐行输出为 NDJSON，最后输出一行 summary
+// summary 行的 data 含 "type": "summary"，管道模式的下游命令会跳过它
+func streamListPages(client *sdk.QuarkClient, dirPath, dirFid string, listOpts sdk.ListOptions, dirsOnly, filesOnly bool, nameContains string) *CLIResult {
+	target := dirPath
+	if dirFid != "" {
+		target = dirFid
+	}
+	filter := dirsOnly || filesOnly || nameContains != ""
+	output := 0
+	response, err := client.ListPages(target, listOpts, func(files []sdk.QuarkFileInfo) bool {
+		if filter {
+			files = filterFileList(files, dirsOnly, filesOnly, nameContains)
+		}
+		for _, qfi := range files {
+			outputStreamJSON(&CLIResult{
+				Success: true,
+				Code:    "OK",
+				Message: "OK",
+				Data:    streamFileData(qfi),
+			})
+		}
+		output += len(files)
+		return true
+	})
+	if err != nil {
+		return &CLIResult{
+			Success: false,
+			Message: err.Error(),
+		}
+	}
+	if !response.Success {
+		// 已输出的条目保持不变，失败结果作为最后一行输出
+		outputStreamJSON(&CLIResult{
+			Success: false,
+			Code:    response.Code,
+			Message: response.Message,
+		})
+		os.Exit(ExitError)
+	}
+
+	summary := map[string]interface{}{
+		"type":     "summary",
+		"count":    output,
+		"total":    response.Data["total"],
+		"has_more": response.Data["has_more"],
+	}
+	if filter {
+		summary["filtered"] = output
+	}
+	outputStreamJSON(&CLIResult{
+		Success: true,
+		Code:    response.Code,
+		Message: response.Message,
+		Data:    summary,
+	})
+	return nil
+}
+
+// handleTree 处理目录树命令
+// 用法: tree [path] [--max-depth N] [--out <file>]
+func handleTree(client *sdk.QuarkClient, args []string) *CLIResult {
+	dirPath := "/"
+	maxDepth := 0
+	outFile := ""
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--out":
+			if i+1 >= len(args) || args[i+1] == "" {
+				return &CLIResult{
+					Success: false,
+					Code:    "INVALID_ARGS",
+					Message: "missing file path for --out",
+				}
+			}
+			outFile = args[i+1]
+			i++
+		case "--max-depth":
+			if i+1 >= len(args) {
+				return &CLIResult{
+					Success: false,
+					Code:    "INVALID_ARGS",
+					Message: "missing value for --max-depth",
+				}
+			}
+			depth, err := strconv.Atoi(args[i+1])
+			if err != nil || depth < 1 {
+				return &CLIResult{
+					Success: false,
+					Code:    "INVALID_ARGS",
+					Message: "invalid --max-depth value, must be a positive integer",
+				}
+			}
+			maxDepth = depth
+			i++
+		default:
+			dirPath = args[i]
+		}
+	}
+
+	// --out 边遍历边写入文件，stdout 只输出汇总
+	if outFile != "" {
+		return exportTreeJSON(client, dirPath, maxDepth, true, outFile)
+	}
+
+	response, err := client.ListTree(dirPath, maxDepth)
+	if err != nil {
+		return &CLIResult{
+			Success: false,
+			Message: err.Error(),
+		}
+	}
+	if !response.Success {
+		return &CLIResult{
+			Success: false,
+			Code:    response.Code,
+			Message: response.Message,
+		}
+	}
+
+	return &CLIResult{
+		Success: true,
+		Code:    response.Code,
+		Message: response.Message,
+		Data:    response.Data,
+	}
+}
+
+// handleDu 处理目录占用统计命令
+// 用法: du [path] [--depth 1]
+func handleDu(client *sdk.QuarkClient, args []string) *CLIResult {
+	dirPath := "/"
+	groupDepth := 0
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--depth":
+			if i+1 >= len(args) {
+				return &CLIResult{
+					Success: false,
+					Code:    "INVALID_ARGS",
+					Message: "missing value for --depth",
+				}
+			}
+			depth, err := strconv.Atoi(args[i+1])
+			if err != nil || depth < 0 || depth > 1 {
+				return &CLIResult{
+					Success: false,
+					Code:    "INVALID_ARGS",
+					Message: "invalid --depth value, must be 0 or 1",
+				}
+			}
+			groupDepth = depth
+			i++
+		default:
+			dirPath = args[i]
+		}
+	}
+
+	response, err := client.DiskUsage(dirPath, groupDepth)
+	if err != nil {
+		return &CLIResult{
+			Success: false,
+			Message: err.Error(),
+		}
+	}
+	if !response.Success {
+		return &CLIResult{
+			Success: false,
+			Code:    response.Code,
+			Message: response.Message,
+		}
+	}
+
+	return &CLIResult{
+		Success: true,
+		Code:    response.Code,
+		Message: response.Message,
+		Data:    response.Data,
+	}
+}
+
+// handleFind 处理按修改时间和大小查找命令，遍历由 SDK 的 Walk 完成，这里只做过滤和格式化
+// 用法: find [path] [--newer-than T] [--older-than T] [--min-size S] [--max-size S] [--max-depth N] [--stream]
+func handleFind(client *sdk.QuarkClient, args []string) *CLIResult {
+	dirPath := "/"
+	maxDepth := 0
+	streamMode := false
+	var newerThan, olderThan time.Time
+	minSize, maxSize := int64(-1), int64(-1)
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch arg {
+		case "--stream", "-s":
+			streamMode = true
+		case "--newer-than", "--older-than":
+			if i+1 >= len(args) {
+				return &CLIResult{
+					Success: false,
+					Code:    "INVALID_ARGS",
+					Message: fmt.Sprintf("missing value for %s", arg),
+				}
+			}
+			t, err := parseTimeArg(args[i+1], time.Now())
+			if err != nil {
+				return &CLIResult{
+					Success: false,
+					Code:    "INVALID_ARGS",
+					Message: fmt.Sprintf("invalid %s value: %v", arg, err),
+				}
+			}
+			if arg == "--newer-than" {
+				newerThan = t
+			} else {
+				olderThan = t
+			}
+			i++
+		case "--min-size", "--max-size":
+			if i+1 >= len(args) {
+				return &CLIResult{
+					Success: false,
+					Code:    "INVALID_ARGS",
+					Message: fmt.Sprintf("missing value for %s", arg),
+				}
+			}
+			size, err := parseSizeArg(args[i+1])
+			if err != nil {
+				return &CLIResult{
+					Success: false,
+					Code:    "INVALID_ARGS",
+					Message: fmt.Sprintf("invalid %s value: %v", arg, err),
+				}
+			}
+			if arg == "--min-size" {
+				minSize = size
+			} else {
+				maxSize = size
+			}
+			i++
+		case "--max-depth":
+			if i+1 >= len(args) {
+				return &CLIResult{
+					Success: false,
+					Code:    "INVALID_ARGS",
+					Message: "missing value for --max-depth",
+				}
+			}
+			depth, err := strconv.Atoi(args[i+1])
+			if err != nil || depth < 1 {
+				return &CLIResult{
+					Success: false,
+					Code:    "INVALID_ARGS",
+					Message: "invalid --max-depth value, must be a positive integer",
+				}
+			}
+			maxDepth = depth
+			i++
+		default:
+			dirPath = arg
+		}
+	}
+
+	sizeFilter := minSize >= 0 || maxSize >= 0
+	matched := make([]sdk.QuarkFileInfo, 0)
+	response, err := client.Walk(dirPath, maxDepth, func(file sdk.QuarkFileInfo) error {
+		mtime := time.Unix(file.ModifyTime, 0)
+		if !newerThan.IsZero() && !mtime.After(newerThan) {
+			return nil
+		}
+		if !olderThan.IsZero() && !mtime.Before(olderThan) {
+			return nil
+		}
+		if sizeFilter {
+			// 目录的 size 恒为 0，大小过滤只匹配文件
+			if file.IsDirectory || (minSize >= 0 && file.Size < minSize) || (maxSize >= 0 && file.Size > maxSize) {
+				return nil
+			}
+		}
+		matched = append(matched, file)
+		return nil
+	})
+	if err != nil {
+		return &CLIResult{
+			Success: false,
+			Message: err.Error(),
+		}
+	}
+	if !response.Success {
+		return &CLIResult{
+			Success: false,
+			Code:    response.Code,
+			Message: response.Message,
+		}
+	}
+
+	if streamMode {
+		for _, file := range matched {
+			outputStreamJSON(&CLIResult{
+				Success: true,
+				Code:    response.Code,
+				Message: "OK",
+				Data:    streamFileData(file),
+			})
+		}
+		return nil
+	}
+
+	return &CLIResult{
+		Success: true,
+		Code:    response.Code,
+		Message: response.Message,
+		Data: map[string]interface{}{
+			"list":    matched,
+			"total":   len(matched),
+			"scanned": response.Data["visited"],
+			"failed":  response.Data["failed"],
+		},
+	}
+}
+
+// parseTimeArg 解析时间参数：Go duration（如 72h，表示 now 之前）、天数（如 7d）或日期（2006-01-02、RFC3339）
+func parseTimeArg(value string, now time.Time) (time.Time, error) {
+	if strings.HasSuffix(value, "d") {
+		if days, err := strconv.Atoi(strings.TrimSuffix(value, "d")); err == nil && days >= 0 {
+			return now.AddDate(0, 0, -days), nil
+		}
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		if d < 0 {
+			return time.Time{}, fmt.Errorf("duration must not be negative: %s", value)
+		}
+		return now.Add(-d), nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	for _, layout := range []string{"2006-01-02 15:04:05", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("expected duration (72h), days (7d) or date (2006-01-02), got %q", value)
+}
+
+// parseSizeArg 解析大小参数：字节数或带 K/M/G/T 后缀（1024 进制，可带 B/iB，如 100M、1.5GB）
+func parseSizeArg(value string) (int64, error) {
+	return sdk.ParseByteSize(value)
+}
+
+// handleRecent 处理最近修改文件命令
+// 用法: recent [N] [--path <dir>]
+func handleRecent(client *sdk.QuarkClient, args []string) *CLIResult {
+	dirPath := "/"
+	limit := sdk.DEFAULT_RECENT_LIMIT
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--path":
+			if i+1 >= len(args) || args[i+1] == "" {
+				return &CLIResult{
+					Success: false,
+					Code:    "INVALID_ARGS",
+					Message: "missing value for --path",
+				}
+			}
+			dirPath = args[i+1]
+			i++
+		default:
+			n, err := strconv.Atoi(args[i])
+			if err != nil || n < 1 {
+				return &CLIResult{
+					Success: false,
+					Code:    "INVALID_ARGS",
+					Message: fmt.Sprintf("invalid count %q, must be a positive integer", args[i]),
+				}
+			}
+			limit = n
+		}
+	}
+
+	response, err := client.Recent(dirPath, limit)
+	if err != nil {
+		return &CLIResult{
+			Success: false,
+			Message: err.Error(),
+		}
+	}
+	if !response.Success {
+		return &CLIResult{
+			Success: false,
+			Code:    response.Code,
+			Message: response.Message,
+		}
+	}
+
+	return &CLIResult{
+		Success: true,
+		Code:    response.Code,
+		Message: response.Message,
+		Data:    response.Data,
+	}
+}
+
+// handleSearch 处理搜索命令
+// 用法: search <keyword> [--page N] [--size N]
+func handleSearch(client *sdk.QuarkClient, args []string) *CLIResult {
+	keyword := ""
+	page, size := 1, 0
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch arg {
+		case "--page", "--size":
+			if i+1 >= len(args) {
+				return &CLIResult{
+					Success: false,
+					Code:    "INVALID_ARGS",
+					Message: fmt.Sprintf("missing value for %s", arg),
+				}
+			}
+			n, err := strconv.Atoi(args[i+1])
+			if err != nil || n < 1 {
+				return &CLIResult{
+					Success: false,
+					Code:    "INVALID_ARGS",
+					Message: fmt.Sprintf("invalid %s value, must be a positive integer", arg),
+				}
+			}
+			if arg == "--page" {
+				page = n
+			} else {
+				size = n
+			}
+			i++
+		default:
+			keyword = arg
+		}
+	}
+	if keyword == "" {
+		return &CLIResult{
+			Success: false,
+			Code:    "INVALID_ARGS",
+			Message: "search requires a keyword: search <keyword> [--page N] [--size N]",
+		}
+	}
+
+	response, err := client.Search(keyword, page, size)
+	if err != nil {
+		return &CLIResult{
+			Success: false,
+			Message: err.Error(),
+		}
+	}
+	if !response.Success {
+		return &CLIResult{
+			Success: false,
+			Code:    response.Code,
+			Message: response.Message,
+		}
+	}
+
+	return &CLIResult{
+		Success: true,
+		Code:    response.Code,
+		Message: response.Message,
+		Data:    response.Data,
+	}
+}
+
+// handleExists 处理判断文件是否存在命令，返回结果和退出码
+// 用法: exists <path> | exists --fid <fid>
+func handleExists(client *sdk.QuarkClient, args []string) (*CLIResult, int) {
+	var infoOpts sdk.FileInfoOptions
+	rest := make([]string, 0, len(args))
+	for _, arg := range args {
+		if arg == "--icase" {
+			infoOpts.IgnoreCase = true
+			continue
+		}
+		rest = append(rest, arg)
+	}
+	args = rest
+
+	if len(args) < 1 || (args[0] == "--fid" && (len(args) < 2 || args[1] == "")) {
+		return &CLIResult{
+			Success: false,
+			Code:    "INVALID_ARGS",
+			Message: "Usage: exists <path> [--icase] | exists --fid <fid>",
+		}, ExitQueryError
+	}
+
+	var response *sdk.StandardResponse
+	var err error
+	target := args[0]
+	if args[0] == "--fid" {
+		target = args[1]
+		response, err = client.GetFileInfoByFid(args[1])
+	} else {
+		response, err = client.GetFileInfoWithOptions(args[0], infoOpts)
+	}
+	if err != nil {
+		return &CLIResult{
+			Success: false,
+			Message: err.Error(),
+		}, ExitQueryError
+	}
+
+	if !response.Success {
+		if response.Code == "FILE_NOT_FOUND" {
+			return &CLIResult{
+				Success: true,
+				Code:    "OK",
+				Message: fmt.Sprintf("not exists: %s", target),
+				Data:    map[string]interface{}{"exists": false, "target": target},
+			}, ExitError
+		}
+		return &CLIResult{
+			Success: false,
+			Code:    response.Code,
+			Message: response.Message,
+			Data:    response.Data,
+		}, ExitQueryError
+	}
+
+	data := map[string]interface{}{"exists": true}
+	for _, key := range []string{"fid", "path", "dir", "size", "mtime"} {
+		if v, ok := response.Data[key]; ok {
+			data[key] = v
+		}
+	}
+	return &CLIResult{
+		Success: true,
+		Code:    "OK",
+		Message: fmt.Sprintf("exists: %s", target),
+		Data:    data,
+	}, ExitSuccess
+}
+
+// filterFileList 按类型和名称过滤文件列表，名称匹配不区分大小写
+func filterFileList(files []sdk.QuarkFileInfo, dirsOnly, filesOnly bool, nameContains string) []sdk.QuarkFileInfo {
+	keyword := strings.ToLower(nameContains)
+	filtered := make([]sdk.QuarkFileInfo, 0, len(files))
+	for _, file := range files {
+		if dirsOnly && !file.IsDirectory {
+			continue
+		}
+		if filesOnly && file.IsDirectory {
+			continue
+		}
+		if keyword != "" && !strings.Contains(strings.ToLower(file.Name), keyword) {
+			continue
+		}
+		filtered = append(filtered, file)
+	}
+	return filtered
 }
 
 // handleInfo 处理获取文件信息命令
 func handleInfo(client *sdk.QuarkClient, args []string) *CLIResult {
+	// --with-share 和 --icase 在管道模式和普通模式下都生效
+	withShare := false
+	var infoOpts sdk.FileInfoOptions
+	rest := make([]string, 0, len(args))
+	for _, arg := range args {
+		switch arg {
+		case "--with-share":
+			withShare = true
+		case "--icase":
+			infoOpts.IgnoreCase = true
+		default:
+			rest = append(rest, arg)
+		}
+	}
+	args = rest
+
 	// 检查是否有 stdin 输入（管道模式）
 	if hasStdinData() {
 		processStdinLines(func(path, fid string) *CLIResult {
-			// 优先使用 path，如果没有则使用 fid
-			targetPath := path
-			if targetPath == "" && fid != "" {
-				// 只有 fid 时，尝试直接使用（某些 API 可能支持）
-				targetPath = fid
-			}
-			
-			if targetPath == "" {
+			// 优先使用 path，如果没有则按 fid 查询
+			if path == "" && fid == "" {
 				return &CLIResult{
 					Success: false,
 					Code:    "INVALID_INPUT",
@@ -712,7 +2454,13 @@ func handleInfo(client *sdk.QuarkClient, args []string) *CLIResult {
 				}
 			}
 
-			response, err := client.GetFileInfo(targetPath)
+			var response *sdk.StandardResponse
+			var err error
+			if path != "" {
+				response, err = client.GetFileInfoWithOptions(path, infoOpts)
+			} else {
+				response, err = client.GetFileInfoByFid(fid)
+			}
 			if err != nil {
 				return &CLIResult{
 					Success: false,
@@ -725,6 +2473,12 @@ func handleInfo(client *sdk.QuarkClient, args []string) *CLIResult {
 					Success: false,
 					Code:    response.Code,
 					Message: response.Message,
+					Data:    response.Data,
+				}
+			}
+			if withShare {
+				if failResult := appendShareStatus(client, response.Data); failResult != nil {
+					return failResult
 				}
 			}
 
@@ -739,17 +2493,59 @@ func handleInfo(client *sdk.QuarkClient, args []string) *CLIResult {
 		return nil
 	}
 
-	// 普通模式：从命令行参数读取
+	// 普通模式：从命令行参数读取，--format 可出现在任意位置
+	tableMode := false
+	rest = make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		if args[i] != "--format" {
+			rest = append(rest, args[i])
+			continue
+		}
+		if i+1 >= len(args) {
+			return &CLIResult{
+				Success: false,
+				Code:    "INVALID_ARGS",
+				Message: "missing value for --format (json/table)",
+			}
+		}
+		switch args[i+1] {
+		case "json":
+			tableMode = false
+		case "table":
+			tableMode = true
+		default:
+			return &CLIResult{
+				Success: false,
+				Code:    "INVALID_ARGS",
+				Message: "invalid --format value, must be 'json' or 'table'",
+			}
+		}
+		i++
+	}
+	args = rest
+
 	if len(args) < 1 {
 		return &CLIResult{
 			Success: false,
 			Code:    "INVALID_ARGS",
-			Message: `Usage: info <path> (path must be quoted, e.g., info 'file(1).txt') or use pipe mode`,
+			Message: `Usage: info <path> | info --fid <fid> [--format json|table] [--with-share] [--icase] (path must be quoted, e.g., info 'file(1).txt') or use pipe mode`,
 		}
 	}
 
-	path := args[0]
-	response, err := client.GetFileInfo(path)
+	var response *sdk.StandardResponse
+	var err error
+	if args[0] == "--fid" {
+		if len(args) < 2 || args[1] == "" {
+			return &CLIResult{
+				Success: false,
+				Code:    "INVALID_ARGS",
+				Message: "missing value for --fid",
+			}
+		}
+		response, err = client.GetFileInfoByFid(args[1])
+	} else {
+		response, err = client.GetFileInfoWithOptions(args[0], infoOpts)
+	}
 	if err != nil {
 		return &CLIResult{
 			Success: false,
@@ -758,13 +2554,26 @@ func handleInfo(client *sdk.QuarkClient, args []string) *CLIResult {
 	}
 
 	if !response.Success {
+		// AMBIGUOUS_PATH 时 Data 中带候选路径
 		return &CLIResult{
 			Success: false,
 			Code:    response.Code,
 			Message: response.Message,
+			Data:    response.Data,
 		}
 	}
 
+	if withShare {
+		if failResult := appendShareStatus(client, response.Data); failResult != nil {
+			return failResult
+		}
+	}
+
+	if tableMode {
+		renderInfoTable(os.Stdout, response.Data)
+		return nil
+	}
+
 	return &CLIResult{
 		Success: true,
 		Code:    response.Code,
@@ -773,6 +2582,28 @@ func handleInfo(client *sdk.QuarkClient, args []string) *CLIResult {
 	}
 }
 
+// appendShareStatus 查询文件的分享状态并追加到 info 结果（shared、share_id、share_url），查询失败时返回失败结果
+func appendShareStatus(client *sdk.QuarkClient, data map[string]interface{}) *CLIResult {
+	fid, _ := data["fid"].(string)
+	if fid == "" || fid == "0" {
+		// 根目录不能分享
+		data["shared"] = false
+		return nil
+	}
+	status, err := client.GetShareStatus(fid)
+	if err != nil {
+		return &CLIResult{
+			Success: false,
+			Code:    "SHARE_STATUS_ERROR",
+			Message: fmt.Sprintf("failed to get share status: %v", err),
+		}
+	}
+	for key, value := range status {
+		data[key] = value
+	}
+	return nil
+}
+
 // handleCreateFolder 处理创建文件夹命令
 func handleCreateFolder(client *sdk.QuarkClient, args []string) *CLIResult {
 	if len(args) < 2 {
@@ -1030,90 +2861,396 @@ func handleDelete(client *sdk.QuarkClient, args []string) *CLIResult {
 		}
 	}
 
-	return &CLIResult{
-		Success: true,
-		Code:    response.Code,
-		Message: response.Message,
-		Data:    response.Data,
+	return &CLIResult{
+		Success: true,
+		Code:    response.Code,
+		Message: response.Message,
+		Data:    response.Data,
+	}
+}
+
+// handleShareCreate 处理创建分享链接命令
+func handleShareCreate(client *sdk.QuarkClient, args []string) *CLIResult {
+	if len(args) < 1 {
+		return &CLIResult{
+			Success: false,
+			Code:    "INVALID_ARGS",
+			Message: "Usage: share <path> [days] [passcode] (path and passcode must be quoted, e.g., share \"file(1).txt\" 7 \"false\")",
+		}
+	}
+
+	path := args[0]
+
+	// 解析有效期天数（可选，默认取配置 defaults.share_days）
+	expireDays := *cliDefaults.ShareDays
+	if len(args) > 1 {
+		days, err := strconv.Atoi(args[1])
+		if err != nil {
+			return &CLIResult{
+				Success: false,
+				Code:    "INVALID_ARGS",
+				Message: "days must be a number",
+			}
+		}
+		expireDays = days
+	}
+
+	// 解析是否需要提取码（可选，默认取配置 defaults.share_passcode）
+	needPasscode := *cliDefaults.SharePasscode
+	if len(args) > 2 {
+		switch args[2] {
+		case "true":
+			needPasscode = true
+		case "false":
+			needPasscode = false
+		default:
+			return &CLIResult{
+				Success: false,
+				Code:    "INVALID_ARGS",
+				Message: "passcode must be 'true' or 'false'",
+			}
+		}
+	}
+
+	shareInfo, err := client.CreateShare(path, expireDays, needPasscode)
+	if err != nil {
+		return &CLIResult{
+			Success: false,
+			Message: err.Error(),
+		}
+	}
+
+	data := map[string]interface{}{
+		"share_url":  shareInfo.ShareURL,
+		"pwd_id":     shareInfo.PwdID,
+		"passcode":   shareInfo.Passcode,
+		"expires_at": shareInfo.ExpiresAt,
+	}
+
+	if shareInfo.ExpiresAt > 0 {
+		expireTime := time.Unix(shareInfo.ExpiresAt/1000, 0)
+		data["expires_at_formatted"] = expireTime.Format("2006-01-02 15:04:05")
+	}
+
+	return &CLIResult{
+		Success: true,
+		Code:    "OK",
+		Message: "Share link created successfully",
+		Data:    data,
+	}
+}
+
+// handleDownload 处理下载命令：download <path> [dest] [--recursive] [--workers N] [--connections N] [--range start-end] | download --fid <fid> [dest]
+// 若提供 dest则下载到本地文件并输出进度；否则仅返回下载链接 JSON；--range 只下载指定的字节范围，服务端不支持 Range 时返回 RANGE_NOT_SUPPORTED
+// --recursive 时目录按相同结构下载到 dest 下，--workers 控制同时下载的文件数；--connections 开启单文件分段并发下载
+// --dest <dir> 时所有位置参数都是远端路径，--from-file 按清单批量下载；下载的文件默认使用远端修改时间作为 mtime（--no-preserve-mtime 关闭）
+func handleDownload(client *sdk.QuarkClient, args []string) *CLIResult {
+	flags := downloadFlags{conflict: sdk.DownloadConflictFail} // 默认不覆盖已有文件，以免误覆盖
+	fileFid := ""
+	destDir := ""
+	hasDestDir := false
+	manifestPath := ""
+	failedOut := ""
+	positional := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--recursive", "-r":
+			flags.recursive = true
+		case "--no-preserve-mtime":
+			flags.noPreserveMtime = true
+		case "--verify":
+			flags.verify = true
+		case "--no-space-check":
+			flags.noSpaceCheck = true
+		case "--restart":
+			flags.restart = true
+		case "--print-headers":
+			flags.printHeaders = true
+		case "--print-cmd":
+			if i+1 >= len(args) || (args[i+1] != "curl" && args[i+1] != "wget") {
+				return &CLIResult{
+					Success: false,
+					Code:    "INVALID_ARGS",
+					Message: "--print-cmd requires curl or wget",
+				}
+			}
+			flags.printCmd = args[i+1]
+			i++
+		case "--aria2-wait":
+			flags.aria2Wait = true
+		case "--aria2", "--aria2-secret":
+			if i+1 >= len(args) || args[i+1] == "" {
+				return &CLIResult{
+					Success: false,
+					Code:    "INVALID_ARGS",
+					Message: fmt.Sprintf("missing value for %s", args[i]),
+				}
+			}
+			if args[i] == "--aria2" {
+				flags.aria2URL = args[i+1]
+			} else {
+				flags.aria2Secret = args[i+1]
+			}
+			i++
+		case "--on-conflict":
+			if i+1 >= len(args) {
+				return &CLIResult{
+					Success: false,
+					Code:    "INVALID_ARGS",
+					Message: "missing value for --on-conflict",
+				}
+			}
+			switch policy := sdk.DownloadConflictPolicy(args[i+1]); policy {
+			case sdk.DownloadConflictOverwrite, sdk.DownloadConflictSkip, sdk.DownloadConflictRename, sdk.DownloadConflictFail:
+				flags.conflict = policy
+			default:
+				return &CLIResult{
+					Success: false,
+					Code:    "INVALID_ARGS",
+					Message: fmt.Sprintf("invalid --on-conflict value %q, must be overwrite, skip, rename or fail", args[i+1]),
+				}
+			}
+			i++
+		case "--from-file", "--failed-out":
+			if i+1 >= len(args) || args[i+1] == "" {
+				return &CLIResult{
+					Success: false,
+					Code:    "INVALID_ARGS",
+					Message: fmt.Sprintf("missing value for %s", args[i]),
+				}
+			}
+			if args[i] == "--from-file" {
+				manifestPath = args[i+1]
+			} else {
+				failedOut = args[i+1]
+			}
+			i++
+		case "--dest":
+			if i+1 >= len(args) || args[i+1] == "" {
+				return &CLIResult{
+					Success: false,
+					Code:    "INVALID_ARGS",
+					Message: "missing value for --dest",
+				}
+			}
+			destDir = args[i+1]
+			hasDestDir = true
+			i++
+		case "--limit-rate":
+			if i+1 >= len(args) {
+				return &CLIResult{
+					Success: false,
+					Code:    "INVALID_ARGS",
+					Message: "missing value for --limit-rate",
+				}
+			}
+			rate, err := parseSizeArg(args[i+1])
+			if err != nil {
+				return &CLIResult{
+					Success: false,
+					Code:    "INVALID_ARGS",
+					Message: fmt.Sprintf("invalid --limit-rate value: %v", err),
+				}
+			}
+			// 同一次命令中所有并发下载共享一个限速器
+			flags.limiter = sdk.NewRateLimiter(rate)
+			i++
+		case "--workers":
+			if i+1 >= len(args) {
+				return &CLIResult{
+					Success: false,
+					Code:    "INVALID_ARGS",
+					Message: "missing value for --workers",
+				}
+			}
+			n, err := strconv.Atoi(args[i+1])
+			if err != nil || n < 1 || n > sdk.MAX_DOWNLOAD_WORKERS {
+				return &CLIResult{
+					Success: false,
+					Code:    "INVALID_ARGS",
+					Message: fmt.Sprintf("invalid --workers value, must be an integer between 1 and %d", sdk.MAX_DOWNLOAD_WORKERS),
+				}
+			}
+			flags.workers = n
+			i++
+		case "--connections":
+			if i+1 >= len(args) {
+				return &CLIResult{
+					Success: false,
+					Code:    "INVALID_ARGS",
+					Message: "missing value for --connections",
+				}
+			}
+			n, err := strconv.Atoi(args[i+1])
+			if err != nil || n < 1 || n > sdk.MAX_DOWNLOAD_CONNECTIONS {
+				return &CLIResult{
+					Success: false,
+					Code:    "INVALID_ARGS",
+					Message: fmt.Sprintf("invalid --connections value, must be an integer between 1 and %d", sdk.MAX_DOWNLOAD_CONNECTIONS),
+				}
+			}
+			flags.connections = n
+			i++
+		case "--range":
+			if i+1 >= len(args) {
+				return &CLIResult{
+					Success: false,
+					Code:    "INVALID_ARGS",
+					Message: "missing value for --range",
+				}
+			}
+			offset, length, err := parseByteRange(args[i+1])
+			if err != nil {
+				return &CLIResult{
+					Success: false,
+					Code:    "INVALID_ARGS",
+					Message: fmt.Sprintf("invalid --range value: %v", err),
+				}
+			}
+			flags.hasRange, flags.offset, flags.length = true, offset, length
+			i++
+		case "--fid":
+			if i+1 >= len(args) || args[i+1] == "" {
+				return &CLIResult{
+					Success: false,
+					Code:    "INVALID_ARGS",
+					Message: "missing value for --fid",
+				}
+			}
+			fileFid = args[i+1]
+			i++
+		default:
+			positional = append(positional, args[i])
+		}
+	}
+	args = positional
+
+	// --range 只用于把单个文件的一段下载到本地文件
+	if flags.hasRange {
+		if msg := rangeFlagConflict(flags, hasDestDir && fileFid == "", manifestPath != ""); msg != "" {
+			return &CLIResult{
+				Success: false,
+				Code:    "INVALID_ARGS",
+				Message: msg,
+			}
+		}
+	}
+
+	// --print-headers / --print-cmd：只输出下载链接和外部下载器需要的请求头（或完整命令），不在本地下载
+	if flags.printHeaders || flags.printCmd != "" {
+		if manifestPath != "" || hasDestDir || flags.recursive || len(args) > 1 || (fileFid == "" && len(args) < 1) {
+			return &CLIResult{
+				Success: false,
+				Code:    "INVALID_ARGS",
+				Message: `Usage: download <path> --print-headers|--print-cmd curl|wget | download --fid <fid> --print-headers|--print-cmd curl|wget (no dest, --dest, --from-file or --recursive)`,
+			}
+		}
+		remotePath := ""
+		if fileFid == "" {
+			remotePath = args[0]
+		}
+		return downloadLinkInfo(client, remotePath, fileFid, flags)
+	}
+
+	// --aria2：把下载任务提交给 aria2，位置参数为远端路径和 aria2 主机上的保存目录（或 --dest）
+	if flags.aria2URL != "" {
+		if manifestPath != "" {
+			return &CLIResult{
+				Success: false,
+				Code:    "INVALID_ARGS",
+				Message: "--aria2 cannot be combined with --from-file",
+			}
+		}
+		paths := args
+		dir := destDir
+		if !hasDestDir {
+			paths, dir = nil, ""
+			if fileFid == "" && len(args) >= 1 {
+				paths = args[:1]
+				args = args[1:]
+			}
+			if len(args) >= 1 {
+				dir = args[0]
+			}
+		} else if fileFid != "" {
+			paths = nil
+		}
+		if fileFid == "" && len(paths) == 0 {
+			return &CLIResult{
+				Success: false,
+				Code:    "INVALID_ARGS",
+				Message: `Usage: download <path> [dir] --aria2 <rpc-url> [--aria2-secret S] [--aria2-wait] [--recursive] | download <path> ... --dest <dir> --aria2 <rpc-url>`,
+			}
+		}
+		return downloadViaAria2(client, paths, fileFid, dir, flags)
 	}
-}
-
-// handleShareCreate 处理创建分享链接命令
-func handleShareCreate(client *sdk.QuarkClient, args []string) *CLIResult {
-	if len(args) < 3 {
+	if flags.aria2Secret != "" || flags.aria2Wait {
 		return &CLIResult{
 			Success: false,
 			Code:    "INVALID_ARGS",
-			Message: "Usage: share <path> <days> <passcode> (path and passcode must be quoted, e.g., share \"file(1).txt\" 7 \"false\")",
+			Message: "--aria2-secret and --aria2-wait require --aria2",
 		}
 	}
 
-	path := args[0]
-
-	// 解析有效期天数（必传）
-	expireDays, err := strconv.Atoi(args[1])
-	if err != nil {
-		return &CLIResult{
-			Success: false,
-			Code:    "INVALID_ARGS",
-			Message: "days must be a number",
+	// --from-file：按清单批量下载，位置参数（或 --dest）为本地目标目录
+	if manifestPath != "" {
+		target := destDir
+		if !hasDestDir {
+			target = strings.TrimRight(defaultDownloadDest(), "/\\")
+			if len(args) >= 1 {
+				target = args[0]
+			}
 		}
+		return downloadFromManifest(client, manifestPath, target, failedOut, flags)
 	}
-
-	// 解析是否需要提取码（必传）
-	passcodeArg := args[2]
-	var needPasscode bool
-	switch passcodeArg {
-	case "true":
-		needPasscode = true
-	case "false":
-		needPasscode = false
-	default:
+	if failedOut != "" {
 		return &CLIResult{
 			Success: false,
 			Code:    "INVALID_ARGS",
-			Message: "passcode must be 'true' or 'false'",
+			Message: "--failed-out requires --from-file",
 		}
 	}
 
-	shareInfo, err := client.CreateShare(path, expireDays, needPasscode)
-	if err != nil {
-		return &CLIResult{
-			Success: false,
-			Message: err.Error(),
+	// --dest：所有位置参数都是远端路径，统一下载到该目录
+	if hasDestDir && fileFid == "" {
+		if len(args) < 1 {
+			return &CLIResult{
+				Success: false,
+				Code:    "INVALID_ARGS",
+				Message: `Usage: download <path> [path2] ... --dest <dir> [--recursive] [--workers N] [--connections N]`,
+			}
 		}
+		return downloadMultiple(client, args, destDir, flags)
 	}
 
-	data := map[string]interface{}{
-		"share_url":  shareInfo.ShareURL,
-		"pwd_id":     shareInfo.PwdID,
-		"passcode":   shareInfo.Passcode,
-		"expires_at": shareInfo.ExpiresAt,
-	}
-
-	if shareInfo.ExpiresAt > 0 {
-		expireTime := time.Unix(shareInfo.ExpiresAt/1000, 0)
-		data["expires_at_formatted"] = expireTime.Format("2006-01-02 15:04:05")
-	}
-
-	return &CLIResult{
-		Success: true,
-		Code:    "OK",
-		Message: "Share link created successfully",
-		Data:    data,
+	// --fid：跳过路径解析，直接按 fid 获取下载信息
+	if fileFid != "" {
+		destPath := defaultDownloadDest()
+		if len(args) >= 1 {
+			destPath = args[0]
+		}
+		if hasDestDir {
+			destPath = strings.TrimRight(destDir, "/\\") + string(filepath.Separator)
+		}
+		if flags.hasRange && destPath == "" {
+			return rangeNeedsDestResult()
+		}
+		return downloadByFid(client, fileFid, destPath, flags)
 	}
-}
 
-// handleDownload 处理下载命令：download <path> [dest]
-// 若提供 dest则下载到本地文件并输出进度；否则仅返回下载链接 JSON
-func handleDownload(client *sdk.QuarkClient, args []string) *CLIResult {
 	// 检查是否有 stdin 输入（管道模式）
 	destPath := ""
 	if len(args) >= 1 {
 		destPath = args[0] // 管道模式下，第一个参数可能是 dest
 	}
+	if destPath == "" {
+		destPath = defaultDownloadDest() // 未指定 dest 时使用配置 defaults.download_dir
+	}
 
 	if hasStdinData() {
+		if flags.hasRange && destPath == "" {
+			return rangeNeedsDestResult()
+		}
 		processStdinLines(func(path, fid string) *CLIResult {
 			// 优先使用 path，如果没有则使用 fid
 			targetPath := path
@@ -1155,6 +3292,13 @@ func handleDownload(client *sdk.QuarkClient, args []string) *CLIResult {
 			}
 
 			isDir, _ := fileInfo.Data["dir"].(bool)
+			if isDir && flags.recursive {
+				filePath, _ := fileInfo.Data["path"].(string)
+				if filePath == "" {
+					filePath = targetPath
+				}
+				return downloadDirectory(client, filePath, destPath, flags)
+			}
 			if isDir {
 				return &CLIResult{
 					Success: false,
@@ -1173,45 +3317,8 @@ func handleDownload(client *sdk.QuarkClient, args []string) *CLIResult {
 
 			// 如果提供了 dest，下载到本地
 			if destPath != "" {
-				var lastProgress *sdk.DownloadProgress
-				var lastPrint time.Time
-				err = client.DownloadFile(fileFid, destPath, fileName, func(p *sdk.DownloadProgress) {
-					lastProgress = p
-					now := time.Now()
-					if now.Sub(lastPrint) < 500*time.Millisecond && p.Total >= 0 && p.Downloaded < p.Total {
-						return
-					}
-					lastPrint = now
-					if p.Total > 0 {
-						pct := float64(p.Downloaded) / float64(p.Total) * 100
-						fmt.Fprintf(os.Stderr, "\rDownloaded %.2f MB / %.2f MB (%.1f%%)", float64(p.Downloaded)/(1024*1024), float64(p.Total)/(1024*1024), pct)
-					} else {
-						fmt.Fprintf(os.Stderr, "\rDownloaded %.2f MB", float64(p.Downloaded)/(1024*1024))
-					}
-				})
-				if err != nil {
-					return &CLIResult{
-						Success: false,
-						Message: fmt.Sprintf("download failed: %v", err),
-					}
-				}
-				if lastProgress != nil && lastProgress.Total > 0 {
-					fmt.Fprintf(os.Stderr, "\rDownloaded %.2f MB / %.2f MB (100.0%%)\n", float64(lastProgress.Downloaded)/(1024*1024), float64(lastProgress.Total)/(1024*1024))
-				} else {
-					fmt.Fprintf(os.Stderr, "\n")
-				}
-				localPath := destPath
-				if destPath == "" || destPath == "." || strings.HasSuffix(destPath, "/") || strings.HasSuffix(destPath, string(filepath.Separator)) {
-					localPath = filepath.Join(destPath, fileName)
-				} else if info, err := os.Stat(destPath); err == nil && info.IsDir() {
-					localPath = filepath.Join(destPath, fileName)
-				}
-				return &CLIResult{
-					Success: true,
-					Code:    "OK",
-					Message: "File downloaded successfully",
-					Data:    map[string]interface{}{"local_path": localPath, "path": targetPath},
-				}
+				localPath, err := downloadToLocal(client, fileFid, destPath, fileName, flags.fileOptions(fileInfo.Data))
+				return localDownloadResult(localPath, err, map[string]interface{}{"path": targetPath})
 			}
 
 			// 未指定 dest：仅返回下载链接
@@ -1238,15 +3345,18 @@ func handleDownload(client *sdk.QuarkClient, args []string) *CLIResult {
 		return &CLIResult{
 			Success: false,
 			Code:    "INVALID_ARGS",
-			Message: `Usage: download <path> [dest] (path must be quoted, e.g., download "/file.txt" or download "/file.txt" ./local) or use pipe mode`,
+			Message: `Usage: download <path> [dest] [--recursive] [--workers N] [--connections N] [--no-preserve-mtime] [--on-conflict overwrite|skip|rename|fail] [--verify] [--no-space-check] [--restart] [--limit-rate R] [--range start-end] (path must be quoted, e.g., download "/file.txt" or download "/file.txt" ./local) or use pipe mode`,
 		}
 	}
 
 	path := args[0]
-	destPath = ""
+	destPath = defaultDownloadDest()
 	if len(args) >= 2 {
 		destPath = args[1]
 	}
+	if flags.hasRange && destPath == "" {
+		return rangeNeedsDestResult()
+	}
 
 	fileInfo, err := client.GetFileInfo(path)
 	if err != nil {
@@ -1273,80 +3383,457 @@ func handleDownload(client *sdk.QuarkClient, args []string) *CLIResult {
 	}
 
 	isDir, _ := fileInfo.Data["dir"].(bool)
+	if isDir && flags.recursive {
+		return downloadDirectory(client, path, destPath, flags)
+	}
 	if isDir {
 		return &CLIResult{
 			Success: false,
-			Code:    "INVALID_FILE_TYPE",
-			Message: "cannot download directory",
+			Code:    "INVALID_FILE_TYPE",
+			Message: "cannot download directory (use --recursive)",
+		}
+	}
+
+	fileName, _ := fileInfo.Data["file_name"].(string)
+	if fileName == "" {
+		fileName = filepath.Base(path)
+	}
+	if fileName == "" || fileName == "." {
+		fileName = "download"
+	}
+
+	// 指定了 dest：下载到本地
+	if destPath != "" {
+		localPath, err := downloadToLocal(client, fid, destPath, fileName, flags.fileOptions(fileInfo.Data))
+		return localDownloadResult(localPath, err, map[string]interface{}{"path": path})
+	}
+
+	// 未指定 dest：仅返回下载链接
+	downloadURL, err := client.GetDownloadURL(fid)
+	if err != nil {
+		return &CLIResult{
+			Success: false,
+			Message: fmt.Sprintf("failed to get download URL: %v", err),
+		}
+	}
+	return &CLIResult{
+		Success: true,
+		Code:    "OK",
+		Message: "Download URL retrieved successfully",
+		Data:    map[string]interface{}{"fid": fid, "path": path, "download_url": downloadURL},
+	}
+}
+
+// downloadByFid 按 fid 下载：直接请求下载接口并使用其返回的文件名，不查询文件信息
+// 指定 destPath 时下载到本地，否则仅返回下载链接；fid 指向目录时返回 INVALID_FILE_TYPE
+func downloadByFid(client *sdk.QuarkClient, fid, destPath string, flags downloadFlags) *CLIResult {
+	info, err := client.GetDownloadInfo(fid)
+	if errors.Is(err, sdk.ErrDownloadDirectory) {
+		return &CLIResult{
+			Success: false,
+			Code:    "INVALID_FILE_TYPE",
+			Message: fmt.Sprintf("cannot download directory: %s", fid),
+		}
+	}
+	if err != nil {
+		return &CLIResult{
+			Success: false,
+			Message: fmt.Sprintf("failed to get download URL: %v", err),
+		}
+	}
+	fileName := info.FileName
+	if fileName == "" {
+		fileName = fid
+	}
+
+	if destPath == "" {
+		return &CLIResult{
+			Success: true,
+			Code:    "OK",
+			Message: "Download URL retrieved successfully",
+			Data:    map[string]interface{}{"fid": fid, "file_name": fileName, "size": info.Size, "download_url": info.DownloadURL},
+		}
+	}
+	opts := flags.fileOptions(map[string]interface{}{"mtime": info.ModifyTime})
+	opts.URL = info.DownloadURL
+	localPath, err := downloadToLocal(client, fid, destPath, fileName, opts)
+	return localDownloadResult(localPath, err, map[string]interface{}{"fid": fid, "file_name": fileName})
+}
+
+// downloadLinkInfo 返回 remotePath（或 fid）的下载链接，--print-headers 时附带 aria2、IDM 等外部下载器请求时必须的请求头，
+// --print-cmd 时附带可直接执行的 curl/wget 命令；请求头中的 Cookie 含登录凭证，只在显式指定这两个参数时输出
+func downloadLinkInfo(client *sdk.QuarkClient, remotePath, fid string, flags downloadFlags) *CLIResult {
+	if fid == "" {
+		fileInfo, err := client.GetFileInfo(remotePath)
+		if err != nil {
+			return &CLIResult{
+				Success: false,
+				Message: fmt.Sprintf("failed to get file info: %v", err),
+			}
+		}
+		if !fileInfo.Success {
+			return &CLIResult{
+				Success: false,
+				Code:    fileInfo.Code,
+				Message: fileInfo.Message,
+			}
+		}
+		if isDir, _ := fileInfo.Data["dir"].(bool); isDir {
+			return &CLIResult{
+				Success: false,
+				Code:    "INVALID_FILE_TYPE",
+				Message: "cannot download directory",
+			}
+		}
+		fid, _ = fileInfo.Data["fid"].(string)
+	}
+
+	info, err := client.GetDownloadInfo(fid)
+	if errors.Is(err, sdk.ErrDownloadDirectory) {
+		return &CLIResult{
+			Success: false,
+			Code:    "INVALID_FILE_TYPE",
+			Message: fmt.Sprintf("cannot download directory: %s", fid),
+		}
+	}
+	if err != nil {
+		return &CLIResult{
+			Success: false,
+			Message: fmt.Sprintf("failed to get download URL: %v", err),
+		}
+	}
+	headers := client.DownloadHeaders()
+	data := map[string]interface{}{
+		"fid":          fid,
+		"file_name":    info.FileName,
+		"size":         info.Size,
+		"download_url": info.DownloadURL,
+	}
+	if remotePath != "" {
+		data["path"] = remotePath
+	}
+	message := "Download URL and headers retrieved successfully"
+	if flags.printHeaders {
+		data["headers"] = headers
+	}
+	if flags.printCmd != "" {
+		data["command"] = downloadCommand(flags.printCmd, info.DownloadURL, info.FileName, headers)
+		message = fmt.Sprintf("%s command generated successfully", flags.printCmd)
+	}
+	return &CLIResult{
+		Success: true,
+		Code:    "OK",
+		Message: message,
+		Data:    data,
+	}
+}
+
+// downloadCommand 生成下载到 fileName 的 curl 或 wget 命令，请求头按名称排序，所有参数按 POSIX shell 规则转义
+func downloadCommand(tool, downloadURL, fileName string, headers map[string]string) string {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var parts []string
+	if tool == "wget" {
+		parts = append(parts, "wget", "-O", shellQuote(fileName))
+		for _, name := range names {
+			parts = append(parts, shellQuote("--header="+name+": "+headers[name]))
+		}
+	} else {
+		parts = append(parts, "curl", "-L", "-o", shellQuote(fileName))
+		for _, name := range names {
+			parts = append(parts, "-H", shellQuote(name+": "+headers[name]))
+		}
+	}
+	parts = append(parts, shellQuote(downloadURL))
+	return strings.Join(parts, " ")
+}
+
+// shellQuote 用单引号包裹 s 作为单个 shell 参数，内部的单引号转义为 '\''；空格、双引号、$、中文等在单引号内都按原样传递
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// downloadFlags download 命令中影响下载方式的参数
+type downloadFlags struct {
+	recursive       bool                       // --recursive：递归下载目录
+	workers         int                        // --workers：同时下载的文件数
+	connections     int                        // --connections：单文件分段并发连接数
+	noPreserveMtime bool                       // --no-preserve-mtime：本地 mtime 保留为下载时间
+	conflict        sdk.DownloadConflictPolicy // --on-conflict：本地文件已存在时的处理方式
+	verify          bool                       // --verify：下载完成后校验大小和哈希
+	noSpaceCheck    bool                       // --no-space-check：不检查目标分区剩余空间
+	restart         bool                       // --restart：忽略目录/批量下载的断点状态文件，重新下载全部文件
+	limiter         *sdk.RateLimiter           // --limit-rate：所有下载共享的限速器，nil 表示不限速
+	printHeaders    bool                       // --print-headers：只输出下载链接和请求头
+	printCmd        string                     // --print-cmd：只输出 curl 或 wget 下载命令
+	aria2URL        string                     // --aria2：aria2 JSON-RPC 地址，设置时提交任务给 aria2 而不在本地下载
+	aria2Secret     string                     // --aria2-secret：aria2 的 RPC 密钥
+	aria2Wait       bool                       // --aria2-wait：提交后轮询 aria2 任务直到完成
+	hasRange        bool                       // --range：只下载指定的字节范围
+	offset          int64                      // --range 的起始字节
+	length          int64                      // --range 的字节数，0 表示到文件末尾
+}
+
+// fileOptions 返回单文件下载选项，data 为文件信息（取其中的 mtime 作为本地修改时间）
+func (f downloadFlags) fileOptions(data map[string]interface{}) sdk.DownloadOptions {
+	opts := sdk.DownloadOptions{Connections: f.connections, Conflict: f.conflict, Verify: f.verify, NoSpaceCheck: f.noSpaceCheck, RateLimiter: f.limiter, Offset: f.offset, Length: f.length}
+	if !f.noPreserveMtime {
+		opts.ModTime, _ = data["mtime"].(int64)
+	}
+	return opts
+}
+
+// parseByteRange 解析 --range 的值：start-end（包含 end）或 start-（到文件末尾），返回起始位置和长度（0 表示到末尾）
+func parseByteRange(s string) (int64, int64, error) {
+	startStr, endStr, ok := strings.Cut(s, "-")
+	if !ok || startStr == "" {
+		return 0, 0, fmt.Errorf("%q must be start-end or start-", s)
+	}
+	start, err := strconv.ParseInt(startStr, 10, 64)
+	if err != nil || start < 0 {
+		return 0, 0, fmt.Errorf("invalid start %q", startStr)
+	}
+	if endStr == "" {
+		return start, 0, nil
+	}
+	end, err := strconv.ParseInt(endStr, 10, 64)
+	if err != nil || end < start {
+		return 0, 0, fmt.Errorf("invalid end %q, must be an integer not less than start", endStr)
+	}
+	return start, end - start + 1, nil
+}
+
+// rangeFlagConflict 返回 --range 与其他参数冲突时的错误信息，没有冲突时返回空字符串
+// multi 为 --dest 批量下载，manifest 为 --from-file
+func rangeFlagConflict(f downloadFlags, multi, manifest bool) string {
+	switch {
+	case f.recursive || multi || manifest:
+		return "--range can only be used to download a single file (no --recursive, --dest or --from-file)"
+	case f.aria2URL != "" || f.printHeaders || f.printCmd != "":
+		return "--range cannot be combined with --aria2, --print-headers or --print-cmd"
+	case f.verify:
+		return "--range cannot be combined with --verify (remote hashes cover the whole file)"
+	case f.connections > 1:
+		return "--range cannot be combined with --connections"
+	}
+	return ""
+}
+
+// rangeNeedsDestResult --range 未指定本地目标（也没有配置默认下载目录）时的错误结果
+func rangeNeedsDestResult() *CLIResult {
+	return &CLIResult{
+		Success: false,
+		Code:    "INVALID_ARGS",
+		Message: "--range requires a local dest, e.g., download \"/file.bin\" part.bin --range 0-1048575",
+	}
+}
+
+// dirOptions 返回目录/批量下载选项（不含回调）
+func (f downloadFlags) dirOptions() sdk.DownloadDirOptions {
+	return sdk.DownloadDirOptions{
+		Workers:         f.workers,
+		Connections:     f.connections,
+		NoPreserveMtime: f.noPreserveMtime,
+		Verify:          f.verify,
+		NoSpaceCheck:    f.noSpaceCheck,
+		RateLimiter:     f.limiter,
+		Conflict:        f.conflict,
+		Restart:         f.restart,
+	}
+}
+
+// downloadToLocal 下载文件到 destPath 并在 stderr 输出进度，返回最终的本地路径
+func downloadToLocal(client *sdk.QuarkClient, fid, destPath, fileName string, opts sdk.DownloadOptions) (string, error) {
+	if cliProgress == progressJSON {
+		events := newJSONProgress("download", fileName)
+		var last sdk.DownloadProgress
+		opts.Progress = func(p *sdk.DownloadProgress) {
+			last = *p
+			phase := ""
+			if p.Phase == sdk.DownloadPhaseVerify {
+				phase = p.Phase
+			}
+			if !p.Done {
+				events.update(phase, p.Downloaded, p.Total, p.Speed, p.ETA)
+			}
+		}
+		localPath, err := client.DownloadFileToPath(fid, destPath, fileName, opts)
+		skipped := errors.Is(err, sdk.ErrDownloadSkipped)
+		if skipped {
+			events.done(0, -1, 0, 0, true, nil)
+		} else {
+			events.done(last.Downloaded, last.Total, doneSpeed(&last), doneElapsed(&last), false, err)
+		}
+		return localPath, err
+	}
+
+	var lastProgress *sdk.DownloadProgress
+	progress := newTextProgress()
+	opts.Progress = func(p *sdk.DownloadProgress) {
+		// --verify 时下载完成后进入校验阶段，另起一行单独显示校验进度
+		if lastProgress != nil && lastProgress.Phase != p.Phase {
+			progress.nextPhase()
+		}
+		lastProgress = p
+		progress.update(downloadProgressLine(p, p.Downloaded), p.Downloaded, p.Total)
+	}
+	localPath, err := client.DownloadFileToPath(fid, destPath, fileName, opts)
+	if err != nil || lastProgress == nil || lastProgress.Total <= 0 {
+		progress.finish("")
+		return localPath, err
+	}
+	progress.finish(downloadProgressLine(lastProgress, lastProgress.Total))
+	return localPath, nil
+}
+
+// downloadProgressLine 格式化文本进度行，done 为已完成字节数；传输中附带平均速度和剩余时间，完成事件附带整体平均速度和总耗时
+func downloadProgressLine(p *sdk.DownloadProgress, done int64) string {
+	line := fmt.Sprintf("%s %.2f MB", progressLabel(p), float64(done)/(1024*1024))
+	if p.Total > 0 {
+		pct := float64(done) / float64(p.Total) * 100
+		line = fmt.Sprintf("%s %.2f MB / %.2f MB (%.1f%%)", progressLabel(p), float64(done)/(1024*1024), float64(p.Total)/(1024*1024), pct)
+	}
+	switch {
+	case p.Done && p.Speed > 0:
+		line += fmt.Sprintf(" | avg %s/s in %s", formatSize(int64(p.Speed)), p.Elapsed.Round(time.Second))
+	case !p.Done && p.Speed > 0:
+		line += fmt.Sprintf(" | %s/s", formatSize(int64(p.Speed)))
+		if p.ETA >= 0 {
+			line += fmt.Sprintf(" | ETA %s", p.ETA.Round(time.Second))
+		}
+	}
+	return line
+}
+
+// doneSpeed 返回完成事件中的整体平均速度，p 不是完成事件时返回 0
+func doneSpeed(p *sdk.DownloadProgress) float64 {
+	if !p.Done {
+		return 0
+	}
+	return p.Speed
+}
+
+// doneElapsed 返回完成事件中的总耗时，p 不是完成事件时返回 0（由调用方按本地计时估算）
+func doneElapsed(p *sdk.DownloadProgress) time.Duration {
+	if !p.Done {
+		return 0
+	}
+	return p.Elapsed
+}
+
+// progressLabel 返回进度行的前缀：下载阶段为 Downloaded，校验阶段为 Verified
+func progressLabel(p *sdk.DownloadProgress) string {
+	if p.Phase == sdk.DownloadPhaseVerify {
+		return "Verified"
+	}
+	return "Downloaded"
+}
+
+// localDownloadResult 将 downloadToLocal 的结果转为 CLIResult，data 为附加字段（local_path 自动加入）
+// 冲突策略为 skip 且本地文件已存在时返回成功，Code 为 SKIPPED
+func localDownloadResult(localPath string, err error, data map[string]interface{}) *CLIResult {
+	if errors.Is(err, sdk.ErrVerifyFailed) {
+		return &CLIResult{
+			Success: false,
+			Code:    "VERIFY_FAILED",
+			Message: err.Error(),
+		}
+	}
+	if errors.Is(err, sdk.ErrInsufficientDiskSpace) {
+		return &CLIResult{
+			Success: false,
+			Code:    "INSUFFICIENT_DISK_SPACE",
+			Message: err.Error(),
+		}
+	}
+	if errors.Is(err, sdk.ErrRangeNotSupported) {
+		return &CLIResult{
+			Success: false,
+			Code:    "RANGE_NOT_SUPPORTED",
+			Message: err.Error(),
+		}
+	}
+	if errors.Is(err, sdk.ErrDownloadExists) {
+		return &CLIResult{
+			Success: false,
+			Code:    "FILE_EXISTS",
+			Message: fmt.Sprintf("%v (use --on-conflict overwrite, skip or rename)", err),
+			Data:    map[string]interface{}{"local_path": localPath},
+		}
+	}
+	if errors.Is(err, sdk.ErrDownloadSkipped) {
+		data["local_path"] = localPath
+		return &CLIResult{
+			Success: true,
+			Code:    "SKIPPED",
+			Message: "Local file already exists, skipped",
+			Data:    data,
+		}
+	}
+	if err != nil {
+		return &CLIResult{
+			Success: false,
+			Message: fmt.Sprintf("download failed: %v", err),
 		}
 	}
-
-	fileName, _ := fileInfo.Data["file_name"].(string)
-	if fileName == "" {
-		fileName = filepath.Base(path)
-	}
-	if fileName == "" || fileName == "." {
-		fileName = "download"
+	data["local_path"] = localPath
+	return &CLIResult{
+		Success: true,
+		Code:    "OK",
+		Message: "File downloaded successfully",
+		Data:    data,
 	}
+}
 
-	// 指定了 dest：下载到本地
-	if destPath != "" {
-		var lastProgress *sdk.DownloadProgress
-		var lastPrint time.Time
-		err = client.DownloadFile(fid, destPath, fileName, func(p *sdk.DownloadProgress) {
-			lastProgress = p
-			now := time.Now()
-			if now.Sub(lastPrint) < 500*time.Millisecond && p.Total >= 0 && p.Downloaded < p.Total {
-				return
-			}
-			lastPrint = now
-			if p.Total > 0 {
-				pct := float64(p.Downloaded) / float64(p.Total) * 100
-				fmt.Fprintf(os.Stderr, "\rDownloaded %.2f MB / %.2f MB (%.1f%%)", float64(p.Downloaded)/(1024*1024), float64(p.Total)/(1024*1024), pct)
-			} else {
-				fmt.Fprintf(os.Stderr, "\rDownloaded %.2f MB", float64(p.Downloaded)/(1024*1024))
-			}
-		})
-		if err != nil {
-			return &CLIResult{
-				Success: false,
-				Message: fmt.Sprintf("download failed: %v", err),
-			}
-		}
-		if lastProgress != nil && lastProgress.Total > 0 {
-			fmt.Fprintf(os.Stderr, "\rDownloaded %.2f MB / %.2f MB (100.0%%)\n", float64(lastProgress.Downloaded)/(1024*1024), float64(lastProgress.Total)/(1024*1024))
-		} else {
-			fmt.Fprintf(os.Stderr, "\n")
-		}
-		// 解析最终本地路径（与 SDK 逻辑一致）
-		localPath := destPath
-		if destPath == "" || destPath == "." || strings.HasSuffix(destPath, "/") || strings.HasSuffix(destPath, string(filepath.Separator)) {
-			localPath = filepath.Join(destPath, fileName)
-		} else if info, err := os.Stat(destPath); err == nil && info.IsDir() {
-			localPath = filepath.Join(destPath, fileName)
+// downloadDirectory 递归下载目录到 destPath（未指定时为当前目录），每个文件结束时在 stderr 输出一行结果，并显示所有文件的总进度
+// 有文件或子目录失败时结果为失败（退出码非 0），Data 中列出成功与失败的文件
+func downloadDirectory(client *sdk.QuarkClient, dirPath, destPath string, flags downloadFlags) *CLIResult {
+	progress := newBatchProgress("download")
+	opts := flags.dirOptions()
+	opts.OnFile = progress.fileDone
+	opts.OnProgress = progress.update
+	response, err := client.DownloadDir(dirPath, destPath, opts)
+	progress.finish()
+	if err != nil {
+		return &CLIResult{
+			Success: false,
+			Message: fmt.Sprintf("download failed: %v", err),
 		}
+	}
+	if !response.Success {
 		return &CLIResult{
-			Success: true,
-			Code:    "OK",
-			Message: "File downloaded successfully",
-			Data:    map[string]interface{}{"local_path": localPath, "path": path},
+			Success: false,
+			Code:    response.Code,
+			Message: response.Message,
 		}
 	}
 
-	// 未指定 dest：仅返回下载链接
-	downloadURL, err := client.GetDownloadURL(fid)
-	if err != nil {
+	if response.Code != "OK" {
 		return &CLIResult{
 			Success: false,
-			Message: fmt.Sprintf("failed to get download URL: %v", err),
+			Code:    "DOWNLOAD_PARTIAL_FAILED",
+			Message: response.Message,
+			Data:    response.Data,
 		}
 	}
 	return &CLIResult{
 		Success: true,
 		Code:    "OK",
-		Message: "Download URL retrieved successfully",
-		Data:    map[string]interface{}{"fid": fid, "path": path, "download_url": downloadURL},
+		Message: response.Message,
+		Data:    response.Data,
+	}
+}
+
+// defaultDownloadDest 返回配置的默认下载目录（以分隔符结尾，确保按目录处理），未配置时返回空字符串
+func defaultDownloadDest() string {
+	if cliDefaults.DownloadDir == "" {
+		return ""
 	}
+	return strings.TrimRight(cliDefaults.DownloadDir, "/\\") + string(filepath.Separator)
 }
 
 // handleShareDelete 处理取消分享命令
@@ -1498,13 +3985,58 @@ func handleShareList(client *sdk.QuarkClient, args []string) *CLIResult {
 }
 
 // handleShareSave 处理转存分享文件命令
-// 用法: share-save <share_link> [passcode] [dest_dir]
+// 用法: share-save <share_link> [passcode] [dest_dir] [--select <glob>]... [--fids fid1,fid2] [--no-wait]
 func handleShareSave(client *sdk.QuarkClient, args []string) *CLIResult {
-	if len(args) < 1 {
+	usage := `Usage: share-save <share_link> [passcode] [dest_dir] [--select <glob>]... [--fids fid1,fid2] [--no-wait] (e.g., share-save "https://pan.quark.cn/s/xxx" "1234" "/folder" --select "S01E01*")`
+	var selection sdk.ShareSelection
+	var positional []string
+	noWait := false
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--no-wait":
+			noWait = true
+		case "--select", "--fids":
+			if i+1 >= len(args) || args[i+1] == "" {
+				return &CLIResult{
+					Success: false,
+					Code:    "INVALID_ARGS",
+					Message: fmt.Sprintf("missing value for %s", args[i]),
+				}
+			}
+			if args[i] == "--select" {
+				if _, err := path.Match(args[i+1], ""); err != nil {
+					return &CLIResult{
+						Success: false,
+						Code:    "INVALID_ARGS",
+						Message: fmt.Sprintf("invalid --select pattern %q: %v", args[i+1], err),
+					}
+				}
+				selection.Patterns = append(selection.Patterns, args[i+1])
+			} else {
+				for _, fid := range strings.Split(args[i+1], ",") {
+					if fid = strings.TrimSpace(fid); fid != "" {
+						selection.Fids = append(selection.Fids, fid)
+					}
+				}
+			}
+			i++
+		default:
+			if strings.HasPrefix(args[i], "--") {
+				return &CLIResult{
+					Success: false,
+					Code:    "INVALID_ARGS",
+					Message: fmt.Sprintf("unknown share-save option: %s", args[i]),
+				}
+			}
+			positional = append(positional, args[i])
+		}
+	}
+	args = positional
+	if len(args) < 1 || len(args) > 3 {
 		return &CLIResult{
 			Success: false,
 			Code:    "INVALID_ARGS",
-			Message: `Usage: share-save <share_link> [passcode] [dest_dir] (e.g., share-save "https://pan.quark.cn/s/xxx" "1234" "/folder")`,
+			Message: usage,
 		}
 	}
 
@@ -1525,39 +4057,10 @@ func handleShareSave(client *sdk.QuarkClient, args []string) *CLIResult {
 		destDir = args[2]
 	}
 
-	// 从分享链接中提取 pwdID 和 passcode
-	shareInfo, err := client.GetShareInfo(shareLink)
-	if err != nil {
-		return &CLIResult{
-			Success: false,
-			Code:    "INVALID_SHARE_LINK",
-			Message: fmt.Sprintf("failed to parse share link: %v", err),
-		}
-	}
-
-	// 如果命令行提供了 passcode，优先使用命令行的
-	if passcode == "" && shareInfo.Passcode != "" {
-		passcode = shareInfo.Passcode
-	}
-
-	// 获取 stoken
-	stokenData, err := client.GetShareStoken(shareInfo.PwdID, passcode)
-	if err != nil {
-		return &CLIResult{
-			Success: false,
-			Code:    "GET_STOKEN_ERROR",
-			Message: fmt.Sprintf("failed to get share stoken: %v", err),
-		}
-	}
-
-	// 从 stokenData 中提取 stoken
-	stoken, ok := stokenData["stoken"].(string)
-	if !ok || stoken == "" {
-		return &CLIResult{
-			Success: false,
-			Code:    "INVALID_STOKEN",
-			Message: "stoken not found in response",
-		}
+	// 解析分享链接并获取 stoken；命令行提供的 passcode 优先于链接文本中的提取码
+	pwdID, stoken, errResult := shareStoken(client, shareLink, passcode)
+	if errResult != nil {
+		return errResult
 	}
 
 	// 处理目标目录
@@ -1598,9 +4101,13 @@ func handleShareSave(client *sdk.QuarkClient, args []string) *CLIResult {
 		}
 	}
 
+	if len(selection.Patterns) > 0 || len(selection.Fids) > 0 {
+		return saveShareSelection(client, pwdID, stoken, destDir, toPdirFid, selection, noWait)
+	}
+
 	// 转存文件（全部保存）
 	// fidList 和 shareTokenList 为空表示全部保存
-	result, err := client.SaveShareFile(shareInfo.PwdID, stoken, []string{}, []string{}, toPdirFid, true)
+	result, err := client.SaveShareFile(pwdID, stoken, []string{}, []string{}, toPdirFid, true)
 	if err != nil {
 		return &CLIResult{
 			Success: false,
@@ -1611,17 +4118,335 @@ func handleShareSave(client *sdk.QuarkClient, args []string) *CLIResult {
 
 	// 构建返回数据
 	data := map[string]interface{}{
-		"pwd_id":    shareInfo.PwdID,
+		"pwd_id":    pwdID,
 		"dest_dir":  destDir,
 		"dest_fid":  toPdirFid,
 		"save_all":  true,
 		"save_data": result,
 	}
+	return shareSaveResult(client, data, []map[string]interface{}{result}, noWait, "Share files saved successfully")
+}
+
+// shareSaveResult 转存请求发出后的结果：默认依次等待 saveData 中各个 task_id 的转存任务完成，Data 带 saved_fids（转存成功的顶层 fid）；
+// 任务失败时返回 SAVE_TASK_FAILED，Data.error 为失败原因，saved_fids 为此前已完成批次的 fid；noWait 时不等待（task_status 为 pending）
+func shareSaveResult(client *sdk.QuarkClient, data map[string]interface{}, saveData []map[string]interface{}, noWait bool, message string) *CLIResult {
+	if noWait {
+		data["task_status"] = "pending"
+		return &CLIResult{
+			Success: true,
+			Code:    "OK",
+			Message: "Share save submitted, not waiting for the task to finish",
+			Data:    data,
+		}
+	}
+	savedFids := make([]string, 0)
+	for _, item := range saveData {
+		taskID, _ := item["task_id"].(string)
+		if taskID == "" {
+			continue
+		}
+		result, err := client.WaitShareSaveTask(taskID)
+		if err != nil {
+			data["task_status"] = "failed"
+			data["saved_fids"] = savedFids
+			data["error"] = err.Error()
+			return &CLIResult{
+				Success: false,
+				Code:    "SAVE_TASK_FAILED",
+				Message: fmt.Sprintf("share save task failed: %v", err),
+				Data:    data,
+			}
+		}
+		savedFids = append(savedFids, result.SavedFids...)
+	}
+	data["task_status"] = "finished"
+	data["saved_fids"] = savedFids
+	return &CLIResult{
+		Success: true,
+		Code:    "OK",
+		Message: message,
+		Data:    data,
+	}
+}
+
+// saveShareSelection 递归列出分享内容，只转存 --select/--fids 选中的条目
+func saveShareSelection(client *sdk.QuarkClient, pwdID, stoken, destDir, toPdirFid string, selection sdk.ShareSelection, noWait bool) *CLIResult {
+	selected, err := client.SelectShareFiles(pwdID, stoken, selection)
+	if err != nil {
+		return &CLIResult{
+			Success: false,
+			Code:    "GET_SHARE_LIST_ERROR",
+			Message: fmt.Sprintf("failed to list share: %v", err),
+		}
+	}
+	found := make(map[string]bool, len(selected))
+	for _, entry := range selected {
+		found[entry.Fid] = true
+	}
+	var missing []string
+	for _, fid := range selection.Fids {
+		if !found[fid] {
+			missing = append(missing, fid)
+		}
+	}
+	if len(missing) > 0 {
+		return &CLIResult{
+			Success: false,
+			Code:    "SHARE_FID_NOT_FOUND",
+			Message: fmt.Sprintf("fids not found in share: %s", strings.Join(missing, ", ")),
+			Data:    map[string]interface{}{"missing_fids": missing},
+		}
+	}
+	if len(selected) == 0 {
+		return &CLIResult{
+			Success: false,
+			Code:    "SHARE_NO_MATCH",
+			Message: fmt.Sprintf("no files in share match %s", strings.Join(selection.Patterns, ", ")),
+		}
+	}
+
+	results, err := client.SaveShareFiles(pwdID, stoken, selected, toPdirFid)
+	if err != nil {
+		return &CLIResult{
+			Success: false,
+			Code:    "SAVE_SHARE_ERROR",
+			Message: fmt.Sprintf("failed to save share files: %v", err),
+			Data:    map[string]interface{}{"selected": selected, "save_data": results},
+		}
+	}
+	data := map[string]interface{}{
+		"pwd_id":    pwdID,
+		"dest_dir":  destDir,
+		"dest_fid":  toPdirFid,
+		"save_all":  false,
+		"selected":  selected,
+		"save_data": results,
+	}
+	return shareSaveResult(client, data, results, noWait, fmt.Sprintf("Saved %d selected item(s) from share", len(selected)))
+}
+
+// handleConfig 处理配置命令
+// 用法: config show [--effective] | config get <key> | config set <key> <value> | config unset <key> | config check [--offline]
+func handleConfig(configPath, cookies string, args []string) *CLIResult {
+	if len(args) < 1 {
+		return &CLIResult{
+			Success: false,
+			Code:    "INVALID_ARGS",
+			Message: `Usage: config show [--effective] | config get <key> | config set <key> <value> | config unset <key> | config check [--offline]`,
+		}
+	}
+
+	switch args[0] {
+	case "show":
+		effective := false
+		for _, arg := range args[1:] {
+			switch arg {
+			case "--effective":
+				effective = true
+			default:
+				return &CLIResult{
+					Success: false,
+					Code:    "INVALID_ARGS",
+					Message: fmt.Sprintf("unknown config show option: %s", arg),
+				}
+			}
+		}
+		return handleConfigShow(configPath, effective)
+	case "get":
+		if len(args) < 2 {
+			return &CLIResult{
+				Success: false,
+				Code:    "INVALID_ARGS",
+				Message: `Usage: config get <key> (e.g., config get transfer.upload_parallel)`,
+			}
+		}
+		return handleConfigGet(configPath, args[1])
+	case "set":
+		if len(args) < 3 {
+			return &CLIResult{
+				Success: false,
+				Code:    "INVALID_ARGS",
+				Message: fmt.Sprintf("Usage: config set <key> <value> (available keys: %s)", strings.Join(sdk.ConfigKeys(), ", ")),
+			}
+		}
+		return handleConfigUpdate(configPath, args[1], func(cfg *sdk.Config) error {
+			return sdk.SetConfigValue(cfg, args[1], args[2])
+		})
+	case "unset":
+		if len(args) < 2 {
+			return &CLIResult{
+				Success: false,
+				Code:    "INVALID_ARGS",
+				Message: fmt.Sprintf("Usage: config unset <key> (available keys: %s)", strings.Join(sdk.ConfigKeys(), ", ")),
+			}
+		}
+		return handleConfigUpdate(configPath, args[1], func(cfg *sdk.Config) error {
+			return sdk.UnsetConfigValue(cfg, args[1])
+		})
+	case "check":
+		online := true
+		for _, arg := range args[1:] {
+			switch arg {
+			case "--offline":
+				online = false
+			default:
+				return &CLIResult{
+					Success: false,
+					Code:    "INVALID_ARGS",
+					Message: fmt.Sprintf("unknown config check option: %s", arg),
+				}
+			}
+		}
+		return handleConfigCheck(configPath, cookies, online)
+	default:
+		return &CLIResult{
+			Success: false,
+			Code:    "INVALID_ARGS",
+			Message: fmt.Sprintf("unknown config subcommand: %s", args[0]),
+		}
+	}
+}
+
+// handleConfigShow 输出配置文件内容（token 脱敏）
+// effective 为 true 时输出与内置默认值合并后的生效配置；配置文件不存在时仅输出内置默认值
+func handleConfigShow(configPath string, effective bool) *CLIResult {
+	cfg, err := sdk.ReadConfig(configPath)
+	if err != nil && !effective {
+		return &CLIResult{
+			Success: false,
+			Code:    "CONFIG_READ_ERROR",
+			Message: err.Error(),
+		}
+	}
+
+	var tokens []string
+	if cfg != nil {
+		tokens = make([]string, 0, len(cfg.Quark.AccessTokens))
+		for _, token := range cfg.Quark.AccessTokens {
+			tokens = append(tokens, sdk.MaskToken(token))
+		}
+	}
+
+	data := map[string]interface{}{
+		"config_path":   configPath,
+		"access_tokens": tokens,
+	}
+	if effective {
+		data["defaults"] = cfg.EffectiveDefaults()
+		data["endpoints"] = cfg.EffectiveEndpoints()
+		data["config_loaded"] = err == nil
+	} else {
+		data["defaults"] = cfg.Defaults
+		data["endpoints"] = cfg.Endpoints
+	}
 
 	return &CLIResult{
 		Success: true,
 		Code:    "OK",
-		Message: "Share files saved successfully",
+		Message: "Get config successfully",
 		Data:    data,
 	}
 }
+
+// handleConfigGet 按点分路径读取配置项
+func handleConfigGet(configPath, key string) *CLIResult {
+	cfg, err := sdk.ReadConfig(configPath)
+	if err != nil {
+		return &CLIResult{
+			Success: false,
+			Code:    "CONFIG_READ_ERROR",
+			Message: err.Error(),
+		}
+	}
+
+	value, err := sdk.GetConfigValue(cfg, key)
+	if err != nil {
+		return &CLIResult{
+			Success: false,
+			Code:    "INVALID_CONFIG_KEY",
+			Message: err.Error(),
+		}
+	}
+
+	return &CLIResult{
+		Success: true,
+		Code:    "OK",
+		Message: "Get config value successfully",
+		Data: map[string]interface{}{
+			"key":   key,
+			"value": value,
+		},
+	}
+}
+
+// handleConfigUpdate 读取配置文件、应用修改并写回（配置文件不存在时新建）
+func handleConfigUpdate(configPath, key string, update func(cfg *sdk.Config) error) *CLIResult {
+	cfg, err := sdk.ReadConfig(configPath)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			return &CLIResult{
+				Success: false,
+				Code:    "CONFIG_READ_ERROR",
+				Message: err.Error(),
+			}
+		}
+		cfg = &sdk.Config{}
+	}
+
+	if err := update(cfg); err != nil {
+		return &CLIResult{
+			Success: false,
+			Code:    "INVALID_CONFIG_VALUE",
+			Message: err.Error(),
+		}
+	}
+
+	if err := sdk.SaveConfig(configPath, cfg); err != nil {
+		return &CLIResult{
+			Success: false,
+			Code:    "CONFIG_WRITE_ERROR",
+			Message: err.Error(),
+		}
+	}
+
+	value, _ := sdk.GetConfigValue(cfg, key)
+	return &CLIResult{
+		Success: true,
+		Code:    "OK",
+		Message: "Config saved successfully",
+		Data: map[string]interface{}{
+			"key":   key,
+			"value": value,
+		},
+	}
+}
+
+// handleConfigCheck 处理配置体检命令，任一检查项失败时返回失败结果（退出码 1）
+func handleConfigCheck(configPath, cookies string, online bool) *CLIResult {
+	checks := sdk.CheckConfig(configPath, online, cookies)
+
+	allOK := true
+	failed := 0
+	for _, check := range checks {
+		if !check.OK {
+			allOK = false
+			failed++
+		}
+	}
+
+	result := &CLIResult{
+		Success: allOK,
+		Code:    "OK",
+		Message: "All checks passed",
+		Data: map[string]interface{}{
+			"config_path": configPath,
+			"online":      online,
+			"checks":      checks,
+		},
+	}
+	if !allOK {
+		result.Code = "CONFIG_CHECK_FAILED"
+		result.Message = fmt.Sprintf("%d of %d checks failed", failed, len(checks))
+	}
+	return result
+}
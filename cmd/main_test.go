@@ -0,0 +1,19 @@
+package main
+
+import "testing"
+
+func TestInitErrorExitCode(t *testing.T) {
+	tests := []struct {
+		command string
+		want    int
+	}{
+		{command: "exists", want: ExitQueryError},
+		{command: "list", want: ExitError},
+		{command: "upload", want: ExitError},
+	}
+	for _, tt := range tests {
+		if got := initErrorExitCode(tt.command); got != tt.want {
+			t.Errorf("initErrorExitCode(%q) = %d, want %d", tt.command, got, tt.want)
+		}
+	}
+}
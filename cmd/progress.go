@@ -0,0 +1,351 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"kuake_sdk/sdk"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// --progress 的取值
+const (
+	progressText = "text" // 默认：stderr 上的人读进度行
+	progressJSON = "json" // 每 500ms 向 stderr 输出一行 JSON 事件，结束时输出 type=done 事件
+
+	progressEventInterval = 500 * time.Millisecond // JSON 进度事件的最小间隔
+)
+
+// cliProgress 全局参数 --progress 的取值，上传和下载共用
+var cliProgress = progressText
+
+// parseProgressMode 校验 --progress 的取值
+func parseProgressMode(value string) (string, error) {
+	switch mode := strings.ToLower(strings.TrimSpace(value)); mode {
+	case progressText, progressJSON:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("invalid --progress value %q, must be text or json", value)
+	}
+}
+
+// progressEvent --progress=json 时输出到 stderr 的一行事件，上传和下载格式相同
+type progressEvent struct {
+	Type       string  `json:"type"`              // progress：传输中；done：传输结束（成功或失败）；total：目录/批量下载的总进度
+	Op         string  `json:"op"`                // download 或 upload
+	File       string  `json:"file"`              // 文件名或远端路径
+	Phase      string  `json:"phase,omitempty"`   // 下载后校验哈希时为 verify，upload --from-url 长度未知先拉取到临时文件时为 fetch
+	Downloaded int64   `json:"downloaded"`        // 已传输字节数（上传时为已上传字节数）
+	Total      int64   `json:"total"`             // 总字节数，-1 表示未知
+	Speed      int64   `json:"speed"`             // 最近几秒的平均速度（字节/秒），done 事件为整体平均速度
+	ETA        int64   `json:"eta"`               // 预计剩余秒数，-1 表示未知
+	Elapsed    float64 `json:"elapsed,omitempty"` // done 事件：总耗时（秒）
+	Skipped    bool    `json:"skipped,omitempty"` // done 事件：本地文件已存在，按冲突策略跳过
+	Error      string  `json:"error,omitempty"`   // done 事件：失败原因
+	FilesDone  int     `json:"files_done,omitempty"`
+	FilesTotal int     `json:"files_total,omitempty"`
+	Failed     int     `json:"failed,omitempty"`
+}
+
+// jsonProgress 为单个文件生成 JSON 进度事件，update 按 progressEventInterval 节流，可并发调用
+// 速度和剩余时间使用 SDK 进度回调中按滑动窗口计算的值
+type jsonProgress struct {
+	mu       sync.Mutex
+	op       string
+	file     string
+	start    time.Time
+	lastEmit time.Time
+}
+
+// newJSONProgress 创建 op（download/upload）文件 file 的 JSON 进度输出
+func newJSONProgress(op, file string) *jsonProgress {
+	now := time.Now()
+	return &jsonProgress{op: op, file: file, start: now, lastEmit: now}
+}
+
+// update 记录进度，距上次输出不足 progressEventInterval 时不输出；phase 为空表示传输阶段
+// speed 为平均速度（字节/秒），eta < 0 表示剩余时间未知
+func (p *jsonProgress) update(phase string, done, total int64, speed float64, eta time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := time.Now()
+	if now.Sub(p.lastEmit) < progressEventInterval {
+		return
+	}
+	etaSeconds := int64(-1)
+	if eta >= 0 && total > 0 {
+		etaSeconds = int64(eta.Seconds())
+	}
+	p.lastEmit = now
+	emitProgressEvent(progressEvent{Type: "progress", Op: p.op, File: p.file, Phase: phase, Downloaded: done, Total: total, Speed: int64(speed), ETA: etaSeconds})
+}
+
+// done 输出结束事件，err 不为 nil 时带上失败原因
+// speed、elapsed 为 SDK 完成事件中的整体平均速度和总耗时，elapsed 为 0（如失败时没有完成事件）时按本地计时估算
+func (p *jsonProgress) done(done, total int64, speed float64, elapsed time.Duration, skipped bool, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if elapsed <= 0 {
+		elapsed = time.Since(p.start)
+		speed = 0
+		if elapsed > 0 {
+			speed = float64(done) / elapsed.Seconds()
+		}
+	}
+	event := progressEvent{Type: "done", Op: p.op, File: p.file, Downloaded: done, Total: total, Speed: int64(speed), Elapsed: elapsed.Seconds(), Skipped: skipped}
+	if err != nil {
+		event.Error = err.Error()
+	}
+	emitProgressEvent(event)
+}
+
+// progressOutputMu 串行化 stderr 上的事件输出，避免并发下载时行交错
+var progressOutputMu sync.Mutex
+
+// emitProgressEvent 将事件编码为一行 JSON 写到 stderr
+func emitProgressEvent(event progressEvent) {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	progressOutputMu.Lock()
+	defer progressOutputMu.Unlock()
+	fmt.Fprintf(os.Stderr, "%s\n", line)
+}
+
+// reportDownloadResult 在 stderr 报告目录/批量下载中第 n 个文件的结果：文本模式输出一行 "[n] ..."，JSON 模式输出 done 事件
+func reportDownloadResult(n int, result sdk.DownloadResult) {
+	if cliProgress == progressJSON {
+		// 目录/批量下载不跟踪单个文件的字节进度，只输出结束事件
+		event := progressEvent{Type: "done", Op: "download", File: result.Path, Downloaded: result.Size, Total: result.Size, Skipped: result.Skipped, Error: result.Error}
+		if result.Error != "" || result.Skipped {
+			event.Downloaded = 0
+		}
+		emitProgressEvent(event)
+		return
+	}
+	if result.Error != "" {
+		fmt.Fprintf(os.Stderr, "[%d] failed %s: %s\n", n, result.Path, result.Error)
+		return
+	}
+	if result.Skipped {
+		fmt.Fprintf(os.Stderr, "[%d] skipped %s (%s exists)\n", n, result.Path, result.LocalPath)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "[%d] downloaded %s -> %s\n", n, result.Path, result.LocalPath)
+}
+
+// reportUploadResult 在 stderr 报告目录上传中第 n 个文件的结果：文本模式输出一行 "[n] ..."，JSON 模式输出 done 事件
+func reportUploadResult(n int, result sdk.UploadResult) {
+	if cliProgress == progressJSON {
+		event := progressEvent{Type: "done", Op: "upload", File: result.LocalPath, Downloaded: result.Size, Total: result.Size, Skipped: result.Skipped, Error: result.Error}
+		if result.Error != "" || result.Skipped {
+			event.Downloaded = 0
+		}
+		emitProgressEvent(event)
+		return
+	}
+	if result.Error != "" {
+		fmt.Fprintf(os.Stderr, "[%d] failed %s: %s\n", n, result.LocalPath, result.Error)
+		return
+	}
+	if result.Skipped && result.Reason != "" {
+		fmt.Fprintf(os.Stderr, "[%d] skipped %s (%s)\n", n, result.LocalPath, result.Reason)
+		return
+	}
+	if result.Skipped {
+		fmt.Fprintf(os.Stderr, "[%d] skipped %s (%s exists)\n", n, result.LocalPath, result.Path)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "[%d] uploaded %s -> %s\n", n, result.LocalPath, result.Path)
+}
+
+// batchProgress 目录/批量下载和目录上传的输出：每个文件结束时一行结果（见 reportDownloadResult、reportUploadResult），
+// 另有一个所有文件的总进度：文本模式为 "Total: 已完成文件数/总数 | 已传输/总大小" 进度行，JSON 模式为节流的 type=total 事件
+// fileDone、uploadFileDone 和 update、uploadUpdate 可并发调用
+type batchProgress struct {
+	mu       sync.Mutex
+	op       string // download 或 upload
+	text     *textProgress
+	lastEmit time.Time
+	last     sdk.DownloadBatchProgress
+	done     int // 已输出结果行的文件数
+}
+
+// newBatchProgress 按 --progress 创建 op（download/upload）的多文件进度输出
+func newBatchProgress(op string) *batchProgress {
+	b := &batchProgress{op: op}
+	if cliProgress != progressJSON {
+		b.text = newTextProgress()
+	}
+	return b
+}
+
+// fileDone 输出一个文件的结果行；终端下先清除当前的总进度行，下次 update 时重新显示
+func (b *batchProgress) fileDone(result sdk.DownloadResult) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.text != nil {
+		b.text.clear()
+	}
+	b.done++
+	reportDownloadResult(b.done, result)
+}
+
+// uploadFileDone 输出一个上传文件的结果行，见 fileDone
+func (b *batchProgress) uploadFileDone(result sdk.UploadResult) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.text != nil {
+		b.text.clear()
+	}
+	b.done++
+	reportUploadResult(b.done, result)
+}
+
+// uploadUpdate 报告上传总进度，按与下载相同的格式输出
+func (b *batchProgress) uploadUpdate(p sdk.UploadBatchProgress) {
+	b.update(sdk.DownloadBatchProgress{FilesDone: p.FilesDone, FilesTotal: p.FilesTotal, Failed: p.Failed, Downloaded: p.Uploaded, Total: p.Total, Speed: p.Speed, ETA: p.ETA})
+}
+
+// update 报告总进度，JSON 事件按 progressEventInterval 节流，文本进度行按 textProgress 的规则节流
+func (b *batchProgress) update(p sdk.DownloadBatchProgress) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.last = p
+	if b.text != nil {
+		b.text.update(batchProgressLine(p), p.Downloaded, p.Total)
+		return
+	}
+	now := time.Now()
+	if now.Sub(b.lastEmit) < progressEventInterval && p.FilesDone < p.FilesTotal {
+		return
+	}
+	b.lastEmit = now
+	emitProgressEvent(batchProgressEvent(b.op, p))
+}
+
+// finish 结束输出：文本模式输出最终的总进度行，JSON 模式输出最后一条 total 事件
+func (b *batchProgress) finish() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.last.FilesTotal == 0 {
+		if b.text != nil {
+			b.text.finish("")
+		}
+		return
+	}
+	if b.text != nil {
+		b.text.finish(batchProgressLine(b.last))
+		return
+	}
+	emitProgressEvent(batchProgressEvent(b.op, b.last))
+}
+
+// batchProgressLine 格式化总进度行
+func batchProgressLine(p sdk.DownloadBatchProgress) string {
+	line := fmt.Sprintf("Total: %d/%d files | %.2f MB / %.2f MB", p.FilesDone, p.FilesTotal, float64(p.Downloaded)/(1024*1024), float64(p.Total)/(1024*1024))
+	if p.Total > 0 {
+		line += fmt.Sprintf(" (%.1f%%)", float64(p.Downloaded)/float64(p.Total)*100)
+	}
+	if p.Failed > 0 {
+		line += fmt.Sprintf(" | %d failed", p.Failed)
+	}
+	if p.Speed > 0 && p.FilesDone < p.FilesTotal {
+		line += fmt.Sprintf(" | %s/s", formatSize(int64(p.Speed)))
+		if p.ETA >= 0 {
+			line += fmt.Sprintf(" | ETA %s", p.ETA.Round(time.Second))
+		}
+	}
+	return line
+}
+
+// batchProgressEvent 将 op（download/upload）的总进度转为 type=total 事件
+func batchProgressEvent(op string, p sdk.DownloadBatchProgress) progressEvent {
+	eta := int64(-1)
+	if p.ETA >= 0 {
+		eta = int64(p.ETA.Seconds())
+	}
+	return progressEvent{Type: "total", Op: op, Downloaded: p.Downloaded, Total: p.Total, Speed: int64(p.Speed), ETA: eta, FilesDone: p.FilesDone, FilesTotal: p.FilesTotal, Failed: p.Failed}
+}
+
+// 文本进度的刷新频率
+const (
+	textProgressInterval    = 500 * time.Millisecond // 终端下单行刷新的最小间隔
+	textProgressLogInterval = 10 * time.Second       // 非终端下两行日志的最大间隔
+	textProgressLogStep     = 5                      // 非终端下每前进多少个百分点输出一行
+)
+
+// textProgress 文本模式的进度输出：stderr 为终端时用 \r 单行刷新，
+// 否则（重定向到日志文件等）每前进 5% 或每 10 秒输出一行普通日志，避免 \r 刷出大量垃圾行
+type textProgress struct {
+	tty       bool
+	printed   bool      // 当前行（终端）或本阶段（非终端）是否已输出过
+	lastPrint time.Time // 上次输出的时间
+	lastStep  int       // 非终端下上次输出时所在的 5% 区间，-1 表示尚未输出
+	lastLine  string    // 上次输出的内容
+	forceNext bool      // 下一次 update 无条件输出（阶段切换后）
+}
+
+// newTextProgress 按 stderr 是否为终端创建文本进度输出
+func newTextProgress() *textProgress {
+	return &textProgress{tty: isStderrTTY(), lastStep: -1}
+}
+
+// update 报告进度 line，done/total 用于按百分比节流（total < 0 表示未知，非终端下只按时间间隔输出）
+func (p *textProgress) update(line string, done, total int64) {
+	now := time.Now()
+	force := p.forceNext || !p.printed
+	p.forceNext = false
+	if p.tty {
+		if !force && now.Sub(p.lastPrint) < textProgressInterval && total >= 0 && done < total {
+			return
+		}
+		fmt.Fprintf(os.Stderr, "\r%s", line)
+	} else {
+		step := -1
+		if total > 0 {
+			step = int(done*100/total) / textProgressLogStep
+		}
+		if !force && now.Sub(p.lastPrint) < textProgressLogInterval && (step < 0 || step <= p.lastStep) {
+			return
+		}
+		p.lastStep = step
+		fmt.Fprintln(os.Stderr, line)
+	}
+	p.printed, p.lastPrint, p.lastLine = true, now, line
+}
+
+// clear 终端下清除当前进度行以便输出其它内容，下一次 update 无条件重新显示；非终端下无需处理
+func (p *textProgress) clear() {
+	if !p.tty || !p.printed {
+		return
+	}
+	fmt.Fprint(os.Stderr, "\r\033[K")
+	p.printed, p.forceNext = false, true
+}
+
+// nextPhase 开始新的进度阶段（如下载后的校验）：终端下换行，下一次 update 无条件输出
+func (p *textProgress) nextPhase() {
+	if p.tty && p.printed {
+		fmt.Fprintln(os.Stderr)
+	}
+	p.printed, p.lastStep, p.forceNext = false, -1, true
+}
+
+// finish 结束进度输出，line 不为空时作为最后一行（非终端下与上次输出相同时不重复）
+func (p *textProgress) finish(line string) {
+	if p.tty {
+		if line != "" {
+			fmt.Fprintf(os.Stderr, "\r%s\n", line)
+		} else if p.printed {
+			fmt.Fprintln(os.Stderr)
+		}
+		return
+	}
+	if line != "" && line != p.lastLine {
+		fmt.Fprintln(os.Stderr, line)
+	}
+}
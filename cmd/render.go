@@ -0,0 +1,216 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"kuake_sdk/sdk"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// fileTableHeaders --format table 输出的列
+var fileTableHeaders = []string{"NAME", "SIZE", "MTIME", "TYPE"}
+
+// isStdoutTTY 判断 stdout 是否为终端
+func isStdoutTTY() bool {
+	stat, err := os.Stdout.Stat()
+	if err != nil {
+		return false
+	}
+	return (stat.Mode() & os.ModeCharDevice) != 0
+}
+
+// formatSize 将字节数格式化为人类可读的大小（B/KB/MB/GB/TB，1024 进制）
+func formatSize(size int64) string {
+	if size < 1024 {
+		return fmt.Sprintf("%d B", size)
+	}
+	units := []string{"KB", "MB", "GB", "TB", "PB"}
+	value := float64(size) / 1024
+	unit := 0
+	for value >= 1024 && unit < len(units)-1 {
+		value /= 1024
+		unit++
+	}
+	return fmt.Sprintf("%.1f %s", value, units[unit])
+}
+
+// formatMtime 将秒级时间戳格式化为本地时区的 ISO 8601 时间，未知时间输出 "-"
+func formatMtime(sec int64) string {
+	if sec <= 0 {
+		return "-"
+	}
+	return time.Unix(sec, 0).Local().Format(time.RFC3339)
+}
+
+// fileTableRow 将文件信息转换为表格行；usePath 为 true 且路径已知时名称列显示完整路径
+func fileTableRow(name, path string, size, mtime int64, dir, usePath bool) []string {
+	if usePath && path != "" {
+		name = path
+	}
+	sizeText, kind := formatSize(size), "file"
+	if dir {
+		sizeText, kind = "-", "dir"
+	}
+	return []string{name, sizeText, formatMtime(mtime), kind}
+}
+
+// renderFileTable 以表格输出文件列表
+func renderFileTable(w io.Writer, files []sdk.QuarkFileInfo, usePath bool) {
+	rows := make([][]string, 0, len(files))
+	for _, file := range files {
+		rows = append(rows, fileTableRow(file.Name, file.Path, file.Size, file.ModifyTime, file.IsDirectory, usePath))
+	}
+	renderTable(w, fileTableHeaders, rows, isStdoutTTY())
+}
+
+// renderInfoTable 以表格输出 info 命令返回的单个文件信息
+func renderInfoTable(w io.Writer, data map[string]interface{}) {
+	name, _ := data["file_name"].(string)
+	path, _ := data["path"].(string)
+	size, _ := data["size"].(int64)
+	mtime, _ := data["mtime"].(int64)
+	dir, _ := data["dir"].(bool)
+	row := fileTableRow(name, path, size, mtime, dir, true)
+	renderTable(w, fileTableHeaders, [][]string{row}, isStdoutTTY())
+}
+
+// fileCSVHeaders CSV 导出的列
+var fileCSVHeaders = []string{"path", "fid", "size", "dir", "ctime", "mtime"}
+
+// renderFileCSV 以标准 CSV（RFC 4180）输出文件列表，含逗号、引号、换行的字段由 encoding/csv 负责转义
+// 时间列为本地时区 ISO 8601 格式，路径未知时 path 列使用文件名
+func renderFileCSV(w io.Writer, files []sdk.QuarkFileInfo) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(fileCSVHeaders); err != nil {
+		return err
+	}
+	for _, file := range files {
+		path := file.Path
+		if path == "" {
+			path = file.Name
+		}
+		record := []string{
+			path,
+			file.Fid,
+			strconv.FormatInt(file.Size, 10),
+			strconv.FormatBool(file.IsDirectory),
+			csvTime(file.CreateTime),
+			csvTime(file.ModifyTime),
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// writeFileCSV 将文件列表导出为 CSV 文件，文件开头写入 UTF-8 BOM 以便 Excel 正确识别中文
+func writeFileCSV(filePath string, files []sdk.QuarkFileInfo) error {
+	file, err := os.Create(filePath)
+	if err != nil {
+		return err
+	}
+	if _, err := file.WriteString("\ufeff"); err != nil {
+		file.Close()
+		return err
+	}
+	if err := renderFileCSV(file, files); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// csvTime 将秒级时间戳格式化为 CSV 中的时间，未知时间为空
+func csvTime(sec int64) string {
+	if sec <= 0 {
+		return ""
+	}
+	return formatMtime(sec)
+}
+
+// renderTable 输出表格：终端下按显示宽度对齐（中文等宽字符占 2 列），非终端时退化为 TSV 便于脚本处理
+func renderTable(w io.Writer, headers []string, rows [][]string, aligned bool) {
+	if !aligned {
+		fmt.Fprintln(w, strings.Join(headers, "\t"))
+		for _, row := range rows {
+			cells := make([]string, len(row))
+			for i, cell := range row {
+				// TSV 单元格中不能出现制表符和换行
+				cells[i] = strings.NewReplacer("\t", " ", "\n", " ").Replace(cell)
+			}
+			fmt.Fprintln(w, strings.Join(cells, "\t"))
+		}
+		return
+	}
+
+	widths := make([]int, len(headers))
+	for i, header := range headers {
+		widths[i] = displayWidth(header)
+	}
+	for _, row := range rows {
+		for i, cell := range row {
+			if width := displayWidth(cell); i < len(widths) && width > widths[i] {
+				widths[i] = width
+			}
+		}
+	}
+
+	writeRow := func(cells []string) {
+		var line strings.Builder
+		for i, cell := range cells {
+			if i > 0 {
+				line.WriteString("  ")
+			}
+			line.WriteString(cell)
+			// 最后一列不补空格，避免行尾多余空白
+			if i < len(cells)-1 {
+				line.WriteString(strings.Repeat(" ", widths[i]-displayWidth(cell)))
+			}
+		}
+		fmt.Fprintln(w, line.String())
+	}
+	writeRow(headers)
+	for _, row := range rows {
+		writeRow(row)
+	}
+}
+
+// displayWidth 计算字符串在终端中的显示宽度：东亚宽字符和全角字符占 2 列，组合字符占 0 列
+func displayWidth(s string) int {
+	width := 0
+	for _, r := range s {
+		switch {
+		case unicode.Is(unicode.Mn, r), unicode.Is(unicode.Me, r), r == '\u200b':
+		case isWideRune(r):
+			width += 2
+		default:
+			width++
+		}
+	}
+	return width
+}
+
+// isWideRune 判断字符是否为东亚宽字符（CJK、谚文、假名、全角符号、emoji 等）
+func isWideRune(r rune) bool {
+	return (r >= 0x1100 && r <= 0x115F) || // 谚文字母
+		(r >= 0x2E80 && r <= 0x303E) || // CJK 部首、标点
+		(r >= 0x3041 && r <= 0x33FF) || // 假名、CJK 符号
+		(r >= 0x3400 && r <= 0x4DBF) || // CJK 扩展 A
+		(r >= 0x4E00 && r <= 0x9FFF) || // CJK 统一汉字
+		(r >= 0xA000 && r <= 0xA4CF) || // 彝文
+		(r >= 0xAC00 && r <= 0xD7A3) || // 谚文音节
+		(r >= 0xF900 && r <= 0xFAFF) || // CJK 兼容汉字
+		(r >= 0xFE30 && r <= 0xFE4F) || // CJK 兼容形式
+		(r >= 0xFF00 && r <= 0xFF60) || // 全角字符
+		(r >= 0xFFE0 && r <= 0xFFE6) || // 全角符号
+		(r >= 0x1F300 && r <= 0x1F64F) || // emoji
+		(r >= 0x1F900 && r <= 0x1F9FF) ||
+		(r >= 0x20000 && r <= 0x3FFFD) // CJK 扩展 B 及以后
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"fmt"
+	"kuake_sdk/sdk"
+	"strconv"
+	"strings"
+)
+
+// shareDetailPageSize share-detail 每页条数
+const shareDetailPageSize = 50
+
+// handleShareDetail 处理 share-detail 命令：列出分享链接中的文件（不转存）
+// 用法: share-detail <share_link> [passcode] [--dir <fid>] [--page N]
+func handleShareDetail(client *sdk.QuarkClient, args []string) *CLIResult {
+	dirFid := "0"
+	page := 1
+	positional := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--dir":
+			if i+1 >= len(args) || args[i+1] == "" {
+				return &CLIResult{
+					Success: false,
+					Code:    "INVALID_ARGS",
+					Message: "missing fid for --dir",
+				}
+			}
+			dirFid = args[i+1]
+			i++
+		case "--page":
+			if i+1 >= len(args) {
+				return &CLIResult{
+					Success: false,
+					Code:    "INVALID_ARGS",
+					Message: "missing value for --page",
+				}
+			}
+			n, err := strconv.Atoi(args[i+1])
+			if err != nil || n < 1 {
+				return &CLIResult{
+					Success: false,
+					Code:    "INVALID_ARGS",
+					Message: "invalid --page value, must be a positive integer",
+				}
+			}
+			page = n
+			i++
+		default:
+			if strings.HasPrefix(args[i], "--") {
+				return &CLIResult{
+					Success: false,
+					Code:    "INVALID_ARGS",
+					Message: fmt.Sprintf("unknown share-detail option: %s", args[i]),
+				}
+			}
+			positional = append(positional, args[i])
+		}
+	}
+	if len(positional) < 1 || len(positional) > 2 {
+		return &CLIResult{
+			Success: false,
+			Code:    "INVALID_ARGS",
+			Message: `Usage: share-detail <share_link> [passcode] [--dir <fid>] [--page N] (e.g., share-detail "https://pan.quark.cn/s/xxx" "1234")`,
+		}
+	}
+	passcode := ""
+	if len(positional) == 2 {
+		passcode = positional[1]
+	}
+
+	pwdID, stoken, errResult := shareStoken(client, positional[0], passcode)
+	if errResult != nil {
+		return errResult
+	}
+	entries, err := client.ListSharePage(pwdID, stoken, dirFid, page, shareDetailPageSize)
+	if err != nil {
+		return &CLIResult{
+			Success: false,
+			Code:    "GET_SHARE_LIST_ERROR",
+			Message: fmt.Sprintf("failed to list share: %v", err),
+		}
+	}
+
+	list := make([]map[string]interface{}, 0, len(entries))
+	var totalSize int64
+	for _, entry := range entries {
+		list = append(list, map[string]interface{}{
+			"name":            entry.Path,
+			"size":            entry.Size,
+			"fid":             entry.Fid,
+			"share_fid_token": entry.Token,
+			"dir":             entry.IsDir,
+		})
+		totalSize += entry.Size
+	}
+	return &CLIResult{
+		Success: true,
+		Code:    "OK",
+		Message: fmt.Sprintf("%d item(s) in share, %s", len(entries), formatSize(totalSize)),
+		Data: map[string]interface{}{
+			"pwd_id":     pwdID,
+			"dir_fid":    dirFid,
+			"page":       page,
+			"size":       shareDetailPageSize,
+			"has_more":   len(entries) == shareDetailPageSize,
+			"total_size": totalSize,
+			"list":       list,
+		},
+	}
+}
+
+// shareStoken 解析分享链接并获取访问分享内容所需的 stoken；passcode 为空时使用链接文本中的提取码
+// 失败时返回的 CLIResult 非 nil
+func shareStoken(client *sdk.QuarkClient, shareLink, passcode string) (pwdID, stoken string, errResult *CLIResult) {
+	shareInfo, err := client.GetShareInfo(shareLink)
+	if err != nil {
+		return "", "", &CLIResult{
+			Success: false,
+			Code:    "INVALID_SHARE_LINK",
+			Message: fmt.Sprintf("failed to parse share link: %v", err),
+		}
+	}
+	if passcode == "" {
+		passcode = shareInfo.Passcode
+	}
+	stokenData, err := client.GetShareStoken(shareInfo.PwdID, passcode)
+	if err != nil {
+		return "", "", &CLIResult{
+			Success: false,
+			Code:    "GET_STOKEN_ERROR",
+			Message: fmt.Sprintf("failed to get share stoken: %v", err),
+		}
+	}
+	stoken, ok := stokenData["stoken"].(string)
+	if !ok || stoken == "" {
+		return "", "", &CLIResult{
+			Success: false,
+			Code:    "INVALID_STOKEN",
+			Message: "stoken not found in response",
+		}
+	}
+	return shareInfo.PwdID, stoken, nil
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"fmt"
+	"kuake_sdk/sdk"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// handleShareDownload 处理 share-download 命令：把分享链接的全部内容下载到本地，不在自己网盘中保留转存
+// 用法: share-download <share_link> [passcode] [local_dir] [--workers N] [--on-conflict fail|overwrite|skip|rename]
+// 只有两个位置参数时，第二个参数为已存在的目录或包含路径分隔符时视为 local_dir，否则视为提取码
+// local_dir 默认为配置的 defaults.download_dir，未配置时为当前目录
+func handleShareDownload(client *sdk.QuarkClient, args []string) *CLIResult {
+	flags := downloadFlags{conflict: sdk.DownloadConflictFail}
+	positional := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--workers":
+			if i+1 >= len(args) {
+				return &CLIResult{
+					Success: false,
+					Code:    "INVALID_ARGS",
+					Message: "missing value for --workers",
+				}
+			}
+			n, err := strconv.Atoi(args[i+1])
+			if err != nil || n < 1 || n > sdk.MAX_DOWNLOAD_WORKERS {
+				return &CLIResult{
+					Success: false,
+					Code:    "INVALID_ARGS",
+					Message: fmt.Sprintf("invalid --workers value, must be an integer between 1 and %d", sdk.MAX_DOWNLOAD_WORKERS),
+				}
+			}
+			flags.workers = n
+			i++
+		case "--on-conflict":
+			if i+1 >= len(args) {
+				return &CLIResult{
+					Success: false,
+					Code:    "INVALID_ARGS",
+					Message: "missing value for --on-conflict",
+				}
+			}
+			switch policy := sdk.DownloadConflictPolicy(args[i+1]); policy {
+			case sdk.DownloadConflictOverwrite, sdk.DownloadConflictSkip, sdk.DownloadConflictRename, sdk.DownloadConflictFail:
+				flags.conflict = policy
+			default:
+				return &CLIResult{
+					Success: false,
+					Code:    "INVALID_ARGS",
+					Message: fmt.Sprintf("invalid --on-conflict value %q, must be overwrite, skip, rename or fail", args[i+1]),
+				}
+			}
+			i++
+		default:
+			positional = append(positional, args[i])
+		}
+	}
+	if len(positional) < 1 || len(positional) > 3 {
+		return &CLIResult{
+			Success: false,
+			Code:    "INVALID_ARGS",
+			Message: `Usage: share-download <share_link> [passcode] [local_dir] (e.g., share-download "https://pan.quark.cn/s/xxx" "1234" ./local)`,
+		}
+	}
+
+	shareLink := positional[0]
+	passcode := ""
+	localDir := ""
+	switch len(positional) {
+	case 2:
+		if isLocalDirArg(positional[1]) {
+			localDir = positional[1]
+		} else {
+			passcode = positional[1]
+		}
+	case 3:
+		passcode, localDir = positional[1], positional[2]
+	}
+	if localDir == "" {
+		localDir = defaultDownloadDest()
+	}
+
+	progress := newBatchProgress("download")
+	response, err := client.DownloadShare(shareLink, passcode, localDir, sdk.DownloadDirOptions{
+		Workers:    flags.workers,
+		Conflict:   flags.conflict,
+		OnFile:     progress.fileDone,
+		OnProgress: progress.update,
+	})
+	progress.finish()
+	if err != nil {
+		return &CLIResult{
+			Success: false,
+			Message: fmt.Sprintf("share download failed: %v", err),
+		}
+	}
+	if !response.Success {
+		return &CLIResult{
+			Success: false,
+			Code:    response.Code,
+			Message: response.Message,
+			Data:    response.Data,
+		}
+	}
+
+	// 分享页不提供直链，结果中说明经过了临时转存
+	message := response.Message + " (saved to a temporary directory first: the share page provides no direct download links)"
+	if cleanupError, _ := response.Data["cleanup_error"].(string); cleanupError != "" {
+		message += fmt.Sprintf("; failed to remove temporary directory %v, please delete it manually", response.Data["temp_dir"])
+	}
+	if response.Code != "OK" {
+		return &CLIResult{
+			Success: false,
+			Code:    "DOWNLOAD_PARTIAL_FAILED",
+			Message: message,
+			Data:    response.Data,
+		}
+	}
+	return &CLIResult{
+		Success: true,
+		Code:    "OK",
+		Message: message,
+		Data:    response.Data,
+	}
+}
+
+// isLocalDirArg 判断参数是否像本地目录：已存在的目录，或包含路径分隔符、以 . 开头
+func isLocalDirArg(arg string) bool {
+	if info, err := os.Stat(arg); err == nil && info.IsDir() {
+		return true
+	}
+	return strings.ContainsAny(arg, `/\`) || strings.HasPrefix(arg, ".")
+}
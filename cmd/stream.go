@@ -0,0 +1,151 @@
+package main
+
+import (
+	"fmt"
+	"kuake_sdk/sdk"
+	"strings"
+)
+
+// validStreamQualities --quality 可选的值
+var validStreamQualities = []string{"4k", "2k", "super", "high", "normal", "low", sdk.VideoQualityOriginal}
+
+// handleStream 处理 stream 命令：输出可在 mpv/VLC 等播放器中直接播放的地址和需要附带的请求头
+// 用法: stream <path> [--quality Q] | stream --fid <fid> [--quality Q]
+// 默认使用最高的转码清晰度，没有转码地址（非视频或尚未转码）时退回原文件下载链接；--quality original 直接使用原文件
+// Data 包含 path、fid、file_name、play_url、headers、quality、qualities（可选清晰度，最后一个为 original），转码时还有 width、height、duration
+func handleStream(client *sdk.QuarkClient, args []string) *CLIResult {
+	filePath := ""
+	fileFid := ""
+	quality := ""
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--fid", "--quality":
+			if i+1 >= len(args) || args[i+1] == "" {
+				return &CLIResult{
+					Success: false,
+					Code:    "INVALID_ARGS",
+					Message: fmt.Sprintf("missing value for %s", args[i]),
+				}
+			}
+			if args[i] == "--fid" {
+				fileFid = args[i+1]
+			} else {
+				quality = strings.ToLower(args[i+1])
+			}
+			i++
+		default:
+			filePath = args[i]
+		}
+	}
+	if filePath == "" && fileFid == "" {
+		return &CLIResult{
+			Success: false,
+			Code:    "INVALID_ARGS",
+			Message: `Usage: stream <path> | stream --fid <fid> [--quality 4k|2k|super|high|normal|low|original] (path must be quoted, e.g., stream "/movies/a.mkv")`,
+		}
+	}
+	valid := quality == ""
+	for _, q := range validStreamQualities {
+		valid = valid || q == quality
+	}
+	if !valid {
+		return &CLIResult{
+			Success: false,
+			Code:    "INVALID_ARGS",
+			Message: fmt.Sprintf("invalid --quality value %q, must be one of %s", quality, strings.Join(validStreamQualities, ", ")),
+		}
+	}
+
+	var fileInfo *sdk.StandardResponse
+	var err error
+	if fileFid != "" {
+		fileInfo, err = client.GetFileInfoByFid(fileFid)
+	} else {
+		fileInfo, err = client.GetFileInfo(filePath)
+	}
+	if err != nil {
+		return &CLIResult{
+			Success: false,
+			Message: fmt.Sprintf("failed to get file info: %v", err),
+		}
+	}
+	if !fileInfo.Success {
+		return &CLIResult{
+			Success: false,
+			Code:    fileInfo.Code,
+			Message: fileInfo.Message,
+		}
+	}
+	if isDir, _ := fileInfo.Data["dir"].(bool); isDir {
+		return &CLIResult{
+			Success: false,
+			Code:    "INVALID_FILE_TYPE",
+			Message: "cannot stream directory",
+		}
+	}
+	fid, _ := fileInfo.Data["fid"].(string)
+	fileName, _ := fileInfo.Data["file_name"].(string)
+	data := map[string]interface{}{
+		"path":      filePath,
+		"fid":       fid,
+		"file_name": fileName,
+	}
+
+	// 转码地址：--quality original 时不请求
+	var streams []sdk.VideoStream
+	if quality != sdk.VideoQualityOriginal {
+		streams, err = client.GetVideoStreams(fid)
+		if err != nil {
+			data["transcode_error"] = err.Error()
+		}
+	}
+	qualities := make([]string, 0, len(streams)+1)
+	for _, s := range streams {
+		qualities = append(qualities, s.Quality)
+	}
+	qualities = append(qualities, sdk.VideoQualityOriginal)
+	data["qualities"] = qualities
+
+	var stream *sdk.VideoStream
+	for i := range streams {
+		if quality == "" || streams[i].Quality == quality {
+			stream = &streams[i]
+			break
+		}
+	}
+	if stream == nil && quality != "" && quality != sdk.VideoQualityOriginal {
+		return &CLIResult{
+			Success: false,
+			Code:    "QUALITY_NOT_AVAILABLE",
+			Message: fmt.Sprintf("quality %s is not available, available: %s", quality, strings.Join(qualities, ", ")),
+			Data:    data,
+		}
+	}
+
+	if stream != nil {
+		data["play_url"] = stream.URL
+		data["quality"] = stream.Quality
+		data["width"] = stream.Width
+		data["height"] = stream.Height
+		data["duration"] = stream.Duration
+	} else {
+		// 没有转码地址时播放原文件，播放器需要支持该容器格式
+		downloadURL, err := client.GetDownloadURL(fid)
+		if err != nil {
+			return &CLIResult{
+				Success: false,
+				Message: fmt.Sprintf("failed to get download url: %v", err),
+				Data:    data,
+			}
+		}
+		data["play_url"] = downloadURL
+		data["quality"] = sdk.VideoQualityOriginal
+	}
+	data["headers"] = client.DownloadHeaders()
+	return &CLIResult{
+		Success: true,
+		Code:    "OK",
+		Message: fmt.Sprintf("play url (%s) retrieved successfully", data["quality"]),
+		Data:    data,
+	}
+}
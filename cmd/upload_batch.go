@@ -0,0 +1,216 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"kuake_sdk/sdk"
+	"os"
+	"strings"
+	"sync"
+)
+
+// uploadManifestEntry 上传清单文件中的一行任务
+type uploadManifestEntry struct {
+	Line      int    // 行号（从 1 开始）
+	Text      string // 原始行内容，写入 --failed-out 时原样输出
+	LocalPath string // 本地文件路径
+	Path      string // 远端路径
+	Error     string // 解析错误，非空时不上传直接记为失败
+}
+
+// parseUploadManifest 解析上传清单：每行 "本地路径<TAB>远端路径"；空行和 # 开头的注释行忽略
+// 远端路径按 upload <file> <dest> 的 dest 处理（为根目录时沿用本地文件名）
+func parseUploadManifest(content string) []uploadManifestEntry {
+	entries := make([]uploadManifestEntry, 0)
+	for i, raw := range strings.Split(content, "\n") {
+		text := strings.TrimRight(raw, "\r")
+		trimmed := strings.TrimSpace(text)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		entry := uploadManifestEntry{Line: i + 1, Text: text}
+		tab := strings.Index(text, "\t")
+		if tab < 0 {
+			entry.LocalPath = trimmed
+			entry.Error = "missing remote path (expected local<TAB>remote)"
+			entries = append(entries, entry)
+			continue
+		}
+		entry.LocalPath = strings.TrimSpace(text[:tab])
+		entry.Path = strings.TrimSpace(text[tab+1:])
+		switch {
+		case entry.LocalPath == "":
+			entry.Error = "missing local path"
+		case entry.Path == "":
+			entry.Error = "missing remote path"
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// uploadManifestExecutor 在 TaskQueue 中执行清单上传任务（实现 sdk.TaskExecutor）：由 sdk.UploadExecutor 上传，
+// 每个条目结束时在 stderr 输出一行结果；任务结果为 sdk.UploadResult，失败时返回错误
+type uploadManifestExecutor struct {
+	upload *sdk.UploadExecutor
+	total  int
+	mu     sync.Mutex
+	done   int
+}
+
+func (e *uploadManifestExecutor) Execute(task *sdk.Task) (interface{}, error) {
+	entry, _ := task.Params["entry"].(uploadManifestEntry)
+	result, err := e.uploadEntry(task, entry)
+
+	e.mu.Lock()
+	e.done++
+	switch {
+	case err != nil:
+		fmt.Fprintf(os.Stderr, "[%d/%d] failed %s: %v\n", e.done, e.total, entry.LocalPath, err)
+	case result.Skipped:
+		fmt.Fprintf(os.Stderr, "[%d/%d] skipped %s (already exists)\n", e.done, e.total, entry.LocalPath)
+	default:
+		fmt.Fprintf(os.Stderr, "[%d/%d] uploaded %s -> %s\n", e.done, e.total, entry.LocalPath, result.Path)
+	}
+	e.mu.Unlock()
+	return result, err
+}
+
+func (e *uploadManifestExecutor) uploadEntry(task *sdk.Task, entry uploadManifestEntry) (sdk.UploadResult, error) {
+	if entry.Error != "" {
+		return sdk.UploadResult{}, errors.New(entry.Error)
+	}
+	info, err := os.Stat(entry.LocalPath)
+	if err != nil {
+		return sdk.UploadResult{}, err
+	}
+	if info.IsDir() {
+		return sdk.UploadResult{}, errors.New("local path is a directory")
+	}
+	result, err := e.upload.Execute(&sdk.Task{
+		ID:     task.ID,
+		Type:   sdk.TaskTypeUpload,
+		Params: map[string]interface{}{"file_path": entry.LocalPath, "dest_path": entry.Path, "size": info.Size()},
+	})
+	if err != nil {
+		return sdk.UploadResult{}, err
+	}
+	return result.(sdk.UploadResult), nil
+}
+
+// uploadFromManifest 按清单文件批量上传，用 TaskQueue 控制并发（workers 个文件同时上传），opts 应用于每个文件
+// 单个条目失败不影响其它条目；failedOut 非空时把失败的行原样写入该文件，可直接作为 --from-file 重跑
+// Data 包含 stats（total/uploaded/failed/skipped）、results（每个条目一条，按清单顺序）、failed（失败的条目）、failed_out；有失败时结果为失败（退出码非 0）
+func uploadFromManifest(client *sdk.QuarkClient, manifestPath, failedOut string, workers int, opts sdk.UploadOptions) *CLIResult {
+	content, err := os.ReadFile(manifestPath)
+	if err != nil {
+		return &CLIResult{
+			Success: false,
+			Code:    "INVALID_ARGS",
+			Message: fmt.Sprintf("failed to read --from-file: %v", err),
+		}
+	}
+	entries := parseUploadManifest(string(content))
+	if workers < 1 {
+		workers = sdk.DEFAULT_UPLOAD_WORKERS
+	}
+
+	// 先加入全部任务再启动队列，避免队列空闲时 Wait 提前返回
+	queue := sdk.NewTaskQueue(workers)
+	tasks := make([]*sdk.Task, 0, len(entries))
+	for _, entry := range entries {
+		tasks = append(tasks, queue.AddTask(sdk.TaskTypeUpload, map[string]interface{}{"entry": entry}))
+	}
+	if len(tasks) > 0 {
+		queue.Start(&uploadManifestExecutor{upload: sdk.NewUploadExecutor(client, opts), total: len(tasks)})
+		queue.Wait()
+		queue.Stop()
+	}
+
+	uploaded, skipped := 0, 0
+	results := make([]map[string]interface{}, 0, len(tasks))
+	failed := make([]map[string]interface{}, 0)
+	failedLines := make([]string, 0)
+	for i, task := range tasks {
+		item := map[string]interface{}{
+			"line":       entries[i].Line,
+			"local_path": entries[i].LocalPath,
+			"path":       entries[i].Path,
+		}
+		if task.Status == sdk.TaskStatusCompleted {
+			result, _ := task.Result.(sdk.UploadResult)
+			if result.Path != "" {
+				item["path"] = result.Path // --on-conflict rename 时为实际上传的路径
+			}
+			item["size"] = result.Size
+			if result.Skipped {
+				item["status"] = "skipped"
+				skipped++
+			} else {
+				item["status"] = "uploaded"
+				uploaded++
+			}
+			results = append(results, item)
+			continue
+		}
+		message := "task not completed"
+		if task.Error != nil {
+			message = task.Error.Error()
+		}
+		item["status"] = "failed"
+		item["error"] = message
+		results = append(results, item)
+		failed = append(failed, item)
+		failedLines = append(failedLines, entries[i].Text)
+	}
+
+	data := map[string]interface{}{
+		"stats": map[string]interface{}{
+			"total":    len(tasks),
+			"uploaded": uploaded,
+			"failed":   len(failed),
+			"skipped":  skipped,
+		},
+		"results": results,
+		"failed":  failed,
+	}
+	if failedOut != "" {
+		output := ""
+		if len(failedLines) > 0 {
+			output = strings.Join(failedLines, "\n") + "\n"
+		}
+		if err := os.WriteFile(failedOut, []byte(output), 0644); err != nil {
+			return &CLIResult{
+				Success: false,
+				Code:    "OUTPUT_WRITE_ERROR",
+				Message: fmt.Sprintf("failed to write --failed-out file: %v", err),
+				Data:    data,
+			}
+		}
+		data["failed_out"] = failedOut
+	}
+
+	message := fmt.Sprintf("%d uploaded, %d failed, %d skipped", uploaded, len(failed), skipped)
+	if opts.Context != nil && opts.Context.Err() != nil {
+		return &CLIResult{
+			Success: false,
+			Code:    "INTERRUPTED",
+			Message: "上传已中断，" + message + "，重新执行相同的命令可继续上传",
+			Data:    data,
+		}
+	}
+	if len(failed) > 0 {
+		return &CLIResult{
+			Success: false,
+			Code:    "UPLOAD_PARTIAL_FAILED",
+			Message: message,
+			Data:    data,
+		}
+	}
+	return &CLIResult{
+		Success: true,
+		Code:    "OK",
+		Message: message,
+		Data:    data,
+	}
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"fmt"
+	"kuake_sdk/sdk"
+	"time"
+)
+
+// handleUploadAbort 处理 upload-abort 命令：清理中断后留下的未完成上传
+// 用法: upload-abort（列出本地断点续传状态） | upload-abort <state-id> | upload-abort --all
+// 对选中的状态调用 OSS AbortMultipartUpload 并删除状态文件；Data 包含 aborted（已清理的 id）和 failed（id 与错误）
+func handleUploadAbort(client *sdk.QuarkClient, args []string) *CLIResult {
+	all := false
+	stateID := ""
+	for _, arg := range args {
+		switch arg {
+		case "--all":
+			all = true
+		default:
+			if stateID != "" {
+				return &CLIResult{
+					Success: false,
+					Code:    "INVALID_ARGS",
+					Message: "Usage: upload-abort [--all | <state-id>]",
+				}
+			}
+			stateID = arg
+		}
+	}
+	if all && stateID != "" {
+		return &CLIResult{
+			Success: false,
+			Code:    "INVALID_ARGS",
+			Message: "--all cannot be combined with a state id",
+		}
+	}
+
+	states, err := client.ListUploadStates()
+	if err != nil {
+		return &CLIResult{
+			Success: false,
+			Code:    "UPLOAD_STATE_ERROR",
+			Message: fmt.Sprintf("failed to list upload states: %v", err),
+		}
+	}
+
+	if !all && stateID == "" {
+		return uploadStateListResult(client, states)
+	}
+
+	selected := states
+	if stateID != "" {
+		selected = nil
+		for _, entry := range states {
+			if entry.ID == stateID {
+				selected = append(selected, entry)
+			}
+		}
+		if len(selected) == 0 {
+			return &CLIResult{
+				Success: false,
+				Code:    "UPLOAD_STATE_NOT_FOUND",
+				Message: fmt.Sprintf("no unfinished upload with id %s (run upload-abort without arguments to list them)", stateID),
+			}
+		}
+	}
+
+	aborted := make([]string, 0, len(selected))
+	failed := make([]map[string]interface{}, 0)
+	for _, entry := range selected {
+		if err := client.AbortUpload(entry.State); err != nil {
+			failed = append(failed, map[string]interface{}{"id": entry.ID, "file_path": entry.State.FilePath, "error": err.Error()})
+			continue
+		}
+		aborted = append(aborted, entry.ID)
+	}
+	data := map[string]interface{}{"aborted": aborted, "failed": failed}
+	if len(failed) > 0 {
+		return &CLIResult{
+			Success: false,
+			Code:    "UPLOAD_ABORT_FAILED",
+			Message: fmt.Sprintf("已清理 %d 个未完成的上传，%d 个失败", len(aborted), len(failed)),
+			Data:    data,
+		}
+	}
+	return &CLIResult{
+		Success: true,
+		Code:    "OK",
+		Message: fmt.Sprintf("已清理 %d 个未完成的上传", len(aborted)),
+		Data:    data,
+	}
+}
+
+// handleUploadState 处理 upload-state 命令：查看和清理断点续传状态文件
+// 用法: upload-state list | upload-state purge --older-than <7d|72h|2006-01-02>
+// 状态目录为环境变量 KUAKE_STATE_DIR 或配置 transfer.upload_state_dir，都未设置时为用户缓存目录下的 kuake/upload_state
+func handleUploadState(client *sdk.QuarkClient, args []string) *CLIResult {
+	usage := &CLIResult{
+		Success: false,
+		Code:    "INVALID_ARGS",
+		Message: "Usage: upload-state list | upload-state purge --older-than <duration> (e.g., 7d, 72h or 2006-01-02)",
+	}
+	if len(args) == 0 {
+		return usage
+	}
+	switch args[0] {
+	case "list":
+		if len(args) > 1 {
+			return usage
+		}
+		states, err := client.ListUploadStates()
+		if err != nil {
+			return &CLIResult{
+				Success: false,
+				Code:    "UPLOAD_STATE_ERROR",
+				Message: fmt.Sprintf("failed to list upload states: %v", err),
+			}
+		}
+		return uploadStateListResult(client, states)
+	case "purge":
+		if len(args) != 3 || args[1] != "--older-than" {
+			return usage
+		}
+		before, err := parseTimeArg(args[2], time.Now())
+		if err != nil {
+			return &CLIResult{
+				Success: false,
+				Code:    "INVALID_ARGS",
+				Message: fmt.Sprintf("invalid --older-than value: %v", err),
+			}
+		}
+		purged, err := client.PurgeUploadStates(before)
+		ids := make([]string, 0, len(purged))
+		for _, entry := range purged {
+			ids = append(ids, entry.ID)
+		}
+		data := map[string]interface{}{"purged": ids, "state_dir": client.UploadStateDir()}
+		if err != nil {
+			return &CLIResult{
+				Success: false,
+				Code:    "UPLOAD_STATE_ERROR",
+				Message: fmt.Sprintf("failed to purge upload states: %v", err),
+				Data:    data,
+			}
+		}
+		return &CLIResult{
+			Success: true,
+			Code:    "OK",
+			Message: fmt.Sprintf("已删除 %d 个 %s 之前保存的上传状态", len(ids), before.Format("2006-01-02 15:04:05")),
+			Data:    data,
+		}
+	}
+	return usage
+}
+
+// uploadStateListResult 输出断点续传状态列表：每项包含 id、源文件、目标路径、已完成分片数/总分片数和最后保存时间
+func uploadStateListResult(client *sdk.QuarkClient, states []sdk.UploadStateEntry) *CLIResult {
+	uploads := make([]map[string]interface{}, 0, len(states))
+	for _, entry := range states {
+		uploads = append(uploads, map[string]interface{}{
+			"id":             entry.ID,
+			"file_path":      entry.State.FilePath,
+			"dest_path":      entry.State.DestPath,
+			"file_size":      entry.State.FileSize,
+			"uploaded_parts": len(entry.State.UploadedParts),
+			"total_parts":    entry.State.TotalParts(),
+			"updated_at":     entry.State.CreatedAt.Format(time.RFC3339),
+		})
+	}
+	return &CLIResult{
+		Success: true,
+		Code:    "OK",
+		Message: fmt.Sprintf("%d 个未完成的上传", len(uploads)),
+		Data:    map[string]interface{}{"uploads": uploads, "state_dir": client.UploadStateDir()},
+	}
+}
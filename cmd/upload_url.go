@@ -0,0 +1,68 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"kuake_sdk/sdk"
+	"net/url"
+	"path"
+	"time"
+)
+
+// uploadFromURL 执行 upload --from-url：从直链拉取并上传到 destPath，stderr 上同时显示已拉取的字节数和上传进度
+func uploadFromURL(client *sdk.QuarkClient, sourceURL, destPath string, opts *sdk.UploadOptions) *CLIResult {
+	if cliProgress == progressJSON {
+		name := sourceURL
+		if u, err := url.Parse(sourceURL); err == nil && path.Base(u.Path) != "/" && path.Base(u.Path) != "." {
+			name = path.Base(u.Path)
+		}
+		events := newJSONProgress("upload", name)
+		var last sdk.UploadProgress
+		response, err := client.UploadFromURL(sourceURL, destPath, func(progress *sdk.UploadProgress) {
+			last = *progress
+			if progress.Total == 0 && progress.Progress < 100 {
+				// 长度未知，正在拉取到临时文件
+				events.update("fetch", progress.Fetched, -1, 0, -1)
+				return
+			}
+			eta := progress.Remaining
+			if progress.Speed <= 0 {
+				eta = -1
+			}
+			events.update("", progress.Uploaded, progress.Total, progress.Speed, eta)
+		}, opts)
+		if err == nil && !response.Success {
+			err = errors.New(response.Message)
+		}
+		var speed float64
+		var elapsed time.Duration
+		if last.Total > 0 && last.Uploaded == last.Total {
+			speed, elapsed = last.Speed, last.Elapsed
+		}
+		events.done(last.Uploaded, last.Total, speed, elapsed, false, err)
+		return uploadResult(response, err)
+	}
+
+	textProgress := newTextProgress()
+	fetching := false // 长度未知时先拉取到临时文件，之后换行显示上传进度
+	response, err := client.UploadFromURL(sourceURL, destPath, func(progress *sdk.UploadProgress) {
+		fetched := formatSize(progress.Fetched)
+		if progress.Total > 0 && fetching {
+			textProgress.nextPhase()
+			fetching = false
+		}
+		switch {
+		case progress.Rapid:
+			textProgress.finish(fmt.Sprintf("已拉取: %s | 上传进度: 100%% | 秒传（文件已存在）", fetched))
+		case progress.Progress == 100:
+			textProgress.finish(fmt.Sprintf("已拉取: %s | 上传进度: 100%% | 平均速度: %s | 耗时: %s", fetched, progress.SpeedStr, progress.Elapsed.Round(time.Second)))
+		case progress.Total == 0:
+			fetching = true
+			textProgress.update(fmt.Sprintf("已拉取: %s（长度未知，先写入临时文件）", fetched), 0, -1)
+		default:
+			line := fmt.Sprintf("已拉取: %s | 上传进度: %d%% | 速度: %s | 剩余: %s", fetched, progress.Progress, progress.SpeedStr, progress.RemainingStr)
+			textProgress.update(line, int64(progress.Progress), 100)
+		}
+	}, opts)
+	return uploadResult(response, err)
+}
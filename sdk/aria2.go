@@ -0,0 +1,179 @@
+package sdk
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// aria2RequestSeq JSON-RPC 请求 id 序号
+var aria2RequestSeq int64
+
+// NewAria2Client 创建 aria2 JSON-RPC 客户端，secret 为 aria2 的 --rpc-secret（未设置时传空字符串）
+func NewAria2Client(rpcURL, secret string) *Aria2Client {
+	return &Aria2Client{
+		RPCURL:     rpcURL,
+		Secret:     secret,
+		HttpClient: &http.Client{Timeout: ARIA2_RPC_TIMEOUT},
+	}
+}
+
+// call 调用 aria2 RPC 方法 method，结果解码到 result；设置了 Secret 时自动在参数最前面加上 "token:<secret>"
+func (ac *Aria2Client) call(method string, result interface{}, params ...interface{}) error {
+	if ac.Secret != "" {
+		params = append([]interface{}{"token:" + ac.Secret}, params...)
+	}
+	payload, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      strconv.FormatInt(atomic.AddInt64(&aria2RequestSeq, 1), 10),
+		"method":  method,
+		"params":  params,
+	})
+	if err != nil {
+		return fmt.Errorf("marshal aria2 request: %w", err)
+	}
+	resp, err := ac.HttpClient.Post(ac.RPCURL, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("aria2 %s: %w", method, err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("aria2 %s: read response: %w", method, err)
+	}
+
+	// aria2 出错时返回 4xx 和 JSON-RPC error 对象，优先使用其中的错误信息
+	var rpcResp struct {
+		Result json.RawMessage `json:"result"`
+		Error  *struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(body, &rpcResp); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("aria2 %s: status %d, body: %s", method, resp.StatusCode, string(body))
+		}
+		return fmt.Errorf("aria2 %s: invalid response: %w", method, err)
+	}
+	if rpcResp.Error != nil {
+		return fmt.Errorf("aria2 %s: %s (code %d)", method, rpcResp.Error.Message, rpcResp.Error.Code)
+	}
+	if result != nil {
+		if err := json.Unmarshal(rpcResp.Result, result); err != nil {
+			return fmt.Errorf("aria2 %s: invalid result: %w", method, err)
+		}
+	}
+	return nil
+}
+
+// AddURI 通过 aria2.addUri 提交下载任务，返回任务 gid
+func (ac *Aria2Client) AddURI(uri string, opts Aria2AddOptions) (string, error) {
+	options := map[string]interface{}{}
+	if opts.Dir != "" {
+		options["dir"] = opts.Dir
+	}
+	if opts.Out != "" {
+		options["out"] = opts.Out
+	}
+	if len(opts.Headers) > 0 {
+		names := make([]string, 0, len(opts.Headers))
+		for name := range opts.Headers {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		headers := make([]string, 0, len(names))
+		for _, name := range names {
+			headers = append(headers, fmt.Sprintf("%s: %s", name, opts.Headers[name]))
+		}
+		options["header"] = headers
+	}
+	var gid string
+	if err := ac.call("aria2.addUri", &gid, []string{uri}, options); err != nil {
+		return "", err
+	}
+	return gid, nil
+}
+
+// TellStatus 通过 aria2.tellStatus 查询任务状态
+func (ac *Aria2Client) TellStatus(gid string) (*Aria2Status, error) {
+	// aria2 的数值字段均以字符串返回
+	var raw struct {
+		GID             string `json:"gid"`
+		Status          string `json:"status"`
+		TotalLength     string `json:"totalLength"`
+		CompletedLength string `json:"completedLength"`
+		DownloadSpeed   string `json:"downloadSpeed"`
+		ErrorCode       string `json:"errorCode"`
+		ErrorMessage    string `json:"errorMessage"`
+	}
+	keys := []string{"gid", "status", "totalLength", "completedLength", "downloadSpeed", "errorCode", "errorMessage"}
+	if err := ac.call("aria2.tellStatus", &raw, gid, keys); err != nil {
+		return nil, err
+	}
+	status := &Aria2Status{
+		GID:          raw.GID,
+		Status:       raw.Status,
+		ErrorCode:    raw.ErrorCode,
+		ErrorMessage: raw.ErrorMessage,
+	}
+	status.TotalLength, _ = strconv.ParseInt(raw.TotalLength, 10, 64)
+	status.CompletedLength, _ = strconv.ParseInt(raw.CompletedLength, 10, 64)
+	status.DownloadSpeed, _ = strconv.ParseInt(raw.DownloadSpeed, 10, 64)
+	// 成功完成的任务 errorCode 为 "0"
+	if status.ErrorCode == "0" {
+		status.ErrorCode = ""
+	}
+	return status, nil
+}
+
+// Wait 每隔 interval 查询一次任务状态，直到任务完成、失败或被移除，返回最终状态
+// interval <= 0 时为 ARIA2_POLL_INTERVAL；progress 在每次查询后调用，可为 nil
+// 任务失败或被移除时同时返回最终状态和错误
+func (ac *Aria2Client) Wait(gid string, interval time.Duration, progress func(*Aria2Status)) (*Aria2Status, error) {
+	if interval <= 0 {
+		interval = ARIA2_POLL_INTERVAL
+	}
+	for {
+		status, err := ac.TellStatus(gid)
+		if err != nil {
+			return nil, err
+		}
+		if progress != nil {
+			progress(status)
+		}
+		switch status.Status {
+		case Aria2StatusComplete:
+			return status, nil
+		case Aria2StatusError:
+			return status, fmt.Errorf("aria2 task %s failed: %s (code %s)", gid, status.ErrorMessage, status.ErrorCode)
+		case Aria2StatusRemoved:
+			return status, fmt.Errorf("aria2 task %s was removed", gid)
+		}
+		time.Sleep(interval)
+	}
+}
+
+// AddToAria2 获取 fid 的下载链接并提交给 aria2，自动附带下载链接需要的请求头（Cookie、User-Agent、Referer）
+// dir 为 aria2 主机上的保存目录，out 为相对 dir 的输出文件名，为空时使用网盘中的文件名
+// 返回任务 gid 和下载信息；fid 指向目录时返回 ErrDownloadDirectory
+func (qc *QuarkClient) AddToAria2(aria2 *Aria2Client, fid, dir, out string) (string, *DownloadInfo, error) {
+	info, err := qc.GetDownloadInfo(fid)
+	if err != nil {
+		return "", nil, err
+	}
+	if out == "" {
+		out = info.FileName
+	}
+	gid, err := aria2.AddURI(info.DownloadURL, Aria2AddOptions{Dir: dir, Out: out, Headers: qc.DownloadHeaders()})
+	if err != nil {
+		return "", info, err
+	}
+	return gid, info, nil
+}
@@ -0,0 +1,119 @@
+package sdk
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeAria2Server 模拟 aria2 JSON-RPC：addUri 记录参数并返回 gid，tellStatus 依次返回 statuses 中的状态
+func fakeAria2Server(t *testing.T, secret string, statuses []string) (*httptest.Server, *[][]interface{}) {
+	var added [][]interface{}
+	polls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     string        `json:"id"`
+			Method string        `json:"method"`
+			Params []interface{} `json:"params"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		if secret != "" {
+			if len(req.Params) == 0 || req.Params[0] != "token:"+secret {
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%q,"error":{"code":1,"message":"Unauthorized"}}`, req.ID)
+				return
+			}
+			req.Params = req.Params[1:]
+		}
+		switch req.Method {
+		case "aria2.addUri":
+			added = append(added, req.Params)
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%q,"result":"gid%d"}`, req.ID, len(added))
+		case "aria2.tellStatus":
+			status := statuses[len(statuses)-1]
+			if polls < len(statuses) {
+				status = statuses[polls]
+			}
+			polls++
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%q,"result":{"gid":%q,"status":%q,"totalLength":"100","completedLength":"%d","downloadSpeed":"10","errorCode":"0"}}`,
+				req.ID, req.Params[0], status, 50*polls)
+		default:
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%q,"error":{"code":1,"message":"Method not found"}}`, req.ID)
+		}
+	}))
+	return server, &added
+}
+
+func TestAddToAria2(t *testing.T) {
+	server, added := fakeAria2Server(t, "s3cret", nil)
+	defer server.Close()
+	client := createMockClient(t, func(req *http.Request) (*http.Response, error) {
+		return jsonResponse(req, `{"status":200,"code":0,"data":[{"fid":"f1","file_name":"big.mkv","size":100,"download_url":"https://dl.example.com/f1"}]}`), nil
+	})
+
+	gid, info, err := client.AddToAria2(NewAria2Client(server.URL, "s3cret"), "f1", "/data", "")
+	if err != nil {
+		t.Fatalf("AddToAria2() error = %v", err)
+	}
+	if gid != "gid1" || info.FileName != "big.mkv" {
+		t.Errorf("AddToAria2() = %q, %+v", gid, info)
+	}
+	if len(*added) != 1 {
+		t.Fatalf("addUri called %d times, want 1", len(*added))
+	}
+	params := (*added)[0]
+	if uris, _ := params[0].([]interface{}); len(uris) != 1 || uris[0] != "https://dl.example.com/f1" {
+		t.Errorf("uris = %v", params[0])
+	}
+	options, _ := params[1].(map[string]interface{})
+	if options["dir"] != "/data" || options["out"] != "big.mkv" {
+		t.Errorf("options = %v", options)
+	}
+	headers := fmt.Sprint(options["header"])
+	for _, want := range []string{"Cookie: test_token=value1; test_token2=value2", "Referer: https://pan.quark.cn/", "User-Agent: "} {
+		if !strings.Contains(headers, want) {
+			t.Errorf("headers %s missing %q", headers, want)
+		}
+	}
+
+	if _, err := NewAria2Client(server.URL, "wrong").AddURI("https://dl.example.com/f1", Aria2AddOptions{}); err == nil || !strings.Contains(err.Error(), "Unauthorized") {
+		t.Errorf("AddURI() with wrong secret error = %v, want Unauthorized", err)
+	}
+}
+
+func TestAria2Client_Wait(t *testing.T) {
+	tests := []struct {
+		name      string
+		statuses  []string
+		wantErr   bool
+		wantPolls int
+	}{
+		{name: "complete", statuses: []string{"waiting", "active", "complete"}, wantPolls: 3},
+		{name: "error", statuses: []string{"active", "error"}, wantErr: true, wantPolls: 2},
+		{name: "removed", statuses: []string{"removed"}, wantErr: true, wantPolls: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, _ := fakeAria2Server(t, "", tt.statuses)
+			defer server.Close()
+
+			polls := 0
+			status, err := NewAria2Client(server.URL, "").Wait("gid1", time.Millisecond, func(s *Aria2Status) { polls++ })
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Wait() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if polls != tt.wantPolls || status == nil || status.Status != tt.statuses[len(tt.statuses)-1] {
+				t.Errorf("Wait() polls = %d, status = %+v", polls, status)
+			}
+			if status.TotalLength != 100 || status.ErrorCode != "" {
+				t.Errorf("Wait() status fields = %+v", status)
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package sdk
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+// ConfigCheck 单项配置体检结果
+type ConfigCheck struct {
+	Name       string `json:"name"`                  // 检查项名称
+	OK         bool   `json:"ok"`                    // 是否通过
+	Detail     string `json:"detail"`                // 详细说明（失败原因或检查结果）
+	DurationMs int64  `json:"duration_ms,omitempty"` // 耗时（毫秒），仅在线检查项
+}
+
+// CheckConfig 对配置文件和运行环境做体检
+// 离线检查：配置文件能否解析、字段是否合法、代理配置
+// 在线检查（online 为 true 时）：每个 token 能否通过 GetUserInfo、各 API 域名是否可达
+// cookies: 可选，提供时使用该 cookie 代替配置文件中的 access_tokens 做登录检查
+func CheckConfig(configPath string, online bool, cookies ...string) []ConfigCheck {
+	var checks []ConfigCheck
+
+	// 1. 配置文件能否解析
+	config, err := ReadConfig(configPath)
+	if err != nil {
+		detail := err.Error()
+		if errors.Is(err, os.ErrNotExist) && len(cookies) > 0 && cookies[0] != "" {
+			detail += " (cookies provided, config file is optional)"
+		}
+		checks = append(checks, ConfigCheck{Name: "config_file", OK: false, Detail: detail})
+	} else {
+		checks = append(checks, ConfigCheck{Name: "config_file", OK: true, Detail: "config file parsed"})
+
+		// 2. 字段是否合法
+		if err := config.Validate(); err != nil {
+			checks = append(checks, ConfigCheck{Name: "config_fields", OK: false, Detail: err.Error()})
+		} else {
+			checks = append(checks, ConfigCheck{Name: "config_fields", OK: true, Detail: "all fields valid"})
+		}
+	}
+
+	// 待检查的 token：命令行 cookies 优先，否则使用配置文件中的 access_tokens
+	var tokens []string
+	if len(cookies) > 0 && cookies[0] != "" {
+		tokens = []string{cookies[0]}
+	} else if config != nil {
+		tokens = config.Quark.AccessTokens
+		if len(tokens) == 0 {
+			checks = append(checks, ConfigCheck{Name: "access_tokens", OK: false, Detail: "access_tokens 必须至少配置一个"})
+		}
+	}
+
+	// 3. 代理配置（读取 HTTP_PROXY/HTTPS_PROXY/NO_PROXY 环境变量）
+	endpoints := config.EffectiveEndpoints()
+	domains := []string{endpoints.PanDomain, endpoints.DriveDomain, endpoints.DriveHDomain, OSS_DOMAIN}
+	for _, domain := range domains {
+		checks = append(checks, checkProxy(domain))
+	}
+
+	if !online {
+		return checks
+	}
+
+	// 4. 每个 token 能否通过 GetUserInfo
+	for i, token := range tokens {
+		checks = append(checks, checkToken(configPath, i, token))
+	}
+
+	// 5. 网络可达性
+	for _, domain := range domains {
+		checks = append(checks, checkReachable(domain))
+	}
+
+	return checks
+}
+
+// checkProxy 检查访问指定域名时使用的代理
+func checkProxy(domain string) ConfigCheck {
+	name := "proxy:" + domain
+	req, err := http.NewRequest("HEAD", domain, nil)
+	if err != nil {
+		return ConfigCheck{Name: name, OK: false, Detail: fmt.Sprintf("invalid domain: %v", err)}
+	}
+	proxyURL, err := http.ProxyFromEnvironment(req)
+	if err != nil {
+		return ConfigCheck{Name: name, OK: false, Detail: fmt.Sprintf("invalid proxy config: %v", err)}
+	}
+	if proxyURL == nil {
+		return ConfigCheck{Name: name, OK: true, Detail: "direct (no proxy)"}
+	}
+	return ConfigCheck{Name: name, OK: true, Detail: fmt.Sprintf("via proxy %s", redactProxyURL(proxyURL))}
+}
+
+// redactProxyURL 隐藏代理地址中的密码
+func redactProxyURL(u *url.URL) string {
+	if u.User == nil {
+		return u.String()
+	}
+	if _, hasPassword := u.User.Password(); hasPassword {
+		redacted := *u
+		redacted.User = url.UserPassword(u.User.Username(), "***")
+		return redacted.String()
+	}
+	return u.String()
+}
+
+// checkToken 使用单个 token 调用 GetUserInfo 检查登录状态
+func checkToken(configPath string, index int, token string) (check ConfigCheck) {
+	check.Name = fmt.Sprintf("token[%d]", index)
+	start := time.Now()
+	defer func() {
+		check.DurationMs = time.Since(start).Milliseconds()
+		if r := recover(); r != nil {
+			check.OK = false
+			check.Detail = fmt.Sprintf("failed to create client: %v", r)
+		}
+	}()
+
+	client := NewQuarkClient(configPath, token)
+	resp, err := client.GetUserInfo()
+	if err != nil {
+		check.Detail = err.Error()
+		return check
+	}
+	if !resp.Success {
+		check.Detail = fmt.Sprintf("%s (%s): %s", MaskToken(token), resp.Code, resp.Message)
+		return check
+	}
+	nickname, _ := resp.Data["nickname"].(string)
+	check.OK = true
+	check.Detail = fmt.Sprintf("%s logged in as %q", MaskToken(token), nickname)
+	return check
+}
+
+// checkReachable 检查域名是否可达（收到任何 HTTP 响应即视为可达）
+func checkReachable(domain string) ConfigCheck {
+	name := "network:" + domain
+	client := &http.Client{Timeout: 10 * time.Second}
+	start := time.Now()
+	resp, err := client.Head(domain)
+	elapsed := time.Since(start).Milliseconds()
+	if err != nil {
+		return ConfigCheck{Name: name, OK: false, Detail: err.Error(), DurationMs: elapsed}
+	}
+	resp.Body.Close()
+	return ConfigCheck{Name: name, OK: true, Detail: fmt.Sprintf("HTTP %d", resp.StatusCode), DurationMs: elapsed}
+}
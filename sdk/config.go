@@ -2,9 +2,15 @@ package sdk
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 )
 
 // getExecutableDir 获取可执行文件所在的目录
@@ -56,10 +62,10 @@ func resolveConfigPath(configPath string) (string, error) {
 	return filepath.Join(execDir, configPath), nil
 }
 
-// LoadConfig 从配置文件加载配置
+// ReadConfig 从配置文件读取配置，不校验 access_tokens
+// 用于 config 子命令和命令默认值读取等不需要登录凭证的场景
 // 如果 configPath 为空，使用默认路径 DEFAULT_CONFIG_PATH
-// 相对路径会相对于可执行文件所在目录解析
-func LoadConfig(configPath string) (*Config, error) {
+func ReadConfig(configPath string) (*Config, error) {
 	// 如果配置文件路径为空，使用默认路径
 	if configPath == "" {
 		configPath = DEFAULT_CONFIG_PATH
@@ -83,12 +89,274 @@ func LoadConfig(configPath string) (*Config, error) {
 		return nil, fmt.Errorf("failed to parse config file: %w", err)
 	}
 
+	return &config, nil
+}
+
+// LoadConfig 从配置文件加载配置
+// 如果 configPath 为空，使用默认路径 DEFAULT_CONFIG_PATH
+// 相对路径会相对于可执行文件所在目录解析
+func LoadConfig(configPath string) (*Config, error) {
+	config, err := ReadConfig(configPath)
+	if err != nil {
+		return nil, err
+	}
+
 	// 验证必要的配置项
 	if len(config.Quark.AccessTokens) == 0 {
 		return nil, fmt.Errorf("access_tokens 必须至少配置一个")
 	}
 
-	return &config, nil
+	return config, nil
+}
+
+// EffectiveDefaults 返回合并内置默认值后的生效默认配置
+// 返回值中所有字段均已填充（DownloadDir 为空表示仅返回下载链接）
+func (c *Config) EffectiveDefaults() DefaultsConfig {
+	var d DefaultsConfig
+	if c != nil {
+		d = c.Defaults
+	}
+
+	if d.ShareDays == nil {
+		days := DEFAULT_SHARE_DAYS
+		d.ShareDays = &days
+	}
+	if d.SharePasscode == nil {
+		passcode := DEFAULT_SHARE_PASSCODE
+		d.SharePasscode = &passcode
+	}
+	if d.ListOutput == "" {
+		d.ListOutput = DEFAULT_LIST_OUTPUT
+	}
+	if d.ConflictPolicy == "" {
+		d.ConflictPolicy = DEFAULT_CONFLICT_POLICY
+	}
+	return d
+}
+
+// EffectiveListRetries 返回列目录单页请求的最大重试次数，未配置或 c 为 nil 时返回 LIST_PAGE_MAX_RETRIES
+func (c *Config) EffectiveListRetries() int {
+	if c == nil || c.Transfer.ListRetries == nil {
+		return LIST_PAGE_MAX_RETRIES
+	}
+	return *c.Transfer.ListRetries
+}
+
+// EffectiveDownloadRetries 返回下载的最大重试次数，未配置或 c 为 nil 时返回 DOWNLOAD_MAX_RETRIES
+func (c *Config) EffectiveDownloadRetries() int {
+	if c == nil || c.Transfer.DownloadRetries == nil {
+		return DOWNLOAD_MAX_RETRIES
+	}
+	return *c.Transfer.DownloadRetries
+}
+
+// EffectiveDownloadRetryInterval 返回下载重试前的等待时间，未配置、格式错误或 c 为 nil 时返回 DEFAULT_DOWNLOAD_RETRY_INTERVAL
+func (c *Config) EffectiveDownloadRetryInterval() time.Duration {
+	if c == nil || c.Transfer.DownloadRetryInterval == "" {
+		return DEFAULT_DOWNLOAD_RETRY_INTERVAL
+	}
+	interval, err := time.ParseDuration(c.Transfer.DownloadRetryInterval)
+	if err != nil || interval < 0 {
+		return DEFAULT_DOWNLOAD_RETRY_INTERVAL
+	}
+	return interval
+}
+
+// EffectiveUploadPartTimeout 返回配置的分片传输超时，未配置、格式错误或 c 为 nil 时返回 0（按分片大小计算）
+func (c *Config) EffectiveUploadPartTimeout() time.Duration {
+	if c == nil || c.Transfer.UploadPartTimeout == "" {
+		return 0
+	}
+	timeout, err := time.ParseDuration(c.Transfer.UploadPartTimeout)
+	if err != nil || timeout < 0 {
+		return 0
+	}
+	return timeout
+}
+
+// EffectiveUploadMinSpeed 返回配置的分片上传低速阈值（字节/秒），未配置、格式错误或 c 为 nil 时返回 0（不检测）
+func (c *Config) EffectiveUploadMinSpeed() int64 {
+	if c == nil || c.Transfer.UploadMinSpeed == "" {
+		return 0
+	}
+	speed, err := ParseByteSize(c.Transfer.UploadMinSpeed)
+	if err != nil {
+		return 0
+	}
+	return speed
+}
+
+// EffectiveUploadSpeedWindow 返回配置的低速检测窗口，未配置、格式错误或 c 为 nil 时返回 UPLOAD_SPEED_WINDOW
+func (c *Config) EffectiveUploadSpeedWindow() time.Duration {
+	if c == nil || c.Transfer.UploadSpeedWindow == "" {
+		return UPLOAD_SPEED_WINDOW
+	}
+	window, err := time.ParseDuration(c.Transfer.UploadSpeedWindow)
+	if err != nil || window <= 0 {
+		return UPLOAD_SPEED_WINDOW
+	}
+	return window
+}
+
+// EffectiveUploadStateDir 返回配置的断点续传状态目录，未配置或 c 为 nil 时返回空字符串（使用默认目录，见 QuarkClient.UploadStateDir）
+func (c *Config) EffectiveUploadStateDir() string {
+	if c == nil {
+		return ""
+	}
+	return c.Transfer.UploadStateDir
+}
+
+// ParseByteSize 解析大小：字节数或带 K/M/G/T 后缀（1024 进制，可带 B/iB，如 100M、1.5GB、512K）
+func ParseByteSize(value string) (int64, error) {
+	text := strings.ToUpper(strings.TrimSpace(value))
+	text = strings.TrimSuffix(strings.TrimSuffix(text, "B"), "I")
+	multiplier := float64(1)
+	if n := len(text); n > 0 {
+		switch text[n-1] {
+		case 'K':
+			multiplier = 1 << 10
+		case 'M':
+			multiplier = 1 << 20
+		case 'G':
+			multiplier = 1 << 30
+		case 'T':
+			multiplier = 1 << 40
+		}
+		if multiplier > 1 {
+			text = text[:n-1]
+		}
+	}
+	number, err := strconv.ParseFloat(text, 64)
+	if err != nil || number < 0 {
+		return 0, fmt.Errorf("expected bytes or size with K/M/G/T suffix, got %q", value)
+	}
+	return int64(number * multiplier), nil
+}
+
+// EffectiveEndpoints 返回合并内置默认域名后的生效 API 域名，c 为 nil 时返回全部默认值
+func (c *Config) EffectiveEndpoints() EndpointsConfig {
+	e := EndpointsConfig{
+		PanDomain:    PAN_DOMAIN,
+		DriveDomain:  DRIVE_DOMAIN,
+		DriveHDomain: DRIVE_H_DOMAIN,
+	}
+	if c == nil {
+		return e
+	}
+	if c.Endpoints.PanDomain != "" {
+		e.PanDomain = strings.TrimRight(c.Endpoints.PanDomain, "/")
+	}
+	if c.Endpoints.DriveDomain != "" {
+		e.DriveDomain = strings.TrimRight(c.Endpoints.DriveDomain, "/")
+	}
+	if c.Endpoints.DriveHDomain != "" {
+		e.DriveHDomain = strings.TrimRight(c.Endpoints.DriveHDomain, "/")
+	}
+	return e
+}
+
+// validateEndpoint 校验域名覆盖值必须是带 http/https 协议的绝对 URL
+func validateEndpoint(value string) error {
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("must be an absolute http(s) URL, got %q", value)
+	}
+	return nil
+}
+
+// Validate 校验配置字段取值是否合法，返回所有不合法字段汇总后的错误
+func (c *Config) Validate() error {
+	var errs []error
+	for i, token := range c.Quark.AccessTokens {
+		if strings.TrimSpace(token) == "" {
+			errs = append(errs, fmt.Errorf("Quark.access_tokens[%d] is empty", i))
+		} else if !strings.Contains(token, "=") {
+			errs = append(errs, fmt.Errorf("Quark.access_tokens[%d] is not a cookie string (key=value)", i))
+		}
+	}
+
+	d := c.Defaults
+	if d.ShareDays != nil && *d.ShareDays < 0 {
+		errs = append(errs, fmt.Errorf("defaults.share_days must be >= 0"))
+	}
+	if d.ListOutput != "" && d.ListOutput != "json" && d.ListOutput != "stream" {
+		errs = append(errs, fmt.Errorf("defaults.list_output must be 'json' or 'stream'"))
+	}
+	switch UploadPolicy(d.ConflictPolicy) {
+	case "", UploadPolicySkip, UploadPolicyOverwrite, UploadPolicyRsync:
+	default:
+		errs = append(errs, fmt.Errorf("defaults.conflict_policy must be 'skip', 'overwrite', or 'rsync'"))
+	}
+
+	if p := c.Transfer.UploadParallel; p != 0 && (p < MIN_UPLOAD_PARALLEL || p > MAX_UPLOAD_PARALLEL) {
+		errs = append(errs, fmt.Errorf("transfer.upload_parallel must be between %d and %d", MIN_UPLOAD_PARALLEL, MAX_UPLOAD_PARALLEL))
+	}
+	if r := c.Transfer.ListRetries; r != nil && (*r < 0 || *r > MAX_LIST_RETRIES) {
+		errs = append(errs, fmt.Errorf("transfer.list_retries must be between 0 and %d", MAX_LIST_RETRIES))
+	}
+	if r := c.Transfer.DownloadRetries; r != nil && (*r < 0 || *r > MAX_DOWNLOAD_RETRIES) {
+		errs = append(errs, fmt.Errorf("transfer.download_retries must be between 0 and %d", MAX_DOWNLOAD_RETRIES))
+	}
+	if interval := c.Transfer.DownloadRetryInterval; interval != "" {
+		if d, err := time.ParseDuration(interval); err != nil || d < 0 {
+			errs = append(errs, fmt.Errorf("transfer.download_retry_interval must be a non-negative duration (e.g., 5s), got %q", interval))
+		}
+	}
+	if timeout := c.Transfer.UploadPartTimeout; timeout != "" {
+		if d, err := time.ParseDuration(timeout); err != nil || d < 0 {
+			errs = append(errs, fmt.Errorf("transfer.upload_part_timeout must be a non-negative duration (e.g., 10m), got %q", timeout))
+		}
+	}
+	if speed := c.Transfer.UploadMinSpeed; speed != "" {
+		if _, err := ParseByteSize(speed); err != nil {
+			errs = append(errs, fmt.Errorf("transfer.upload_min_speed must be bytes/s with optional K/M/G suffix (e.g., 512K), got %q", speed))
+		}
+	}
+	if window := c.Transfer.UploadSpeedWindow; window != "" {
+		if d, err := time.ParseDuration(window); err != nil || d <= 0 {
+			errs = append(errs, fmt.Errorf("transfer.upload_speed_window must be a positive duration (e.g., 30s), got %q", window))
+		}
+	}
+
+	endpoints := map[string]string{
+		"endpoints.pan_domain":     c.Endpoints.PanDomain,
+		"endpoints.drive_domain":   c.Endpoints.DriveDomain,
+		"endpoints.drive_h_domain": c.Endpoints.DriveHDomain,
+	}
+	for _, key := range []string{"endpoints.pan_domain", "endpoints.drive_domain", "endpoints.drive_h_domain"} {
+		if value := endpoints[key]; value != "" {
+			if err := validateEndpoint(value); err != nil {
+				errs = append(errs, fmt.Errorf("%s %w", key, err))
+			}
+		}
+	}
+
+	for i, pattern := range c.Sync.Ignore {
+		if err := ValidateIgnorePattern(pattern); err != nil {
+			errs = append(errs, fmt.Errorf("sync.ignore[%d]: %w", i, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// IgnoreMatcher 根据 sync.ignore 与命令行 --ignore-file 中的模式构建排除匹配器
+// 命令行模式排在配置之后，同一路径冲突时命令行优先；本地 .kuakeignore 由遍历目录时通过 WithIgnoreFile 追加
+func (c *Config) IgnoreMatcher(excludes ...string) (*IgnoreMatcher, error) {
+	var patterns []string
+	if c != nil {
+		patterns = append(patterns, c.Sync.Ignore...)
+	}
+	patterns = append(patterns, excludes...)
+	return NewIgnoreMatcher(patterns...)
+}
+
+// MaskToken 对敏感的 token/cookie 做脱敏处理，仅保留前后少量字符
+func MaskToken(token string) string {
+	if len(token) <= 8 {
+		return "***"
+	}
+	return fmt.Sprintf("%s***%s (%d chars)", token[:4], token[len(token)-4:], len(token))
 }
 
 // SaveConfig 保存配置到文件
@@ -112,10 +380,303 @@ func SaveConfig(configPath string, config *Config) error {
 		return fmt.Errorf("failed to marshal config: %w", err)
 	}
 
-	// 写入文件
-	if err := os.WriteFile(resolvedPath, data, 0644); err != nil {
+	// 先写入同目录临时文件再重命名，避免写入中断导致配置文件损坏
+	tmpFile, err := os.CreateTemp(filepath.Dir(resolvedPath), ".config-*.tmp")
+	if err != nil {
 		return fmt.Errorf("failed to write config file %s: %w", resolvedPath, err)
 	}
+	tmpPath := tmpFile.Name()
+	defer os.Remove(tmpPath)
 
+	if _, err := tmpFile.Write(data); err != nil {
+		tmpFile.Close()
+		return fmt.Errorf("failed to write config file %s: %w", resolvedPath, err)
+	}
+	if err := tmpFile.Close(); err != nil {
+		return fmt.Errorf("failed to write config file %s: %w", resolvedPath, err)
+	}
+	if err := os.Chmod(tmpPath, 0644); err != nil {
+		return fmt.Errorf("failed to write config file %s: %w", resolvedPath, err)
+	}
+	if err := os.Rename(tmpPath, resolvedPath); err != nil {
+		return fmt.Errorf("failed to write config file %s: %w", resolvedPath, err)
+	}
+
+	return nil
+}
+
+// configField 可通过 config set/unset 修改的配置项
+type configField struct {
+	set   func(c *Config, value string) error // 解析并校验取值后写入
+	unset func(c *Config)                     // 恢复为未配置
+}
+
+// configFields 可写配置项，key 为点分路径（与配置文件 JSON 字段一致）
+var configFields = map[string]configField{
+	"defaults.download_dir": {
+		set: func(c *Config, value string) error {
+			if strings.TrimSpace(value) == "" {
+				return fmt.Errorf("download_dir cannot be empty, use unset instead")
+			}
+			c.Defaults.DownloadDir = value
+			return nil
+		},
+		unset: func(c *Config) { c.Defaults.DownloadDir = "" },
+	},
+	"defaults.share_days": {
+		set: func(c *Config, value string) error {
+			days, err := strconv.Atoi(value)
+			if err != nil || days < 0 {
+				return fmt.Errorf("share_days must be an integer >= 0 (0=permanent, 1/7/30=days)")
+			}
+			c.Defaults.ShareDays = &days
+			return nil
+		},
+		unset: func(c *Config) { c.Defaults.ShareDays = nil },
+	},
+	"defaults.share_passcode": {
+		set: func(c *Config, value string) error {
+			passcode, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("share_passcode must be 'true' or 'false'")
+			}
+			c.Defaults.SharePasscode = &passcode
+			return nil
+		},
+		unset: func(c *Config) { c.Defaults.SharePasscode = nil },
+	},
+	"defaults.list_output": {
+		set: func(c *Config, value string) error {
+			if value != "json" && value != "stream" {
+				return fmt.Errorf("list_output must be 'json' or 'stream'")
+			}
+			c.Defaults.ListOutput = value
+			return nil
+		},
+		unset: func(c *Config) { c.Defaults.ListOutput = "" },
+	},
+	"defaults.conflict_policy": {
+		set: func(c *Config, value string) error {
+			switch UploadPolicy(value) {
+			case UploadPolicySkip, UploadPolicyOverwrite, UploadPolicyRsync:
+			default:
+				return fmt.Errorf("conflict_policy must be 'skip', 'overwrite', or 'rsync'")
+			}
+			c.Defaults.ConflictPolicy = value
+			return nil
+		},
+		unset: func(c *Config) { c.Defaults.ConflictPolicy = "" },
+	},
+	"endpoints.pan_domain":     endpointField(func(c *Config) *string { return &c.Endpoints.PanDomain }),
+	"endpoints.drive_domain":   endpointField(func(c *Config) *string { return &c.Endpoints.DriveDomain }),
+	"endpoints.drive_h_domain": endpointField(func(c *Config) *string { return &c.Endpoints.DriveHDomain }),
+	"sync.ignore": {
+		set: func(c *Config, value string) error {
+			var patterns []string
+			for _, pattern := range strings.Split(value, ",") {
+				pattern = strings.TrimSpace(pattern)
+				if pattern == "" {
+					continue
+				}
+				if err := ValidateIgnorePattern(pattern); err != nil {
+					return err
+				}
+				patterns = append(patterns, pattern)
+			}
+			if len(patterns) == 0 {
+				return fmt.Errorf("sync.ignore cannot be empty, use unset instead")
+			}
+			c.Sync.Ignore = patterns
+			return nil
+		},
+		unset: func(c *Config) { c.Sync.Ignore = nil },
+	},
+	"transfer.upload_parallel": {
+		set: func(c *Config, value string) error {
+			parallel, err := strconv.Atoi(value)
+			if err != nil || parallel < MIN_UPLOAD_PARALLEL || parallel > MAX_UPLOAD_PARALLEL {
+				return fmt.Errorf("upload_parallel must be an integer between %d and %d", MIN_UPLOAD_PARALLEL, MAX_UPLOAD_PARALLEL)
+			}
+			c.Transfer.UploadParallel = parallel
+			return nil
+		},
+		unset: func(c *Config) { c.Transfer.UploadParallel = 0 },
+	},
+	"transfer.list_retries": {
+		set: func(c *Config, value string) error {
+			retries, err := strconv.Atoi(value)
+			if err != nil || retries < 0 || retries > MAX_LIST_RETRIES {
+				return fmt.Errorf("list_retries must be an integer between 0 and %d", MAX_LIST_RETRIES)
+			}
+			c.Transfer.ListRetries = &retries
+			return nil
+		},
+		unset: func(c *Config) { c.Transfer.ListRetries = nil },
+	},
+	"transfer.download_retries": {
+		set: func(c *Config, value string) error {
+			retries, err := strconv.Atoi(value)
+			if err != nil || retries < 0 || retries > MAX_DOWNLOAD_RETRIES {
+				return fmt.Errorf("download_retries must be an integer between 0 and %d", MAX_DOWNLOAD_RETRIES)
+			}
+			c.Transfer.DownloadRetries = &retries
+			return nil
+		},
+		unset: func(c *Config) { c.Transfer.DownloadRetries = nil },
+	},
+	"transfer.download_retry_interval": {
+		set: func(c *Config, value string) error {
+			if d, err := time.ParseDuration(value); err != nil || d < 0 {
+				return fmt.Errorf("download_retry_interval must be a non-negative duration (e.g., 5s)")
+			}
+			c.Transfer.DownloadRetryInterval = value
+			return nil
+		},
+		unset: func(c *Config) { c.Transfer.DownloadRetryInterval = "" },
+	},
+	"transfer.upload_part_timeout": {
+		set: func(c *Config, value string) error {
+			if d, err := time.ParseDuration(value); err != nil || d < 0 {
+				return fmt.Errorf("upload_part_timeout must be a non-negative duration (e.g., 10m, 0 to compute from the part size)")
+			}
+			c.Transfer.UploadPartTimeout = value
+			return nil
+		},
+		unset: func(c *Config) { c.Transfer.UploadPartTimeout = "" },
+	},
+	"transfer.upload_min_speed": {
+		set: func(c *Config, value string) error {
+			if _, err := ParseByteSize(value); err != nil {
+				return fmt.Errorf("upload_min_speed must be bytes/s with optional K/M/G suffix (e.g., 512K, 0 to disable)")
+			}
+			c.Transfer.UploadMinSpeed = value
+			return nil
+		},
+		unset: func(c *Config) { c.Transfer.UploadMinSpeed = "" },
+	},
+	"transfer.upload_state_dir": {
+		set: func(c *Config, value string) error {
+			if strings.TrimSpace(value) == "" {
+				return fmt.Errorf("upload_state_dir must not be empty (use unset to restore the default)")
+			}
+			c.Transfer.UploadStateDir = value
+			return nil
+		},
+		unset: func(c *Config) { c.Transfer.UploadStateDir = "" },
+	},
+	"transfer.sanitize_names": {
+		set: func(c *Config, value string) error {
+			sanitize, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("sanitize_names must be 'true' or 'false'")
+			}
+			c.Transfer.SanitizeNames = sanitize
+			return nil
+		},
+		unset: func(c *Config) { c.Transfer.SanitizeNames = false },
+	},
+	"transfer.upload_speed_window": {
+		set: func(c *Config, value string) error {
+			if d, err := time.ParseDuration(value); err != nil || d <= 0 {
+				return fmt.Errorf("upload_speed_window must be a positive duration (e.g., 30s)")
+			}
+			c.Transfer.UploadSpeedWindow = value
+			return nil
+		},
+		unset: func(c *Config) { c.Transfer.UploadSpeedWindow = "" },
+	},
+}
+
+// endpointField 构造域名覆盖配置项，field 返回要读写的字段指针
+func endpointField(field func(c *Config) *string) configField {
+	return configField{
+		set: func(c *Config, value string) error {
+			if err := validateEndpoint(value); err != nil {
+				return err
+			}
+			*field(c) = value
+			return nil
+		},
+		unset: func(c *Config) { *field(c) = "" },
+	}
+}
+
+// ConfigKeys 返回所有可通过 config set/unset 修改的配置项（已排序）
+func ConfigKeys() []string {
+	keys := make([]string, 0, len(configFields))
+	for key := range configFields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// SetConfigValue 按点分路径设置配置项，value 会按字段类型解析并校验
+func SetConfigValue(c *Config, key, value string) error {
+	field, ok := configFields[strings.ToLower(key)]
+	if !ok {
+		return fmt.Errorf("unknown or read-only config key: %s", key)
+	}
+	return field.set(c, value)
+}
+
+// UnsetConfigValue 按点分路径删除配置项，使其回退到内置默认值
+func UnsetConfigValue(c *Config, key string) error {
+	field, ok := configFields[strings.ToLower(key)]
+	if !ok {
+		return fmt.Errorf("unknown or read-only config key: %s", key)
+	}
+	field.unset(c)
 	return nil
 }
+
+// GetConfigValue 按点分路径读取配置项（支持读取整段，如 "defaults"）
+// access_tokens 只返回脱敏摘要；已知但未设置的配置项返回 nil
+func GetConfigValue(c *Config, key string) (interface{}, error) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+	var current interface{}
+	if err := json.Unmarshal(data, &current); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+	}
+
+	// access_tokens 脱敏
+	if root, ok := current.(map[string]interface{}); ok {
+		if quark, ok := root["Quark"].(map[string]interface{}); ok {
+			if tokens, ok := quark["access_tokens"].([]interface{}); ok {
+				masked := make([]interface{}, 0, len(tokens))
+				for _, token := range tokens {
+					s, _ := token.(string)
+					masked = append(masked, MaskToken(s))
+				}
+				quark["access_tokens"] = masked
+			}
+		}
+	}
+
+	for _, segment := range strings.Split(key, ".") {
+		obj, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("unknown config key: %s", key)
+		}
+		// 段名大小写不敏感（兼容 "Quark" 段）
+		var next interface{}
+		found := false
+		for k, v := range obj {
+			if strings.EqualFold(k, segment) {
+				next, found = v, true
+				break
+			}
+		}
+		if !found {
+			if _, known := configFields[strings.ToLower(key)]; known {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("unknown config key: %s", key)
+		}
+		current = next
+	}
+	return current, nil
+}
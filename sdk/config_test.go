@@ -3,6 +3,7 @@ package sdk
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -166,3 +167,215 @@ func TestLoadConfig_DefaultPath(t *testing.T) {
 	}
 }
 
+func TestEffectiveDefaults(t *testing.T) {
+	days := 0
+	passcode := true
+
+	tests := []struct {
+		name   string
+		config *Config
+		check  func(t *testing.T, d DefaultsConfig)
+	}{
+		{
+			name:   "nil config uses built-in defaults",
+			config: nil,
+			check: func(t *testing.T, d DefaultsConfig) {
+				if d.ShareDays == nil || *d.ShareDays != DEFAULT_SHARE_DAYS {
+					t.Errorf("ShareDays = %v, want %d", d.ShareDays, DEFAULT_SHARE_DAYS)
+				}
+				if d.SharePasscode == nil || *d.SharePasscode != DEFAULT_SHARE_PASSCODE {
+					t.Errorf("SharePasscode = %v, want %v", d.SharePasscode, DEFAULT_SHARE_PASSCODE)
+				}
+				if d.ListOutput != DEFAULT_LIST_OUTPUT {
+					t.Errorf("ListOutput = %q, want %q", d.ListOutput, DEFAULT_LIST_OUTPUT)
+				}
+				if d.ConflictPolicy != DEFAULT_CONFLICT_POLICY {
+					t.Errorf("ConflictPolicy = %q, want %q", d.ConflictPolicy, DEFAULT_CONFLICT_POLICY)
+				}
+				if d.DownloadDir != "" {
+					t.Errorf("DownloadDir = %q, want empty", d.DownloadDir)
+				}
+			},
+		},
+		{
+			name: "configured values override built-in defaults",
+			config: &Config{Defaults: DefaultsConfig{
+				DownloadDir:    "/tmp/downloads",
+				ShareDays:      &days,
+				SharePasscode:  &passcode,
+				ListOutput:     "stream",
+				ConflictPolicy: "overwrite",
+			}},
+			check: func(t *testing.T, d DefaultsConfig) {
+				if *d.ShareDays != 0 {
+					t.Errorf("ShareDays = %d, want 0", *d.ShareDays)
+				}
+				if !*d.SharePasscode {
+					t.Errorf("SharePasscode = false, want true")
+				}
+				if d.ListOutput != "stream" || d.ConflictPolicy != "overwrite" || d.DownloadDir != "/tmp/downloads" {
+					t.Errorf("unexpected defaults: %+v", d)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.check(t, tt.config.EffectiveDefaults())
+		})
+	}
+}
+
+func TestReadConfig_WithoutTokens(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(tmpFile, []byte(`{"defaults": {"download_dir": "./dl"}}`), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	config, err := ReadConfig(tmpFile)
+	if err != nil {
+		t.Fatalf("ReadConfig() error = %v", err)
+	}
+	if config.Defaults.DownloadDir != "./dl" {
+		t.Errorf("DownloadDir = %q, want %q", config.Defaults.DownloadDir, "./dl")
+	}
+
+	if _, err := LoadConfig(tmpFile); err == nil {
+		t.Errorf("LoadConfig() should fail without access_tokens")
+	}
+}
+
+func TestSetConfigValue(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     string
+		value   string
+		wantErr bool
+	}{
+		{name: "set upload parallel", key: "transfer.upload_parallel", value: "8", wantErr: false},
+		{name: "upload parallel out of range", key: "transfer.upload_parallel", value: "32", wantErr: true},
+		{name: "upload parallel not a number", key: "transfer.upload_parallel", value: "abc", wantErr: true},
+		{name: "set list retries", key: "transfer.list_retries", value: "0", wantErr: false},
+		{name: "list retries out of range", key: "transfer.list_retries", value: "11", wantErr: true},
+		{name: "set download retries", key: "transfer.download_retries", value: "5", wantErr: false},
+		{name: "download retries out of range", key: "transfer.download_retries", value: "11", wantErr: true},
+		{name: "set download retry interval", key: "transfer.download_retry_interval", value: "500ms", wantErr: false},
+		{name: "invalid download retry interval", key: "transfer.download_retry_interval", value: "5", wantErr: true},
+		{name: "set upload part timeout", key: "transfer.upload_part_timeout", value: "10m", wantErr: false},
+		{name: "negative upload part timeout", key: "transfer.upload_part_timeout", value: "-1s", wantErr: true},
+		{name: "set upload min speed", key: "transfer.upload_min_speed", value: "512K", wantErr: false},
+		{name: "invalid upload min speed", key: "transfer.upload_min_speed", value: "fast", wantErr: true},
+		{name: "set upload speed window", key: "transfer.upload_speed_window", value: "30s", wantErr: false},
+		{name: "zero upload speed window", key: "transfer.upload_speed_window", value: "0s", wantErr: true},
+		{name: "set upload state dir", key: "transfer.upload_state_dir", value: "/var/cache/kuake", wantErr: false},
+		{name: "empty upload state dir", key: "transfer.upload_state_dir", value: " ", wantErr: true},
+		{name: "set sanitize names", key: "transfer.sanitize_names", value: "true", wantErr: false},
+		{name: "invalid sanitize names", key: "transfer.sanitize_names", value: "yes", wantErr: true},
+		{name: "set share days zero", key: "defaults.share_days", value: "0", wantErr: false},
+		{name: "negative share days", key: "defaults.share_days", value: "-1", wantErr: true},
+		{name: "set share passcode", key: "defaults.share_passcode", value: "true", wantErr: false},
+		{name: "invalid share passcode", key: "defaults.share_passcode", value: "yes please", wantErr: true},
+		{name: "set list output", key: "defaults.list_output", value: "stream", wantErr: false},
+		{name: "invalid list output", key: "defaults.list_output", value: "xml", wantErr: true},
+		{name: "invalid conflict policy", key: "defaults.conflict_policy", value: "rename", wantErr: true},
+		{name: "set drive domain", key: "endpoints.drive_domain", value: "http://127.0.0.1:8080", wantErr: false},
+		{name: "invalid drive domain", key: "endpoints.drive_domain", value: "ftp://x", wantErr: true},
+		{name: "set sync ignore", key: "sync.ignore", value: "*.log, node_modules/", wantErr: false},
+		{name: "invalid sync ignore", key: "sync.ignore", value: "[abc", wantErr: true},
+		{name: "access tokens are read-only", key: "quark.access_tokens", value: "x", wantErr: true},
+		{name: "unknown key", key: "defaults.unknown", value: "x", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &Config{}
+			err := SetConfigValue(config, tt.key, tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("SetConfigValue() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGetConfigValue(t *testing.T) {
+	config := &Config{}
+	config.Quark.AccessTokens = []string{"__pus=abcdefghijklmnopqrstuvwxyz;"}
+	if err := SetConfigValue(config, "transfer.upload_parallel", "8"); err != nil {
+		t.Fatalf("SetConfigValue() error = %v", err)
+	}
+
+	value, err := GetConfigValue(config, "transfer.upload_parallel")
+	if err != nil {
+		t.Fatalf("GetConfigValue() error = %v", err)
+	}
+	if value != float64(8) {
+		t.Errorf("GetConfigValue() = %v, want 8", value)
+	}
+
+	// 已知但未设置的配置项返回 nil
+	value, err = GetConfigValue(config, "defaults.download_dir")
+	if err != nil || value != nil {
+		t.Errorf("GetConfigValue(unset) = %v, %v, want nil, nil", value, err)
+	}
+
+	// access_tokens 只返回脱敏摘要
+	value, err = GetConfigValue(config, "quark.access_tokens")
+	if err != nil {
+		t.Fatalf("GetConfigValue() error = %v", err)
+	}
+	tokens, ok := value.([]interface{})
+	if !ok || len(tokens) != 1 || strings.Contains(tokens[0].(string), "abcdefghijklmnop") {
+		t.Errorf("access_tokens not masked: %v", value)
+	}
+
+	if _, err := GetConfigValue(config, "nope.nothing"); err == nil {
+		t.Errorf("GetConfigValue() should fail for unknown key")
+	}
+
+	// unset 后回退为未设置
+	if err := UnsetConfigValue(config, "transfer.upload_parallel"); err != nil {
+		t.Fatalf("UnsetConfigValue() error = %v", err)
+	}
+	if config.Transfer.UploadParallel != 0 {
+		t.Errorf("UploadParallel = %d after unset, want 0", config.Transfer.UploadParallel)
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	days := -1
+	tests := []struct {
+		name    string
+		modify  func(c *Config)
+		wantErr bool
+	}{
+		{name: "valid config", modify: func(c *Config) {}, wantErr: false},
+		{name: "empty token", modify: func(c *Config) { c.Quark.AccessTokens = []string{" "} }, wantErr: true},
+		{name: "token without key=value", modify: func(c *Config) { c.Quark.AccessTokens = []string{"abcdef"} }, wantErr: true},
+		{name: "negative share days", modify: func(c *Config) { c.Defaults.ShareDays = &days }, wantErr: true},
+		{name: "invalid list output", modify: func(c *Config) { c.Defaults.ListOutput = "xml" }, wantErr: true},
+		{name: "invalid conflict policy", modify: func(c *Config) { c.Defaults.ConflictPolicy = "rename" }, wantErr: true},
+		{name: "upload parallel out of range", modify: func(c *Config) { c.Transfer.UploadParallel = 99 }, wantErr: true},
+		{name: "list retries out of range", modify: func(c *Config) { retries := -1; c.Transfer.ListRetries = &retries }, wantErr: true},
+		{name: "download retries out of range", modify: func(c *Config) { retries := 20; c.Transfer.DownloadRetries = &retries }, wantErr: true},
+		{name: "negative download retry interval", modify: func(c *Config) { c.Transfer.DownloadRetryInterval = "-1s" }, wantErr: true},
+		{name: "invalid upload part timeout", modify: func(c *Config) { c.Transfer.UploadPartTimeout = "forever" }, wantErr: true},
+		{name: "invalid upload min speed", modify: func(c *Config) { c.Transfer.UploadMinSpeed = "-1K" }, wantErr: true},
+		{name: "invalid upload speed window", modify: func(c *Config) { c.Transfer.UploadSpeedWindow = "soon" }, wantErr: true},
+		{name: "valid endpoint override", modify: func(c *Config) { c.Endpoints.DriveDomain = "http://127.0.0.1:8080" }, wantErr: false},
+		{name: "endpoint without scheme", modify: func(c *Config) { c.Endpoints.PanDomain = "pan.example.com" }, wantErr: true},
+		{name: "invalid sync ignore pattern", modify: func(c *Config) { c.Sync.Ignore = []string{"[abc"} }, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &Config{}
+			config.Quark.AccessTokens = []string{"__pus=test_token;"}
+			tt.modify(config)
+			err := config.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -1,15 +1,38 @@
 package sdk
 
+import "time"
+
 // 域名常量
 const (
 	PAN_DOMAIN     = "https://pan.quark.cn"      // 主要用于用户信息获取
 	DRIVE_DOMAIN   = "https://drive-pc.quark.cn" // 主要用于大部分API请求
 	DRIVE_H_DOMAIN = "https://drive-h.quark.cn"  // save_share_file部分请求
+	OSS_DOMAIN     = "https://pds.quark.cn"      // 分片上传 OSS 域名（预上传响应 upload_url 的默认值）
 )
 
 // 配置相关常量
 const (
-	DEFAULT_CONFIG_PATH = "config.json" // 默认配置文件路径
+	DEFAULT_CONFIG_PATH = "config.json"  // 默认配置文件路径
+	IGNORE_FILE_NAME    = ".kuakeignore" // 本地目录排除规则文件名（gitignore 风格，就近生效）
+)
+
+// 命令内置默认值（配置文件 defaults 段未设置时使用）
+const (
+	DEFAULT_SHARE_DAYS      = 7      // 分享默认有效期（天）
+	DEFAULT_SHARE_PASSCODE  = false  // 分享默认不需要提取码
+	DEFAULT_LIST_OUTPUT     = "json" // list 默认输出完整 JSON
+	DEFAULT_CONFLICT_POLICY = "skip" // 上传默认跳过同名文件
+)
+
+// 传输配置取值范围
+const (
+	MIN_UPLOAD_PARALLEL = 1
+	MAX_UPLOAD_PARALLEL = 16
+
+	UPLOAD_PARALLEL_ENV = "KUAKE_UPLOAD_PARALLEL" // UploadOptions.Parallel 未设置时读取的上传并发数环境变量
+	UPLOAD_STATE_ENV    = "KUAKE_STATE_DIR"       // 断点续传状态目录环境变量，优先于配置 transfer.upload_state_dir
+
+	UPLOAD_PREFETCH_BUFFERS = 2 // 顺序上传的分片缓冲数：上传一片的同时后台预读下一片
 )
 
 // 用户信息
@@ -24,16 +47,114 @@ const (
 	FILE_UPDATE_HASH   = "/1/clouddrive/file/update/hash"
 	FILE_UPLOAD_AUTH   = "/1/clouddrive/file/upload/auth"
 	FILE_UPLOAD_FINISH = "/1/clouddrive/file/upload/finish"
+
+	UPLOAD_PART_MIN_SPEED      = 64 * 1024        // 计算分片传输超时时按此最低速率（字节/秒）估算上传一个分片所需的时间
+	UPLOAD_PART_TIMEOUT_MARGIN = time.Minute      // 分片传输超时在估算时间之外额外留出的余量
+	UPLOAD_CONNECT_TIMEOUT     = 30 * time.Second // 分片上传建立 TCP 连接和 TLS 握手的超时时间
+	UPLOAD_STALL_TIMEOUT       = time.Minute      // 超过传输超时后，最近这段时间内仍有进展则继续等待，否则取消请求
+	UPLOAD_SPEED_WINDOW        = 30 * time.Second // 低速检测（transfer.upload_min_speed）默认的统计窗口
+
+	UPLOAD_STATE_DIR = "kuake/upload_state" // 断点续传状态文件所在目录（相对用户缓存目录），上传成功后删除对应的状态文件
+
+	OSS_MAX_PARTS = 10000 // OSS 分片上传的最大分片数，超大文件按此调大分片
+
+	DEFAULT_UPLOAD_WORKERS = 2  // 目录上传默认同时上传的文件数（每个文件内部另有分片并发）
+	MAX_UPLOAD_WORKERS     = 16 // 目录上传并发数上限
 )
 
 // 文件下载
 const (
 	FILE_DOWNLOAD = "/1/clouddrive/file/download"
+
+	DEFAULT_DOWNLOAD_WORKERS = 4  // 目录下载默认同时下载的文件数
+	MAX_DOWNLOAD_WORKERS     = 16 // 目录下载并发数上限
+
+	DOWNLOAD_PART_SUFFIX = ".kuake-tmp"      // 下载中的临时文件后缀，校验完成后原子重命名为目标文件
+	DOWNLOAD_META_SUFFIX = ".kuake-tmp.meta" // 断点续传信息文件后缀（fid、下载 URL、期望大小、ETag）
+
+	MAX_DOWNLOAD_CONNECTIONS  = 16          // 分段下载的最大连接数
+	MIN_DOWNLOAD_SEGMENT_SIZE = 1024 * 1024 // 分段下载每段的最小字节数，文件太小时减少段数或退回单连接
+
+	DOWNLOAD_DISK_SPACE_RESERVE = 16 * 1024 * 1024 // 下载前检查剩余空间时在文件大小之外额外保留的字节数
+
+	RATE_LIMIT_MIN_BURST = 32 * 1024 // 限速器桶容量的下限（一次读取的缓冲区大小），避免低速率下频繁小块读取
+
+	DOWNLOAD_MAX_RETRIES            = 3               // 下载遇到网络错误或 403/5xx 时的默认最大重试次数
+	MAX_DOWNLOAD_RETRIES            = 10              // transfer.download_retries 可配置的上限
+	DEFAULT_DOWNLOAD_RETRY_INTERVAL = 2 * time.Second // 下载重试前的默认等待时间
+	DOWNLOAD_TASK_MAX_RETRIES       = 2               // 多文件下载中单个文件重试后仍失败时，整个文件重新入队的最大次数
+
+	DOWNLOAD_STATE_FILE          = ".kuake-download-state.json" // 目录/批量下载写在目标目录下的断点状态文件，全部完成后删除
+	DOWNLOAD_STATE_SAVE_INTERVAL = time.Second                  // 断点状态文件的最小写入间隔，避免文件很多时频繁重写
+
+	DEFAULT_CAT_MAX_SIZE = 100 * 1024 * 1024 // cat 默认允许输出的最大文件大小（字节），防止误输出超大文件
+)
+
+// 传输进度
+const (
+	PROGRESS_SPEED_WINDOW = 5 * time.Second // 上传/下载进度中平均速度的滑动窗口，ETA 按该平均速度估算
+
+	URL_FETCH_PROGRESS_INTERVAL = 500 * time.Millisecond // UploadFromURL 长度未知、先拉取到临时文件时报告拉取进度的间隔
+)
+
+// aria2 RPC
+const (
+	ARIA2_RPC_TIMEOUT   = 30 * time.Second // 单次 aria2 JSON-RPC 请求的超时时间
+	ARIA2_POLL_INTERVAL = 2 * time.Second  // 等待 aria2 任务完成时查询状态的间隔
+
+	Aria2StatusActive   = "active"   // 正在下载
+	Aria2StatusWaiting  = "waiting"  // 排队中
+	Aria2StatusPaused   = "paused"   // 已暂停
+	Aria2StatusComplete = "complete" // 下载完成
+	Aria2StatusError    = "error"    // 下载失败
+	Aria2StatusRemoved  = "removed"  // 已被用户移除
+)
+
+// 在线播放
+const (
+	FILE_VIDEO_PLAY = "/1/clouddrive/file/v2/play" // 获取视频各清晰度的转码播放地址
+
+	VIDEO_PLAY_RESOLUTIONS = "normal,low,high,super,2k,4k" // 请求的清晰度列表
+	VideoQualityOriginal   = "original"                    // 不转码，直接播放原文件（下载链接）
+)
+
+// 文件信息
+const (
+	FILE_INFO           = "/1/clouddrive/file/info"           // 按 fid 查询文件详情
+	FILE_INFO_PATH_LIST = "/1/clouddrive/file/info/path_list" // 按完整路径批量解析 fid
 )
 
 // 文件列表
 const (
-	FILE_SORT = "/1/clouddrive/file/sort"
+	FILE_SORT        = "/1/clouddrive/file/sort" // 列目录（网页端使用的接口）
+	FILE_LIST_LEGACY = "/1/clouddrive/file"      // 旧版列目录接口（GET），FILE_SORT 被拒绝时回退使用
+
+	DEFAULT_LIST_SORT = "file_type:asc,updated_at:desc" // 默认排序：目录在前，按修改时间倒序
+
+	LIST_PAGE_SIZE        = 100 // 列目录每页条数（服务端单页上限）
+	LIST_PAGE_MAX_RETRIES = 3   // 单页请求遇到 5xx/瞬时网络错误时的默认最大重试次数
+	MAX_LIST_RETRIES      = 10  // transfer.list_retries 可配置的上限
+
+	RECURSIVE_LIST_CONCURRENCY = 4 // 递归列目录时同时拉取的目录数上限
+
+	DEFAULT_RECENT_LIMIT = 50 // recent 默认返回的文件数
+
+	PATH_CACHE_TTL = 30 * time.Second // 路径 → 文件信息缓存的默认有效期
+)
+
+// LIST_CATEGORIES 列目录按文件类别过滤时类别名到 category 查询参数的映射
+var LIST_CATEGORIES = map[string]string{
+	"video":    "1",
+	"audio":    "2",
+	"image":    "3",
+	"document": "4",
+}
+
+// 文件搜索
+const (
+	FILE_SEARCH = "/1/clouddrive/file/search" // 按文件名关键字全盘搜索
+
+	DEFAULT_SEARCH_SORT = "file_type:desc,updated_at:desc" // 搜索结果默认排序
 )
 
 // 文件操作
@@ -45,6 +166,23 @@ const (
 	CREATE_FOLDER = "/1/clouddrive/file"
 )
 
+// 上传文件名校验（upPre 之前检查，服务端会拒绝控制字符和超长文件名）
+const (
+	MAX_FILE_NAME_BYTES     = 255 // 文件名的最大字节数（UTF-8）
+	UPLOAD_NAME_REPLACEMENT = "_" // SanitizeNames 时替换非法字符所用的字符串
+
+	MIME_SNIFF_BYTES = 512 // 扩展名无法判断 MIME 类型时读取文件开头的字节数（http.DetectContentType 最多看这么多）
+)
+
+// md5/sha1 并行计算（秒传预检、下载校验等整文件哈希）
+const (
+	HASH_CHUNK_SIZE     = 4 << 20 // 每次读取并分发给 md5、sha1 goroutine 的数据块大小
+	HASH_PIPELINE_DEPTH = 4       // 每个哈希 goroutine 最多排队的数据块数，限制较慢的一方落后时占用的内存
+)
+
+// CREATE_FOLDER_EXISTS_CODE 新建文件夹时同名目录已存在的错误码（并发建同一目录时后到的请求会收到）
+const CREATE_FOLDER_EXISTS_CODE = 23008
+
 // 内容分享
 const (
 	SHARE               = "/1/clouddrive/share"
@@ -64,3 +202,16 @@ const (
 	SHARE_SHAREPAGE_DETAIL = "/1/clouddrive/share/sharepage/detail"
 	SHARE_SHAREPAGE_SAVE   = "/1/clouddrive/share/sharepage/save"
 )
+
+// 下载分享内容（DownloadShare：临时转存 → 下载 → 删除）
+const (
+	SHARE_DOWNLOAD_TEMP_PREFIX = ".kuake-share-"  // 临时转存目录名前缀，建在网盘根目录下
+	SHARE_SAVE_POLL_INTERVAL   = 1 * time.Second  // 查询转存任务状态的间隔
+	SHARE_SAVE_TIMEOUT         = 10 * time.Minute // 等待转存任务完成的最长时间
+)
+
+// 创建分享任务（TASK 接口）的轮询间隔和超时
+const (
+	SHARE_CREATE_POLL_INTERVAL = 500 * time.Millisecond // 查询创建分享任务状态的间隔
+	SHARE_CREATE_TIMEOUT       = 5 * time.Second        // 等待创建分享任务完成的最长时间
+)
@@ -0,0 +1,33 @@
+package sdk
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+)
+
+// ErrInsufficientDiskSpace 目标分区剩余空间不足以保存下载文件
+var ErrInsufficientDiskSpace = errors.New("insufficient disk space")
+
+// diskFreeSpace 返回 dir 所在分区当前用户可用的字节数，平台不支持时返回 -1（测试中可替换）
+var diskFreeSpace = availableDiskSpace
+
+// checkDiskSpace 检查 dir 所在分区是否还能写入 need 字节（另加 DOWNLOAD_DISK_SPACE_RESERVE 余量）
+// need <= 0 或无法获取剩余空间时不检查
+func checkDiskSpace(dir string, need int64) error {
+	if need <= 0 {
+		return nil
+	}
+	if dir == "" {
+		dir = "."
+	}
+	free, err := diskFreeSpace(dir)
+	if err != nil || free < 0 {
+		return nil
+	}
+	if required := need + DOWNLOAD_DISK_SPACE_RESERVE; free < required {
+		abs, _ := filepath.Abs(dir)
+		return fmt.Errorf("%w: %s has %d bytes free, need %d (file %d + reserve %d)", ErrInsufficientDiskSpace, abs, free, required, need, int64(DOWNLOAD_DISK_SPACE_RESERVE))
+	}
+	return nil
+}
@@ -0,0 +1,8 @@
+//go:build !linux && !darwin && !freebsd && !windows
+
+package sdk
+
+// availableDiskSpace 当前平台不支持查询剩余空间，返回 -1 表示跳过检查
+func availableDiskSpace(dir string) (int64, error) {
+	return -1, nil
+}
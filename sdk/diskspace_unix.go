@@ -0,0 +1,14 @@
+//go:build linux || darwin || freebsd
+
+package sdk
+
+import "syscall"
+
+// availableDiskSpace 通过 statfs 获取 dir 所在分区非 root 用户可用的字节数
+func availableDiskSpace(dir string) (int64, error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(dir, &stat); err != nil {
+		return -1, err
+	}
+	return int64(uint64(stat.Bavail) * uint64(stat.Bsize)), nil
+}
@@ -0,0 +1,24 @@
+//go:build windows
+
+package sdk
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+var procGetDiskFreeSpaceExW = syscall.NewLazyDLL("kernel32.dll").NewProc("GetDiskFreeSpaceExW")
+
+// availableDiskSpace 通过 GetDiskFreeSpaceExW 获取 dir 所在分区当前用户可用的字节数
+func availableDiskSpace(dir string) (int64, error) {
+	path, err := syscall.UTF16PtrFromString(dir)
+	if err != nil {
+		return -1, err
+	}
+	var freeBytes uint64
+	ok, _, callErr := procGetDiskFreeSpaceExW.Call(uintptr(unsafe.Pointer(path)), uintptr(unsafe.Pointer(&freeBytes)), 0, 0)
+	if ok == 0 {
+		return -1, callErr
+	}
+	return int64(freeBytes), nil
+}
@@ -0,0 +1,358 @@
+package sdk
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"path/filepath"
+	"sync"
+)
+
+// DownloadFiles 把 jobs 中的每个文件作为 TaskTypeDownload 任务放入同一个 TaskQueue，按 opts.Workers 并发下载
+// 下载失败（重试后仍为网络错误、403/5xx，或校验失败）的文件按重试策略重新入队，最多 DOWNLOAD_TASK_MAX_RETRIES 次，
+// 等待 transfer.download_retry_interval 后再执行；transfer.download_retries 为 0 时不重新入队
+// 每个文件结束后回调 opts.OnFile，opts.OnProgress 不为 nil 时回调总进度；返回的结果按完成顺序排列
+func (qc *QuarkClient) DownloadFiles(jobs []DownloadJob, opts DownloadDirOptions) []DownloadResult {
+	results := make([]DownloadResult, 0, len(jobs))
+	if len(jobs) == 0 {
+		return results
+	}
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = DEFAULT_DOWNLOAD_WORKERS
+	}
+	if workers > MAX_DOWNLOAD_WORKERS {
+		workers = MAX_DOWNLOAD_WORKERS
+	}
+
+	tracker := newDownloadBatchTracker(jobs, opts.OnProgress)
+	queue := NewTaskQueue(workers)
+	if qc.downloadRetries > 0 {
+		queue.SetRetryPolicy(TaskRetryPolicy{
+			MaxRetries: DOWNLOAD_TASK_MAX_RETRIES,
+			Interval:   qc.downloadRetryWait,
+			Retryable:  isRetryableDownloadTaskError,
+		})
+	}
+
+	// 任务的最终回调在其移出运行列表之后才调用，用 WaitGroup 等待全部回调结束，而不是 queue.Wait
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	record := func(taskID string, result DownloadResult) {
+		defer wg.Done()
+		mu.Lock()
+		defer mu.Unlock()
+		results = append(results, result)
+		tracker.fileDone(taskID, result)
+		if opts.OnFile != nil {
+			opts.OnFile(result)
+		}
+	}
+	for _, job := range jobs {
+		job := job
+		task := queue.AddTask(TaskTypeDownload, map[string]interface{}{"job": job})
+		wg.Add(1)
+		queue.SetTaskCallback(task.ID, TaskCallback{
+			OnComplete: func(task *Task, result interface{}) {
+				record(task.ID, result.(DownloadResult))
+			},
+			OnError: func(task *Task, err error) {
+				result := job.result()
+				result.Error = err.Error()
+				record(task.ID, result)
+			},
+		})
+	}
+	tracker.report()
+	queue.Start(&downloadExecutor{qc: qc, opts: opts, tracker: tracker})
+	wg.Wait()
+	queue.Stop()
+	return results
+}
+
+// DownloadPaths 把多个远程路径（文件或目录）下载到 localDir，所有文件共用 DownloadFiles 的同一个任务队列
+// 文件保存为 localDir/<文件名>；目录需设置 opts.Recursive，按 DownloadDir 的规则保存为 localDir/<目录名>/...
+// 获取信息失败的路径记为失败并回调 opts.OnFile；未设置 opts.NoSpaceCheck 时下载前按待下载文件大小之和检查一次剩余空间
+// 与 DownloadDir 相同，在 localDir 下维护断点状态文件，重跑同一组路径时跳过已完成的文件
+// 返回 Data 包含 local_dir、files、dirs、downloaded、skipped、resumed、failed（[]DownloadResult）、list_failed；有失败时 Code 为 PARTIAL_SUCCESS
+func (qc *QuarkClient) DownloadPaths(paths []string, localDir string, opts DownloadDirOptions) (*StandardResponse, error) {
+	if localDir == "" {
+		localDir = "."
+	}
+	var jobs []DownloadJob
+	var plans []*dirDownloadPlan
+	failed := make([]DownloadResult, 0)
+	listFailed := make([]map[string]interface{}, 0)
+	dirs := 0
+	fail := func(result DownloadResult) {
+		failed = append(failed, result)
+		if opts.OnFile != nil {
+			opts.OnFile(result)
+		}
+	}
+
+	for _, remotePath := range paths {
+		result := DownloadResult{Path: remotePath}
+		fileInfo, err := qc.GetFileInfo(remotePath)
+		if err != nil {
+			result.Error = fmt.Sprintf("failed to get file info: %v", err)
+			fail(result)
+			continue
+		}
+		if !fileInfo.Success {
+			result.Error = fileInfo.Message
+			fail(result)
+			continue
+		}
+		result.Fid, _ = fileInfo.Data["fid"].(string)
+		result.Size, _ = fileInfo.Data["size"].(int64)
+		if result.Fid == "" {
+			result.Error = "file info does not contain valid fid"
+			fail(result)
+			continue
+		}
+
+		if isDir, _ := fileInfo.Data["dir"].(bool); isDir {
+			if !opts.Recursive {
+				result.Error = "cannot download directory (use --recursive)"
+				fail(result)
+				continue
+			}
+			dirPath, _ := fileInfo.Data["path"].(string)
+			if dirPath == "" {
+				dirPath = remotePath
+			}
+			rootPath := normalizePath(dirPath)
+			dirLocal := localDir
+			if rootPath != "/" {
+				dirLocal = filepath.Join(localDir, path.Base(rootPath))
+			}
+			plan, failResp, err := qc.planDirDownload(rootPath, dirLocal)
+			if err != nil {
+				result.Error = err.Error()
+				fail(result)
+				continue
+			}
+			if failResp != nil {
+				result.Error = failResp.Message
+				fail(result)
+				continue
+			}
+			plans = append(plans, plan)
+			jobs = append(jobs, plan.jobs...)
+			dirs += plan.dirs
+			listFailed = append(listFailed, plan.listFailed...)
+			for _, r := range plan.failed {
+				fail(r)
+			}
+			continue
+		}
+
+		fileName, _ := fileInfo.Data["file_name"].(string)
+		if fileName == "" {
+			fileName = path.Base(remotePath)
+		}
+		if fileName == "" || fileName == "." || fileName == "/" {
+			fileName = "download"
+		}
+		modTime, _ := fileInfo.Data["mtime"].(int64)
+		jobs = append(jobs, DownloadJob{Fid: result.Fid, Path: remotePath, FileName: fileName, LocalPath: filepath.Join(localDir, fileName), Size: result.Size, ModTime: modTime})
+	}
+
+	state := openDownloadState(localDir, paths, opts.Restart)
+	jobs, resumed := state.pending(jobs)
+	if !opts.NoSpaceCheck && len(jobs) > 0 {
+		if err := checkDiskSpace(localDir, totalJobSize(jobs)); err != nil {
+			return &StandardResponse{
+				Success: false,
+				Code:    "INSUFFICIENT_DISK_SPACE",
+				Message: err.Error(),
+			}, nil
+		}
+	}
+
+	downloaded, skipped, downloadFailed := splitDownloadResults(qc.downloadFilesWithState(state, jobs, opts))
+	failed = append(failed, downloadFailed...)
+	if !opts.NoPreserveMtime {
+		for _, plan := range plans {
+			plan.applyDirModTimes()
+		}
+	}
+	state.finish(len(failed) == 0 && len(listFailed) == 0)
+
+	code, message := "OK", fmt.Sprintf("下载成功，共 %d 个文件", len(downloaded))
+	if len(failed) > 0 || len(listFailed) > 0 {
+		code = "PARTIAL_SUCCESS"
+		message = fmt.Sprintf("下载完成，%d 个文件成功，%d 个失败，%d 个子目录列出失败", len(downloaded), len(failed), len(listFailed))
+	}
+	if len(skipped) > 0 {
+		message += fmt.Sprintf("，跳过 %d 个已存在的文件", len(skipped))
+	}
+	if resumed > 0 {
+		message += fmt.Sprintf("，按断点状态跳过 %d 个已完成的文件", resumed)
+	}
+	return &StandardResponse{
+		Success: true,
+		Code:    code,
+		Message: message,
+		Data: map[string]interface{}{
+			"local_dir":   localDir,
+			"files":       len(jobs) + resumed,
+			"dirs":        dirs,
+			"downloaded":  downloaded,
+			"skipped":     skipped,
+			"resumed":     resumed,
+			"failed":      failed,
+			"list_failed": listFailed,
+		},
+	}, nil
+}
+
+// splitDownloadResults 按成功、跳过、失败拆分下载结果，三个切片均不为 nil
+func splitDownloadResults(results []DownloadResult) (downloaded, skipped, failed []DownloadResult) {
+	downloaded = make([]DownloadResult, 0, len(results))
+	skipped = make([]DownloadResult, 0)
+	failed = make([]DownloadResult, 0)
+	for _, result := range results {
+		switch {
+		case result.Error != "":
+			failed = append(failed, result)
+		case result.Skipped:
+			skipped = append(skipped, result)
+		default:
+			downloaded = append(downloaded, result)
+		}
+	}
+	return downloaded, skipped, failed
+}
+
+// isRetryableDownloadTaskError 判断文件下载失败后是否重新入队：网络错误、403/5xx（单次下载内的重试已用完）和校验失败
+func isRetryableDownloadTaskError(err error) bool {
+	return isRetryableDownloadError(err) || errors.Is(err, ErrVerifyFailed)
+}
+
+// result 返回任务对应的初始下载结果
+func (job DownloadJob) result() DownloadResult {
+	return DownloadResult{Fid: job.Fid, Path: job.Path, LocalPath: job.LocalPath, Size: job.Size}
+}
+
+// downloadExecutor 在 TaskQueue 中执行 DownloadFiles 的下载任务（实现 TaskExecutor）
+// 任务结果为 DownloadResult；本地文件已存在且按冲突策略跳过时视为成功，Skipped 为 true
+type downloadExecutor struct {
+	qc      *QuarkClient
+	opts    DownloadDirOptions
+	tracker *downloadBatchTracker
+}
+
+func (e *downloadExecutor) Execute(task *Task) (interface{}, error) {
+	job, _ := task.Params["job"].(DownloadJob)
+	fileOpts := DownloadOptions{Connections: e.opts.Connections, Verify: e.opts.Verify, NoSpaceCheck: e.opts.NoSpaceCheck, RateLimiter: e.opts.RateLimiter, Conflict: e.opts.Conflict}
+	if !e.opts.NoPreserveMtime {
+		fileOpts.ModTime = job.ModTime
+	}
+	fileOpts.Progress = e.tracker.fileProgress(task.ID)
+	fileName := job.FileName
+	if fileName == "" {
+		fileName = path.Base(job.Path)
+	}
+	localPath, err := e.qc.DownloadFileToPath(job.Fid, job.LocalPath, fileName, fileOpts)
+	result := job.result()
+	if errors.Is(err, ErrDownloadSkipped) {
+		result.Skipped = true
+		return result, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	result.LocalPath = localPath
+	return result, nil
+}
+
+// downloadBatchTracker 汇总多文件下载的总进度，可并发调用，方法均可在 nil 上调用
+type downloadBatchTracker struct {
+	mu          sync.Mutex
+	callback    func(DownloadBatchProgress)
+	progress    DownloadBatchProgress
+	finished    int64            // 已结束（成功或跳过）文件的字节数
+	current     map[string]int64 // 任务ID -> 下载中文件的已下载字节数
+	transferred int64            // 本次实际传输的字节数，用于计算速度
+	meter       *speedMeter
+}
+
+// newDownloadBatchTracker 创建 jobs 的总进度汇总，callback 为 nil 时返回 nil
+func newDownloadBatchTracker(jobs []DownloadJob, callback func(DownloadBatchProgress)) *downloadBatchTracker {
+	if callback == nil {
+		return nil
+	}
+	t := &downloadBatchTracker{
+		callback: callback,
+		current:  make(map[string]int64),
+		meter:    newSpeedMeter(0),
+	}
+	t.progress.FilesTotal = len(jobs)
+	for _, job := range jobs {
+		t.progress.Total += job.Size
+	}
+	return t
+}
+
+// fileProgress 返回任务 taskID 的单文件进度回调，t 为 nil 时返回 nil；校验阶段的进度不计入
+func (t *downloadBatchTracker) fileProgress(taskID string) func(*DownloadProgress) {
+	if t == nil {
+		return nil
+	}
+	return func(p *DownloadProgress) {
+		if p.Phase != DownloadPhaseDownload {
+			return
+		}
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		// 每次下载开始时先回调起点（续传时为已下载的字节数），之后的增量才是本次传输的字节
+		if prev, ok := t.current[taskID]; ok && p.Downloaded > prev {
+			t.transferred += p.Downloaded - prev
+		}
+		t.current[taskID] = p.Downloaded
+		t.reportLocked()
+	}
+}
+
+// fileDone 记录任务 taskID 的文件结束：成功或跳过的文件按大小计入已完成字节数，失败的文件不再计入
+func (t *downloadBatchTracker) fileDone(taskID string, result DownloadResult) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.current, taskID)
+	t.progress.FilesDone++
+	if result.Error != "" {
+		t.progress.Failed++
+	} else {
+		t.finished += result.Size
+	}
+	t.reportLocked()
+}
+
+// report 回调当前总进度
+func (t *downloadBatchTracker) report() {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.reportLocked()
+}
+
+func (t *downloadBatchTracker) reportLocked() {
+	downloaded := t.finished
+	for _, n := range t.current {
+		downloaded += n
+	}
+	if downloaded > t.progress.Total {
+		downloaded = t.progress.Total
+	}
+	_, t.progress.Speed = t.meter.update(t.transferred)
+	t.progress.Downloaded = downloaded
+	t.progress.ETA = estimateRemaining(downloaded, t.progress.Total, t.progress.Speed)
+	t.callback(t.progress)
+}
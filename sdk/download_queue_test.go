@@ -0,0 +1,137 @@
+package sdk
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestDownloadFiles_RetryAndProgress(t *testing.T) {
+	var mu sync.Mutex
+	requests := make(map[string]int)
+	content := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests[r.URL.Path]++
+		n := requests[r.URL.Path]
+		mu.Unlock()
+		// flaky 的前两次请求（单次下载内的一次重试）都失败，整个文件重新入队后成功
+		if r.URL.Path == "/flaky" && n <= 2 {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		if r.URL.Path == "/gone" {
+			http.Error(w, "gone", http.StatusGone)
+			return
+		}
+		fmt.Fprint(w, strings.Repeat("x", 10))
+	}))
+	defer content.Close()
+
+	client := createMockClient(t, func(req *http.Request) (*http.Response, error) {
+		var body struct {
+			Fids []string `json:"fids"`
+		}
+		json.NewDecoder(req.Body).Decode(&body)
+		return jsonResponse(req, fmt.Sprintf(`{"status":200,"code":0,"data":[{"download_url":"%s/%s"}]}`, content.URL, body.Fids[0])), nil
+	})
+	client.SetDownloadRetries(1, 0)
+
+	localDir := t.TempDir()
+	jobs := []DownloadJob{
+		{Fid: "ok", Path: "/ok.txt", LocalPath: filepath.Join(localDir, "ok.txt"), Size: 10},
+		{Fid: "flaky", Path: "/flaky.txt", LocalPath: filepath.Join(localDir, "flaky.txt"), Size: 10},
+		{Fid: "gone", Path: "/gone.txt", LocalPath: filepath.Join(localDir, "gone.txt"), Size: 10},
+	}
+	var last DownloadBatchProgress
+	var files int
+	results := client.DownloadFiles(jobs, DownloadDirOptions{
+		Workers:    2,
+		OnFile:     func(DownloadResult) { files++ },
+		OnProgress: func(p DownloadBatchProgress) { last = p },
+	})
+
+	if len(results) != 3 || files != 3 {
+		t.Fatalf("got %d results, %d OnFile callbacks, want 3", len(results), files)
+	}
+	for _, result := range results {
+		wantErr := result.Fid == "gone"
+		if (result.Error != "") != wantErr {
+			t.Errorf("%s: error %q", result.Fid, result.Error)
+		}
+	}
+	if requests["/flaky"] != 3 {
+		t.Errorf("flaky requested %d times, want 3 (requeued once)", requests["/flaky"])
+	}
+	// 410 不可重试：只请求一次，不重新入队
+	if requests["/gone"] != 1 {
+		t.Errorf("gone requested %d times, want 1", requests["/gone"])
+	}
+	if last.FilesDone != 3 || last.FilesTotal != 3 || last.Failed != 1 || last.Downloaded != 20 || last.Total != 30 {
+		t.Errorf("last progress = %+v", last)
+	}
+}
+
+func TestDownloadPaths(t *testing.T) {
+	entry := func(fid, name string, dir bool) map[string]interface{} {
+		return map[string]interface{}{"fid": fid, "file_name": name, "dir": dir, "size": len(fid)}
+	}
+	dirs := map[string][]map[string]interface{}{
+		"0":    {entry("docs", "docs", true), entry("top", "top.txt", false)},
+		"docs": {entry("f1", "a.txt", false), entry("sub", "sub", true)},
+		"sub":  {entry("f2", "b.txt", false)},
+	}
+	content := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "content of %s", strings.TrimPrefix(r.URL.Path, "/"))
+	}))
+	defer content.Close()
+	tree := fakeTreeServer(dirs, nil)
+	client := createMockClient(t, func(req *http.Request) (*http.Response, error) {
+		if req.URL.Path != FILE_DOWNLOAD {
+			return tree(req)
+		}
+		var body struct {
+			Fids []string `json:"fids"`
+		}
+		json.NewDecoder(req.Body).Decode(&body)
+		return jsonResponse(req, fmt.Sprintf(`{"status":200,"code":0,"data":[{"download_url":"%s/%s"}]}`, content.URL, body.Fids[0])), nil
+	})
+
+	localDir := t.TempDir()
+	var last DownloadBatchProgress
+	resp, err := client.DownloadPaths([]string{"/top.txt", "/docs", "/missing.txt"}, localDir, DownloadDirOptions{
+		Recursive:  true,
+		OnProgress: func(p DownloadBatchProgress) { last = p },
+	})
+	if err != nil || !resp.Success || resp.Code != "PARTIAL_SUCCESS" {
+		t.Fatalf("DownloadPaths() = %+v, %v", resp, err)
+	}
+	for localPath, want := range map[string]string{
+		"top.txt":        "content of top",
+		"docs/a.txt":     "content of f1",
+		"docs/sub/b.txt": "content of f2",
+	} {
+		data, err := os.ReadFile(filepath.Join(localDir, localPath))
+		if err != nil || string(data) != want {
+			t.Errorf("%s = %q, %v, want %q", localPath, data, err, want)
+		}
+	}
+	if failed, _ := resp.Data["failed"].([]DownloadResult); len(failed) != 1 || failed[0].Path != "/missing.txt" {
+		t.Errorf("failed = %+v, want only /missing.txt", resp.Data["failed"])
+	}
+	// 三个文件共用一个队列，总进度覆盖全部文件
+	if resp.Data["files"] != 3 || last.FilesTotal != 3 || last.FilesDone != 3 {
+		t.Errorf("files = %v, last progress = %+v", resp.Data["files"], last)
+	}
+
+	// 未设置 Recursive 时目录记为失败
+	resp, _ = client.DownloadPaths([]string{"/docs"}, t.TempDir(), DownloadDirOptions{})
+	if failed, _ := resp.Data["failed"].([]DownloadResult); len(failed) != 1 || !strings.Contains(failed[0].Error, "directory") {
+		t.Errorf("non-recursive directory: failed = %+v", resp.Data["failed"])
+	}
+}
@@ -0,0 +1,131 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// hasRange 是否指定了字节范围
+func (opts DownloadOptions) hasRange() bool {
+	return opts.Offset > 0 || opts.Length > 0
+}
+
+// validateRange 检查字节范围参数
+func (opts DownloadOptions) validateRange() error {
+	if opts.Offset < 0 || opts.Length < 0 {
+		return fmt.Errorf("invalid byte range: offset %d, length %d", opts.Offset, opts.Length)
+	}
+	return nil
+}
+
+// downloadRangeToWriter 把 opts.Offset 起 opts.Length 字节（为 0 时到文件末尾）写入 w
+// 服务端必须返回 206：返回 200 时不读取响应体，直接返回 ErrRangeNotSupported；起始位置超出文件大小时返回错误
+// 中断后用 Range 从已写入的位置继续，并带 If-Range，远端文件已变化时返回错误
+func (qc *QuarkClient) downloadRangeToWriter(fid string, w io.Writer, opts DownloadOptions) error {
+	var written int64
+	var meta *downloadMeta // 首次 206 响应的 ETag/Last-Modified/文件大小
+	progress := newDownloadProgressMeter(opts.Progress)
+	opts.Progress = progress.progressFunc()
+	err := qc.retryDownload(fid, opts.URL, func(downloadURL string) error {
+		if downloadURL == "" {
+			u, err := qc.GetDownloadURL(fid)
+			if err != nil {
+				return err
+			}
+			downloadURL = u
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Hour)
+		defer cancel()
+		start, end := opts.Offset+written, int64(-1)
+		if opts.Length > 0 {
+			end = opts.Offset + opts.Length - 1
+		}
+		resp, err := qc.requestDownloadRange(ctx, downloadURL, start, end, meta.ifRange())
+		if err != nil {
+			return retryableDownload(fmt.Errorf("download request: %w", err))
+		}
+		defer resp.Body.Close()
+
+		switch resp.StatusCode {
+		case http.StatusPartialContent:
+		case http.StatusOK:
+			// 续传时带了 If-Range，200 说明远端文件已变化
+			if written > 0 {
+				return fmt.Errorf("download failed: remote file changed while resuming at %d", opts.Offset+written)
+			}
+			return fmt.Errorf("%w: status 200 for Range: bytes=%d-", ErrRangeNotSupported, start)
+		case http.StatusRequestedRangeNotSatisfiable:
+			return fmt.Errorf("download failed: range start %d is beyond the end of the file (%s)", start, resp.Header.Get("Content-Range"))
+		default:
+			data, _ := io.ReadAll(resp.Body)
+			return downloadStatusError(resp.StatusCode, fmt.Errorf("download failed: status %d, body: %s", resp.StatusCode, string(data)))
+		}
+
+		rangeStart, size := parseContentRange(resp.Header.Get("Content-Range"))
+		if rangeStart != start {
+			return fmt.Errorf("download failed: requested range from %d, server returned range from %d", start, rangeStart)
+		}
+		if meta == nil {
+			meta = &downloadMeta{Size: size, ETag: resp.Header.Get("ETag"), LastModified: resp.Header.Get("Last-Modified")}
+		} else if meta.changed(resp, size) {
+			return fmt.Errorf("download failed: remote file changed while resuming at %d", start)
+		}
+		// 请求的范围超出文件末尾时服务端只返回到末尾的部分，以响应长度为准
+		var total int64 = -1
+		if resp.ContentLength >= 0 {
+			total = written + resp.ContentLength
+		}
+		written, err = copyDownloadBody(resp.Body, w, written, total, opts.RateLimiter, opts.Progress)
+		return err
+	})
+	if err == nil {
+		progress.finish()
+	}
+	return err
+}
+
+// downloadRangeToPath 把字节范围下载到 path：写入同目录的临时文件，完成后按 opts.Conflict 重命名为目标文件
+// 范围下载不保留续传记录、不设置 mtime，也不支持 Verify（远端哈希针对整个文件）；临时文件名与完整下载的 .kuake-tmp 不同，不影响其续传
+func (qc *QuarkClient) downloadRangeToPath(fid, path string, opts DownloadOptions) (string, error) {
+	if err := opts.validateRange(); err != nil {
+		return "", err
+	}
+	if opts.Verify {
+		return "", errors.New("verify is not supported for byte range downloads")
+	}
+	dir := filepath.Dir(path)
+	if !opts.NoSpaceCheck {
+		if err := checkDiskSpace(dir, opts.Length); err != nil {
+			return "", err
+		}
+	}
+	file, err := os.CreateTemp(dir, "."+filepath.Base(path)+".range-*"+DOWNLOAD_PART_SUFFIX)
+	if err != nil {
+		return "", fmt.Errorf("create temp file: %w", err)
+	}
+	partPath := file.Name()
+	err = qc.downloadRangeToWriter(fid, file, opts)
+	if closeErr := file.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("close temp file: %w", closeErr)
+	}
+	if err != nil {
+		os.Remove(partPath)
+		return "", err
+	}
+
+	target := path
+	if opts.Conflict == DownloadConflictRename {
+		target = availableLocalPath(path)
+	}
+	if err := replaceFile(partPath, target); err != nil {
+		os.Remove(partPath)
+		return "", fmt.Errorf("rename downloaded file: %w", err)
+	}
+	return target, nil
+}
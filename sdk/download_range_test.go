@@ -0,0 +1,108 @@
+package sdk
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDownloadFileToPath_Range(t *testing.T) {
+	content := strings.Repeat("0123456789", 100)
+	var ranges []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ranges = append(ranges, r.Header.Get("Range"))
+		if r.URL.Path == "/norange" {
+			io.WriteString(w, content)
+			return
+		}
+		http.ServeContent(w, r, "file.bin", time.Time{}, strings.NewReader(content))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name      string
+		urlPath   string
+		offset    int64
+		length    int64
+		wantRange string
+		want      string
+		wantErr   error
+	}{
+		{name: "start-end", urlPath: "/file", offset: 10, length: 25, wantRange: "bytes=10-34", want: content[10:35]},
+		{name: "start to end of file", urlPath: "/file", offset: 990, wantRange: "bytes=990-", want: content[990:]},
+		{name: "range past end of file", urlPath: "/file", offset: 995, length: 100, wantRange: "bytes=995-1094", want: content[995:]},
+		{name: "not supported", urlPath: "/norange", offset: 10, length: 25, wantRange: "bytes=10-34", wantErr: ErrRangeNotSupported},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ranges = nil
+			dir := t.TempDir()
+			client := createMockClient(t, func(req *http.Request) (*http.Response, error) {
+				return jsonResponse(req, fmt.Sprintf(`{"status":200,"code":0,"data":[{"download_url":"%s%s"}]}`, server.URL, tt.urlPath)), nil
+			})
+			client.SetDownloadRetries(0, 0)
+
+			dest := filepath.Join(dir, "part.bin")
+			localPath, err := client.DownloadFileToPath("fid", dest, "file.bin", DownloadOptions{Offset: tt.offset, Length: tt.length})
+			if len(ranges) != 1 || ranges[0] != tt.wantRange {
+				t.Errorf("Range headers = %q, want [%q]", ranges, tt.wantRange)
+			}
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("DownloadFileToPath() error = %v, want %v", err, tt.wantErr)
+				}
+				if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+					t.Errorf("files left after failed range download: %v", entries)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("DownloadFileToPath() error = %v", err)
+			}
+			if localPath != dest {
+				t.Errorf("DownloadFileToPath() path = %s, want %s", localPath, dest)
+			}
+			if data, _ := os.ReadFile(dest); string(data) != tt.want {
+				t.Errorf("part.bin = %q, want %q", data, tt.want)
+			}
+		})
+	}
+}
+
+func TestDownloadToWriterWithOptions_Range(t *testing.T) {
+	content := strings.Repeat("abcdefghij", 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeContent(w, r, "file.bin", time.Time{}, strings.NewReader(content))
+	}))
+	defer server.Close()
+	client := createMockClient(t, func(req *http.Request) (*http.Response, error) {
+		return jsonResponse(req, fmt.Sprintf(`{"status":200,"code":0,"data":[{"download_url":"%s/f1"}]}`, server.URL)), nil
+	})
+
+	var out strings.Builder
+	var last DownloadProgress
+	opts := DownloadOptions{Offset: 20, Length: 15, Progress: func(p *DownloadProgress) { last = *p }}
+	if err := client.DownloadToWriterWithOptions("f1", &out, opts); err != nil {
+		t.Fatalf("DownloadToWriterWithOptions() error = %v", err)
+	}
+	if out.String() != content[20:35] {
+		t.Errorf("DownloadToWriterWithOptions() wrote %q, want %q", out.String(), content[20:35])
+	}
+	if !last.Done || last.Downloaded != 15 || last.Total != 15 {
+		t.Errorf("last progress = %+v, want done with 15/15 bytes", last)
+	}
+
+	if err := client.DownloadToWriterWithOptions("f1", io.Discard, DownloadOptions{Offset: -1}); err == nil {
+		t.Error("DownloadToWriterWithOptions() with negative offset error = nil, want error")
+	}
+	if err := client.DownloadToWriterWithOptions("f1", io.Discard, DownloadOptions{Offset: 200}); err == nil || errors.Is(err, ErrRangeNotSupported) {
+		t.Errorf("DownloadToWriterWithOptions() past end of file error = %v, want out of range error", err)
+	}
+}
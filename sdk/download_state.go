@@ -0,0 +1,167 @@
+package sdk
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// downloadState 目录/批量下载的断点状态，保存在目标目录下的 DOWNLOAD_STATE_FILE 中
+// 记录已完成文件的 fid、大小和远端修改时间；重跑同一命令时远端未变化且本地文件大小一致的文件直接跳过
+type downloadState struct {
+	Sources   []string                      `json:"sources"`    // 下载的远程路径，与本次不同时忽略已有状态
+	Files     map[string]downloadStateEntry `json:"files"`      // 相对目标目录的本地路径（/ 分隔）-> 完成记录
+	UpdatedAt int64                         `json:"updated_at"` // 最后写入时间（Unix 秒）
+
+	mu       sync.Mutex
+	dir      string
+	lastSave time.Time
+	dirty    bool
+}
+
+// downloadStateEntry 断点状态中一个已完成文件的记录
+type downloadStateEntry struct {
+	Fid         string `json:"fid"`
+	Size        int64  `json:"size"`
+	ModTime     int64  `json:"mtime,omitempty"` // 远端修改时间（秒）
+	CompletedAt int64  `json:"completed_at"`    // 完成时间（Unix 秒）
+}
+
+// openDownloadState 读取 dir 下的断点状态；文件不存在、无法解析、sources 不同或 restart 为 true 时从空状态开始
+func openDownloadState(dir string, sources []string, restart bool) *downloadState {
+	sorted := append([]string(nil), sources...)
+	sort.Strings(sorted)
+	state := &downloadState{Sources: sorted, Files: make(map[string]downloadStateEntry), dir: dir}
+	if restart {
+		return state
+	}
+	data, err := os.ReadFile(filepath.Join(dir, DOWNLOAD_STATE_FILE))
+	if err != nil {
+		return state
+	}
+	var saved downloadState
+	if json.Unmarshal(data, &saved) != nil || saved.Files == nil || !equalStrings(saved.Sources, sorted) {
+		return state
+	}
+	state.Files = saved.Files
+	return state
+}
+
+// key 返回本地路径在状态中的键，不在目标目录内时返回 false
+func (s *downloadState) key(localPath string) (string, bool) {
+	rel, err := filepath.Rel(s.dir, localPath)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return filepath.ToSlash(rel), true
+}
+
+// completed 判断 job 是否已在之前的运行中完成：记录的 fid、大小、修改时间与远端一致，且本地文件仍存在、大小相同
+func (s *downloadState) completed(job DownloadJob) bool {
+	key, ok := s.key(job.LocalPath)
+	if !ok {
+		return false
+	}
+	s.mu.Lock()
+	entry, ok := s.Files[key]
+	s.mu.Unlock()
+	if !ok || entry.Fid != job.Fid || entry.Size != job.Size || entry.ModTime != job.ModTime {
+		return false
+	}
+	info, err := os.Stat(job.LocalPath)
+	return err == nil && info.Mode().IsRegular() && info.Size() == job.Size
+}
+
+// markDone 记录 job 已完成（下载成功或按冲突策略跳过），距上次写入超过 DOWNLOAD_STATE_SAVE_INTERVAL 时写入状态文件
+func (s *downloadState) markDone(job DownloadJob) {
+	key, ok := s.key(job.LocalPath)
+	if !ok {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Files[key] = downloadStateEntry{Fid: job.Fid, Size: job.Size, ModTime: job.ModTime, CompletedAt: time.Now().Unix()}
+	s.dirty = true
+	if time.Since(s.lastSave) >= DOWNLOAD_STATE_SAVE_INTERVAL {
+		s.saveLocked()
+	}
+}
+
+// finish 下载结束时调用：complete 为 true（全部成功）时删除状态文件，否则写入最新状态供下次续传
+func (s *downloadState) finish(complete bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if complete {
+		os.Remove(filepath.Join(s.dir, DOWNLOAD_STATE_FILE))
+		return
+	}
+	if s.dirty {
+		s.saveLocked()
+	}
+}
+
+// saveLocked 先写临时文件再重命名，避免进程中途被杀时留下不完整的状态文件；写入失败忽略（只影响续传）
+func (s *downloadState) saveLocked() {
+	s.UpdatedAt = time.Now().Unix()
+	data, err := json.Marshal(s)
+	if err != nil {
+		return
+	}
+	path := filepath.Join(s.dir, DOWNLOAD_STATE_FILE)
+	if err := os.WriteFile(path+".tmp", data, 0644); err != nil {
+		return
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		os.Remove(path + ".tmp")
+		return
+	}
+	s.lastSave, s.dirty = time.Now(), false
+}
+
+// pending 返回 jobs 中尚未完成、需要下载的文件，以及按断点状态跳过的文件数
+func (s *downloadState) pending(jobs []DownloadJob) ([]DownloadJob, int) {
+	pending := make([]DownloadJob, 0, len(jobs))
+	for _, job := range jobs {
+		if !s.completed(job) {
+			pending = append(pending, job)
+		}
+	}
+	return pending, len(jobs) - len(pending)
+}
+
+// downloadFilesWithState 用 DownloadFiles 下载 jobs，下载成功或按冲突策略跳过的文件记入断点状态
+func (qc *QuarkClient) downloadFilesWithState(state *downloadState, jobs []DownloadJob, opts DownloadDirOptions) []DownloadResult {
+	byPath := make(map[string]DownloadJob, len(jobs))
+	for _, job := range jobs {
+		byPath[job.Path] = job
+	}
+
+	onFile := opts.OnFile
+	opts.OnFile = func(result DownloadResult) {
+		// 结果的 LocalPath 在 rename 冲突策略下可能改变，按远程路径找到任务后用任务的本地路径记录
+		if job, ok := byPath[result.Path]; ok && result.Error == "" {
+			state.markDone(job)
+		}
+		if onFile != nil {
+			onFile(result)
+		}
+	}
+	return qc.DownloadFiles(jobs, opts)
+}
+
+// equalStrings 判断两个字符串切片是否相同
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,86 @@
+package sdk
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestDownloadDir_StateFile(t *testing.T) {
+	entry := func(fid, name string, dir bool) map[string]interface{} {
+		return map[string]interface{}{"fid": fid, "file_name": name, "dir": dir, "size": len("content of " + fid), "updated_at": 1700000000000}
+	}
+	dirs := map[string][]map[string]interface{}{
+		"0":    {entry("docs", "docs", true)},
+		"docs": {entry("f1", "a.txt", false), entry("f2", "b.txt", false), entry("bad", "bad.txt", false)},
+	}
+	var mu sync.Mutex
+	requests := make(map[string]int)
+	failBad := true
+	content := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests[r.URL.Path]++
+		fail := failBad && r.URL.Path == "/bad"
+		mu.Unlock()
+		if fail {
+			http.Error(w, "gone", http.StatusGone)
+			return
+		}
+		fmt.Fprintf(w, "content of %s", strings.TrimPrefix(r.URL.Path, "/"))
+	}))
+	defer content.Close()
+
+	tree := fakeTreeServer(dirs, nil)
+	client := createMockClient(t, func(req *http.Request) (*http.Response, error) {
+		if req.URL.Path != FILE_DOWNLOAD {
+			return tree(req)
+		}
+		var body struct {
+			Fids []string `json:"fids"`
+		}
+		json.NewDecoder(req.Body).Decode(&body)
+		return jsonResponse(req, fmt.Sprintf(`{"status":200,"code":0,"data":[{"download_url":"%s/%s"}]}`, content.URL, body.Fids[0])), nil
+	})
+
+	localDir := t.TempDir()
+	statePath := filepath.Join(localDir, "docs", DOWNLOAD_STATE_FILE)
+	resp, err := client.DownloadDir("/docs", localDir, DownloadDirOptions{})
+	if err != nil || resp.Code != "PARTIAL_SUCCESS" {
+		t.Fatalf("first DownloadDir() = %+v, %v", resp, err)
+	}
+	data, err := os.ReadFile(statePath)
+	if err != nil {
+		t.Fatalf("state file not written after partial failure: %v", err)
+	}
+	var state downloadState
+	if err := json.Unmarshal(data, &state); err != nil || len(state.Files) != 2 || state.Files["a.txt"].Fid != "f1" {
+		t.Fatalf("state = %s, %v", data, err)
+	}
+
+	// 重跑：已完成的文件按状态跳过（默认冲突策略 fail 也不会报 FILE_EXISTS），失败的文件重新下载，全部成功后删除状态文件
+	failBad = false
+	resp, err = client.DownloadDir("/docs", localDir, DownloadDirOptions{})
+	if err != nil || resp.Code != "OK" || resp.Data["resumed"] != 2 {
+		t.Fatalf("second DownloadDir() = %+v, %v", resp, err)
+	}
+	if requests["/f1"] != 1 || requests["/f2"] != 1 || requests["/bad"] != 2 {
+		t.Errorf("requests = %v, want completed files downloaded once", requests)
+	}
+	if _, err := os.Stat(statePath); !os.IsNotExist(err) {
+		t.Errorf("state file still exists after complete download: %v", err)
+	}
+
+	// --restart 忽略状态：已有的文件按冲突策略处理
+	failBad = true
+	client.DownloadDir("/docs", localDir, DownloadDirOptions{Conflict: DownloadConflictOverwrite})
+	resp, _ = client.DownloadDir("/docs", localDir, DownloadDirOptions{Restart: true, Conflict: DownloadConflictOverwrite})
+	if resp.Data["resumed"] != 0 || requests["/f1"] != 3 {
+		t.Errorf("restart: resumed = %v, f1 requested %d times, want 0 and 3", resp.Data["resumed"], requests["/f1"])
+	}
+}
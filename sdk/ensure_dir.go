@@ -0,0 +1,131 @@
+package sdk
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// ensureDirCall 一次进行中的 EnsureDir 调用，同一路径的并发调用等待 done 后共享结果
+type ensureDirCall struct {
+	done    chan struct{}
+	fid     string
+	errResp *StandardResponse
+}
+
+// EnsureDir 确保远端目录存在并返回其 fid（根目录为 "0"），不存在时逐级创建
+// 同一客户端内对同一路径的并发调用只会创建一次；建目录时遇到同名目录已存在（如其他进程刚建好）视为成功
+func (qc *QuarkClient) EnsureDir(dirPath string) (string, error) {
+	fid, errResp := qc.ensureDir(stripQuotes(dirPath))
+	if errResp != nil {
+		return "", errors.New(errResp.Message)
+	}
+	return fid, nil
+}
+
+// ensureDir 同 EnsureDir，失败时返回错误响应
+func (qc *QuarkClient) ensureDir(dirPath string) (string, *StandardResponse) {
+	dirPath = normalizePath(dirPath)
+	if dirPath == "" || dirPath == "/" || dirPath == "." {
+		return "0", nil
+	}
+
+	qc.ensureDirMu.Lock()
+	if call, ok := qc.ensureDirCalls[dirPath]; ok {
+		qc.ensureDirMu.Unlock()
+		<-call.done
+		return call.fid, call.errResp
+	}
+	if qc.ensureDirCalls == nil {
+		qc.ensureDirCalls = make(map[string]*ensureDirCall)
+	}
+	call := &ensureDirCall{done: make(chan struct{})}
+	qc.ensureDirCalls[dirPath] = call
+	qc.ensureDirMu.Unlock()
+
+	call.fid, call.errResp = qc.createDirIfMissing(dirPath)
+
+	qc.ensureDirMu.Lock()
+	delete(qc.ensureDirCalls, dirPath)
+	qc.ensureDirMu.Unlock()
+	close(call.done)
+	return call.fid, call.errResp
+}
+
+// createDirIfMissing 查询目录，不存在时先确保父目录存在，再在父目录下创建
+func (qc *QuarkClient) createDirIfMissing(dirPath string) (string, *StandardResponse) {
+	fid, found, errResp := qc.lookupDirFid(dirPath)
+	if errResp != nil || found {
+		return fid, errResp
+	}
+
+	parentFid, errResp := qc.ensureDir(path.Dir(dirPath))
+	if errResp != nil {
+		return "", errResp
+	}
+	createResp, err := qc.CreateFolder(path.Base(dirPath), parentFid)
+	if err != nil {
+		return "", &StandardResponse{
+			Success: false,
+			Code:    "CREATE_DIRECTORY_ERROR",
+			Message: fmt.Sprintf("failed to create directory %s: %v", dirPath, err),
+		}
+	}
+	if createResp.Success {
+		if fid, ok := createResp.Data["fid"].(string); ok && fid != "" {
+			return fid, nil
+		}
+	} else if createResp.Code != "FOLDER_EXISTS" {
+		return "", &StandardResponse{
+			Success: false,
+			Code:    "CREATE_DIRECTORY_ERROR",
+			Message: fmt.Sprintf("failed to create directory %s: %s", dirPath, createResp.Message),
+		}
+	}
+
+	// 同名目录已存在（被并发的上传抢先创建）或创建结果未带 fid：清掉缓存后重新查询
+	qc.InvalidatePathCache(dirPath)
+	fid, found, errResp = qc.lookupDirFid(dirPath)
+	if errResp != nil {
+		return "", errResp
+	}
+	if !found {
+		return "", &StandardResponse{
+			Success: false,
+			Code:    "CREATE_DIRECTORY_ERROR",
+			Message: fmt.Sprintf("failed to create directory %s: %s", dirPath, createResp.Message),
+		}
+	}
+	return fid, nil
+}
+
+// lookupDirFid 查询目录的 fid，目录不存在时 found 为 false
+func (qc *QuarkClient) lookupDirFid(dirPath string) (fid string, found bool, errResp *StandardResponse) {
+	info, err := qc.GetFileInfo(dirPath, true)
+	if err != nil || (!info.Success && info.Code == "FILE_NOT_FOUND") {
+		return "", false, nil
+	}
+	if !info.Success {
+		return "", false, &StandardResponse{
+			Success: false,
+			Code:    info.Code,
+			Message: fmt.Sprintf("failed to get destination directory: %s", info.Message),
+		}
+	}
+	fid, _ = info.Data["fid"].(string)
+	if fid == "" {
+		return "", false, &StandardResponse{
+			Success: false,
+			Code:    "INVALID_DIRECTORY_INFO",
+			Message: "destination directory info is invalid: fid not found or empty",
+		}
+	}
+	return fid, true, nil
+}
+
+// isFolderExistsError 判断建目录请求的错误是否为同名目录已存在
+func isFolderExistsError(msg string) bool {
+	return strings.Contains(msg, fmt.Sprintf("code %d", CREATE_FOLDER_EXISTS_CODE)) ||
+		strings.Contains(strings.ToLower(msg), "doubloon")
+}
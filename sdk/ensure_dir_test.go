@@ -0,0 +1,183 @@
+package sdk
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestEnsureDir_ConcurrentCreatesOnce(t *testing.T) {
+	files := map[string]map[string]interface{}{}
+	infoFn, _ := fakeFileInfoServer(files)
+	var mu sync.Mutex
+	creates := map[string]int{}
+	client := createMockClient(t, func(req *http.Request) (*http.Response, error) {
+		if req.URL.Path == CREATE_FOLDER {
+			time.Sleep(20 * time.Millisecond)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if req.URL.Path != CREATE_FOLDER {
+			return infoFn(req)
+		}
+		var body struct {
+			PdirFid  string `json:"pdir_fid"`
+			FileName string `json:"file_name"`
+		}
+		json.NewDecoder(req.Body).Decode(&body)
+		p := ""
+		for dirPath, info := range files {
+			if info["fid"] == body.PdirFid {
+				p = dirPath
+			}
+		}
+		p += "/" + body.FileName
+		creates[p]++
+		fid := "fid_" + body.FileName
+		files[p] = map[string]interface{}{"fid": fid, "file_name": body.FileName, "dir": true}
+		return jsonResponse(req, fmt.Sprintf(`{"status":200,"code":0,"data":{"fid":"%s"}}`, fid)), nil
+	})
+
+	var wg sync.WaitGroup
+	fids := make([]string, 8)
+	errs := make([]error, 8)
+	for i := range fids {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			fids[i], errs[i] = client.EnsureDir("/a/b")
+		}(i)
+	}
+	wg.Wait()
+	for i := range fids {
+		if errs[i] != nil || fids[i] != "fid_b" {
+			t.Errorf("EnsureDir() #%d = %q, %v, want fid_b", i, fids[i], errs[i])
+		}
+	}
+	if creates["/a"] != 1 || creates["/a/b"] != 1 {
+		t.Errorf("create folder calls = %v, want one per directory", creates)
+	}
+
+	if fid, err := client.EnsureDir("/"); err != nil || fid != "0" {
+		t.Errorf("EnsureDir(/) = %q, %v, want 0", fid, err)
+	}
+}
+
+func TestEnsureDir_FolderExists(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+	}{
+		{"code in body", http.StatusOK, `{"status":200,"code":23008,"message":"file is doubloon"}`},
+		{"http error with code", http.StatusBadRequest, `{"status":400,"code":23008}`},
+		{"http error with message", http.StatusBadRequest, `{"status":400,"code":23008,"message":"file is doubloon"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := map[string]map[string]interface{}{}
+			infoFn, _ := fakeFileInfoServer(files)
+			client := createMockClient(t, func(req *http.Request) (*http.Response, error) {
+				if req.URL.Path != CREATE_FOLDER {
+					return infoFn(req)
+				}
+				// 模拟另一个进程抢先建好了同名目录
+				files["/shared"] = map[string]interface{}{"fid": "fid_shared", "file_name": "shared", "dir": true}
+				resp := jsonResponse(req, tt.body)
+				resp.StatusCode = tt.status
+				return resp, nil
+			})
+			fid, err := client.EnsureDir("/shared")
+			if err != nil || fid != "fid_shared" {
+				t.Errorf("EnsureDir() = %q, %v, want fid_shared", fid, err)
+			}
+		})
+	}
+}
+
+// createParentsClient 返回一个空网盘的客户端：上传请求交给 server，新建的目录按路径记录到 created
+func createParentsClient(t *testing.T, server *fakeUploadServer, created *[]string) *QuarkClient {
+	files := map[string]map[string]interface{}{}
+	infoFn, _ := fakeFileInfoServer(files)
+	serve := server.roundTrip(t)
+	return createMockClient(t, func(req *http.Request) (*http.Response, error) {
+		switch req.URL.Path {
+		case FILE_INFO_PATH_LIST, FILE_INFO, FILE_SORT:
+			return infoFn(req)
+		case CREATE_FOLDER:
+			var body struct {
+				PdirFid  string `json:"pdir_fid"`
+				FileName string `json:"file_name"`
+			}
+			json.NewDecoder(req.Body).Decode(&body)
+			p := ""
+			for dirPath, info := range files {
+				if info["fid"] == body.PdirFid {
+					p = dirPath
+				}
+			}
+			p += "/" + body.FileName
+			*created = append(*created, p)
+			files[p] = map[string]interface{}{"fid": "fid_" + body.FileName, "file_name": body.FileName, "dir": true}
+			return jsonResponse(req, fmt.Sprintf(`{"status":200,"code":0,"data":{"fid":"fid_%s"}}`, body.FileName)), nil
+		}
+		return serve(req)
+	})
+}
+
+func TestUploadFile_CreateParents(t *testing.T) {
+	isolateUploadState(t)
+	localPath := filepath.Join(t.TempDir(), "a.txt")
+	if err := os.WriteFile(localPath, []byte("hello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	server := &fakeUploadServer{partSize: 1024}
+	var created []string
+	client := createParentsClient(t, server, &created)
+
+	resp, err := client.UploadFile(localPath, "/backup/2025/a.txt", nil, nil)
+	if err != nil || resp.Code != "PARENT_NOT_FOUND" || resp.Data["path"] != "/backup/2025" || !strings.Contains(resp.Message, "--parents") {
+		t.Fatalf("UploadFile() = %+v, %v, want PARENT_NOT_FOUND mentioning --parents", resp, err)
+	}
+	if len(created) != 0 || server.preCalls != 0 {
+		t.Errorf("created %v, pre-upload called %d times, want nothing", created, server.preCalls)
+	}
+
+	resp, err = client.UploadFile(localPath, "/backup/2025/a.txt", nil, &UploadOptions{CreateParents: true})
+	if err != nil || !resp.Success {
+		t.Fatalf("UploadFile(CreateParents) = %+v, %v", resp, err)
+	}
+	if fmt.Sprint(created) != "[/backup /backup/2025]" {
+		t.Errorf("created %v, want /backup and /backup/2025", created)
+	}
+}
+
+func TestUploadByHash_CreateParents(t *testing.T) {
+	const md5Hex = "d41d8cd98f00b204e9800998ecf8427e"
+	const sha1Hex = "da39a3ee5e6b4b0d3255bfef95601890afd80709"
+	server := &fakeUploadServer{partSize: 1024, hashFinish: true}
+	var created []string
+	client := createParentsClient(t, server, &created)
+
+	resp, err := client.UploadByHash("a.txt", "/backup/2025", 0, md5Hex, sha1Hex, nil)
+	if err != nil || resp.Code != "PARENT_NOT_FOUND" || resp.Data["path"] != "/backup/2025" || !strings.Contains(resp.Message, "--parents") {
+		t.Fatalf("UploadByHash() = %+v, %v, want PARENT_NOT_FOUND mentioning --parents", resp, err)
+	}
+	if len(created) != 0 || server.preCalls != 0 {
+		t.Errorf("created %v, pre-upload called %d times, want nothing", created, server.preCalls)
+	}
+
+	resp, err = client.UploadByHash("a.txt", "/backup/2025", 0, md5Hex, sha1Hex, &UploadOptions{CreateParents: true})
+	if err != nil || !resp.Success || resp.Data["path"] != "/backup/2025/a.txt" {
+		t.Fatalf("UploadByHash(CreateParents) = %+v, %v", resp, err)
+	}
+	if fmt.Sprint(created) != "[/backup /backup/2025]" {
+		t.Errorf("created %v, want /backup and /backup/2025", created)
+	}
+}
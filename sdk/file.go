@@ -9,16 +9,21 @@ import (
 	"encoding"
 	"encoding/base64"
 	"encoding/binary"
+	"encoding/hex"
 	"encoding/json"
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"hash"
 	"io"
-	"mime"
 	"net/http"
 	"net/url"
 	"os"
+	"path"
 	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -30,6 +35,9 @@ func isRetryableError(err error) bool {
 	if err == nil {
 		return false
 	}
+	if errors.Is(err, errUploadStalled) || errors.Is(err, errUploadTooSlow) {
+		return true
+	}
 	errStr := err.Error()
 	retryablePatterns := []string{
 		"EOF",
@@ -60,16 +68,32 @@ type uploadPartResult struct {
 	err        error
 }
 
-// getUploadStatePath 获取上传状态文件路径
-func getUploadStatePath(filePath, destPath string) string {
+// uploadStatePath 获取上传状态文件路径
+// 状态文件保存在 transfer.upload_state_dir 配置的目录中，未配置时为用户缓存目录下的 UPLOAD_STATE_DIR（重启后仍在，不像临时目录可能被清理）
+// filePath 应为绝对路径，在不同工作目录下重跑同一上传时对应同一个状态文件
+func (qc *QuarkClient) uploadStatePath(filePath, destPath string) string {
 	// 基于文件路径和目标路径生成唯一的状态文件路径
 	hash := md5.Sum([]byte(filePath + "|" + destPath))
 	hashStr := fmt.Sprintf("%x", hash)
-	stateDir := filepath.Join(os.TempDir(), "kuake_upload_state")
-	os.MkdirAll(stateDir, 0755)
+	stateDir := qc.UploadStateDir()
+	// 状态文件含 OSS 上传凭据，目录只允许当前用户访问
+	os.MkdirAll(stateDir, 0700)
 	return filepath.Join(stateDir, hashStr+".json")
 }
 
+// UploadStateDir 返回断点续传状态文件所在目录：SetUploadStateDir、环境变量 UPLOAD_STATE_ENV 或 transfer.upload_state_dir 设置的目录，
+// 未设置时为用户缓存目录下的 UPLOAD_STATE_DIR，无法获取缓存目录时退回临时目录
+func (qc *QuarkClient) UploadStateDir() string {
+	if qc.uploadStateDir != "" {
+		return qc.uploadStateDir
+	}
+	baseDir, err := os.UserCacheDir()
+	if err != nil {
+		baseDir = os.TempDir()
+	}
+	return filepath.Join(baseDir, UPLOAD_STATE_DIR)
+}
+
 // loadUploadState 加载上传状态
 func loadUploadState(statePath string) (*UploadState, error) {
 	data, err := os.ReadFile(statePath)
@@ -85,12 +109,23 @@ func loadUploadState(statePath string) (*UploadState, error) {
 
 // saveUploadState 保存上传状态
 func saveUploadState(statePath string, state *UploadState) error {
+	if statePath == "" {
+		return nil // 流式上传（UploadFromURL）无法续传，不保存状态
+	}
 	state.CreatedAt = time.Now()
 	data, err := json.MarshalIndent(state, "", "  ")
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(statePath, data, 0644)
+	// 先写临时文件再重命名，避免进程中途被杀时留下不完整的状态文件
+	if err := os.WriteFile(statePath+".tmp", data, 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(statePath+".tmp", statePath); err != nil {
+		os.Remove(statePath + ".tmp")
+		return err
+	}
+	return nil
 }
 
 // deleteUploadState 删除上传状态文件
@@ -142,12 +177,26 @@ func cloneHashCtx(hashCtx *HashCtx) *HashCtx {
 	return &cloned
 }
 
+// rapidUploadProgress 秒传完成时的最后一次进度：已上传等于总大小，Rapid 为 true
+func rapidUploadProgress(total int64, startTime time.Time) *UploadProgress {
+	return &UploadProgress{
+		Progress:     100,
+		Uploaded:     total,
+		Total:        total,
+		SpeedStr:     formatSpeed(0),
+		RemainingStr: formatDuration(0),
+		Elapsed:      time.Since(startTime),
+		Rapid:        true,
+	}
+}
+
+// buildUploadProgressInfo 生成上传进度：Speed 为最近 PROGRESS_SPEED_WINDOW 内的平均速度，剩余时间按平均速度估算
+// 上传完成（uploaded == total）时 Speed 为整体平均速度，Elapsed 为总耗时
 func buildUploadProgressInfo(
 	uploaded int64,
 	total int64,
 	startTime time.Time,
-	lastUpdateTime *time.Time,
-	lastUploaded *int64,
+	meter *speedMeter,
 ) *UploadProgress {
 	if uploaded < 0 {
 		uploaded = 0
@@ -164,34 +213,16 @@ func buildUploadProgressInfo(
 		}
 	}
 
-	now := time.Now()
-	elapsed := now.Sub(startTime)
-
-	var speed float64
-	var remaining time.Duration
-
-	if !lastUpdateTime.IsZero() && elapsed > 0 {
-		deltaTime := now.Sub(*lastUpdateTime)
-		deltaUploaded := uploaded - *lastUploaded
-		if deltaTime > 0 {
-			speed = float64(deltaUploaded) / deltaTime.Seconds()
-		} else {
-			speed = float64(uploaded) / elapsed.Seconds()
-		}
-		if speed > 0 {
-			remainingBytes := total - uploaded
-			remaining = time.Duration(float64(remainingBytes)/speed) * time.Second
-		}
-	} else if elapsed > 0 {
-		speed = float64(uploaded) / elapsed.Seconds()
-		if speed > 0 {
-			remainingBytes := total - uploaded
-			remaining = time.Duration(float64(remainingBytes)/speed) * time.Second
-		}
+	elapsed := time.Since(startTime)
+	instant, speed := meter.update(uploaded)
+	remaining := estimateRemaining(uploaded, total, speed)
+	if remaining < 0 {
+		remaining = 0
+	}
+	if uploaded == total {
+		speed, _ = meter.overall(uploaded)
+		instant = 0
 	}
-
-	*lastUpdateTime = now
-	*lastUploaded = uploaded
 
 	return &UploadProgress{
 		Progress:     progress,
@@ -199,6 +230,7 @@ func buildUploadProgressInfo(
 		Total:        total,
 		Speed:        speed,
 		SpeedStr:     formatSpeed(speed),
+		InstantSpeed: instant,
 		Remaining:    remaining,
 		RemainingStr: formatDuration(remaining),
 		Elapsed:      elapsed,
@@ -206,7 +238,8 @@ func buildUploadProgressInfo(
 }
 
 func (qc *QuarkClient) uploadPartsParallel(
-	file *os.File,
+	parent context.Context, // 取消时中断所有分片，返回 parent.Err()
+	file io.Reader, // 按分片顺序读取，除最后一片外每次 Read 须读满分片（流式数据用 fullReader 包装）
 	pre *PreUploadResponse,
 	mimeType string,
 	partSize int64,
@@ -219,8 +252,9 @@ func (qc *QuarkClient) uploadPartsParallel(
 	alreadyUploaded map[int]string, // 断点续传：已上传分片（partNumber -> etag），为空则全新上传
 	hashMD5 hash.Hash, // 嵌入式哈希：生产者累积计算 MD5（用于 upHash）
 	hashSHA1ForUpHash hash.Hash, // 嵌入式哈希：生产者累积计算 SHA1（用于 upHash）
+	limiter *RateLimiter, // 上传限速器：所有分片 worker 共享，nil 表示不限速
 ) (map[int]string, error) {
-	ctx, cancel := context.WithCancel(context.Background())
+	ctx, cancel := context.WithCancel(parent)
 	defer cancel()
 
 	jobCh := make(chan uploadPartJob, uploadParallel*2)
@@ -244,7 +278,7 @@ func (qc *QuarkClient) uploadPartsParallel(
 						return
 					}
 					var uploadErr error
-					etag, _, uploadErr = qc.upPart(pre, mimeType, job.partNumber, job.chunkData, job.hashCtx) // 【Round 20.5】恢复传递 HashCtx。虽然是并行模式，但服务端仍要求每个分片携带 Context，最终在 commit 阶段做链式跨分片校验。
+					etag, _, uploadErr = qc.upPart(ctx, pre, mimeType, job.partNumber, job.chunkData, job.hashCtx, limiter) // 【Round 20.5】恢复传递 HashCtx。虽然是并行模式，但服务端仍要求每个分片携带 Context，最终在 commit 阶段做链式跨分片校验。
 					if uploadErr == nil {
 						lastErr = nil
 						break
@@ -258,9 +292,16 @@ func (qc *QuarkClient) uploadPartsParallel(
 						backoff := time.Duration(1<<uint(attempt)) * time.Second
 						fmt.Printf("[重试] 分片 %d 上传失败 (第 %d/%d 次): %v, %.0f秒后重试...\n",
 							job.partNumber, attempt+1, maxRetries, uploadErr, backoff.Seconds())
-						time.Sleep(backoff)
+						select {
+						case <-time.After(backoff):
+						case <-ctx.Done():
+							return
+						}
 					}
 				}
+				// 分片已发送完毕（upPart 返回后不再读取缓冲），放回缓冲池供生产者读取后续分片
+				size := int64(len(job.chunkData))
+				putPartBuffer(job.chunkData)
 				if lastErr != nil {
 					resultCh <- uploadPartResult{
 						partNumber: job.partNumber,
@@ -272,7 +313,7 @@ func (qc *QuarkClient) uploadPartsParallel(
 				select {
 				case resultCh <- uploadPartResult{
 					partNumber: job.partNumber,
-					size:       int64(len(job.chunkData)),
+					size:       size,
 					etag:       etag,
 				}:
 				case <-ctx.Done():
@@ -282,7 +323,9 @@ func (qc *QuarkClient) uploadPartsParallel(
 		}()
 	}
 
+	producerDone := make(chan struct{})
 	go func() {
+		defer close(producerDone)
 		defer close(jobCh)
 
 		partNumber := 1
@@ -295,12 +338,15 @@ func (qc *QuarkClient) uploadPartsParallel(
 				return
 			}
 
-			chunk := make([]byte, partSize)
+			// 分片缓冲从缓冲池获取，由 worker 上传完后放回；同时在途的缓冲最多为 jobCh 容量加上 worker 数
+			chunk := getPartBuffer(partSize)
 			n, err := file.Read(chunk)
 			if err == io.EOF {
+				putPartBuffer(chunk)
 				return
 			}
 			if err != nil {
+				putPartBuffer(chunk)
 				resultCh <- uploadPartResult{
 					partNumber: partNumber,
 					err:        fmt.Errorf("failed to read file chunk: %w", err),
@@ -309,6 +355,7 @@ func (qc *QuarkClient) uploadPartsParallel(
 				return
 			}
 			if n == 0 {
+				putPartBuffer(chunk)
 				return
 			}
 
@@ -332,6 +379,7 @@ func (qc *QuarkClient) uploadPartsParallel(
 
 			// 断点续传：跳过已上传的分片（仍需读文件和计算哈希以维持后续分片 HashCtx 一致性）
 			if _, ok := alreadyUploaded[partNumber]; ok {
+				putPartBuffer(chunk)
 				partNumber++
 				continue
 			}
@@ -345,22 +393,23 @@ func (qc *QuarkClient) uploadPartsParallel(
 			select {
 			case jobCh <- job:
 			case <-ctx.Done():
+				putPartBuffer(chunk)
 				return
 			}
 			partNumber++
 		}
 	}()
 
+	// 等生产者也退出后再关闭 resultCh：返回后调用方可能立即复用 file（如退回串行上传）
 	go func() {
 		workerWG.Wait()
+		<-producerDone
 		close(resultCh)
 	}()
 
 	totalParts := int((fileSize + partSize - 1) / partSize)
 	uploadedPartMap := make(map[int]string, totalParts)
 	var uploadedBytes int64
-	var lastUpdateTime time.Time
-	var lastUploaded int64
 	var firstErr error
 
 	// 断点续传：预填充已上传分片信息，计算已传字节数
@@ -377,14 +426,13 @@ func (qc *QuarkClient) uploadPartsParallel(
 			}
 		}
 	}
-	if len(alreadyUploaded) > 0 {
-		lastUpdateTime = time.Now()
-		lastUploaded = uploadedBytes
-	}
+	// 已上传的分片不计入速度
+	meter := newSpeedMeter(uploadedBytes)
 
 	for result := range resultCh {
 		if result.err != nil {
-			if firstErr == nil {
+			// PartNotSequential 优先返回，调用方据此退回串行上传
+			if firstErr == nil || (errors.Is(result.err, ErrPartNotSequential) && !errors.Is(firstErr, ErrPartNotSequential)) {
 				firstErr = result.err
 				cancel()
 			}
@@ -401,13 +449,16 @@ func (qc *QuarkClient) uploadPartsParallel(
 				uploadedBytes,
 				fileSize,
 				startTime,
-				&lastUpdateTime,
-				&lastUploaded,
+				meter,
 			)
 			progressCallback(progressInfo)
 		}
 	}
 
+	// 被调用方取消时，其它分片因取消而失败的错误不再返回
+	if err := parent.Err(); err != nil {
+		return nil, err
+	}
 	if firstErr != nil {
 		return nil, firstErr
 	}
@@ -419,6 +470,182 @@ func (qc *QuarkClient) uploadPartsParallel(
 	return uploadedPartMap, nil
 }
 
+// finishRapidUpload upHash 返回 finish=true（服务端已有相同文件）时调用 upFinish 完成秒传，Data 中 rapid 为 true
+func (qc *QuarkClient) finishRapidUpload(pre *PreUploadResponse) *StandardResponse {
+	finishResp, err := qc.upFinish(pre)
+	if err != nil {
+		return &StandardResponse{
+			Success: false,
+			Code:    "FINISH_UPLOAD_ERROR",
+			Message: fmt.Sprintf("finish upload failed: %v", err),
+			Data:    nil,
+		}
+	}
+	responseData := make(map[string]interface{})
+	for k, v := range finishResp.Data {
+		if k != "preview_url" {
+			responseData[k] = v
+		}
+	}
+	responseData["rapid"] = true
+	return &StandardResponse{
+		Success: true,
+		Code:    "OK",
+		Message: "上传完成（秒传）",
+		Data:    responseData,
+	}
+}
+
+// rapidUploadWithHash 用已算好的 md5/sha1 调用 upHash 尝试秒传：命中时完成上传，未命中时返回 RAPID_UPLOAD_MISS
+func (qc *QuarkClient) rapidUploadWithHash(pre *PreUploadResponse, md5Sum, sha1Sum string) *StandardResponse {
+	hashResp, err := qc.upHash(md5Sum, sha1Sum, pre.Data.TaskID)
+	if err != nil {
+		return &StandardResponse{
+			Success: false,
+			Code:    "UPLOAD_HASH_ERROR",
+			Message: fmt.Sprintf("rapid upload check failed: %v", err),
+			Data:    nil,
+		}
+	}
+	if !hashResp.Data.Finish {
+		return &StandardResponse{
+			Success: false,
+			Code:    "RAPID_UPLOAD_MISS",
+			Message: "秒传未命中：服务端没有相同的文件，未上传",
+			Data:    map[string]interface{}{"rapid": false, "md5": md5Sum, "sha1": sha1Sum},
+		}
+	}
+	return qc.finishRapidUpload(pre)
+}
+
+// UploadByHash 用已知的 md5/sha1/大小直接秒传（不需要本地文件）：在 destPath 目录下创建名为 name 的文件，
+// 只走 upPre → upHash → upFinish；服务端没有相同文件时返回 RAPID_UPLOAD_MISS。
+// 哈希支持十六进制或 base64 编码；opts 只有 CreateParents 生效（可为 nil）：目标目录不存在时返回 PARENT_NOT_FOUND，为 true 时逐级创建
+func (qc *QuarkClient) UploadByHash(name, destPath string, size int64, md5Hash, sha1Hash string, opts *UploadOptions) (*StandardResponse, error) {
+	name = normalizeNFC(strings.TrimSpace(name))
+	if name == "" || strings.Contains(name, "/") {
+		return &StandardResponse{
+			Success: false,
+			Code:    "INVALID_ARGS",
+			Message: fmt.Sprintf("invalid file name: %q", name),
+			Data:    nil,
+		}, nil
+	}
+	if size < 0 {
+		return &StandardResponse{
+			Success: false,
+			Code:    "INVALID_ARGS",
+			Message: fmt.Sprintf("invalid file size: %d", size),
+			Data:    nil,
+		}, nil
+	}
+	md5Sum := normalizeHashHex(strings.TrimSpace(md5Hash), md5.Size)
+	sha1Sum := normalizeHashHex(strings.TrimSpace(sha1Hash), sha1.Size)
+	if md5Sum == "" || sha1Sum == "" {
+		return &StandardResponse{
+			Success: false,
+			Code:    "INVALID_ARGS",
+			Message: "invalid hash: md5 must be 32 hex characters and sha1 40 hex characters",
+			Data:    nil,
+		}, nil
+	}
+
+	destDirPath := normalizePath(destPath)
+	destFilePath := normalizePath(strings.TrimSuffix(destDirPath, "/") + "/" + name)
+	// 上传可能新建目标文件，结束后使其路径缓存失效
+	defer qc.InvalidatePathCache(destFilePath)
+
+	dirFid, errResp := qc.uploadDestDirFid(destDirPath, opts != nil && opts.CreateParents)
+	if errResp != nil {
+		return errResp, nil
+	}
+	mimeType := uploadMimeType(name, "", nil)
+	pre, err := qc.upPre(name, mimeType, size, dirFid, 0, 0)
+	if err != nil {
+		return &StandardResponse{
+			Success: false,
+			Code:    "PRE_UPLOAD_ERROR",
+			Message: fmt.Sprintf("pre-upload failed: %v", err),
+			Data:    nil,
+		}, nil
+	}
+	hashResp, err := qc.upHash(md5Sum, sha1Sum, pre.Data.TaskID)
+	if err != nil {
+		return &StandardResponse{
+			Success: false,
+			Code:    "UPLOAD_HASH_ERROR",
+			Message: fmt.Sprintf("rapid upload check failed: %v", err),
+			Data:    nil,
+		}, nil
+	}
+	if !hashResp.Data.Finish {
+		return &StandardResponse{
+			Success: false,
+			Code:    "RAPID_UPLOAD_MISS",
+			Message: fmt.Sprintf("秒传未命中：服务端没有 md5=%s sha1=%s 的文件", md5Sum, sha1Sum),
+			Data:    map[string]interface{}{"rapid": false, "path": destFilePath, "md5": md5Sum, "sha1": sha1Sum, "size": size},
+		}, nil
+	}
+	resp := qc.finishRapidUpload(pre)
+	if resp.Success {
+		resp.Data["path"] = destFilePath
+	}
+	return resp, nil
+}
+
+// discardExpiredUploadState 分片上传返回 NoSuchUpload（uploadId 已过期或被清理）时删除断点续传状态，下次上传重新开始
+func discardExpiredUploadState(statePath string, err error) {
+	if err != nil && strings.Contains(err.Error(), "NoSuchUpload") {
+		deleteUploadState(statePath)
+	}
+}
+
+// uploadPartSizeFor 返回上传 fileSize 字节的文件使用的分片大小：从服务端给出的 partSize 开始翻倍，直到分片数不超过 OSS_MAX_PARTS
+func uploadPartSizeFor(partSize, fileSize int64) int64 {
+	if partSize <= 0 {
+		return partSize
+	}
+	for (fileSize+partSize-1)/partSize > OSS_MAX_PARTS {
+		partSize *= 2
+	}
+	return partSize
+}
+
+// uploadParallelFor 返回同时上传的分片数：parallel（为 0 时读取环境变量 UPLOAD_PARALLEL_ENV）覆盖服务端的 partThread，
+// 都未设置时使用 partThread；服务端只允许单线程（partThread <= 1，含 bucket 要求顺序上传后保存的续传状态）时总是串行，结果不超过 totalParts
+func uploadParallelFor(parallel, partThread, totalParts int) int {
+	if parallel <= 0 {
+		if n, err := strconv.Atoi(strings.TrimSpace(os.Getenv(UPLOAD_PARALLEL_ENV))); err == nil && n >= MIN_UPLOAD_PARALLEL && n <= MAX_UPLOAD_PARALLEL {
+			parallel = n
+		}
+	}
+	n := partThread
+	if partThread > 1 && parallel > 0 {
+		n = parallel
+	}
+	if n <= 0 {
+		n = 1 // 服务端未返回时退回单线程
+	}
+	if n > totalParts {
+		n = totalParts
+	}
+	return n
+}
+
+// sequentialUploadedPrefix 返回从分片 1 起连续上传成功的分片 ETag，以及之后第一个需要上传的分片号
+func sequentialUploadedPrefix(parts map[int]string) ([]string, int) {
+	etags := make([]string, 0, len(parts))
+	next := 1
+	for {
+		etag, ok := parts[next]
+		if !ok {
+			return etags, next
+		}
+		etags = append(etags, etag)
+		next++
+	}
+}
+
 // stripQuotes 去掉路径参数中可能存在的首尾引号（处理 Git Bash 等特殊情况）
 // 某些 shell 或调用方式可能会保留引号，此函数用于统一处理
 func stripQuotes(path string) string {
@@ -430,16 +657,16 @@ func stripQuotes(path string) string {
 }
 
 // normalizePath 将路径标准化为 Unix 风格（使用 / 作为分隔符）
-func normalizePath(path string) string {
-	path = stripQuotes(path)
-	path = strings.ReplaceAll(path, "\\", "/")
-	for strings.Contains(path, "//") {
-		path = strings.ReplaceAll(path, "//", "/")
-	}
-	if len(path) > 1 && strings.HasSuffix(path, "/") {
-		path = strings.TrimSuffix(path, "/")
-	}
-	return path
+// 按 path.Clean 的语义合并重复斜杠、去掉末尾斜杠并解析 "." 和 ".." 路径段，
+// 绝对路径中越过根目录的 ".." 钳制在 "/"；与本地操作系统的分隔符无关
+// 同时将文件名统一为 Unicode NFC 形式（macOS 本地文件名为 NFD，网盘中为 NFC）
+func normalizePath(remotePath string) string {
+	remotePath = normalizeNFC(stripQuotes(remotePath))
+	remotePath = strings.ReplaceAll(remotePath, "\\", "/")
+	if remotePath == "" {
+		return ""
+	}
+	return path.Clean(remotePath)
 }
 
 // normalizeRootDir 将根目录路径转换为 API 所需的 FID "0"
@@ -451,17 +678,23 @@ func normalizeRootDir(path string) string {
 	return path
 }
 
-// upPre 预上传请求
-func (qc *QuarkClient) upPre(fileName, mimeType string, size int64, parentID string) (*PreUploadResponse, error) {
+// upPre 预上传请求；createdAt/updatedAt 为文件的本地创建/修改时间（毫秒），<= 0 时使用当前时间
+func (qc *QuarkClient) upPre(fileName, mimeType string, size int64, parentID string, createdAt, updatedAt int64) (*PreUploadResponse, error) {
 	now := time.Now().UnixMilli()
+	if createdAt <= 0 {
+		createdAt = now
+	}
+	if updatedAt <= 0 {
+		updatedAt = now
+	}
 	data := map[string]interface{}{
 		"ccp_hash_update": true,
 		"parallel_upload": true,
 		"dir_name":        "",
 		"file_name":       fileName,
 		"format_type":     mimeType,
-		"l_created_at":    now,
-		"l_updated_at":    now,
+		"l_created_at":    createdAt,
+		"l_updated_at":    updatedAt,
 		"pdir_fid":        parentID,
 		"size":            size,
 	}
@@ -584,8 +817,8 @@ func encodeHashCtx(ctx *HashCtx) (string, error) {
 	return base64.StdEncoding.EncodeToString(jsonData), nil
 }
 
-// upPart 上传文件分片
-func (qc *QuarkClient) upPart(pre *PreUploadResponse, mimeType string, partNumber int, chunkData []byte, hashCtx *HashCtx) (string, *HashCtx, error) {
+// upPart 上传文件分片，limiter 不为 nil 时按限速器发送请求体
+func (qc *QuarkClient) upPart(parent context.Context, pre *PreUploadResponse, mimeType string, partNumber int, chunkData []byte, hashCtx *HashCtx, limiter *RateLimiter) (string, *HashCtx, error) {
 	now := time.Now().UTC().Format("Mon, 02 Jan 2006 15:04:05 GMT")
 
 	// 构建 authMeta，如果 partNumber >= 2，需要包含 X-Oss-Hash-Ctx
@@ -638,15 +871,49 @@ func (qc *QuarkClient) upPart(pre *PreUploadResponse, mimeType string, partNumbe
 	params.Set("uploadId", pre.Data.UploadID)
 	req.URL.RawQuery = params.Encode()
 
-	// 为上传请求设置较长的超时时间（30分钟），主要依赖服务器端响应
-	// 这个超时仅作为安全网，防止网络问题导致的永久挂起
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+	// 分片上传不使用主客户端的 30 秒超时：连接阶段由 uploadTransport 限时，
+	// 传输阶段按分片大小计算超时，到期时只要请求体仍在发送就继续等待，连续 UPLOAD_STALL_TIMEOUT 没有进展才取消
+	timeout := qc.uploadPartTimeoutFor(int64(len(chunkData)))
+	ctx, cancel := context.WithCancel(parent)
 	defer cancel()
+	watchdog := startTransferWatchdog(cancel, timeout, UPLOAD_STALL_TIMEOUT)
+	defer watchdog.stop()
+	// 低速检测：一个统计窗口内平均速度低于 transfer.upload_min_speed 时断开，由重试逻辑重新发送该分片
+	watchdog.watchSpeed(qc.uploadMinSpeed, qc.uploadSpeedWindow, int64(len(chunkData)))
 	req = req.WithContext(ctx)
+	// chunkData 来自分片缓冲池，返回后调用方会复用；Transport 可能在返回后仍读取请求体，返回前断开所有请求体与缓冲的关联
+	var bodiesMu sync.Mutex
+	var bodies []*partBody
+	newBody := func() io.ReadCloser {
+		body := newPartBody(chunkData)
+		bodiesMu.Lock()
+		bodies = append(bodies, body)
+		bodiesMu.Unlock()
+		return io.NopCloser(watchdog.reader(limiter.Reader(body)))
+	}
+	defer func() {
+		bodiesMu.Lock()
+		defer bodiesMu.Unlock()
+		for _, body := range bodies {
+			body.detach()
+		}
+	}()
+	req.Body = newBody()
+	req.GetBody = func() (io.ReadCloser, error) {
+		return newBody(), nil
+	}
 
 	// 发送请求
-	resp, err := qc.HttpClient.Do(req)
+	resp, err := qc.uploadHTTPClient().Do(req)
 	if err != nil {
+		if watchdog.expired() {
+			return "", nil, fmt.Errorf("failed to upload chunk: %w (part %d, %d bytes, no progress for %s after %s)",
+				errUploadStalled, partNumber, len(chunkData), UPLOAD_STALL_TIMEOUT, timeout)
+		}
+		if watchdog.slow() {
+			return "", nil, fmt.Errorf("failed to upload chunk: %w (part %d, %d bytes, below %d bytes/s over %s)",
+				errUploadTooSlow, partNumber, len(chunkData), qc.uploadMinSpeed, qc.uploadSpeedWindow)
+		}
 		return "", nil, fmt.Errorf("failed to upload chunk: %w", err)
 	}
 	defer resp.Body.Close()
@@ -674,6 +941,11 @@ func (qc *QuarkClient) upPart(pre *PreUploadResponse, mimeType string, partNumbe
 			}
 		}
 
+		// PartNotSequential（400）：bucket 要求按 partNumber 顺序上传，调用方据此退回串行上传
+		if strings.Contains(bodyStr, "PartNotSequential") {
+			return "", nil, fmt.Errorf("%w: upload chunk failed with status %d: %s", ErrPartNotSequential, resp.StatusCode, bodyStr)
+		}
+
 		return "", nil, fmt.Errorf("upload chunk failed with status %d: %s", resp.StatusCode, bodyStr)
 	}
 
@@ -822,17 +1094,113 @@ func (qc *QuarkClient) upFinish(pre *PreUploadResponse) (*FinishResponse, error)
 	return &finishResp, nil
 }
 
-// UploadFile 上传文件到夸克网盘，支持大文件分片上传
+// uploadDestPath 返回上传的远端文件路径：destPath 为根目录时在其下沿用本地文件名，否则 destPath 即目标文件路径
+func uploadDestPath(destPath, localFileName string) string {
+	destPath = normalizePath(destPath)
+	if strings.HasSuffix(destPath, "/") || filepath.Base(destPath) == "" || filepath.Base(destPath) == "." {
+		return strings.TrimSuffix(destPath, "/") + "/" + localFileName
+	}
+	return destPath
+}
+
+// uploadDestDirFid 返回上传目标目录的 fid（根目录为 "0"），目录不存在时 createParents 为 true 则逐级创建，否则返回 PARENT_NOT_FOUND；失败时返回错误响应
+func (qc *QuarkClient) uploadDestDirFid(destDirPath string, createParents bool) (string, *StandardResponse) {
+	if createParents {
+		return qc.ensureDir(destDirPath)
+	}
+	destDirPath = normalizePath(destDirPath)
+	if destDirPath == "" || destDirPath == "/" || destDirPath == "." {
+		return "0", nil
+	}
+	fid, found, errResp := qc.lookupDirFid(destDirPath)
+	if errResp != nil || found {
+		return fid, errResp
+	}
+	return "", parentNotFoundResponse(destDirPath)
+}
+
+// parentNotFoundResponse 上传目标目录不存在且未要求自动创建时的错误响应
+func parentNotFoundResponse(dirPath string) *StandardResponse {
+	return &StandardResponse{
+		Success: false,
+		Code:    "PARENT_NOT_FOUND",
+		Message: fmt.Sprintf("目标目录不存在: %s（上传不再自动创建缺失的父目录，需要时使用 --parents 或 UploadOptions.CreateParents）", dirPath),
+		Data:    map[string]interface{}{"path": dirPath},
+	}
+}
+
+// uploadPolicySkipped 按去重策略检查远端 destPath（size 为要上传的字节数），需要跳过上传时返回 SKIPPED 结果，否则返回 nil
+func (qc *QuarkClient) uploadPolicySkipped(destPath string, size int64, policy UploadPolicy) *StandardResponse {
+	if policy != UploadPolicySkip && policy != UploadPolicyRsync {
+		return nil // policy == UploadPolicyOverwrite 或未设置：直接上传
+	}
+	existingInfo, existErr := qc.GetFileInfo(destPath)
+	if existErr != nil || existingInfo == nil || !existingInfo.Success {
+		return nil
+	}
+	// 文件已存在
+	switch policy {
+	case UploadPolicySkip:
+		return &StandardResponse{
+			Success: true,
+			Code:    "SKIPPED",
+			Message: fmt.Sprintf("文件已存在，跳过上传: %s", destPath),
+			Data:    existingInfo.Data,
+		}
+	case UploadPolicyRsync:
+		// 检查文件大小是否一致
+		if existingInfo.Data != nil {
+			var existingSize int64
+			switch v := existingInfo.Data["size"].(type) {
+			case float64:
+				existingSize = int64(v)
+			case int64:
+				existingSize = v
+			}
+			if existingSize == size {
+				return &StandardResponse{
+					Success: true,
+					Code:    "SKIPPED",
+					Message: fmt.Sprintf("文件大小相同，跳过上传: %s (%d bytes)", destPath, existingSize),
+					Data:    existingInfo.Data,
+				}
+			}
+			// 大小不同，继续上传（覆盖）
+		}
+	}
+	return nil
+}
+
+// uploadLocalFile UploadFile 读取的本地文件
+type uploadLocalFile interface {
+	io.ReadSeekCloser
+	Stat() (os.FileInfo, error)
+}
+
+// openUploadFile 打开 UploadFile 要上传的本地文件（测试中可替换）
+var openUploadFile = func(name string) (uploadLocalFile, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+// UploadFile 上传文件到夸克网盘，支持大文件分片上传和断点续传
 // progressCallback: 进度回调函数，如果为 nil 则不显示进度
-// opts: 上传选项（可为 nil，使用默认行为）
+// opts: 上传选项（可为 nil，使用默认行为）；目标目录不存在时返回 PARENT_NOT_FOUND，opts.CreateParents 为 true 时逐级创建
 func (qc *QuarkClient) UploadFile(filePath, destPath string, progressCallback func(*UploadProgress), opts *UploadOptions) (*StandardResponse, error) {
 	// 解析选项，nil 安全
-	var policy UploadPolicy
+	var o UploadOptions
 	if opts != nil {
-		policy = opts.Policy
+		o = *opts
+	}
+	ctx := o.Context
+	if ctx == nil {
+		ctx = context.Background()
 	}
 	filePath = stripQuotes(filePath)
-	file, err := os.Open(filePath)
+	file, err := openUploadFile(filePath)
 	if err != nil {
 		return &StandardResponse{
 			Success: false,
@@ -853,251 +1221,109 @@ func (qc *QuarkClient) UploadFile(filePath, destPath string, progressCallback fu
 		}, nil
 	}
 
-	fileSize := fileInfo.Size()
-	localFileName := fileInfo.Name()
-
-	// 记录开始时间，用于计算速度和剩余时间
-	startTime := time.Now()
-
-	destPath = normalizePath(destPath)
-	var destFileName string
-	if strings.HasSuffix(destPath, "/") || filepath.Base(destPath) == "" || filepath.Base(destPath) == "." {
-		destPath = strings.TrimSuffix(destPath, "/") + "/" + localFileName
-		destFileName = localFileName
-	} else {
-		destFileName = filepath.Base(destPath)
+	destPath = uploadDestPath(destPath, normalizeNFC(fileInfo.Name()))
+	// 服务端拒绝控制字符和超长文件名且报错难懂，预上传前先校验
+	namedPath, nameErr := uploadNamedDestPath(destPath, o.SanitizeNames)
+	if nameErr != nil {
+		return invalidFileNameResponse(destPath, nameErr), nil
 	}
 
-	destDirPath := destPath
-	if destDirPath == "/" || destDirPath == "" {
-		destDirPath = "/"
-	} else {
-		lastSlash := strings.LastIndex(destDirPath, "/")
-		if lastSlash == 0 {
-			destDirPath = "/"
-		} else if lastSlash > 0 {
-			destDirPath = destDirPath[:lastSlash]
-		} else {
-			destDirPath = "/"
-		}
+	// 断点续传状态按本地文件的绝对路径和目标路径区分
+	absFilePath, absErr := filepath.Abs(filePath)
+	if absErr != nil {
+		absFilePath = filePath
 	}
-	destDirPath = normalizePath(destDirPath)
-
-	if destDirPath != "/" && destDirPath != "" && destDirPath != "." {
-		destDirInfo, err := qc.GetFileInfo(destDirPath)
-		needCreate := err != nil || (destDirInfo != nil && !destDirInfo.Success && destDirInfo.Code == "FILE_NOT_FOUND")
-		if needCreate {
-			parts := strings.Split(strings.Trim(destDirPath, "/"), "/")
-			currentPath := ""
-			var lastCreatedFid string // 记录最后创建的目录 FID
-			for _, part := range parts {
-				if part == "" {
-					continue
-				}
-				if currentPath == "" {
-					currentPath = "/" + part
-				} else {
-					currentPath = currentPath + "/" + part
-				}
-				currentPath = normalizePath(currentPath)
-				checkInfo, err := qc.GetFileInfo(currentPath)
-				needCreatePath := err != nil || (checkInfo != nil && !checkInfo.Success && checkInfo.Code == "FILE_NOT_FOUND")
-				if needCreatePath {
-					parentPathForCreate := "/"
-					if currentPath != "/" && currentPath != "" {
-						if lastSlash := strings.LastIndex(currentPath, "/"); lastSlash > 0 {
-							parentPathForCreate = normalizePath(currentPath[:lastSlash])
-						}
-					}
-					createResp, createErr := qc.CreateFolder(part, parentPathForCreate)
-					if createErr != nil {
-						return &StandardResponse{
-							Success: false,
-							Code:    "CREATE_DIRECTORY_ERROR",
-							Message: fmt.Sprintf("failed to create directory %s: %v", currentPath, createErr),
-							Data:    nil,
-						}, nil
-					}
-					if createResp == nil || !createResp.Success {
-						msg := "unknown error"
-						if createResp != nil {
-							msg = createResp.Message
-						}
-						return &StandardResponse{
-							Success: false,
-							Code:    "CREATE_DIRECTORY_ERROR",
-							Message: fmt.Sprintf("failed to create directory %s: %s", currentPath, msg),
-							Data:    nil,
-						}, nil
-					}
-					// 如果创建成功，从返回的 Data 中获取 FID
-					if createResp.Data != nil {
-						if fid, ok := createResp.Data["fid"].(string); ok && fid != "" {
-							lastCreatedFid = fid
-						}
-					}
-				}
-			}
-			// 如果创建了目录并获取到了 FID，直接使用 FID，否则再次查询路径
-			if lastCreatedFid != "" {
-				destDirPath = lastCreatedFid
-				destDirInfo = &StandardResponse{
-					Success: true,
-					Code:    "OK",
-					Message: "Directory created",
-					Data:    map[string]interface{}{"fid": lastCreatedFid},
-				}
-			} else {
-				destDirInfo, err = qc.GetFileInfo(destDirPath)
-				if err != nil {
-					return &StandardResponse{
-						Success: false,
-						Code:    "GET_DIRECTORY_INFO_ERROR",
-						Message: fmt.Sprintf("failed to get destination directory info: %v", err),
-						Data:    nil,
-					}, nil
-				}
-			}
-		}
-		if !destDirInfo.Success {
-			return &StandardResponse{
-				Success: false,
-				Code:    destDirInfo.Code,
-				Message: fmt.Sprintf("failed to get destination directory: %s", destDirInfo.Message),
-				Data:    nil,
-			}, nil
-		}
-		fid, ok := destDirInfo.Data["fid"].(string)
-		if !ok || fid == "" {
-			return &StandardResponse{
-				Success: false,
-				Code:    "INVALID_DIRECTORY_INFO",
-				Message: "destination directory info is invalid: fid not found or empty",
-				Data:    nil,
-			}, nil
-		}
-		destDirPath = fid
-	} else {
-		destDirPath = "0"
+	src := &uploadSource{
+		r:      file,
+		size:   fileInfo.Size(),
+		resume: &uploadResume{file: file, filePath: absFilePath, modTime: fileInfo.ModTime().UnixNano()},
 	}
-
-	mimeType := mime.TypeByExtension(filepath.Ext(destFileName))
-	if mimeType == "" {
-		mimeType = "application/octet-stream"
+	// 网盘中的创建/修改时间记为本地文件的时间，列表和增量同步看到的 mtime 与本地一致
+	if !o.NoPreserveMtime {
+		src.createdAt, src.updatedAt = fileCreateTime(fileInfo).UnixMilli(), fileInfo.ModTime().UnixMilli()
 	}
-
-	// 去重策略检查：在 upPre 之前检查目标路径是否已存在同名文件
-	if policy == UploadPolicySkip || policy == UploadPolicyRsync {
-		existingInfo, existErr := qc.GetFileInfo(destPath)
-		if existErr == nil && existingInfo != nil && existingInfo.Success {
-			// 文件已存在
-			switch policy {
-			case UploadPolicySkip:
-				return &StandardResponse{
-					Success: true,
-					Code:    "SKIPPED",
-					Message: fmt.Sprintf("文件已存在，跳过上传: %s", destPath),
-					Data:    existingInfo.Data,
-				}, nil
-			case UploadPolicyRsync:
-				// 检查文件大小是否一致
-				if existingInfo.Data != nil {
-					var existingSize int64
-					switch v := existingInfo.Data["size"].(type) {
-					case float64:
-						existingSize = int64(v)
-					case int64:
-						existingSize = v
-					}
-					if existingSize == fileSize {
-						return &StandardResponse{
-							Success: true,
-							Code:    "SKIPPED",
-							Message: fmt.Sprintf("文件大小相同，跳过上传: %s (%d bytes)", destPath, existingSize),
-							Data:    existingInfo.Data,
-						}, nil
-					}
-					// 大小不同，继续上传（覆盖）
-				}
-			}
-		}
-		// policy == UploadPolicyOverwrite 或文件不存在：继续上传
-	}
-
-	// 先检查是否有保存的上传状态（断点续传）
-	statePath := getUploadStatePath(filePath, destPath)
-	var savedState *UploadState
-	var pre *PreUploadResponse
-	var useSavedState bool
-
-	// 尝试加载保存的上传状态
-	if state, loadErr := loadUploadState(statePath); loadErr == nil {
-		// 验证状态是否有效：文件路径、大小、目标路径是否匹配
-		if state.FilePath == filePath && state.DestPath == destPath && state.FileSize == fileSize {
-			// 尝试使用保存的状态，构建 PreUploadResponse
-			pre = &PreUploadResponse{
-				Code:   0,
-				Status: 200,
-			}
-			pre.Data.TaskID = state.TaskID
-			pre.Data.Bucket = state.Bucket
-			pre.Data.ObjKey = state.ObjKey
-			pre.Data.UploadID = state.UploadID
-			pre.Data.UploadURL = state.UploadURL
-			pre.Data.AuthInfo = state.AuthInfo
-			pre.Data.Callback = state.Callback
-			pre.Metadata.PartSize = state.PartSize
-			pre.Metadata.PartThread = state.PartThread // 恢复并发线程数，确保 parallel 模式续传不退化
-
-			// 验证 uploadId 是否仍然有效：尝试上传一个空分片或查询分片列表
-			// 由于没有查询 API，我们直接尝试使用，如果失败再重新获取
-			useSavedState = true
-			savedState = state
-			// HashCtx 会在后续的上传循环中从 savedState 恢复
-		} else {
-			// 文件不匹配，删除旧状态
-			deleteUploadState(statePath)
-		}
+	result := qc.uploadData(ctx, src, namedPath, progressCallback, &o)
+	if namedPath != destPath {
+		// 文件名已替换：按替换后的路径上传，结果中带上实际路径和原文件名
+		return withSanitizedName(result, nil, destPath, namedPath)
 	}
+	return result, nil
+}
 
-	// 如果没有保存的状态或状态无效，调用 upPre 获取新的上传信息
-	if !useSavedState {
-		pre, err = qc.upPre(destFileName, mimeType, fileSize, destDirPath)
-		if err != nil {
-			return &StandardResponse{
-				Success: false,
-				Code:    "PRE_UPLOAD_ERROR",
-				Message: fmt.Sprintf("pre-upload failed: %v", err),
-				Data:    nil,
-			}, nil
-		}
-	}
+// uploadResume 本地文件上传的断点续传状态：每上传一个分片写入状态文件，中断后重新上传同一文件时跳过已上传的分片
+type uploadResume struct {
+	file      io.ReadSeeker // 上传的本地文件，续传时需要从头重新读取
+	filePath  string        // 本地文件的绝对路径
+	modTime   int64         // 本地文件的修改时间（纳秒），文件改动过时已上传的分片作废
+	destPath  string        // 目标文件路径，由 uploadData 处理冲突后填入
+	statePath string
+	saved     *UploadState // 沿用的上传状态，nil 表示新建的上传会话
+}
 
-	// upHash 确认上传会话：通过嵌入式哈希策略，在分片读取过程中同步计算 MD5+SHA1，
-	// 之后调用 upHash 确认服务端上传生命周期（upPre → upHash → upCommit）。
-	//
-	// 【核心】upHash 必须被调用，否则服务端会清理未确认的 OSS 上传会话，
-	// 导致 upCommit 时返回 404 NoSuchUpload。
-	//
-	// 【嵌入式策略（Round 17）】将 MD5+SHA1 计算嵌入分片读取流程（生产者/顺序路径），
-	// 消除第二个文件句柄的 14GB 冗余读取，NFS 场景下断点续传启动延迟大幅降低。
+// load 读取断点续传状态：文件路径、大小、修改时间、目标路径都匹配时返回沿用的上传会话和建立会话时的 MIME 类型，
+// 不匹配时删除旧状态，返回 nil（需要 upPre 新建会话）
+func (rs *uploadResume) load(size int64, mimeType string) (*PreUploadResponse, string) {
+	state, err := loadUploadState(rs.statePath)
+	if err != nil {
+		return nil, mimeType
+	}
+	if state.FilePath != rs.filePath || state.DestPath != rs.destPath || state.FileSize != size || state.ModTime != rs.modTime {
+		deleteUploadState(rs.statePath)
+		return nil, mimeType
+	}
+	// 由于没有查询 uploadId 是否有效的 API，直接沿用，失败时再重新上传
+	pre := &PreUploadResponse{
+		Code:   0,
+		Status: 200,
+	}
+	pre.Data.TaskID = state.TaskID
+	pre.Data.Bucket = state.Bucket
+	pre.Data.ObjKey = state.ObjKey
+	pre.Data.UploadID = state.UploadID
+	pre.Data.UploadURL = state.UploadURL
+	pre.Data.AuthInfo = state.AuthInfo
+	pre.Data.Callback = state.Callback
+	pre.Metadata.PartSize = state.PartSize
+	pre.Metadata.PartThread = state.PartThread // 恢复并发线程数，确保 parallel 模式续传不退化
+	if state.MimeType != "" {
+		mimeType = state.MimeType // 续传的分片沿用建立上传会话时 upPre 的 MIME 类型
+	}
+	rs.saved = state
+	return pre, mimeType
+}
 
-	// parallelHashResult 在主线程内传递 upHash 结果
-	type parallelHashResult struct {
-		isRapid bool
-		err     error
+// discard 删除断点续传状态文件；rs 为 nil（流式上传）时什么都不做
+func (rs *uploadResume) discard() {
+	if rs != nil {
+		deleteUploadState(rs.statePath)
 	}
-	parallelHashCh := make(chan parallelHashResult, 1)
+}
+
+// uploadResumableParts 上传本地文件的全部分片，每上传一个分片保存断点续传状态，沿用 rs.saved 时跳过其中已上传的分片。
+// 【嵌入式策略（Round 17）】读取分片时同步计算 upHash 所需的 MD5+SHA1，消除第二个文件句柄的 14GB 冗余读取，
+// NFS 场景下断点续传启动延迟大幅降低
+func (qc *QuarkClient) uploadResumableParts(ctx context.Context, rs *uploadResume, pre *PreUploadResponse, mimeType string, fileSize int64, startTime time.Time, progressCallback func(*UploadProgress), opts *UploadOptions) (*uploadedParts, *StandardResponse) {
+	file := rs.file
+	statePath := rs.statePath
+	savedState := rs.saved
+	useSavedState := savedState != nil
+	limiter := opts.RateLimiter
 
 	// 嵌入式哈希对象：在分片读取过程中累积计算，所有分片处理完毕后提交 upHash
 	embeddedMD5 := md5.New()
 	embeddedSHA1 := sha1.New()
 
-	partSize := pre.Metadata.PartSize
+	// 服务端给出的分片大小对超大文件会超过 OSS_MAX_PARTS 片，在最后 commit 时才失败，这里提前翻倍到不超限
+	partSize := uploadPartSizeFor(pre.Metadata.PartSize, fileSize)
+	if partSize != pre.Metadata.PartSize && qc.Debug {
+		fmt.Printf("[调试] 文件大小 %d 按 %d 字节分片会超过 %d 片，改用分片大小 %d（共 %d 片）\n",
+			fileSize, pre.Metadata.PartSize, OSS_MAX_PARTS, partSize, (fileSize+partSize-1)/partSize)
+	}
 	file.Seek(0, 0)
 
 	var etags []string
-	var startPartNumber int = 1
+	startPartNumber := 1
+	var resumedParts int // 断点续传跳过的已上传分片数
 
 	// 如果使用保存的状态，恢复已上传的分片信息
 	if useSavedState {
@@ -1120,25 +1346,20 @@ func (qc *QuarkClient) UploadFile(filePath, destPath string, progressCallback fu
 				break
 			}
 		}
+		resumedParts = startPartNumber - 1
 	}
 
-	// 并发数完全由服务端 part_thread 控制。
 	// 当 upPre 请求含 parallel_upload=true 时，服务端启用并行 OSS 模式，
-	// 返回 metadata.part_thread 作为并发数（通常为 3）。
+	// 返回 metadata.part_thread 作为默认并发数（通常为 3），opts.Parallel 可覆盖
 	totalParts := int((fileSize + partSize - 1) / partSize)
-	uploadParallel := pre.Metadata.PartThread
-	if uploadParallel <= 0 {
-		uploadParallel = 1 // 服务端未返回时退回单线程
-	}
-	if uploadParallel > totalParts {
-		uploadParallel = totalParts
-	}
+	uploadParallel := uploadParallelFor(opts.Parallel, pre.Metadata.PartThread, totalParts)
 	canUseParallel := uploadParallel > 1
 
 	buildUploadState := func(currentHashCtx *HashCtx) *UploadState {
 		return &UploadState{
-			FilePath:      filePath,
-			DestPath:      destPath,
+			FilePath:      rs.filePath,
+			ModTime:       rs.modTime,
+			DestPath:      rs.destPath,
 			FileSize:      fileSize,
 			UploadID:      pre.Data.UploadID,
 			TaskID:        pre.Data.TaskID,
@@ -1167,6 +1388,7 @@ func (qc *QuarkClient) UploadFile(filePath, destPath string, progressCallback fu
 				alreadyUploaded[pn] = etag
 			}
 		}
+		resumedParts = len(alreadyUploaded)
 
 		// 构建新的上传状态，并预填充已上传分片
 		savedState = buildUploadState(nil)
@@ -1175,6 +1397,7 @@ func (qc *QuarkClient) UploadFile(filePath, destPath string, progressCallback fu
 		}
 
 		uploadedPartMap, uploadErr := qc.uploadPartsParallel(
+			ctx,
 			file,
 			pre,
 			mimeType,
@@ -1188,53 +1411,70 @@ func (qc *QuarkClient) UploadFile(filePath, destPath string, progressCallback fu
 			alreadyUploaded,
 			embeddedMD5,
 			embeddedSHA1,
+			limiter,
 		)
-		if uploadErr != nil {
-			return &StandardResponse{
+		if errors.Is(uploadErr, ErrPartNotSequential) {
+			// 该 bucket 要求按 partNumber 顺序上传：保留从分片 1 起连续上传成功的分片，其余分片由下面的顺序路径重传
+			// PartThread 置为 1 并写入续传状态，之后续传同一文件时直接串行上传
+			canUseParallel = false
+			pre.Metadata.PartThread = 1
+			savedState.PartThread = 1
+			etags, startPartNumber = sequentialUploadedPrefix(savedState.UploadedParts)
+			for pn := range savedState.UploadedParts {
+				if pn >= startPartNumber {
+					delete(savedState.UploadedParts, pn)
+				}
+			}
+			_ = saveUploadState(statePath, savedState)
+			useSavedState = startPartNumber > 1
+			// 生产者已读过的数据已写入嵌入式哈希，顺序路径从文件头重新读取和累积
+			file.Seek(0, 0)
+			embeddedMD5.Reset()
+			embeddedSHA1.Reset()
+		} else if ctx.Err() != nil {
+			// 已完成的分片在 uploadPartsParallel 中逐片写入了状态文件；一个分片都没完成时也保存，下次运行沿用这次的上传会话
+			_ = saveUploadState(statePath, savedState)
+			return nil, uploadInterrupted(statePath, len(savedState.UploadedParts), totalParts)
+		} else if uploadErr != nil {
+			discardExpiredUploadState(statePath, uploadErr)
+			return nil, &StandardResponse{
 				Success: false,
 				Code:    "UPLOAD_PART_ERROR",
 				Message: uploadErr.Error(),
 				Data:    nil,
-			}, nil
-		}
-
-		// 嵌入式哈希：所有分片已由生产者读取并累积哈希，提交 upHash
-		md5Sum := fmt.Sprintf("%x", embeddedMD5.Sum(nil))
-		sha1Sum := fmt.Sprintf("%x", embeddedSHA1.Sum(nil))
-		hashResp, hashErr := qc.upHash(md5Sum, sha1Sum, pre.Data.TaskID)
-		if hashErr != nil {
-			parallelHashCh <- parallelHashResult{err: hashErr}
+			}
 		} else {
-			parallelHashCh <- parallelHashResult{isRapid: hashResp.Data.Finish}
-		}
-
-		etags = make([]string, totalParts)
-		for i := 1; i <= totalParts; i++ {
-			etag, ok := uploadedPartMap[i]
-			if !ok {
-				return &StandardResponse{
-					Success: false,
-					Code:    "UPLOAD_PART_ERROR",
-					Message: fmt.Sprintf("parallel upload missing part %d", i),
-					Data:    nil,
-				}, nil
+			etags = make([]string, totalParts)
+			for i := 1; i <= totalParts; i++ {
+				etag, ok := uploadedPartMap[i]
+				if !ok {
+					return nil, &StandardResponse{
+						Success: false,
+						Code:    "UPLOAD_PART_ERROR",
+						Message: fmt.Sprintf("parallel upload missing part %d", i),
+						Data:    nil,
+					}
+				}
+				etags[i-1] = etag
 			}
-			etags[i-1] = etag
 		}
-	} else {
-		// === 顺序上传路径（后备逻辑，仅在 totalParts==1 或 uploadParallel==1 时触发）===
+	}
+	if !canUseParallel {
+		// === 顺序上传路径（totalParts==1、uploadParallel==1，或并行上传遇到 PartNotSequential 时触发）===
+
+		// 续传时重新读取已上传分片计算哈希所用的缓冲；上传阶段的分片由 partPrefetcher 双缓冲预读
+		partBuf := getPartBuffer(partSize)
+		defer putPartBuffer(partBuf)
 
-		// 用于计算速度和剩余时间
-		var lastUpdateTime time.Time
-		var lastUploaded int64
+		// 用于计算速度和剩余时间，续传时已上传的字节数不计入速度
+		var resumedBytes int64
 
 		// 如果从断点续传，计算已上传的字节数并跳过已上传的分片
 		if useSavedState && startPartNumber > 1 {
-			lastUploaded = int64(startPartNumber-1) * partSize
-			if lastUploaded > fileSize {
-				lastUploaded = fileSize
+			resumedBytes = int64(startPartNumber-1) * partSize
+			if resumedBytes > fileSize {
+				resumedBytes = fileSize
 			}
-			lastUpdateTime = time.Now()
 			skipBytes := int64(startPartNumber-1) * partSize
 			if skipBytes > 0 {
 				file.Seek(skipBytes, 0)
@@ -1253,18 +1493,20 @@ func (qc *QuarkClient) UploadFile(filePath, destPath string, progressCallback fu
 				cumulativeHash = sha1.New()
 				processedBytes = 0
 				for i := 1; i < startPartNumber; i++ {
-					chunk := make([]byte, partSize)
+					chunk := partBuf
 					n, err := file.Read(chunk)
 					if err != nil && err != io.EOF {
-						return &StandardResponse{
+						return nil, &StandardResponse{
 							Success: false,
 							Code:    "READ_FILE_ERROR",
 							Message: fmt.Sprintf("failed to read file chunk for hash calculation: %v", err),
 							Data:    nil,
-						}, nil
+						}
 					}
 					if n > 0 {
 						cumulativeHash.Write(chunk[:n])
+						embeddedMD5.Write(chunk[:n])
+						embeddedSHA1.Write(chunk[:n])
 						processedBytes += int64(n)
 					}
 				}
@@ -1274,18 +1516,20 @@ func (qc *QuarkClient) UploadFile(filePath, destPath string, progressCallback fu
 				cumulativeHash = sha1.New()
 				processedBytes = 0
 				for i := 1; i < startPartNumber; i++ {
-					chunk := make([]byte, partSize)
+					chunk := partBuf
 					n, err := file.Read(chunk)
 					if err != nil && err != io.EOF {
-						return &StandardResponse{
+						return nil, &StandardResponse{
 							Success: false,
 							Code:    "READ_FILE_ERROR",
 							Message: fmt.Sprintf("failed to read file chunk for hash calculation: %v", err),
 							Data:    nil,
-						}, nil
+						}
 					}
 					if n > 0 {
 						cumulativeHash.Write(chunk[:n])
+						embeddedMD5.Write(chunk[:n])
+						embeddedSHA1.Write(chunk[:n])
 						processedBytes += int64(n)
 					}
 				}
@@ -1298,10 +1542,13 @@ func (qc *QuarkClient) UploadFile(filePath, destPath string, progressCallback fu
 			hashCtx = nil
 		}
 
+		meter := newSpeedMeter(resumedBytes)
 		partNumber := startPartNumber
+		// 后台预读下一片，上传当前分片时磁盘读取不必等待网络
+		prefetch := newPartPrefetcher(file, partSize)
+		defer prefetch.close()
 		for {
-			chunk := make([]byte, partSize)
-			n, err := file.Read(chunk)
+			chunk, err := prefetch.next()
 			if err == io.EOF {
 				break
 			}
@@ -1321,20 +1568,14 @@ func (qc *QuarkClient) UploadFile(filePath, destPath string, progressCallback fu
 				}
 				_ = saveUploadState(statePath, savedState)
 
-				return &StandardResponse{
+				return nil, &StandardResponse{
 					Success: false,
 					Code:    "READ_FILE_ERROR",
 					Message: fmt.Sprintf("failed to read file chunk: %v", err),
 					Data:    nil,
-				}, nil
-			}
-
-			if n == 0 {
-				break
+				}
 			}
 
-			chunk = chunk[:n]
-
 			// 嵌入式哈希：顺序路径也在每个分片读取后累积 MD5+SHA1
 			embeddedMD5.Write(chunk)
 			embeddedSHA1.Write(chunk)
@@ -1345,7 +1586,7 @@ func (qc *QuarkClient) UploadFile(filePath, destPath string, progressCallback fu
 				currentHashCtx = hashCtx
 			}
 
-			etag, _, err := qc.upPart(pre, mimeType, partNumber, chunk, currentHashCtx)
+			etag, _, err := qc.upPart(ctx, pre, mimeType, partNumber, chunk, currentHashCtx, limiter)
 			if err != nil {
 				// 上传失败，保存当前状态以便断点续传
 				if savedState == nil {
@@ -1361,13 +1602,17 @@ func (qc *QuarkClient) UploadFile(filePath, destPath string, progressCallback fu
 					savedState.UploadedParts[i+1] = uploadedEtag
 				}
 				_ = saveUploadState(statePath, savedState)
+				if ctx.Err() != nil {
+					return nil, uploadInterrupted(statePath, len(etags), totalParts)
+				}
+				discardExpiredUploadState(statePath, err)
 
-				return &StandardResponse{
+				return nil, &StandardResponse{
 					Success: false,
 					Code:    "UPLOAD_PART_ERROR",
 					Message: fmt.Sprintf("failed to upload part %d: %v", partNumber, err),
 					Data:    nil,
-				}, nil
+				}
 			}
 
 			etags = append(etags, etag)
@@ -1377,6 +1622,7 @@ func (qc *QuarkClient) UploadFile(filePath, destPath string, progressCallback fu
 				hashCtx, _ = updateHashCtxFromHash(cumulativeHash, chunk, processedBytes)
 				processedBytes += int64(len(chunk))
 			}
+			prefetch.release(chunk)
 
 			// 更新上传状态
 			if savedState == nil {
@@ -1393,128 +1639,24 @@ func (qc *QuarkClient) UploadFile(filePath, destPath string, progressCallback fu
 
 			// 更新进度
 			if progressCallback != nil {
-				uploaded := int64(len(etags)) * partSize
 				progressInfo := buildUploadProgressInfo(
-					uploaded,
+					processedBytes, // 按实际读取的字节数计算，最后一片不满 partSize
 					fileSize,
 					startTime,
-					&lastUpdateTime,
-					&lastUploaded,
+					meter,
 				)
 				progressCallback(progressInfo)
 			}
 
 			partNumber++
 		}
-
-		// 嵌入式哈希：顺序路径所有分片读取完毕，提交 upHash
-		md5Sum := fmt.Sprintf("%x", embeddedMD5.Sum(nil))
-		sha1Sum := fmt.Sprintf("%x", embeddedSHA1.Sum(nil))
-		hashResp, hashErr := qc.upHash(md5Sum, sha1Sum, pre.Data.TaskID)
-		if hashErr != nil {
-			parallelHashCh <- parallelHashResult{err: hashErr}
-		} else {
-			parallelHashCh <- parallelHashResult{isRapid: hashResp.Data.Finish}
-		}
-	}
-
-	// 10. 嵌入式哈希完成后，检查 upHash 结果
-	// 目的：确保 upHash 在 upCommit 之前被调用（服务端协议要求）
-	if parallelHashCh != nil {
-		hashResult := <-parallelHashCh
-		if hashResult.err != nil {
-			// upHash 失败：记录但不中断（降级处理，继续 commit 尝试）
-			// 在正常网络条件下不应进入此分支
-			_ = hashResult.err
-		} else if hashResult.isRapid {
-			// 秒传：upHash 告知服务端文件已存在，直接走 upFinish 跳过 commit
-			deleteUploadState(statePath)
-			finishResp, err := qc.upFinish(pre)
-			if err != nil {
-				return &StandardResponse{
-					Success: false,
-					Code:    "FINISH_UPLOAD_ERROR",
-					Message: fmt.Sprintf("finish upload failed: %v", err),
-					Data:    nil,
-				}, nil
-			}
-			responseData := make(map[string]interface{})
-			for k, v := range finishResp.Data {
-				if k != "preview_url" {
-					responseData[k] = v
-				}
-			}
-			return &StandardResponse{
-				Success: true,
-				Code:    "OK",
-				Message: "上传完成（秒传）",
-				Data:    responseData,
-			}, nil
-		}
-		// isRapid=false：服务端确认需要正常上传，继续走 commit 流程
-	}
-
-	// 10. 提交上传
-	finish, err := qc.upCommit(pre, etags)
-	if err != nil {
-		// NoSuchUpload 说明 OSS 端的 uploadId 已失效（可能已过期或被清理），
-		// 必须删除断点续传状态文件，避免重试时反复使用同一个过期 uploadId 导致死循环
-		if strings.Contains(err.Error(), "NoSuchUpload") {
-			deleteUploadState(statePath)
-		}
-		return &StandardResponse{
-			Success: false,
-			Code:    "COMMIT_UPLOAD_ERROR",
-			Message: fmt.Sprintf("commit upload failed: %v", err),
-			Data:    nil,
-		}, nil
-	}
-
-	// OSS commit 成功后，需要调用 upFinish 通知夸克服务器
-	if finish.Code == 0 && finish.Status == 200 {
-		// 调用 upFinish 确认上传完成
-		finishResp, err := qc.upFinish(pre)
-		if err != nil {
-			return &StandardResponse{
-				Success: false,
-				Code:    "FINISH_UPLOAD_ERROR",
-				Message: fmt.Sprintf("finish upload failed: %v", err),
-				Data:    nil,
-			}, nil
-		}
-		if finishResp.Code != 0 || finishResp.Status != 200 {
-			return &StandardResponse{
-				Success: false,
-				Code:    "FINISH_UPLOAD_ERROR",
-				Message: fmt.Sprintf("finish upload failed: code=%d, status=%d", finishResp.Code, finishResp.Status),
-				Data:    nil,
-			}, nil
-		}
-
-		// 上传成功，删除状态文件
-		deleteUploadState(statePath)
-
-		// 移除 preview_url 字段
-		responseData := make(map[string]interface{})
-		for k, v := range finishResp.Data {
-			if k != "preview_url" {
-				responseData[k] = v
-			}
-		}
-		return &StandardResponse{
-			Success: true,
-			Code:    "OK",
-			Message: "上传完成",
-			Data:    responseData,
-		}, nil
 	}
 
-	// 如果 commit 失败
-	return &StandardResponse{
-		Success: false,
-		Code:    "COMMIT_UPLOAD_ERROR",
-		Message: fmt.Sprintf("commit upload failed: code=%d, status=%d", finish.Code, finish.Status),
-		Data:    nil,
+	return &uploadedParts{
+		etags:   etags,
+		md5Sum:  fmt.Sprintf("%x", embeddedMD5.Sum(nil)),
+		sha1Sum: fmt.Sprintf("%x", embeddedSHA1.Sum(nil)),
+		resumed: resumedParts,
 	}, nil
 }
 
@@ -1542,6 +1684,14 @@ func (qc *QuarkClient) CreateFolder(folderName, pdirFid string) (*StandardRespon
 
 	respMap, err := qc.makeRequest("POST", CREATE_FOLDER, bytes.NewBuffer(jsonData), nil)
 	if err != nil {
+		if isFolderExistsError(err.Error()) {
+			return &StandardResponse{
+				Success: false,
+				Code:    "FOLDER_EXISTS",
+				Message: fmt.Sprintf("folder already exists: %s", folderName),
+				Data:    nil,
+			}, nil
+		}
 		return &StandardResponse{
 			Success: false,
 			Code:    "CREATE_FOLDER_REQUEST_ERROR",
@@ -1560,6 +1710,14 @@ func (qc *QuarkClient) CreateFolder(folderName, pdirFid string) (*StandardRespon
 		}, nil
 	}
 
+	if createResp.Code == CREATE_FOLDER_EXISTS_CODE {
+		return &StandardResponse{
+			Success: false,
+			Code:    "FOLDER_EXISTS",
+			Message: fmt.Sprintf("folder already exists: %s", folderName),
+			Data:    nil,
+		}, nil
+	}
 	if createResp.Code != 0 || createResp.Status != 200 {
 		return &StandardResponse{
 			Success: false,
@@ -1569,6 +1727,7 @@ func (qc *QuarkClient) CreateFolder(folderName, pdirFid string) (*StandardRespon
 		}, nil
 	}
 
+	qc.invalidatePathCacheByFid(pdirFid)
 	return &StandardResponse{
 		Success: true,
 		Code:    "OK",
@@ -1902,6 +2061,8 @@ func (qc *QuarkClient) Move(srcPath, destPath string) (*StandardResponse, error)
 		}, nil
 	}
 
+	qc.InvalidatePathCache(srcPath)
+	qc.InvalidatePathCache(destPath)
 	return &StandardResponse{
 		Success: true,
 		Code:    "OK",
@@ -1993,6 +2154,8 @@ func (qc *QuarkClient) Rename(oldPath, newName string) (*StandardResponse, error
 		}, nil
 	}
 
+	qc.InvalidatePathCache(oldPath)
+	qc.InvalidatePathCache(normalizePath(filepath.Join(filepath.Dir(oldPath), newName)))
 	return &StandardResponse{
 		Success: true,
 		Code:    "OK",
@@ -2001,25 +2164,129 @@ func (qc *QuarkClient) Rename(oldPath, newName string) (*StandardResponse, error
 	}, nil
 }
 
-// listByFid 通过 FID 列出目录下的文件（内部方法，避免循环调用）
-// 支持分页，自动获取所有文件
-func (qc *QuarkClient) listByFid(pdirFid string, parentPath ...string) (*StandardResponse, error) {
-	// 确定父目录路径：如果提供了 parentPath，使用它；否则根据 pdirFid 判断
-	var basePath string
-	if len(parentPath) > 0 && parentPath[0] != "" {
-		basePath = parentPath[0]
-	} else if pdirFid == "0" {
-		basePath = "/"
-	} else {
-		// 如果没有提供 parentPath 且不是根目录，路径为空（无法确定）
-		basePath = ""
+// httpStatusFromError 从 makeRequest 返回的错误中提取 HTTP 状态码（"status 502: ..."），非 HTTP 状态错误返回 0
+func httpStatusFromError(err error) int {
+	var status int
+	if _, scanErr := fmt.Sscanf(err.Error(), "status %d", &status); scanErr != nil {
+		return 0
 	}
+	return status
+}
 
-	// 用于存储所有文件的列表
-	allFileList := make([]QuarkFileInfo, 0)
-	page := 1
-	pageSize := 50 // 每页大小
+// isRetryableListError 判断列目录这类幂等 GET 请求的错误是否值得重试：5xx、请求超时和瞬时网络错误
+func isRetryableListError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if status := httpStatusFromError(err); status > 0 {
+		return status >= 500
+	}
+	return err.Error() == "request timeout" || isRetryableError(err)
+}
+
+// listAttemptResult 单次列目录请求失败的简要结果，HTTP 错误为状态码，其它为错误信息
+func listAttemptResult(err error) string {
+	if status := httpStatusFromError(err); status > 0 {
+		return fmt.Sprintf("status %d", status)
+	}
+	return err.Error()
+}
+
+// listRetryBaseDelay 列目录单页重试的退避基数（第 n 次重试等待 2^n 倍），测试中可调小
+var listRetryBaseDelay = time.Second
+
+// sortParam 校验排序选项并生成 _sort 查询参数，始终保持目录在前
+func (o ListOptions) sortParam() (string, error) {
+	if o.SortBy == "" && o.Order == "" {
+		return DEFAULT_LIST_SORT, nil
+	}
+	sortBy := o.SortBy
+	switch sortBy {
+	case "":
+		sortBy = "file_name"
+	case "file_name", "updated_at", "size":
+	default:
+		return "", fmt.Errorf("invalid sort field %q, must be 'file_name', 'updated_at', or 'size'", o.SortBy)
+	}
+	order := o.Order
+	switch order {
+	case "":
+		order = "asc"
+	case "asc", "desc":
+	default:
+		return "", fmt.Errorf("invalid sort order %q, must be 'asc' or 'desc'", o.Order)
+	}
+	return fmt.Sprintf("file_type:asc,%s:%s", sortBy, order), nil
+}
+
+// validate 校验列目录选项
+func (o ListOptions) validate() error {
+	if _, err := o.sortParam(); err != nil {
+		return err
+	}
+	if o.Page < 0 {
+		return fmt.Errorf("invalid page %d, must be >= 1", o.Page)
+	}
+	if o.Limit < 0 {
+		return fmt.Errorf("invalid limit %d, must be >= 1", o.Limit)
+	}
+	if _, err := o.categoryParam(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// categoryParam 将类别名转换为 category 查询参数，空表示不过滤
+func (o ListOptions) categoryParam() (string, error) {
+	if o.Category == "" {
+		return "", nil
+	}
+	category, ok := LIST_CATEGORIES[o.Category]
+	if !ok {
+		return "", fmt.Errorf("invalid category %q, must be 'video', 'audio', 'image', or 'document'", o.Category)
+	}
+	return category, nil
+}
+
+// listPageMeta 翻页列目录时从服务端响应中收集的元信息
+type listPageMeta struct {
+	total    int                    // 服务端总数（metadata._total），未知时为已遍历条目数
+	lastPage int                    // 最后拉取的服务端页码
+	metadata map[string]interface{} // 最后一页响应的原始 metadata
+}
+
+// data 将元信息转换为 StandardResponse.Data 中的字段（total、page、size、metadata）
+func (m listPageMeta) data() map[string]interface{} {
+	metadata := m.metadata
+	if metadata == nil {
+		metadata = map[string]interface{}{}
+	}
+	return map[string]interface{}{
+		"total":    m.total,
+		"page":     m.lastPage,
+		"size":     LIST_PAGE_SIZE,
+		"metadata": metadata,
+	}
+}
+
+// walkListPages 按页遍历 FID 目录下的文件，每取到一页调用一次 visit，visit 返回 false 时提前停止翻页
+// 按响应 metadata._total 翻页直到取完，单页请求遇到瞬时网络错误时按指数退避重试，
+// file/sort 接口失败时回退到旧版列表接口（FILE_LIST_LEGACY），
+// 条目保持服务端排序并按 fid 去重
+// 返回服务端总数等元信息（见 listPageMeta）；失败时返回非 nil 的 StandardResponse
+// sort 为 _sort 查询参数，空表示默认排序；category 为类别过滤参数，空表示不过滤；firstPage 为起始页码（每页 LIST_PAGE_SIZE 条）
+func (qc *QuarkClient) walkListPages(pdirFid, basePath, sort, category string, firstPage int, visit func(files []QuarkFileInfo) bool) (listPageMeta, *StandardResponse) {
+	if sort == "" {
+		sort = DEFAULT_LIST_SORT
+	}
+	seenFids := make(map[string]bool)
+	visited := 0
+	page := firstPage
+	pageSize := LIST_PAGE_SIZE // 每页大小
 	hasMore := true
+	total := -1 // 服务端返回的总数，-1 表示未知
+	meta := listPageMeta{lastPage: firstPage}
+	endpoint := FILE_SORT
 
 	// 循环获取所有数据
 	for hasMore {
@@ -2031,555 +2298,1614 @@ func (qc *QuarkClient) listByFid(pdirFid string, parentPath ...string) (*Standar
 		params.Set("_size", fmt.Sprintf("%d", pageSize))
 		params.Set("_fetch_total", "1")
 		params.Set("_fetch_sub_dirs", "0")
-		params.Set("_sort", "file_type:asc,updated_at:desc")
+		params.Set("_sort", sort)
 		params.Set("fetch_all_file", "1")
 		params.Set("fetch_risk_file_name", "1")
-
-		// 构建完整 URL
-		endpoint := FILE_SORT + "?" + params.Encode()
-		respMap, err := qc.makeRequest("GET", endpoint, nil, nil)
-		if err != nil {
-			return &StandardResponse{
-				Success: false,
-				Code:    "LIST_REQUEST_ERROR",
-				Message: fmt.Sprintf("list request failed: %v", err),
-				Data:    nil,
-			}, nil
+		if category != "" {
+			params.Set("category", category)
 		}
 
-		// 检查状态码
-		status, _ := respMap["status"].(float64)
-		code, _ := respMap["code"].(float64)
-		if status >= 400 || code != 0 {
-			message, _ := respMap["message"].(string)
-			return &StandardResponse{
-				Success: false,
-				Code:    "LIST_FAILED",
-				Message: fmt.Sprintf("list files failed: %s (status: %.0f, code: %.0f)", message, status, code),
-				Data:    nil,
-			}, nil
+		// 优先使用 file/sort；失败时用同样的参数回退到旧版列表接口，回退成功后之后的页都使用旧接口
+		respMap, data, listData, failResp := qc.requestListPage(endpoint, params, page)
+		if failResp != nil && endpoint == FILE_SORT {
+			if qc.Debug {
+				fmt.Printf("[调试] %s 列出 pdir_fid %s 失败: %s，改用 %s\n",
+					FILE_SORT, pdirFid, failResp.Message, FILE_LIST_LEGACY)
+			}
+			legacyResp, legacyData, legacyList, legacyFail := qc.requestListPage(FILE_LIST_LEGACY, params, page)
+			// 旧接口也失败时保留 file/sort 的错误信息
+			if legacyFail == nil {
+				endpoint = FILE_LIST_LEGACY
+				respMap, data, listData, failResp = legacyResp, legacyData, legacyList, nil
+			}
 		}
-
-		// 解析响应数据
-		data, ok := respMap["data"].(map[string]interface{})
-		if !ok {
-			return &StandardResponse{
-				Success: false,
-				Code:    "INVALID_RESPONSE_FORMAT",
-				Message: "invalid response format: data field not found",
-				Data:    nil,
-			}, nil
+		if failResp != nil {
+			return listPageMeta{}, failResp
 		}
 
-		listData, ok := data["list"].([]interface{})
-		if !ok {
-			return &StandardResponse{
-				Success: false,
-				Code:    "INVALID_LIST_FORMAT",
-				Message: "invalid list format in response",
-				Data:    nil,
-			}, nil
+		// 优先读取 metadata._total，兼容旧格式 data.total
+		meta.lastPage = page
+		if metadata, ok := respMap["metadata"].(map[string]interface{}); ok {
+			meta.metadata = metadata
+			if t, ok := metadata["_total"].(float64); ok {
+				total = int(t)
+			}
+		}
+		if t, ok := data["total"].(float64); ok && total < 0 {
+			total = int(t)
 		}
 
 		// 如果本次返回的数据为空，说明已经获取了所有数据
 		if len(listData) == 0 {
-			hasMore = false
 			break
 		}
 
-		// 转换文件列表，根据实际API响应精准映射所有字段
+		pageFiles := make([]QuarkFileInfo, 0, len(listData))
 		for _, item := range listData {
-			if itemMap, ok := item.(map[string]interface{}); ok {
-				var fileInfo QuarkFileInfo
-
-				// 映射 fid (文件ID)
-				if fid, ok := itemMap["fid"].(string); ok {
-					fileInfo.Fid = fid
-				}
-
-				// 映射 file_name (文件名)
-				if name, ok := itemMap["file_name"].(string); ok {
-					fileInfo.Name = name
-					// 构建文件路径：根据父目录路径和文件名
-					if basePath == "/" {
-						fileInfo.Path = "/" + name
-					} else if basePath != "" {
-						fileInfo.Path = normalizePath(filepath.Join(basePath, name))
-					} else {
-						fileInfo.Path = "" // 无法确定路径
-					}
-				} else {
-					fileInfo.Path = ""
-				}
-
-				// 映射 size (文件大小，可能是 float64 或 int)
-				if size, ok := itemMap["size"].(float64); ok {
-					fileInfo.Size = int64(size)
-				} else if size, ok := itemMap["size"].(int); ok {
-					fileInfo.Size = int64(size)
-				} else if size, ok := itemMap["size"].(int64); ok {
-					fileInfo.Size = size
-				}
-
-				// 处理创建时间：优先使用 created_at，其次使用 l_created_at（都是毫秒时间戳）
-				if createdAt, ok := itemMap["created_at"].(float64); ok {
-					fileInfo.CreatedAt = int64(createdAt)
-					fileInfo.CreateTime = int64(createdAt) / 1000 // 转换为秒
-				} else if createdAt, ok := itemMap["created_at"].(int64); ok {
-					fileInfo.CreatedAt = createdAt
-					fileInfo.CreateTime = createdAt / 1000
-				} else if lCreatedAt, ok := itemMap["l_created_at"].(float64); ok {
-					fileInfo.LCreatedAt = int64(lCreatedAt)
-					fileInfo.CreateTime = int64(lCreatedAt) / 1000 // 转换为秒
-				} else if lCreatedAt, ok := itemMap["l_created_at"].(int64); ok {
-					fileInfo.LCreatedAt = lCreatedAt
-					fileInfo.CreateTime = lCreatedAt / 1000
-				}
-
-				// 处理修改时间：优先使用 updated_at，其次使用 l_updated_at（都是毫秒时间戳）
-				if updatedAt, ok := itemMap["updated_at"].(float64); ok {
-					fileInfo.UpdatedAt = int64(updatedAt)
-					fileInfo.ModifyTime = int64(updatedAt) / 1000 // 转换为秒
-				} else if updatedAt, ok := itemMap["updated_at"].(int64); ok {
-					fileInfo.UpdatedAt = updatedAt
-					fileInfo.ModifyTime = updatedAt / 1000
-				} else if lUpdatedAt, ok := itemMap["l_updated_at"].(float64); ok {
-					fileInfo.LUpdatedAt = int64(lUpdatedAt)
-					fileInfo.ModifyTime = int64(lUpdatedAt) / 1000 // 转换为秒
-				} else if lUpdatedAt, ok := itemMap["l_updated_at"].(int64); ok {
-					fileInfo.LUpdatedAt = lUpdatedAt
-					fileInfo.ModifyTime = lUpdatedAt / 1000
-				}
-
-				// 处理是否为目录：优先使用 dir 字段，其次使用 file 字段取反
-				if dir, ok := itemMap["dir"].(bool); ok {
-					fileInfo.IsDirectory = dir
-				} else if file, ok := itemMap["file"].(bool); ok {
-					fileInfo.IsDirectory = !file
+			itemMap, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			fileInfo := parseListItem(itemMap, basePath)
+			if fileInfo.PdirFid == "" {
+				fileInfo.PdirFid = pdirFid
+			}
+			// 翻页期间目录内容变化可能导致条目跨页重复，按 fid 去重
+			if fileInfo.Fid != "" {
+				if seenFids[fileInfo.Fid] {
+					continue
 				}
-
-				// download_url 字段在列表API中通常不存在，需要单独获取
-				fileInfo.DownloadURL = ""
-
-				allFileList = append(allFileList, fileInfo)
+				seenFids[fileInfo.Fid] = true
 			}
+			pageFiles = append(pageFiles, fileInfo)
+		}
+		visited += len(pageFiles)
+		if !visit(pageFiles) {
+			break
 		}
 
 		// 检查是否还有更多数据
-		// 如果返回的数据量少于 pageSize，说明已经获取了所有数据
-		if len(listData) < pageSize {
-			hasMore = false
+		// 有 total 时以 total 为准（服务端可能返回少于 pageSize 的非末页）；
+		// 否则返回的数据量少于 pageSize 说明已经获取了所有数据
+		if total >= 0 {
+			hasMore = page*pageSize < total
 		} else {
-			// 检查响应中是否有 total 字段来判断是否还有更多数据
-			if total, ok := data["total"].(float64); ok {
-				currentCount := float64(len(allFileList))
-				hasMore = currentCount < total
-			} else {
-				// 如果没有 total 字段，根据返回的数据量判断
-				// 如果返回的数据量等于 pageSize，可能还有更多数据
-				hasMore = len(listData) == pageSize
-			}
-			page++
+			hasMore = len(listData) == pageSize
 		}
+		page++
 	}
 
-	return &StandardResponse{
-		Success: true,
-		Code:    "OK",
-		Message: "列出目录成功",
-		Data:    map[string]interface{}{"list": allFileList},
-	}, nil
+	if total < 0 {
+		total = visited
+	}
+	meta.total = total
+	return meta, nil
 }
 
-// List 列出目录下的文件
-// dirPath: 目录路径（根目录使用 "/"）
-func (qc *QuarkClient) List(dirPath string) (*StandardResponse, error) {
-	dirPath = normalizePath(dirPath)
-	// 处理目录路径：根目录使用标准表示 "/"
-	var pdirFid string
-	if dirPath == "" || dirPath == "/" {
-		pdirFid = normalizeRootDir(dirPath) // 根目录使用 "/"，转换为 "0"
-	} else if dirPath == "0" {
-		// 兼容旧代码：如果传入 "0"，也转换为根目录
-		pdirFid = "0"
-	} else if strings.HasPrefix(dirPath, "/") {
-		// 是路径字符串，需要转换为 FID
-		dirInfo, err := qc.GetFileInfo(dirPath, true) // 传入 true 跳过路径转换检查
-		if err != nil {
-			return &StandardResponse{
-				Success: false,
-				Code:    "GET_DIRECTORY_INFO_ERROR",
-				Message: fmt.Sprintf("failed to get directory info: %v", err),
-				Data:    nil,
-			}, nil
+// requestListPage 请求列目录的单页数据，遇到 5xx 或瞬时网络错误时按指数退避重试（最多 qc.listRetries 次）
+// 返回原始响应、data 字段和 data.list；失败时返回非 nil 的 StandardResponse，请求最终失败时错误信息包含每次尝试的结果
+func (qc *QuarkClient) requestListPage(endpoint string, params url.Values, page int) (map[string]interface{}, map[string]interface{}, []interface{}, *StandardResponse) {
+	requestURL := endpoint + "?" + params.Encode()
+	var respMap map[string]interface{}
+	var err error
+	var attempts []string
+	for attempt := 0; attempt <= qc.listRetries; attempt++ {
+		respMap, err = qc.makeRequest("GET", requestURL, nil, nil)
+		if err == nil {
+			break
 		}
-		if !dirInfo.Success {
-			return &StandardResponse{
-				Success: false,
-				Code:    dirInfo.Code,
-				Message: fmt.Sprintf("failed to get directory info: %s", dirInfo.Message),
-				Data:    nil,
-			}, nil
+		attempts = append(attempts, fmt.Sprintf("#%d %s", attempt+1, listAttemptResult(err)))
+		if !isRetryableListError(err) || attempt == qc.listRetries {
+			break
 		}
-		// 安全地获取 fid
-		fid, ok := dirInfo.Data["fid"].(string)
-		if !ok || fid == "" {
-			return &StandardResponse{
-				Success: false,
-				Code:    "INVALID_DIRECTORY_INFO",
-				Message: "directory info is invalid: fid not found or empty",
-				Data:    nil,
-			}, nil
+		backoff := time.Duration(1<<uint(attempt)) * listRetryBaseDelay
+		if qc.Debug {
+			fmt.Printf("[调试] 列表第 %d 页请求失败（重试 %d/%d）: %v，%.0f秒后重试\n",
+				page, attempt+1, qc.listRetries, err, backoff.Seconds())
 		}
-		pdirFid = fid
-	} else {
-		// 假设是 FID（不是以 / 开头的字符串）
-		pdirFid = dirPath
+		time.Sleep(backoff)
 	}
-
-	// 确定父目录路径用于构建文件路径
-	var parentPath string
-	if dirPath == "" || dirPath == "/" || dirPath == "0" {
-		parentPath = "/"
-	} else if strings.HasPrefix(dirPath, "/") {
-		parentPath = dirPath
-	} else {
-		// 如果传入的是 FID，无法确定路径
-		parentPath = ""
+	if err != nil {
+		message := fmt.Sprintf("list request failed at page %d: %v", page, err)
+		if len(attempts) > 1 {
+			message = fmt.Sprintf("%s (%d attempts: %s)", message, len(attempts), strings.Join(attempts, ", "))
+		}
+		return nil, nil, nil, &StandardResponse{
+			Success: false,
+			Code:    "LIST_REQUEST_ERROR",
+			Message: message,
+			Data:    nil,
+		}
 	}
 
-	// 使用内部方法通过 FID 列出文件
-	return qc.listByFid(pdirFid, parentPath)
-}
+	// 检查状态码
+	status, _ := respMap["status"].(float64)
+	code, _ := respMap["code"].(float64)
+	if status >= 400 || code != 0 {
+		message, _ := respMap["message"].(string)
+		return nil, nil, nil, &StandardResponse{
+			Success: false,
+			Code:    "LIST_FAILED",
+			Message: fmt.Sprintf("list files failed: %s (status: %.0f, code: %.0f)", message, status, code),
+			Data:    nil,
+		}
+	}
 
-// GetFileInfo 获取文件或目录信息
-func (qc *QuarkClient) GetFileInfo(remotePath string, skipPathConversion ...bool) (*StandardResponse, error) {
-	remotePath = normalizePath(remotePath)
-
-	if remotePath == "/" || remotePath == "" || remotePath == "." {
-		return &StandardResponse{
-			Success: true,
-			Code:    "OK",
-			Message: "根目录",
-			Data: map[string]interface{}{
-				"fid":          "0",
-				"file_name":    "",
-				"path":         "/",
-				"size":         0,
-				"dir":          true,
-				"is_directory": true,
-			},
-		}, nil
+	// 解析响应数据
+	data, ok := respMap["data"].(map[string]interface{})
+	if !ok {
+		return nil, nil, nil, &StandardResponse{
+			Success: false,
+			Code:    "INVALID_RESPONSE_FORMAT",
+			Message: "invalid response format: data field not found",
+			Data:    nil,
+		}
 	}
 
-	fileName := filepath.Base(remotePath)
-	if fileName == "." || fileName == "/" {
-		parts := strings.Split(strings.Trim(remotePath, "/"), "/")
-		if len(parts) > 0 && parts[len(parts)-1] != "" {
-			fileName = parts[len(parts)-1]
-		} else {
-			fileName = ""
+	listData, ok := data["list"].([]interface{})
+	if !ok {
+		return nil, nil, nil, &StandardResponse{
+			Success: false,
+			Code:    "INVALID_LIST_FORMAT",
+			Message: "invalid list format in response",
+			Data:    nil,
 		}
 	}
+	return respMap, data, listData, nil
+}
 
-	parentPath := remotePath
-	lastSlash := strings.LastIndex(parentPath, "/")
-	if lastSlash == 0 {
-		parentPath = "/"
-	} else if lastSlash > 0 {
-		parentPath = parentPath[:lastSlash]
-		if parentPath == "" {
-			parentPath = "/"
+// msField 读取 itemMap 中的毫秒时间戳字段（JSON 解码为 float64，也兼容 int64），不存在时返回 0
+func msField(itemMap map[string]interface{}, key string) int64 {
+	switch v := itemMap[key].(type) {
+	case float64:
+		return int64(v)
+	case int64:
+		return v
+	}
+	return 0
+}
+
+// parseListItem 将列表 API 返回的单个条目转换为 QuarkFileInfo，根据实际API响应精准映射所有字段
+// basePath 为父目录路径，用于构建条目的完整路径；为空表示无法确定路径
+func parseListItem(itemMap map[string]interface{}, basePath string) QuarkFileInfo {
+	var fileInfo QuarkFileInfo
+
+	// 映射 fid (文件ID)
+	if fid, ok := itemMap["fid"].(string); ok {
+		fileInfo.Fid = fid
+	}
+
+	// 映射 pdir_fid (父目录ID)
+	if pdirFid, ok := itemMap["pdir_fid"].(string); ok {
+		fileInfo.PdirFid = pdirFid
+	}
+
+	// 映射 file_name (文件名)
+	if name, ok := itemMap["file_name"].(string); ok {
+		fileInfo.Name = name
+		// 构建文件路径：根据父目录路径和文件名
+		if basePath == "/" {
+			fileInfo.Path = "/" + name
+		} else if basePath != "" {
+			fileInfo.Path = normalizePath(filepath.Join(basePath, name))
+		} else {
+			fileInfo.Path = "" // 无法确定路径
 		}
 	} else {
-		parentPath = "/"
+		fileInfo.Path = ""
+	}
+
+	// 映射 size (文件大小，可能是 float64 或 int)
+	if size, ok := itemMap["size"].(float64); ok {
+		fileInfo.Size = int64(size)
+	} else if size, ok := itemMap["size"].(int); ok {
+		fileInfo.Size = int64(size)
+	} else if size, ok := itemMap["size"].(int64); ok {
+		fileInfo.Size = size
+	}
+
+	// 处理创建/修改时间（都是毫秒时间戳）：优先使用 l_created_at/l_updated_at（上传时记录的本地文件时间），
+	// 其次使用 created_at/updated_at（服务端入库时间），保证列表中的时间与上传前的本地文件一致
+	fileInfo.CreatedAt = msField(itemMap, "created_at")
+	fileInfo.LCreatedAt = msField(itemMap, "l_created_at")
+	fileInfo.UpdatedAt = msField(itemMap, "updated_at")
+	fileInfo.LUpdatedAt = msField(itemMap, "l_updated_at")
+	if fileInfo.LCreatedAt > 0 {
+		fileInfo.CreateTime = fileInfo.LCreatedAt / 1000 // 转换为秒
+	} else {
+		fileInfo.CreateTime = fileInfo.CreatedAt / 1000
 	}
-
-	var parentFid string
-	parentPath = normalizePath(parentPath)
-	if parentPath == "/" || parentPath == "." || parentPath == "" {
-		parentFid = normalizeRootDir(parentPath)
+	if fileInfo.LUpdatedAt > 0 {
+		fileInfo.ModifyTime = fileInfo.LUpdatedAt / 1000 // 转换为秒
 	} else {
-		if parentPath == remotePath {
-			return &StandardResponse{
-				Success: false,
-				Code:    "INVALID_PATH",
-				Message: fmt.Sprintf("invalid path: parent path equals current path: %s", remotePath),
-				Data:    nil,
-			}, nil
-		}
+		fileInfo.ModifyTime = fileInfo.UpdatedAt / 1000
+	}
 
-		parentInfo, err := qc.GetFileInfo(parentPath, true)
-		if err != nil {
-			return &StandardResponse{
-				Success: false,
-				Code:    "GET_PARENT_DIRECTORY_ERROR",
-				Message: fmt.Sprintf("failed to get parent directory: %v", err),
-				Data:    nil,
-			}, nil
-		}
-		if !parentInfo.Success {
-			return &StandardResponse{
-				Success: false,
-				Code:    parentInfo.Code,
-				Message: fmt.Sprintf("failed to get parent directory: %s", parentInfo.Message),
-				Data:    nil,
-			}, nil
-		}
-		fid, ok := parentInfo.Data["fid"].(string)
-		if !ok || fid == "" {
+	// 处理是否为目录：优先使用 dir 字段，其次使用 file 字段取反
+	if dir, ok := itemMap["dir"].(bool); ok {
+		fileInfo.IsDirectory = dir
+	} else if file, ok := itemMap["file"].(bool); ok {
+		fileInfo.IsDirectory = !file
+	}
+
+	// download_url 字段在列表API中通常不存在，需要单独获取
+	fileInfo.DownloadURL = ""
+
+	return fileInfo
+}
+
+// listByFid 通过 FID 列出目录下的文件（内部方法，避免循环调用）
+// 支持分页，自动获取所有文件（见 walkListPages），使用默认排序
+// 返回 Data 包含 list、total（服务端总数）、has_more（是否仍有未取到的条目）、page、size 和服务端原始 metadata
+func (qc *QuarkClient) listByFid(pdirFid string, parentPath ...string) (*StandardResponse, error) {
+	path := ""
+	if len(parentPath) > 0 {
+		path = parentPath[0]
+	}
+	return qc.listByFidWithOptions(pdirFid, path, ListOptions{})
+}
+
+// listByFidWithOptions 按指定选项通过 FID 列出目录下的文件，选项不合法时返回 INVALID_ARGS
+func (qc *QuarkClient) listByFidWithOptions(pdirFid, parentPath string, opts ListOptions) (*StandardResponse, error) {
+	if opts.Page > 0 || opts.Limit > 0 {
+		if err := opts.validate(); err != nil {
 			return &StandardResponse{
 				Success: false,
-				Code:    "INVALID_PARENT_DIRECTORY_INFO",
-				Message: "parent directory info is invalid: fid not found or empty",
+				Code:    "INVALID_ARGS",
+				Message: err.Error(),
 				Data:    nil,
 			}, nil
 		}
-		parentFid = fid
+		sort, _ := opts.sortParam()
+		return qc.listPageByFid(pdirFid, listBasePath(pdirFid, parentPath), sort, opts)
 	}
 
-	var parentPathForList string
-	parentPath = normalizePath(parentPath)
-	if parentPath == "/" || parentPath == "." || parentPath == "" {
-		parentPathForList = "/"
-	} else {
-		parentPathForList = parentPath
-	}
+	// 用于存储所有文件的列表
+	allFileList := make([]QuarkFileInfo, 0)
+	resp, err := qc.listByFidPages(pdirFid, parentPath, opts, func(files []QuarkFileInfo) bool {
+		allFileList = append(allFileList, files...)
+		return true
+	})
+	if err != nil || !resp.Success {
+		return resp, err
+	}
+	delete(resp.Data, "visited")
+	resp.Data["list"] = allFileList
+	return resp, nil
+}
 
-	// 使用 listByFid 列出父目录下的文件（避免循环调用）
-	listResp, err := qc.listByFid(parentFid, parentPathForList)
-	if err != nil {
+// listByFidPages 按页列出 FID 目录下的全部文件，每取到一页调用一次 visit，visit 返回 false 时停止翻页
+// 忽略手动分页选项（Page/Limit）；返回 Data 为汇总信息：visited（回调的条目数）、total、has_more、page、size、metadata
+func (qc *QuarkClient) listByFidPages(pdirFid, parentPath string, opts ListOptions, visit func(files []QuarkFileInfo) bool) (*StandardResponse, error) {
+	opts.Page, opts.Limit = 0, 0
+	if err := opts.validate(); err != nil {
 		return &StandardResponse{
 			Success: false,
-			Code:    "LIST_DIRECTORY_ERROR",
-			Message: fmt.Sprintf("failed to list directory: %v", err),
+			Code:    "INVALID_ARGS",
+			Message: err.Error(),
 			Data:    nil,
 		}, nil
 	}
+	sort, _ := opts.sortParam()
+	category, _ := opts.categoryParam()
 
-	// 检查 List 是否成功
-	if !listResp.Success {
-		return &StandardResponse{
-			Success: false,
-			Code:    listResp.Code,
-			Message: fmt.Sprintf("failed to list directory: %s", listResp.Message),
-			Data:    nil,
-		}, nil
+	visited := 0
+	meta, failResp := qc.walkListPages(pdirFid, listBasePath(pdirFid, parentPath), sort, category, 1, func(files []QuarkFileInfo) bool {
+		visited += len(files)
+		return visit(files)
+	})
+	if failResp != nil {
+		return failResp, nil
 	}
 
-	listData, ok := listResp.Data["list"]
-	if !ok {
-		return &StandardResponse{
-			Success: false,
-			Code:    "INVALID_LIST_DATA",
-			Message: "list data not found in response",
-			Data:    nil,
-		}, nil
-	}
+	// page 为最后拉取的服务端页码，size 为服务端每页条数
+	data := meta.data()
+	data["visited"] = visited
+	data["has_more"] = visited < meta.total
+	return &StandardResponse{
+		Success: true,
+		Code:    "OK",
+		Message: "列出目录成功",
+		Data:    data,
+	}, nil
+}
 
-	fileList, ok := listData.([]QuarkFileInfo)
-	if !ok {
-		if listInterface, ok := listData.([]interface{}); ok {
-			fileList = make([]QuarkFileInfo, 0, len(listInterface))
-			for _, item := range listInterface {
-				if itemMap, ok := item.(map[string]interface{}); ok {
-					var fileInfo QuarkFileInfo
-					if fid, ok := itemMap["fid"].(string); ok {
-						fileInfo.Fid = fid
-					}
-					if name, ok := itemMap["file_name"].(string); ok {
-						fileInfo.Name = name
-						if parentPathForList == "/" {
-							fileInfo.Path = "/" + name
-						} else if parentPathForList != "" {
-							fileInfo.Path = normalizePath(filepath.Join(parentPathForList, name))
-						} else {
-							fileInfo.Path = ""
-						}
-					} else {
-						fileInfo.Path = ""
-					}
-					if size, ok := itemMap["size"].(float64); ok {
-						fileInfo.Size = int64(size)
-					}
-					if createdAt, ok := itemMap["created_at"].(float64); ok {
-						fileInfo.CreateTime = int64(createdAt) / 1000
-					} else if lCreatedAt, ok := itemMap["l_created_at"].(float64); ok {
-						fileInfo.CreateTime = int64(lCreatedAt) / 1000
-					}
-					if updatedAt, ok := itemMap["updated_at"].(float64); ok {
-						fileInfo.ModifyTime = int64(updatedAt) / 1000
-					} else if lUpdatedAt, ok := itemMap["l_updated_at"].(float64); ok {
-						fileInfo.ModifyTime = int64(lUpdatedAt) / 1000
-					}
-					if dir, ok := itemMap["dir"].(bool); ok {
-						fileInfo.IsDirectory = dir
-					} else if file, ok := itemMap["file"].(bool); ok {
-						fileInfo.IsDirectory = !file
-					}
-					fileInfo.DownloadURL = ""
-					fileList = append(fileList, fileInfo)
-				}
-			}
-		} else {
-			return &StandardResponse{
-				Success: false,
-				Code:    "INVALID_LIST_FORMAT",
-				Message: "list data format is invalid",
-				Data:    nil,
-			}, nil
-		}
+// listBasePath 确定列目录时条目路径的父目录路径：提供了 parentPath 时使用它，根目录为 "/"，否则为空（无法确定）
+func listBasePath(pdirFid, parentPath string) string {
+	if parentPath != "" {
+		return parentPath
 	}
+	if pdirFid == "0" {
+		return "/"
+	}
+	return ""
+}
 
-	for _, file := range fileList {
-		if file.Name == fileName {
-			// 找到匹配的文件，构建返回数据
-			fileData := map[string]interface{}{
-				"fid":          file.Fid,
-				"file_name":    file.Name,
-				"path":         file.Path,
-				"size":         file.Size,
-				"dir":          file.IsDirectory,
-				"ctime":        file.CreateTime,
-				"mtime":        file.ModifyTime,
-				"download_url": file.DownloadURL,
+// listPageByFid 手动分页列出目录：返回第 opts.Page 页（每页 opts.Limit 条）
+// 服务端单页条数有上限，按 LIST_PAGE_SIZE 拉取覆盖该区间的服务端页后截取；页码超出范围时返回空列表
+func (qc *QuarkClient) listPageByFid(pdirFid, basePath, sort string, opts ListOptions) (*StandardResponse, error) {
+	page, limit := opts.Page, opts.Limit
+	if page <= 0 {
+		page = 1
+	}
+	if limit <= 0 {
+		limit = LIST_PAGE_SIZE
+	}
+	start := (page - 1) * limit
+	end := start + limit
+
+	firstPage := start/LIST_PAGE_SIZE + 1
+	offset := (firstPage - 1) * LIST_PAGE_SIZE // 当前已遍历条目在目录中的起始位置
+	fileList := make([]QuarkFileInfo, 0, limit)
+	category, _ := opts.categoryParam()
+	meta, failResp := qc.walkListPages(pdirFid, basePath, sort, category, firstPage, func(files []QuarkFileInfo) bool {
+		for _, file := range files {
+			if offset >= start && offset < end {
+				fileList = append(fileList, file)
 			}
-
-			return &StandardResponse{
-				Success: true,
-				Code:    "OK",
-				Message: "获取文件信息成功",
-				Data:    fileData,
-			}, nil
+			offset++
 		}
-	}
-
-	// 文件未找到
+		return offset < end
+	})
+	if failResp != nil {
+		return failResp, nil
+	}
+
+	// 手动分页时 page/size 为调用方请求的页码和每页条数
+	data := meta.data()
+	data["list"] = fileList
+	data["page"] = page
+	data["size"] = limit
+	data["limit"] = limit
+	data["has_more"] = end < meta.total
 	return &StandardResponse{
-		Success: false,
-		Code:    "FILE_NOT_FOUND",
-		Message: fmt.Sprintf("file not found: %s", remotePath),
-		Data:    nil,
+		Success: true,
+		Code:    "OK",
+		Message: "列出目录成功",
+		Data:    data,
 	}, nil
 }
 
-// Delete 删除文件或目录
-func (qc *QuarkClient) Delete(remotePath string) (*StandardResponse, error) {
-	remotePath = normalizePath(remotePath)
-
-	// 获取文件信息以获取文件 ID
-	fileInfo, err := qc.GetFileInfo(remotePath)
-	if err != nil {
+// ListPages 按页列出目录下的全部文件，每从服务端取到一页就调用一次 visit，适合大目录边拉取边输出
+// dirPath: 目录路径（根目录使用 "/"），不以 "/" 开头时按 FID 处理（条目 path 为空）
+// visit 返回 false 时停止翻页；opts 的排序和类别过滤生效，手动分页（Page/Limit）被忽略
+// 返回 Data 为汇总信息：visited（回调的条目数）、total（服务端总数）、has_more、page、size、metadata
+func (qc *QuarkClient) ListPages(dirPath string, opts ListOptions, visit func(files []QuarkFileInfo) bool) (*StandardResponse, error) {
+	opts.Page, opts.Limit = 0, 0
+	if err := opts.validate(); err != nil {
 		return &StandardResponse{
 			Success: false,
-			Code:    "GET_FILE_INFO_ERROR",
-			Message: fmt.Sprintf("failed to get file info: %v", err),
+			Code:    "INVALID_ARGS",
+			Message: err.Error(),
 			Data:    nil,
 		}, nil
 	}
 
-	// 检查 GetFileInfo 是否成功
-	if !fileInfo.Success {
+	pdirFid, parentPath, failResp := qc.resolveDirFid(dirPath)
+	if failResp != nil {
+		return failResp, nil
+	}
+	return qc.listByFidPages(pdirFid, parentPath, opts, visit)
+}
+
+// ListByFidPages 按页列出 FID 目录下的全部文件，与 ListPages 相同但不做路径解析
+// dirPath 为该目录的路径，用于构建条目的 path，未知时传空（条目 path 为空，根目录 "0" 除外）
+func (qc *QuarkClient) ListByFidPages(fid, dirPath string, opts ListOptions, visit func(files []QuarkFileInfo) bool) (*StandardResponse, error) {
+	if fid == "" {
 		return &StandardResponse{
 			Success: false,
-			Code:    fileInfo.Code,
-			Message: fmt.Sprintf("failed to get file info: %s", fileInfo.Message),
+			Code:    "INVALID_FID",
+			Message: "fid cannot be empty",
 			Data:    nil,
 		}, nil
 	}
+	return qc.listByFidPages(fid, dirPath, opts, visit)
+}
 
-	// 安全地获取 fid
-	fileFid, ok := fileInfo.Data["fid"].(string)
-	if !ok || fileFid == "" {
+// ListByFid 直接通过目录 FID 列出文件，不做路径解析
+// 返回条目的 path 为空（无法确定完整路径，根目录 "0" 除外），可通过 pdir_fid/fid 继续向下列目录
+func (qc *QuarkClient) ListByFid(fid string, opts ...ListOptions) (*StandardResponse, error) {
+	if fid == "" {
 		return &StandardResponse{
 			Success: false,
-			Code:    "INVALID_FILE_INFO",
-			Message: "file info is invalid: fid not found or empty",
+			Code:    "INVALID_FID",
+			Message: "fid cannot be empty",
 			Data:    nil,
 		}, nil
 	}
-
-	deleteData := map[string]interface{}{
-		"action_type":  1,
-		"exclude_fids": []string{},
-		"filelist":     []string{fileFid},
+	var options ListOptions
+	if len(opts) > 0 {
+		options = opts[0]
 	}
+	return qc.listByFidWithOptions(fid, "", options)
+}
 
-	jsonData, err := json.Marshal(deleteData)
-	if err != nil {
+// List 列出目录下的文件
+// dirPath: 目录路径（根目录使用 "/"）
+// opts: 可选的列目录选项（排序、手动分页、类别过滤），不合法时返回 INVALID_ARGS
+func (qc *QuarkClient) List(dirPath string, opts ...ListOptions) (*StandardResponse, error) {
+	var options ListOptions
+	if len(opts) > 0 {
+		options = opts[0]
+	}
+	// 先校验选项，避免无效参数时还去解析路径
+	if err := options.validate(); err != nil {
 		return &StandardResponse{
 			Success: false,
-			Code:    "MARSHAL_DELETE_DATA_ERROR",
-			Message: fmt.Sprintf("failed to marshal delete data: %v", err),
+			Code:    "INVALID_ARGS",
+			Message: err.Error(),
 			Data:    nil,
 		}, nil
 	}
 
-	respMap, err := qc.makeRequest("POST", FILE_DELETE, bytes.NewBuffer(jsonData), nil)
-	if err != nil {
+	pdirFid, parentPath, failResp := qc.resolveDirFid(dirPath)
+	if failResp != nil {
+		return failResp, nil
+	}
+
+	// 使用内部方法通过 FID 列出文件
+	return qc.listByFidWithOptions(pdirFid, parentPath, options)
+}
+
+// Search 按文件名关键字全盘搜索（服务端搜索接口，无需递归列目录）
+// page: 页码，<= 0 时为 1；size: 每页条数，<= 0 时为 LIST_PAGE_SIZE，超过 LIST_PAGE_SIZE 时取 LIST_PAGE_SIZE
+// 返回 Data 包含 list（条目 path 为空，pdir_fid 为所在目录）、total、page、size、has_more；无匹配时 list 为空
+func (qc *QuarkClient) Search(keyword string, page, size int) (*StandardResponse, error) {
+	keyword = strings.TrimSpace(keyword)
+	if keyword == "" {
 		return &StandardResponse{
 			Success: false,
-			Code:    "DELETE_REQUEST_ERROR",
-			Message: fmt.Sprintf("delete request failed: %v", err),
+			Code:    "INVALID_ARGS",
+			Message: "search keyword cannot be empty",
 			Data:    nil,
 		}, nil
 	}
-
-	var deleteResp struct {
-		Status  int                    `json:"status"`
-		Code    int                    `json:"code"`
-		Message string                 `json:"message"`
-		Data    map[string]interface{} `json:"data"`
+	if page <= 0 {
+		page = 1
+	}
+	if size <= 0 || size > LIST_PAGE_SIZE {
+		size = LIST_PAGE_SIZE
 	}
 
-	if err := qc.parseResponse(respMap, &deleteResp); err != nil {
+	// url.Values 负责对关键字做 URL 编码
+	params := url.Values{}
+	params.Set("uc_param_str", "")
+	params.Set("q", keyword)
+	params.Set("_page", fmt.Sprintf("%d", page))
+	params.Set("_size", fmt.Sprintf("%d", size))
+	params.Set("_fetch_total", "1")
+	params.Set("_sort", DEFAULT_SEARCH_SORT)
+	params.Set("_is_hl", "0")
+	respMap, err := qc.makeRequest("GET", FILE_SEARCH+"?"+params.Encode(), nil, nil)
+	if err != nil {
 		return &StandardResponse{
 			Success: false,
-			Code:    "DECODE_DELETE_RESPONSE_ERROR",
-			Message: fmt.Sprintf("failed to decode delete response: %v", err),
+			Code:    "SEARCH_REQUEST_ERROR",
+			Message: fmt.Sprintf("search request failed: %v", err),
 			Data:    nil,
 		}, nil
 	}
 
-	if deleteResp.Status >= 400 || deleteResp.Code != 0 {
+	status, _ := respMap["status"].(float64)
+	code, _ := respMap["code"].(float64)
+	if status >= 400 || code != 0 {
+		message, _ := respMap["message"].(string)
 		return &StandardResponse{
 			Success: false,
-			Code:    "DELETE_FAILED",
-			Message: fmt.Sprintf("delete failed: %s (status: %d, code: %d)", deleteResp.Message, deleteResp.Status, deleteResp.Code),
+			Code:    "SEARCH_FAILED",
+			Message: fmt.Sprintf("search failed: %s (status: %.0f, code: %.0f)", message, status, code),
 			Data:    nil,
 		}, nil
 	}
 
+	// 无匹配时服务端可能不返回 list 字段，按空结果处理
+	fileList := make([]QuarkFileInfo, 0)
+	if data, ok := respMap["data"].(map[string]interface{}); ok {
+		items, _ := data["list"].([]interface{})
+		for _, item := range items {
+			itemMap, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			fileList = append(fileList, parseListItem(itemMap, ""))
+		}
+	}
+	total := -1
+	if metadata, ok := respMap["metadata"].(map[string]interface{}); ok {
+		if t, ok := metadata["_total"].(float64); ok {
+			total = int(t)
+		}
+	}
+	if total < 0 {
+		total = (page-1)*size + len(fileList)
+	}
+
 	return &StandardResponse{
 		Success: true,
 		Code:    "OK",
-		Message: "删除成功",
-		Data:    map[string]interface{}{"fid": deleteResp.Data["fid"]},
+		Message: "搜索成功",
+		Data: map[string]interface{}{
+			"keyword":  keyword,
+			"list":     fileList,
+			"total":    total,
+			"page":     page,
+			"size":     size,
+			"has_more": page*size < total,
+		},
 	}, nil
 }
 
-// BuildHeaders 实现 RequestHeaderBuilder 接口（OSSPartUploadHeaderBuilder）
-func (b *OSSPartUploadHeaderBuilder) BuildHeaders(req *http.Request, qc *QuarkClient) error {
-	req.Header.Set("Authorization", b.AuthKey)
-	req.Header.Set("Content-Type", b.MimeType)
-	req.Header.Set("x-oss-date", b.Timestamp)
-	req.Header.Set("x-oss-user-agent", "aliyun-sdk-js/1.0.0 Chrome 145.0.0.0 on Windows 10 64-bit")
-
-	// 如果存在 HashCtx，设置 X-Oss-Hash-Ctx header
-	if b.HashCtx != nil {
-		hashCtxStr, err := encodeHashCtx(b.HashCtx)
-		if err == nil {
-			req.Header.Set("X-Oss-Hash-Ctx", hashCtxStr)
+// resolveDirFid 将目录路径解析为 FID，同时返回用于构建条目路径的父目录路径
+// 根目录（""、"/"、"0"）返回 "0"；以 "/" 开头的按路径解析；其他字符串视为 FID，此时父目录路径为空（无法确定）
+func (qc *QuarkClient) resolveDirFid(dirPath string) (string, string, *StandardResponse) {
+	dirPath = normalizePath(dirPath)
+	// 处理目录路径：根目录使用标准表示 "/"
+	var pdirFid string
+	if dirPath == "" || dirPath == "/" {
+		pdirFid = normalizeRootDir(dirPath) // 根目录使用 "/"，转换为 "0"
+	} else if dirPath == "0" {
+		// 兼容旧代码：如果传入 "0"，也转换为根目录
+		pdirFid = "0"
+	} else if strings.HasPrefix(dirPath, "/") {
+		// 是路径字符串，需要转换为 FID
+		dirInfo, err := qc.GetFileInfo(dirPath, true) // 传入 true 跳过路径转换检查
+		if err != nil {
+			return "", "", &StandardResponse{
+				Success: false,
+				Code:    "GET_DIRECTORY_INFO_ERROR",
+				Message: fmt.Sprintf("failed to get directory info: %v", err),
+				Data:    nil,
+			}
 		}
-	}
-
-	return nil
-}
-
-// BuildHeaders 实现 RequestHeaderBuilder 接口（OSSCommitHeaderBuilder）
-func (b *OSSCommitHeaderBuilder) BuildHeaders(req *http.Request, qc *QuarkClient) error {
-	req.Header.Set("Authorization", b.AuthKey)
-	req.Header.Set("Content-MD5", b.ContentMD5)
+		if !dirInfo.Success {
+			return "", "", &StandardResponse{
+				Success: false,
+				Code:    dirInfo.Code,
+				Message: fmt.Sprintf("failed to get directory info: %s", dirInfo.Message),
+				Data:    nil,
+			}
+		}
+		// 安全地获取 fid
+		fid, ok := dirInfo.Data["fid"].(string)
+		if !ok || fid == "" {
+			return "", "", &StandardResponse{
+				Success: false,
+				Code:    "INVALID_DIRECTORY_INFO",
+				Message: "directory info is invalid: fid not found or empty",
+				Data:    nil,
+			}
+		}
+		pdirFid = fid
+	} else {
+		// 假设是 FID（不是以 / 开头的字符串）
+		pdirFid = dirPath
+	}
+
+	// 确定父目录路径用于构建文件路径
+	var parentPath string
+	if dirPath == "" || dirPath == "/" || dirPath == "0" {
+		parentPath = "/"
+	} else if strings.HasPrefix(dirPath, "/") {
+		parentPath = dirPath
+	} else {
+		// 如果传入的是 FID，无法确定路径
+		parentPath = ""
+	}
+	return pdirFid, parentPath, nil
+}
+
+// listTreeNode 递归列目录时的目录节点
+type listTreeNode struct {
+	fid       string
+	path      string
+	depth     int                      // 目录深度，起始目录为 0
+	files     []QuarkFileInfo          // 目录下的直接子条目（保持服务端排序）
+	children  map[string]*listTreeNode // 已展开的子目录，key 为 fid
+	truncated bool                     // 因超过最大深度未展开
+	err       *StandardResponse        // 列目录失败时的错误
+}
+
+// walkListTree 从指定目录开始并发递归列出子树
+// maxDepth <= 0 表示不限深度；maxDepth = 1 只列起始目录的直接子条目
+// 同时最多 RECURSIVE_LIST_CONCURRENCY 个目录在拉取；某个子目录失败时记录在节点上并继续其余目录
+func (qc *QuarkClient) walkListTree(rootFid, rootPath string, maxDepth int, opts ListOptions) *listTreeNode {
+	root := &listTreeNode{fid: rootFid, path: rootPath}
+	sem := make(chan struct{}, RECURSIVE_LIST_CONCURRENCY)
+	var wg sync.WaitGroup
+
+	var visit func(node *listTreeNode)
+	visit = func(node *listTreeNode) {
+		defer wg.Done()
+
+		sem <- struct{}{}
+		resp, err := qc.listByFidWithOptions(node.fid, node.path, opts)
+		<-sem
+
+		if err != nil {
+			node.err = &StandardResponse{Success: false, Code: "LIST_REQUEST_ERROR", Message: err.Error()}
+			return
+		}
+		if !resp.Success {
+			node.err = resp
+			return
+		}
+		node.files, _ = resp.Data["list"].([]QuarkFileInfo)
+		node.children = make(map[string]*listTreeNode)
+		for _, file := range node.files {
+			if !file.IsDirectory {
+				continue
+			}
+			child := &listTreeNode{fid: file.Fid, path: file.Path, depth: node.depth + 1}
+			node.children[file.Fid] = child
+			if maxDepth > 0 && child.depth >= maxDepth {
+				child.truncated = true
+				continue
+			}
+			wg.Add(1)
+			go visit(child)
+		}
+	}
+
+	wg.Add(1)
+	visit(root)
+	wg.Wait()
+	return root
+}
+
+// ListTree 递归列出目录子树并以嵌套结构返回
+// dirPath: 起始目录路径（根目录使用 "/"）
+// maxDepth: 最大深度，<= 0 表示不限深度；超过深度的目录标记 truncated 且不展开
+// 返回 Data 包含 tree（根节点）、total（子树中的条目数）、failed（列出失败的目录数）
+// 子目录失败时在对应节点的 error 中说明并继续其余目录，结果 Code 为 PARTIAL_SUCCESS
+func (qc *QuarkClient) ListTree(dirPath string, maxDepth int) (*StandardResponse, error) {
+	rootFid, rootPath, failResp := qc.resolveDirFid(dirPath)
+	if failResp != nil {
+		return failResp, nil
+	}
+
+	root := qc.walkListTree(rootFid, rootPath, maxDepth, ListOptions{})
+	if root.err != nil {
+		return root.err, nil
+	}
+
+	total, failed := 0, 0
+	var build func(node *listTreeNode, treeNode *TreeNode)
+	build = func(node *listTreeNode, treeNode *TreeNode) {
+		treeNode.Children = make([]*TreeNode, 0, len(node.files))
+		for _, file := range node.files {
+			total++
+			child := &TreeNode{
+				Fid:         file.Fid,
+				Name:        file.Name,
+				Path:        file.Path,
+				Size:        file.Size,
+				IsDirectory: file.IsDirectory,
+				ModifyTime:  file.ModifyTime,
+			}
+			treeNode.Children = append(treeNode.Children, child)
+			sub, ok := node.children[file.Fid]
+			if !ok {
+				continue
+			}
+			switch {
+			case sub.truncated:
+				child.Truncated = true
+			case sub.err != nil:
+				child.Error = sub.err.Message
+				failed++
+			default:
+				build(sub, child)
+			}
+		}
+	}
+
+	rootName := ""
+	if rootPath != "" && rootPath != "/" {
+		rootName = filepath.Base(rootPath)
+	}
+	tree := &TreeNode{Fid: rootFid, Name: rootName, Path: rootPath, IsDirectory: true}
+	build(root, tree)
+
+	code, message := "OK", "列出目录树成功"
+	if failed > 0 {
+		code = "PARTIAL_SUCCESS"
+		message = fmt.Sprintf("列出目录树完成，%d 个子目录失败", failed)
+	}
+	return &StandardResponse{
+		Success: true,
+		Code:    code,
+		Message: message,
+		Data: map[string]interface{}{
+			"tree":      tree,
+			"total":     total,
+			"failed":    failed,
+			"max_depth": maxDepth,
+		},
+	}, nil
+}
+
+// DiskUsage 递归统计目录占用的空间（目录条目自身的 size 为 0，需要累加子树中的文件）
+// dirPath: 起始目录路径（根目录使用 "/"）
+// groupDepth: 1 表示额外按一级子目录分组统计，<= 0 只统计总数
+// 返回 Data 包含 fid、path、size、files、dirs、failed（列目录失败的子目录及原因），分组时包含 children
+// 子目录失败不影响其余目录的统计，结果 Code 为 PARTIAL_SUCCESS，失败目录下的内容不计入
+func (qc *QuarkClient) DiskUsage(dirPath string, groupDepth int) (*StandardResponse, error) {
+	if groupDepth > 1 {
+		return &StandardResponse{
+			Success: false,
+			Code:    "INVALID_ARGS",
+			Message: "group depth must be 0 or 1",
+			Data:    nil,
+		}, nil
+	}
+
+	rootFid, rootPath, failResp := qc.resolveDirFid(dirPath)
+	if failResp != nil {
+		return failResp, nil
+	}
+
+	root := qc.walkListTree(rootFid, rootPath, 0, ListOptions{})
+	if root.err != nil {
+		return root.err, nil
+	}
+
+	failed := make([]map[string]interface{}, 0)
+	recordFailed := func(node *listTreeNode) {
+		failed = append(failed, map[string]interface{}{
+			"fid":     node.fid,
+			"path":    node.path,
+			"code":    node.err.Code,
+			"message": node.err.Message,
+		})
+	}
+	var sum func(node *listTreeNode, usage *DiskUsage)
+	sum = func(node *listTreeNode, usage *DiskUsage) {
+		for _, file := range node.files {
+			if !file.IsDirectory {
+				usage.Files++
+				usage.Size += file.Size
+				continue
+			}
+			usage.Dirs++
+			child, ok := node.children[file.Fid]
+			if !ok {
+				continue
+			}
+			if child.err != nil {
+				recordFailed(child)
+				continue
+			}
+			sum(child, usage)
+		}
+	}
+
+	total := DiskUsage{Fid: rootFid, Path: rootPath}
+	var groups []DiskUsage
+	if groupDepth == 1 {
+		// 一级子目录各自统计，再合并到总数
+		groups = make([]DiskUsage, 0)
+		for _, file := range root.files {
+			if !file.IsDirectory {
+				total.Files++
+				total.Size += file.Size
+				continue
+			}
+			total.Dirs++
+			group := DiskUsage{Fid: file.Fid, Path: file.Path}
+			child := root.children[file.Fid]
+			if child.err != nil {
+				recordFailed(child)
+			} else {
+				sum(child, &group)
+			}
+			total.Size += group.Size
+			total.Files += group.Files
+			total.Dirs += group.Dirs
+			groups = append(groups, group)
+		}
+	} else {
+		sum(root, &total)
+	}
+
+	code, message := "OK", "统计目录占用成功"
+	if len(failed) > 0 {
+		code = "PARTIAL_SUCCESS"
+		message = fmt.Sprintf("统计目录占用完成，%d 个子目录失败", len(failed))
+	}
+	data := map[string]interface{}{
+		"fid":    total.Fid,
+		"path":   total.Path,
+		"size":   total.Size,
+		"files":  total.Files,
+		"dirs":   total.Dirs,
+		"failed": failed,
+	}
+	if groups != nil {
+		data["children"] = groups
+	}
+	return &StandardResponse{
+		Success: true,
+		Code:    code,
+		Message: message,
+		Data:    data,
+	}, nil
+}
+
+// ListRecursive 递归列出目录下的所有文件和子目录
+// dirPath: 起始目录路径（根目录使用 "/"）
+// maxDepth: 最大深度，<= 0 表示不限深度，1 表示只列直接子条目
+// 返回 Data 包含 list（先序遍历顺序，每个条目带完整 path）、total、failed（列目录失败的子目录及原因）
+// 起始目录失败时返回失败；子目录失败不影响其余目录，结果 Code 为 PARTIAL_SUCCESS
+// opts: 可选的列目录选项，排序对每一级目录生效；递归时忽略手动分页（Page/Limit），不支持类别过滤
+func (qc *QuarkClient) ListRecursive(dirPath string, maxDepth int, opts ...ListOptions) (*StandardResponse, error) {
+	var options ListOptions
+	if len(opts) > 0 {
+		options = opts[0]
+	}
+	options.Page, options.Limit = 0, 0
+	if options.Category != "" {
+		// 类别过滤由服务端完成，会把子目录一并过滤掉，无法继续向下递归
+		return &StandardResponse{
+			Success: false,
+			Code:    "INVALID_ARGS",
+			Message: "category filter cannot be used with recursive listing",
+			Data:    nil,
+		}, nil
+	}
+	if _, err := options.sortParam(); err != nil {
+		return &StandardResponse{
+			Success: false,
+			Code:    "INVALID_ARGS",
+			Message: err.Error(),
+			Data:    nil,
+		}, nil
+	}
+
+	rootFid, rootPath, failResp := qc.resolveDirFid(dirPath)
+	if failResp != nil {
+		return failResp, nil
+	}
+
+	root := qc.walkListTree(rootFid, rootPath, maxDepth, options)
+	if root.err != nil {
+		return root.err, nil
+	}
+
+	allFileList := make([]QuarkFileInfo, 0)
+	failed := make([]map[string]interface{}, 0)
+	var flatten func(node *listTreeNode)
+	flatten = func(node *listTreeNode) {
+		for _, file := range node.files {
+			allFileList = append(allFileList, file)
+			child, ok := node.children[file.Fid]
+			if !ok {
+				continue
+			}
+			if child.err != nil {
+				failed = append(failed, map[string]interface{}{
+					"fid":     child.fid,
+					"path":    child.path,
+					"code":    child.err.Code,
+					"message": child.err.Message,
+				})
+				continue
+			}
+			flatten(child)
+		}
+	}
+	flatten(root)
+
+	code, message := "OK", "递归列出目录成功"
+	if len(failed) > 0 {
+		code = "PARTIAL_SUCCESS"
+		message = fmt.Sprintf("递归列出目录完成，%d 个子目录失败", len(failed))
+	}
+	return &StandardResponse{
+		Success: true,
+		Code:    code,
+		Message: message,
+		Data: map[string]interface{}{
+			"list":      allFileList,
+			"total":     len(allFileList),
+			"failed":    failed,
+			"max_depth": maxDepth,
+		},
+	}, nil
+}
+
+// SkipDir WalkFunc 对目录返回 SkipDir 时跳过该目录的子树，遍历继续
+var SkipDir = errors.New("skip this directory")
+
+// WalkFunc Walk 对每个条目调用的回调，返回 SkipDir 跳过目录子树，返回其他错误时停止遍历
+type WalkFunc func(file QuarkFileInfo) error
+
+// Walk 递归遍历目录子树，按先序（与 ListRecursive 的 list 顺序一致）对每个条目调用 fn
+// dirPath: 起始目录路径（根目录使用 "/"）；maxDepth: 最大深度，<= 0 表示不限深度
+// 子树由 walkListTree 并发拉取；返回 Data 包含 visited（回调的条目数）、failed（列目录失败的子目录及原因）
+// fn 返回 SkipDir 以外的错误时停止遍历并原样返回该错误
+func (qc *QuarkClient) Walk(dirPath string, maxDepth int, fn WalkFunc) (*StandardResponse, error) {
+	rootFid, rootPath, failResp := qc.resolveDirFid(dirPath)
+	if failResp != nil {
+		return failResp, nil
+	}
+
+	root := qc.walkListTree(rootFid, rootPath, maxDepth, ListOptions{})
+	if root.err != nil {
+		return root.err, nil
+	}
+
+	visited := 0
+	failed := make([]map[string]interface{}, 0)
+	var visit func(node *listTreeNode) error
+	visit = func(node *listTreeNode) error {
+		for _, file := range node.files {
+			visited++
+			if err := fn(file); err == SkipDir {
+				if !file.IsDirectory {
+					// 对文件返回 SkipDir 表示跳过所在目录的剩余条目
+					return nil
+				}
+				continue
+			} else if err != nil {
+				return err
+			}
+			child, ok := node.children[file.Fid]
+			if !ok {
+				continue
+			}
+			if child.err != nil {
+				failed = append(failed, map[string]interface{}{
+					"fid":     child.fid,
+					"path":    child.path,
+					"code":    child.err.Code,
+					"message": child.err.Message,
+				})
+				continue
+			}
+			if err := visit(child); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := visit(root); err != nil {
+		return nil, err
+	}
+
+	code, message := "OK", "遍历目录成功"
+	if len(failed) > 0 {
+		code = "PARTIAL_SUCCESS"
+		message = fmt.Sprintf("遍历目录完成，%d 个子目录失败", len(failed))
+	}
+	return &StandardResponse{
+		Success: true,
+		Code:    code,
+		Message: message,
+		Data: map[string]interface{}{
+			"visited":   visited,
+			"failed":    failed,
+			"max_depth": maxDepth,
+		},
+	}, nil
+}
+
+// Recent 列出目录子树中最近修改的 limit 个文件（不含目录），按修改时间倒序
+// 列表接口没有全局最近文件入口，这里基于 Walk 遍历子树后取修改时间最新的条目；条目带完整 path 和 pdir_fid
+// dirPath: 起始目录路径（根目录使用 "/"）；limit <= 0 时为 DEFAULT_RECENT_LIMIT
+// 返回 Data 包含 list、total（list 条数）、scanned（遍历的条目数）、failed（列目录失败的子目录及原因）
+func (qc *QuarkClient) Recent(dirPath string, limit int) (*StandardResponse, error) {
+	if limit <= 0 {
+		limit = DEFAULT_RECENT_LIMIT
+	}
+	files := make([]QuarkFileInfo, 0)
+	resp, err := qc.Walk(dirPath, 0, func(file QuarkFileInfo) error {
+		if !file.IsDirectory {
+			files = append(files, file)
+		}
+		return nil
+	})
+	if err != nil || !resp.Success {
+		return resp, err
+	}
+
+	sort.SliceStable(files, func(i, j int) bool {
+		return recentTime(files[i]) > recentTime(files[j])
+	})
+	if len(files) > limit {
+		files = files[:limit]
+	}
+
+	return &StandardResponse{
+		Success: true,
+		Code:    resp.Code,
+		Message: "列出最近修改的文件成功",
+		Data: map[string]interface{}{
+			"list":    files,
+			"total":   len(files),
+			"scanned": resp.Data["visited"],
+			"failed":  resp.Data["failed"],
+		},
+	}, nil
+}
+
+// recentTime 返回用于最近文件排序的毫秒时间戳（优先 updated_at，其次 l_updated_at，最后 mtime）
+func recentTime(file QuarkFileInfo) int64 {
+	switch {
+	case file.UpdatedAt > 0:
+		return file.UpdatedAt
+	case file.LUpdatedAt > 0:
+		return file.LUpdatedAt
+	default:
+		return file.ModifyTime * 1000
+	}
+}
+
+// GetFileInfoByFid 按 fid 查询文件或目录详情（单次请求，不需要逐级解析路径）
+// fid 不存在时返回 Code FILE_NOT_FOUND；返回 Data 与 GetFileInfo 字段一致，额外包含 pdir_fid
+func (qc *QuarkClient) GetFileInfoByFid(fid string) (*StandardResponse, error) {
+	if fid == "" {
+		return &StandardResponse{
+			Success: false,
+			Code:    "INVALID_FID",
+			Message: "fid cannot be empty",
+			Data:    nil,
+		}, nil
+	}
+	if fid == "0" {
+		return rootFileInfo(), nil
+	}
+
+	params := url.Values{}
+	params.Set("fid", fid)
+	params.Set("_fetch_full_path", "1")
+	respMap, err := qc.makeRequest("GET", FILE_INFO+"?"+params.Encode(), nil, nil)
+	if err != nil {
+		// 服务端对不存在的 fid 返回 4xx，makeRequest 会转为错误
+		if strings.Contains(err.Error(), "not found") || strings.Contains(err.Error(), "不存在") {
+			return &StandardResponse{
+				Success: false,
+				Code:    "FILE_NOT_FOUND",
+				Message: fmt.Sprintf("file not found: %s", fid),
+				Data:    nil,
+			}, nil
+		}
+		return &StandardResponse{
+			Success: false,
+			Code:    "GET_FILE_INFO_ERROR",
+			Message: fmt.Sprintf("get file info request failed: %v", err),
+			Data:    nil,
+		}, nil
+	}
+
+	status, _ := respMap["status"].(float64)
+	code, _ := respMap["code"].(float64)
+	data, _ := respMap["data"].(map[string]interface{})
+	if status == 404 || ((status < 400 && code == 0) && (data == nil || data["fid"] == nil)) {
+		return &StandardResponse{
+			Success: false,
+			Code:    "FILE_NOT_FOUND",
+			Message: fmt.Sprintf("file not found: %s", fid),
+			Data:    nil,
+		}, nil
+	}
+	if status >= 400 || code != 0 {
+		message, _ := respMap["message"].(string)
+		return &StandardResponse{
+			Success: false,
+			Code:    "GET_FILE_INFO_FAILED",
+			Message: fmt.Sprintf("get file info failed: %s (status: %.0f, code: %.0f)", message, status, code),
+			Data:    nil,
+		}, nil
+	}
+
+	// 详情接口返回 full_path 时使用完整路径，否则 path 留空
+	fileInfo := parseListItem(data, "")
+	if fullPath, ok := data["full_path"].(string); ok && fullPath != "" {
+		fileInfo.Path = normalizePath(fullPath)
+	}
+	fileData := fileInfoData(fileInfo)
+	if pdirFid, ok := data["pdir_fid"].(string); ok {
+		fileData["pdir_fid"] = pdirFid
+	}
+	return &StandardResponse{
+		Success: true,
+		Code:    "OK",
+		Message: "获取文件信息成功",
+		Data:    fileData,
+	}, nil
+}
+
+// resolvePathFids 通过 path_list 接口一次性把完整路径解析为 fid，不存在的路径不会出现在返回结果中
+func (qc *QuarkClient) resolvePathFids(paths []string) (map[string]string, error) {
+	jsonData, err := json.Marshal(map[string]interface{}{
+		"file_path": paths,
+		"namespace": "0",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request data: %w", err)
+	}
+
+	respMap, err := qc.makeRequest("POST", FILE_INFO_PATH_LIST, bytes.NewBuffer(jsonData), nil)
+	if err != nil {
+		return nil, err
+	}
+	status, _ := respMap["status"].(float64)
+	code, _ := respMap["code"].(float64)
+	if status >= 400 || code != 0 {
+		message, _ := respMap["message"].(string)
+		return nil, fmt.Errorf("resolve path failed: %s (status: %.0f, code: %.0f)", message, status, code)
+	}
+	list, ok := respMap["data"].([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid path_list response format")
+	}
+
+	fids := make(map[string]string, len(list))
+	for _, item := range list {
+		itemMap, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		filePath, _ := itemMap["file_path"].(string)
+		fid, _ := itemMap["fid"].(string)
+		if filePath != "" && fid != "" {
+			fids[normalizePath(filePath)] = fid
+		}
+	}
+	return fids, nil
+}
+
+// rootFileInfo 根目录的文件信息
+func rootFileInfo() *StandardResponse {
+	return &StandardResponse{
+		Success: true,
+		Code:    "OK",
+		Message: "根目录",
+		Data: map[string]interface{}{
+			"fid":          "0",
+			"file_name":    "",
+			"path":         "/",
+			"size":         0,
+			"dir":          true,
+			"is_directory": true,
+		},
+	}
+}
+
+// fileInfoData 将 QuarkFileInfo 转换为 GetFileInfo 返回的 Data 结构
+func fileInfoData(file QuarkFileInfo) map[string]interface{} {
+	return map[string]interface{}{
+		"fid":          file.Fid,
+		"file_name":    file.Name,
+		"path":         file.Path,
+		"size":         file.Size,
+		"dir":          file.IsDirectory,
+		"ctime":        file.CreateTime,
+		"mtime":        file.ModifyTime,
+		"download_url": file.DownloadURL,
+	}
+}
+
+// GetFileInfo 获取文件或目录信息
+// 优先通过 path_list 接口直接解析完整路径的 fid 再按 fid 查询详情（请求数与路径深度无关）；
+// 接口不可用或类型与期望不符时回退为逐页列出父目录并按文件名匹配。
+// skipPathConversion 为 true 表示内部解析目录路径，此时同名文件和目录同时存在会优先返回目录。
+// 返回 Data 中 match_type 说明匹配方式
+func (qc *QuarkClient) GetFileInfo(remotePath string, skipPathConversion ...bool) (*StandardResponse, error) {
+	// 期望类型：内部目录解析或路径以 "/" 结尾时期望目录
+	wantDir := (len(skipPathConversion) > 0 && skipPathConversion[0]) ||
+		(len(remotePath) > 1 && (strings.HasSuffix(remotePath, "/") || strings.HasSuffix(remotePath, "\\")))
+	remotePath = normalizePath(remotePath)
+
+	if data, ok := qc.loadPathCache(remotePath, wantDir); ok {
+		return &StandardResponse{
+			Success: true,
+			Code:    "OK",
+			Message: "获取文件信息成功",
+			Data:    data,
+		}, nil
+	}
+
+	var resp *StandardResponse
+	if remotePath != "/" && remotePath != "" && remotePath != "." {
+		resp = qc.getFileInfoByPathList(remotePath, wantDir)
+	}
+	if resp == nil {
+		var err error
+		resp, err = qc.getFileInfoByListing(remotePath, wantDir)
+		if err != nil {
+			return resp, err
+		}
+	}
+	if resp.Success {
+		qc.storePathCache(remotePath, resp.Data)
+	}
+	return resp, nil
+}
+
+// GetFileInfoWithOptions 按选项获取文件或目录信息，见 FileInfoOptions
+// 大小写不敏感模式下先按精确路径查找，找不到时再逐级列目录按大小写不敏感匹配，命中时 match_type 为 ignore_case
+func (qc *QuarkClient) GetFileInfoWithOptions(remotePath string, opts FileInfoOptions) (*StandardResponse, error) {
+	resp, err := qc.GetFileInfo(remotePath, opts.PreferDir)
+	if err != nil || !opts.IgnoreCase || resp.Success || resp.Code != "FILE_NOT_FOUND" {
+		return resp, err
+	}
+	wantDir := opts.PreferDir ||
+		(len(remotePath) > 1 && (strings.HasSuffix(remotePath, "/") || strings.HasSuffix(remotePath, "\\")))
+	return qc.getFileInfoIgnoreCase(normalizePath(remotePath), wantDir), nil
+}
+
+// getFileInfoIgnoreCase 从根目录逐级列目录，按大小写不敏感匹配每一级路径段
+// 同一级有多个只大小写不同的候选时：名称完全一致的优先，最后一级再按期望类型优先，仍不唯一时返回 AMBIGUOUS_PATH
+func (qc *QuarkClient) getFileInfoIgnoreCase(remotePath string, wantDir bool) *StandardResponse {
+	if remotePath == "/" || remotePath == "" || remotePath == "." {
+		return rootFileInfo()
+	}
+
+	segments := strings.Split(strings.Trim(remotePath, "/"), "/")
+	parentFid, parentPath := "0", "/"
+	var match QuarkFileInfo
+	for idx, segment := range segments {
+		last := idx == len(segments)-1
+		candidates := make([]QuarkFileInfo, 0)
+		_, failResp := qc.walkListPages(parentFid, parentPath, "", "", 1, func(files []QuarkFileInfo) bool {
+			for _, file := range files {
+				// 中间路径段只能是目录
+				if (last || file.IsDirectory) && strings.EqualFold(normalizeNFC(file.Name), segment) {
+					candidates = append(candidates, file)
+				}
+			}
+			return true
+		})
+		if failResp != nil {
+			return &StandardResponse{
+				Success: false,
+				Code:    failResp.Code,
+				Message: fmt.Sprintf("failed to list directory: %s", failResp.Message),
+				Data:    nil,
+			}
+		}
+
+		candidates = preferCaseCandidates(candidates, func(file QuarkFileInfo) bool {
+			return normalizeNFC(file.Name) == segment
+		})
+		if last {
+			candidates = preferCaseCandidates(candidates, func(file QuarkFileInfo) bool {
+				return file.IsDirectory == wantDir
+			})
+		}
+		switch len(candidates) {
+		case 0:
+			return &StandardResponse{
+				Success: false,
+				Code:    "FILE_NOT_FOUND",
+				Message: fmt.Sprintf("file not found: %s", remotePath),
+				Data:    nil,
+			}
+		case 1:
+			match = candidates[0]
+		default:
+			paths := make([]string, 0, len(candidates))
+			for _, candidate := range candidates {
+				paths = append(paths, candidate.Path)
+			}
+			return &StandardResponse{
+				Success: false,
+				Code:    "AMBIGUOUS_PATH",
+				Message: fmt.Sprintf("ambiguous path %s: %s", remotePath, strings.Join(paths, ", ")),
+				Data:    map[string]interface{}{"candidates": paths},
+			}
+		}
+		parentFid, parentPath = match.Fid, match.Path
+	}
+
+	fileData := fileInfoData(match)
+	fileData["match_type"] = "ignore_case"
+	return &StandardResponse{
+		Success: true,
+		Code:    "OK",
+		Message: "获取文件信息成功",
+		Data:    fileData,
+	}
+}
+
+// preferCaseCandidates 候选中有满足 prefer 的条目时只保留这些条目，否则原样返回
+func preferCaseCandidates(candidates []QuarkFileInfo, prefer func(file QuarkFileInfo) bool) []QuarkFileInfo {
+	preferred := make([]QuarkFileInfo, 0, len(candidates))
+	for _, candidate := range candidates {
+		if prefer(candidate) {
+			preferred = append(preferred, candidate)
+		}
+	}
+	if len(preferred) == 0 {
+		return candidates
+	}
+	return preferred
+}
+
+// getFileInfoByPathList 通过 path_list + 详情接口获取文件信息
+// 返回 nil 表示需要回退到列目录方式（接口失败，或期望目录但解析到的是文件）
+func (qc *QuarkClient) getFileInfoByPathList(remotePath string, wantDir bool) *StandardResponse {
+	fids, err := qc.resolvePathFids([]string{remotePath})
+	if err != nil {
+		return nil
+	}
+	fid, ok := fids[remotePath]
+	if !ok {
+		return &StandardResponse{
+			Success: false,
+			Code:    "FILE_NOT_FOUND",
+			Message: fmt.Sprintf("file not found: %s", remotePath),
+			Data:    nil,
+		}
+	}
+
+	infoResp, err := qc.GetFileInfoByFid(fid)
+	if err != nil || !infoResp.Success {
+		return nil
+	}
+	if isDir, _ := infoResp.Data["dir"].(bool); wantDir && !isDir {
+		// 可能存在同名目录，交给列目录方式按类型匹配
+		return nil
+	}
+	infoResp.Data["path"] = remotePath
+	infoResp.Data["match_type"] = "exact"
+	return infoResp
+}
+
+// getFileInfoByListing 逐页列出父目录并按文件名匹配获取文件信息
+func (qc *QuarkClient) getFileInfoByListing(remotePath string, wantDir bool) (*StandardResponse, error) {
+
+	if remotePath == "/" || remotePath == "" || remotePath == "." {
+		return rootFileInfo(), nil
+	}
+
+	fileName := filepath.Base(remotePath)
+	if fileName == "." || fileName == "/" {
+		parts := strings.Split(strings.Trim(remotePath, "/"), "/")
+		if len(parts) > 0 && parts[len(parts)-1] != "" {
+			fileName = parts[len(parts)-1]
+		} else {
+			fileName = ""
+		}
+	}
+
+	parentPath := remotePath
+	lastSlash := strings.LastIndex(parentPath, "/")
+	if lastSlash == 0 {
+		parentPath = "/"
+	} else if lastSlash > 0 {
+		parentPath = parentPath[:lastSlash]
+		if parentPath == "" {
+			parentPath = "/"
+		}
+	} else {
+		parentPath = "/"
+	}
+
+	var parentFid string
+	parentPath = normalizePath(parentPath)
+	if parentPath == "/" || parentPath == "." || parentPath == "" {
+		parentFid = normalizeRootDir(parentPath)
+	} else {
+		if parentPath == remotePath {
+			return &StandardResponse{
+				Success: false,
+				Code:    "INVALID_PATH",
+				Message: fmt.Sprintf("invalid path: parent path equals current path: %s", remotePath),
+				Data:    nil,
+			}, nil
+		}
+
+		parentInfo, err := qc.GetFileInfo(parentPath, true)
+		if err != nil {
+			return &StandardResponse{
+				Success: false,
+				Code:    "GET_PARENT_DIRECTORY_ERROR",
+				Message: fmt.Sprintf("failed to get parent directory: %v", err),
+				Data:    nil,
+			}, nil
+		}
+		if !parentInfo.Success {
+			return &StandardResponse{
+				Success: false,
+				Code:    parentInfo.Code,
+				Message: fmt.Sprintf("failed to get parent directory: %s", parentInfo.Message),
+				Data:    nil,
+			}, nil
+		}
+		fid, ok := parentInfo.Data["fid"].(string)
+		if !ok || fid == "" {
+			return &StandardResponse{
+				Success: false,
+				Code:    "INVALID_PARENT_DIRECTORY_INFO",
+				Message: "parent directory info is invalid: fid not found or empty",
+				Data:    nil,
+			}, nil
+		}
+		parentFid = fid
+	}
+
+	var parentPathForList string
+	parentPath = normalizePath(parentPath)
+	if parentPath == "/" || parentPath == "." || parentPath == "" {
+		parentPathForList = "/"
+	} else {
+		parentPathForList = parentPath
+	}
+
+	// 逐页查找父目录下的同名条目，命中期望类型后立即停止翻页，避免大目录全量拉取
+	// 同名文件和目录同时存在时：内部解析目录路径（skipPathConversion）或路径以 "/" 结尾时优先目录，否则优先文件
+	var match, fallback *QuarkFileInfo
+	_, failResp := qc.walkListPages(parentFid, parentPathForList, "", "", 1, func(files []QuarkFileInfo) bool {
+		for i := range files {
+			if normalizeNFC(files[i].Name) != fileName {
+				continue
+			}
+			if files[i].IsDirectory == wantDir {
+				match = &files[i]
+				return false
+			}
+			if fallback == nil {
+				fallback = &files[i]
+			}
+		}
+		return true
+	})
+	if failResp != nil {
+		return &StandardResponse{
+			Success: false,
+			Code:    failResp.Code,
+			Message: fmt.Sprintf("failed to list directory: %s", failResp.Message),
+			Data:    nil,
+		}, nil
+	}
+
+	// match_type 说明匹配方式：exact 为名称和期望类型都一致；type_fallback 为只找到另一种类型的同名条目
+	matchType := "exact"
+	if match == nil && fallback != nil {
+		match = fallback
+		matchType = "type_fallback"
+	}
+	if match != nil {
+		fileData := fileInfoData(*match)
+		fileData["match_type"] = matchType
+
+		return &StandardResponse{
+			Success: true,
+			Code:    "OK",
+			Message: "获取文件信息成功",
+			Data:    fileData,
+		}, nil
+	}
+
+	// 文件未找到
+	return &StandardResponse{
+		Success: false,
+		Code:    "FILE_NOT_FOUND",
+		Message: fmt.Sprintf("file not found: %s", remotePath),
+		Data:    nil,
+	}, nil
+}
+
+// Delete 删除文件或目录
+func (qc *QuarkClient) Delete(remotePath string) (*StandardResponse, error) {
+	remotePath = normalizePath(remotePath)
+
+	// 获取文件信息以获取文件 ID
+	fileInfo, err := qc.GetFileInfo(remotePath)
+	if err != nil {
+		return &StandardResponse{
+			Success: false,
+			Code:    "GET_FILE_INFO_ERROR",
+			Message: fmt.Sprintf("failed to get file info: %v", err),
+			Data:    nil,
+		}, nil
+	}
+
+	// 检查 GetFileInfo 是否成功
+	if !fileInfo.Success {
+		return &StandardResponse{
+			Success: false,
+			Code:    fileInfo.Code,
+			Message: fmt.Sprintf("failed to get file info: %s", fileInfo.Message),
+			Data:    nil,
+		}, nil
+	}
+
+	// 安全地获取 fid
+	fileFid, ok := fileInfo.Data["fid"].(string)
+	if !ok || fileFid == "" {
+		return &StandardResponse{
+			Success: false,
+			Code:    "INVALID_FILE_INFO",
+			Message: "file info is invalid: fid not found or empty",
+			Data:    nil,
+		}, nil
+	}
+
+	deleteData := map[string]interface{}{
+		"action_type":  1,
+		"exclude_fids": []string{},
+		"filelist":     []string{fileFid},
+	}
+
+	jsonData, err := json.Marshal(deleteData)
+	if err != nil {
+		return &StandardResponse{
+			Success: false,
+			Code:    "MARSHAL_DELETE_DATA_ERROR",
+			Message: fmt.Sprintf("failed to marshal delete data: %v", err),
+			Data:    nil,
+		}, nil
+	}
+
+	respMap, err := qc.makeRequest("POST", FILE_DELETE, bytes.NewBuffer(jsonData), nil)
+	if err != nil {
+		return &StandardResponse{
+			Success: false,
+			Code:    "DELETE_REQUEST_ERROR",
+			Message: fmt.Sprintf("delete request failed: %v", err),
+			Data:    nil,
+		}, nil
+	}
+
+	var deleteResp struct {
+		Status  int                    `json:"status"`
+		Code    int                    `json:"code"`
+		Message string                 `json:"message"`
+		Data    map[string]interface{} `json:"data"`
+	}
+
+	if err := qc.parseResponse(respMap, &deleteResp); err != nil {
+		return &StandardResponse{
+			Success: false,
+			Code:    "DECODE_DELETE_RESPONSE_ERROR",
+			Message: fmt.Sprintf("failed to decode delete response: %v", err),
+			Data:    nil,
+		}, nil
+	}
+
+	if deleteResp.Status >= 400 || deleteResp.Code != 0 {
+		return &StandardResponse{
+			Success: false,
+			Code:    "DELETE_FAILED",
+			Message: fmt.Sprintf("delete failed: %s (status: %d, code: %d)", deleteResp.Message, deleteResp.Status, deleteResp.Code),
+			Data:    nil,
+		}, nil
+	}
+
+	qc.InvalidatePathCache(remotePath)
+	return &StandardResponse{
+		Success: true,
+		Code:    "OK",
+		Message: "删除成功",
+		Data:    map[string]interface{}{"fid": deleteResp.Data["fid"]},
+	}, nil
+}
+
+// BuildHeaders 实现 RequestHeaderBuilder 接口（OSSPartUploadHeaderBuilder）
+func (b *OSSPartUploadHeaderBuilder) BuildHeaders(req *http.Request, qc *QuarkClient) error {
+	req.Header.Set("Authorization", b.AuthKey)
+	req.Header.Set("Content-Type", b.MimeType)
+	req.Header.Set("x-oss-date", b.Timestamp)
+	req.Header.Set("x-oss-user-agent", "aliyun-sdk-js/1.0.0 Chrome 145.0.0.0 on Windows 10 64-bit")
+
+	// 如果存在 HashCtx，设置 X-Oss-Hash-Ctx header
+	if b.HashCtx != nil {
+		hashCtxStr, err := encodeHashCtx(b.HashCtx)
+		if err == nil {
+			req.Header.Set("X-Oss-Hash-Ctx", hashCtxStr)
+		}
+	}
+
+	return nil
+}
+
+// BuildHeaders 实现 RequestHeaderBuilder 接口（OSSCommitHeaderBuilder）
+func (b *OSSCommitHeaderBuilder) BuildHeaders(req *http.Request, qc *QuarkClient) error {
+	req.Header.Set("Authorization", b.AuthKey)
+	req.Header.Set("Content-MD5", b.ContentMD5)
 	req.Header.Set("Content-Type", "application/xml")
 	req.Header.Set("Referer", "https://pan.quark.cn/")
 	req.Header.Set("x-oss-callback", b.Callback)
@@ -2588,194 +3914,1197 @@ func (b *OSSCommitHeaderBuilder) BuildHeaders(req *http.Request, qc *QuarkClient
 	return nil
 }
 
+// BuildHeaders 实现 RequestHeaderBuilder 接口（OSSAbortHeaderBuilder）
+func (b *OSSAbortHeaderBuilder) BuildHeaders(req *http.Request, qc *QuarkClient) error {
+	req.Header.Set("Authorization", b.AuthKey)
+	req.Header.Set("Referer", "https://pan.quark.cn/")
+	req.Header.Set("x-oss-date", b.Timestamp)
+	req.Header.Set("x-oss-user-agent", "aliyun-sdk-js/1.0.0 Chrome 145.0.0.0 on Windows 10 64-bit")
+	return nil
+}
+
+// ErrDownloadDirectory fid 指向目录，不能直接下载
+var ErrDownloadDirectory = errors.New("cannot download directory")
+
+// ErrDownloadSkipped 本地目标文件已存在且冲突策略为 skip，未下载
+var ErrDownloadSkipped = errors.New("local file already exists, skipped")
+
+// ErrDownloadExists 本地目标文件已存在且冲突策略为 fail，未下载
+var ErrDownloadExists = errors.New("local file already exists")
+
+// ErrVerifyFailed 下载后校验失败（大小或哈希与远端不一致），下载的文件已删除
+var ErrVerifyFailed = errors.New("download verification failed")
+
+// ErrRangeNotSupported 指定了字节范围（DownloadOptions.Offset/Length），但服务端没有按 Range 返回部分内容
+var ErrRangeNotSupported = errors.New("server does not support range requests")
+
+// ErrPartNotSequential OSS 返回 PartNotSequential：bucket 要求分片按 partNumber 顺序上传，UploadFile 遇到时自动退回串行上传
+var ErrPartNotSequential = errors.New("oss requires parts to be uploaded sequentially")
+
+// ErrHashUnavailable 服务端元数据中没有文件的 md5/sha1，需要下载内容计算（见 ComputeFileHash）
+var ErrHashUnavailable = errors.New("file hash not available from metadata")
+
 // GetDownloadURL 获取文件的下载链接（支持同步与异步，大文件为异步任务会轮询直到拿到 URL）
 // fid: 文件ID
 // 返回: 下载链接URL
 func (qc *QuarkClient) GetDownloadURL(fid string) (string, error) {
+	info, err := qc.GetDownloadInfo(fid)
+	if err != nil {
+		return "", err
+	}
+	return info.DownloadURL, nil
+}
+
+// GetDownloadInfo 获取文件的下载链接以及下载接口返回的文件名、大小，不需要先按路径查询文件信息
+// fid 指向目录时返回 ErrDownloadDirectory
+func (qc *QuarkClient) GetDownloadInfo(fid string) (*DownloadInfo, error) {
 	data := map[string]interface{}{
 		"fids": []string{fid},
 	}
 	jsonData, err := json.Marshal(data)
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal download request: %w", err)
+		return nil, fmt.Errorf("failed to marshal download request: %w", err)
 	}
 	respMap, err := qc.makeRequest("POST", FILE_DOWNLOAD, bytes.NewBuffer(jsonData), nil)
 	if err != nil {
-		errStr := err.Error()
-		if strings.Contains(errStr, "23018") || strings.Contains(errStr, "download file size limit") {
-			return "", fmt.Errorf("超过文件下载大小限制，请使用客户端下载")
+		errStr := err.Error()
+		if strings.Contains(errStr, "23018") || strings.Contains(errStr, "download file size limit") {
+			return nil, fmt.Errorf("超过文件下载大小限制，请使用客户端下载")
+		}
+		return nil, fmt.Errorf("download request failed: %w", err)
+	}
+	code, _ := respMap["code"].(float64)
+	status, _ := respMap["status"].(float64)
+	if int(code) != 0 || int(status) != 200 {
+		return nil, fmt.Errorf("download failed: code=%v, status=%v", code, status)
+	}
+	rawData := respMap["data"]
+	if rawData == nil {
+		return nil, fmt.Errorf("download response data is empty")
+	}
+	// 同步：data 为数组，直接带 download_url
+	if arr, ok := rawData.([]interface{}); ok && len(arr) > 0 {
+		if first, ok := arr[0].(map[string]interface{}); ok {
+			if info, err := downloadInfoFromItem(fid, first); info != nil || err != nil {
+				return info, err
+			}
+		}
+	}
+	// 异步：data 为对象，含 task_id / task_sync / task_resp
+	if obj, ok := rawData.(map[string]interface{}); ok {
+		taskID, _ := obj["task_id"].(string)
+		_, _ = obj["task_sync"].(bool)
+		if taskResp, _ := obj["task_resp"].(map[string]interface{}); taskResp != nil {
+			if dataArr, _ := taskResp["data"].([]interface{}); len(dataArr) > 0 {
+				if first, _ := dataArr[0].(map[string]interface{}); first != nil {
+					if info, err := downloadInfoFromItem(fid, first); info != nil || err != nil {
+						return info, err
+					}
+				}
+			}
+		}
+		if taskID != "" {
+			item, err := qc.waitForDownloadTaskComplete(taskID)
+			if err != nil {
+				return nil, err
+			}
+			if info, err := downloadInfoFromItem(fid, item); info != nil || err != nil {
+				return info, err
+			}
+		}
+	}
+	return nil, fmt.Errorf("download response data is empty or invalid")
+}
+
+// downloadInfoFromItem 从下载接口返回的条目中提取下载信息；条目为目录时返回 ErrDownloadDirectory，没有 download_url 时返回 nil, nil
+func downloadInfoFromItem(fid string, item map[string]interface{}) (*DownloadInfo, error) {
+	fileInfo := parseListItem(item, "")
+	if fileInfo.IsDirectory {
+		return nil, fmt.Errorf("%w: %s", ErrDownloadDirectory, fid)
+	}
+	downloadURL, _ := item["download_url"].(string)
+	if downloadURL == "" {
+		return nil, nil
+	}
+	md5Hex, _ := item["md5"].(string)
+	sha1Hex, _ := item["sha1"].(string)
+	if fileInfo.Fid == "" {
+		fileInfo.Fid = fid
+	}
+	return &DownloadInfo{
+		Fid:         fileInfo.Fid,
+		FileName:    fileInfo.Name,
+		Size:        fileInfo.Size,
+		DownloadURL: downloadURL,
+		ModifyTime:  fileInfo.ModifyTime,
+		MD5:         normalizeHashHex(md5Hex, md5.Size),
+		SHA1:        normalizeHashHex(sha1Hex, sha1.Size),
+	}, nil
+}
+
+// GetFileHash 从下载接口返回的元数据中获取文件的 md5 和 sha1（小写十六进制），不下载文件内容
+// 只拿到其中一个时另一个为空字符串；都拿不到时返回 ErrHashUnavailable，fid 指向目录时返回 ErrDownloadDirectory
+func (qc *QuarkClient) GetFileHash(fid string) (string, string, error) {
+	info, err := qc.GetDownloadInfo(fid)
+	if err != nil {
+		return "", "", err
+	}
+	if info.MD5 == "" && info.SHA1 == "" {
+		return "", "", fmt.Errorf("%w: %s", ErrHashUnavailable, fid)
+	}
+	return info.MD5, info.SHA1, nil
+}
+
+// ComputeFileHash 流式下载文件并计算 md5 和 sha1（小写十六进制），内容边下载边丢弃，不落盘
+// opts 与 DownloadToWriterWithOptions 相同（Progress、URL、RateLimiter），同样支持失败重试
+func (qc *QuarkClient) ComputeFileHash(fid string, opts DownloadOptions) (string, string, error) {
+	hasher := newParallelHasher()
+	err := qc.DownloadToWriterWithOptions(fid, hasher, opts)
+	md5Sum, sha1Sum := hasher.Sum()
+	if err != nil {
+		return "", "", err
+	}
+	return md5Sum, sha1Sum, nil
+}
+
+// normalizeHashHex 将接口返回的哈希统一为小写十六进制：支持十六进制或 base64 编码，长度不符时返回空字符串
+func normalizeHashHex(value string, size int) string {
+	if value == "" {
+		return ""
+	}
+	if len(value) == size*2 {
+		if _, err := hex.DecodeString(value); err == nil {
+			return strings.ToLower(value)
+		}
+	}
+	if raw, err := base64.StdEncoding.DecodeString(value); err == nil && len(raw) == size {
+		return hex.EncodeToString(raw)
+	}
+	return ""
+}
+
+// waitForDownloadTaskComplete 轮询下载任务直到完成，返回带 download_url 的条目
+func (qc *QuarkClient) waitForDownloadTaskComplete(taskID string) (map[string]interface{}, error) {
+	const maxRetries = 60
+	retryInterval := 2 * time.Second
+	for i := 0; i < maxRetries; i++ {
+		time.Sleep(retryInterval)
+		queryParams := url.Values{}
+		queryParams.Set("task_id", taskID)
+		queryParams.Set("retry_index", "0")
+		reqURL := qc.baseURL + TASK + "?" + queryParams.Encode()
+		respMap, err := qc.makeRequest("GET", reqURL, nil, nil)
+		if err != nil {
+			return nil, fmt.Errorf("query download task failed: %w", err)
+		}
+		rawData := respMap["data"]
+		if rawData == nil {
+			continue
+		}
+		data, ok := rawData.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		status, _ := data["status"].(float64)
+		if status == 3 {
+			return nil, fmt.Errorf("download task failed")
+		}
+		if status == 2 {
+			if u, _ := data["download_url"].(string); u != "" {
+				return data, nil
+			}
+			if arr, _ := data["data"].([]interface{}); len(arr) > 0 {
+				if first, _ := arr[0].(map[string]interface{}); first != nil {
+					if u, _ := first["download_url"].(string); u != "" {
+						return first, nil
+					}
+				}
+			}
+		}
+	}
+	return nil, fmt.Errorf("download task timeout after %d retries", maxRetries)
+}
+
+// DownloadDir 递归下载远程目录，在 localDir 下按相同结构创建目录并逐个下载文件
+// dirPath: 远程目录路径（根目录使用 "/"）；非根目录保存为 localDir/<目录名>/...，根目录或 opts.ContentsOnly 时内容直接保存在 localDir 下
+// 文件由 DownloadFiles 按 opts.Workers 并发下载，单个文件失败不影响其它文件；列目录失败的子目录记录在 list_failed 中
+// 下载过程中在 localDir 下维护断点状态文件 DOWNLOAD_STATE_FILE，中断后重跑时跳过已完成的文件（opts.Restart 时忽略），全部成功后删除
+// 返回 Data 包含 local_dir（本地根目录）、files、dirs、downloaded、skipped（按 opts.Conflict 跳过的文件）、resumed（按断点状态跳过的文件数）、
+// failed（[]DownloadResult）、list_failed；有文件或子目录失败时 Code 为 PARTIAL_SUCCESS
+func (qc *QuarkClient) DownloadDir(dirPath, localDir string, opts DownloadDirOptions) (*StandardResponse, error) {
+	rootPath := normalizePath(dirPath)
+	if !strings.HasPrefix(rootPath, "/") {
+		return &StandardResponse{
+			Success: false,
+			Code:    "INVALID_PATH",
+			Message: fmt.Sprintf("directory path must be absolute: %s", dirPath),
+		}, nil
+	}
+	if localDir == "" {
+		localDir = "."
+	}
+	if rootPath != "/" && !opts.ContentsOnly {
+		localDir = filepath.Join(localDir, path.Base(rootPath))
+	}
+	plan, failResp, err := qc.planDirDownload(rootPath, localDir)
+	if err != nil {
+		return nil, err
+	}
+	if failResp != nil {
+		return failResp, nil
+	}
+	// 重跑时跳过断点状态文件中记录为已完成的文件
+	state := openDownloadState(localDir, []string{rootPath}, opts.Restart)
+	jobs, resumed := state.pending(plan.jobs)
+	if !opts.NoSpaceCheck {
+		if err := checkDiskSpace(localDir, totalJobSize(jobs)); err != nil {
+			return &StandardResponse{
+				Success: false,
+				Code:    "INSUFFICIENT_DISK_SPACE",
+				Message: err.Error(),
+			}, nil
+		}
+	}
+
+	// 所有文件作为下载任务进入同一个任务队列，结果按完成顺序汇总
+	downloaded, skipped, failed := splitDownloadResults(qc.downloadFilesWithState(state, jobs, opts))
+	failed = append(plan.failed, failed...)
+	if failed == nil {
+		failed = make([]DownloadResult, 0)
+	}
+	if !opts.NoPreserveMtime {
+		plan.applyDirModTimes()
+	}
+	state.finish(len(failed) == 0 && len(plan.listFailed) == 0)
+
+	code, message := "OK", fmt.Sprintf("下载目录成功，共 %d 个文件", len(downloaded))
+	if len(failed) > 0 || len(plan.listFailed) > 0 {
+		code = "PARTIAL_SUCCESS"
+		message = fmt.Sprintf("下载目录完成，%d 个文件成功，%d 个失败，%d 个子目录列出失败", len(downloaded), len(failed), len(plan.listFailed))
+	}
+	if len(skipped) > 0 {
+		message += fmt.Sprintf("，跳过 %d 个已存在的文件", len(skipped))
+	}
+	if resumed > 0 {
+		message += fmt.Sprintf("，按断点状态跳过 %d 个已完成的文件", resumed)
+	}
+	return &StandardResponse{
+		Success: true,
+		Code:    code,
+		Message: message,
+		Data: map[string]interface{}{
+			"local_dir":   localDir,
+			"files":       len(plan.jobs),
+			"dirs":        plan.dirs,
+			"downloaded":  downloaded,
+			"skipped":     skipped,
+			"resumed":     resumed,
+			"failed":      failed,
+			"list_failed": plan.listFailed,
+		},
+	}, nil
+}
+
+// dirDownloadPlan 遍历远程目录得到的下载计划
+type dirDownloadPlan struct {
+	jobs        []DownloadJob
+	failed      []DownloadResult         // 遍历时已失败的条目（不安全的本地路径、创建本地目录失败）
+	localDirs   []string                 // 按先序遍历顺序建立的本地子目录
+	dirModTimes map[string]int64         // 本地子目录 -> 远端修改时间（秒）
+	dirs        int                      // 远程子目录数
+	listFailed  []map[string]interface{} // 列目录失败的子目录
+}
+
+// planDirDownload 遍历远程目录 rootPath：在 localDir 下建好对应的本地目录并收集待下载文件
+// 无法开始下载（建立 localDir 失败、列根目录失败等）时返回非 nil 的失败响应
+func (qc *QuarkClient) planDirDownload(rootPath, localDir string) (*dirDownloadPlan, *StandardResponse, error) {
+	if err := os.MkdirAll(localDir, 0755); err != nil {
+		return nil, &StandardResponse{
+			Success: false,
+			Code:    "LOCAL_DIR_ERROR",
+			Message: fmt.Sprintf("create local dir: %v", err),
+		}, nil
+	}
+	plan := &dirDownloadPlan{dirModTimes: make(map[string]int64)}
+	walkResp, err := qc.Walk(rootPath, 0, func(file QuarkFileInfo) error {
+		localPath, ok := downloadLocalPath(localDir, rootPath, file.Path)
+		if !ok {
+			plan.failed = append(plan.failed, DownloadResult{Fid: file.Fid, Path: file.Path, Size: file.Size, Error: "unsafe local path"})
+			if file.IsDirectory {
+				return SkipDir
+			}
+			return nil
+		}
+		if file.IsDirectory {
+			plan.dirs++
+			if err := os.MkdirAll(localPath, 0755); err != nil {
+				plan.failed = append(plan.failed, DownloadResult{Fid: file.Fid, Path: file.Path, LocalPath: localPath, Error: fmt.Sprintf("create local dir: %v", err)})
+				return SkipDir
+			}
+			plan.localDirs = append(plan.localDirs, localPath)
+			plan.dirModTimes[localPath] = file.ModifyTime
+			return nil
+		}
+		plan.jobs = append(plan.jobs, DownloadJob{Fid: file.Fid, Path: file.Path, LocalPath: localPath, Size: file.Size, ModTime: file.ModifyTime})
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	if !walkResp.Success {
+		return nil, walkResp, nil
+	}
+	plan.listFailed, _ = walkResp.Data["failed"].([]map[string]interface{})
+	return plan, nil, nil
+}
+
+// totalJobSize 返回全部文件的大小之和
+func totalJobSize(jobs []DownloadJob) int64 {
+	var total int64
+	for _, job := range jobs {
+		total += job.Size
+	}
+	return total
+}
+
+// applyDirModTimes 写入文件会更新所在目录的 mtime，因此在全部下载结束后从深到浅设置目录 mtime（尽力而为，失败忽略）
+func (p *dirDownloadPlan) applyDirModTimes() {
+	for i := len(p.localDirs) - 1; i >= 0; i-- {
+		if modTime := p.dirModTimes[p.localDirs[i]]; modTime > 0 {
+			t := time.Unix(modTime, 0)
+			os.Chtimes(p.localDirs[i], t, t)
+		}
+	}
+}
+
+// downloadLocalPath 将远程路径映射为 localDir 下的本地路径，结果不在 localDir 内（如文件名为 ".."）时返回 false
+func downloadLocalPath(localDir, rootPath, remotePath string) (string, bool) {
+	rel := strings.TrimPrefix(strings.TrimPrefix(remotePath, rootPath), "/")
+	if rel == "" {
+		return "", false
+	}
+	for _, segment := range strings.Split(rel, "/") {
+		if segment == "" || segment == "." || segment == ".." || strings.ContainsRune(segment, filepath.Separator) {
+			return "", false
+		}
+	}
+	return filepath.Join(localDir, filepath.FromSlash(rel)), true
+}
+
+// DownloadProgress 下载进度回调参数
+type DownloadProgress struct {
+	Phase        string        // 阶段：DownloadPhaseDownload（下载）或 DownloadPhaseVerify（下载后校验哈希）
+	Downloaded   int64         // 已下载（校验阶段为已校验）字节数
+	Total        int64         // 总字节数，-1 表示未知
+	Speed        float64       // 最近 PROGRESS_SPEED_WINDOW 内的平均速度（字节/秒）；Done 时为本阶段的整体平均速度
+	InstantSpeed float64       // 瞬时速度（字节/秒），即与上一次回调之间的速度
+	ETA          time.Duration // 按平均速度估算的剩余时间，-1 表示未知
+	Elapsed      time.Duration // 本阶段已用时间；Done 时为本阶段总耗时
+	Done         bool          // 本阶段成功结束后的最后一次回调
+}
+
+// DownloadProgress.Phase 取值
+const (
+	DownloadPhaseDownload = "download" // 下载中
+	DownloadPhaseVerify   = "verify"   // 下载完成后校验哈希
+)
+
+// DownloadFile 将文件下载到本地，支持断点续传
+// fid: 文件ID；destPath: 本地路径（文件或目录，为目录时使用 fileName 作为文件名）；fileName: 远程文件名（当 destPath 为目录时使用）
+// progressCallback: 进度回调，可为 nil
+// 下载过程中写入 <目标>.kuake-tmp，并在 <目标>.kuake-tmp.meta 中记录 fid、下载 URL、期望大小和 ETag；
+// 再次下载同一文件时发送 Range 续传：服务端返回 206 时追加写入，返回 200 或文件已变化（大小/ETag 不一致）时从头下载，
+// 记录的 URL 过期时重新获取下载链接后续传；校验临时文件大小后原子重命名为目标文件并删除 .kuake-tmp.meta，
+// 目标文件在下载完成前保持不变，中断时保留临时文件供下次续传；重试、限速和进度回调与 DownloadToWriter 共用同一套逻辑
+func (qc *QuarkClient) DownloadFile(fid, destPath, fileName string, progressCallback func(*DownloadProgress)) error {
+	return qc.DownloadFileWithOptions(fid, destPath, fileName, DownloadOptions{Progress: progressCallback})
+}
+
+// DownloadFileWithOptions 与 DownloadFile 相同，opts.Connections > 1 时按 Content-Length 切成多段并发下载
+// 分段模式下各段用 Range 请求写入预分配文件的对应偏移，进度按各段合计回调；
+// 服务端不支持 Range、文件太小或存在单连接的续传记录时退回单连接下载（见 DownloadFile）
+// 分段下载失败时删除未完成的临时文件（分段下载不支持续传）
+func (qc *QuarkClient) DownloadFileWithOptions(fid, destPath, fileName string, opts DownloadOptions) error {
+	_, err := qc.DownloadFileToPath(fid, destPath, fileName, opts)
+	return err
+}
+
+// DownloadFileToPath 与 DownloadFileWithOptions 相同，返回最终保存的本地路径（opts.Conflict 为 rename 时可能与目标路径不同）
+// 目标已存在且 opts.Conflict 为 skip 时不下载，返回目标路径和 ErrDownloadSkipped；为 fail 时返回目标路径和 ErrDownloadExists
+func (qc *QuarkClient) DownloadFileToPath(fid, destPath, fileName string, opts DownloadOptions) (string, error) {
+	path := resolveDownloadPath(destPath, fileName)
+	dir := filepath.Dir(path)
+	if dir != "" && dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return "", fmt.Errorf("create local dir: %w", err)
+		}
+	}
+	if opts.Conflict == DownloadConflictSkip || opts.Conflict == DownloadConflictFail {
+		if _, err := os.Stat(path); err == nil {
+			if opts.Conflict == DownloadConflictSkip {
+				return path, ErrDownloadSkipped
+			}
+			return path, fmt.Errorf("%w: %s", ErrDownloadExists, path)
+		}
+	}
+	if opts.hasRange() {
+		return qc.downloadRangeToPath(fid, path, opts)
+	}
+
+	// 校验需要下载接口返回的大小和哈希，顺带使用其下载链接
+	var expect *DownloadInfo
+	if opts.Verify {
+		info, err := qc.GetDownloadInfo(fid)
+		if err != nil {
+			return "", err
+		}
+		expect = info
+		if opts.URL == "" {
+			opts.URL = info.DownloadURL
+		}
+	}
+
+	// 进度回调补充滑动平均速度和 ETA，成功结束时回调一次 Done 事件
+	progress := newDownloadProgressMeter(opts.Progress)
+	opts.Progress = progress.progressFunc()
+
+	// 网络错误或 403/5xx 时重新获取下载链接后重试，单连接下载用 Range 续传已完成的部分
+	var size int64
+	err := qc.retryDownload(fid, opts.URL, func(downloadURL string) error {
+		var err error
+		size, err = qc.downloadOnce(fid, path, downloadURL, opts)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+	localPath, err := finishDownload(path, size, expect, opts)
+	if err == nil {
+		progress.finish()
+	}
+	return localPath, err
+}
+
+// retryDownload 调用 attempt 下载，遇到可重试的错误（网络错误、连接中断、403/5xx）时等待 qc.downloadRetryWait、
+// 重新获取下载链接后再次调用，最多重试 qc.downloadRetries 次；downloadURL 为首次使用的链接，为空时由 attempt 自行获取
+// 多次尝试后仍失败时，返回的错误包装最后一次的错误并列出每次失败的原因
+func (qc *QuarkClient) retryDownload(fid, downloadURL string, attempt func(downloadURL string) error) error {
+	var attempts []string
+	for n := 0; ; n++ {
+		err := attempt(downloadURL)
+		if err == nil {
+			return nil
+		}
+		attempts = append(attempts, fmt.Sprintf("#%d %s", n+1, downloadAttemptResult(err)))
+		if !isRetryableDownloadError(err) || n >= qc.downloadRetries {
+			if len(attempts) > 1 {
+				return fmt.Errorf("%w (%d attempts: %s)", err, len(attempts), strings.Join(attempts, ", "))
+			}
+			return err
+		}
+		if qc.Debug {
+			fmt.Printf("[调试] 下载失败（重试 %d/%d）: %v，%s后重新获取下载链接重试\n",
+				n+1, qc.downloadRetries, err, qc.downloadRetryWait)
+		}
+		time.Sleep(qc.downloadRetryWait)
+		// 获取新链接失败时留空，由下一次尝试使用续传记录中的链接或再次获取
+		downloadURL = ""
+		if u, urlErr := qc.GetDownloadURL(fid); urlErr == nil {
+			downloadURL = u
+		}
+	}
+}
+
+// downloadOnce 下载一次到 path 的临时文件并返回期望大小：opts.Connections > 1 且没有单连接续传记录时分段下载，否则单连接续传
+func (qc *QuarkClient) downloadOnce(fid, path, downloadURL string, opts DownloadOptions) (int64, error) {
+	if opts.Connections > 1 {
+		if meta := readDownloadMeta(path + DOWNLOAD_META_SUFFIX); meta == nil || meta.Fid != fid {
+			if downloadURL == "" {
+				u, err := qc.GetDownloadURL(fid)
+				if err != nil {
+					return 0, err
+				}
+				downloadURL = u
+			}
+			handled, size, err := qc.downloadSegmented(downloadURL, path, opts.Connections, !opts.NoSpaceCheck, opts.RateLimiter, opts.Progress)
+			if handled {
+				return size, err
+			}
+		}
+	}
+	return qc.downloadResumable(fid, path, downloadURL, !opts.NoSpaceCheck, opts.RateLimiter, opts.Progress)
+}
+
+// retryableDownloadError 标记可重试的下载错误（网络错误、连接中断、403/5xx），错误信息与原错误相同
+type retryableDownloadError struct {
+	err    error
+	status int // HTTP 状态码，网络错误时为 0
+}
+
+func (e *retryableDownloadError) Error() string { return e.err.Error() }
+func (e *retryableDownloadError) Unwrap() error { return e.err }
+
+// retryableDownload 将错误标记为可重试
+func retryableDownload(err error) error {
+	return &retryableDownloadError{err: err}
+}
+
+// downloadStatusError 构造下载链接返回非预期状态码的错误，403/5xx（链接过期、服务端故障）可重试
+func downloadStatusError(status int, err error) error {
+	if status == http.StatusForbidden || status >= 500 {
+		return &retryableDownloadError{err: err, status: status}
+	}
+	return err
+}
+
+// isRetryableDownloadError 判断下载错误是否值得换新链接重试
+func isRetryableDownloadError(err error) bool {
+	var retryable *retryableDownloadError
+	return errors.As(err, &retryable)
+}
+
+// downloadAttemptResult 单次下载失败的简要原因，HTTP 错误为状态码，其它为错误信息
+func downloadAttemptResult(err error) string {
+	var retryable *retryableDownloadError
+	if errors.As(err, &retryable) && retryable.status > 0 {
+		return fmt.Sprintf("status %d", retryable.status)
+	}
+	return err.Error()
+}
+
+// resolveDownloadPath 解析下载的本地目标路径：destPath 为空、"."、已存在的目录或以分隔符结尾时保存为 destPath/fileName
+func resolveDownloadPath(destPath, fileName string) string {
+	if destPath == "" || destPath == "." {
+		return fileName
+	}
+	if info, err := os.Stat(destPath); err == nil && info.IsDir() {
+		return filepath.Join(destPath, fileName)
+	}
+	if strings.HasSuffix(destPath, "/") || strings.HasSuffix(destPath, string(filepath.Separator)) {
+		return filepath.Join(destPath, fileName)
+	}
+	return destPath
+}
+
+// finishDownload 校验临时文件大小（size < 0 表示未知，不校验）后按冲突策略重命名为目标文件，并设置 mtime
+// expect 不为 nil 时（opts.Verify）还要求大小与远端一致，有 md5/sha1 时校验哈希，不一致时返回 ErrVerifyFailed
+// 校验失败时删除临时文件和续传记录，目标文件保持不变
+func finishDownload(path string, size int64, expect *DownloadInfo, opts DownloadOptions) (string, error) {
+	partPath := path + DOWNLOAD_PART_SUFFIX
+	metaPath := path + DOWNLOAD_META_SUFFIX
+	info, err := os.Stat(partPath)
+	if err != nil {
+		return "", fmt.Errorf("verify downloaded file: %w", err)
+	}
+	if size >= 0 && info.Size() != size {
+		os.Remove(partPath)
+		os.Remove(metaPath)
+		return "", fmt.Errorf("verify downloaded file: got %d bytes, want %d", info.Size(), size)
+	}
+	if expect != nil {
+		if err := verifyDownloadedFile(partPath, info.Size(), expect, opts.Progress); err != nil {
+			os.Remove(partPath)
+			os.Remove(metaPath)
+			return "", err
 		}
-		return "", fmt.Errorf("download request failed: %w", err)
 	}
-	code, _ := respMap["code"].(float64)
-	status, _ := respMap["status"].(float64)
-	if int(code) != 0 || int(status) != 200 {
-		return "", fmt.Errorf("download failed: code=%v, status=%v", code, status)
+
+	target := path
+	if opts.Conflict == DownloadConflictRename {
+		target = availableLocalPath(path)
 	}
-	rawData := respMap["data"]
-	if rawData == nil {
-		return "", fmt.Errorf("download response data is empty")
+	if err := replaceFile(partPath, target); err != nil {
+		return "", fmt.Errorf("rename downloaded file: %w", err)
 	}
-	// 同步：data 为数组，直接带 download_url
-	if arr, ok := rawData.([]interface{}); ok && len(arr) > 0 {
-		if first, ok := arr[0].(map[string]interface{}); ok {
-			if u, _ := first["download_url"].(string); u != "" {
-				return u, nil
-			}
-		}
+	os.Remove(metaPath)
+	if err := preserveMtime(target, opts); err != nil {
+		return target, err
 	}
-	// 异步：data 为对象，含 task_id / task_sync / task_resp
-	if obj, ok := rawData.(map[string]interface{}); ok {
-		taskID, _ := obj["task_id"].(string)
-		_, _ = obj["task_sync"].(bool)
-		if taskResp, _ := obj["task_resp"].(map[string]interface{}); taskResp != nil {
-			if dataArr, _ := taskResp["data"].([]interface{}); len(dataArr) > 0 {
-				if first, _ := dataArr[0].(map[string]interface{}); first != nil {
-					if u, _ := first["download_url"].(string); u != "" {
-						return u, nil
-					}
-				}
+	return target, nil
+}
+
+// verifyDownloadedFile 校验本地文件与远端大小一致，expect 带 md5/sha1 时再计算哈希比较，校验进度以 DownloadPhaseVerify 回调
+func verifyDownloadedFile(localPath string, localSize int64, expect *DownloadInfo, progressCallback func(*DownloadProgress)) error {
+	if expect.Size > 0 && localSize != expect.Size {
+		return fmt.Errorf("%w: size %d, remote size %d", ErrVerifyFailed, localSize, expect.Size)
+	}
+	if expect.MD5 == "" && expect.SHA1 == "" {
+		return nil
+	}
+
+	file, err := os.Open(localPath)
+	if err != nil {
+		return fmt.Errorf("verify downloaded file: %w", err)
+	}
+	defer file.Close()
+	hasher := newParallelHasher()
+	var verified int64
+	if progressCallback != nil {
+		progressCallback(&DownloadProgress{Phase: DownloadPhaseVerify, Downloaded: 0, Total: localSize})
+	}
+	buf := make([]byte, 256*1024)
+	for {
+		n, errRead := file.Read(buf)
+		if n > 0 {
+			hasher.Write(buf[:n])
+			verified += int64(n)
+			if progressCallback != nil {
+				progressCallback(&DownloadProgress{Phase: DownloadPhaseVerify, Downloaded: verified, Total: localSize})
 			}
 		}
-		if taskID != "" {
-			return qc.waitForDownloadTaskComplete(taskID)
+		if errRead == io.EOF {
+			break
+		}
+		if errRead != nil {
+			hasher.Sum()
+			return fmt.Errorf("verify downloaded file: %w", errRead)
 		}
 	}
-	return "", fmt.Errorf("download response data is empty or invalid")
+	gotMD5, gotSHA1 := hasher.Sum()
+	if expect.MD5 != "" && gotMD5 != expect.MD5 {
+		return fmt.Errorf("%w: md5 %s, remote md5 %s", ErrVerifyFailed, gotMD5, expect.MD5)
+	}
+	if expect.SHA1 != "" && gotSHA1 != expect.SHA1 {
+		return fmt.Errorf("%w: sha1 %s, remote sha1 %s", ErrVerifyFailed, gotSHA1, expect.SHA1)
+	}
+	return nil
 }
 
-// waitForDownloadTaskComplete 轮询下载任务直到完成，返回 download_url
-func (qc *QuarkClient) waitForDownloadTaskComplete(taskID string) (string, error) {
-	const maxRetries = 60
-	retryInterval := 2 * time.Second
-	for i := 0; i < maxRetries; i++ {
-		time.Sleep(retryInterval)
-		queryParams := url.Values{}
-		queryParams.Set("task_id", taskID)
-		queryParams.Set("retry_index", "0")
-		reqURL := qc.baseURL + TASK + "?" + queryParams.Encode()
-		respMap, err := qc.makeRequest("GET", reqURL, nil, nil)
-		if err != nil {
-			return "", fmt.Errorf("query download task failed: %w", err)
-		}
-		rawData := respMap["data"]
-		if rawData == nil {
-			continue
+// availableLocalPath 返回不存在的本地路径：path 不存在时原样返回，否则依次尝试 "name (1).ext"、"name (2).ext"…
+func availableLocalPath(path string) string {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return path
+	}
+	ext := filepath.Ext(path)
+	base := strings.TrimSuffix(path, ext)
+	for i := 1; ; i++ {
+		candidate := fmt.Sprintf("%s (%d)%s", base, i, ext)
+		if _, err := os.Stat(candidate); os.IsNotExist(err) {
+			return candidate
 		}
-		data, ok := rawData.(map[string]interface{})
-		if !ok {
-			continue
+	}
+}
+
+// replaceFile 将 src 重命名为 dst，dst 已存在时覆盖
+// Windows 上目标被占用或带只读属性时 MoveFileEx 覆盖会失败，此时先把旧文件移到 <dst>.kuake-old 再重命名，失败时还原
+func replaceFile(src, dst string) error {
+	err := os.Rename(src, dst)
+	if err == nil || runtime.GOOS != "windows" {
+		return err
+	}
+	if _, statErr := os.Stat(dst); statErr != nil {
+		return err
+	}
+	backup := dst + ".kuake-old"
+	os.Remove(backup)
+	if errMove := os.Rename(dst, backup); errMove != nil {
+		return err
+	}
+	if errRename := os.Rename(src, dst); errRename != nil {
+		os.Rename(backup, dst)
+		return errRename
+	}
+	os.Remove(backup)
+	return nil
+}
+
+// preserveMtime 将下载完成的本地文件 mtime 设为远端修改时间 opts.ModTime（为 0 时不处理）
+func preserveMtime(localPath string, opts DownloadOptions) error {
+	if opts.ModTime <= 0 {
+		return nil
+	}
+	t := time.Unix(opts.ModTime, 0)
+	if err := os.Chtimes(localPath, t, t); err != nil {
+		return fmt.Errorf("set local mtime: %w", err)
+	}
+	return nil
+}
+
+// DownloadToWriter 将文件内容直接写入 w，不落盘（如输出到 stdout 或转发给 HTTP 客户端）
+// progressCallback: 进度回调，可为 nil；w 返回错误时停止下载并返回该错误
+func (qc *QuarkClient) DownloadToWriter(fid string, w io.Writer, progressCallback func(*DownloadProgress)) error {
+	return qc.DownloadToWriterWithOptions(fid, w, DownloadOptions{Progress: progressCallback})
+}
+
+// DownloadToWriterWithOptions 与 DownloadToWriter 相同，使用 opts 中的 Progress、URL、RateLimiter 和 Offset/Length（其余字段只对本地文件有效）
+// 与 DownloadFile 共享重试逻辑：连接中断或 403/5xx 时重新获取下载链接，用 Range 从已写入 w 的位置继续，
+// 服务端不支持 Range 时跳过已写入的部分，保证 w 收到的内容不重复；续传时远端文件已变化则返回错误（已写入 w 的内容无法撤回）
+// 指定 Offset/Length 时只写入该字节范围，服务端返回 200（不支持 Range）时返回 ErrRangeNotSupported，不会退回下载整个文件
+func (qc *QuarkClient) DownloadToWriterWithOptions(fid string, w io.Writer, opts DownloadOptions) error {
+	if err := opts.validateRange(); err != nil {
+		return err
+	}
+	if opts.hasRange() {
+		return qc.downloadRangeToWriter(fid, w, opts)
+	}
+	var written int64
+	var meta *downloadMeta // 首次响应的 ETag/Last-Modified/大小，续传时用于 If-Range 和变化检测
+	progress := newDownloadProgressMeter(opts.Progress)
+	opts.Progress = progress.progressFunc()
+	err := qc.retryDownload(fid, opts.URL, func(downloadURL string) error {
+		if downloadURL == "" {
+			u, err := qc.GetDownloadURL(fid)
+			if err != nil {
+				return err
+			}
+			downloadURL = u
 		}
-		status, _ := data["status"].(float64)
-		if status == 3 {
-			return "", fmt.Errorf("download task failed")
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Hour)
+		defer cancel()
+		resp, err := qc.requestDownload(ctx, downloadURL, written, meta.ifRange())
+		if err != nil {
+			return retryableDownload(fmt.Errorf("download request: %w", err))
 		}
-		if status == 2 {
-			if u, _ := data["download_url"].(string); u != "" {
-				return u, nil
+		defer resp.Body.Close()
+
+		var total int64 = -1
+		body := io.Reader(resp.Body)
+		switch {
+		case resp.StatusCode == http.StatusPartialContent && written > 0:
+			start, size := parseContentRange(resp.Header.Get("Content-Range"))
+			if start != written {
+				return fmt.Errorf("download failed: resume at %d, server returned range from %d", written, start)
 			}
-			if arr, _ := data["data"].([]interface{}); len(arr) > 0 {
-				if first, _ := arr[0].(map[string]interface{}); first != nil {
-					if u, _ := first["download_url"].(string); u != "" {
-						return u, nil
-					}
+			if meta.changed(resp, size) {
+				return fmt.Errorf("download failed: remote file changed while resuming at %d", written)
+			}
+			total = size
+		case resp.StatusCode == http.StatusOK:
+			if resp.ContentLength >= 0 {
+				total = resp.ContentLength
+			}
+			if meta == nil {
+				meta = &downloadMeta{Size: total, ETag: resp.Header.Get("ETag"), LastModified: resp.Header.Get("Last-Modified")}
+			}
+			// 服务端不支持 Range 或 If-Range 不匹配：远端文件未变化时丢弃已经写入 w 的部分
+			if written > 0 {
+				if meta.changed(resp, total) {
+					return fmt.Errorf("download failed: remote file changed while resuming at %d", written)
+				}
+				if _, err := io.CopyN(io.Discard, body, written); err != nil {
+					return retryableDownload(fmt.Errorf("read body: %w", err))
 				}
 			}
+		default:
+			data, _ := io.ReadAll(resp.Body)
+			return downloadStatusError(resp.StatusCode, fmt.Errorf("download failed: status %d, body: %s", resp.StatusCode, string(data)))
 		}
+		written, err = copyDownloadBody(body, w, written, total, opts.RateLimiter, opts.Progress)
+		return err
+	})
+	if err == nil {
+		progress.finish()
 	}
-	return "", fmt.Errorf("download task timeout after %d retries", maxRetries)
+	return err
 }
 
-// DownloadProgress 下载进度回调参数
-type DownloadProgress struct {
-	Downloaded int64 // 已下载字节数
-	Total      int64 // 总字节数，-1 表示未知
+// copyDownloadBody 把下载响应体写入 w 直到结束，written 为此前已写入的字节数（续传起点），返回累计写入的字节数
+// 开始时以起点 written 回调一次，之后每写入一块回调一次进度；读取中断或长度不足 total（>= 0 时）返回可重试的错误，limiter 不为 nil 时按其限速读取
+func copyDownloadBody(body io.Reader, w io.Writer, written, total int64, limiter *RateLimiter, progressCallback func(*DownloadProgress)) (int64, error) {
+	if progressCallback != nil {
+		progressCallback(&DownloadProgress{Phase: DownloadPhaseDownload, Downloaded: written, Total: total})
+	}
+	body = limiter.Reader(body)
+	buf := make([]byte, 32*1024)
+	for {
+		nr, errRead := body.Read(buf)
+		if nr > 0 {
+			nw, errWrite := w.Write(buf[:nr])
+			written += int64(nw)
+			if errWrite != nil {
+				return written, fmt.Errorf("write: %w", errWrite)
+			}
+			if progressCallback != nil {
+				progressCallback(&DownloadProgress{Phase: DownloadPhaseDownload, Downloaded: written, Total: total})
+			}
+		}
+		if errRead == io.EOF {
+			break
+		}
+		if errRead != nil {
+			return written, retryableDownload(fmt.Errorf("read body: %w", errRead))
+		}
+	}
+	if total >= 0 && written != total {
+		return written, retryableDownload(fmt.Errorf("download incomplete: got %d of %d bytes", written, total))
+	}
+	return written, nil
 }
 
-// DownloadFile 将文件下载到本地
-// fid: 文件ID；destPath: 本地路径（文件或目录，为目录时使用 fileName 作为文件名）；fileName: 远程文件名（当 destPath 为目录时使用）
-// progressCallback: 进度回调，可为 nil
-func (qc *QuarkClient) DownloadFile(fid, destPath, fileName string, progressCallback func(*DownloadProgress)) error {
-	downloadURL, err := qc.GetDownloadURL(fid)
+// downloadResumable 单连接下载到 path 的临时文件，支持断点续传（见 DownloadFile），返回期望的文件大小（-1 表示未知）
+// downloadURL 为已获取的下载链接，为空时使用续传记录中的链接或重新获取；重命名为目标文件由 finishDownload 完成
+// spaceCheck 为 true 时在写入前检查剩余磁盘空间，不足时返回 ErrInsufficientDiskSpace；limiter 不为 nil 时按其限速读取
+func (qc *QuarkClient) downloadResumable(fid, path, downloadURL string, spaceCheck bool, limiter *RateLimiter, progressCallback func(*DownloadProgress)) (int64, error) {
+	partPath := path + DOWNLOAD_PART_SUFFIX
+	metaPath := path + DOWNLOAD_META_SUFFIX
+
+	// 读取上次中断留下的续传信息，fid 不一致或 .part 不存在时从头下载
+	var offset int64
+	meta := readDownloadMeta(metaPath)
+	if meta != nil && meta.Fid == fid {
+		if info, err := os.Stat(partPath); err == nil {
+			offset = info.Size()
+		}
+	} else {
+		meta = nil
+	}
+
+	savedURL := false
+	if downloadURL == "" && meta != nil && meta.URL != "" {
+		downloadURL, savedURL = meta.URL, true
+	}
+	if downloadURL == "" {
+		u, err := qc.GetDownloadURL(fid)
+		if err != nil {
+			return 0, err
+		}
+		downloadURL = u
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Hour)
+	defer cancel()
+	// 续传请求带 If-Range：远端文件在中断期间被替换时服务端直接返回完整内容，避免把新旧内容拼在一起
+	resp, err := qc.requestDownload(ctx, downloadURL, offset, meta.ifRange())
+	if err == nil && savedURL && isExpiredDownloadStatus(resp.StatusCode) {
+		// 记录的下载链接已过期：重新获取后续传
+		resp.Body.Close()
+		if qc.Debug {
+			fmt.Printf("[调试] 下载链接已过期（状态码 %d），重新获取下载链接\n", resp.StatusCode)
+		}
+		if downloadURL, err = qc.GetDownloadURL(fid); err != nil {
+			return 0, err
+		}
+		resp, err = qc.requestDownload(ctx, downloadURL, offset, meta.ifRange())
+	}
 	if err != nil {
-		return err
+		return 0, retryableDownload(fmt.Errorf("download request: %w", err))
+	}
+
+	// 续传只在起始位置正确且文件未变化时有效，否则丢弃临时文件从头下载
+	restart := false
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		// 服务端不支持 If-Range 时仍可能返回 206，再比较一次 ETag/Last-Modified/大小
+		start, size := parseContentRange(resp.Header.Get("Content-Range"))
+		restart = meta == nil || start != offset || meta.changed(resp, size)
+	case http.StatusRequestedRangeNotSatisfiable:
+		// 临时文件已经完整（上次在重命名前中断）时直接完成
+		if meta != nil && meta.Size >= 0 && offset == meta.Size {
+			resp.Body.Close()
+			return meta.Size, nil
+		}
+		restart = true
+	}
+	if restart {
+		resp.Body.Close()
+		offset = 0
+		if resp, err = qc.requestDownload(ctx, downloadURL, 0, ""); err != nil {
+			return 0, retryableDownload(fmt.Errorf("download request: %w", err))
+		}
 	}
-	// 若目标为目录或以分隔符结尾，则保存为 destPath/fileName
-	path := destPath
-	if path == "" || path == "." {
-		path = fileName
-	} else if info, err := os.Stat(path); err == nil && info.IsDir() {
-		path = filepath.Join(path, fileName)
-	} else if strings.HasSuffix(path, "/") || strings.HasSuffix(path, string(filepath.Separator)) {
-		path = filepath.Join(path, fileName)
+	defer resp.Body.Close()
+
+	var total int64 = -1
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		_, total = parseContentRange(resp.Header.Get("Content-Range"))
+	case http.StatusOK:
+		// 服务端不支持 Range、临时文件不存在或 If-Range 不匹配（远端文件已变化）：丢弃已下载部分，从头下载
+		if offset > 0 && qc.Debug {
+			fmt.Printf("[调试] 服务端对续传请求返回了完整文件，丢弃已下载的 %d 字节\n", offset)
+		}
+		offset = 0
+		if resp.ContentLength >= 0 {
+			total = resp.ContentLength
+		}
+	default:
+		body, _ := io.ReadAll(resp.Body)
+		return 0, downloadStatusError(resp.StatusCode, fmt.Errorf("download failed: status %d, body: %s", resp.StatusCode, string(body)))
 	}
-	dir := filepath.Dir(path)
-	if dir != "" && dir != "." {
-		if err := os.MkdirAll(dir, 0755); err != nil {
-			return fmt.Errorf("create local dir: %w", err)
+	if spaceCheck && total > 0 {
+		if err := checkDiskSpace(filepath.Dir(partPath), total-offset); err != nil {
+			return 0, err
 		}
 	}
-	out, err := os.Create(path)
+
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if offset > 0 {
+		flags = os.O_WRONLY | os.O_APPEND
+	}
+	out, err := os.OpenFile(partPath, flags, 0644)
 	if err != nil {
-		return fmt.Errorf("create local file: %w", err)
+		return 0, fmt.Errorf("create local file: %w", err)
 	}
 	defer out.Close()
+	newMeta := &downloadMeta{Fid: fid, URL: downloadURL, Size: total, ETag: resp.Header.Get("ETag"), LastModified: resp.Header.Get("Last-Modified")}
+	if err := writeDownloadMeta(metaPath, newMeta); err != nil {
+		return 0, fmt.Errorf("write download meta: %w", err)
+	}
+
+	if _, err := copyDownloadBody(resp.Body, out, offset, total, limiter, progressCallback); err != nil {
+		return 0, err
+	}
+	if err := out.Close(); err != nil {
+		return 0, fmt.Errorf("write file: %w", err)
+	}
+	return total, nil
+}
 
+// downloadSegmented 分段并发下载到 path 的临时文件，返回文件大小；服务端不支持 Range 或文件太小不值得分段时返回 handled=false，由调用方退回单连接下载
+func (qc *QuarkClient) downloadSegmented(downloadURL, path string, connections int, spaceCheck bool, limiter *RateLimiter, progressCallback func(*DownloadProgress)) (bool, int64, error) {
+	if connections > MAX_DOWNLOAD_CONNECTIONS {
+		connections = MAX_DOWNLOAD_CONNECTIONS
+	}
 	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Hour)
 	defer cancel()
-	req, err := http.NewRequestWithContext(ctx, "GET", downloadURL, nil)
+
+	// 用 Range: bytes=0-0 探测是否支持 Range 并取得文件大小
+	probe, err := qc.requestDownloadRange(ctx, downloadURL, 0, 0, "")
 	if err != nil {
-		return fmt.Errorf("create request: %w", err)
+		return true, 0, retryableDownload(fmt.Errorf("download request: %w", err))
+	}
+	probe.Body.Close()
+	_, total := parseContentRange(probe.Header.Get("Content-Range"))
+	if probe.StatusCode != http.StatusPartialContent || total <= 0 {
+		if qc.Debug {
+			fmt.Printf("[调试] 服务端不支持 Range 请求（状态码 %d），改用单连接下载\n", probe.StatusCode)
+		}
+		return false, 0, nil
 	}
-	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/142.0.0.0 Safari/537.36")
-	cookieParts := make([]string, 0, len(qc.cookies))
-	for k, v := range qc.cookies {
-		cookieParts = append(cookieParts, fmt.Sprintf("%s=%s", k, v))
+	segments := int((total + MIN_DOWNLOAD_SEGMENT_SIZE - 1) / MIN_DOWNLOAD_SEGMENT_SIZE)
+	if segments > connections {
+		segments = connections
 	}
-	if len(cookieParts) > 0 {
-		req.Header.Set("Cookie", strings.Join(cookieParts, "; "))
+	if segments <= 1 {
+		return false, 0, nil
 	}
 
-	client := &http.Client{
-		Timeout: 2 * time.Hour,
-		Transport: &http.Transport{
-			// 禁用 HTTP/2，与主客户端保持一致
-			TLSNextProto: make(map[string]func(authority string, c *tls.Conn) http.RoundTripper),
-		},
+	partPath := path + DOWNLOAD_PART_SUFFIX
+	if spaceCheck {
+		if err := checkDiskSpace(filepath.Dir(partPath), total); err != nil {
+			return true, 0, err
+		}
 	}
-	resp, err := client.Do(req)
+	out, err := os.OpenFile(partPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
 	if err != nil {
-		return fmt.Errorf("download request: %w", err)
+		return true, 0, fmt.Errorf("create local file: %w", err)
 	}
-	defer resp.Body.Close()
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("download failed: status %d, body: %s", resp.StatusCode, string(body))
+	if err := out.Truncate(total); err != nil {
+		out.Close()
+		os.Remove(partPath)
+		return true, 0, fmt.Errorf("preallocate local file: %w", err)
 	}
-	var total int64 = -1
-	if resp.ContentLength >= 0 {
-		total = resp.ContentLength
+
+	var mu sync.Mutex
+	var downloaded int64
+	report := func(n int64) {
+		mu.Lock()
+		defer mu.Unlock()
+		downloaded += n
+		if progressCallback != nil {
+			progressCallback(&DownloadProgress{Phase: DownloadPhaseDownload, Downloaded: downloaded, Total: total})
+		}
 	}
-	var written int64
+
+	report(0) // 起点，用于计算速度
+	segmentSize := (total + int64(segments) - 1) / int64(segments)
+	errCh := make(chan error, segments)
+	var wg sync.WaitGroup
+	for i := 0; i < segments; i++ {
+		start := int64(i) * segmentSize
+		end := start + segmentSize - 1
+		if end >= total {
+			end = total - 1
+		}
+		wg.Add(1)
+		go func(start, end int64) {
+			defer wg.Done()
+			if err := qc.downloadSegment(ctx, downloadURL, out, start, end, limiter, report); err != nil {
+				errCh <- fmt.Errorf("segment %d-%d: %w", start, end, err)
+				cancel() // 任一段失败时取消其余分段
+			}
+		}(start, end)
+	}
+	wg.Wait()
+	close(errCh)
+
+	if err := <-errCh; err != nil {
+		out.Close()
+		os.Remove(partPath)
+		return true, 0, err
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(partPath)
+		return true, 0, fmt.Errorf("write file: %w", err)
+	}
+	return true, total, nil
+}
+
+// downloadSegment 下载 [start, end] 区间并写入 out 的对应偏移，每写入一块调用 report；各段共享 limiter 限速
+func (qc *QuarkClient) downloadSegment(ctx context.Context, downloadURL string, out *os.File, start, end int64, limiter *RateLimiter, report func(n int64)) error {
+	resp, err := qc.requestDownloadRange(ctx, downloadURL, start, end, "")
+	if err != nil {
+		return retryableDownload(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusPartialContent {
+		return downloadStatusError(resp.StatusCode, fmt.Errorf("unexpected status %d", resp.StatusCode))
+	}
+
+	offset := start
+	body := limiter.Reader(resp.Body)
 	buf := make([]byte, 32*1024)
 	for {
-		nr, errRead := resp.Body.Read(buf)
+		nr, errRead := body.Read(buf)
 		if nr > 0 {
-			nw, errWrite := out.Write(buf[:nr])
-			written += int64(nw)
-			if errWrite != nil {
-				return fmt.Errorf("write file: %w", errWrite)
+			if offset+int64(nr) > end+1 {
+				return fmt.Errorf("server returned more data than requested")
 			}
-			if progressCallback != nil {
-				progressCallback(&DownloadProgress{Downloaded: written, Total: total})
+			if _, errWrite := out.WriteAt(buf[:nr], offset); errWrite != nil {
+				return fmt.Errorf("write file: %w", errWrite)
 			}
+			offset += int64(nr)
+			report(int64(nr))
 		}
 		if errRead == io.EOF {
 			break
 		}
 		if errRead != nil {
-			return fmt.Errorf("read body: %w", errRead)
+			return retryableDownload(fmt.Errorf("read body: %w", errRead))
 		}
 	}
+	if offset != end+1 {
+		return retryableDownload(fmt.Errorf("incomplete segment: got %d of %d bytes", offset-start, end-start+1))
+	}
 	return nil
 }
+
+// downloadMeta 断点续传信息，保存在 <目标>.kuake-tmp.meta 中
+type downloadMeta struct {
+	Fid          string `json:"fid"`                     // 文件ID，与本次下载不一致时不续传
+	URL          string `json:"url"`                     // 上次使用的下载链接，过期时重新获取
+	Size         int64  `json:"size"`                    // 期望的文件大小，-1 表示未知
+	ETag         string `json:"etag,omitempty"`          // 下载链接返回的 ETag，续传时用于确认文件未变化
+	LastModified string `json:"last_modified,omitempty"` // 下载链接返回的 Last-Modified，没有强 ETag 时用于 If-Range
+}
+
+// ifRange 返回续传请求的 If-Range 值：优先使用强 ETag（弱 ETag 不能用于 If-Range），其次 Last-Modified，都没有时为空
+func (m *downloadMeta) ifRange() string {
+	if m == nil {
+		return ""
+	}
+	if m.ETag != "" && !strings.HasPrefix(m.ETag, "W/") {
+		return m.ETag
+	}
+	return m.LastModified
+}
+
+// changed 判断续传响应 resp 是否表明远端文件已经变化：ETag 或 Last-Modified 与记录值不一致，
+// 都没有记录时比较文件大小（size 为响应中的文件总大小，-1 表示未知）
+func (m *downloadMeta) changed(resp *http.Response, size int64) bool {
+	if m.ETag != "" && resp.Header.Get("ETag") != m.ETag {
+		return true
+	}
+	if lastModified := resp.Header.Get("Last-Modified"); m.LastModified != "" && lastModified != "" && lastModified != m.LastModified {
+		return true
+	}
+	return m.Size > 0 && size != m.Size
+}
+
+// readDownloadMeta 读取续传信息，文件不存在或格式错误时返回 nil
+func readDownloadMeta(metaPath string) *downloadMeta {
+	data, err := os.ReadFile(metaPath)
+	if err != nil {
+		return nil
+	}
+	var meta downloadMeta
+	if err := json.Unmarshal(data, &meta); err != nil {
+		return nil
+	}
+	return &meta
+}
+
+// writeDownloadMeta 写入续传信息
+func writeDownloadMeta(metaPath string, meta *downloadMeta) error {
+	data, err := json.Marshal(meta)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(metaPath, data, 0644)
+}
+
+// requestDownload 请求下载链接，offset > 0 时带 Range 头从该位置续传
+// ifRange 不为空时续传请求同时带 If-Range，远端文件已变化时服务端返回 200 和完整内容而不是 206
+func (qc *QuarkClient) requestDownload(ctx context.Context, downloadURL string, offset int64, ifRange string) (*http.Response, error) {
+	if offset > 0 {
+		return qc.requestDownloadRange(ctx, downloadURL, offset, -1, ifRange)
+	}
+	return qc.requestDownloadRange(ctx, downloadURL, -1, -1, "")
+}
+
+// DownloadHeaders 返回请求下载链接时需要附带的请求头（Cookie、User-Agent、Referer），
+// 供 aria2、IDM 等外部下载器使用；缺少这些请求头时下载链接会返回 403。Cookie 含登录凭证，不要写入日志
+func (qc *QuarkClient) DownloadHeaders() map[string]string {
+	headers := map[string]string{
+		"User-Agent": "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/142.0.0.0 Safari/537.36",
+		"Referer":    "https://pan.quark.cn/",
+	}
+	// 按名称排序，保证输出稳定
+	names := make([]string, 0, len(qc.cookies))
+	for name := range qc.cookies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	cookieParts := make([]string, 0, len(names))
+	for _, name := range names {
+		cookieParts = append(cookieParts, fmt.Sprintf("%s=%s", name, qc.cookies[name]))
+	}
+	if len(cookieParts) > 0 {
+		headers["Cookie"] = strings.Join(cookieParts, "; ")
+	}
+	return headers
+}
+
+// requestDownloadRange 请求下载链接的 [start, end] 区间；start < 0 时不带 Range，end < 0 表示到文件末尾
+// ifRange 不为空且带 Range 时设置 If-Range 请求头
+func (qc *QuarkClient) requestDownloadRange(ctx context.Context, downloadURL string, start, end int64, ifRange string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", downloadURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	for name, value := range qc.DownloadHeaders() {
+		req.Header.Set(name, value)
+	}
+	switch {
+	case start >= 0 && end >= 0:
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", start, end))
+	case start >= 0:
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", start))
+	}
+	if start >= 0 && ifRange != "" {
+		req.Header.Set("If-Range", ifRange)
+	}
+
+	client := &http.Client{
+		Timeout: 2 * time.Hour,
+		Transport: &http.Transport{
+			// 禁用 HTTP/2，与主客户端保持一致
+			TLSNextProto: make(map[string]func(authority string, c *tls.Conn) http.RoundTripper),
+		},
+	}
+	return client.Do(req)
+}
+
+// isExpiredDownloadStatus 判断下载链接是否已过期（签名失效时 CDN 返回 403/404/410）
+func isExpiredDownloadStatus(status int) bool {
+	return status == http.StatusForbidden || status == http.StatusNotFound || status == http.StatusGone
+}
+
+// parseContentRange 解析 "bytes start-end/size"，返回起始位置和总大小，无法解析时返回 -1
+func parseContentRange(value string) (int64, int64) {
+	var start, end, size int64
+	if _, err := fmt.Sscanf(value, "bytes %d-%d/%d", &start, &end, &size); err != nil {
+		return -1, -1
+	}
+	return start, size
+}
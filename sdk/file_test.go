@@ -1,10 +1,25 @@
 package sdk
 
 import (
+	"bytes"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strconv"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestNormalizePath(t *testing.T) {
@@ -53,6 +68,61 @@ func TestNormalizePath(t *testing.T) {
 			path: "d:\\a.mkv",
 			want: "d:/a.mkv",
 		},
+		{
+			name: "resolve parent segment",
+			path: "/a/b/../c",
+			want: "/a/c",
+		},
+		{
+			name: "resolve leading dot segment",
+			path: "/./a",
+			want: "/a",
+		},
+		{
+			name: "resolve dot segments in the middle",
+			path: "/a/./b/./c/",
+			want: "/a/b/c",
+		},
+		{
+			name: "resolve consecutive parent segments",
+			path: "/a/b/c/../../d",
+			want: "/a/d",
+		},
+		{
+			name: "clamp parent segment at root",
+			path: "/..",
+			want: "/",
+		},
+		{
+			name: "clamp parent segments beyond root",
+			path: "/a/../../b",
+			want: "/b",
+		},
+		{
+			name: "resolve segments with backslashes",
+			path: "\\a\\b\\..\\c",
+			want: "/a/c",
+		},
+		{
+			name: "resolve to root",
+			path: "/a/..",
+			want: "/",
+		},
+		{
+			name: "keep names that only start with dots",
+			path: "/a/..b/.c/...",
+			want: "/a/..b/.c/...",
+		},
+		{
+			name: "keep relative parent segment",
+			path: "a/../../b",
+			want: "../b",
+		},
+		{
+			name: "strip quotes before cleaning",
+			path: "\"/a/./b\"",
+			want: "/a/b",
+		},
 	}
 
 	for _, tt := range tests {
@@ -830,7 +900,7 @@ func TestPathNormalizationInFunctions(t *testing.T) {
 					{"/a//b//c", "/a/b/c", "路径中多个斜杠被合并"},
 					{"", "", "空字符串保持不变"},
 					{".", ".", "当前目录表示保持不变"},
-					{"/.", "/.", "根目录下的点保持不变"},
+					{"/.", "/", "根目录下的点被解析为根目录"},
 				}
 
 				for _, tt := range testCases {
@@ -927,3 +997,1809 @@ func TestPathNormalizationInFunctions(t *testing.T) {
 		t.Run(tt.name, tt.testFunc)
 	}
 }
+
+// fakeListServer 模拟 FILE_SORT 分页接口，目录下有 count 个文件
+// failures 指定页码在成功前返回瞬时网络错误的次数
+func fakeListServer(count int, failures map[int]int) (roundTripFunc, *int) {
+	items := make([]map[string]interface{}, 0, count)
+	for i := 0; i < count; i++ {
+		items = append(items, map[string]interface{}{
+			"fid":       fmt.Sprintf("fid_%03d", i),
+			"file_name": fmt.Sprintf("file_%03d.txt", i),
+			"size":      i,
+			"dir":       false,
+		})
+	}
+	return fakeListServerItems(items, failures)
+}
+
+// fakeListServerItems 模拟 FILE_SORT 分页接口，按请求的 _page/_size 返回 items 的对应切片
+// 其他接口一律返回 404（模拟 path_list 等接口不可用），只统计 FILE_SORT 请求数
+func fakeListServerItems(items []map[string]interface{}, failures map[int]int) (roundTripFunc, *int) {
+	requests := 0
+	return func(req *http.Request) (*http.Response, error) {
+		if req.URL.Path != FILE_SORT {
+			resp := jsonResponse(req, `{"status":404,"code":404,"message":"not supported"}`)
+			resp.StatusCode = http.StatusNotFound
+			return resp, nil
+		}
+		requests++
+		query := req.URL.Query()
+		page, _ := strconv.Atoi(query.Get("_page"))
+		size, _ := strconv.Atoi(query.Get("_size"))
+		if failures[page] > 0 {
+			failures[page]--
+			return nil, fmt.Errorf("read: connection reset by peer")
+		}
+
+		start, end := (page-1)*size, page*size
+		if start > len(items) {
+			start = len(items)
+		}
+		if end > len(items) {
+			end = len(items)
+		}
+		body, _ := json.Marshal(map[string]interface{}{
+			"status":   200,
+			"code":     0,
+			"data":     map[string]interface{}{"list": items[start:end]},
+			"metadata": map[string]interface{}{"_total": len(items), "_page": page, "_size": size},
+		})
+		return jsonResponse(req, string(body)), nil
+	}, &requests
+}
+
+func TestListByFid_Pagination(t *testing.T) {
+	defer func(d time.Duration) { listRetryBaseDelay = d }(listRetryBaseDelay)
+	listRetryBaseDelay = time.Millisecond
+
+	tests := []struct {
+		name     string
+		count    int
+		failures map[int]int
+		wantReqs int
+	}{
+		{name: "empty directory", count: 0, wantReqs: 1},
+		{name: "single page", count: 30, wantReqs: 1},
+		{name: "exact page boundary", count: LIST_PAGE_SIZE, wantReqs: 1},
+		{name: "multiple pages", count: 3*LIST_PAGE_SIZE + 17, wantReqs: 4},
+		{name: "retry transient page failure", count: 2*LIST_PAGE_SIZE + 1, failures: map[int]int{2: 1}, wantReqs: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fn, requests := fakeListServer(tt.count, tt.failures)
+			client := createMockClient(t, fn)
+
+			resp, err := client.List("/")
+			if err != nil || !resp.Success {
+				t.Fatalf("List() = %+v, %v", resp, err)
+			}
+			list := resp.Data["list"].([]QuarkFileInfo)
+			if len(list) != tt.count {
+				t.Errorf("List() returned %d items, want %d", len(list), tt.count)
+			}
+			for i, item := range list {
+				if want := fmt.Sprintf("file_%03d.txt", i); item.Name != want {
+					t.Fatalf("List()[%d] = %s, want %s (server order not preserved)", i, item.Name, want)
+				}
+			}
+			if resp.Data["total"] != tt.count || resp.Data["has_more"] != false {
+				t.Errorf("total = %v, has_more = %v, want %d, false", resp.Data["total"], resp.Data["has_more"], tt.count)
+			}
+			if *requests != tt.wantReqs {
+				t.Errorf("sent %d requests, want %d", *requests, tt.wantReqs)
+			}
+			if resp.Data["page"] != tt.wantReqs-len(tt.failures) || resp.Data["size"] != LIST_PAGE_SIZE {
+				t.Errorf("page = %v, size = %v, want %d, %d", resp.Data["page"], resp.Data["size"], tt.wantReqs-len(tt.failures), LIST_PAGE_SIZE)
+			}
+			if metadata, ok := resp.Data["metadata"].(map[string]interface{}); !ok || metadata["_total"] != float64(tt.count) {
+				t.Errorf("metadata = %v, want server metadata with _total %d", resp.Data["metadata"], tt.count)
+			}
+		})
+	}
+}
+
+func TestListByFid_PageFailure(t *testing.T) {
+	defer func(d time.Duration) { listRetryBaseDelay = d }(listRetryBaseDelay)
+	listRetryBaseDelay = time.Millisecond
+
+	fn, _ := fakeListServer(2*LIST_PAGE_SIZE, map[int]int{2: LIST_PAGE_MAX_RETRIES + 1})
+	client := createMockClient(t, fn)
+
+	resp, err := client.List("/")
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if resp.Success || resp.Code != "LIST_REQUEST_ERROR" {
+		t.Errorf("List() = %+v, want LIST_REQUEST_ERROR", resp)
+	}
+}
+
+func TestListByFid_Retry5xx(t *testing.T) {
+	defer func(d time.Duration) { listRetryBaseDelay = d }(listRetryBaseDelay)
+	listRetryBaseDelay = time.Millisecond
+
+	// 前 failures 次 file/sort 请求返回 HTTP 502，之后正常
+	server := func(failures int, status int) (roundTripFunc, *int) {
+		requests := 0
+		return func(req *http.Request) (*http.Response, error) {
+			if req.URL.Path != FILE_SORT {
+				resp := jsonResponse(req, `{"status":404,"code":404,"message":"not supported"}`)
+				resp.StatusCode = http.StatusNotFound
+				return resp, nil
+			}
+			requests++
+			if requests <= failures {
+				resp := jsonResponse(req, `{"status":502,"code":502,"message":"bad gateway"}`)
+				resp.StatusCode = status
+				return resp, nil
+			}
+			return jsonResponse(req, `{"status":200,"code":0,"data":{"list":[{"fid":"a","file_name":"a.txt"}]},"metadata":{"_total":1}}`), nil
+		}, &requests
+	}
+
+	fn, requests := server(2, http.StatusBadGateway)
+	resp, err := createMockClient(t, fn).ListByFid("0", ListOptions{})
+	if err != nil || !resp.Success || *requests != 3 {
+		t.Errorf("ListByFid() after two 502 = %+v, %v, requests = %d, want success after 3 requests", resp, err, *requests)
+	}
+
+	fn, requests = server(LIST_PAGE_MAX_RETRIES+1, http.StatusBadGateway)
+	resp, err = createMockClient(t, fn).ListByFid("0", ListOptions{})
+	if err != nil || resp.Success || resp.Code != "LIST_REQUEST_ERROR" || *requests != LIST_PAGE_MAX_RETRIES+1 {
+		t.Fatalf("ListByFid() persistent 502 = %+v, %v, requests = %d", resp, err, *requests)
+	}
+	if want := "4 attempts: #1 status 502, #2 status 502, #3 status 502, #4 status 502"; !strings.Contains(resp.Message, want) {
+		t.Errorf("ListByFid() message = %q, want it to contain %q", resp.Message, want)
+	}
+
+	// 4xx 不重试
+	fn, requests = server(1, http.StatusForbidden)
+	createMockClient(t, fn).ListByFid("0", ListOptions{})
+	if *requests != 1 {
+		t.Errorf("ListByFid() 403 sent %d file/sort requests, want 1", *requests)
+	}
+
+	// 重试次数可配置，0 表示不重试
+	fn, requests = server(1, http.StatusBadGateway)
+	client := createMockClient(t, fn)
+	client.SetListRetries(0)
+	if resp, _ := client.ListByFid("0", ListOptions{}); resp.Success || *requests != 1 {
+		t.Errorf("ListByFid() with 0 retries = %+v, requests = %d, want failure after 1 request", resp, *requests)
+	}
+}
+
+func TestGetFileInfo_Paging(t *testing.T) {
+	items := make([]map[string]interface{}, 0)
+	for i := 0; i < 3*LIST_PAGE_SIZE; i++ {
+		items = append(items, map[string]interface{}{
+			"fid":       fmt.Sprintf("fid_%03d", i),
+			"file_name": fmt.Sprintf("file_%03d.txt", i),
+			"dir":       false,
+		})
+	}
+	// 同名文件和目录分别位于第 2 页和第 3 页
+	items[LIST_PAGE_SIZE+5] = map[string]interface{}{"fid": "fid_same_file", "file_name": "same", "dir": false}
+	items[2*LIST_PAGE_SIZE+5] = map[string]interface{}{"fid": "fid_same_dir", "file_name": "same", "dir": true}
+
+	tests := []struct {
+		name          string
+		path          string
+		skipPathConv  bool
+		wantFid       string
+		wantMatchType string
+		wantReqs      int
+		wantCode      string
+	}{
+		{name: "file on later page", path: "/file_150.txt", wantFid: "fid_150", wantMatchType: "exact", wantReqs: 2},
+		{name: "stops paging once found", path: "/file_001.txt", wantFid: "fid_001", wantMatchType: "exact", wantReqs: 1},
+		{name: "prefers file by default", path: "/same", wantFid: "fid_same_file", wantMatchType: "exact", wantReqs: 2},
+		{name: "trailing slash prefers directory", path: "/same/", wantFid: "fid_same_dir", wantMatchType: "exact", wantReqs: 3},
+		{name: "directory lookup prefers directory", path: "/same", skipPathConv: true, wantFid: "fid_same_dir", wantMatchType: "exact", wantReqs: 3},
+		{name: "falls back to other type", path: "/file_010.txt/", wantFid: "fid_010", wantMatchType: "type_fallback", wantReqs: 3},
+		{name: "not found", path: "/missing", wantCode: "FILE_NOT_FOUND", wantReqs: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fn, requests := fakeListServerItems(items, nil)
+			client := createMockClient(t, fn)
+
+			resp, err := client.GetFileInfo(tt.path, tt.skipPathConv)
+			if err != nil {
+				t.Fatalf("GetFileInfo() error = %v", err)
+			}
+			if tt.wantCode != "" {
+				if resp.Success || resp.Code != tt.wantCode {
+					t.Errorf("GetFileInfo() = %+v, want code %s", resp, tt.wantCode)
+				}
+			} else {
+				if !resp.Success {
+					t.Fatalf("GetFileInfo() = %+v", resp)
+				}
+				if resp.Data["fid"] != tt.wantFid || resp.Data["match_type"] != tt.wantMatchType {
+					t.Errorf("GetFileInfo() fid = %v, match_type = %v, want %s, %s", resp.Data["fid"], resp.Data["match_type"], tt.wantFid, tt.wantMatchType)
+				}
+			}
+			if *requests != tt.wantReqs {
+				t.Errorf("sent %d requests, want %d", *requests, tt.wantReqs)
+			}
+		})
+	}
+}
+
+// fakeTreeServer 模拟按 pdir_fid 返回不同目录内容的 FILE_SORT 接口，failing 中的目录返回业务错误
+func fakeTreeServer(dirs map[string][]map[string]interface{}, failing map[string]bool) roundTripFunc {
+	return func(req *http.Request) (*http.Response, error) {
+		pdirFid := req.URL.Query().Get("pdir_fid")
+		if failing[pdirFid] {
+			return jsonResponse(req, `{"status":500,"code":41000,"message":"internal error"}`), nil
+		}
+		items := dirs[pdirFid]
+		if items == nil {
+			items = []map[string]interface{}{}
+		}
+		body, _ := json.Marshal(map[string]interface{}{
+			"status":   200,
+			"code":     0,
+			"data":     map[string]interface{}{"list": items},
+			"metadata": map[string]interface{}{"_total": len(items)},
+		})
+		return jsonResponse(req, string(body)), nil
+	}
+}
+
+func TestListRecursive(t *testing.T) {
+	entry := func(fid, name string, dir bool) map[string]interface{} {
+		return map[string]interface{}{"fid": fid, "file_name": name, "dir": dir}
+	}
+	dirs := map[string][]map[string]interface{}{
+		"0":     {entry("a", "a", true), entry("b", "b", true), entry("f1", "f1.txt", false)},
+		"a":     {entry("a_sub", "sub", true), entry("f2", "f2.txt", false)},
+		"a_sub": {entry("f3", "f3.txt", false)},
+		"b":     {entry("f4", "f4.txt", false)},
+	}
+
+	tests := []struct {
+		name       string
+		maxDepth   int
+		failing    map[string]bool
+		wantPaths  []string
+		wantFailed int
+		wantCode   string
+	}{
+		{
+			name:      "unlimited depth",
+			maxDepth:  0,
+			wantPaths: []string{"/a", "/a/sub", "/a/sub/f3.txt", "/a/f2.txt", "/b", "/b/f4.txt", "/f1.txt"},
+			wantCode:  "OK",
+		},
+		{
+			name:      "max depth 1",
+			maxDepth:  1,
+			wantPaths: []string{"/a", "/b", "/f1.txt"},
+			wantCode:  "OK",
+		},
+		{
+			name:      "max depth 2",
+			maxDepth:  2,
+			wantPaths: []string{"/a", "/a/sub", "/a/f2.txt", "/b", "/b/f4.txt", "/f1.txt"},
+			wantCode:  "OK",
+		},
+		{
+			name:       "subdirectory failure continues",
+			maxDepth:   0,
+			failing:    map[string]bool{"a": true},
+			wantPaths:  []string{"/a", "/b", "/b/f4.txt", "/f1.txt"},
+			wantFailed: 1,
+			wantCode:   "PARTIAL_SUCCESS",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := createMockClient(t, fakeTreeServer(dirs, tt.failing))
+			resp, err := client.ListRecursive("/", tt.maxDepth)
+			if err != nil || !resp.Success {
+				t.Fatalf("ListRecursive() = %+v, %v", resp, err)
+			}
+			if resp.Code != tt.wantCode {
+				t.Errorf("ListRecursive() code = %s, want %s", resp.Code, tt.wantCode)
+			}
+			list := resp.Data["list"].([]QuarkFileInfo)
+			paths := make([]string, 0, len(list))
+			for _, item := range list {
+				paths = append(paths, item.Path)
+			}
+			if strings.Join(paths, ",") != strings.Join(tt.wantPaths, ",") {
+				t.Errorf("ListRecursive() paths = %v, want %v", paths, tt.wantPaths)
+			}
+			if failed := resp.Data["failed"].([]map[string]interface{}); len(failed) != tt.wantFailed {
+				t.Errorf("ListRecursive() failed = %v, want %d entries", failed, tt.wantFailed)
+			}
+		})
+	}
+
+	t.Run("root failure", func(t *testing.T) {
+		client := createMockClient(t, fakeTreeServer(dirs, map[string]bool{"0": true}))
+		resp, err := client.ListRecursive("/", 0)
+		if err != nil || resp.Success {
+			t.Errorf("ListRecursive() = %+v, %v, want failure", resp, err)
+		}
+	})
+}
+
+// fakeFileInfoServer 模拟 path_list 和 file/info 接口，files 以完整路径为 key
+func fakeFileInfoServer(files map[string]map[string]interface{}) (roundTripFunc, *[]string) {
+	var requested []string
+	return func(req *http.Request) (*http.Response, error) {
+		requested = append(requested, req.URL.Path)
+		switch req.URL.Path {
+		case FILE_INFO_PATH_LIST:
+			var body struct {
+				FilePath []string `json:"file_path"`
+			}
+			json.NewDecoder(req.Body).Decode(&body)
+			list := make([]map[string]interface{}, 0)
+			for _, p := range body.FilePath {
+				if f, ok := files[p]; ok {
+					list = append(list, map[string]interface{}{"file_path": p, "fid": f["fid"]})
+				}
+			}
+			data, _ := json.Marshal(map[string]interface{}{"status": 200, "code": 0, "data": list})
+			return jsonResponse(req, string(data)), nil
+		case FILE_INFO:
+			fid := req.URL.Query().Get("fid")
+			for p, f := range files {
+				if f["fid"] == fid {
+					item := map[string]interface{}{"full_path": p}
+					for k, v := range f {
+						item[k] = v
+					}
+					data, _ := json.Marshal(map[string]interface{}{"status": 200, "code": 0, "data": item})
+					return jsonResponse(req, string(data)), nil
+				}
+			}
+			resp := jsonResponse(req, `{"status":404,"code":41005,"message":"file not found"}`)
+			resp.StatusCode = http.StatusNotFound
+			return resp, nil
+		default:
+			data, _ := json.Marshal(map[string]interface{}{"status": 200, "code": 0, "data": map[string]interface{}{"list": []interface{}{}}})
+			return jsonResponse(req, string(data)), nil
+		}
+	}, &requested
+}
+
+func TestGetFileInfo_PathList(t *testing.T) {
+	files := map[string]map[string]interface{}{
+		"/a/b/c/d/file.txt": {"fid": "fid_file", "file_name": "file.txt", "size": 12, "dir": false, "pdir_fid": "fid_d"},
+		"/a/b/c/d":          {"fid": "fid_d", "file_name": "d", "dir": true, "pdir_fid": "fid_c"},
+	}
+
+	tests := []struct {
+		name      string
+		path      string
+		skip      bool
+		wantFid   string
+		wantCode  string
+		wantPaths []string
+	}{
+		{
+			name:      "deep file resolved in two requests",
+			path:      "/a/b/c/d/file.txt",
+			wantFid:   "fid_file",
+			wantPaths: []string{FILE_INFO_PATH_LIST, FILE_INFO},
+		},
+		{
+			name:      "directory lookup",
+			path:      "/a/b/c/d",
+			skip:      true,
+			wantFid:   "fid_d",
+			wantPaths: []string{FILE_INFO_PATH_LIST, FILE_INFO},
+		},
+		{
+			name:      "missing path",
+			path:      "/a/b/missing.txt",
+			wantCode:  "FILE_NOT_FOUND",
+			wantPaths: []string{FILE_INFO_PATH_LIST},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fn, requested := fakeFileInfoServer(files)
+			client := createMockClient(t, fn)
+
+			resp, err := client.GetFileInfo(tt.path, tt.skip)
+			if err != nil {
+				t.Fatalf("GetFileInfo() error = %v", err)
+			}
+			if tt.wantCode != "" {
+				if resp.Success || resp.Code != tt.wantCode {
+					t.Errorf("GetFileInfo() = %+v, want code %s", resp, tt.wantCode)
+				}
+			} else if !resp.Success || resp.Data["fid"] != tt.wantFid || resp.Data["path"] != tt.path {
+				t.Errorf("GetFileInfo() = %+v, want fid %s path %s", resp, tt.wantFid, tt.path)
+			}
+			if strings.Join(*requested, ",") != strings.Join(tt.wantPaths, ",") {
+				t.Errorf("requests = %v, want %v", *requested, tt.wantPaths)
+			}
+		})
+	}
+}
+
+func TestGetFileInfoByFid(t *testing.T) {
+	fn, _ := fakeFileInfoServer(map[string]map[string]interface{}{
+		"/docs/a.txt": {"fid": "fid_a", "file_name": "a.txt", "size": 3, "dir": false, "pdir_fid": "fid_docs"},
+		"/docs":       {"fid": "fid_docs", "file_name": "docs", "dir": true, "pdir_fid": "0"},
+	})
+	client := createMockClient(t, fn)
+
+	tests := []struct {
+		name     string
+		fid      string
+		wantPath string
+		wantDir  bool
+		wantCode string
+	}{
+		{name: "file", fid: "fid_a", wantPath: "/docs/a.txt", wantDir: false, wantCode: "OK"},
+		{name: "directory", fid: "fid_docs", wantPath: "/docs", wantDir: true, wantCode: "OK"},
+		{name: "root", fid: "0", wantPath: "/", wantDir: true, wantCode: "OK"},
+		{name: "not found", fid: "fid_missing", wantCode: "FILE_NOT_FOUND"},
+		{name: "empty fid", fid: "", wantCode: "INVALID_FID"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := client.GetFileInfoByFid(tt.fid)
+			if err != nil {
+				t.Fatalf("GetFileInfoByFid() error = %v", err)
+			}
+			if resp.Code != tt.wantCode {
+				t.Fatalf("GetFileInfoByFid() code = %s, want %s (%s)", resp.Code, tt.wantCode, resp.Message)
+			}
+			if tt.wantCode == "OK" && (resp.Data["path"] != tt.wantPath || resp.Data["dir"] != tt.wantDir) {
+				t.Errorf("GetFileInfoByFid() data = %v, want path %s dir %v", resp.Data, tt.wantPath, tt.wantDir)
+			}
+		})
+	}
+}
+
+func TestList_SortOptions(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     ListOptions
+		wantSort string
+		wantCode string
+	}{
+		{name: "default sort", opts: ListOptions{}, wantSort: DEFAULT_LIST_SORT, wantCode: "OK"},
+		{name: "file name asc", opts: ListOptions{SortBy: "file_name", Order: "asc"}, wantSort: "file_type:asc,file_name:asc", wantCode: "OK"},
+		{name: "updated at desc", opts: ListOptions{SortBy: "updated_at", Order: "desc"}, wantSort: "file_type:asc,updated_at:desc", wantCode: "OK"},
+		{name: "size default order", opts: ListOptions{SortBy: "size"}, wantSort: "file_type:asc,size:asc", wantCode: "OK"},
+		{name: "invalid sort field", opts: ListOptions{SortBy: "ctime"}, wantCode: "INVALID_ARGS"},
+		{name: "invalid order", opts: ListOptions{SortBy: "size", Order: "down"}, wantCode: "INVALID_ARGS"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotSort string
+			client := createMockClient(t, func(req *http.Request) (*http.Response, error) {
+				gotSort = req.URL.Query().Get("_sort")
+				return jsonResponse(req, `{"status":200,"code":0,"data":{"list":[]},"metadata":{"_total":0}}`), nil
+			})
+
+			resp, err := client.List("/", tt.opts)
+			if err != nil {
+				t.Fatalf("List() error = %v", err)
+			}
+			if resp.Code != tt.wantCode {
+				t.Fatalf("List() code = %s, want %s", resp.Code, tt.wantCode)
+			}
+			if gotSort != tt.wantSort {
+				t.Errorf("_sort = %q, want %q", gotSort, tt.wantSort)
+			}
+		})
+	}
+}
+
+func TestList_Category(t *testing.T) {
+	tests := []struct {
+		name         string
+		opts         ListOptions
+		wantCategory string
+		wantCode     string
+	}{
+		{name: "no category", opts: ListOptions{}, wantCategory: "", wantCode: "OK"},
+		{name: "video", opts: ListOptions{Category: "video"}, wantCategory: LIST_CATEGORIES["video"], wantCode: "OK"},
+		{name: "document with paging", opts: ListOptions{Category: "document", Page: 1}, wantCategory: LIST_CATEGORIES["document"], wantCode: "OK"},
+		{name: "unknown category", opts: ListOptions{Category: "ebook"}, wantCode: "INVALID_ARGS"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotCategory string
+			client := createMockClient(t, func(req *http.Request) (*http.Response, error) {
+				gotCategory = req.URL.Query().Get("category")
+				return jsonResponse(req, `{"status":200,"code":0,"data":{"list":[]},"metadata":{"_total":0}}`), nil
+			})
+
+			resp, err := client.List("/", tt.opts)
+			if err != nil {
+				t.Fatalf("List() error = %v", err)
+			}
+			if resp.Code != tt.wantCode {
+				t.Fatalf("List() code = %s, want %s", resp.Code, tt.wantCode)
+			}
+			if gotCategory != tt.wantCategory {
+				t.Errorf("category = %q, want %q", gotCategory, tt.wantCategory)
+			}
+		})
+	}
+}
+
+func TestList_ManualPaging(t *testing.T) {
+	tests := []struct {
+		name      string
+		count     int
+		opts      ListOptions
+		wantFirst string
+		wantLen   int
+		wantMore  bool
+		wantCode  string
+	}{
+		{name: "first page default limit", count: 250, opts: ListOptions{Page: 1}, wantFirst: "file_000.txt", wantLen: LIST_PAGE_SIZE, wantMore: true, wantCode: "OK"},
+		{name: "limit larger than server page", count: 450, opts: ListOptions{Page: 2, Limit: 200}, wantFirst: "file_200.txt", wantLen: 200, wantMore: true, wantCode: "OK"},
+		{name: "unaligned page", count: 250, opts: ListOptions{Page: 3, Limit: 30}, wantFirst: "file_060.txt", wantLen: 30, wantMore: true, wantCode: "OK"},
+		{name: "last partial page", count: 250, opts: ListOptions{Page: 3, Limit: 100}, wantFirst: "file_200.txt", wantLen: 50, wantMore: false, wantCode: "OK"},
+		{name: "limit only", count: 250, opts: ListOptions{Limit: 10}, wantFirst: "file_000.txt", wantLen: 10, wantMore: true, wantCode: "OK"},
+		{name: "page out of range", count: 250, opts: ListOptions{Page: 10, Limit: 100}, wantLen: 0, wantMore: false, wantCode: "OK"},
+		{name: "negative page", count: 10, opts: ListOptions{Page: -1}, wantCode: "INVALID_ARGS"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fn, _ := fakeListServer(tt.count, nil)
+			client := createMockClient(t, fn)
+
+			resp, err := client.List("/", tt.opts)
+			if err != nil {
+				t.Fatalf("List() error = %v", err)
+			}
+			if resp.Code != tt.wantCode {
+				t.Fatalf("List() code = %s, want %s (%s)", resp.Code, tt.wantCode, resp.Message)
+			}
+			if tt.wantCode != "OK" {
+				return
+			}
+			list := resp.Data["list"].([]QuarkFileInfo)
+			if len(list) != tt.wantLen {
+				t.Errorf("List() returned %d items, want %d", len(list), tt.wantLen)
+			}
+			if len(list) > 0 && list[0].Name != tt.wantFirst {
+				t.Errorf("List()[0] = %s, want %s", list[0].Name, tt.wantFirst)
+			}
+			if resp.Data["total"] != tt.count || resp.Data["has_more"] != tt.wantMore {
+				t.Errorf("total = %v, has_more = %v, want %d, %v", resp.Data["total"], resp.Data["has_more"], tt.count, tt.wantMore)
+			}
+		})
+	}
+}
+
+func TestListByFid(t *testing.T) {
+	var gotPdirFid string
+	client := createMockClient(t, func(req *http.Request) (*http.Response, error) {
+		if req.URL.Path != FILE_SORT {
+			t.Errorf("unexpected request to %s, ListByFid should not resolve paths", req.URL.Path)
+		}
+		gotPdirFid = req.URL.Query().Get("pdir_fid")
+		return jsonResponse(req, `{"status":200,"code":0,"data":{"list":[{"fid":"child","file_name":"sub","dir":true}]},"metadata":{"_total":1}}`), nil
+	})
+
+	resp, err := client.ListByFid("fid_parent")
+	if err != nil || !resp.Success {
+		t.Fatalf("ListByFid() = %+v, %v", resp, err)
+	}
+	if gotPdirFid != "fid_parent" {
+		t.Errorf("pdir_fid = %s, want fid_parent", gotPdirFid)
+	}
+	list := resp.Data["list"].([]QuarkFileInfo)
+	if len(list) != 1 || list[0].Path != "" || list[0].PdirFid != "fid_parent" {
+		t.Errorf("ListByFid() list = %+v, want empty path and pdir_fid fid_parent", list)
+	}
+
+	if resp, _ := client.ListByFid(""); resp.Success || resp.Code != "INVALID_FID" {
+		t.Errorf("ListByFid(\"\") = %+v, want INVALID_FID", resp)
+	}
+}
+
+func TestListTree(t *testing.T) {
+	entry := func(fid, name string, dir bool) map[string]interface{} {
+		return map[string]interface{}{"fid": fid, "file_name": name, "dir": dir}
+	}
+	dirs := map[string][]map[string]interface{}{
+		"0":     {entry("a", "a", true), entry("b", "b", true), entry("f1", "f1.txt", false)},
+		"a":     {entry("a_sub", "sub", true), entry("f2", "f2.txt", false)},
+		"a_sub": {entry("f3", "f3.txt", false)},
+	}
+
+	t.Run("nested with depth limit", func(t *testing.T) {
+		client := createMockClient(t, fakeTreeServer(dirs, map[string]bool{"b": true}))
+		resp, err := client.ListTree("/", 2)
+		if err != nil || !resp.Success {
+			t.Fatalf("ListTree() = %+v, %v", resp, err)
+		}
+		if resp.Code != "PARTIAL_SUCCESS" || resp.Data["failed"] != 1 {
+			t.Errorf("ListTree() code = %s, failed = %v, want PARTIAL_SUCCESS, 1", resp.Code, resp.Data["failed"])
+		}
+		tree := resp.Data["tree"].(*TreeNode)
+		if tree.Fid != "0" || len(tree.Children) != 3 {
+			t.Fatalf("root = %+v, want fid 0 with 3 children", tree)
+		}
+		a, b, f1 := tree.Children[0], tree.Children[1], tree.Children[2]
+		if a.Path != "/a" || len(a.Children) != 2 {
+			t.Errorf("a = %+v, want /a with 2 children", a)
+		}
+		if sub := a.Children[0]; !sub.Truncated || sub.Children != nil {
+			t.Errorf("a/sub = %+v, want truncated without children", sub)
+		}
+		if b.Error == "" {
+			t.Errorf("b = %+v, want error", b)
+		}
+		if f1.IsDirectory || f1.Children != nil {
+			t.Errorf("f1 = %+v, want file without children", f1)
+		}
+		if resp.Data["total"] != 5 {
+			t.Errorf("total = %v, want 5", resp.Data["total"])
+		}
+	})
+}
+
+func TestDiskUsage(t *testing.T) {
+	entry := func(fid, name string, dir bool, size int64) map[string]interface{} {
+		return map[string]interface{}{"fid": fid, "file_name": name, "dir": dir, "size": size}
+	}
+	dirs := map[string][]map[string]interface{}{
+		"0":     {entry("a", "a", true, 0), entry("b", "b", true, 0), entry("f1", "f1.txt", false, 1)},
+		"a":     {entry("a_sub", "sub", true, 0), entry("f2", "f2.txt", false, 10)},
+		"a_sub": {entry("f3", "f3.txt", false, 100)},
+		"b":     {entry("f4", "f4.txt", false, 1000)},
+	}
+
+	t.Run("total", func(t *testing.T) {
+		client := createMockClient(t, fakeTreeServer(dirs, nil))
+		resp, err := client.DiskUsage("/", 0)
+		if err != nil || !resp.Success || resp.Code != "OK" {
+			t.Fatalf("DiskUsage() = %+v, %v", resp, err)
+		}
+		if resp.Data["size"] != int64(1111) || resp.Data["files"] != 4 || resp.Data["dirs"] != 3 {
+			t.Errorf("DiskUsage() data = %+v, want size 1111, files 4, dirs 3", resp.Data)
+		}
+		if _, ok := resp.Data["children"]; ok {
+			t.Errorf("DiskUsage() without grouping should not include children")
+		}
+	})
+
+	t.Run("grouped with failure", func(t *testing.T) {
+		client := createMockClient(t, fakeTreeServer(dirs, map[string]bool{"b": true}))
+		resp, err := client.DiskUsage("/", 1)
+		if err != nil || !resp.Success || resp.Code != "PARTIAL_SUCCESS" {
+			t.Fatalf("DiskUsage() = %+v, %v", resp, err)
+		}
+		if resp.Data["size"] != int64(111) || resp.Data["files"] != 3 {
+			t.Errorf("DiskUsage() data = %+v, want size 111, files 3", resp.Data)
+		}
+		groups := resp.Data["children"].([]DiskUsage)
+		want := []DiskUsage{
+			{Fid: "a", Path: "/a", Size: 110, Files: 2, Dirs: 1},
+			{Fid: "b", Path: "/b"},
+		}
+		if !reflect.DeepEqual(groups, want) {
+			t.Errorf("DiskUsage() children = %+v, want %+v", groups, want)
+		}
+		if failed := resp.Data["failed"].([]map[string]interface{}); len(failed) != 1 || failed[0]["path"] != "/b" {
+			t.Errorf("DiskUsage() failed = %+v, want /b", failed)
+		}
+	})
+}
+
+func TestSearch(t *testing.T) {
+	var gotQuery url.Values
+	client := createMockClient(t, func(req *http.Request) (*http.Response, error) {
+		gotQuery = req.URL.Query()
+		if req.URL.Path != FILE_SEARCH {
+			t.Errorf("request path = %s, want %s", req.URL.Path, FILE_SEARCH)
+		}
+		if gotQuery.Get("q") == "none" {
+			return jsonResponse(req, `{"status":200,"code":0,"data":{},"metadata":{"_total":0}}`), nil
+		}
+		return jsonResponse(req, `{"status":200,"code":0,"data":{"list":[
+			{"fid":"f1","file_name":"a b&c.txt","pdir_fid":"d1","size":3,"dir":false}
+		]},"metadata":{"_total":51}}`), nil
+	})
+
+	resp, err := client.Search(" a b&c ", 1, 50)
+	if err != nil || !resp.Success {
+		t.Fatalf("Search() = %+v, %v", resp, err)
+	}
+	if gotQuery.Get("q") != "a b&c" || gotQuery.Get("_page") != "1" || gotQuery.Get("_size") != "50" {
+		t.Errorf("Search() query = %v", gotQuery)
+	}
+	list := resp.Data["list"].([]QuarkFileInfo)
+	if len(list) != 1 || list[0].Fid != "f1" || list[0].PdirFid != "d1" {
+		t.Errorf("Search() list = %+v", list)
+	}
+	if resp.Data["total"] != 51 || resp.Data["has_more"] != true {
+		t.Errorf("Search() total = %v, has_more = %v, want 51, true", resp.Data["total"], resp.Data["has_more"])
+	}
+
+	resp, err = client.Search("none", 0, 0)
+	if err != nil || !resp.Success {
+		t.Fatalf("Search(none) = %+v, %v", resp, err)
+	}
+	if list := resp.Data["list"].([]QuarkFileInfo); len(list) != 0 || resp.Data["has_more"] != false {
+		t.Errorf("Search(none) data = %+v, want empty list", resp.Data)
+	}
+
+	if resp, _ := client.Search("  ", 1, 10); resp.Success || resp.Code != "INVALID_ARGS" {
+		t.Errorf("Search(\"  \") = %+v, want INVALID_ARGS", resp)
+	}
+}
+
+func TestWalk(t *testing.T) {
+	entry := func(fid, name string, dir bool) map[string]interface{} {
+		return map[string]interface{}{"fid": fid, "file_name": name, "dir": dir}
+	}
+	dirs := map[string][]map[string]interface{}{
+		"0":     {entry("a", "a", true), entry("b", "b", true), entry("f1", "f1.txt", false)},
+		"a":     {entry("a_sub", "sub", true), entry("f2", "f2.txt", false)},
+		"a_sub": {entry("f3", "f3.txt", false)},
+		"b":     {entry("f4", "f4.txt", false)},
+	}
+	client := createMockClient(t, fakeTreeServer(dirs, map[string]bool{"b": true}))
+
+	var paths []string
+	resp, err := client.Walk("/", 0, func(file QuarkFileInfo) error {
+		paths = append(paths, file.Path)
+		if file.Path == "/a/sub" {
+			return SkipDir
+		}
+		return nil
+	})
+	if err != nil || !resp.Success || resp.Code != "PARTIAL_SUCCESS" {
+		t.Fatalf("Walk() = %+v, %v", resp, err)
+	}
+	want := []string{"/a", "/a/sub", "/a/f2.txt", "/b", "/f1.txt"}
+	if !reflect.DeepEqual(paths, want) {
+		t.Errorf("Walk() visited %v, want %v", paths, want)
+	}
+	if resp.Data["visited"] != len(want) {
+		t.Errorf("Walk() visited = %v, want %d", resp.Data["visited"], len(want))
+	}
+
+	stop := fmt.Errorf("stop")
+	resp, err = client.Walk("/", 0, func(file QuarkFileInfo) error {
+		if file.Path == "/a/f2.txt" {
+			return stop
+		}
+		return nil
+	})
+	if err != stop || resp != nil {
+		t.Errorf("Walk() = %+v, %v, want callback error", resp, err)
+	}
+}
+
+func TestRecent(t *testing.T) {
+	entry := func(fid, name string, dir bool, updatedAt int64) map[string]interface{} {
+		return map[string]interface{}{"fid": fid, "file_name": name, "dir": dir, "updated_at": updatedAt}
+	}
+	dirs := map[string][]map[string]interface{}{
+		"0": {entry("a", "a", true, 9000), entry("f1", "f1.txt", false, 1000)},
+		"a": {entry("f2", "f2.txt", false, 3000), entry("f3", "f3.txt", false, 2000)},
+	}
+	client := createMockClient(t, fakeTreeServer(dirs, nil))
+
+	resp, err := client.Recent("/", 2)
+	if err != nil || !resp.Success {
+		t.Fatalf("Recent() = %+v, %v", resp, err)
+	}
+	list := resp.Data["list"].([]QuarkFileInfo)
+	paths := make([]string, 0, len(list))
+	for _, file := range list {
+		paths = append(paths, file.Path)
+	}
+	if want := []string{"/a/f2.txt", "/a/f3.txt"}; !reflect.DeepEqual(paths, want) {
+		t.Errorf("Recent() paths = %v, want %v", paths, want)
+	}
+	if list[0].PdirFid != "a" || resp.Data["scanned"] != 4 {
+		t.Errorf("Recent() pdir_fid = %s, scanned = %v, want a, 4", list[0].PdirFid, resp.Data["scanned"])
+	}
+}
+
+func TestListPages(t *testing.T) {
+	fn, requests := fakeListServer(2*LIST_PAGE_SIZE+10, nil)
+	client := createMockClient(t, fn)
+
+	var pageSizes []int
+	resp, err := client.ListPages("/", ListOptions{}, func(files []QuarkFileInfo) bool {
+		pageSizes = append(pageSizes, len(files))
+		return true
+	})
+	if err != nil || !resp.Success {
+		t.Fatalf("ListPages() = %+v, %v", resp, err)
+	}
+	if want := []int{LIST_PAGE_SIZE, LIST_PAGE_SIZE, 10}; !reflect.DeepEqual(pageSizes, want) {
+		t.Errorf("ListPages() page sizes = %v, want %v", pageSizes, want)
+	}
+	if resp.Data["visited"] != 2*LIST_PAGE_SIZE+10 || resp.Data["has_more"] != false {
+		t.Errorf("ListPages() visited = %v, has_more = %v", resp.Data["visited"], resp.Data["has_more"])
+	}
+	if _, ok := resp.Data["list"]; ok {
+		t.Errorf("ListPages() summary should not include list")
+	}
+
+	*requests = 0
+	resp, err = client.ListPages("/", ListOptions{}, func(files []QuarkFileInfo) bool {
+		return false
+	})
+	if err != nil || !resp.Success || *requests != 1 || resp.Data["has_more"] != true {
+		t.Errorf("ListPages() stop early = %+v, %v, requests = %d, want 1 request and has_more", resp, err, *requests)
+	}
+}
+
+func TestList_FallbackToLegacyEndpoint(t *testing.T) {
+	var sortRequests, legacyRequests int
+	client := createMockClient(t, func(req *http.Request) (*http.Response, error) {
+		switch req.URL.Path {
+		case FILE_SORT:
+			sortRequests++
+			return jsonResponse(req, `{"status":400,"code":31001,"message":"unsupported dir"}`), nil
+		case FILE_LIST_LEGACY:
+			legacyRequests++
+			if req.URL.Query().Get("pdir_fid") != "sub" || req.URL.Query().Get("_fetch_total") != "1" {
+				t.Errorf("legacy request query = %s", req.URL.RawQuery)
+			}
+			return jsonResponse(req, `{"status":200,"code":0,"data":{"list":[{"fid":"a","file_name":"a.txt","dir":false}]},"metadata":{"_total":1}}`), nil
+		}
+		return jsonResponse(req, `{"status":404,"code":404,"message":"not found"}`), nil
+	})
+
+	resp, err := client.ListByFid("sub", ListOptions{})
+	if err != nil || !resp.Success {
+		t.Fatalf("ListByFid() = %+v, %v", resp, err)
+	}
+	files, _ := resp.Data["list"].([]QuarkFileInfo)
+	if len(files) != 1 || files[0].Fid != "a" {
+		t.Errorf("ListByFid() list = %+v, want the legacy endpoint entries", files)
+	}
+	if sortRequests != 1 || legacyRequests != 1 {
+		t.Errorf("requests: sort = %d, legacy = %d, want 1 and 1", sortRequests, legacyRequests)
+	}
+}
+
+func TestListByFidPages(t *testing.T) {
+	dirs := map[string][]map[string]interface{}{
+		"docs": {{"fid": "a", "file_name": "a.txt", "dir": false}, {"fid": "sub", "file_name": "sub", "dir": true}},
+	}
+	client := createMockClient(t, fakeTreeServer(dirs, nil))
+
+	var paths []string
+	resp, err := client.ListByFidPages("docs", "/docs", ListOptions{}, func(files []QuarkFileInfo) bool {
+		for _, file := range files {
+			paths = append(paths, file.Path)
+		}
+		return true
+	})
+	if err != nil || !resp.Success {
+		t.Fatalf("ListByFidPages() = %+v, %v", resp, err)
+	}
+	if want := []string{"/docs/a.txt", "/docs/sub"}; !reflect.DeepEqual(paths, want) {
+		t.Errorf("ListByFidPages() paths = %v, want %v", paths, want)
+	}
+
+	resp, err = client.ListByFidPages("", "", ListOptions{}, func(files []QuarkFileInfo) bool { return true })
+	if err != nil || resp.Success || resp.Code != "INVALID_FID" {
+		t.Errorf("ListByFidPages(\"\") = %+v, %v, want INVALID_FID", resp, err)
+	}
+}
+
+func TestGetFileInfoWithOptions_IgnoreCase(t *testing.T) {
+	entry := func(fid, name string, dir bool) map[string]interface{} {
+		return map[string]interface{}{"fid": fid, "file_name": name, "dir": dir}
+	}
+	dirs := map[string][]map[string]interface{}{
+		"0":    {entry("docs", "Docs", true), entry("r1", "Readme.md", false), entry("r2", "README.md", false)},
+		"docs": {entry("rep", "Report.PDF", false), entry("sub", "Sub", true), entry("subf", "sub", false)},
+	}
+
+	tests := []struct {
+		name     string
+		path     string
+		opts     FileInfoOptions
+		wantFid  string
+		wantCode string
+	}{
+		{name: "case mismatch found", path: "/docs/report.pdf", opts: FileInfoOptions{IgnoreCase: true}, wantFid: "rep"},
+		{name: "case mismatch without option", path: "/docs/report.pdf", wantCode: "FILE_NOT_FOUND"},
+		{name: "exact case preferred", path: "/DOCS/Sub", opts: FileInfoOptions{IgnoreCase: true}, wantFid: "sub"},
+		{name: "ambiguous candidates", path: "/readme.md", opts: FileInfoOptions{IgnoreCase: true}, wantCode: "AMBIGUOUS_PATH"},
+		{name: "type preference on last segment", path: "/DOCS/SUB", opts: FileInfoOptions{IgnoreCase: true}, wantFid: "subf"},
+		{name: "prefer directory", path: "/DOCS/SUB", opts: FileInfoOptions{IgnoreCase: true, PreferDir: true}, wantFid: "sub"},
+		{name: "not found", path: "/docs/missing", opts: FileInfoOptions{IgnoreCase: true}, wantCode: "FILE_NOT_FOUND"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := createMockClient(t, fakeTreeServer(dirs, nil))
+			resp, err := client.GetFileInfoWithOptions(tt.path, tt.opts)
+			if err != nil {
+				t.Fatalf("GetFileInfoWithOptions() error = %v", err)
+			}
+			if tt.wantCode != "" {
+				if resp.Success || resp.Code != tt.wantCode {
+					t.Errorf("GetFileInfoWithOptions() = %+v, want code %s", resp, tt.wantCode)
+				}
+				return
+			}
+			if !resp.Success || resp.Data["fid"] != tt.wantFid {
+				t.Errorf("GetFileInfoWithOptions() = %+v, want fid %s", resp, tt.wantFid)
+			}
+		})
+	}
+}
+
+func TestDownloadDir(t *testing.T) {
+	entry := func(fid, name string, dir bool) map[string]interface{} {
+		return map[string]interface{}{"fid": fid, "file_name": name, "dir": dir, "size": len(fid), "updated_at": 1700000000000}
+	}
+	dirs := map[string][]map[string]interface{}{
+		"0":    {entry("docs", "docs", true)},
+		"docs": {entry("sub", "sub", true), entry("f1", "a.txt", false), entry("bad", "bad.txt", false)},
+		"sub":  {entry("f2", "b.txt", false), entry("empty", "empty", true)},
+	}
+
+	content := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/bad" {
+			http.Error(w, "gone", http.StatusGone)
+			return
+		}
+		fmt.Fprintf(w, "content of %s", strings.TrimPrefix(r.URL.Path, "/"))
+	}))
+	defer content.Close()
+
+	tree := fakeTreeServer(dirs, nil)
+	client := createMockClient(t, func(req *http.Request) (*http.Response, error) {
+		if req.URL.Path != FILE_DOWNLOAD {
+			return tree(req)
+		}
+		var body struct {
+			Fids []string `json:"fids"`
+		}
+		json.NewDecoder(req.Body).Decode(&body)
+		return jsonResponse(req, fmt.Sprintf(`{"status":200,"code":0,"data":[{"download_url":"%s/%s"}]}`, content.URL, body.Fids[0])), nil
+	})
+
+	localDir := t.TempDir()
+	var callbacks int
+	resp, err := client.DownloadDir("/docs", localDir, DownloadDirOptions{Workers: 2, OnFile: func(DownloadResult) { callbacks++ }})
+	if err != nil || !resp.Success || resp.Code != "PARTIAL_SUCCESS" {
+		t.Fatalf("DownloadDir() = %+v, %v", resp, err)
+	}
+	if resp.Data["files"] != 3 || resp.Data["dirs"] != 2 || callbacks != 3 {
+		t.Errorf("DownloadDir() files = %v, dirs = %v, callbacks = %d, want 3, 2, 3", resp.Data["files"], resp.Data["dirs"], callbacks)
+	}
+	if failed := resp.Data["failed"].([]DownloadResult); len(failed) != 1 || failed[0].Path != "/docs/bad.txt" {
+		t.Errorf("DownloadDir() failed = %+v, want only /docs/bad.txt", failed)
+	}
+
+	for localPath, want := range map[string]string{
+		"docs/a.txt":     "content of f1",
+		"docs/sub/b.txt": "content of f2",
+	} {
+		data, err := os.ReadFile(filepath.Join(localDir, localPath))
+		if err != nil || string(data) != want {
+			t.Errorf("%s = %q, %v, want %q", localPath, data, err, want)
+		}
+	}
+	if info, err := os.Stat(filepath.Join(localDir, "docs", "sub", "empty")); err != nil || !info.IsDir() {
+		t.Errorf("empty remote directory not created locally: %v", err)
+	}
+	// 文件和子目录的 mtime 为远端 updated_at
+	wantMtime := time.Unix(1700000000, 0)
+	for _, localPath := range []string{"docs/a.txt", "docs/sub/b.txt", "docs/sub"} {
+		info, err := os.Stat(filepath.Join(localDir, localPath))
+		if err != nil {
+			t.Fatalf("stat %s: %v", localPath, err)
+		}
+		if !info.ModTime().Equal(wantMtime) {
+			t.Errorf("%s mtime = %v, want %v", localPath, info.ModTime(), wantMtime)
+		}
+	}
+
+	noMtimeDir := t.TempDir()
+	client.DownloadDir("/docs", noMtimeDir, DownloadDirOptions{NoPreserveMtime: true})
+	if info, err := os.Stat(filepath.Join(noMtimeDir, "docs", "a.txt")); err != nil {
+		t.Errorf("NoPreserveMtime: stat a.txt: %v", err)
+	} else if info.ModTime().Equal(wantMtime) {
+		t.Errorf("NoPreserveMtime: a.txt mtime = %v, want download time", info.ModTime())
+	}
+
+	// 再次下载到同一目录时对每个文件分别应用冲突策略
+	resp, _ = client.DownloadDir("/docs", localDir, DownloadDirOptions{Conflict: DownloadConflictSkip})
+	if skipped := resp.Data["skipped"].([]DownloadResult); len(skipped) != 2 || !skipped[0].Skipped {
+		t.Errorf("Conflict skip: skipped = %+v, want a.txt and b.txt", skipped)
+	}
+	resp, _ = client.DownloadDir("/docs", localDir, DownloadDirOptions{Conflict: DownloadConflictFail})
+	if failed := resp.Data["failed"].([]DownloadResult); len(failed) != 3 {
+		t.Errorf("Conflict fail: failed = %+v, want 3 files", failed)
+	}
+	client.DownloadDir("/docs", localDir, DownloadDirOptions{Conflict: DownloadConflictRename})
+	if _, err := os.Stat(filepath.Join(localDir, "docs", "sub", "b (1).txt")); err != nil {
+		t.Errorf("Conflict rename: %v", err)
+	}
+}
+
+func TestDownloadLocalPath(t *testing.T) {
+	tests := []struct {
+		rootPath, remotePath string
+		want                 string
+		wantOK               bool
+	}{
+		{"/docs", "/docs/a.txt", filepath.Join("out", "a.txt"), true},
+		{"/docs", "/docs/sub/b.txt", filepath.Join("out", "sub", "b.txt"), true},
+		{"/", "/a.txt", filepath.Join("out", "a.txt"), true},
+		{"/docs", "/docs/..", "", false},
+		{"/docs", "/docs", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := downloadLocalPath("out", tt.rootPath, tt.remotePath)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("downloadLocalPath(%q, %q) = %q, %v, want %q, %v", tt.rootPath, tt.remotePath, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestDownloadFile_Resume(t *testing.T) {
+	content := strings.Repeat("0123456789", 100)
+	var ranges []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/expired":
+			http.Error(w, "signature expired", http.StatusForbidden)
+		case "/norange":
+			ranges = append(ranges, r.Header.Get("Range"))
+			io.WriteString(w, content)
+		default:
+			ranges = append(ranges, r.Header.Get("Range"))
+			w.Header().Set("ETag", `"v1"`)
+			http.ServeContent(w, r, "file.bin", time.Time{}, strings.NewReader(content))
+		}
+	}))
+	defer server.Close()
+
+	newClient := func(t *testing.T, urlPath string) (*QuarkClient, *int) {
+		urlRequests := 0
+		return createMockClient(t, func(req *http.Request) (*http.Response, error) {
+			urlRequests++
+			return jsonResponse(req, fmt.Sprintf(`{"status":200,"code":0,"data":[{"download_url":"%s%s"}]}`, server.URL, urlPath)), nil
+		}), &urlRequests
+	}
+
+	tests := []struct {
+		name        string
+		urlPath     string
+		meta        *downloadMeta
+		wantRange   string
+		wantURLReqs int
+	}{
+		{name: "fresh download", urlPath: "/file", wantRange: "", wantURLReqs: 1},
+		{name: "resume with saved url", urlPath: "/file", meta: &downloadMeta{Fid: "fid", URL: server.URL + "/file", Size: 1000, ETag: `"v1"`}, wantRange: "bytes=300-", wantURLReqs: 0},
+		{name: "expired url is refreshed", urlPath: "/file", meta: &downloadMeta{Fid: "fid", URL: server.URL + "/expired", Size: 1000, ETag: `"v1"`}, wantRange: "bytes=300-", wantURLReqs: 1},
+		{name: "changed etag restarts", urlPath: "/file", meta: &downloadMeta{Fid: "fid", URL: server.URL + "/file", Size: 1000, ETag: `"v0"`}, wantRange: "bytes=300-", wantURLReqs: 0},
+		{name: "other fid restarts", urlPath: "/file", meta: &downloadMeta{Fid: "other", URL: server.URL + "/file", Size: 1000}, wantRange: "", wantURLReqs: 1},
+		{name: "range not supported", urlPath: "/norange", meta: &downloadMeta{Fid: "fid", URL: server.URL + "/norange", Size: 1000}, wantRange: "bytes=300-", wantURLReqs: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ranges = nil
+			dest := filepath.Join(t.TempDir(), "file.bin")
+			if tt.meta != nil {
+				// 模拟上次中断：.part 中已有前 300 字节（"other fid" 用错误内容验证会被覆盖）
+				partial := content[:300]
+				if tt.meta.Fid != "fid" {
+					partial = strings.Repeat("x", 300)
+				}
+				os.WriteFile(dest+DOWNLOAD_PART_SUFFIX, []byte(partial), 0644)
+				writeDownloadMeta(dest+DOWNLOAD_META_SUFFIX, tt.meta)
+			}
+
+			client, urlRequests := newClient(t, tt.urlPath)
+			if err := client.DownloadFile("fid", dest, "file.bin", nil); err != nil {
+				t.Fatalf("DownloadFile() error = %v", err)
+			}
+			if data, _ := os.ReadFile(dest); string(data) != content {
+				t.Errorf("downloaded %d bytes, content mismatch", len(data))
+			}
+			if len(ranges) == 0 || ranges[0] != tt.wantRange {
+				t.Errorf("first Range header = %v, want %q", ranges, tt.wantRange)
+			}
+			if *urlRequests != tt.wantURLReqs {
+				t.Errorf("GetDownloadURL called %d times, want %d", *urlRequests, tt.wantURLReqs)
+			}
+			for _, leftover := range []string{dest + DOWNLOAD_PART_SUFFIX, dest + DOWNLOAD_META_SUFFIX} {
+				if _, err := os.Stat(leftover); !os.IsNotExist(err) {
+					t.Errorf("%s should be removed after download", filepath.Base(leftover))
+				}
+			}
+		})
+	}
+}
+
+func TestDownloadFile_ResumeIfRange(t *testing.T) {
+	oldContent := strings.Repeat("a", 1000)
+	newContent := strings.Repeat("b", 1000)
+	modTime := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Header.Get("Range")+" "+r.Header.Get("If-Range"))
+		if r.URL.Path == "/etag" {
+			w.Header().Set("ETag", `"v2"`)
+		}
+		// 远端文件已被替换：If-Range 不匹配时 ServeContent 返回 200 和完整的新内容
+		http.ServeContent(w, r, "file.bin", modTime, strings.NewReader(newContent))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name         string
+		urlPath      string
+		meta         downloadMeta
+		wantRequests []string
+	}{
+		{name: "etag changed", urlPath: "/etag",
+			meta:         downloadMeta{Fid: "fid", Size: 1000, ETag: `"v1"`},
+			wantRequests: []string{`bytes=300- "v1"`}},
+		{name: "last-modified changed", urlPath: "/plain",
+			meta:         downloadMeta{Fid: "fid", Size: 1000, LastModified: modTime.Add(-time.Hour).Format(http.TimeFormat)},
+			wantRequests: []string{"bytes=300- " + modTime.Add(-time.Hour).Format(http.TimeFormat)}},
+		// 弱 ETag 不能用于 If-Range，改用 Last-Modified；服务端返回 206 但 ETag 与记录不一致时仍然重新下载
+		{name: "weak etag falls back to last-modified", urlPath: "/plain",
+			meta:         downloadMeta{Fid: "fid", Size: 1000, ETag: `W/"v1"`, LastModified: modTime.Format(http.TimeFormat)},
+			wantRequests: []string{"bytes=300- " + modTime.Format(http.TimeFormat), " "}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests = nil
+			dest := filepath.Join(t.TempDir(), "file.bin")
+			os.WriteFile(dest+DOWNLOAD_PART_SUFFIX, []byte(oldContent[:300]), 0644)
+			tt.meta.URL = server.URL + tt.urlPath
+			writeDownloadMeta(dest+DOWNLOAD_META_SUFFIX, &tt.meta)
+
+			client := createMockClient(t, func(req *http.Request) (*http.Response, error) {
+				return jsonResponse(req, fmt.Sprintf(`{"status":200,"code":0,"data":[{"download_url":"%s%s"}]}`, server.URL, tt.urlPath)), nil
+			})
+			if err := client.DownloadFile("fid", dest, "file.bin", nil); err != nil {
+				t.Fatalf("DownloadFile() error = %v", err)
+			}
+			if data, _ := os.ReadFile(dest); string(data) != newContent {
+				t.Errorf("downloaded content mixes old and new data")
+			}
+			if strings.Join(requests, ",") != strings.Join(tt.wantRequests, ",") {
+				t.Errorf("requests = %q, want %q", requests, tt.wantRequests)
+			}
+		})
+	}
+}
+
+func TestDownloadFile_RetryRefreshesURL(t *testing.T) {
+	content := strings.Repeat("0123456789", 100)
+	var ranges []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ranges = append(ranges, r.URL.Path+" "+r.Header.Get("Range"))
+		switch r.URL.Path {
+		case "/drop":
+			// 只发送前 300 字节后断开连接
+			w.Header().Set("ETag", `"v1"`)
+			w.Header().Set("Content-Length", "1000")
+			io.WriteString(w, content[:300])
+		case "/expired":
+			http.Error(w, "signature expired", http.StatusForbidden)
+		case "/missing":
+			http.NotFound(w, r)
+		default:
+			w.Header().Set("ETag", `"v1"`)
+			http.ServeContent(w, r, "file.bin", time.Time{}, strings.NewReader(content))
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name       string
+		urlPaths   []string // 每次获取下载链接依次返回的路径，用完后重复最后一个
+		retries    int
+		wantErr    string
+		wantRanges []string
+	}{
+		{name: "resume after connection drop", urlPaths: []string{"/drop", "/file"}, retries: 3,
+			wantRanges: []string{"/drop ", "/file bytes=300-"}},
+		{name: "expired url is refreshed", urlPaths: []string{"/expired", "/expired", "/file"}, retries: 3,
+			wantRanges: []string{"/expired ", "/expired ", "/file "}},
+		{name: "retries exhausted", urlPaths: []string{"/expired"}, retries: 2,
+			wantErr: "3 attempts: #1 status 403, #2 status 403, #3 status 403"},
+		{name: "no retry on 404", urlPaths: []string{"/missing"}, retries: 3,
+			wantErr: "status 404", wantRanges: []string{"/missing "}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ranges = nil
+			urlRequests := 0
+			client := createMockClient(t, func(req *http.Request) (*http.Response, error) {
+				urlPath := tt.urlPaths[len(tt.urlPaths)-1]
+				if urlRequests < len(tt.urlPaths) {
+					urlPath = tt.urlPaths[urlRequests]
+				}
+				urlRequests++
+				return jsonResponse(req, fmt.Sprintf(`{"status":200,"code":0,"data":[{"download_url":"%s%s"}]}`, server.URL, urlPath)), nil
+			})
+			client.SetDownloadRetries(tt.retries, time.Millisecond)
+
+			dest := filepath.Join(t.TempDir(), "file.bin")
+			err := client.DownloadFile("fid", dest, "file.bin", nil)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("DownloadFile() error = %v, want containing %q", err, tt.wantErr)
+				}
+				if urlRequests != tt.retries+1 && tt.wantRanges == nil {
+					t.Errorf("GetDownloadURL called %d times, want %d", urlRequests, tt.retries+1)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("DownloadFile() error = %v", err)
+				}
+				if data, _ := os.ReadFile(dest); string(data) != content {
+					t.Errorf("downloaded %d bytes, content mismatch", len(data))
+				}
+			}
+			if tt.wantRanges != nil && strings.Join(ranges, ",") != strings.Join(tt.wantRanges, ",") {
+				t.Errorf("requests = %q, want %q", ranges, tt.wantRanges)
+			}
+		})
+	}
+}
+
+func TestDownloadFileToPath_Conflict(t *testing.T) {
+	content := "new content"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/short" {
+			// 声明的长度大于实际发送的内容，模拟下载中途断开
+			w.Header().Set("Content-Length", "1000")
+			io.WriteString(w, content)
+			return
+		}
+		io.WriteString(w, content)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		urlPath  string
+		conflict DownloadConflictPolicy
+		wantErr  error
+		wantPath string
+		wantOld  string // 原目标文件的内容
+	}{
+		{name: "overwrite", urlPath: "/file", conflict: DownloadConflictOverwrite, wantPath: "file.bin", wantOld: content},
+		{name: "skip", urlPath: "/file", conflict: DownloadConflictSkip, wantErr: ErrDownloadSkipped, wantPath: "file.bin", wantOld: "old"},
+		{name: "rename", urlPath: "/file", conflict: DownloadConflictRename, wantPath: "file (1).bin", wantOld: "old"},
+		{name: "fail", urlPath: "/file", conflict: DownloadConflictFail, wantErr: ErrDownloadExists, wantPath: "file.bin", wantOld: "old"},
+		{name: "interrupted keeps target", urlPath: "/short", wantOld: "old"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			dest := filepath.Join(dir, "file.bin")
+			os.WriteFile(dest, []byte("old"), 0644)
+			client := createMockClient(t, func(req *http.Request) (*http.Response, error) {
+				return jsonResponse(req, fmt.Sprintf(`{"status":200,"code":0,"data":[{"download_url":"%s%s"}]}`, server.URL, tt.urlPath)), nil
+			})
+			client.SetDownloadRetries(0, 0)
+
+			localPath, err := client.DownloadFileToPath("fid", dest, "file.bin", DownloadOptions{Conflict: tt.conflict})
+			if tt.wantPath == "" {
+				if err == nil {
+					t.Fatal("DownloadFileToPath() error = nil, want error")
+				}
+				if _, statErr := os.Stat(dest + DOWNLOAD_PART_SUFFIX); statErr != nil {
+					t.Errorf("temp file not kept for resume: %v", statErr)
+				}
+			} else {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("DownloadFileToPath() error = %v, want %v", err, tt.wantErr)
+				}
+				if localPath != filepath.Join(dir, tt.wantPath) {
+					t.Errorf("DownloadFileToPath() path = %s, want %s", localPath, tt.wantPath)
+				}
+				if data, _ := os.ReadFile(localPath); tt.wantErr == nil && string(data) != content {
+					t.Errorf("%s = %q, want %q", tt.wantPath, data, content)
+				}
+			}
+			if data, _ := os.ReadFile(dest); string(data) != tt.wantOld {
+				t.Errorf("file.bin = %q, want %q", data, tt.wantOld)
+			}
+		})
+	}
+}
+
+func TestDownloadFileToPath_Verify(t *testing.T) {
+	content := "verified content"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, content)
+	}))
+	defer server.Close()
+	md5Sum := md5.Sum([]byte(content))
+	md5Hex := hex.EncodeToString(md5Sum[:])
+
+	tests := []struct {
+		name    string
+		extra   string // 下载接口条目中的额外字段
+		wantErr bool
+	}{
+		{name: "size only", extra: fmt.Sprintf(`"size":%d`, len(content))},
+		{name: "md5 hex", extra: fmt.Sprintf(`"size":%d,"md5":"%s"`, len(content), md5Hex)},
+		{name: "md5 base64", extra: fmt.Sprintf(`"size":%d,"md5":"%s"`, len(content), base64.StdEncoding.EncodeToString(md5Sum[:]))},
+		{name: "size mismatch", extra: `"size":1`, wantErr: true},
+		{name: "md5 mismatch", extra: fmt.Sprintf(`"size":%d,"md5":"%s"`, len(content), strings.Repeat("0", 32)), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := createMockClient(t, func(req *http.Request) (*http.Response, error) {
+				return jsonResponse(req, fmt.Sprintf(`{"status":200,"code":0,"data":[{"fid":"fid","file_name":"file.bin","download_url":"%s/file",%s}]}`, server.URL, tt.extra)), nil
+			})
+			dest := filepath.Join(t.TempDir(), "file.bin")
+			var phases []string
+			_, err := client.DownloadFileToPath("fid", dest, "file.bin", DownloadOptions{
+				Verify:   true,
+				Progress: func(p *DownloadProgress) { phases = append(phases, p.Phase) },
+			})
+			if tt.wantErr {
+				if !errors.Is(err, ErrVerifyFailed) {
+					t.Fatalf("DownloadFileToPath() error = %v, want ErrVerifyFailed", err)
+				}
+				if _, statErr := os.Stat(dest + DOWNLOAD_PART_SUFFIX); !os.IsNotExist(statErr) {
+					t.Errorf("temp file not removed after failed verification: %v", statErr)
+				}
+				if _, statErr := os.Stat(dest); !os.IsNotExist(statErr) {
+					t.Errorf("target created after failed verification: %v", statErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("DownloadFileToPath() error = %v", err)
+			}
+			if data, _ := os.ReadFile(dest); string(data) != content {
+				t.Errorf("file.bin = %q, want %q", data, content)
+			}
+			if strings.Contains(tt.extra, "md5") && phases[len(phases)-1] != DownloadPhaseVerify {
+				t.Errorf("progress phases = %v, want verify phase last", phases)
+			}
+		})
+	}
+}
+
+func TestDownloadFileToPath_DiskSpace(t *testing.T) {
+	content := strings.Repeat("x", 1000)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, content)
+	}))
+	defer server.Close()
+	client := createMockClient(t, func(req *http.Request) (*http.Response, error) {
+		return jsonResponse(req, fmt.Sprintf(`{"status":200,"code":0,"data":[{"download_url":"%s/file"}]}`, server.URL)), nil
+	})
+
+	saved := diskFreeSpace
+	defer func() { diskFreeSpace = saved }()
+	diskFreeSpace = func(string) (int64, error) { return DOWNLOAD_DISK_SPACE_RESERVE + 999, nil }
+
+	dest := filepath.Join(t.TempDir(), "file.bin")
+	if _, err := client.DownloadFileToPath("fid", dest, "file.bin", DownloadOptions{}); !errors.Is(err, ErrInsufficientDiskSpace) {
+		t.Fatalf("DownloadFileToPath() error = %v, want ErrInsufficientDiskSpace", err)
+	}
+	if _, err := os.Stat(dest + DOWNLOAD_PART_SUFFIX); !os.IsNotExist(err) {
+		t.Errorf("temp file created despite insufficient space: %v", err)
+	}
+
+	if _, err := client.DownloadFileToPath("fid", dest, "file.bin", DownloadOptions{NoSpaceCheck: true}); err != nil {
+		t.Fatalf("DownloadFileToPath(NoSpaceCheck) error = %v", err)
+	}
+
+	diskFreeSpace = func(string) (int64, error) { return DOWNLOAD_DISK_SPACE_RESERVE + 1000, nil }
+	os.Remove(dest)
+	if _, err := client.DownloadFileToPath("fid", dest, "file.bin", DownloadOptions{}); err != nil {
+		t.Fatalf("DownloadFileToPath() with enough space error = %v", err)
+	}
+}
+
+func TestDownloadFileWithOptions_Segmented(t *testing.T) {
+	content := strings.Repeat("abcdefghij", MIN_DOWNLOAD_SEGMENT_SIZE/4+3)
+	var mu sync.Mutex
+	var ranges []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		ranges = append(ranges, r.Header.Get("Range"))
+		mu.Unlock()
+		if r.URL.Path == "/norange" {
+			w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+			io.WriteString(w, content)
+			return
+		}
+		http.ServeContent(w, r, "file.bin", time.Time{}, strings.NewReader(content))
+	}))
+	defer server.Close()
+
+	for _, tt := range []struct {
+		urlPath    string
+		wantRanges int
+	}{
+		// 探测请求 + 3 段（文件约 2.5MB，每段至少 1MB）
+		{urlPath: "/file", wantRanges: 4},
+		// 探测返回 200 后退回单连接：探测 + 完整下载
+		{urlPath: "/norange", wantRanges: 2},
+	} {
+		t.Run(tt.urlPath, func(t *testing.T) {
+			ranges = nil
+			client := createMockClient(t, func(req *http.Request) (*http.Response, error) {
+				return jsonResponse(req, fmt.Sprintf(`{"status":200,"code":0,"data":[{"download_url":"%s%s"}]}`, server.URL, tt.urlPath)), nil
+			})
+			dest := filepath.Join(t.TempDir(), "file.bin")
+			var last DownloadProgress
+			err := client.DownloadFileWithOptions("fid", dest, "file.bin", DownloadOptions{
+				Connections: 4,
+				Progress:    func(p *DownloadProgress) { last = *p },
+			})
+			if err != nil {
+				t.Fatalf("DownloadFileWithOptions() error = %v", err)
+			}
+			if data, _ := os.ReadFile(dest); string(data) != content {
+				t.Errorf("downloaded %d bytes, want %d with identical content", len(data), len(content))
+			}
+			if len(ranges) != tt.wantRanges {
+				t.Errorf("sent %d requests (%v), want %d", len(ranges), ranges, tt.wantRanges)
+			}
+			if last.Downloaded != int64(len(content)) || last.Total != int64(len(content)) {
+				t.Errorf("last progress = %+v, want %d/%d", last, len(content), len(content))
+			}
+			if _, err := os.Stat(dest + DOWNLOAD_PART_SUFFIX); !os.IsNotExist(err) {
+				t.Errorf(".part should be removed after download")
+			}
+		})
+	}
+}
+
+func TestGetDownloadInfo(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		wantName string
+		wantErr  error
+	}{
+		{
+			name:     "file",
+			body:     `{"status":200,"code":0,"data":[{"fid":"f1","file_name":"report.pdf","size":42,"dir":false,"download_url":"https://dl.example/f1"}]}`,
+			wantName: "report.pdf",
+		},
+		{
+			name:    "directory",
+			body:    `{"status":200,"code":0,"data":[{"fid":"d1","file_name":"docs","dir":true}]}`,
+			wantErr: ErrDownloadDirectory,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := createMockClient(t, func(req *http.Request) (*http.Response, error) {
+				return jsonResponse(req, tt.body), nil
+			})
+			info, err := client.GetDownloadInfo("f1")
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("GetDownloadInfo() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || info.FileName != tt.wantName || info.Size != 42 || info.DownloadURL != "https://dl.example/f1" {
+				t.Errorf("GetDownloadInfo() = %+v, %v", info, err)
+			}
+		})
+	}
+}
+
+func TestGetFileHash(t *testing.T) {
+	tests := []struct {
+		name     string
+		item     string
+		wantMD5  string
+		wantSHA1 string
+		wantErr  error
+	}{
+		{
+			name:     "hex",
+			item:     `"md5":"5D41402ABC4B2A76B9719D911017C592","sha1":"aaf4c61ddcc5e8a2dabede0f3b482cd9aea9434d"`,
+			wantMD5:  "5d41402abc4b2a76b9719d911017c592",
+			wantSHA1: "aaf4c61ddcc5e8a2dabede0f3b482cd9aea9434d",
+		},
+		{
+			name:    "base64 md5 only",
+			item:    `"md5":"XUFAKrxLKna5cZ2REBfFkg=="`,
+			wantMD5: "5d41402abc4b2a76b9719d911017c592",
+		},
+		{
+			name:    "missing",
+			item:    `"md5":""`,
+			wantErr: ErrHashUnavailable,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := createMockClient(t, func(req *http.Request) (*http.Response, error) {
+				return jsonResponse(req, `{"status":200,"code":0,"data":[{"fid":"f1","file_name":"hello.txt","size":5,"download_url":"https://dl.example/f1",`+tt.item+`}]}`), nil
+			})
+			gotMD5, gotSHA1, err := client.GetFileHash("f1")
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("GetFileHash() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || gotMD5 != tt.wantMD5 || gotSHA1 != tt.wantSHA1 {
+				t.Errorf("GetFileHash() = %q, %q, %v", gotMD5, gotSHA1, err)
+			}
+		})
+	}
+}
+
+func TestComputeFileHash(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "hello")
+	}))
+	defer server.Close()
+	client := createMockClient(t, func(req *http.Request) (*http.Response, error) {
+		return jsonResponse(req, fmt.Sprintf(`{"status":200,"code":0,"data":[{"fid":"f1","file_name":"hello.txt","download_url":"%s/f1"}]}`, server.URL)), nil
+	})
+
+	gotMD5, gotSHA1, err := client.ComputeFileHash("f1", DownloadOptions{})
+	if err != nil {
+		t.Fatalf("ComputeFileHash() error = %v", err)
+	}
+	if gotMD5 != "5d41402abc4b2a76b9719d911017c592" || gotSHA1 != "aaf4c61ddcc5e8a2dabede0f3b482cd9aea9434d" {
+		t.Errorf("ComputeFileHash() = %q, %q", gotMD5, gotSHA1)
+	}
+}
+
+func TestDownloadToWriter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "line 1\nline 2\n")
+	}))
+	defer server.Close()
+	client := createMockClient(t, func(req *http.Request) (*http.Response, error) {
+		return jsonResponse(req, fmt.Sprintf(`{"status":200,"code":0,"data":[{"fid":"f1","file_name":"todo.txt","download_url":"%s/f1"}]}`, server.URL)), nil
+	})
+
+	var out strings.Builder
+	var last DownloadProgress
+	if err := client.DownloadToWriter("f1", &out, func(p *DownloadProgress) { last = *p }); err != nil {
+		t.Fatalf("DownloadToWriter() error = %v", err)
+	}
+	if out.String() != "line 1\nline 2\n" || last.Downloaded != 14 {
+		t.Errorf("DownloadToWriter() wrote %q, progress %+v", out.String(), last)
+	}
+}
+
+func TestDownloadToWriter_Retry(t *testing.T) {
+	content := strings.Repeat("0123456789", 100)
+	var ranges []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ranges = append(ranges, r.URL.Path+" "+r.Header.Get("Range"))
+		switch r.URL.Path {
+		case "/drop":
+			// 只发送前 300 字节后断开连接
+			w.Header().Set("Content-Length", "1000")
+			io.WriteString(w, content[:300])
+		case "/norange":
+			io.WriteString(w, content)
+		default:
+			http.ServeContent(w, r, "file.bin", time.Time{}, strings.NewReader(content))
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name       string
+		retryPath  string
+		wantRanges []string
+	}{
+		{name: "resume with range", retryPath: "/file", wantRanges: []string{"/drop ", "/file bytes=300-"}},
+		{name: "range not supported skips written bytes", retryPath: "/norange", wantRanges: []string{"/drop ", "/norange bytes=300-"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ranges = nil
+			urlPaths := []string{"/drop", tt.retryPath}
+			urlRequests := 0
+			client := createMockClient(t, func(req *http.Request) (*http.Response, error) {
+				urlPath := urlPaths[urlRequests]
+				urlRequests++
+				return jsonResponse(req, fmt.Sprintf(`{"status":200,"code":0,"data":[{"download_url":"%s%s"}]}`, server.URL, urlPath)), nil
+			})
+			client.SetDownloadRetries(1, time.Millisecond)
+
+			var out strings.Builder
+			var last DownloadProgress
+			err := client.DownloadToWriterWithOptions("fid", &out, DownloadOptions{Progress: func(p *DownloadProgress) { last = *p }})
+			if err != nil {
+				t.Fatalf("DownloadToWriterWithOptions() error = %v", err)
+			}
+			if out.String() != content {
+				t.Errorf("wrote %d bytes, content mismatch", out.Len())
+			}
+			if last.Downloaded != 1000 || last.Total != 1000 {
+				t.Errorf("last progress = %+v", last)
+			}
+			if strings.Join(ranges, ",") != strings.Join(tt.wantRanges, ",") {
+				t.Errorf("requests = %q, want %q", ranges, tt.wantRanges)
+			}
+		})
+	}
+}
+
+func TestDownloadHeaders(t *testing.T) {
+	var got http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+		io.WriteString(w, "data")
+	}))
+	defer server.Close()
+	client := createMockClient(t, func(req *http.Request) (*http.Response, error) {
+		return jsonResponse(req, fmt.Sprintf(`{"status":200,"code":0,"data":[{"fid":"f1","file_name":"a.txt","download_url":"%s/f1"}]}`, server.URL)), nil
+	})
+
+	headers := client.DownloadHeaders()
+	if headers["Cookie"] != "test_token=value1; test_token2=value2" {
+		t.Errorf("Cookie = %q", headers["Cookie"])
+	}
+	if headers["User-Agent"] == "" || headers["Referer"] == "" {
+		t.Errorf("missing User-Agent or Referer: %v", headers)
+	}
+
+	// 实际下载请求使用同一组请求头，外部下载器照此设置即可
+	if err := client.DownloadToWriter("f1", io.Discard, nil); err != nil {
+		t.Fatalf("DownloadToWriter() error = %v", err)
+	}
+	for name, value := range headers {
+		if got.Get(name) != value {
+			t.Errorf("download request %s = %q, want %q", name, got.Get(name), value)
+		}
+	}
+}
+
+func TestUploadFile_PreserveMtime(t *testing.T) {
+	isolateUploadState(t)
+
+	localPath := filepath.Join(t.TempDir(), "a.txt")
+	if err := os.WriteFile(localPath, []byte("hello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	mtime := time.Date(2023, 5, 6, 7, 8, 9, 123e6, time.UTC)
+	if err := os.Chtimes(localPath, mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, noPreserve := range []bool{false, true} {
+		t.Run(fmt.Sprintf("no_preserve=%t", noPreserve), func(t *testing.T) {
+			server := &fakeUploadServer{partSize: 1024}
+			var pre map[string]interface{}
+			roundTrip := server.roundTrip(t)
+			client := createMockClient(t, func(req *http.Request) (*http.Response, error) {
+				if strings.HasSuffix(req.URL.Path, FILE_UPLOAD_PRE) {
+					body, _ := io.ReadAll(req.Body)
+					json.Unmarshal(body, &pre)
+				}
+				return roundTrip(req)
+			})
+			start := time.Now().UnixMilli()
+			resp, err := client.UploadFile(localPath, "/a.txt", nil, &UploadOptions{NoPreserveMtime: noPreserve})
+			if err != nil || !resp.Success {
+				t.Fatalf("UploadFile() = %+v, %v", resp, err)
+			}
+			updatedAt, _ := pre["l_updated_at"].(float64)
+			createdAt, _ := pre["l_created_at"].(float64)
+			if noPreserve {
+				if int64(updatedAt) < start || int64(createdAt) < start {
+					t.Errorf("l_created_at = %.0f, l_updated_at = %.0f, want upload time >= %d", createdAt, updatedAt, start)
+				}
+				return
+			}
+			if int64(updatedAt) != mtime.UnixMilli() {
+				t.Errorf("l_updated_at = %.0f, want local mtime %d", updatedAt, mtime.UnixMilli())
+			}
+			if createdAt <= 0 {
+				t.Errorf("l_created_at = %.0f, want local creation time", createdAt)
+			}
+		})
+	}
+}
+
+func TestParseListItem_LocalTimes(t *testing.T) {
+	tests := []struct {
+		name       string
+		item       map[string]interface{}
+		wantCreate int64
+		wantModify int64
+	}{
+		{
+			name:       "local times preferred",
+			item:       map[string]interface{}{"created_at": float64(1700000000000), "updated_at": float64(1700000500000), "l_created_at": float64(1600000000000), "l_updated_at": float64(1600000500000)},
+			wantCreate: 1600000000,
+			wantModify: 1600000500,
+		},
+		{
+			name:       "server times without local times",
+			item:       map[string]interface{}{"created_at": float64(1700000000000), "updated_at": float64(1700000500000)},
+			wantCreate: 1700000000,
+			wantModify: 1700000500,
+		},
+		{
+			name:       "zero local times ignored",
+			item:       map[string]interface{}{"updated_at": int64(1700000500000), "l_updated_at": float64(0)},
+			wantModify: 1700000500,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := parseListItem(tt.item, "/")
+			if info.CreateTime != tt.wantCreate || info.ModifyTime != tt.wantModify {
+				t.Errorf("ctime, mtime = %d, %d, want %d, %d", info.CreateTime, info.ModifyTime, tt.wantCreate, tt.wantModify)
+			}
+		})
+	}
+}
+
+func TestUploadFile_Progress(t *testing.T) {
+	isolateUploadState(t)
+
+	content := bytes.Repeat([]byte("x"), 2500) // 3 个分片，最后一片 452 字节
+	localPath := filepath.Join(t.TempDir(), "p.bin")
+	if err := os.WriteFile(localPath, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("uploaded bytes", func(t *testing.T) {
+		client := (&fakeUploadServer{partSize: 1024}).client(t)
+		var uploaded []int64
+		var last UploadProgress
+		resp, err := client.UploadFile(localPath, "/p.bin", func(p *UploadProgress) {
+			uploaded = append(uploaded, p.Uploaded)
+			last = *p
+		}, nil)
+		if err != nil || !resp.Success {
+			t.Fatalf("UploadFile() = %+v, %v", resp, err)
+		}
+		if fmt.Sprint(uploaded) != "[1024 2048 2500]" {
+			t.Errorf("progress uploaded = %v, want [1024 2048 2500]", uploaded)
+		}
+		if last.Progress != 100 || last.Total != 2500 || last.Rapid {
+			t.Errorf("last progress = %+v, want 100%% of 2500 bytes, not rapid", last)
+		}
+	})
+
+	t.Run("rapid", func(t *testing.T) {
+		client := (&fakeUploadServer{partSize: 1024, hashFinish: true}).client(t)
+		var last UploadProgress
+		resp, err := client.UploadFile(localPath, "/p.bin", func(p *UploadProgress) { last = *p }, nil)
+		if err != nil || !resp.Success {
+			t.Fatalf("UploadFile() = %+v, %v", resp, err)
+		}
+		if !last.Rapid || last.Progress != 100 || last.Uploaded != 2500 || last.Speed != 0 {
+			t.Errorf("last progress = %+v, want rapid with 2500/2500 bytes", last)
+		}
+	})
+}
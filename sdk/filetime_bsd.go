@@ -0,0 +1,17 @@
+//go:build darwin || freebsd
+
+package sdk
+
+import (
+	"os"
+	"syscall"
+	"time"
+)
+
+// fileCreateTime 返回文件的创建时间（stat 的 birthtime），取不到时返回修改时间
+func fileCreateTime(info os.FileInfo) time.Time {
+	if st, ok := info.Sys().(*syscall.Stat_t); ok && st.Birthtimespec.Sec > 0 {
+		return time.Unix(int64(st.Birthtimespec.Sec), int64(st.Birthtimespec.Nsec))
+	}
+	return info.ModTime()
+}
@@ -0,0 +1,13 @@
+//go:build !darwin && !freebsd && !windows
+
+package sdk
+
+import (
+	"os"
+	"time"
+)
+
+// fileCreateTime 当前平台的 stat 不提供创建时间（Linux 的 ctime 是状态变更时间），返回修改时间
+func fileCreateTime(info os.FileInfo) time.Time {
+	return info.ModTime()
+}
@@ -0,0 +1,17 @@
+//go:build windows
+
+package sdk
+
+import (
+	"os"
+	"syscall"
+	"time"
+)
+
+// fileCreateTime 返回文件的创建时间（CreationTime），取不到时返回修改时间
+func fileCreateTime(info os.FileInfo) time.Time {
+	if data, ok := info.Sys().(*syscall.Win32FileAttributeData); ok {
+		return time.Unix(0, data.CreationTime.Nanoseconds())
+	}
+	return info.ModTime()
+}
@@ -0,0 +1,136 @@
+package sdk
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"encoding/hex"
+	"hash"
+	"io"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// parallelHasher 同时计算 md5 和 sha1 的 io.Writer：数据攒成 HASH_CHUNK_SIZE 的块后分发给 md5、sha1 各自的 goroutine，
+// 读取方、md5、sha1 三者并行，大文件的哈希时间约等于单独计算较慢的 sha1，而不是两者之和。
+// GOMAXPROCS 为 1 时并行没有收益，块直接在写入方依次计算。
+// 块缓冲来自分片缓冲池，两个哈希都处理完后放回；用完（包括出错时）必须调用 Sum 结束 goroutine
+type parallelHasher struct {
+	md5, sha1     hash.Hash
+	md5Ch, sha1Ch chan *hashChunk // 单核时为 nil
+	wg            sync.WaitGroup
+	buf           []byte // 正在攒的块，nil 表示还没取缓冲
+	n             int    // buf 中已写入的字节数
+	done          bool
+}
+
+// hashChunk 分发给两个哈希 goroutine 的数据块，refs 归零时放回缓冲池
+type hashChunk struct {
+	data []byte
+	refs atomic.Int32
+}
+
+func newParallelHasher() *parallelHasher {
+	h := &parallelHasher{md5: md5.New(), sha1: sha1.New()}
+	if runtime.GOMAXPROCS(0) < 2 {
+		return h
+	}
+	h.md5Ch = make(chan *hashChunk, HASH_PIPELINE_DEPTH)
+	h.sha1Ch = make(chan *hashChunk, HASH_PIPELINE_DEPTH)
+	h.wg.Add(2)
+	go h.consume(h.md5, h.md5Ch)
+	go h.consume(h.sha1, h.sha1Ch)
+	return h
+}
+
+func (h *parallelHasher) consume(sum hash.Hash, chunks <-chan *hashChunk) {
+	defer h.wg.Done()
+	for chunk := range chunks {
+		sum.Write(chunk.data)
+		if chunk.refs.Add(-1) == 0 {
+			putPartBuffer(chunk.data)
+		}
+	}
+}
+
+// Write 复制 p 到当前块，块满时分发；调用方可以立即复用 p
+func (h *parallelHasher) Write(p []byte) (int, error) {
+	if h.md5Ch == nil {
+		if h.n > 0 {
+			h.dispatch()
+		}
+		h.md5.Write(p)
+		h.sha1.Write(p)
+		return len(p), nil
+	}
+	written := len(p)
+	for len(p) > 0 {
+		if h.buf == nil {
+			h.buf = getPartBuffer(HASH_CHUNK_SIZE)
+		}
+		copied := copy(h.buf[h.n:], p)
+		h.n += copied
+		p = p[copied:]
+		if h.n == len(h.buf) {
+			h.dispatch()
+		}
+	}
+	return written, nil
+}
+
+// ReadFrom 直接读入块缓冲（io.Copy 优先使用），省去 Write 的一次复制，每次读取最多 HASH_CHUNK_SIZE 字节
+func (h *parallelHasher) ReadFrom(r io.Reader) (int64, error) {
+	var total int64
+	for {
+		if h.buf == nil {
+			h.buf = getPartBuffer(HASH_CHUNK_SIZE)
+		}
+		n, err := io.ReadFull(r, h.buf[h.n:])
+		h.n += n
+		total += int64(n)
+		if h.n == len(h.buf) {
+			h.dispatch()
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return total, nil
+		}
+		if err != nil {
+			return total, err
+		}
+	}
+}
+
+// dispatch 把当前块交给两个哈希 goroutine
+func (h *parallelHasher) dispatch() {
+	if h.md5Ch == nil {
+		h.md5.Write(h.buf[:h.n])
+		h.sha1.Write(h.buf[:h.n])
+		h.n = 0
+		return
+	}
+	chunk := &hashChunk{data: h.buf[:h.n]}
+	chunk.refs.Store(2)
+	h.buf, h.n = nil, 0
+	h.md5Ch <- chunk
+	h.sha1Ch <- chunk
+}
+
+// Sum 分发剩余数据，等待两个哈希完成并返回小写十六进制的 md5 和 sha1；之后不能再写入
+func (h *parallelHasher) Sum() (md5Sum, sha1Sum string) {
+	if !h.done {
+		h.done = true
+		if h.n > 0 {
+			h.dispatch()
+		}
+		if h.buf != nil {
+			putPartBuffer(h.buf)
+			h.buf = nil
+		}
+		if h.md5Ch != nil {
+			close(h.md5Ch)
+			close(h.sha1Ch)
+			h.wg.Wait()
+		}
+	}
+	return hex.EncodeToString(h.md5.Sum(nil)), hex.EncodeToString(h.sha1.Sum(nil))
+}
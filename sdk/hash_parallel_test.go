@@ -0,0 +1,68 @@
+package sdk
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"testing"
+	"testing/iotest"
+)
+
+func TestParallelHasher(t *testing.T) {
+	for _, size := range []int{0, 1, HASH_CHUNK_SIZE - 1, HASH_CHUNK_SIZE, 2*HASH_CHUNK_SIZE + 17} {
+		data := make([]byte, size)
+		for i := range data {
+			data[i] = byte(i * 7)
+		}
+		wantMD5 := fmt.Sprintf("%x", md5.Sum(data))
+		wantSHA1 := fmt.Sprintf("%x", sha1.Sum(data))
+
+		// Write：按不规则的大小分多次写入，每次写完立即改写调用方的缓冲
+		writer := newParallelHasher()
+		buf := make([]byte, 100003)
+		for off := 0; off < size; off += len(buf) {
+			n := copy(buf, data[off:])
+			writer.Write(buf[:n])
+			for i := range buf {
+				buf[i] = 0xff
+			}
+		}
+		if gotMD5, gotSHA1 := writer.Sum(); gotMD5 != wantMD5 || gotSHA1 != wantSHA1 {
+			t.Errorf("size %d: Write sums = %s %s, want %s %s", size, gotMD5, gotSHA1, wantMD5, wantSHA1)
+		}
+
+		// ReadFrom：短读的 reader
+		reader := newParallelHasher()
+		n, err := io.Copy(reader, iotest.HalfReader(bytes.NewReader(data)))
+		if err != nil || n != int64(size) {
+			t.Fatalf("size %d: io.Copy() = %d, %v", size, n, err)
+		}
+		if gotMD5, gotSHA1 := reader.Sum(); gotMD5 != wantMD5 || gotSHA1 != wantSHA1 {
+			t.Errorf("size %d: ReadFrom sums = %s %s, want %s %s", size, gotMD5, gotSHA1, wantMD5, wantSHA1)
+		}
+	}
+}
+
+// BenchmarkHashMD5SHA1 对比大文件整文件哈希：md5、sha1 串行共用一个 MultiWriter，与 parallelHasher 分别在独立 goroutine 中计算
+func BenchmarkHashMD5SHA1(b *testing.B) {
+	data := bytes.Repeat([]byte("0123456789abcdef"), 64<<20/16)
+	b.Run("multiwriter", func(b *testing.B) {
+		b.SetBytes(int64(len(data)))
+		for i := 0; i < b.N; i++ {
+			hashMD5, hashSHA1 := md5.New(), sha1.New()
+			io.Copy(io.MultiWriter(hashMD5, hashSHA1), bytes.NewReader(data))
+			_, _ = hex.EncodeToString(hashMD5.Sum(nil)), hex.EncodeToString(hashSHA1.Sum(nil))
+		}
+	})
+	b.Run("parallel", func(b *testing.B) {
+		b.SetBytes(int64(len(data)))
+		for i := 0; i < b.N; i++ {
+			hasher := newParallelHasher()
+			io.Copy(hasher, bytes.NewReader(data))
+			hasher.Sum()
+		}
+	})
+}
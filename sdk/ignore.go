@@ -0,0 +1,245 @@
+package sdk
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ignoreRule 单条 gitignore 风格规则
+type ignoreRule struct {
+	segments []string // 按 "/" 拆分后的模式段，支持 "**"
+	negate   bool     // "!" 开头，重新包含
+	dirOnly  bool     // "/" 结尾，只匹配目录
+	base     string   // 规则生效的相对目录（.kuakeignore 所在目录），空表示根目录
+	text     string   // 规则描述，如 "sub/.kuakeignore: *.bak"、"ignore *.log"
+}
+
+// IgnoreMatcher gitignore 风格的路径排除匹配器
+// 配置文件 sync.ignore、命令行 --exclude 和 .kuakeignore 文件共用同一套匹配逻辑
+// 规则按添加顺序生效，后添加的规则优先（与 gitignore 一致），因此越靠近文件的 .kuakeignore 优先级越高
+type IgnoreMatcher struct {
+	rules []ignoreRule
+}
+
+// NewIgnoreMatcher 根据模式列表创建匹配器，模式语法与 .gitignore 相同
+// 空行和 "#" 开头的行会被忽略
+func NewIgnoreMatcher(patterns ...string) (*IgnoreMatcher, error) {
+	m := &IgnoreMatcher{}
+	if err := m.add("", "", patterns); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// WithPatterns 返回追加了指定模式的新匹配器，规则相对 relDir 生效，原匹配器不受影响
+func (m *IgnoreMatcher) WithPatterns(relDir string, patterns ...string) (*IgnoreMatcher, error) {
+	child := &IgnoreMatcher{}
+	if m != nil {
+		child.rules = append(child.rules, m.rules...)
+	}
+	if err := child.add(relDir, "", patterns); err != nil {
+		return nil, err
+	}
+	return child, nil
+}
+
+// WithIgnoreFile 读取本地目录 dir 下的 .kuakeignore 文件，返回追加了其中规则的新匹配器
+// relDir 为 dir 相对遍历根目录的路径；文件不存在时直接返回原匹配器
+func (m *IgnoreMatcher) WithIgnoreFile(dir, relDir string) (*IgnoreMatcher, error) {
+	ignorePath := filepath.Join(dir, IGNORE_FILE_NAME)
+	patterns, err := ReadIgnoreFile(ignorePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return m, nil
+		}
+		return nil, err
+	}
+
+	child := &IgnoreMatcher{}
+	if m != nil {
+		child.rules = append(child.rules, m.rules...)
+	}
+	if err := child.add(relDir, path.Join(filepath.ToSlash(relDir), IGNORE_FILE_NAME), patterns); err != nil {
+		return nil, fmt.Errorf("%s: %w", ignorePath, err)
+	}
+	return child, nil
+}
+
+// ReadIgnoreFile 读取 gitignore 风格的规则文件，返回其中的每一行（空行和注释由匹配器忽略）；文件不存在时返回的错误满足 os.IsNotExist
+func ReadIgnoreFile(filePath string) ([]string, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to open %s: %w", filePath, err)
+	}
+	defer file.Close()
+
+	var patterns []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		patterns = append(patterns, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", filePath, err)
+	}
+	return patterns, nil
+}
+
+// Match 判断相对路径是否被排除，relPath 使用 "/" 或系统分隔符均可
+// 父目录被排除时其下所有内容都被排除（与 gitignore 一致，无法通过 "!" 重新包含）
+func (m *IgnoreMatcher) Match(relPath string, isDir bool) bool {
+	ignored, _ := m.MatchRule(relPath, isDir)
+	return ignored
+}
+
+// MatchRule 同 Match，另外返回决定结果的规则描述（如 "sub/.kuakeignore: *.bak"，来自 NewIgnoreMatcher/WithPatterns 的规则为 "ignore *.bak"），
+// 没有规则命中时为空
+func (m *IgnoreMatcher) MatchRule(relPath string, isDir bool) (bool, string) {
+	if m == nil || len(m.rules) == 0 {
+		return false, ""
+	}
+	relPath = strings.Trim(path.Clean(filepath.ToSlash(relPath)), "/")
+	if relPath == "" || relPath == "." {
+		return false, ""
+	}
+
+	segments := strings.Split(relPath, "/")
+	for i := 1; i < len(segments); i++ {
+		if ignored, rule := m.matchPath(segments[:i], true); ignored {
+			return true, rule
+		}
+	}
+	return m.matchPath(segments, isDir)
+}
+
+// matchPath 按规则顺序计算单个路径的匹配结果，最后一条命中的规则决定结果
+func (m *IgnoreMatcher) matchPath(segments []string, isDir bool) (bool, string) {
+	ignored, text := false, ""
+	for _, rule := range m.rules {
+		if rule.dirOnly && !isDir {
+			continue
+		}
+		rel, ok := trimBase(segments, rule.base)
+		if !ok {
+			continue
+		}
+		if matchSegments(rule.segments, rel) {
+			ignored, text = !rule.negate, rule.text
+		}
+	}
+	return ignored, text
+}
+
+// add 解析并追加模式，source 为规则来源的文件（相对遍历根目录），空表示配置或命令行
+func (m *IgnoreMatcher) add(base, source string, patterns []string) error {
+	base = strings.Trim(path.Clean(filepath.ToSlash(base)), "/")
+	if base == "." {
+		base = ""
+	}
+	for _, pattern := range patterns {
+		rule, ok, err := parseIgnorePattern(pattern)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		rule.base = base
+		rule.text = "ignore " + strings.TrimRight(pattern, " \t\r")
+		if source != "" {
+			rule.text = source + ": " + strings.TrimRight(pattern, " \t\r")
+		}
+		m.rules = append(m.rules, rule)
+	}
+	return nil
+}
+
+// ValidateIgnorePattern 校验单个 gitignore 风格模式的语法
+func ValidateIgnorePattern(pattern string) error {
+	_, _, err := parseIgnorePattern(pattern)
+	return err
+}
+
+// parseIgnorePattern 解析单个模式，ok=false 表示空行或注释
+func parseIgnorePattern(pattern string) (ignoreRule, bool, error) {
+	var rule ignoreRule
+	p := strings.TrimRight(pattern, " \t\r")
+	if p == "" || strings.HasPrefix(p, "#") {
+		return rule, false, nil
+	}
+	if strings.HasPrefix(p, "!") {
+		rule.negate = true
+		p = p[1:]
+	} else if strings.HasPrefix(p, `\!`) || strings.HasPrefix(p, `\#`) {
+		p = p[1:]
+	}
+	if strings.HasSuffix(p, "/") {
+		rule.dirOnly = true
+		p = strings.TrimRight(p, "/")
+	}
+	// 不含 "/" 的模式匹配任意层级的文件名；含 "/" 的模式相对规则所在目录锚定
+	anchored := strings.Contains(p, "/")
+	p = strings.TrimLeft(p, "/")
+	if p == "" {
+		return rule, false, fmt.Errorf("invalid ignore pattern %q", pattern)
+	}
+
+	rule.segments = strings.Split(p, "/")
+	if !anchored {
+		rule.segments = append([]string{"**"}, rule.segments...)
+	}
+	for _, seg := range rule.segments {
+		if seg == "**" {
+			continue
+		}
+		if _, err := path.Match(seg, ""); err != nil {
+			return rule, false, fmt.Errorf("invalid ignore pattern %q: %w", pattern, err)
+		}
+	}
+	return rule, true, nil
+}
+
+// trimBase 去掉规则所在目录前缀，路径不在该目录下时返回 false
+func trimBase(segments []string, base string) ([]string, bool) {
+	if base == "" {
+		return segments, true
+	}
+	baseSegs := strings.Split(base, "/")
+	if len(segments) <= len(baseSegs) {
+		return nil, false
+	}
+	for i, seg := range baseSegs {
+		if segments[i] != seg {
+			return nil, false
+		}
+	}
+	return segments[len(baseSegs):], true
+}
+
+// matchSegments 逐段匹配，"**" 匹配零个或多个路径段
+func matchSegments(pattern, segments []string) bool {
+	if len(pattern) == 0 {
+		return len(segments) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(segments); i++ {
+			if matchSegments(pattern[1:], segments[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(segments) == 0 {
+		return false
+	}
+	if ok, _ := path.Match(pattern[0], segments[0]); !ok {
+		return false
+	}
+	return matchSegments(pattern[1:], segments[1:])
+}
@@ -0,0 +1,199 @@
+package sdk
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestIgnoreMatcher_Match(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []string
+		path     string
+		isDir    bool
+		want     bool
+	}{
+		{name: "basename any level", patterns: []string{"*.log"}, path: "a/b/app.log", want: true},
+		{name: "basename no match", patterns: []string{"*.log"}, path: "a/b/app.txt", want: false},
+		{name: "dir only matches dir", patterns: []string{"node_modules/"}, path: "web/node_modules", isDir: true, want: true},
+		{name: "dir only skips file", patterns: []string{"build/"}, path: "build", isDir: false, want: false},
+		{name: "files under ignored dir", patterns: []string{"node_modules/"}, path: "web/node_modules/x/index.js", want: true},
+		{name: "anchored pattern", patterns: []string{"/dist"}, path: "dist", isDir: true, want: true},
+		{name: "anchored pattern not nested", patterns: []string{"/dist"}, path: "pkg/dist", isDir: true, want: false},
+		{name: "double star middle", patterns: []string{"docs/**/*.md"}, path: "docs/a/b/readme.md", want: true},
+		{name: "double star zero segments", patterns: []string{"docs/**/*.md"}, path: "docs/readme.md", want: true},
+		{name: "negation re-includes", patterns: []string{"*.log", "!keep.log"}, path: "keep.log", want: false},
+		{name: "later rule wins", patterns: []string{"!keep.log", "*.log"}, path: "keep.log", want: true},
+		{name: "negation cannot re-include under ignored dir", patterns: []string{"tmp/", "!tmp/keep.txt"}, path: "tmp/keep.txt", want: true},
+		{name: "comments and blanks", patterns: []string{"# comment", "", "*.tmp"}, path: "x.tmp", want: true},
+		{name: "no patterns", patterns: nil, path: "anything", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := NewIgnoreMatcher(tt.patterns...)
+			if err != nil {
+				t.Fatalf("NewIgnoreMatcher() error = %v", err)
+			}
+			if got := m.Match(tt.path, tt.isDir); got != tt.want {
+				t.Errorf("Match(%q, %v) = %v, want %v", tt.path, tt.isDir, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewIgnoreMatcher_InvalidPattern(t *testing.T) {
+	if _, err := NewIgnoreMatcher("[abc"); err == nil {
+		t.Error("NewIgnoreMatcher() expected error for invalid pattern")
+	}
+}
+
+func TestIgnoreMatcher_Layered(t *testing.T) {
+	root := t.TempDir()
+	sub := filepath.Join(root, "sub")
+	if err := os.MkdirAll(sub, 0755); err != nil {
+		t.Fatalf("MkdirAll() error = %v", err)
+	}
+	// 根目录排除所有 .log，子目录重新包含 keep.log 并额外排除 *.bak
+	if err := os.WriteFile(filepath.Join(root, IGNORE_FILE_NAME), []byte("*.log\n"), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(sub, IGNORE_FILE_NAME), []byte("!keep.log\n*.bak\n"), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	config := &Config{}
+	config.Sync.Ignore = []string{"*.tmp"}
+	base, err := config.IgnoreMatcher("secret.txt")
+	if err != nil {
+		t.Fatalf("IgnoreMatcher() error = %v", err)
+	}
+	rootMatcher, err := base.WithIgnoreFile(root, "")
+	if err != nil {
+		t.Fatalf("WithIgnoreFile(root) error = %v", err)
+	}
+	subMatcher, err := rootMatcher.WithIgnoreFile(sub, "sub")
+	if err != nil {
+		t.Fatalf("WithIgnoreFile(sub) error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		matcher *IgnoreMatcher
+		path    string
+		want    bool
+	}{
+		{name: "config pattern", matcher: subMatcher, path: "a.tmp", want: true},
+		{name: "exclude pattern", matcher: subMatcher, path: "sub/secret.txt", want: true},
+		{name: "root ignore file", matcher: subMatcher, path: "app.log", want: true},
+		{name: "nearest file re-includes", matcher: subMatcher, path: "sub/keep.log", want: false},
+		{name: "nearest file only applies below", matcher: subMatcher, path: "keep.log", want: true},
+		{name: "sub rule scoped to sub", matcher: subMatcher, path: "x.bak", want: false},
+		{name: "sub rule applies in sub", matcher: subMatcher, path: "sub/deep/x.bak", want: true},
+		{name: "parent matcher unaffected", matcher: rootMatcher, path: "sub/keep.log", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.matcher.Match(tt.path, false); got != tt.want {
+				t.Errorf("Match(%q) = %v, want %v", tt.path, got, tt.want)
+			}
+		})
+	}
+
+	// 目录中没有 .kuakeignore 时返回原匹配器
+	empty := t.TempDir()
+	same, err := base.WithIgnoreFile(empty, "")
+	if err != nil || same != base {
+		t.Errorf("WithIgnoreFile(empty) = %v, %v, want original matcher", same, err)
+	}
+}
+
+func TestIgnoreMatcher_MatchRule(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, IGNORE_FILE_NAME), []byte("build/\n"), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	base, err := NewIgnoreMatcher("*.log", "!keep.log")
+	if err != nil {
+		t.Fatalf("NewIgnoreMatcher() error = %v", err)
+	}
+	m, err := base.WithIgnoreFile(root, "")
+	if err != nil {
+		t.Fatalf("WithIgnoreFile() error = %v", err)
+	}
+	tests := []struct {
+		path     string
+		isDir    bool
+		want     bool
+		wantRule string
+	}{
+		{path: "app.log", want: true, wantRule: "ignore *.log"},
+		{path: "keep.log", want: false, wantRule: "ignore !keep.log"},
+		{path: "build", isDir: true, want: true, wantRule: ".kuakeignore: build/"},
+		{path: "build/out.bin", want: true, wantRule: ".kuakeignore: build/"},
+		{path: "main.go", want: false},
+	}
+	for _, tt := range tests {
+		got, rule := m.MatchRule(tt.path, tt.isDir)
+		if got != tt.want || rule != tt.wantRule {
+			t.Errorf("MatchRule(%q) = %v, %q, want %v, %q", tt.path, got, rule, tt.want, tt.wantRule)
+		}
+	}
+
+	if _, err := ReadIgnoreFile(filepath.Join(root, "missing")); !os.IsNotExist(err) {
+		t.Errorf("ReadIgnoreFile(missing) error = %v, want not exist", err)
+	}
+}
+
+func TestPlanDirUpload_IgnoreFile(t *testing.T) {
+	localDir := t.TempDir()
+	files := map[string]string{
+		IGNORE_FILE_NAME:          "*.log\nbuild/\n",
+		"app.log":                 "",
+		"main.go":                 "",
+		"build/out.bin":           "",
+		"sub/" + IGNORE_FILE_NAME: "!keep.log\n",
+		"sub/keep.log":            "",
+		"sub/other.log":           "",
+		"bad/" + IGNORE_FILE_NAME: "[abc\n",
+		"bad/x.log":               "",
+	}
+	for name, content := range files {
+		p := filepath.Join(localDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	base, err := NewIgnoreMatcher("*.tmp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	jobs, _, excluded, err := planDirUpload(localDir, "/r", UploadLinksSkip, base, nil)
+	if err != nil {
+		t.Fatalf("planDirUpload() error = %v", err)
+	}
+	var got, gotExcluded []string
+	for _, job := range jobs {
+		if job.Error != "" {
+			got = append(got, job.Path+":fail")
+			continue
+		}
+		got = append(got, job.Path)
+	}
+	for _, job := range excluded {
+		gotExcluded = append(gotExcluded, job.Path+":"+job.Rule)
+	}
+	// bad/.kuakeignore 语法错误：记为失败，其规则不生效，上级目录的规则仍然生效
+	if want := "[/r/.kuakeignore /r/bad/.kuakeignore:fail /r/main.go /r/sub/.kuakeignore /r/sub/keep.log]"; fmt.Sprint(got) != want {
+		t.Errorf("jobs = %v, want %s", got, want)
+	}
+	if want := "[/r/app.log:.kuakeignore: *.log /r/bad/x.log:.kuakeignore: *.log /r/build:.kuakeignore: build/ /r/sub/other.log:.kuakeignore: *.log]"; fmt.Sprint(gotExcluded) != want {
+		t.Errorf("excluded = %v, want %s", gotExcluded, want)
+	}
+}
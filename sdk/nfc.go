@@ -0,0 +1,11 @@
+package sdk
+
+import "golang.org/x/text/unicode/norm"
+
+// macOS 文件系统返回的文件名是 NFD（分解）形式，网盘中保存的是 NFC（预组合）形式，
+// 名称比较和上传前需要统一为 NFC。
+
+// normalizeNFC 将字符串归一化为 NFC，已是 NFC 时原样返回
+func normalizeNFC(s string) string {
+	return norm.NFC.String(s)
+}
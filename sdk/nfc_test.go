@@ -0,0 +1,69 @@
+package sdk
+
+import "testing"
+
+func TestNormalizeNFC(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "ascii unchanged", input: "report-2024.txt", want: "report-2024.txt"},
+		{name: "chinese unchanged", input: "中文文件名.txt", want: "中文文件名.txt"},
+		{name: "precomposed unchanged", input: "caf\u00e9.txt", want: "caf\u00e9.txt"},
+		{name: "latin acute", input: "cafe\u0301.txt", want: "caf\u00e9.txt"},
+		{name: "mixed combining and precomposed", input: "Cre\u0300me br\u00fble\u0301e", want: "Cr\u00e8me br\u00fbl\u00e9e"},
+		{name: "vietnamese two marks", input: "Vie\u0323\u0302t", want: "Vi\u1ec7t"},
+		{name: "japanese dakuten", input: "\u304b\u3099\u30cf\u309a", want: "\u304c\u30d1"},
+		{name: "hangul jamo", input: "\u1112\u1161\u11ab\u1100\u1173\u11af", want: "\ud55c\uae00"},
+		{name: "hangul LV only", input: "\u1100\u1161", want: "\uac00"},
+		{name: "uncomposable mark kept", input: "x\u0301", want: "x\u0301"},
+		{name: "blocked mark kept", input: "a\u0301\u0301", want: "\u00e1\u0301"},
+		{name: "leading mark kept", input: "\u0301a", want: "\u0301a"},
+		{name: "devanagari nukta", input: "\u0928\u093c", want: "\u0929"},
+		{name: "hebrew points unchanged", input: "\u05e9\u05c1", want: "\u05e9\u05c1"},
+		{name: "bengali vowel sign", input: "\u09c7\u09be", want: "\u09cb"},
+		{name: "non-canonical order", input: "a\u0301\u0323", want: "\u1ea1\u0301"},
+		{name: "emoji unchanged", input: "\U0001f4c1 \U0001f44d\U0001f3fd", want: "\U0001f4c1 \U0001f44d\U0001f3fd"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := normalizeNFC(tt.input); got != tt.want {
+				t.Errorf("normalizeNFC(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetFileInfo_NFDName(t *testing.T) {
+	items := []map[string]interface{}{
+		{"fid": "fid_nfc", "file_name": "caf\u00e9 \u30d1\u30f3.txt", "dir": false},
+		{"fid": "fid_nfd", "file_name": "Cre\u0300me.txt", "dir": false},
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		wantFid string
+	}{
+		{name: "NFD path matches NFC name", path: "/cafe\u0301 \u30cf\u309a\u30f3.txt", wantFid: "fid_nfc"},
+		{name: "NFC path matches NFC name", path: "/caf\u00e9 \u30d1\u30f3.txt", wantFid: "fid_nfc"},
+		{name: "NFC path matches NFD name", path: "/Cr\u00e8me.txt", wantFid: "fid_nfd"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fn, _ := fakeListServerItems(items, nil)
+			client := createMockClient(t, fn)
+
+			resp, err := client.GetFileInfo(tt.path)
+			if err != nil || !resp.Success {
+				t.Fatalf("GetFileInfo(%q) = %+v, %v", tt.path, resp, err)
+			}
+			if resp.Data["fid"] != tt.wantFid {
+				t.Errorf("GetFileInfo(%q) fid = %v, want %s", tt.path, resp.Data["fid"], tt.wantFid)
+			}
+		})
+	}
+}
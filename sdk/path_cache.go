@@ -0,0 +1,106 @@
+package sdk
+
+import (
+	"strings"
+	"time"
+)
+
+// pathCacheEntry 路径缓存条目
+type pathCacheEntry struct {
+	data      map[string]interface{} // GetFileInfo 成功时返回的 Data
+	expiresAt time.Time
+}
+
+// SetPathCacheTTL 设置路径→文件信息缓存的有效期，ttl <= 0 表示关闭缓存并清空已缓存条目
+// 外部可能并发修改网盘时（如多个客户端同时操作同一目录）建议关闭或调小
+func (qc *QuarkClient) SetPathCacheTTL(ttl time.Duration) {
+	qc.pathCacheTTL = ttl
+	if ttl <= 0 {
+		qc.ClearPathCache()
+	}
+}
+
+// InvalidatePathCache 使指定路径及其所有子路径的缓存失效
+func (qc *QuarkClient) InvalidatePathCache(path string) {
+	path = normalizePath(path)
+	if path == "" || path == "/" {
+		qc.ClearPathCache()
+		return
+	}
+	prefix := path + "/"
+	qc.pathCache.Range(func(key, _ interface{}) bool {
+		cached := key.(string)
+		if cached == path || strings.HasPrefix(cached, prefix) {
+			qc.pathCache.Delete(key)
+		}
+		return true
+	})
+}
+
+// ClearPathCache 清空路径缓存
+func (qc *QuarkClient) ClearPathCache() {
+	qc.pathCache.Range(func(key, _ interface{}) bool {
+		qc.pathCache.Delete(key)
+		return true
+	})
+}
+
+// invalidatePathCacheByFid 使缓存中 fid 对应的路径及其子路径失效
+// 用于只知道 fid 的操作（如在某个目录下创建文件夹）；传入以 "/" 开头的路径时按路径失效
+func (qc *QuarkClient) invalidatePathCacheByFid(fid string) {
+	if fid == "" || fid == "0" {
+		qc.ClearPathCache()
+		return
+	}
+	if strings.HasPrefix(fid, "/") {
+		qc.InvalidatePathCache(fid)
+		return
+	}
+	var paths []string
+	qc.pathCache.Range(func(key, value interface{}) bool {
+		if entry := value.(*pathCacheEntry); entry.data["fid"] == fid {
+			paths = append(paths, key.(string))
+		}
+		return true
+	})
+	for _, path := range paths {
+		qc.InvalidatePathCache(path)
+	}
+}
+
+// loadPathCache 读取未过期的缓存条目，返回 Data 的副本，调用方可以安全修改
+// wantDir 为 true 时只接受目录条目
+func (qc *QuarkClient) loadPathCache(path string, wantDir bool) (map[string]interface{}, bool) {
+	if qc.pathCacheTTL <= 0 {
+		return nil, false
+	}
+	value, ok := qc.pathCache.Load(path)
+	if !ok {
+		return nil, false
+	}
+	entry := value.(*pathCacheEntry)
+	if time.Now().After(entry.expiresAt) {
+		qc.pathCache.Delete(path)
+		return nil, false
+	}
+	if isDir, _ := entry.data["dir"].(bool); wantDir && !isDir {
+		return nil, false
+	}
+	data := make(map[string]interface{}, len(entry.data))
+	for k, v := range entry.data {
+		data[k] = v
+	}
+	return data, true
+}
+
+// storePathCache 缓存 GetFileInfo 的成功结果
+func (qc *QuarkClient) storePathCache(path string, data map[string]interface{}) {
+	if qc.pathCacheTTL <= 0 || path == "" || path == "/" {
+		return
+	}
+	copied := make(map[string]interface{}, len(data))
+	for k, v := range data {
+		copied[k] = v
+	}
+	qc.pathCache.Store(path, &pathCacheEntry{data: copied, expiresAt: time.Now().Add(qc.pathCacheTTL)})
+}
@@ -0,0 +1,95 @@
+package sdk
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPathCache(t *testing.T) {
+	files := map[string]map[string]interface{}{
+		"/docs":       {"fid": "fid_docs", "file_name": "docs", "dir": true},
+		"/docs/a.txt": {"fid": "fid_a", "file_name": "a.txt", "dir": false},
+		"/other.txt":  {"fid": "fid_other", "file_name": "other.txt", "dir": false},
+	}
+
+	lookup := func(t *testing.T, client *QuarkClient, path string) {
+		t.Helper()
+		resp, err := client.GetFileInfo(path)
+		if err != nil || !resp.Success {
+			t.Fatalf("GetFileInfo(%s) = %+v, %v", path, resp, err)
+		}
+	}
+
+	t.Run("repeated lookups hit cache", func(t *testing.T) {
+		fn, requested := fakeFileInfoServer(files)
+		client := createMockClient(t, fn)
+		lookup(t, client, "/docs/a.txt")
+		lookup(t, client, "/docs/a.txt")
+		if len(*requested) != 2 {
+			t.Errorf("sent %d requests, want 2 (second lookup should be cached)", len(*requested))
+		}
+	})
+
+	t.Run("cached data is a copy", func(t *testing.T) {
+		fn, _ := fakeFileInfoServer(files)
+		client := createMockClient(t, fn)
+		resp, _ := client.GetFileInfo("/docs/a.txt")
+		resp.Data["fid"] = "modified"
+		resp, _ = client.GetFileInfo("/docs/a.txt")
+		if resp.Data["fid"] != "fid_a" {
+			t.Errorf("cached fid = %v, want fid_a", resp.Data["fid"])
+		}
+	})
+
+	t.Run("expired entries are refetched", func(t *testing.T) {
+		fn, requested := fakeFileInfoServer(files)
+		client := createMockClient(t, fn)
+		client.SetPathCacheTTL(time.Millisecond)
+		lookup(t, client, "/docs/a.txt")
+		time.Sleep(5 * time.Millisecond)
+		lookup(t, client, "/docs/a.txt")
+		if len(*requested) != 4 {
+			t.Errorf("sent %d requests, want 4", len(*requested))
+		}
+	})
+
+	t.Run("disabled cache", func(t *testing.T) {
+		fn, requested := fakeFileInfoServer(files)
+		client := createMockClient(t, fn)
+		client.SetPathCacheTTL(0)
+		lookup(t, client, "/docs/a.txt")
+		lookup(t, client, "/docs/a.txt")
+		if len(*requested) != 4 {
+			t.Errorf("sent %d requests, want 4", len(*requested))
+		}
+	})
+
+	t.Run("invalidate path and descendants", func(t *testing.T) {
+		fn, requested := fakeFileInfoServer(files)
+		client := createMockClient(t, fn)
+		lookup(t, client, "/docs")
+		lookup(t, client, "/docs/a.txt")
+		lookup(t, client, "/other.txt")
+		client.InvalidatePathCache("/docs")
+		*requested = nil
+		lookup(t, client, "/docs")
+		lookup(t, client, "/docs/a.txt")
+		lookup(t, client, "/other.txt")
+		if len(*requested) != 4 {
+			t.Errorf("sent %d requests after invalidation, want 4 (/other.txt should stay cached)", len(*requested))
+		}
+	})
+
+	t.Run("delete invalidates", func(t *testing.T) {
+		fn, requested := fakeFileInfoServer(files)
+		client := createMockClient(t, fn)
+		if resp, err := client.Delete("/docs/a.txt"); err != nil || !resp.Success {
+			t.Fatalf("Delete() = %+v, %v", resp, err)
+		}
+		*requested = nil
+		lookup(t, client, "/docs/a.txt")
+		if len(*requested) != 2 {
+			t.Errorf("sent %d requests after delete, want 2", len(*requested))
+		}
+	})
+}
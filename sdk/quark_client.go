@@ -20,15 +20,21 @@ func NewQuarkClient(configPath string, cookies ...string) *QuarkClient {
 	var accessTokens []string
 	var initialToken string
 	var initialIdx int
+	var config *Config
 
 	// 如果提供了 cookies 参数，直接使用
 	if len(cookies) > 0 && cookies[0] != "" {
 		accessTokens = []string{cookies[0]}
 		initialToken = cookies[0]
 		initialIdx = 0
+		// 配置文件可选，仅用于读取 endpoints 等非 token 配置
+		if cfg, err := ReadConfig(configPath); err == nil {
+			config = cfg
+		}
 	} else {
 		// 否则从配置文件加载
-		config, err := LoadConfig(configPath)
+		var err error
+		config, err = LoadConfig(configPath)
 		if err != nil {
 			panic("failed to load config file")
 		}
@@ -51,27 +57,111 @@ func NewQuarkClient(configPath string, cookies ...string) *QuarkClient {
 	isDebugEnv := debugEnv == "1"
 
 	client := &QuarkClient{
-		baseURL:          DRIVE_DOMAIN,    // 使用 DRIVE_DOMAIN 常量
-		accessToken:      initialToken,    // 当前使用的 token
-		accessTokens:     accessTokens,    // 所有可用的 tokens
-		currentTokenIdx:  initialIdx,      // 当前 token 索引
-		authCheckTimeout: 5 * time.Minute, // 默认5分钟内缓存认证检查结果
-		failedTokens:     make(map[int]bool),
-		Debug:            isDebugEnv, // 从环境变量读取，默认关闭
+		baseURL:           DRIVE_DOMAIN, // 使用 DRIVE_DOMAIN 常量
+		panDomain:         PAN_DOMAIN,
+		driveHDomain:      DRIVE_H_DOMAIN,
+		accessToken:       initialToken,    // 当前使用的 token
+		accessTokens:      accessTokens,    // 所有可用的 tokens
+		currentTokenIdx:   initialIdx,      // 当前 token 索引
+		authCheckTimeout:  5 * time.Minute, // 默认5分钟内缓存认证检查结果
+		pathCacheTTL:      PATH_CACHE_TTL,
+		listRetries:       config.EffectiveListRetries(),
+		downloadRetries:   config.EffectiveDownloadRetries(),
+		downloadRetryWait: config.EffectiveDownloadRetryInterval(),
+		uploadPartTimeout: config.EffectiveUploadPartTimeout(),
+		uploadMinSpeed:    config.EffectiveUploadMinSpeed(),
+		uploadSpeedWindow: config.EffectiveUploadSpeedWindow(),
+		uploadStateDir:    config.EffectiveUploadStateDir(),
+		failedTokens:      make(map[int]bool),
+		Debug:             isDebugEnv, // 从环境变量读取，默认关闭
 		HttpClient: &http.Client{
 			Timeout: 30 * time.Second, // 普通 API 请求的超时时间，上传请求使用动态超时
 		},
 	}
+	// 环境变量 UPLOAD_STATE_ENV 覆盖配置文件中的状态目录（容器中可指向持久化的卷）
+	if dir := os.Getenv(UPLOAD_STATE_ENV); dir != "" {
+		client.uploadStateDir = dir
+	}
+	// 应用配置文件中的域名覆盖，未配置时使用默认域名
+	client.SetEndpoints(config.EffectiveEndpoints())
 	// 解析 cookie
 	client.cookies = client.parseCookie(initialToken)
 	return client
 }
 
+// SetListRetries 设置列目录单页请求遇到 5xx/网络错误时的最大重试次数，n < 0 按 0 处理（不重试）
+func (qc *QuarkClient) SetListRetries(n int) {
+	if n < 0 {
+		n = 0
+	}
+	qc.listRetries = n
+}
+
+// SetDownloadRetries 设置下载遇到网络错误或 403/5xx 时的最大重试次数和重试前的等待时间
+// n < 0 按 0 处理（不重试），wait < 0 按 0 处理
+func (qc *QuarkClient) SetDownloadRetries(n int, wait time.Duration) {
+	if n < 0 {
+		n = 0
+	}
+	if wait < 0 {
+		wait = 0
+	}
+	qc.downloadRetries = n
+	qc.downloadRetryWait = wait
+}
+
+// SetUploadPartTimeout 设置单个分片的传输超时，d <= 0 时按分片大小计算（见 uploadPartTimeoutFor）
+func (qc *QuarkClient) SetUploadPartTimeout(d time.Duration) {
+	if d < 0 {
+		d = 0
+	}
+	qc.uploadPartTimeout = d
+}
+
+// SetUploadMinSpeed 设置分片上传的低速阈值（字节/秒）：发送分片时一个统计窗口内的平均速度低于此值就取消该分片请求并重试，
+// bytesPerSecond <= 0 时不检测；window <= 0 时保持当前窗口（默认 UPLOAD_SPEED_WINDOW）
+func (qc *QuarkClient) SetUploadMinSpeed(bytesPerSecond int64, window time.Duration) {
+	if bytesPerSecond < 0 {
+		bytesPerSecond = 0
+	}
+	qc.uploadMinSpeed = bytesPerSecond
+	if window > 0 {
+		qc.uploadSpeedWindow = window
+	}
+}
+
+// SetUploadStateDir 设置断点续传状态文件目录，dir 为空时恢复为用户缓存目录下的 UPLOAD_STATE_DIR
+func (qc *QuarkClient) SetUploadStateDir(dir string) {
+	qc.uploadStateDir = dir
+}
+
 // SetBaseURL 设置自定义 API 基础 URL
 func (qc *QuarkClient) SetBaseURL(baseURL string) {
 	qc.baseURL = baseURL
 }
 
+// SetEndpoints 覆盖各 API 域名，空字段保持当前值不变
+func (qc *QuarkClient) SetEndpoints(endpoints EndpointsConfig) {
+	if endpoints.PanDomain != "" {
+		qc.panDomain = strings.TrimRight(endpoints.PanDomain, "/")
+	}
+	if endpoints.DriveDomain != "" {
+		qc.baseURL = strings.TrimRight(endpoints.DriveDomain, "/")
+	}
+	if endpoints.DriveHDomain != "" {
+		qc.driveHDomain = strings.TrimRight(endpoints.DriveHDomain, "/")
+	}
+}
+
+// Endpoints 返回客户端当前使用的 API 域名
+func (qc *QuarkClient) Endpoints() EndpointsConfig {
+	return EndpointsConfig{
+		PanDomain:    qc.panDomain,
+		DriveDomain:  qc.baseURL,
+		DriveHDomain: qc.driveHDomain,
+	}
+}
+
 // GetCookies 获取解析后的 cookie 字典
 func (qc *QuarkClient) GetCookies() map[string]string {
 	return qc.cookies
@@ -1,9 +1,14 @@
 package sdk
 
 import (
+	"io"
+	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestNewQuarkClient(t *testing.T) {
@@ -110,6 +115,85 @@ func TestSetBaseURL(t *testing.T) {
 	}
 }
 
+// recordingTransport 记录请求的 host 并返回固定的空响应，用于验证请求发往的域名
+type recordingTransport struct {
+	mu    sync.Mutex
+	hosts []string
+}
+
+func (rt *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	rt.mu.Lock()
+	rt.hosts = append(rt.hosts, req.URL.Scheme+"://"+req.URL.Host)
+	rt.mu.Unlock()
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(`{"status":200,"code":0,"data":{}}`)),
+		Request:    req,
+	}, nil
+}
+
+func TestEndpointsOverride(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "config.json")
+	config := &Config{}
+	config.Quark.AccessTokens = []string{"__pus=test_token;"}
+	config.Endpoints = EndpointsConfig{
+		PanDomain:    "http://127.0.0.1:8081/",
+		DriveDomain:  "http://127.0.0.1:8082",
+		DriveHDomain: "http://127.0.0.1:8083",
+	}
+	if err := SaveConfig(tmpFile, config); err != nil {
+		t.Fatalf("SaveConfig() error = %v", err)
+	}
+
+	client := NewQuarkClient(tmpFile)
+	want := EndpointsConfig{
+		PanDomain:    "http://127.0.0.1:8081",
+		DriveDomain:  "http://127.0.0.1:8082",
+		DriveHDomain: "http://127.0.0.1:8083",
+	}
+	if got := client.Endpoints(); got != want {
+		t.Fatalf("Endpoints() = %+v, want %+v", got, want)
+	}
+
+	tests := []struct {
+		name     string
+		call     func(c *QuarkClient)
+		wantHost string
+	}{
+		{name: "user info uses pan domain", call: func(c *QuarkClient) { c.GetUserInfo() }, wantHost: want.PanDomain},
+		{name: "share token uses drive_h domain", call: func(c *QuarkClient) { c.GetShareStoken("pwd", "") }, wantHost: want.DriveHDomain},
+		{name: "share list uses drive_h domain", call: func(c *QuarkClient) { c.GetShareList("pwd", "stoken", "0", 1, 50, "file_name", "asc") }, wantHost: want.DriveHDomain},
+		{name: "share save uses drive domain", call: func(c *QuarkClient) { c.SaveShareFile("pwd", "stoken", nil, nil, "0", true) }, wantHost: want.DriveDomain},
+		{name: "share delete uses drive domain", call: func(c *QuarkClient) { c.DeleteShare([]string{"share_id"}) }, wantHost: want.DriveDomain},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := &recordingTransport{}
+			client.HttpClient = &http.Client{Transport: transport}
+			// 预置认证缓存，避免请求前的登录检查干扰域名断言
+			client.authCheckValid = true
+			client.lastAuthCheck = time.Now()
+			tt.call(client)
+			if len(transport.hosts) == 0 {
+				t.Fatal("no request was sent")
+			}
+			if transport.hosts[0] != tt.wantHost {
+				t.Errorf("request sent to %s, want %s", transport.hosts[0], tt.wantHost)
+			}
+		})
+	}
+}
+
+func TestEndpointsDefault(t *testing.T) {
+	client := NewQuarkClient(filepath.Join(t.TempDir(), "missing.json"), "__pus=test_token;")
+	want := EndpointsConfig{PanDomain: PAN_DOMAIN, DriveDomain: DRIVE_DOMAIN, DriveHDomain: DRIVE_H_DOMAIN}
+	if got := client.Endpoints(); got != want {
+		t.Errorf("Endpoints() = %+v, want %+v", got, want)
+	}
+}
+
 func TestGetCookies(t *testing.T) {
 	client := createTestClient(t)
 	if client == nil {
@@ -272,7 +356,7 @@ func TestConvertToFileInfo(t *testing.T) {
 }
 
 // createTestClient 创建测试用的客户端
-func createTestClient(t *testing.T) *QuarkClient {
+func createTestClient(t testing.TB) *QuarkClient {
 	tmpFile := filepath.Join(t.TempDir(), "test_config.json")
 	config := &Config{
 		Quark: struct {
@@ -296,3 +380,29 @@ func createTestClient(t *testing.T) *QuarkClient {
 	return client
 }
 
+
+// roundTripFunc 将函数适配为 http.RoundTripper，用于在测试中模拟服务端响应
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// jsonResponse 构造 JSON 响应
+func jsonResponse(req *http.Request, body string) *http.Response {
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Request:    req,
+	}
+}
+
+// createMockClient 创建使用模拟 Transport 的测试客户端，并预置认证缓存跳过登录检查
+func createMockClient(t testing.TB, fn roundTripFunc) *QuarkClient {
+	client := createTestClient(t)
+	client.HttpClient = &http.Client{Transport: fn}
+	client.authCheckValid = true
+	client.lastAuthCheck = time.Now()
+	return client
+}
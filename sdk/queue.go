@@ -2,9 +2,13 @@ package sdk
 
 import (
 	"fmt"
+	"sync/atomic"
 	"time"
 )
 
+// taskIDSeq 任务ID序号，保证同一纳秒内添加的任务ID也不重复
+var taskIDSeq uint64
+
 // NewTaskQueue 创建新的任务队列
 func NewTaskQueue(maxWorkers int) *TaskQueue {
 	return &TaskQueue{
@@ -52,22 +56,30 @@ func (q *TaskQueue) worker() {
 	}
 }
 
-// getNextPendingTask 获取下一个待处理任务
+// getNextPendingTask 获取下一个待处理任务，跳过尚未到重试时间的任务
 func (q *TaskQueue) getNextPendingTask() *Task {
 	q.mu.Lock()
 	defer q.mu.Unlock()
 
-	if len(q.pending) == 0 {
+	now := time.Now()
+	index := -1
+	for i, t := range q.pending {
+		if !t.retryAt.After(now) {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
 		return nil
 	}
 
-	task := q.pending[0]
-	q.pending = q.pending[1:]
+	task := q.pending[index]
+	q.pending = append(q.pending[:index], q.pending[index+1:]...)
 	q.running = append(q.running, task)
 
 	task.Status = TaskStatusRunning
-	now := time.Now()
 	task.StartedAt = &now
+	task.Attempts++
 
 	return task
 }
@@ -92,6 +104,16 @@ func (q *TaskQueue) executeTask(task *Task) {
 
 	// 更新任务状态
 	q.mu.Lock()
+	if err != nil && q.shouldRetry(task, err) {
+		// 按重试策略放回等待队列末尾，到时间后再执行；最终结果确定前不调用回调
+		task.Status = TaskStatusPending
+		task.Error = err
+		task.retryAt = time.Now().Add(q.retry.Interval)
+		q.removeRunning(task)
+		q.pending = append(q.pending, task)
+		q.mu.Unlock()
+		return
+	}
 	if err != nil {
 		task.Status = TaskStatusFailed
 		task.Error = err
@@ -104,12 +126,7 @@ func (q *TaskQueue) executeTask(task *Task) {
 	task.Progress = 100.0
 
 	// 从运行中移除
-	for i, t := range q.running {
-		if t.ID == task.ID {
-			q.running = append(q.running[:i], q.running[i+1:]...)
-			break
-		}
-	}
+	q.removeRunning(task)
 
 	// 添加到已完成
 	q.completed = append(q.completed, task)
@@ -135,15 +152,28 @@ func (q *TaskQueue) completeTask(task *Task) {
 	defer q.mu.Unlock()
 
 	// 从运行中移除
+	q.removeRunning(task)
+
+	// 添加到已完成
+	q.completed = append(q.completed, task)
+}
+
+// removeRunning 从运行中列表移除任务，调用方需持有 q.mu
+func (q *TaskQueue) removeRunning(task *Task) {
 	for i, t := range q.running {
 		if t.ID == task.ID {
 			q.running = append(q.running[:i], q.running[i+1:]...)
 			break
 		}
 	}
+}
 
-	// 添加到已完成
-	q.completed = append(q.completed, task)
+// shouldRetry 按重试策略判断失败的任务是否重试，调用方需持有 q.mu
+func (q *TaskQueue) shouldRetry(task *Task, err error) bool {
+	if task.Attempts > q.retry.MaxRetries {
+		return false
+	}
+	return q.retry.Retryable == nil || q.retry.Retryable(err)
 }
 
 // AddTask 添加任务到队列
@@ -242,6 +272,14 @@ func (q *TaskQueue) CancelTask(taskID string) error {
 	return nil
 }
 
+// SetRetryPolicy 设置失败任务的重试策略，应在 Start 之前调用
+func (q *TaskQueue) SetRetryPolicy(policy TaskRetryPolicy) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.retry = policy
+}
+
 // SetTaskCallback 设置任务回调
 func (q *TaskQueue) SetTaskCallback(taskID string, callback TaskCallback) {
 	q.mu.Lock()
@@ -274,5 +312,5 @@ func (q *TaskQueue) Stop() {
 
 // generateTaskID 生成任务ID
 func generateTaskID() string {
-	return fmt.Sprintf("task_%d", time.Now().UnixNano())
+	return fmt.Sprintf("task_%d_%d", time.Now().UnixNano(), atomic.AddUint64(&taskIDSeq, 1))
 }
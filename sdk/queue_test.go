@@ -0,0 +1,96 @@
+package sdk
+
+import (
+	"errors"
+	"sync"
+	"testing"
+)
+
+type echoExecutor struct{}
+
+func (echoExecutor) Execute(task *Task) (interface{}, error) {
+	return task.Params["n"], nil
+}
+
+func TestTaskQueue_AddManyTasks(t *testing.T) {
+	queue := NewTaskQueue(3)
+	const count = 200
+	tasks := make([]*Task, 0, count)
+	for i := 0; i < count; i++ {
+		tasks = append(tasks, queue.AddTask(TaskTypeDownload, map[string]interface{}{"n": i}))
+	}
+	if got := len(queue.GetAllTasks()); got != count {
+		t.Fatalf("queue has %d tasks, want %d (task IDs must be unique)", got, count)
+	}
+
+	queue.Start(echoExecutor{})
+	queue.Wait()
+	queue.Stop()
+	for i, task := range tasks {
+		if task.Status != TaskStatusCompleted || task.Result != i {
+			t.Errorf("task %d: status %s, result %v", i, task.Status, task.Result)
+		}
+	}
+}
+
+// flakyExecutor 前 failures 次执行返回错误，之后成功；n 为 "fatal" 的任务总是返回不可重试的错误
+type flakyExecutor struct {
+	mu       sync.Mutex
+	failures int
+	calls    map[interface{}]int
+}
+
+var errFatal = errors.New("fatal")
+
+func (e *flakyExecutor) Execute(task *Task) (interface{}, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	n := task.Params["n"]
+	e.calls[n]++
+	if n == "fatal" {
+		return nil, errFatal
+	}
+	if e.calls[n] <= e.failures {
+		return nil, errors.New("temporary")
+	}
+	return n, nil
+}
+
+func TestTaskQueue_RetryPolicy(t *testing.T) {
+	queue := NewTaskQueue(2)
+	queue.SetRetryPolicy(TaskRetryPolicy{
+		MaxRetries: 2,
+		Retryable:  func(err error) bool { return !errors.Is(err, errFatal) },
+	})
+	ok := queue.AddTask(TaskTypeDownload, map[string]interface{}{"n": "ok"})
+	fatal := queue.AddTask(TaskTypeDownload, map[string]interface{}{"n": "fatal"})
+	var mu sync.Mutex
+	errorCallbacks := 0
+	queue.SetTaskCallback(ok.ID, TaskCallback{OnError: func(*Task, error) { mu.Lock(); errorCallbacks++; mu.Unlock() }})
+
+	executor := &flakyExecutor{failures: 2, calls: make(map[interface{}]int)}
+	queue.Start(executor)
+	queue.Wait()
+	queue.Stop()
+
+	if ok.Status != TaskStatusCompleted || ok.Attempts != 3 || ok.Result != "ok" {
+		t.Errorf("retried task: status %s, attempts %d, result %v, want completed after 3 attempts", ok.Status, ok.Attempts, ok.Result)
+	}
+	if errorCallbacks != 0 {
+		t.Errorf("OnError called %d times for a task that eventually succeeded", errorCallbacks)
+	}
+	if fatal.Status != TaskStatusFailed || fatal.Attempts != 1 || !errors.Is(fatal.Error, errFatal) {
+		t.Errorf("non-retryable task: status %s, attempts %d, error %v, want failed after 1 attempt", fatal.Status, fatal.Attempts, fatal.Error)
+	}
+
+	// 超过最大重试次数后失败
+	queue = NewTaskQueue(1)
+	queue.SetRetryPolicy(TaskRetryPolicy{MaxRetries: 1})
+	task := queue.AddTask(TaskTypeDownload, map[string]interface{}{"n": "ok"})
+	queue.Start(&flakyExecutor{failures: 5, calls: make(map[interface{}]int)})
+	queue.Wait()
+	queue.Stop()
+	if task.Status != TaskStatusFailed || task.Attempts != 2 {
+		t.Errorf("task: status %s, attempts %d, want failed after 2 attempts", task.Status, task.Attempts)
+	}
+}
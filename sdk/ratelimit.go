@@ -0,0 +1,77 @@
+package sdk
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+// RateLimiter 令牌桶限速器，按字节限制总传输速率；同一个限速器可在多个并发下载间共享
+// nil *RateLimiter 表示不限速，所有方法都可安全调用
+type RateLimiter struct {
+	mu     sync.Mutex
+	rate   float64   // 每秒产生的令牌（字节）数
+	burst  float64   // 桶容量，空闲后最多可一次性消耗的字节数
+	tokens float64   // 当前令牌数，为负表示已透支，需要等待补足
+	last   time.Time // 上次补充令牌的时间
+}
+
+// NewRateLimiter 创建每秒 bytesPerSecond 字节的限速器，bytesPerSecond <= 0 时返回 nil（不限速）
+func NewRateLimiter(bytesPerSecond int64) *RateLimiter {
+	if bytesPerSecond <= 0 {
+		return nil
+	}
+	burst := float64(bytesPerSecond)
+	if burst < RATE_LIMIT_MIN_BURST {
+		burst = RATE_LIMIT_MIN_BURST
+	}
+	return &RateLimiter{rate: float64(bytesPerSecond), burst: burst, tokens: burst, last: time.Now()}
+}
+
+// Wait 消耗 n 个令牌，令牌不足时阻塞到补足为止
+func (rl *RateLimiter) Wait(n int) {
+	if rl == nil || n <= 0 {
+		return
+	}
+	rl.mu.Lock()
+	now := time.Now()
+	rl.tokens += now.Sub(rl.last).Seconds() * rl.rate
+	if rl.tokens > rl.burst {
+		rl.tokens = rl.burst
+	}
+	rl.last = now
+	// 先透支再等待，并发调用者按调用顺序依次排队，总速率不超过 rate
+	rl.tokens -= float64(n)
+	var delay time.Duration
+	if rl.tokens < 0 {
+		delay = time.Duration(-rl.tokens / rl.rate * float64(time.Second))
+	}
+	rl.mu.Unlock()
+	if delay > 0 {
+		time.Sleep(delay)
+	}
+}
+
+// Reader 返回按限速器读取 r 的 Reader，rl 为 nil 时直接返回 r
+func (rl *RateLimiter) Reader(r io.Reader) io.Reader {
+	if rl == nil {
+		return r
+	}
+	return &rateLimitedReader{r: r, limiter: rl}
+}
+
+// rateLimitedReader 每次读取后按读到的字节数消耗令牌
+type rateLimitedReader struct {
+	r       io.Reader
+	limiter *RateLimiter
+}
+
+func (lr *rateLimitedReader) Read(p []byte) (int, error) {
+	// 单次读取不超过桶容量，避免一次透支过多导致速率抖动
+	if max := int(lr.limiter.burst); len(p) > max {
+		p = p[:max]
+	}
+	n, err := lr.r.Read(p)
+	lr.limiter.Wait(n)
+	return n, err
+}
@@ -0,0 +1,78 @@
+package sdk
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter_Nil(t *testing.T) {
+	if limiter := NewRateLimiter(0); limiter != nil {
+		t.Fatalf("NewRateLimiter(0) = %v, want nil", limiter)
+	}
+	var limiter *RateLimiter
+	r := strings.NewReader("data")
+	if limiter.Reader(r) != io.Reader(r) {
+		t.Error("nil limiter should return the reader unchanged")
+	}
+	limiter.Wait(1 << 30) // 不应阻塞
+}
+
+func TestRateLimiter_SharedAcrossReaders(t *testing.T) {
+	const rate = 512 * 1024
+	limiter := NewRateLimiter(rate)
+	data := bytes.Repeat([]byte("x"), 384*1024)
+
+	// 两个读取共享限速器：共 768KB，桶内初始 512KB，剩余 256KB 需要约 0.5 秒
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			n, err := io.Copy(io.Discard, limiter.Reader(bytes.NewReader(data)))
+			if err != nil || n != int64(len(data)) {
+				t.Errorf("copied %d bytes, err = %v", n, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if elapsed := time.Since(start); elapsed < 400*time.Millisecond || elapsed > 3*time.Second {
+		t.Errorf("elapsed %s, want about 500ms", elapsed)
+	}
+}
+
+func TestUploadFile_RateLimit(t *testing.T) {
+	isolateUploadState(t)
+
+	const rate = 32 * 1024
+	content := bytes.Repeat([]byte("0123456789abcdef"), 64*1024/16) // 4 个分片，两个并发
+	localPath := filepath.Join(t.TempDir(), "big.bin")
+	if err := os.WriteFile(localPath, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+	server := &fakeUploadServer{partSize: 16 * 1024, partThread: 2}
+	client := server.client(t)
+
+	// 并发分片共享限速器：共 64KB，桶内初始 32KB，剩余 32KB 需要约 1 秒
+	var last UploadProgress
+	start := time.Now()
+	resp, err := client.UploadFile(localPath, "/big.bin", func(p *UploadProgress) { last = *p }, &UploadOptions{RateLimiter: NewRateLimiter(rate)})
+	if err != nil || !resp.Success {
+		t.Fatalf("UploadFile() = %+v, %v", resp, err)
+	}
+	if elapsed := time.Since(start); elapsed < 800*time.Millisecond || elapsed > 5*time.Second {
+		t.Errorf("elapsed %s, want about 1s", elapsed)
+	}
+	if len(server.parts) != 4 {
+		t.Errorf("uploaded %d parts, want 4", len(server.parts))
+	}
+	if last.Speed <= 0 || last.Speed > 2.5*rate {
+		t.Errorf("last progress speed = %.0f, want limited to about %d bytes/s", last.Speed, rate)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	cryptorand "crypto/rand"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math/big"
 	"math/rand"
@@ -70,8 +71,8 @@ func (qc *QuarkClient) GetShareStoken(pwdID, passcode string) (map[string]interf
 		return nil, fmt.Errorf("failed to marshal request data: %w", err)
 	}
 
-	// 使用 DRIVE_H_DOMAIN 作为 baseURL
-	reqURL := DRIVE_H_DOMAIN + SHARE_SHAREPAGE_TOKEN + "?" + queryParams.Encode()
+	// 使用 driveHDomain（DRIVE_H_DOMAIN）作为 baseURL
+	reqURL := qc.driveHDomain + SHARE_SHAREPAGE_TOKEN + "?" + queryParams.Encode()
 	respMap, err := qc.makeRequest("POST", reqURL, bytes.NewBuffer(jsonData), nil)
 	if err != nil {
 		return nil, fmt.Errorf("request failed: %w", err)
@@ -129,7 +130,7 @@ func (qc *QuarkClient) GetShareList(pwdID, stoken, pdirFid string, page, size in
 	queryParams.Set("__dt", fmt.Sprintf("%d", dt))
 	queryParams.Set("__t", fmt.Sprintf("%d", t))
 
-	reqURL := DRIVE_H_DOMAIN + SHARE_SHAREPAGE_DETAIL + "?" + queryParams.Encode()
+	reqURL := qc.driveHDomain + SHARE_SHAREPAGE_DETAIL + "?" + queryParams.Encode()
 	respMap, err := qc.makeRequest("GET", reqURL, nil, nil)
 	if err != nil {
 		return nil, fmt.Errorf("request failed: %w", err)
@@ -156,6 +157,11 @@ func (qc *QuarkClient) GetShareList(pwdID, stoken, pdirFid string, page, size in
 // pdirSaveAll: 是否全部保存，默认true
 // 返回转存结果数据和错误
 func (qc *QuarkClient) SaveShareFile(pwdID, stoken string, fidList, shareTokenList []string, toPdirFid string, pdirSaveAll bool) (map[string]interface{}, error) {
+	return qc.saveShareFiles(pwdID, stoken, "0", fidList, shareTokenList, toPdirFid, pdirSaveAll)
+}
+
+// saveShareFiles 同 SaveShareFile，pdirFid 为 fidList 所在的分享内目录（"0" 为分享根目录）
+func (qc *QuarkClient) saveShareFiles(pwdID, stoken, pdirFid string, fidList, shareTokenList []string, toPdirFid string, pdirSaveAll bool) (map[string]interface{}, error) {
 	// 生成随机数和时间戳
 	rand.Seed(time.Now().UnixNano())
 	dt := rand.Intn(900) + 100 // 100-999
@@ -174,7 +180,7 @@ func (qc *QuarkClient) SaveShareFile(pwdID, stoken string, fidList, shareTokenLi
 		"to_pdir_fid":      toPdirFid,
 		"pwd_id":           pwdID,
 		"stoken":           stoken,
-		"pdir_fid":         "0",
+		"pdir_fid":         pdirFid,
 		"pdir_save_all":    pdirSaveAll,
 		"exclude_fids":     []string{},
 		"scene":            "link",
@@ -185,7 +191,7 @@ func (qc *QuarkClient) SaveShareFile(pwdID, stoken string, fidList, shareTokenLi
 		return nil, fmt.Errorf("failed to marshal request data: %w", err)
 	}
 
-	reqURL := DRIVE_DOMAIN + SHARE_SHAREPAGE_SAVE + "?" + queryParams.Encode()
+	reqURL := qc.baseURL + SHARE_SHAREPAGE_SAVE + "?" + queryParams.Encode()
 	respMap, err := qc.makeRequest("POST", reqURL, bytes.NewBuffer(jsonData), nil)
 	if err != nil {
 		return nil, fmt.Errorf("request failed: %w", err)
@@ -378,55 +384,19 @@ func (qc *QuarkClient) CreateShare(filePath string, expireDays int, needPasscode
 	return shareLinkInfo, nil
 }
 
-// waitForTaskComplete 轮询任务状态直到完成
+// waitForTaskComplete 轮询创建分享的任务直到完成
 // taskID: 任务ID
 // 返回share_id和错误
 func (qc *QuarkClient) waitForTaskComplete(taskID string) (string, error) {
-	maxRetries := 10
-	retryInterval := 500 * time.Millisecond
-
-	for i := 0; i < maxRetries; i++ {
-		time.Sleep(retryInterval)
-
-		queryParams := url.Values{}
-		queryParams.Set("task_id", taskID)
-		queryParams.Set("retry_index", "0")
-
-		reqURL := qc.baseURL + TASK + "?" + queryParams.Encode()
-		respMap, err := qc.makeRequest("GET", reqURL, nil, nil)
-		if err != nil {
-			return "", fmt.Errorf("query task status failed: %w", err)
-		}
-
-		var taskResp struct {
-			Code   int `json:"code"`
-			Status int `json:"status"`
-			Data   struct {
-				Status  int    `json:"status"` // 2表示完成
-				ShareID string `json:"share_id"`
-			} `json:"data"`
-		}
-
-		if err := qc.parseResponse(respMap, &taskResp); err != nil {
-			return "", fmt.Errorf("failed to decode task response: %w", err)
-		}
-
-		if taskResp.Data.Status == 2 && taskResp.Data.ShareID != "" {
-			return taskResp.Data.ShareID, nil
-		}
-
-		// 如果任务还在进行中，继续等待
-		if taskResp.Data.Status == 1 {
-			continue
-		}
-
-		// 如果任务失败
-		if taskResp.Data.Status == 3 {
-			return "", fmt.Errorf("task failed")
-		}
+	data, err := qc.pollTask(taskID, SHARE_CREATE_POLL_INTERVAL, SHARE_CREATE_TIMEOUT)
+	if err != nil {
+		return "", err
 	}
-
-	return "", fmt.Errorf("task timeout after %d retries", maxRetries)
+	shareID, _ := data["share_id"].(string)
+	if shareID == "" {
+		return "", fmt.Errorf("task %s finished without share_id", taskID)
+	}
+	return shareID, nil
 }
 
 // GetShareLink 通过share_id获取分享链接
@@ -555,7 +525,7 @@ func (qc *QuarkClient) GetMyShareList(page, size int, orderField, orderType stri
 	queryParams.Set("_fetch_total", "1")
 	queryParams.Set("_fetch_notify_follow", "1")
 
-	reqURL := DRIVE_DOMAIN + SHARE_MYPAGE_DETAIL + "?" + queryParams.Encode()
+	reqURL := qc.baseURL + SHARE_MYPAGE_DETAIL + "?" + queryParams.Encode()
 	respMap, err := qc.makeRequest("GET", reqURL, nil, nil)
 	if err != nil {
 		return nil, fmt.Errorf("request failed: %w", err)
@@ -578,9 +548,12 @@ func (qc *QuarkClient) GetMyShareList(page, size int, orderField, orderType stri
 	return listResp.Data, nil
 }
 
+// ErrShareNotFound 文件没有对应的分享（GetShareIDByFid 返回的错误可用 errors.Is 判断）
+var ErrShareNotFound = errors.New("share_id not found")
+
 // GetShareIDByFid 通过文件fid从我的分享列表中获取share_id
 // fid: 文件ID
-// 返回share_id和错误
+// 返回share_id和错误；文件未分享时返回 ErrShareNotFound
 func (qc *QuarkClient) GetShareIDByFid(fid string) (string, error) {
 	// 获取我的分享列表，查找匹配的fid
 	// 可能需要遍历多页，先尝试第一页
@@ -618,7 +591,29 @@ func (qc *QuarkClient) GetShareIDByFid(fid string) (string, error) {
 		}
 	}
 
-	return "", fmt.Errorf("share_id not found for fid: %s", fid)
+	return "", fmt.Errorf("%w for fid: %s", ErrShareNotFound, fid)
+}
+
+// GetShareStatus 查询文件的分享状态，未分享是正常情况而不是错误
+// fid: 文件ID
+// 返回 shared（是否已分享），已分享时还包含 share_id 和 share_url（获取链接失败时省略 share_url）
+func (qc *QuarkClient) GetShareStatus(fid string) (map[string]interface{}, error) {
+	shareID, err := qc.GetShareIDByFid(fid)
+	if errors.Is(err, ErrShareNotFound) {
+		return map[string]interface{}{"shared": false}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	status := map[string]interface{}{
+		"shared":   true,
+		"share_id": shareID,
+	}
+	if link, err := qc.GetShareLink(shareID); err == nil && link.ShareURL != "" {
+		status["share_url"] = link.ShareURL
+	}
+	return status, nil
 }
 
 // DeleteShare 取消分享（删除分享）
@@ -643,7 +638,7 @@ func (qc *QuarkClient) DeleteShare(shareIDs []string) error {
 		return fmt.Errorf("failed to marshal request data: %w", err)
 	}
 
-	reqURL := DRIVE_DOMAIN + SHARE_DELETE + "?" + queryParams.Encode()
+	reqURL := qc.baseURL + SHARE_DELETE + "?" + queryParams.Encode()
 	respMap, err := qc.makeRequest("POST", reqURL, bytes.NewBuffer(jsonData), nil)
 	if err != nil {
 		return fmt.Errorf("request failed: %w", err)
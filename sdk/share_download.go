@@ -0,0 +1,149 @@
+package sdk
+
+import (
+	"fmt"
+	"time"
+)
+
+// DownloadShare 把分享链接中的全部内容下载到本地 localDir（保持分享内的目录结构），不需要事先手动转存
+// shareText: 分享链接（可带提取码文本）；passcode 为空时使用从链接文本中提取的提取码
+// 分享页接口不为非本人的文件返回下载链接，因此流程为：在网盘根目录建临时目录 → 全部转存进去 → DownloadDir 下载 → 删除临时目录
+// 转存期间占用自己的网盘空间；删除的临时目录进入回收站。无论下载是否成功都会尝试删除，删除失败时 Data.cleanup_error 说明原因
+// 返回 Data 在 DownloadDir 的基础上增加 pwd_id、method（temp_save）、temp_dir、cleaned_up、cleanup_error
+func (qc *QuarkClient) DownloadShare(shareText, passcode, localDir string, opts DownloadDirOptions) (*StandardResponse, error) {
+	shareInfo, err := qc.GetShareInfo(shareText)
+	if err != nil {
+		return &StandardResponse{
+			Success: false,
+			Code:    "INVALID_SHARE_LINK",
+			Message: fmt.Sprintf("failed to parse share link: %v", err),
+		}, nil
+	}
+	if passcode == "" {
+		passcode = shareInfo.Passcode
+	}
+	stokenData, err := qc.GetShareStoken(shareInfo.PwdID, passcode)
+	if err != nil {
+		return &StandardResponse{
+			Success: false,
+			Code:    "GET_STOKEN_ERROR",
+			Message: fmt.Sprintf("failed to get share stoken: %v", err),
+		}, nil
+	}
+	stoken, _ := stokenData["stoken"].(string)
+	if stoken == "" {
+		return &StandardResponse{
+			Success: false,
+			Code:    "INVALID_STOKEN",
+			Message: "stoken not found in response",
+		}, nil
+	}
+
+	// 分享为空时不必创建临时目录
+	listData, err := qc.GetShareList(shareInfo.PwdID, stoken, "0", 1, 1, "file_name", "asc")
+	if err != nil {
+		return &StandardResponse{
+			Success: false,
+			Code:    "GET_SHARE_LIST_ERROR",
+			Message: fmt.Sprintf("failed to list share: %v", err),
+		}, nil
+	}
+	if list, _ := listData["list"].([]interface{}); len(list) == 0 {
+		return &StandardResponse{
+			Success: false,
+			Code:    "SHARE_EMPTY",
+			Message: "share contains no files",
+		}, nil
+	}
+
+	tempName := fmt.Sprintf("%s%s-%d", SHARE_DOWNLOAD_TEMP_PREFIX, shareInfo.PwdID, time.Now().UnixNano())
+	tempDir := "/" + tempName
+	createResp, err := qc.CreateFolder(tempName, "0")
+	if err != nil {
+		return nil, err
+	}
+	if !createResp.Success {
+		return &StandardResponse{
+			Success: false,
+			Code:    createResp.Code,
+			Message: fmt.Sprintf("failed to create temporary directory %s: %s", tempDir, createResp.Message),
+		}, nil
+	}
+	tempFid, _ := createResp.Data["fid"].(string)
+
+	response, err := qc.saveAndDownloadShare(shareInfo.PwdID, stoken, tempFid, tempDir, localDir, opts)
+
+	// 无论下载结果如何都删除临时目录
+	cleanupError := ""
+	if deleteResp, deleteErr := qc.Delete(tempDir); deleteErr != nil {
+		cleanupError = deleteErr.Error()
+	} else if !deleteResp.Success {
+		cleanupError = deleteResp.Message
+	}
+	if err != nil {
+		if cleanupError != "" {
+			return nil, fmt.Errorf("%w (temporary directory %s not removed: %s)", err, tempDir, cleanupError)
+		}
+		return nil, err
+	}
+	if response.Data == nil {
+		response.Data = map[string]interface{}{}
+	}
+	response.Data["pwd_id"] = shareInfo.PwdID
+	response.Data["method"] = "temp_save"
+	response.Data["temp_dir"] = tempDir
+	response.Data["cleaned_up"] = cleanupError == ""
+	if cleanupError != "" {
+		response.Data["cleanup_error"] = cleanupError
+	}
+	return response, nil
+}
+
+// saveAndDownloadShare 把分享的全部内容转存到临时目录 tempFid（路径 tempDir），等待转存完成后下载其内容到 localDir
+func (qc *QuarkClient) saveAndDownloadShare(pwdID, stoken, tempFid, tempDir, localDir string, opts DownloadDirOptions) (*StandardResponse, error) {
+	saveData, err := qc.SaveShareFile(pwdID, stoken, []string{}, []string{}, tempFid, true)
+	if err != nil {
+		return &StandardResponse{
+			Success: false,
+			Code:    "SAVE_SHARE_ERROR",
+			Message: fmt.Sprintf("failed to save share files: %v", err),
+		}, nil
+	}
+	if taskID, _ := saveData["task_id"].(string); taskID != "" {
+		if _, err := qc.WaitShareSaveTask(taskID); err != nil {
+			return &StandardResponse{
+				Success: false,
+				Code:    "SAVE_SHARE_ERROR",
+				Message: fmt.Sprintf("failed to save share files: %v", err),
+			}, nil
+		}
+	}
+	// 转存由服务端异步写入临时目录，清掉缓存以免列出转存前的空目录
+	qc.invalidatePathCacheByFid(tempFid)
+
+	opts.ContentsOnly = true
+	return qc.DownloadDir(tempDir, localDir, opts)
+}
+
+// WaitShareSaveTask 轮询转存任务 taskID（SaveShareFile 返回 Data 中的 task_id）直到完成，返回转存成功的顶层 fid；
+// 任务失败（如网盘容量不足，错误中带服务端给出的原因）或超过 SHARE_SAVE_TIMEOUT 时返回错误
+func (qc *QuarkClient) WaitShareSaveTask(taskID string) (*ShareSaveResult, error) {
+	data, err := qc.pollTask(taskID, SHARE_SAVE_POLL_INTERVAL, SHARE_SAVE_TIMEOUT)
+	if err != nil {
+		return nil, err
+	}
+	var saved struct {
+		SaveAs struct {
+			SaveAsTopFids []string `json:"save_as_top_fids"`
+			ToPdirFid     string   `json:"to_pdir_fid"`
+		} `json:"save_as"`
+	}
+	if err := qc.parseResponse(data, &saved); err != nil {
+		return nil, fmt.Errorf("failed to decode task response: %w", err)
+	}
+	result := &ShareSaveResult{TaskID: taskID, SavedFids: saved.SaveAs.SaveAsTopFids, ToPdirFid: saved.SaveAs.ToPdirFid}
+	if result.SavedFids == nil {
+		result.SavedFids = []string{}
+	}
+	return result, nil
+}
@@ -0,0 +1,157 @@
+package sdk
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestDownloadShare(t *testing.T) {
+	content := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "content of %s", strings.TrimPrefix(r.URL.Path, "/"))
+	}))
+	defer content.Close()
+
+	entry := func(fid, name string, dir bool) map[string]interface{} {
+		return map[string]interface{}{"fid": fid, "file_name": name, "dir": dir, "size": 10}
+	}
+	var mu sync.Mutex
+	tempName := ""
+	var deleted []string
+	dirs := map[string][]map[string]interface{}{
+		"tmp":   {entry("album", "album", true), entry("f1", "readme.txt", false)},
+		"album": {entry("f2", "song.mp3", false)},
+	}
+	client := createMockClient(t, func(req *http.Request) (*http.Response, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case req.URL.Path == SHARE_SHAREPAGE_TOKEN:
+			return jsonResponse(req, `{"status":200,"code":0,"data":{"stoken":"st"}}`), nil
+		case req.URL.Path == SHARE_SHAREPAGE_DETAIL:
+			return jsonResponse(req, `{"status":200,"code":0,"data":{"list":[{"fid":"s1","file_name":"album","dir":true}]}}`), nil
+		case req.URL.Path == CREATE_FOLDER && req.Method == "POST":
+			var body struct {
+				FileName string `json:"file_name"`
+				PdirFid  string `json:"pdir_fid"`
+			}
+			json.NewDecoder(req.Body).Decode(&body)
+			if body.PdirFid != "0" || !strings.HasPrefix(body.FileName, SHARE_DOWNLOAD_TEMP_PREFIX+"abc123-") {
+				t.Errorf("create folder %q in %q", body.FileName, body.PdirFid)
+			}
+			tempName = body.FileName
+			return jsonResponse(req, `{"status":200,"code":0,"data":{"fid":"tmp"}}`), nil
+		case req.URL.Path == SHARE_SHAREPAGE_SAVE:
+			var body struct {
+				ToPdirFid string `json:"to_pdir_fid"`
+			}
+			json.NewDecoder(req.Body).Decode(&body)
+			if body.ToPdirFid != "tmp" {
+				t.Errorf("saved into %q, want temporary directory", body.ToPdirFid)
+			}
+			return jsonResponse(req, `{"status":200,"code":0,"data":{"task_id":"task1"}}`), nil
+		case req.URL.Path == TASK:
+			return jsonResponse(req, `{"status":200,"code":0,"data":{"status":2}}`), nil
+		case req.URL.Path == FILE_DOWNLOAD:
+			var body struct {
+				Fids []string `json:"fids"`
+			}
+			json.NewDecoder(req.Body).Decode(&body)
+			return jsonResponse(req, fmt.Sprintf(`{"status":200,"code":0,"data":[{"download_url":"%s/%s"}]}`, content.URL, body.Fids[0])), nil
+		case req.URL.Path == FILE_DELETE:
+			var body struct {
+				Filelist []string `json:"filelist"`
+			}
+			json.NewDecoder(req.Body).Decode(&body)
+			deleted = append(deleted, body.Filelist...)
+			return jsonResponse(req, `{"status":200,"code":0,"data":{}}`), nil
+		}
+		items := dirs[req.URL.Query().Get("pdir_fid")]
+		if req.URL.Query().Get("pdir_fid") == "0" && tempName != "" {
+			items = []map[string]interface{}{entry("tmp", tempName, true)}
+		}
+		if items == nil {
+			items = []map[string]interface{}{}
+		}
+		body, _ := json.Marshal(map[string]interface{}{
+			"status":   200,
+			"code":     0,
+			"data":     map[string]interface{}{"list": items},
+			"metadata": map[string]interface{}{"_total": len(items)},
+		})
+		return jsonResponse(req, string(body)), nil
+	})
+
+	localDir := t.TempDir()
+	resp, err := client.DownloadShare("https://pan.quark.cn/s/abc123", "", localDir, DownloadDirOptions{})
+	if err != nil || !resp.Success || resp.Code != "OK" {
+		t.Fatalf("DownloadShare() = %+v, %v", resp, err)
+	}
+	if resp.Data["method"] != "temp_save" || resp.Data["cleaned_up"] != true || resp.Data["temp_dir"] != "/"+tempName {
+		t.Errorf("DownloadShare() data = %+v", resp.Data)
+	}
+	// 内容直接保存在 localDir 下，不带临时目录名
+	for localPath, want := range map[string]string{
+		"readme.txt":     "content of f1",
+		"album/song.mp3": "content of f2",
+	} {
+		data, err := os.ReadFile(filepath.Join(localDir, localPath))
+		if err != nil || string(data) != want {
+			t.Errorf("%s = %q, %v, want %q", localPath, data, err, want)
+		}
+	}
+	if len(deleted) != 1 || deleted[0] != "tmp" {
+		t.Errorf("deleted = %v, want the temporary directory", deleted)
+	}
+}
+
+func TestWaitShareSaveTask(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      string
+		wantFids  string
+		wantError string
+	}{
+		{
+			name:     "finished",
+			body:     `{"status":200,"code":0,"data":{"status":2,"save_as":{"save_as_top_fids":["n1","n2"],"to_pdir_fid":"dest"}}}`,
+			wantFids: "[n1 n2]",
+		},
+		{
+			name:      "task failed",
+			body:      `{"status":200,"code":0,"message":"capacity limit","data":{"status":3}}`,
+			wantError: "capacity limit",
+		},
+		{
+			name:      "error code",
+			body:      `{"status":400,"code":32003,"message":"capacity limit[2]","data":{}}`,
+			wantError: "code=32003",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := createMockClient(t, func(req *http.Request) (*http.Response, error) {
+				if req.URL.Path != TASK || req.URL.Query().Get("task_id") != "task1" {
+					t.Errorf("unexpected request %s %s", req.Method, req.URL)
+				}
+				return jsonResponse(req, tt.body), nil
+			})
+			result, err := client.WaitShareSaveTask("task1")
+			if tt.wantError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantError) {
+					t.Errorf("WaitShareSaveTask() = %+v, %v, want error containing %q", result, err, tt.wantError)
+				}
+				return
+			}
+			if err != nil || fmt.Sprint(result.SavedFids) != tt.wantFids || result.ToPdirFid != "dest" || result.TaskID != "task1" {
+				t.Errorf("WaitShareSaveTask() = %+v, %v, want fids %s", result, err, tt.wantFids)
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package sdk
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// SelectShareFiles 递归列出分享内容，返回 sel 选中的条目（按分享内的遍历顺序）；
+// 命中的目录作为一项返回，不再进入。没有条目命中时返回空切片
+func (qc *QuarkClient) SelectShareFiles(pwdID, stoken string, sel ShareSelection) ([]ShareFileEntry, error) {
+	for _, pattern := range sel.Patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid select pattern %q: %w", pattern, err)
+		}
+	}
+	wantFids := make(map[string]bool, len(sel.Fids))
+	for _, fid := range sel.Fids {
+		wantFids[fid] = true
+	}
+
+	selected := make([]ShareFileEntry, 0)
+	var walk func(pdirFid, dir string) error
+	walk = func(pdirFid, dir string) error {
+		entries, err := qc.listShareDir(pwdID, stoken, pdirFid, dir)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if wantFids[entry.Fid] || matchShareSelection(sel.Patterns, entry.Path) {
+				selected = append(selected, entry)
+				continue
+			}
+			if entry.IsDir {
+				if err := walk(entry.Fid, entry.Path); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	if err := walk("0", ""); err != nil {
+		return nil, err
+	}
+	return selected, nil
+}
+
+// SaveShareFiles 转存分享中的指定条目（通常来自 SelectShareFiles）到目录 toPdirFid，
+// 转存接口要求同一请求中的条目在同一个分享内目录下，因此按 PdirFid 分批请求（pdir_save_all 为 false），
+// 子目录中的条目直接保存在 toPdirFid 下；返回每批的转存结果（含 task_id），任一批失败时返回错误
+func (qc *QuarkClient) SaveShareFiles(pwdID, stoken string, entries []ShareFileEntry, toPdirFid string) ([]map[string]interface{}, error) {
+	var order []string
+	batches := make(map[string][]ShareFileEntry)
+	for _, entry := range entries {
+		if _, ok := batches[entry.PdirFid]; !ok {
+			order = append(order, entry.PdirFid)
+		}
+		batches[entry.PdirFid] = append(batches[entry.PdirFid], entry)
+	}
+
+	results := make([]map[string]interface{}, 0, len(order))
+	for _, pdirFid := range order {
+		var fids, tokens []string
+		for _, entry := range batches[pdirFid] {
+			fids = append(fids, entry.Fid)
+			tokens = append(tokens, entry.Token)
+		}
+		data, err := qc.saveShareFiles(pwdID, stoken, pdirFid, fids, tokens, toPdirFid, false)
+		if err != nil {
+			return results, err
+		}
+		results = append(results, data)
+	}
+	return results, nil
+}
+
+// ListSharePage 列出分享内目录 pdirFid（"0" 为分享根目录）的第 page 页（从 1 开始，每页 size 条），目录在前、按名称排序；
+// 返回条目的 Path 为文件名，条数少于 size 时已是最后一页
+func (qc *QuarkClient) ListSharePage(pwdID, stoken, pdirFid string, page, size int) ([]ShareFileEntry, error) {
+	data, err := qc.GetShareList(pwdID, stoken, pdirFid, page, size, "file_name", "asc")
+	if err != nil {
+		return nil, err
+	}
+	list, _ := data["list"].([]interface{})
+	entries := make([]ShareFileEntry, 0, len(list))
+	for _, item := range list {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		entry := ShareFileEntry{PdirFid: pdirFid}
+		entry.Fid, _ = m["fid"].(string)
+		entry.Token, _ = m["share_fid_token"].(string)
+		entry.IsDir, _ = m["dir"].(bool)
+		if size, ok := m["size"].(float64); ok {
+			entry.Size = int64(size)
+		}
+		entry.Path, _ = m["file_name"].(string)
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// listShareDir 列出分享内目录 pdirFid（相对路径 dir）下的全部条目，自动翻页
+func (qc *QuarkClient) listShareDir(pwdID, stoken, pdirFid, dir string) ([]ShareFileEntry, error) {
+	var entries []ShareFileEntry
+	for page := 1; ; page++ {
+		pageEntries, err := qc.ListSharePage(pwdID, stoken, pdirFid, page, LIST_PAGE_SIZE)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range pageEntries {
+			entry.Path = path.Join(dir, entry.Path)
+			entries = append(entries, entry)
+		}
+		if len(pageEntries) < LIST_PAGE_SIZE {
+			return entries, nil
+		}
+	}
+}
+
+// matchShareSelection 判断分享内的相对路径是否命中任一模式
+func matchShareSelection(patterns []string, sharePath string) bool {
+	for _, pattern := range patterns {
+		if !strings.Contains(pattern, "/") {
+			if ok, _ := path.Match(pattern, path.Base(sharePath)); ok {
+				return true
+			}
+			continue
+		}
+		if matchSegments(strings.Split(strings.Trim(pattern, "/"), "/"), strings.Split(sharePath, "/")) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,117 @@
+package sdk
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestSelectAndSaveShareFiles(t *testing.T) {
+	entry := func(fid, name string, dir bool) map[string]interface{} {
+		return map[string]interface{}{"fid": fid, "file_name": name, "dir": dir, "size": 10, "share_fid_token": "tok_" + fid}
+	}
+	dirs := map[string][]map[string]interface{}{
+		"0":  {entry("d1", "S01", true), entry("d2", "extras", true), entry("f0", "readme.txt", false)},
+		"d1": {entry("f1", "S01E01.mkv", false), entry("f2", "S01E02.mkv", false), entry("f3", "S01E01.srt", false)},
+		"d2": {entry("d3", "subs", true)},
+		"d3": {entry("f4", "S01E02.srt", false)},
+	}
+	var saves []string
+	client := createMockClient(t, func(req *http.Request) (*http.Response, error) {
+		switch req.URL.Path {
+		case SHARE_SHAREPAGE_DETAIL:
+			items := dirs[req.URL.Query().Get("pdir_fid")]
+			body, _ := json.Marshal(map[string]interface{}{"status": 200, "code": 0, "data": map[string]interface{}{"list": items}})
+			return jsonResponse(req, string(body)), nil
+		case SHARE_SHAREPAGE_SAVE:
+			var body struct {
+				PdirFid     string   `json:"pdir_fid"`
+				FidList     []string `json:"fid_list"`
+				TokenList   []string `json:"share_token_list"`
+				ToPdirFid   string   `json:"to_pdir_fid"`
+				PdirSaveAll bool     `json:"pdir_save_all"`
+			}
+			json.NewDecoder(req.Body).Decode(&body)
+			if body.PdirSaveAll || body.ToPdirFid != "dest" {
+				t.Errorf("save request pdir_save_all=%v to_pdir_fid=%s", body.PdirSaveAll, body.ToPdirFid)
+			}
+			saves = append(saves, fmt.Sprintf("%s:%s:%s", body.PdirFid, strings.Join(body.FidList, ","), strings.Join(body.TokenList, ",")))
+			return jsonResponse(req, `{"status":200,"code":0,"data":{"task_id":"task"}}`), nil
+		}
+		t.Errorf("unexpected request %s %s", req.Method, req.URL)
+		return jsonResponse(req, `{"status":404,"code":1}`), nil
+	})
+
+	tests := []struct {
+		name string
+		sel  ShareSelection
+		want string
+	}{
+		{name: "name glob in subfolder", sel: ShareSelection{Patterns: []string{"S01E01*"}}, want: "[S01/S01E01.mkv S01/S01E01.srt]"},
+		{name: "several patterns", sel: ShareSelection{Patterns: []string{"*.srt", "readme.txt"}}, want: "[S01/S01E01.srt extras/subs/S01E02.srt readme.txt]"},
+		{name: "path pattern", sel: ShareSelection{Patterns: []string{"extras/**/*.srt"}}, want: "[extras/subs/S01E02.srt]"},
+		{name: "matching folder is not entered", sel: ShareSelection{Patterns: []string{"S01"}}, want: "[S01]"},
+		{name: "fids", sel: ShareSelection{Fids: []string{"f2", "f4"}}, want: "[S01/S01E02.mkv extras/subs/S01E02.srt]"},
+		{name: "no match", sel: ShareSelection{Patterns: []string{"*.iso"}}, want: "[]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selected, err := client.SelectShareFiles("pwd", "st", tt.sel)
+			if err != nil {
+				t.Fatalf("SelectShareFiles() error = %v", err)
+			}
+			var paths []string
+			for _, e := range selected {
+				paths = append(paths, e.Path)
+			}
+			sort.Strings(paths)
+			if got := fmt.Sprint(paths); got != tt.want {
+				t.Errorf("SelectShareFiles() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	selected, err := client.SelectShareFiles("pwd", "st", ShareSelection{Patterns: []string{"S01E01*", "readme.txt"}})
+	if err != nil {
+		t.Fatalf("SelectShareFiles() error = %v", err)
+	}
+	results, err := client.SaveShareFiles("pwd", "st", selected, "dest")
+	if err != nil || len(results) != 2 {
+		t.Fatalf("SaveShareFiles() = %v, %v, want 2 batches", results, err)
+	}
+	// 同一分享目录下的条目在一个请求中，pdir_fid 为它们所在的目录
+	sort.Strings(saves)
+	if want := "[0:f0:tok_f0 d1:f1,f3:tok_f1,tok_f3]"; fmt.Sprint(saves) != want {
+		t.Errorf("save requests = %v, want %s", saves, want)
+	}
+
+	if _, err := client.SelectShareFiles("pwd", "st", ShareSelection{Patterns: []string{"[abc"}}); err == nil {
+		t.Error("SelectShareFiles() expected error for invalid pattern")
+	}
+}
+
+func TestListSharePage(t *testing.T) {
+	client := createMockClient(t, func(req *http.Request) (*http.Response, error) {
+		q := req.URL.Query()
+		if req.URL.Path != SHARE_SHAREPAGE_DETAIL || q.Get("pdir_fid") != "d1" || q.Get("_page") != "2" || q.Get("_size") != "50" {
+			t.Errorf("unexpected request %s %s", req.Method, req.URL)
+		}
+		return jsonResponse(req, `{"status":200,"code":0,"data":{"list":[
+			{"fid":"sub","file_name":"subs","dir":true,"share_fid_token":"tok_sub"},
+			{"fid":"f1","file_name":"S01E01.mkv","dir":false,"size":1048576,"share_fid_token":"tok_f1"}]}}`), nil
+	})
+	entries, err := client.ListSharePage("pwd", "st", "d1", 2, 50)
+	if err != nil {
+		t.Fatalf("ListSharePage() error = %v", err)
+	}
+	want := []ShareFileEntry{
+		{Fid: "sub", Token: "tok_sub", PdirFid: "d1", Path: "subs", IsDir: true},
+		{Fid: "f1", Token: "tok_f1", PdirFid: "d1", Path: "S01E01.mkv", Size: 1048576},
+	}
+	if fmt.Sprint(entries) != fmt.Sprint(want) {
+		t.Errorf("ListSharePage() = %+v, want %+v", entries, want)
+	}
+}
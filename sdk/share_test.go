@@ -1,6 +1,7 @@
 package sdk
 
 import (
+	"net/http"
 	"testing"
 )
 
@@ -289,3 +290,34 @@ func TestSetSharePassword(t *testing.T) {
 	}
 }
 
+
+func TestGetShareStatus(t *testing.T) {
+	client := createMockClient(t, func(req *http.Request) (*http.Response, error) {
+		switch req.URL.Path {
+		case SHARE_MYPAGE_DETAIL:
+			return jsonResponse(req, `{"status":200,"code":0,"data":{"list":[
+				{"share_id":"share_1","first_file":{"fid":"fid_shared"}}
+			]}}`), nil
+		case SHARE_PASSWORD:
+			return jsonResponse(req, `{"status":200,"code":0,"data":{"share_url":"https://pan.quark.cn/s/abc","pwd_id":"abc"}}`), nil
+		}
+		t.Errorf("unexpected request %s", req.URL.Path)
+		return jsonResponse(req, `{"status":404,"code":1}`), nil
+	})
+
+	status, err := client.GetShareStatus("fid_shared")
+	if err != nil {
+		t.Fatalf("GetShareStatus() error = %v", err)
+	}
+	if status["shared"] != true || status["share_id"] != "share_1" || status["share_url"] != "https://pan.quark.cn/s/abc" {
+		t.Errorf("GetShareStatus() = %v, want shared share_1 with url", status)
+	}
+
+	status, err = client.GetShareStatus("fid_private")
+	if err != nil {
+		t.Fatalf("GetShareStatus() not shared error = %v", err)
+	}
+	if status["shared"] != false || status["share_id"] != nil {
+		t.Errorf("GetShareStatus() = %v, want shared false", status)
+	}
+}
@@ -0,0 +1,145 @@
+package sdk
+
+import (
+	"sync"
+	"time"
+)
+
+// speedSample 一次进度采样：时间和累计完成字节数
+type speedSample struct {
+	at   time.Time
+	done int64
+}
+
+// speedMeter 按最近 PROGRESS_SPEED_WINDOW 内的采样计算滑动平均速度，同时给出瞬时速度和整体平均速度
+// base 为开始计时时已完成的字节数（如续传的部分），不计入速度
+type speedMeter struct {
+	start   time.Time
+	base    int64
+	samples []speedSample // 窗口内的采样（保留窗口外最近的一个作为起点），按时间递增
+}
+
+// newSpeedMeter 从现在开始计时，base 为已完成的字节数
+func newSpeedMeter(base int64) *speedMeter {
+	now := time.Now()
+	return &speedMeter{start: now, base: base, samples: []speedSample{{at: now, done: base}}}
+}
+
+// update 记录累计完成字节数 done，返回瞬时速度（与上一次采样之间）和窗口内的平均速度（字节/秒）
+func (m *speedMeter) update(done int64) (instant, average float64) {
+	now := time.Now()
+	last := m.samples[len(m.samples)-1]
+	if elapsed := now.Sub(last.at).Seconds(); elapsed > 0 {
+		instant = float64(done-last.done) / elapsed
+	}
+	m.samples = append(m.samples, speedSample{at: now, done: done})
+	// 丢弃窗口外的采样，但保留一个作为窗口起点
+	cutoff := now.Add(-PROGRESS_SPEED_WINDOW)
+	drop := 0
+	for drop+1 < len(m.samples) && !m.samples[drop+1].at.After(cutoff) {
+		drop++
+	}
+	m.samples = m.samples[drop:]
+
+	first := m.samples[0]
+	if elapsed := now.Sub(first.at).Seconds(); elapsed > 0 {
+		average = float64(done-first.done) / elapsed
+	}
+	if instant < 0 {
+		instant = 0
+	}
+	if average < 0 {
+		average = 0
+	}
+	return instant, average
+}
+
+// overall 返回开始以来的整体平均速度（字节/秒）和耗时
+func (m *speedMeter) overall(done int64) (float64, time.Duration) {
+	elapsed := time.Since(m.start)
+	if elapsed <= 0 || done <= m.base {
+		return 0, elapsed
+	}
+	return float64(done-m.base) / elapsed.Seconds(), elapsed
+}
+
+// estimateRemaining 按速度 speed 估算剩余时间，total 未知（< 0）或速度为 0 时返回 -1
+func estimateRemaining(done, total int64, speed float64) time.Duration {
+	if total < 0 || speed <= 0 {
+		return -1
+	}
+	if done >= total {
+		return 0
+	}
+	return time.Duration(float64(total-done) / speed * float64(time.Second))
+}
+
+// downloadProgressMeter 为下载进度回调补充 Speed、InstantSpeed、ETA、Elapsed 字段，可并发调用
+// 阶段切换（下载 → 校验）时先为上一阶段回调一次 Done 事件，再为新阶段重新计时；进度回退（从头重新下载）时也重新计时
+type downloadProgressMeter struct {
+	mu       sync.Mutex
+	callback func(*DownloadProgress)
+	meter    *speedMeter
+	last     DownloadProgress
+	reported bool // 是否已有进度回调
+}
+
+// newDownloadProgressMeter 包装进度回调，callback 为 nil 时返回 nil（其方法均可在 nil 上调用）
+func newDownloadProgressMeter(callback func(*DownloadProgress)) *downloadProgressMeter {
+	if callback == nil {
+		return nil
+	}
+	return &downloadProgressMeter{callback: callback}
+}
+
+// progressFunc 返回作为 DownloadOptions.Progress 使用的回调，m 为 nil 时返回 nil
+func (m *downloadProgressMeter) progressFunc() func(*DownloadProgress) {
+	if m == nil {
+		return nil
+	}
+	return m.report
+}
+
+// report 计算速度后调用原回调
+func (m *downloadProgressMeter) report(p *DownloadProgress) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.reported && m.last.Phase != p.Phase {
+		m.finishLocked()
+	}
+	if !m.reported || m.last.Phase != p.Phase || p.Downloaded < m.last.Downloaded {
+		// 各阶段开始时先回调一次起点（续传时为已下载的字节数），起点之前的部分不计入速度
+		m.meter = newSpeedMeter(p.Downloaded)
+	}
+	progress := *p
+	progress.InstantSpeed, progress.Speed = m.meter.update(p.Downloaded)
+	progress.ETA = estimateRemaining(p.Downloaded, p.Total, progress.Speed)
+	progress.Elapsed = time.Since(m.meter.start)
+	m.last, m.reported = progress, true
+	m.callback(&progress)
+}
+
+// finish 下载成功结束时调用：为最后一个阶段回调一次 Done 事件（Speed 为整体平均速度，Elapsed 为总耗时）
+func (m *downloadProgressMeter) finish() {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.reported {
+		m.finishLocked()
+	}
+}
+
+func (m *downloadProgressMeter) finishLocked() {
+	if m.last.Done {
+		return
+	}
+	done := m.last
+	done.Speed, done.Elapsed = m.meter.overall(done.Downloaded)
+	done.InstantSpeed = 0
+	done.ETA = 0
+	done.Done = true
+	m.last = done
+	m.callback(&done)
+}
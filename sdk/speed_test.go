@@ -0,0 +1,80 @@
+package sdk
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEstimateRemaining(t *testing.T) {
+	tests := []struct {
+		done, total int64
+		speed       float64
+		want        time.Duration
+	}{
+		{done: 0, total: 100, speed: 10, want: 10 * time.Second},
+		{done: 50, total: 100, speed: 100, want: 500 * time.Millisecond},
+		{done: 100, total: 100, speed: 10, want: 0},
+		{done: 10, total: -1, speed: 10, want: -1},
+		{done: 10, total: 100, speed: 0, want: -1},
+	}
+	for _, tt := range tests {
+		if got := estimateRemaining(tt.done, tt.total, tt.speed); got != tt.want {
+			t.Errorf("estimateRemaining(%d, %d, %v) = %v, want %v", tt.done, tt.total, tt.speed, got, tt.want)
+		}
+	}
+}
+
+func TestSpeedMeter_ExcludesBase(t *testing.T) {
+	meter := newSpeedMeter(1000)
+	time.Sleep(20 * time.Millisecond)
+	instant, average := meter.update(1100)
+	if instant <= 0 || average <= 0 {
+		t.Fatalf("update() = %v, %v, want positive speeds", instant, average)
+	}
+	// 20ms 内 100 字节，已完成的 1000 字节不计入：速度应远小于 1100 字节 / 20ms
+	if average > 100/0.015 {
+		t.Errorf("average = %v, base bytes were counted", average)
+	}
+	speed, elapsed := meter.overall(1100)
+	if speed <= 0 || elapsed < 20*time.Millisecond {
+		t.Errorf("overall() = %v, %v", speed, elapsed)
+	}
+}
+
+func TestDownloadProgressMeter(t *testing.T) {
+	if newDownloadProgressMeter(nil).progressFunc() != nil {
+		t.Fatal("nil callback should give nil progress func")
+	}
+
+	var events []DownloadProgress
+	meter := newDownloadProgressMeter(func(p *DownloadProgress) { events = append(events, *p) })
+	report := meter.progressFunc()
+	report(&DownloadProgress{Phase: DownloadPhaseDownload, Downloaded: 0, Total: 200})
+	time.Sleep(10 * time.Millisecond)
+	report(&DownloadProgress{Phase: DownloadPhaseDownload, Downloaded: 200, Total: 200})
+	report(&DownloadProgress{Phase: DownloadPhaseVerify, Downloaded: 0, Total: 200})
+	time.Sleep(10 * time.Millisecond)
+	report(&DownloadProgress{Phase: DownloadPhaseVerify, Downloaded: 200, Total: 200})
+	meter.finish()
+	meter.finish()
+
+	if len(events) != 6 {
+		t.Fatalf("got %d events, want 6: %+v", len(events), events)
+	}
+	if p := events[1]; p.Done || p.Speed <= 0 || p.InstantSpeed <= 0 || p.ETA != 0 {
+		t.Errorf("progress event = %+v, want speeds and zero ETA", p)
+	}
+	// 阶段切换前先为下载阶段回调一次完成事件
+	for _, i := range []int{2, 5} {
+		p := events[i]
+		if !p.Done || p.Speed <= 0 || p.Elapsed <= 0 || p.InstantSpeed != 0 {
+			t.Errorf("event %d = %+v, want done event with overall speed", i, p)
+		}
+	}
+	if events[2].Phase != DownloadPhaseDownload || events[5].Phase != DownloadPhaseVerify {
+		t.Errorf("done event phases = %q, %q", events[2].Phase, events[5].Phase)
+	}
+	if events[3].Done || events[3].Speed != 0 {
+		t.Errorf("verify start event = %+v, want restarted meter", events[3])
+	}
+}
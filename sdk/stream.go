@@ -0,0 +1,79 @@
+package sdk
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// videoQualityRank 清晰度从高到低的顺序，GetVideoStreams 按此排序，未知清晰度排在最后
+var videoQualityRank = map[string]int{"4k": 0, "2k": 1, "super": 2, "high": 3, "normal": 4, "low": 5}
+
+// GetVideoStreams 获取视频文件各清晰度的转码播放地址，按清晰度从高到低排序
+// 没有播放地址的清晰度（如需要会员）不返回；文件不是视频或尚未转码时返回空列表或错误，可改用原文件下载链接播放
+func (qc *QuarkClient) GetVideoStreams(fid string) ([]VideoStream, error) {
+	jsonData, err := json.Marshal(map[string]interface{}{
+		"fid":         fid,
+		"resolutions": VIDEO_PLAY_RESOLUTIONS,
+		"supports":    "fmp4,m3u8",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal play request: %w", err)
+	}
+	respMap, err := qc.makeRequest("POST", FILE_VIDEO_PLAY, bytes.NewBuffer(jsonData), nil)
+	if err != nil {
+		return nil, fmt.Errorf("play request failed: %w", err)
+	}
+
+	var playResp struct {
+		Code    int    `json:"code"`
+		Status  int    `json:"status"`
+		Message string `json:"message"`
+		Data    struct {
+			VideoList []struct {
+				Resolution string `json:"resolution"`
+				VideoInfo  struct {
+					URL      string `json:"url"`
+					Width    int    `json:"width"`
+					Height   int    `json:"height"`
+					Duration int64  `json:"duration"`
+					Size     int64  `json:"size"`
+				} `json:"video_info"`
+			} `json:"video_list"`
+		} `json:"data"`
+	}
+	if err := qc.parseResponse(respMap, &playResp); err != nil {
+		return nil, fmt.Errorf("failed to decode play response: %w", err)
+	}
+	if playResp.Code != 0 || playResp.Status != 200 {
+		return nil, fmt.Errorf("get play url failed: code=%d, status=%d, message=%s", playResp.Code, playResp.Status, playResp.Message)
+	}
+
+	streams := make([]VideoStream, 0, len(playResp.Data.VideoList))
+	for _, video := range playResp.Data.VideoList {
+		if video.VideoInfo.URL == "" {
+			continue
+		}
+		streams = append(streams, VideoStream{
+			Quality:  video.Resolution,
+			URL:      video.VideoInfo.URL,
+			Width:    video.VideoInfo.Width,
+			Height:   video.VideoInfo.Height,
+			Duration: video.VideoInfo.Duration,
+			Size:     video.VideoInfo.Size,
+		})
+	}
+	sort.SliceStable(streams, func(i, j int) bool {
+		return qualityRank(streams[i].Quality) < qualityRank(streams[j].Quality)
+	})
+	return streams, nil
+}
+
+// qualityRank 返回清晰度的排序位置，未知清晰度排在已知清晰度之后
+func qualityRank(quality string) int {
+	if rank, ok := videoQualityRank[quality]; ok {
+		return rank
+	}
+	return len(videoQualityRank)
+}
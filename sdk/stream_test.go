@@ -0,0 +1,38 @@
+package sdk
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestGetVideoStreams(t *testing.T) {
+	var requestPath string
+	client := createMockClient(t, func(req *http.Request) (*http.Response, error) {
+		requestPath = req.URL.Path
+		return jsonResponse(req, `{"status":200,"code":0,"data":{"video_list":[
+			{"resolution":"normal","video_info":{"url":"https://play.example/normal.m3u8","width":854,"height":480,"duration":60}},
+			{"resolution":"super","video_info":{"url":"https://play.example/super.m3u8","width":1920,"height":1080,"duration":60,"size":1000}},
+			{"resolution":"4k","video_info":{"url":""}},
+			{"resolution":"high","video_info":{"url":"https://play.example/high.m3u8","width":1280,"height":720,"duration":60}}
+		]}}`), nil
+	})
+
+	streams, err := client.GetVideoStreams("f1")
+	if err != nil {
+		t.Fatalf("GetVideoStreams() error = %v", err)
+	}
+	if requestPath != FILE_VIDEO_PLAY {
+		t.Errorf("request path = %q, want %q", requestPath, FILE_VIDEO_PLAY)
+	}
+	// 按清晰度从高到低排序，没有播放地址的 4k 不返回
+	var qualities []string
+	for _, s := range streams {
+		qualities = append(qualities, s.Quality)
+	}
+	if len(streams) != 3 || qualities[0] != "super" || qualities[1] != "high" || qualities[2] != "normal" {
+		t.Fatalf("GetVideoStreams() qualities = %v, want [super high normal]", qualities)
+	}
+	if streams[0].URL != "https://play.example/super.m3u8" || streams[0].Height != 1080 || streams[0].Size != 1000 {
+		t.Errorf("GetVideoStreams()[0] = %+v", streams[0])
+	}
+}
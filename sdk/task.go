@@ -0,0 +1,49 @@
+package sdk
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// pollTask 每隔 interval 查询一次异步任务 taskID（TASK 接口）直到完成（data.status 为 2），返回任务的 data；
+// 接口返回错误码、任务失败（data.status 为 3，错误中带服务端给出的原因）或超过 timeout 仍未完成时返回错误
+func (qc *QuarkClient) pollTask(taskID string, interval, timeout time.Duration) (map[string]interface{}, error) {
+	deadline := time.Now().Add(timeout)
+	for retry := 0; ; retry++ {
+		queryParams := url.Values{}
+		queryParams.Set("task_id", taskID)
+		queryParams.Set("retry_index", fmt.Sprintf("%d", retry))
+
+		reqURL := qc.baseURL + TASK + "?" + queryParams.Encode()
+		respMap, err := qc.makeRequest("GET", reqURL, nil, nil)
+		if err != nil {
+			return nil, fmt.Errorf("query task status failed: %w", err)
+		}
+		var taskResp struct {
+			Code    int                    `json:"code"`
+			Message string                 `json:"message"`
+			Data    map[string]interface{} `json:"data"`
+		}
+		if err := qc.parseResponse(respMap, &taskResp); err != nil {
+			return nil, fmt.Errorf("failed to decode task response: %w", err)
+		}
+		if taskResp.Code != 0 {
+			return nil, fmt.Errorf("task %s failed: code=%d, message=%s", taskID, taskResp.Code, taskResp.Message)
+		}
+		// data.status：0/1 进行中，2 完成，3 失败
+		switch status, _ := taskResp.Data["status"].(float64); status {
+		case 2:
+			return taskResp.Data, nil
+		case 3:
+			if taskResp.Message != "" {
+				return nil, fmt.Errorf("task %s failed: %s", taskID, taskResp.Message)
+			}
+			return nil, fmt.Errorf("task %s failed", taskID)
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("task %s timeout after %v", taskID, timeout)
+		}
+		time.Sleep(interval)
+	}
+}
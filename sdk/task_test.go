@@ -0,0 +1,55 @@
+package sdk
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPollTask(t *testing.T) {
+	tests := []struct {
+		name      string
+		statuses  []int
+		timeout   time.Duration
+		wantPolls int
+		wantError string
+	}{
+		{name: "finished after pending", statuses: []int{0, 1, 2}, timeout: time.Second, wantPolls: 3},
+		{name: "failed", statuses: []int{1, 3}, timeout: time.Second, wantPolls: 2, wantError: "task t1 failed"},
+		{name: "timeout", statuses: []int{1}, timeout: 0, wantPolls: 1, wantError: "timeout"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var retries []string
+			client := createMockClient(t, func(req *http.Request) (*http.Response, error) {
+				if req.URL.Path != TASK || req.URL.Query().Get("task_id") != "t1" {
+					t.Errorf("unexpected request %s %s", req.Method, req.URL)
+				}
+				status := tt.statuses[len(tt.statuses)-1]
+				if len(retries) < len(tt.statuses) {
+					status = tt.statuses[len(retries)]
+				}
+				retries = append(retries, req.URL.Query().Get("retry_index"))
+				return jsonResponse(req, fmt.Sprintf(`{"status":200,"code":0,"data":{"status":%d,"task_id":"t1"}}`, status)), nil
+			})
+			data, err := client.pollTask("t1", time.Millisecond, tt.timeout)
+			if len(retries) != tt.wantPolls {
+				t.Errorf("polled %d times (retry_index %v), want %d", len(retries), retries, tt.wantPolls)
+			}
+			if tt.wantError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantError) {
+					t.Errorf("pollTask() = %v, %v, want error containing %q", data, err, tt.wantError)
+				}
+				return
+			}
+			if err != nil || data["task_id"] != "t1" {
+				t.Errorf("pollTask() = %v, %v, want the task data", data, err)
+			}
+			if strings.Join(retries, ",") != "0,1,2" {
+				t.Errorf("retry_index = %v, want 0,1,2", retries)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package sdk
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"sync"
@@ -18,7 +19,9 @@ type FileInfo struct {
 
 // QuarkClient 夸克网盘 API 客户端
 type QuarkClient struct {
-	baseURL           string
+	baseURL           string            // 主要 API 域名（DRIVE_DOMAIN）
+	panDomain         string            // 用户信息域名（PAN_DOMAIN）
+	driveHDomain      string            // 分享页相关 API 域名（DRIVE_H_DOMAIN）
 	accessToken       string            // 当前使用的 access token
 	accessTokens      []string          // 所有可用的 access tokens
 	currentTokenIdx   int               // 当前使用的 token 索引
@@ -31,6 +34,18 @@ type QuarkClient struct {
 	failedTokens      map[int]bool  // 记录已失败的 token 索引
 	failedTokensMutex sync.RWMutex  // 失败 token 记录的锁
 	Debug             bool          // 调试开关，控制是否输出调试信息
+	pathCache         sync.Map      // 路径 → 文件信息缓存（*pathCacheEntry）
+	pathCacheTTL      time.Duration // 路径缓存有效期，<= 0 表示关闭
+	listRetries       int           // 列目录单页请求的最大重试次数
+	downloadRetries   int           // 下载的最大重试次数
+	downloadRetryWait time.Duration // 下载重试前的等待时间
+	uploadPartTimeout time.Duration // 分片上传的传输超时，0 表示按分片大小计算
+	uploadMinSpeed    int64         // 分片上传低速阈值（字节/秒），0 表示不检测
+	uploadSpeedWindow time.Duration // 低速检测的统计窗口
+	uploadStateDir    string        // 断点续传状态文件目录，空表示用户缓存目录下的 UPLOAD_STATE_DIR
+
+	ensureDirMu    sync.Mutex                // 保护 ensureDirCalls
+	ensureDirCalls map[string]*ensureDirCall // 进行中的 EnsureDir 调用（路径 → 调用），同一路径并发时只建一次
 }
 
 // QuarkFileInfo 夸克网盘文件信息
@@ -38,9 +53,10 @@ type QuarkFileInfo struct {
 	Fid         string `json:"fid"`                    // 文件ID
 	Name        string `json:"file_name"`              // 文件名
 	Path        string `json:"path"`                   // 文件路径
+	PdirFid     string `json:"pdir_fid,omitempty"`     // 父目录ID，路径未知时可用于继续向下列目录
 	Size        int64  `json:"size"`                   // 文件大小
-	CreateTime  int64  `json:"ctime"`                  // 创建时间戳（秒），从 created_at 或 l_created_at 转换
-	ModifyTime  int64  `json:"mtime"`                  // 修改时间戳（秒），从 updated_at 或 l_updated_at 转换
+	CreateTime  int64  `json:"ctime"`                  // 创建时间戳（秒），从 l_created_at 或 created_at 转换
+	ModifyTime  int64  `json:"mtime"`                  // 修改时间戳（秒），从 l_updated_at 或 updated_at 转换
 	IsDirectory bool   `json:"dir"`                    // 是否为目录，从 dir 或 file 字段获取
 	DownloadURL string `json:"download_url"`           // 下载链接（列表API中通常不存在）
 	CreatedAt   int64  `json:"created_at,omitempty"`   // 创建时间戳（毫秒），API原始字段
@@ -49,6 +65,149 @@ type QuarkFileInfo struct {
 	LUpdatedAt  int64  `json:"l_updated_at,omitempty"` // 修改时间戳（毫秒），API原始字段
 }
 
+// TreeNode 目录树节点（ListTree 返回）
+type TreeNode struct {
+	Fid         string      `json:"fid"`                 // 文件ID
+	Name        string      `json:"name"`                // 文件名
+	Path        string      `json:"path"`                // 完整路径
+	Size        int64       `json:"size"`                // 文件大小
+	IsDirectory bool        `json:"dir"`                 // 是否为目录
+	ModifyTime  int64       `json:"mtime"`               // 修改时间戳（秒）
+	Children    []*TreeNode `json:"children,omitempty"`  // 子节点，仅已展开的目录有
+	Truncated   bool        `json:"truncated,omitempty"` // 目录因超过最大深度未展开
+	Error       string      `json:"error,omitempty"`     // 目录列出失败的原因
+}
+
+// DiskUsage 目录占用统计（DiskUsage 返回）
+type DiskUsage struct {
+	Fid   string `json:"fid"`   // 目录ID
+	Path  string `json:"path"`  // 目录路径
+	Size  int64  `json:"size"`  // 子树中所有文件的总字节数
+	Files int    `json:"files"` // 子树中的文件数
+	Dirs  int    `json:"dirs"`  // 子树中的目录数（不含自身）
+}
+
+// DownloadInfo 下载接口返回的文件信息（GetDownloadInfo 返回）
+type DownloadInfo struct {
+	Fid         string `json:"fid"`            // 文件ID
+	FileName    string `json:"file_name"`      // 文件名
+	Size        int64  `json:"size"`           // 文件大小
+	DownloadURL string `json:"download_url"`   // 下载链接
+	ModifyTime  int64  `json:"mtime"`          // 修改时间戳（秒）
+	MD5         string `json:"md5,omitempty"`  // 文件 MD5（小写十六进制），接口未返回时为空
+	SHA1        string `json:"sha1,omitempty"` // 文件 SHA1（小写十六进制），接口未返回时为空
+}
+
+// DownloadOptions 单文件下载选项（DownloadFileWithOptions 使用）
+type DownloadOptions struct {
+	Connections  int                     // 分段并发连接数，<= 1 为单连接（支持断点续传），最大 MAX_DOWNLOAD_CONNECTIONS
+	Progress     func(*DownloadProgress) // 进度回调（分段模式下为各段合计），调用已串行化，可为 nil
+	URL          string                  // 已获取的下载链接（如 GetDownloadInfo 返回），为空时自动获取
+	ModTime      int64                   // 远端修改时间（秒），> 0 时下载完成后设为本地文件 mtime，为 0 时保留下载时间
+	Conflict     DownloadConflictPolicy  // 本地目标文件已存在时的处理方式，空字符串等同 overwrite
+	Verify       bool                    // 下载完成后校验大小与远端一致，接口返回 md5/sha1 时再校验哈希；不一致时删除文件并返回 ErrVerifyFailed
+	NoSpaceCheck bool                    // 为 true 时不在写入前检查目标分区剩余空间（如稀疏/压缩文件系统）
+	RateLimiter  *RateLimiter            // 下载限速器，可在多个并发下载间共享以限制总速率，nil 表示不限速
+	Offset       int64                   // 字节范围下载的起始位置，与 Length 均为 0 时下载整个文件
+	Length       int64                   // 字节范围下载的长度，0 表示从 Offset 到文件末尾；服务端不支持 Range 时返回 ErrRangeNotSupported
+}
+
+// DownloadConflictPolicy 下载时本地目标文件已存在的处理策略
+type DownloadConflictPolicy string
+
+const (
+	// DownloadConflictOverwrite 下载完成后覆盖已有文件（默认行为）
+	DownloadConflictOverwrite DownloadConflictPolicy = "overwrite"
+	// DownloadConflictSkip 目标已存在时不下载，返回 ErrDownloadSkipped
+	DownloadConflictSkip DownloadConflictPolicy = "skip"
+	// DownloadConflictRename 保留已有文件，新文件另存为 "name (1).ext"、"name (2).ext"……
+	DownloadConflictRename DownloadConflictPolicy = "rename"
+	// DownloadConflictFail 目标已存在时不下载，返回 ErrDownloadExists
+	DownloadConflictFail DownloadConflictPolicy = "fail"
+)
+
+// DownloadDirOptions 目录下载选项（DownloadDir 使用）
+type DownloadDirOptions struct {
+	Workers         int                         // 同时下载的文件数，<= 0 时为 DEFAULT_DOWNLOAD_WORKERS，最大 MAX_DOWNLOAD_WORKERS
+	Connections     int                         // 每个文件的分段连接数，见 DownloadOptions.Connections
+	OnFile          func(result DownloadResult) // 每个文件下载结束（成功或失败）后回调，调用已串行化，可为 nil
+	NoPreserveMtime bool                        // 为 true 时不把本地文件和目录的 mtime 设为远端修改时间
+	Verify          bool                        // 每个文件下载后校验，见 DownloadOptions.Verify
+	NoSpaceCheck    bool                        // 为 true 时不检查剩余空间；否则下载前按全部文件大小之和检查一次
+	RateLimiter     *RateLimiter                // 所有文件共享的下载限速器，nil 表示不限速
+	Conflict        DownloadConflictPolicy      // 对每个文件分别应用的冲突策略，见 DownloadOptions.Conflict
+	ContentsOnly    bool                        // 为 true 时目录内容直接保存在 localDir 下，不创建 <目录名> 子目录
+	OnProgress      func(DownloadBatchProgress) // 所有文件的总进度回调，调用已串行化，可为 nil
+	Recursive       bool                        // 仅 DownloadPaths：为 true 时下载参数中的目录，否则目录记为失败
+	Restart         bool                        // 为 true 时忽略目标目录下的断点状态文件，重新下载全部文件
+}
+
+// DownloadJob 多文件下载中的一个待下载文件（DownloadFiles 的参数）
+type DownloadJob struct {
+	Fid       string // 文件ID
+	Path      string // 远程路径
+	FileName  string // 下载时使用的文件名，为空时取 Path 的最后一段
+	LocalPath string // 本地保存路径
+	Size      int64  // 文件大小
+	ModTime   int64  // 远端修改时间（秒），> 0 且未设置 NoPreserveMtime 时设为本地 mtime
+}
+
+// DownloadBatchProgress 多文件下载的总进度
+type DownloadBatchProgress struct {
+	FilesDone  int           // 已结束的文件数（成功、跳过或失败）
+	FilesTotal int           // 文件总数
+	Failed     int           // 失败的文件数
+	Downloaded int64         // 已完成的字节数：成功或跳过的文件按大小计，下载中的文件按当前进度计
+	Total      int64         // 所有文件的总字节数
+	Speed      float64       // 最近 PROGRESS_SPEED_WINDOW 内的平均下载速度（字节/秒），不含跳过的文件和续传前已下载的部分
+	ETA        time.Duration // 按平均速度估算的剩余时间，-1 表示未知
+}
+
+// DownloadResult 目录下载中单个文件的结果
+type DownloadResult struct {
+	Fid       string `json:"fid"`               // 文件ID
+	Path      string `json:"path"`              // 远程路径
+	LocalPath string `json:"local_path"`        // 本地路径
+	Size      int64  `json:"size"`              // 文件大小
+	Error     string `json:"error,omitempty"`   // 失败原因，成功时为空
+	Skipped   bool   `json:"skipped,omitempty"` // 本地文件已存在且冲突策略为 skip，未下载
+}
+
+// VideoStream 视频的一个转码清晰度（GetVideoStreams 返回）
+type VideoStream struct {
+	Quality  string `json:"quality"`  // 清晰度：4k、2k、super、high、normal、low
+	URL      string `json:"url"`      // 播放地址（m3u8），请求时需附带 DownloadHeaders 中的请求头
+	Width    int    `json:"width"`    // 画面宽度
+	Height   int    `json:"height"`   // 画面高度
+	Duration int64  `json:"duration"` // 时长（秒）
+	Size     int64  `json:"size"`     // 转码后大小（字节），接口未返回时为 0
+}
+
+// Aria2Client aria2 JSON-RPC 客户端（NewAria2Client 创建）
+type Aria2Client struct {
+	RPCURL     string       // JSON-RPC 地址，如 http://127.0.0.1:6800/jsonrpc
+	Secret     string       // --rpc-secret，为空时不带 token
+	HttpClient *http.Client // 请求使用的 HTTP 客户端
+}
+
+// Aria2AddOptions 提交 aria2 下载任务的选项
+type Aria2AddOptions struct {
+	Dir     string            // aria2 所在主机上的保存目录，为空时使用 aria2 的默认目录
+	Out     string            // 相对 Dir 的输出文件名（可含子目录），为空时由 aria2 决定
+	Headers map[string]string // 请求下载链接时附带的请求头
+}
+
+// Aria2Status aria2 任务状态（aria2.tellStatus 的部分字段）
+type Aria2Status struct {
+	GID             string `json:"gid"`                     // 任务 gid
+	Status          string `json:"status"`                  // active、waiting、paused、complete、error、removed
+	TotalLength     int64  `json:"total_length"`            // 文件总字节数，未知时为 0
+	CompletedLength int64  `json:"completed_length"`        // 已下载字节数
+	DownloadSpeed   int64  `json:"download_speed"`          // 当前下载速度（字节/秒）
+	ErrorCode       string `json:"error_code,omitempty"`    // 失败时 aria2 的错误码
+	ErrorMessage    string `json:"error_message,omitempty"` // 失败时 aria2 的错误信息
+}
+
 // QuarkListResponse 列表响应
 type QuarkListResponse struct {
 	Data struct {
@@ -79,6 +238,46 @@ type Config struct {
 	Quark struct {
 		AccessTokens []string `json:"access_tokens"` // Access Token 数组
 	}
+	Defaults  DefaultsConfig  `json:"defaults"`  // 各命令的默认参数
+	Transfer  TransferConfig  `json:"transfer"`  // 传输相关配置
+	Sync      SyncConfig      `json:"sync"`      // 目录同步相关配置
+	Endpoints EndpointsConfig `json:"endpoints"` // API 域名覆盖（镜像/调试代理）
+}
+
+// EndpointsConfig API 域名覆盖配置，未设置的字段使用内置默认域名
+type EndpointsConfig struct {
+	PanDomain    string `json:"pan_domain,omitempty"`     // 用户信息域名，默认 PAN_DOMAIN
+	DriveDomain  string `json:"drive_domain,omitempty"`   // 主要 API 域名，默认 DRIVE_DOMAIN
+	DriveHDomain string `json:"drive_h_domain,omitempty"` // 分享页相关 API 域名，默认 DRIVE_H_DOMAIN
+}
+
+// SyncConfig 目录同步/目录上传相关配置
+type SyncConfig struct {
+	Ignore []string `json:"ignore,omitempty"` // 全局排除规则（gitignore 风格），与命令行 --ignore-file、.kuakeignore 合并生效
+}
+
+// TransferConfig 传输相关配置
+type TransferConfig struct {
+	UploadParallel        int    `json:"upload_parallel,omitempty"`         // 上传并发数（1-16），0 表示由服务端 part_thread 决定
+	ListRetries           *int   `json:"list_retries,omitempty"`            // 列目录单页请求遇到 5xx/网络错误时的最大重试次数（0-10），未设置时为 LIST_PAGE_MAX_RETRIES
+	DownloadRetries       *int   `json:"download_retries,omitempty"`        // 下载遇到网络错误或 403/5xx 时的最大重试次数（0-10），未设置时为 DOWNLOAD_MAX_RETRIES
+	DownloadRetryInterval string `json:"download_retry_interval,omitempty"` // 下载重试前的等待时间（Go duration，如 "5s"），未设置时为 DEFAULT_DOWNLOAD_RETRY_INTERVAL
+	UploadPartTimeout     string `json:"upload_part_timeout,omitempty"`     // 单个分片的传输超时（Go duration，如 "10m"），未设置或为 0 时按分片大小计算
+	UploadMinSpeed        string `json:"upload_min_speed,omitempty"`        // 分片上传低速阈值（字节/秒，可带 K/M/G 后缀，如 "512K"），一个统计窗口内平均低于此值时断开重传，未设置或为 0 时不检测
+	UploadSpeedWindow     string `json:"upload_speed_window,omitempty"`     // 低速检测的统计窗口（Go duration，如 "30s"），未设置时为 UPLOAD_SPEED_WINDOW
+	UploadStateDir        string `json:"upload_state_dir,omitempty"`        // 断点续传状态文件目录，未设置时为用户缓存目录下的 UPLOAD_STATE_DIR
+	SanitizeNames         bool   `json:"sanitize_names,omitempty"`          // upload 默认自动替换文件名中的非法字符（同 --sanitize-names），未设置时遇到非法文件名报 INVALID_FILE_NAME
+}
+
+// DefaultsConfig 命令默认值配置
+// 命令行未显式传参时使用，优先级：命令行 > 配置文件 > 内置默认值
+// 指针字段用于区分“未配置”和零值（如 share_days=0 表示永久有效）
+type DefaultsConfig struct {
+	DownloadDir    string `json:"download_dir,omitempty"`    // download 未指定 dest 时的本地保存目录，空表示仅返回下载链接
+	ShareDays      *int   `json:"share_days,omitempty"`      // share 默认有效期天数（0=永久，1/7/30）
+	SharePasscode  *bool  `json:"share_passcode,omitempty"`  // share 默认是否需要提取码
+	ListOutput     string `json:"list_output,omitempty"`     // list 默认输出格式（json/stream）
+	ConflictPolicy string `json:"conflict_policy,omitempty"` // upload 默认同名冲突策略（skip/overwrite/rsync）
 }
 
 // UserInfo 用户信息结构
@@ -107,21 +306,141 @@ const (
 	UploadPolicyRsync UploadPolicy = "rsync"
 )
 
+// ListOptions 列目录选项
+type ListOptions struct {
+	SortBy string // 排序字段（file_name/updated_at/size），空表示默认排序（目录在前，按修改时间倒序）
+	Order  string // 排序方式（asc/desc），空表示 asc
+	Page   int    // 手动分页页码（从 1 开始），0 表示自动翻页返回全部条目
+	Limit  int    // 手动分页每页条数，0 表示 LIST_PAGE_SIZE；只设置 Limit 时等同 Page=1
+
+	Category string // 文件类别过滤（video/audio/image/document，见 LIST_CATEGORIES），空表示不过滤
+}
+
+// FileInfoOptions 按路径查询文件信息的选项
+type FileInfoOptions struct {
+	PreferDir  bool // 同名文件和目录同时存在时优先目录（等同 GetFileInfo 的 skipPathConversion）
+	IgnoreCase bool // 精确匹配不到时逐级按大小写不敏感匹配；多个条目只有大小写不同时返回 AMBIGUOUS_PATH
+}
+
 // UploadOptions 上传选项
 type UploadOptions struct {
-	Policy UploadPolicy // 去重策略（skip/overwrite/rsync），空字符串表示不检查
+	Policy    UploadPolicy // 去重策略（skip/overwrite/rsync），空字符串表示不检查
+	NoResume  bool         // 忽略并删除已保存的断点续传状态，从第 1 片重新上传
+	RapidOnly bool         // 只尝试秒传：服务端没有相同文件时返回 RAPID_UPLOAD_MISS，不上传分片
+
+	Verify          bool // 上传完成后重新查询远端文件比对大小（能拿到时也比对 md5/sha1），不一致时返回 VERIFY_FAILED 并保留断点续传状态
+	NoPreserveMtime bool // 为 true 时网盘文件的创建/修改时间记为上传时间，否则使用本地文件的创建时间和 mtime
+
+	Parallel    int          // 同时上传的分片数（MIN_UPLOAD_PARALLEL-MAX_UPLOAD_PARALLEL），0 时读取环境变量 UPLOAD_PARALLEL_ENV，仍未设置则由服务端 part_thread 决定
+	RateLimiter *RateLimiter // 上传限速器，同一文件的并发分片共享；可在多个文件的上传间共享以限制总速率，nil 表示不限速
+
+	OnConflict UploadConflictPolicy // 远端已有同名文件时的处理方式，空字符串表示不检查（设置后忽略 Policy）
+
+	CreateParents bool // 目标父目录不存在时逐级创建，为 false 时返回 PARENT_NOT_FOUND；UploadDir 中指 dest 的上级目录，dest 本身及其下的子目录总会创建
+
+	SanitizeNames bool // 目标文件名含控制字符、非法 UTF-8 或超过 MAX_FILE_NAME_BYTES 时自动替换/截断，为 false 时返回 INVALID_FILE_NAME
+
+	ContentType string // 上传使用的 MIME 类型（upPre 与分片请求相同），为空时按扩展名判断，扩展名不认识时按内容开头 MIME_SNIFF_BYTES 字节嗅探
+
+	ModTime      time.Time // 仅 UploadReader：非零时记为网盘文件的创建/修改时间（NoPreserveMtime 时忽略），零值为上传时间
+	NoRapidCheck bool      // 仅 UploadReader：r 可 Seek 时默认先读一遍计算哈希尝试秒传，为 true 时跳过预读，数据只读一遍（服务端已有相同文件时仍会在分片上传后秒传完成）
+
+	Links UploadLinkPolicy // 仅 UploadDir 和 PlanUpload（目录）：遍历时遇到符号链接的处理方式，空字符串同 UploadLinksSkip
+
+	Filters []UploadFilterRule // 仅 UploadDir 和 PlanUpload（目录）：按顺序匹配的 include/exclude 规则，第一条命中的规则决定是否上传
+	Ignore  *IgnoreMatcher     // 仅 UploadDir 和 PlanUpload（目录）：基础排除规则（如配置 sync.ignore、--ignore-file），遍历时叠加各目录下的 IGNORE_FILE_NAME，先于 Filters 生效
+
+	Context context.Context // 取消时中断正在上传的分片，已完成的分片保存在断点续传状态中并返回 INTERRUPTED；nil 表示不可取消
+}
+
+// UploadDirOptions 目录上传选项（UploadDir 使用）
+type UploadDirOptions struct {
+	Workers    int                       // 同时上传的文件数（与单个文件内部的分片并发无关），<= 0 时为 DEFAULT_UPLOAD_WORKERS，最大 MAX_UPLOAD_WORKERS
+	Upload     UploadOptions             // 对每个文件分别应用的上传选项（如 OnConflict）
+	OnFile     func(result UploadResult) // 每个文件上传结束（成功、跳过或失败）后回调，调用已串行化，可为 nil
+	OnProgress func(UploadBatchProgress) // 所有文件的总进度回调，调用已串行化，可为 nil
 }
 
+// UploadBatchProgress 多文件上传的总进度
+type UploadBatchProgress struct {
+	FilesDone  int           // 已结束的文件数（成功、跳过或失败）
+	FilesTotal int           // 文件总数
+	Failed     int           // 失败的文件数
+	Uploaded   int64         // 已完成的字节数：成功或跳过的文件按大小计，上传中的文件按当前进度计
+	Total      int64         // 所有文件的总字节数
+	Speed      float64       // 最近 PROGRESS_SPEED_WINDOW 内的平均上传速度（字节/秒），不含跳过的文件和续传前已上传的部分
+	ETA        time.Duration // 按平均速度估算的剩余时间，-1 表示未知
+}
+
+// UploadPlanAction 上传预演（PlanUpload）中的一个计划动作
+type UploadPlanAction struct {
+	Action    string `json:"action"`               // UploadPlanCreateDir、UploadPlanUpload、UploadPlanSkip、UploadPlanOverwrite、UploadPlanFail 或 UploadPlanExclude
+	LocalPath string `json:"local_path,omitempty"` // 本地文件路径，create_dir 时为空
+	Path      string `json:"path"`                 // 远程路径（--on-conflict rename 时为改名后的路径）
+	Size      int64  `json:"size,omitempty"`       // 文件大小
+	Reason    string `json:"reason,omitempty"`     // 跳过、覆盖、改名或失败的原因
+
+	Rule string `json:"rule,omitempty"` // 递归上传时决定该文件上传或排除的过滤规则
+}
+
+// UploadResult 目录上传中单个文件的结果
+type UploadResult struct {
+	LocalPath string `json:"local_path"`        // 本地路径
+	Path      string `json:"path"`              // 远程路径
+	Size      int64  `json:"size"`              // 文件大小
+	Error     string `json:"error,omitempty"`   // 失败原因，成功时为空
+	Skipped   bool   `json:"skipped,omitempty"` // 远端已有相同文件（按去重/冲突策略跳过），未上传
+
+	Reason string `json:"reason,omitempty"` // 遍历本地目录时就跳过的原因（符号链接、目录循环），远端已存在而跳过时为空
+	Rule   string `json:"rule,omitempty"`   // 决定该条目上传或排除的过滤/忽略规则（如 "exclude *.tmp"、".kuakeignore: *.log"），没有规则命中时为空
+}
+
+// UploadFilterRule 递归上传的一条过滤规则
+type UploadFilterRule struct {
+	Exclude bool   `json:"exclude,omitempty"` // true 为排除规则，false 为包含规则
+	Pattern string `json:"pattern"`           // 相对本地目录的 glob 模式，语法同 .kuakeignore：不含 "/" 时匹配任意层级的名字，"**" 匹配多级目录，"/" 结尾只匹配目录
+}
+
+// UploadConflictPolicy 上传时远端目标路径已存在的处理策略，采取的动作写入结果 Data 的 conflict_action
+type UploadConflictPolicy string
+
+const (
+	// UploadConflictSkip 远端同名文件大小与本地一致时跳过（SKIPPED），大小不同时按 overwrite 处理
+	UploadConflictSkip UploadConflictPolicy = "skip"
+	// UploadConflictOverwrite 先删除远端同名文件再上传
+	UploadConflictOverwrite UploadConflictPolicy = "overwrite"
+	// UploadConflictRename 保留远端文件，新文件上传为 "name (1).ext"、"name (2).ext"……
+	UploadConflictRename UploadConflictPolicy = "rename"
+	// UploadConflictFail 远端已存在时不上传，返回 FILE_EXISTS
+	UploadConflictFail UploadConflictPolicy = "fail"
+)
+
+// UploadLinkPolicy 递归上传遍历本地目录时遇到符号链接的处理方式
+type UploadLinkPolicy string
+
+const (
+	// UploadLinksSkip 不上传符号链接，记入结果的 skipped 并在 Reason 中注明（默认）
+	UploadLinksSkip UploadLinkPolicy = "skip"
+	// UploadLinksFollow 跟随符号链接：指向文件时上传目标文件，指向目录时进入该目录；
+	// 按真实路径记录已进入的目录，再次指向它们的链接（目录循环）记为跳过；坏链接记为失败
+	UploadLinksFollow UploadLinkPolicy = "follow"
+	// UploadLinksError 把符号链接记为失败的文件，其它文件照常上传
+	UploadLinksError UploadLinkPolicy = "error"
+)
+
 // UploadProgress 上传进度信息
 type UploadProgress struct {
 	Progress     int           `json:"progress"`      // 进度百分比 (0-100)
 	Uploaded     int64         `json:"uploaded"`      // 已上传字节数
 	Total        int64         `json:"total"`         // 总字节数
-	Speed        float64       `json:"speed"`         // 上传速度 (字节/秒)
+	Speed        float64       `json:"speed"`         // 上传速度 (字节/秒)，最近几秒的平均速度，完成时为整体平均速度
 	SpeedStr     string        `json:"speed_str"`     // 格式化的速度字符串 (如 "25.5 MB/s")
+	InstantSpeed float64       `json:"instant_speed"` // 瞬时速度 (字节/秒)，与上一次进度回调之间
 	Remaining    time.Duration `json:"remaining"`     // 剩余时间
 	RemainingStr string        `json:"remaining_str"` // 格式化的剩余时间字符串 (如 "2m30s")
 	Elapsed      time.Duration `json:"elapsed"`       // 已用时间
+	Rapid        bool          `json:"rapid"`         // 秒传完成（服务端已有相同文件，未上传剩余分片），此时 Progress 为 100、Speed 为 0
+	Fetched      int64         `json:"fetched"`       // UploadFromURL 时已从源站拉取的字节数，其它上传为 0
 }
 
 // UploadState 上传状态（用于断点续传）
@@ -129,6 +448,7 @@ type UploadState struct {
 	FilePath      string          `json:"file_path"`          // 本地文件路径
 	DestPath      string          `json:"dest_path"`          // 目标路径
 	FileSize      int64           `json:"file_size"`          // 文件大小
+	ModTime       int64           `json:"mod_time,omitempty"` // 本地文件修改时间（UnixNano），与当前不一致时不续传
 	UploadID      string          `json:"upload_id"`          // OSS UploadID
 	TaskID        string          `json:"task_id"`            // 任务ID
 	Bucket        string          `json:"bucket"`             // OSS Bucket
@@ -144,6 +464,13 @@ type UploadState struct {
 	CreatedAt     time.Time       `json:"created_at"`         // 创建时间
 }
 
+// UploadStateEntry 本地保存的一个断点续传状态（ListUploadStates/PurgeUploadStates 返回）
+type UploadStateEntry struct {
+	ID    string       // 状态 ID（状态文件名去掉 .json），upload-abort 按它选择要清理的上传
+	Path  string       // 状态文件路径
+	State *UploadState // 状态内容
+}
+
 // PreUploadResponse 预上传响应
 type PreUploadResponse struct {
 	Code   int `json:"code"`
@@ -242,6 +569,29 @@ type ShareInfo struct {
 	Passcode string // 提取码
 }
 
+// ShareFileEntry 分享中的一个文件或目录（SelectShareFiles 的结果）
+type ShareFileEntry struct {
+	Fid     string `json:"fid"`
+	Token   string `json:"share_fid_token"` // 转存时与 fid 对应的 share_fid_token
+	PdirFid string `json:"pdir_fid"`        // 所在的分享内目录，"0" 为分享根目录
+	Path    string `json:"path"`            // 分享内的相对路径，如 "S01/S01E01.mkv"
+	Size    int64  `json:"size"`
+	IsDir   bool   `json:"dir"`
+}
+
+// ShareSelection 选择分享中的部分文件转存，Patterns 与 Fids 命中任意一个即选中
+type ShareSelection struct {
+	Patterns []string // glob 模式：不含 "/" 时匹配文件名，含 "/" 时匹配分享内的相对路径（支持 "**"）；命中的目录整个转存
+	Fids     []string // 直接指定的 fid，可以是子目录中的条目
+}
+
+// ShareSaveResult 转存任务（SaveShareFile 返回的 task_id）完成后的结果
+type ShareSaveResult struct {
+	TaskID    string   `json:"task_id"`
+	SavedFids []string `json:"saved_fids"`            // 转存到自己网盘中的顶层条目 fid（服务端 save_as.save_as_top_fids）
+	ToPdirFid string   `json:"to_pdir_fid,omitempty"` // 转存的目标目录
+}
+
 // ShareStokenResponse 分享stoken响应
 type ShareStokenResponse struct {
 	Code   int                    `json:"code"`
@@ -339,7 +689,9 @@ type Task struct {
 	StartedAt   *time.Time             `json:"started_at"`   // 开始时间
 	CompletedAt *time.Time             `json:"completed_at"` // 完成时间
 	Progress    float64                `json:"progress"`     // 进度（0-100）
+	Attempts    int                    `json:"attempts"`     // 已执行次数（含重试）
 	mu          sync.RWMutex           `json:"-"`            // 读写锁
+	retryAt     time.Time              // 重试任务最早可再次执行的时间
 }
 
 // TaskCallback 任务回调结构
@@ -349,6 +701,13 @@ type TaskCallback struct {
 	OnError    func(task *Task, err error)          // 错误回调
 }
 
+// TaskRetryPolicy 任务失败后的重试策略
+type TaskRetryPolicy struct {
+	MaxRetries int                  // 最大重试次数，0 表示不重试
+	Interval   time.Duration        // 重试前的等待时间（期间工作协程先执行其它任务）
+	Retryable  func(err error) bool // 判断错误是否值得重试，nil 表示所有错误都重试
+}
+
 // TaskQueue 任务队列
 type TaskQueue struct {
 	maxWorkers int
@@ -359,6 +718,7 @@ type TaskQueue struct {
 	mu         sync.RWMutex
 	executor   TaskExecutor
 	callbacks  map[string]TaskCallback
+	retry      TaskRetryPolicy
 	stopCh     chan struct{}
 	wg         sync.WaitGroup
 }
@@ -388,3 +748,9 @@ type OSSCommitHeaderBuilder struct {
 	Callback   string
 	Timestamp  string
 }
+
+// OSSAbortHeaderBuilder OSS 取消分片上传头部构建器
+type OSSAbortHeaderBuilder struct {
+	AuthKey   string
+	Timestamp string
+}
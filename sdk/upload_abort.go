@@ -0,0 +1,51 @@
+package sdk
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// AbortUpload 调用 OSS AbortMultipartUpload 取消 state 对应的分片上传（释放 OSS 端已上传的分片），然后删除本地状态文件
+// OSS 返回 NoSuchUpload（uploadId 已完成、过期或已取消）时视为已清理，同样删除状态文件；其它失败时保留状态文件并返回错误
+func (qc *QuarkClient) AbortUpload(state *UploadState) error {
+	if state == nil || state.UploadID == "" {
+		return errors.New("upload state has no upload id")
+	}
+	now := time.Now().UTC().Format("Mon, 02 Jan 2006 15:04:05 GMT")
+	authMeta := fmt.Sprintf("DELETE\n\n\n%s\nx-oss-date:%s\nx-oss-user-agent:aliyun-sdk-js/1.0.0 Chrome 145.0.0.0 on Windows 10 64-bit\n/%s/%s?uploadId=%s",
+		now, now, state.Bucket, state.ObjKey, state.UploadID)
+	authKey, err := qc.getOSSAuthKey(authMeta, state.AuthInfo, state.TaskID)
+	if err != nil {
+		return fmt.Errorf("get oss auth for abort: %w", err)
+	}
+
+	uploadURLBase := strings.TrimPrefix(strings.TrimPrefix(state.UploadURL, "https://"), "http://")
+	uploadURL := fmt.Sprintf("https://%s.%s/%s", state.Bucket, uploadURLBase, state.ObjKey)
+	req, err := qc.newRequestWithHeaders("DELETE", uploadURL, nil, &OSSAbortHeaderBuilder{AuthKey: authKey, Timestamp: now})
+	if err != nil {
+		return fmt.Errorf("failed to create abort request: %w", err)
+	}
+	params := req.URL.Query()
+	params.Set("uploadId", state.UploadID)
+	req.URL.RawQuery = params.Encode()
+
+	resp, err := qc.HttpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to abort upload: %w", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK && !strings.Contains(string(body), "NoSuchUpload") {
+		return fmt.Errorf("abort upload failed with status %d: %s", resp.StatusCode, string(body))
+	}
+
+	if err := deleteUploadState(qc.uploadStatePath(state.FilePath, state.DestPath)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("delete upload state: %w", err)
+	}
+	return nil
+}
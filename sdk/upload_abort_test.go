@@ -0,0 +1,81 @@
+package sdk
+
+import (
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAbortUpload(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		body      string
+		wantErr   bool
+		wantState bool // 状态文件是否保留
+	}{
+		{name: "aborted", status: http.StatusNoContent},
+		{name: "already gone", status: http.StatusNotFound, body: "<Error><Code>NoSuchUpload</Code></Error>"},
+		{name: "failed", status: http.StatusForbidden, body: "<Error><Code>AccessDenied</Code></Error>", wantErr: true, wantState: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			isolateUploadState(t)
+			var aborted []string
+			client := createMockClient(t, func(req *http.Request) (*http.Response, error) {
+				switch {
+				case strings.HasSuffix(req.URL.Path, FILE_UPLOAD_AUTH):
+					return jsonResponse(req, `{"status":200,"code":0,"data":{"auth_key":"k"}}`), nil
+				case req.URL.Host == "b.oss.example.com" && req.Method == "DELETE":
+					if req.Header.Get("Authorization") != "k" {
+						t.Errorf("Authorization = %q, want k", req.Header.Get("Authorization"))
+					}
+					aborted = append(aborted, req.URL.Path+"?"+req.URL.RawQuery)
+					return &http.Response{StatusCode: tt.status, Header: make(http.Header), Body: io.NopCloser(strings.NewReader(tt.body)), Request: req}, nil
+				}
+				t.Errorf("unexpected request %s %s", req.Method, req.URL)
+				return jsonResponse(req, `{"status":404,"code":1}`), nil
+			})
+
+			older := &UploadState{FilePath: "/data/a.bin", DestPath: "/a.bin", UploadID: "u1", TaskID: "t1", Bucket: "b", ObjKey: "obj1", UploadURL: "http://oss.example.com"}
+			newer := &UploadState{FilePath: "/data/b.bin", DestPath: "/b.bin", UploadID: "u2", TaskID: "t2", Bucket: "b", ObjKey: "obj2", UploadURL: "http://oss.example.com"}
+			olderPath := client.uploadStatePath(older.FilePath, older.DestPath)
+			if err := saveUploadState(olderPath, older); err != nil {
+				t.Fatal(err)
+			}
+			time.Sleep(10 * time.Millisecond)
+			if err := saveUploadState(client.uploadStatePath(newer.FilePath, newer.DestPath), newer); err != nil {
+				t.Fatal(err)
+			}
+
+			states, err := client.ListUploadStates()
+			if err != nil {
+				t.Fatalf("ListUploadStates() error = %v", err)
+			}
+			if len(states) != 2 || states[0].State.UploadID != "u1" || states[0].Path != olderPath {
+				t.Fatalf("ListUploadStates() = %+v, want 2 states, oldest first", states)
+			}
+
+			err = client.AbortUpload(states[0].State)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("AbortUpload() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(aborted) != 1 || aborted[0] != "/obj1?uploadId=u1" {
+				t.Errorf("abort requests = %v, want [/obj1?uploadId=u1]", aborted)
+			}
+			if _, statErr := os.Stat(olderPath); (statErr == nil) != tt.wantState {
+				t.Errorf("state file kept = %v, want %v", statErr == nil, tt.wantState)
+			}
+			wantRemaining := 1
+			if tt.wantState {
+				wantRemaining = 2
+			}
+			if remaining, _ := client.ListUploadStates(); len(remaining) != wantRemaining {
+				t.Errorf("ListUploadStates() after abort = %d states, want %d", len(remaining), wantRemaining)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package sdk
+
+import (
+	"errors"
+	"io"
+	"sync"
+)
+
+// partBuffers 分片缓冲池：分片大小 -> *sync.Pool，同一进程内的并发分片和多个文件的上传共用，避免每个分片重新分配 partSize 字节
+var partBuffers sync.Map
+
+// errPartBodyDetached upPart 返回后 Transport 仍在读取请求体（如服务端提前响应），缓冲可能已被下一个分片复用
+var errPartBodyDetached = errors.New("upload part body already released")
+
+// getPartBuffer 从分片缓冲池取一个 size 字节的缓冲，用完后调用 putPartBuffer 放回
+func getPartBuffer(size int64) []byte {
+	pool, ok := partBuffers.Load(size)
+	if !ok {
+		pool, _ = partBuffers.LoadOrStore(size, &sync.Pool{New: func() interface{} {
+			buf := make([]byte, size)
+			return &buf
+		}})
+	}
+	return *pool.(*sync.Pool).Get().(*[]byte)
+}
+
+// putPartBuffer 把 getPartBuffer 取得的缓冲放回缓冲池（可以是截短后的切片），nil 时忽略
+func putPartBuffer(buf []byte) {
+	if buf == nil {
+		return
+	}
+	buf = buf[:cap(buf)]
+	if pool, ok := partBuffers.Load(int64(len(buf))); ok {
+		pool.(*sync.Pool).Put(&buf)
+	}
+}
+
+// partBody 分片请求体：upPart 返回前调用 detach，之后读取返回 errPartBodyDetached，
+// 保证缓冲放回缓冲池后不会再被这次请求读取
+type partBody struct {
+	mu   sync.Mutex
+	data []byte
+	off  int
+}
+
+func newPartBody(data []byte) *partBody {
+	return &partBody{data: data}
+}
+
+func (b *partBody) Read(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.data == nil {
+		return 0, errPartBodyDetached
+	}
+	if b.off >= len(b.data) {
+		return 0, io.EOF
+	}
+	n := copy(p, b.data[b.off:])
+	b.off += n
+	return n, nil
+}
+
+// detach 断开与分片缓冲的关联
+func (b *partBody) detach() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.data = nil
+}
@@ -0,0 +1,58 @@
+package sdk
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPartBody_Detach(t *testing.T) {
+	buf := getPartBuffer(8)
+	if len(buf) != 8 {
+		t.Fatalf("getPartBuffer(8) len = %d", len(buf))
+	}
+	copy(buf, "abcdefgh")
+	body := newPartBody(buf[:6])
+	p := make([]byte, 4)
+	if n, err := body.Read(p); n != 4 || err != nil || string(p) != "abcd" {
+		t.Fatalf("Read() = %d, %v, %q", n, err, p[:n])
+	}
+	body.detach()
+	putPartBuffer(buf[:6])
+	if _, err := body.Read(p); !errors.Is(err, errPartBodyDetached) {
+		t.Errorf("Read() after detach error = %v, want errPartBodyDetached", err)
+	}
+
+	whole := newPartBody([]byte("xyz"))
+	if data, err := io.ReadAll(whole); err != nil || string(data) != "xyz" {
+		t.Errorf("ReadAll() = %q, %v", data, err)
+	}
+}
+
+// BenchmarkUploadFile 上传 16 个 1MB 分片，用 -benchmem 对比每次上传分配的内存（分片缓冲来自缓冲池，不随分片数增长）
+func BenchmarkUploadFile(b *testing.B) {
+	isolateUploadState(b)
+	const partSize = 1 << 20
+	localPath := filepath.Join(b.TempDir(), "big.bin")
+	if err := os.WriteFile(localPath, bytes.Repeat([]byte("0123456789abcdef"), 16*partSize/16), 0644); err != nil {
+		b.Fatal(err)
+	}
+	for _, partThread := range []int{1, 4} {
+		b.Run(fmt.Sprintf("part_thread=%d", partThread), func(b *testing.B) {
+			server := &fakeUploadServer{partSize: partSize, partThread: partThread}
+			client := server.client(b)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				resp, err := client.UploadFile(localPath, "/big.bin", nil, &UploadOptions{NoResume: true})
+				if err != nil || !resp.Success {
+					b.Fatalf("UploadFile() = %+v, %v", resp, err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package sdk
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// 上传冲突处理采取的动作，写入结果 Data 的 conflict_action
+const (
+	uploadConflictNone        = "none"        // 远端没有同名文件
+	uploadConflictSkipped     = "skipped"     // 大小一致，跳过上传
+	uploadConflictOverwritten = "overwritten" // 删除旧文件后上传
+	uploadConflictRenamed     = "renamed"     // 上传为新名字
+	uploadConflictFailed      = "failed"      // 已存在，未上传
+)
+
+// resolveUploadConflict 按 onConflict 处理远端已存在的 destPath（size 为要上传的字节数），返回实际上传的路径和 conflict_action；
+// 不需要上传（跳过、失败或删除旧文件出错）时 done 为最终结果
+func (qc *QuarkClient) resolveUploadConflict(destPath string, size int64, onConflict UploadConflictPolicy) (string, string, *StandardResponse) {
+	switch onConflict {
+	case UploadConflictSkip, UploadConflictOverwrite, UploadConflictRename, UploadConflictFail:
+	default:
+		return "", "", &StandardResponse{
+			Success: false,
+			Code:    "INVALID_ARGS",
+			Message: fmt.Sprintf("invalid upload conflict policy %q, must be skip, overwrite, rename or fail", onConflict),
+			Data:    nil,
+		}
+	}
+	existing, errResp := qc.remoteUploadTarget(destPath)
+	if errResp != nil {
+		return "", "", errResp
+	}
+	action := uploadConflictNone
+	if existing != nil {
+		isDir, _ := existing.Data["dir"].(bool)
+		if isDir && onConflict != UploadConflictRename {
+			return "", "", &StandardResponse{
+				Success: false,
+				Code:    "DEST_IS_DIRECTORY",
+				Message: fmt.Sprintf("远端已有同名目录: %s", destPath),
+				Data:    map[string]interface{}{"conflict_action": uploadConflictFailed, "path": destPath},
+			}
+		}
+		switch onConflict {
+		case UploadConflictFail:
+			return "", "", &StandardResponse{
+				Success: false,
+				Code:    "FILE_EXISTS",
+				Message: fmt.Sprintf("远端文件已存在: %s", destPath),
+				Data:    map[string]interface{}{"conflict_action": uploadConflictFailed, "path": destPath},
+			}
+		case UploadConflictSkip:
+			if remoteSize, ok := fileInfoSize(existing.Data); ok && remoteSize == size {
+				data := make(map[string]interface{}, len(existing.Data)+1)
+				for k, v := range existing.Data {
+					data[k] = v
+				}
+				data["conflict_action"] = uploadConflictSkipped
+				return "", "", &StandardResponse{
+					Success: true,
+					Code:    "SKIPPED",
+					Message: fmt.Sprintf("远端文件大小相同，跳过上传: %s (%d bytes)", destPath, remoteSize),
+					Data:    data,
+				}
+			}
+			// 大小不同：按 overwrite 处理
+			fallthrough
+		case UploadConflictOverwrite:
+			delResp, err := qc.Delete(destPath)
+			if err == nil && !delResp.Success {
+				err = fmt.Errorf("%s", delResp.Message)
+			}
+			if err != nil {
+				return "", "", &StandardResponse{
+					Success: false,
+					Code:    "DELETE_ERROR",
+					Message: fmt.Sprintf("failed to delete existing file %s: %v", destPath, err),
+					Data:    nil,
+				}
+			}
+			action = uploadConflictOverwritten
+		case UploadConflictRename:
+			renamed, errResp := qc.availableRemotePath(destPath)
+			if errResp != nil {
+				return "", "", errResp
+			}
+			destPath = renamed
+			action = uploadConflictRenamed
+		}
+	}
+	return destPath, action, nil
+}
+
+// remoteUploadTarget 查询远端路径：不存在时返回 nil, nil，查询失败时返回错误响应
+func (qc *QuarkClient) remoteUploadTarget(remotePath string) (*StandardResponse, *StandardResponse) {
+	info, err := qc.GetFileInfo(remotePath)
+	if err != nil {
+		return nil, &StandardResponse{
+			Success: false,
+			Code:    "GET_FILE_INFO_ERROR",
+			Message: fmt.Sprintf("failed to check destination %s: %v", remotePath, err),
+			Data:    nil,
+		}
+	}
+	if info.Success {
+		return info, nil
+	}
+	if info.Code == "FILE_NOT_FOUND" {
+		return nil, nil
+	}
+	return nil, &StandardResponse{
+		Success: false,
+		Code:    info.Code,
+		Message: fmt.Sprintf("failed to check destination %s: %s", remotePath, info.Message),
+		Data:    nil,
+	}
+}
+
+// availableRemotePath 返回远端不存在的路径：依次尝试 "name (1).ext"、"name (2).ext"…
+func (qc *QuarkClient) availableRemotePath(remotePath string) (string, *StandardResponse) {
+	ext := path.Ext(remotePath)
+	base := strings.TrimSuffix(remotePath, ext)
+	for i := 1; ; i++ {
+		candidate := fmt.Sprintf("%s (%d)%s", base, i, ext)
+		existing, errResp := qc.remoteUploadTarget(candidate)
+		if errResp != nil {
+			return "", errResp
+		}
+		if existing == nil {
+			return candidate, nil
+		}
+	}
+}
+
+// fileInfoSize 取 GetFileInfo 返回 Data 中的 size
+func fileInfoSize(data map[string]interface{}) (int64, bool) {
+	switch v := data["size"].(type) {
+	case int64:
+		return v, true
+	case int:
+		return int64(v), true
+	case float64:
+		return int64(v), true
+	}
+	return 0, false
+}
@@ -0,0 +1,125 @@
+package sdk
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestUploadFile_OnConflict(t *testing.T) {
+	content := []byte("0123456789abcdef")
+	localPath := filepath.Join(t.TempDir(), "big.bin")
+	if err := os.WriteFile(localPath, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		policy     UploadConflictPolicy
+		existing   map[string]map[string]interface{}
+		wantCode   string
+		wantAction string
+		wantPath   string
+		wantDelete bool
+		wantUpload bool
+	}{
+		{name: "no conflict", policy: UploadConflictFail, wantCode: "OK", wantAction: "none", wantPath: "/big.bin", wantUpload: true},
+		{
+			name:       "skip same size",
+			policy:     UploadConflictSkip,
+			existing:   map[string]map[string]interface{}{"/big.bin": {"fid": "old", "file_name": "big.bin", "size": len(content)}},
+			wantCode:   "SKIPPED",
+			wantAction: "skipped",
+		},
+		{
+			name:       "skip different size overwrites",
+			policy:     UploadConflictSkip,
+			existing:   map[string]map[string]interface{}{"/big.bin": {"fid": "old", "file_name": "big.bin", "size": 3}},
+			wantCode:   "OK",
+			wantAction: "overwritten",
+			wantPath:   "/big.bin",
+			wantDelete: true,
+			wantUpload: true,
+		},
+		{
+			name:       "overwrite",
+			policy:     UploadConflictOverwrite,
+			existing:   map[string]map[string]interface{}{"/big.bin": {"fid": "old", "file_name": "big.bin", "size": len(content)}},
+			wantCode:   "OK",
+			wantAction: "overwritten",
+			wantPath:   "/big.bin",
+			wantDelete: true,
+			wantUpload: true,
+		},
+		{
+			name:   "rename",
+			policy: UploadConflictRename,
+			existing: map[string]map[string]interface{}{
+				"/big.bin":     {"fid": "old", "file_name": "big.bin", "size": 3},
+				"/big (1).bin": {"fid": "old1", "file_name": "big (1).bin", "size": 3},
+			},
+			wantCode:   "OK",
+			wantAction: "renamed",
+			wantPath:   "/big (2).bin",
+			wantUpload: true,
+		},
+		{
+			name:       "fail",
+			policy:     UploadConflictFail,
+			existing:   map[string]map[string]interface{}{"/big.bin": {"fid": "old", "file_name": "big.bin", "size": 3}},
+			wantCode:   "FILE_EXISTS",
+			wantAction: "failed",
+		},
+		{
+			name:       "directory with the same name",
+			policy:     UploadConflictOverwrite,
+			existing:   map[string]map[string]interface{}{"/big.bin": {"fid": "dir", "file_name": "big.bin", "dir": true}},
+			wantCode:   "DEST_IS_DIRECTORY",
+			wantAction: "failed",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			isolateUploadState(t)
+			files := tt.existing
+			if files == nil {
+				files = map[string]map[string]interface{}{}
+			}
+			infoFn, _ := fakeFileInfoServer(files)
+			server := &fakeUploadServer{partSize: 1024}
+			uploadFn := server.roundTrip(t)
+			deleted := false
+			client := createMockClient(t, func(req *http.Request) (*http.Response, error) {
+				switch req.URL.Path {
+				case FILE_INFO_PATH_LIST, FILE_INFO, FILE_SORT:
+					return infoFn(req)
+				case FILE_DELETE:
+					deleted = true
+					return jsonResponse(req, `{"status":200,"code":0,"data":{}}`), nil
+				}
+				return uploadFn(req)
+			})
+
+			resp, err := client.UploadFile(localPath, "/big.bin", nil, &UploadOptions{OnConflict: tt.policy})
+			if err != nil {
+				t.Fatalf("UploadFile() error = %v", err)
+			}
+			if resp.Code != tt.wantCode {
+				t.Fatalf("UploadFile() = %+v, want code %s", resp, tt.wantCode)
+			}
+			if resp.Data["conflict_action"] != tt.wantAction {
+				t.Errorf("Data[conflict_action] = %v, want %s", resp.Data["conflict_action"], tt.wantAction)
+			}
+			if tt.wantPath != "" && resp.Data["path"] != tt.wantPath {
+				t.Errorf("Data[path] = %v, want %s", resp.Data["path"], tt.wantPath)
+			}
+			if deleted != tt.wantDelete {
+				t.Errorf("deleted = %v, want %v", deleted, tt.wantDelete)
+			}
+			if uploaded := server.preCalls > 0; uploaded != tt.wantUpload {
+				t.Errorf("uploaded = %v, want %v", uploaded, tt.wantUpload)
+			}
+		})
+	}
+}
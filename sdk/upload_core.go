@@ -0,0 +1,266 @@
+package sdk
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"path"
+	"time"
+)
+
+// uploadSource 要上传的数据：r 从当前位置起恰好 size 字节
+type uploadSource struct {
+	r         io.Reader
+	size      int64
+	createdAt int64         // 记为网盘文件创建时间的毫秒时间戳，0 表示不指定
+	updatedAt int64         // 记为网盘文件修改时间的毫秒时间戳，0 表示不指定
+	prehash   bool          // 上传分片前先读一遍 r 计算哈希尝试秒传（r 须可 Seek，opts.NoRapidCheck 关闭）
+	resume    *uploadResume // 断点续传状态，nil 时不保存状态，中断或失败后需从头上传
+}
+
+// uploadedParts 分片上传阶段的结果
+type uploadedParts struct {
+	etags   []string // 按 partNumber 排列的全部分片 ETag
+	md5Sum  string   // 读取分片时计算的 MD5（十六进制），未计算时为空
+	sha1Sum string
+	resumed int // 断点续传跳过的已上传分片数
+}
+
+// uploadData 把 src 上传到 destPath（完整文件路径），UploadFile、UploadReader 和 UploadFromURL 共用：
+// 按 opts 处理冲突或去重策略 → 确定目标目录 → 嗅探 MIME 类型 → upPre（或沿用断点续传的上传会话）→ 上传分片 → upHash → upCommit → upFinish，
+// 秒传命中时不上传分片；opts.Verify 时成功后校验远端文件。成功时 Data 中带 path，按 opts.OnConflict 处理过冲突时带 conflict_action
+func (qc *QuarkClient) uploadData(ctx context.Context, src *uploadSource, destPath string, progressCallback func(*UploadProgress), opts *UploadOptions) *StandardResponse {
+	startTime := time.Now()
+	action := ""
+	if opts.OnConflict != "" {
+		var done *StandardResponse
+		destPath, action, done = qc.resolveUploadConflict(destPath, src.size, opts.OnConflict)
+		if done != nil {
+			return done
+		}
+	} else if skipped := qc.uploadPolicySkipped(destPath, src.size, opts.Policy); skipped != nil {
+		return skipped
+	}
+	// 上传可能新建或覆盖目标文件，结束后使其路径缓存失效
+	defer qc.InvalidatePathCache(destPath)
+
+	dirFid, errResp := qc.uploadDestDirFid(path.Dir(destPath), opts.CreateParents)
+	if errResp != nil {
+		return errResp
+	}
+	fileName := path.Base(destPath)
+	var head []byte
+	var sniffErr error
+	mimeType := uploadMimeType(fileName, opts.ContentType, func() []byte {
+		head, src.r, sniffErr = peekStream(src.r, src.size)
+		return head
+	})
+	if sniffErr != nil {
+		return streamReadFailed(int64(len(head)), src.size, sniffErr)
+	}
+
+	var pre *PreUploadResponse
+	if rs := src.resume; rs != nil {
+		rs.destPath, rs.statePath = destPath, qc.uploadStatePath(rs.filePath, destPath)
+		if ctx.Err() != nil {
+			// 已被取消（如目录上传中途按了 Ctrl+C）：不再开始新的上传，之前保存的断点续传状态保持不变
+			return uploadInterrupted(rs.statePath, 0, 0)
+		}
+		if opts.NoResume {
+			// --no-resume：丢弃之前的上传会话，从第 1 片重新上传
+			rs.discard()
+		} else if !opts.RapidOnly {
+			// 只尝试秒传时不上传分片，总是新建上传会话
+			pre, mimeType = rs.load(src.size, mimeType)
+		}
+	}
+	if pre == nil {
+		var err error
+		pre, err = qc.upPre(fileName, mimeType, src.size, dirFid, src.createdAt, src.updatedAt)
+		if err != nil {
+			return &StandardResponse{
+				Success: false,
+				Code:    "PRE_UPLOAD_ERROR",
+				Message: fmt.Sprintf("pre-upload failed: %v", err),
+				Data:    nil,
+			}
+		}
+	}
+
+	result := qc.uploadSession(ctx, src, destPath, pre, mimeType, startTime, progressCallback, opts)
+	if result.Success {
+		result.Data["path"] = destPath
+		if action != "" {
+			result.Data["conflict_action"] = action
+		}
+	}
+	return result
+}
+
+// uploadSession 在上传会话 pre 建立后上传 src 的数据并提交。预读过哈希时 upHash 在分片之前调用（命中秒传则不上传分片），
+// 否则读取分片时嵌入式计算 MD5+SHA1，在分片之后、commit 之前调用 upHash
+func (qc *QuarkClient) uploadSession(ctx context.Context, src *uploadSource, destPath string, pre *PreUploadResponse, mimeType string, startTime time.Time, progressCallback func(*UploadProgress), opts *UploadOptions) *StandardResponse {
+	size, rs := src.size, src.resume
+	rapidDone := func(resp *StandardResponse, md5Sum, sha1Sum string) *StandardResponse {
+		if !resp.Success {
+			return resp
+		}
+		rs.discard()
+		if progressCallback != nil {
+			progress := rapidUploadProgress(size, startTime)
+			progress.Rapid, _ = resp.Data["rapid"].(bool)
+			progressCallback(progress)
+		}
+		return qc.verifyUploadResult(resp, destPath, size, md5Sum, sha1Sum, opts.Verify)
+	}
+	if opts.RapidOnly {
+		md5Sum, sha1Sum, read, err := hashStream(src.r, size)
+		if err != nil {
+			return streamReadFailed(read, size, err)
+		}
+		return rapidDone(qc.rapidUploadWithHash(pre, md5Sum, sha1Sum), md5Sum, sha1Sum)
+	}
+	// 0 字节文件没有分片可读，不能走分片上传（etags 为空时 commit 行为未定义），单独处理
+	if size == 0 {
+		return rapidDone(qc.uploadEmptyFile(pre, mimeType), emptyFileMD5, emptyFileSHA1)
+	}
+
+	var md5Sum, sha1Sum string
+	hashed := false // upHash 已成功调用
+	if src.prehash && !opts.NoRapidCheck {
+		var read int64
+		var err error
+		md5Sum, sha1Sum, read, err = hashStream(src.r, size)
+		if err != nil {
+			return streamReadFailed(read, size, err)
+		}
+		hashResp, hashErr := qc.upHash(md5Sum, sha1Sum, pre.Data.TaskID)
+		if hashErr == nil && hashResp.Data.Finish {
+			return rapidDone(qc.finishRapidUpload(pre), md5Sum, sha1Sum)
+		}
+		hashed = hashErr == nil
+	}
+
+	var parts *uploadedParts
+	var failed *StandardResponse
+	if rs != nil {
+		parts, failed = qc.uploadResumableParts(ctx, rs, pre, mimeType, size, startTime, progressCallback, opts)
+	} else {
+		parts, failed = qc.uploadStreamedParts(ctx, src.r, size, pre, mimeType, md5Sum == "", startTime, progressCallback, opts)
+	}
+	if failed != nil {
+		return failed
+	}
+	if md5Sum == "" {
+		md5Sum, sha1Sum = parts.md5Sum, parts.sha1Sum
+	}
+
+	// 【核心】upHash 必须在 commit 之前调用（服务端协议要求），否则服务端会清理未确认的 OSS 上传会话，
+	// 导致 upCommit 时返回 404 NoSuchUpload。upHash 失败时降级处理，继续尝试 commit
+	if !hashed {
+		if hashResp, hashErr := qc.upHash(md5Sum, sha1Sum, pre.Data.TaskID); hashErr == nil && hashResp.Data.Finish {
+			// 秒传：服务端已有相同文件，直接走 upFinish 跳过 commit
+			return rapidDone(qc.finishRapidUpload(pre), md5Sum, sha1Sum)
+		}
+	}
+
+	finish, err := qc.upCommit(pre, parts.etags)
+	if err == nil && (finish.Code != 0 || finish.Status != 200) {
+		err = fmt.Errorf("code=%d, status=%d", finish.Code, finish.Status)
+	}
+	if err != nil {
+		// NoSuchUpload 说明 OSS 端的 uploadId 已失效，删除断点续传状态，避免重试时反复使用同一个过期 uploadId
+		if rs != nil {
+			discardExpiredUploadState(rs.statePath, err)
+		}
+		return &StandardResponse{
+			Success: false,
+			Code:    "COMMIT_UPLOAD_ERROR",
+			Message: fmt.Sprintf("commit upload failed: %v", err),
+			Data:    nil,
+		}
+	}
+	// OSS commit 成功后，需要调用 upFinish 通知夸克服务器
+	finishResp, err := qc.upFinish(pre)
+	if err != nil {
+		return &StandardResponse{
+			Success: false,
+			Code:    "FINISH_UPLOAD_ERROR",
+			Message: fmt.Sprintf("finish upload failed: %v", err),
+			Data:    nil,
+		}
+	}
+
+	responseData := make(map[string]interface{})
+	for k, v := range finishResp.Data {
+		if k != "preview_url" {
+			responseData[k] = v
+		}
+	}
+	responseData["rapid"] = false
+	message := "上传完成"
+	if parts.resumed > 0 {
+		responseData["resumed_parts"] = parts.resumed
+		message = fmt.Sprintf("上传完成（断点续传，跳过 %d 个已上传的分片）", parts.resumed)
+	}
+	resp := qc.verifyUploadResult(&StandardResponse{
+		Success: true,
+		Code:    "OK",
+		Message: message,
+		Data:    responseData,
+	}, destPath, size, md5Sum, sha1Sum, opts.Verify)
+	// --verify：远端文件缺失或与本地不一致时不删除状态文件，便于重新上传
+	if resp.Success {
+		rs.discard()
+	}
+	return resp
+}
+
+// uploadStreamedParts 按分片边读边上传 r 中的 size 字节，不保存断点续传状态；embedHash 时同时计算 MD5+SHA1
+func (qc *QuarkClient) uploadStreamedParts(ctx context.Context, r io.Reader, size int64, pre *PreUploadResponse, mimeType string, embedHash bool, startTime time.Time, progressCallback func(*UploadProgress), opts *UploadOptions) (*uploadedParts, *StandardResponse) {
+	partSize := uploadPartSizeFor(pre.Metadata.PartSize, size)
+	totalParts := int((size + partSize - 1) / partSize)
+	// 数据只能读一遍，并发数为 1 时也走 uploadPartsParallel：生产者按序读满分片，worker 按序上传
+	parallel := uploadParallelFor(opts.Parallel, pre.Metadata.PartThread, totalParts)
+	stream := &streamReader{r: r, size: size}
+	var md5Hash, sha1Hash hash.Hash
+	if embedHash {
+		md5Hash, sha1Hash = md5.New(), sha1.New()
+	}
+	state := &UploadState{UploadedParts: make(map[int]string)}
+	partMap, err := qc.uploadPartsParallel(ctx, stream, pre, mimeType, partSize, size, "", state, startTime,
+		progressCallback, parallel, nil, md5Hash, sha1Hash, opts.RateLimiter)
+	switch {
+	case ctx.Err() != nil:
+		return nil, streamUploadInterrupted(stream.read)
+	case stream.err != nil:
+		return nil, streamReadFailed(stream.read, size, stream.err)
+	case errors.Is(err, ErrPartNotSequential):
+		return nil, &StandardResponse{
+			Success: false,
+			Code:    "UPLOAD_PART_ERROR",
+			Message: fmt.Sprintf("%v; streamed data cannot be re-read, retry with upload parallelism 1", err),
+		}
+	case err != nil:
+		return nil, &StandardResponse{
+			Success: false,
+			Code:    "UPLOAD_PART_ERROR",
+			Message: err.Error(),
+		}
+	}
+
+	parts := &uploadedParts{etags: make([]string, totalParts)}
+	for i := range parts.etags {
+		parts.etags[i] = partMap[i+1]
+	}
+	if embedHash {
+		parts.md5Sum = hex.EncodeToString(md5Hash.Sum(nil))
+		parts.sha1Sum = hex.EncodeToString(sha1Hash.Sum(nil))
+	}
+	return parts, nil
+}
@@ -0,0 +1,475 @@
+package sdk
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// UploadDir 递归上传本地目录：localDir 的内容保存到远程目录 destDir 下，按本地结构创建远程子目录（包括空目录）后逐个上传文件
+// destDir 不存在时会创建，但它的上级目录不存在时返回 PARENT_NOT_FOUND，除非 opts.Upload.CreateParents 为 true
+// 远程目录在上传前按先序串行创建，避免并发上传在同一父目录下重复建目录；文件由 TaskQueue 按 opts.Workers 并发上传，
+// 单个文件失败不影响其它文件，创建失败的目录下的文件记为失败；只上传普通文件，符号链接按 opts.Upload.Links 处理（默认跳过并记入 skipped，Reason 注明）
+// 本地目录（及子目录）下的 IGNORE_FILE_NAME 按 gitignore 语法排除文件，叠加在 opts.Upload.Ignore 之上；
+// 之后 opts.Upload.Filters 按顺序匹配 include/exclude 规则，被排除的目录整个子树都不遍历
+// 返回 Data 包含 local_dir、remote_dir、files、dirs、uploaded、skipped、failed、excluded（[]UploadResult，Rule 为命中的规则）；有失败时 Code 为 PARTIAL_SUCCESS
+func (qc *QuarkClient) UploadDir(localDir, destDir string, opts UploadDirOptions) (*StandardResponse, error) {
+	info, err := os.Stat(localDir)
+	if err != nil {
+		return &StandardResponse{
+			Success: false,
+			Code:    "FILE_INFO_ERROR",
+			Message: fmt.Sprintf("failed to get local dir info: %v", err),
+		}, nil
+	}
+	if !info.IsDir() {
+		return &StandardResponse{
+			Success: false,
+			Code:    "NOT_A_DIRECTORY",
+			Message: fmt.Sprintf("not a directory: %s", localDir),
+		}, nil
+	}
+	remoteRoot := normalizePath(destDir)
+	if remoteRoot == "" || remoteRoot == "." {
+		remoteRoot = "/"
+	}
+
+	filter, err := newUploadFilter(opts.Upload.Filters)
+	if err != nil {
+		return invalidFilterResponse(err), nil
+	}
+	jobs, dirs, excluded, walkErr := planDirUpload(localDir, remoteRoot, opts.Upload.Links, opts.Upload.Ignore, filter)
+	if walkErr != nil {
+		return &StandardResponse{
+			Success: false,
+			Code:    "READ_DIR_ERROR",
+			Message: fmt.Sprintf("failed to read local dir: %v", walkErr),
+		}, nil
+	}
+
+	// dest 本身可以新建，它的上级目录不存在时除非 CreateParents 否则整体失败
+	if _, errResp := qc.uploadDestDirFid(path.Dir(remoteRoot), opts.Upload.CreateParents); errResp != nil {
+		return errResp, nil
+	}
+
+	// 先串行创建远程目录（父目录在前），失败的目录记下原因，其下的子目录和文件不再处理
+	failedDirs := make(map[string]string)
+	for _, dir := range dirs {
+		if reason, ok := failedDirs[path.Dir(dir)]; ok {
+			failedDirs[dir] = reason
+			continue
+		}
+		if _, errResp := qc.uploadDestDirFid(dir, true); errResp != nil {
+			failedDirs[dir] = fmt.Sprintf("create remote dir %s: %s", dir, errResp.Message)
+		}
+	}
+
+	var pending []UploadResult
+	failed := make([]UploadResult, 0)
+	for _, job := range jobs {
+		if reason, ok := failedDirs[path.Dir(job.Path)]; ok {
+			job.Error = reason
+			failed = append(failed, job)
+			if opts.OnFile != nil {
+				opts.OnFile(job)
+			}
+			continue
+		}
+		pending = append(pending, job)
+	}
+
+	uploaded := make([]UploadResult, 0)
+	skipped := make([]UploadResult, 0)
+	for _, result := range qc.uploadFiles(pending, len(failed), opts) {
+		switch {
+		case result.Error != "":
+			failed = append(failed, result)
+		case result.Skipped:
+			skipped = append(skipped, result)
+		default:
+			uploaded = append(uploaded, result)
+		}
+	}
+
+	code, message := "OK", fmt.Sprintf("上传目录成功，共 %d 个文件", len(uploaded))
+	if len(failed) > 0 {
+		code = "PARTIAL_SUCCESS"
+		message = fmt.Sprintf("上传目录完成，%d 个文件成功，%d 个失败", len(uploaded), len(failed))
+	}
+	linkSkipped := 0
+	for _, result := range skipped {
+		if result.Reason != "" {
+			linkSkipped++
+		}
+	}
+	if n := len(skipped) - linkSkipped; n > 0 {
+		message += fmt.Sprintf("，跳过 %d 个远端已存在的文件", n)
+	}
+	if linkSkipped > 0 {
+		message += fmt.Sprintf("，跳过 %d 个符号链接", linkSkipped)
+	}
+	if len(excluded) > 0 {
+		message += fmt.Sprintf("，按过滤规则排除 %d 项", len(excluded))
+	}
+	if excluded == nil {
+		excluded = make([]UploadResult, 0)
+	}
+	return &StandardResponse{
+		Success: true,
+		Code:    code,
+		Message: message,
+		Data: map[string]interface{}{
+			"local_dir":  localDir,
+			"remote_dir": remoteRoot,
+			"files":      len(jobs),
+			"dirs":       len(dirs),
+			"uploaded":   uploaded,
+			"skipped":    skipped,
+			"failed":     failed,
+			"excluded":   excluded,
+		},
+	}, nil
+}
+
+// planDirUpload 遍历本地目录，返回待上传的文件、需要创建的远程子目录（先序，父目录在前）和被 filter 排除的文件与目录（Rule 为命中的规则）
+// 符号链接按 links 处理；无法读取的子目录、坏链接等记为带 Error 的条目，不中断遍历
+// 每个目录下的 IGNORE_FILE_NAME 叠加在 ignore 之上，对该目录及其子目录生效，被忽略的条目同样记入排除列表
+func planDirUpload(localDir, remoteRoot string, links UploadLinkPolicy, ignore *IgnoreMatcher, filter *uploadFilter) ([]UploadResult, []string, []UploadResult, error) {
+	w := &dirUploadWalker{localDir: localDir, remoteRoot: remoteRoot, links: links, filter: filter, dirs: []string{remoteRoot}}
+	if links == UploadLinksFollow {
+		w.visited = make(map[string]bool)
+	}
+	if err := w.walk(localDir, ignore); err != nil {
+		return nil, nil, nil, err
+	}
+	sort.SliceStable(w.jobs, func(i, j int) bool { return w.jobs[i].Path < w.jobs[j].Path })
+	sort.SliceStable(w.excluded, func(i, j int) bool { return w.excluded[i].Path < w.excluded[j].Path })
+	return w.jobs, w.dirs, w.excluded, nil
+}
+
+// dirUploadWalker 递归遍历本地目录，收集 planDirUpload 的结果
+type dirUploadWalker struct {
+	localDir   string
+	remoteRoot string
+	links      UploadLinkPolicy
+	filter     *uploadFilter
+	visited    map[string]bool // 已进入目录的真实路径，仅 UploadLinksFollow 时使用
+	jobs       []UploadResult
+	dirs       []string
+	excluded   []UploadResult
+}
+
+// walk 遍历本地目录 dir 的条目（dir 对应的远程目录已在 dirs 中），只有 localDir 本身读取失败时返回错误
+// ignore 为上级目录生效的排除规则，dir 下的 IGNORE_FILE_NAME 无法读取或有语法错误时记为失败的条目，其规则不生效
+func (w *dirUploadWalker) walk(dir string, ignore *IgnoreMatcher) error {
+	if w.visited != nil {
+		if real, err := filepath.EvalSymlinks(dir); err == nil {
+			w.visited[real] = true
+		}
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if dir == w.localDir {
+			return err
+		}
+		w.jobs = append(w.jobs, UploadResult{LocalPath: dir, Path: w.remotePath(dir), Error: err.Error()})
+		return nil
+	}
+	relDir, _ := filepath.Rel(w.localDir, dir)
+	badIgnoreFile := false
+	if m, err := ignore.WithIgnoreFile(dir, relDir); err != nil {
+		p := filepath.Join(dir, IGNORE_FILE_NAME)
+		w.jobs = append(w.jobs, UploadResult{LocalPath: p, Path: w.remotePath(p), Error: err.Error()})
+		badIgnoreFile = true
+	} else {
+		ignore = m
+	}
+	for _, entry := range entries {
+		if badIgnoreFile && entry.Name() == IGNORE_FILE_NAME {
+			continue // 已记为失败
+		}
+		p := filepath.Join(dir, entry.Name())
+		isLink := entry.Type()&fs.ModeSymlink != 0
+		isDir := entry.IsDir()
+		if isLink && w.links == UploadLinksFollow {
+			if info, err := os.Stat(p); err == nil {
+				isDir = info.IsDir()
+			}
+		}
+		rel, _ := filepath.Rel(w.localDir, p)
+		if ignored, rule := ignore.MatchRule(rel, isDir); ignored {
+			w.excluded = append(w.excluded, UploadResult{LocalPath: p, Path: w.remotePath(p), Rule: rule})
+			continue
+		}
+		included, rule := w.filter.match(rel, isDir)
+		if !included {
+			w.excluded = append(w.excluded, UploadResult{LocalPath: p, Path: w.remotePath(p), Rule: rule})
+			continue
+		}
+		switch {
+		case isLink:
+			w.link(p, rule, ignore)
+		case isDir:
+			w.dirs = append(w.dirs, w.remotePath(p))
+			w.walk(p, ignore)
+		default:
+			w.file(p, rule)
+		}
+	}
+	return nil
+}
+
+// file 记下普通文件 p（或 follow 时链接指向的文件），其它类型（设备、管道等）忽略；rule 为包含它的过滤规则
+func (w *dirUploadWalker) file(p, rule string) {
+	info, err := os.Stat(p)
+	if err != nil {
+		w.jobs = append(w.jobs, UploadResult{LocalPath: p, Path: w.remotePath(p), Error: err.Error(), Rule: rule})
+		return
+	}
+	if info.Mode().IsRegular() {
+		w.jobs = append(w.jobs, UploadResult{LocalPath: p, Path: w.remotePath(p), Size: info.Size(), Rule: rule})
+	}
+}
+
+// link 按 links 处理符号链接 p
+func (w *dirUploadWalker) link(p, rule string, ignore *IgnoreMatcher) {
+	remotePath := w.remotePath(p)
+	target, _ := os.Readlink(p)
+	switch w.links {
+	case UploadLinksFollow:
+	case UploadLinksError:
+		w.jobs = append(w.jobs, UploadResult{LocalPath: p, Path: remotePath, Error: fmt.Sprintf("symbolic link to %s is not uploaded (links policy is error)", target), Rule: rule})
+		return
+	default:
+		w.jobs = append(w.jobs, UploadResult{LocalPath: p, Path: remotePath, Skipped: true, Reason: fmt.Sprintf("符号链接（指向 %s）", target), Rule: rule})
+		return
+	}
+
+	info, err := os.Stat(p)
+	if err != nil {
+		w.jobs = append(w.jobs, UploadResult{LocalPath: p, Path: remotePath, Error: fmt.Sprintf("broken symbolic link to %s: %v", target, err), Rule: rule})
+		return
+	}
+	if !info.IsDir() {
+		w.file(p, rule)
+		return
+	}
+	real, err := filepath.EvalSymlinks(p)
+	if err != nil {
+		w.jobs = append(w.jobs, UploadResult{LocalPath: p, Path: remotePath, Error: err.Error()})
+		return
+	}
+	if w.visited[real] {
+		w.jobs = append(w.jobs, UploadResult{LocalPath: p, Path: remotePath, Skipped: true, Reason: fmt.Sprintf("目录循环（%s 已遍历过）", real)})
+		return
+	}
+	w.dirs = append(w.dirs, remotePath)
+	w.walk(p, ignore)
+}
+
+func (w *dirUploadWalker) remotePath(localPath string) string {
+	return uploadRemotePath(w.remoteRoot, w.localDir, localPath)
+}
+
+// uploadRemotePath 将 localDir 下的本地路径映射为 remoteRoot 下的远程路径
+func uploadRemotePath(remoteRoot, localDir, localPath string) string {
+	rel, err := filepath.Rel(localDir, localPath)
+	if err != nil {
+		rel = filepath.Base(localPath)
+	}
+	return normalizePath(strings.TrimSuffix(remoteRoot, "/") + "/" + normalizeNFC(filepath.ToSlash(rel)))
+}
+
+// uploadFiles 把每个文件作为 TaskTypeUpload 任务放入同一个 TaskQueue，由 UploadExecutor 按 opts.Workers 并发上传
+// 已带有 Error 或 Skipped 的条目（遍历时就失败或跳过）不上传，直接计入结果；preFailed 为调用方已按失败回调过的文件数，计入总进度
+// 每个文件结束后回调 opts.OnFile，opts.OnProgress 不为 nil 时回调总进度；返回的结果按完成顺序排列
+func (qc *QuarkClient) uploadFiles(jobs []UploadResult, preFailed int, opts UploadDirOptions) []UploadResult {
+	results := make([]UploadResult, 0, len(jobs))
+	tracker := newUploadBatchTracker(jobs, preFailed, opts.OnProgress)
+	var mu sync.Mutex
+	record := func(taskID string, result UploadResult) {
+		mu.Lock()
+		defer mu.Unlock()
+		results = append(results, result)
+		tracker.fileDone(taskID, result)
+		if opts.OnFile != nil {
+			opts.OnFile(result)
+		}
+	}
+
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = DEFAULT_UPLOAD_WORKERS
+	}
+	if workers > MAX_UPLOAD_WORKERS {
+		workers = MAX_UPLOAD_WORKERS
+	}
+	queue := NewTaskQueue(workers)
+	var wg sync.WaitGroup
+	for _, job := range jobs {
+		job := job
+		if job.Error != "" || job.Skipped {
+			record("", job)
+			continue
+		}
+		task := queue.AddTask(TaskTypeUpload, map[string]interface{}{"file_path": job.LocalPath, "dest_path": job.Path, "size": job.Size})
+		wg.Add(1)
+		queue.SetTaskCallback(task.ID, TaskCallback{
+			OnComplete: func(task *Task, result interface{}) {
+				defer wg.Done()
+				record(task.ID, result.(UploadResult))
+			},
+			OnError: func(task *Task, err error) {
+				defer wg.Done()
+				job.Error = err.Error()
+				record(task.ID, job)
+			},
+		})
+	}
+	tracker.report()
+	executor := NewUploadExecutor(qc, opts.Upload)
+	executor.tracker = tracker
+	queue.Start(executor)
+	wg.Wait()
+	queue.Stop()
+	return results
+}
+
+// UploadExecutor 上传任务执行器，处理 TaskTypeUpload 任务：Params 中 file_path 为本地文件路径，dest_path 为远程路径，
+// size（int64，可选）为文件大小；成功或跳过时返回 UploadResult，失败时返回的错误即失败原因（记录在 Task.Error）
+// 文件级并发由 TaskQueue 的 maxWorkers 控制，与单个文件内部的分片并发（transfer.upload_parallel）相互独立
+type UploadExecutor struct {
+	qc      *QuarkClient
+	opts    UploadOptions
+	tracker *uploadBatchTracker
+}
+
+// NewUploadExecutor 创建上传任务执行器，opts 应用于每个文件
+func NewUploadExecutor(qc *QuarkClient, opts UploadOptions) *UploadExecutor {
+	return &UploadExecutor{qc: qc, opts: opts}
+}
+
+// Execute 实现 TaskExecutor 接口
+func (e *UploadExecutor) Execute(task *Task) (interface{}, error) {
+	if task.Type != TaskTypeUpload {
+		return nil, fmt.Errorf("unsupported task type: %s", task.Type)
+	}
+	filePath, _ := task.Params["file_path"].(string)
+	destPath, _ := task.Params["dest_path"].(string)
+	if filePath == "" || destPath == "" {
+		return nil, errors.New("upload task requires file_path and dest_path")
+	}
+	job := UploadResult{LocalPath: filePath, Path: destPath}
+	job.Size, _ = task.Params["size"].(int64)
+
+	fileOpts := e.opts
+	resp, err := e.qc.UploadFile(filePath, destPath, e.tracker.fileProgress(task.ID), &fileOpts)
+	if err != nil {
+		return nil, err
+	}
+	if !resp.Success {
+		return nil, errors.New(resp.Message)
+	}
+	if p, ok := resp.Data["path"].(string); ok && p != "" {
+		job.Path = p // --on-conflict rename 时为实际上传的路径
+	}
+	job.Skipped = resp.Code == "SKIPPED"
+	return job, nil
+}
+
+// uploadBatchTracker 汇总多文件上传的总进度，可并发调用，方法均可在 nil 上调用
+type uploadBatchTracker struct {
+	mu          sync.Mutex
+	callback    func(UploadBatchProgress)
+	progress    UploadBatchProgress
+	finished    int64            // 已结束（成功或跳过）文件的字节数
+	current     map[string]int64 // 任务ID -> 上传中文件的已上传字节数
+	transferred int64            // 本次实际传输的字节数，用于计算速度
+	meter       *speedMeter
+}
+
+// newUploadBatchTracker 创建 jobs 的总进度汇总，preFailed 个文件已失败；callback 为 nil 时返回 nil
+func newUploadBatchTracker(jobs []UploadResult, preFailed int, callback func(UploadBatchProgress)) *uploadBatchTracker {
+	if callback == nil {
+		return nil
+	}
+	t := &uploadBatchTracker{
+		callback: callback,
+		current:  make(map[string]int64),
+		meter:    newSpeedMeter(0),
+	}
+	t.progress.FilesTotal = len(jobs) + preFailed
+	t.progress.FilesDone = preFailed
+	t.progress.Failed = preFailed
+	for _, job := range jobs {
+		t.progress.Total += job.Size
+	}
+	return t
+}
+
+// fileProgress 返回任务 taskID 的单文件进度回调，t 为 nil 时返回 nil
+func (t *uploadBatchTracker) fileProgress(taskID string) func(*UploadProgress) {
+	if t == nil {
+		return nil
+	}
+	return func(p *UploadProgress) {
+		if p == nil {
+			return
+		}
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		// 第一次回调为起点（续传时为已上传的字节数），之后的增量才是本次传输的字节
+		if prev, ok := t.current[taskID]; ok && p.Uploaded > prev {
+			t.transferred += p.Uploaded - prev
+		}
+		t.current[taskID] = p.Uploaded
+		t.reportLocked()
+	}
+}
+
+// fileDone 记录任务 taskID 的文件结束：成功或跳过的文件按大小计入已完成字节数，失败的文件不再计入
+func (t *uploadBatchTracker) fileDone(taskID string, result UploadResult) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.current, taskID)
+	t.progress.FilesDone++
+	if result.Error != "" {
+		t.progress.Failed++
+	} else {
+		t.finished += result.Size
+	}
+	t.reportLocked()
+}
+
+// report 回调当前总进度
+func (t *uploadBatchTracker) report() {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.reportLocked()
+}
+
+func (t *uploadBatchTracker) reportLocked() {
+	uploaded := t.finished
+	for _, n := range t.current {
+		uploaded += n
+	}
+	if uploaded > t.progress.Total {
+		uploaded = t.progress.Total
+	}
+	_, t.progress.Speed = t.meter.update(t.transferred)
+	t.progress.Uploaded = uploaded
+	t.progress.ETA = estimateRemaining(uploaded, t.progress.Total, t.progress.Speed)
+	t.callback(t.progress)
+}
@@ -0,0 +1,215 @@
+package sdk
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+)
+
+func TestUploadDir(t *testing.T) {
+	isolateUploadState(t)
+	localDir := t.TempDir()
+	for _, name := range []string{"a.txt", "sub/b.txt", "sub/deep/c.txt", "bad/d.txt", "bad/inner/e.txt"} {
+		p := filepath.Join(localDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(localDir, "empty"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	files := map[string]map[string]interface{}{
+		"/backup": {"fid": "fid_backup", "file_name": "backup", "dir": true},
+	}
+	infoFn, _ := fakeFileInfoServer(files)
+	server := &fakeUploadServer{partSize: 1024}
+	uploadFn := server.roundTrip(t)
+	var mu sync.Mutex
+	var created []string
+	client := createMockClient(t, func(req *http.Request) (*http.Response, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch req.URL.Path {
+		case FILE_INFO_PATH_LIST, FILE_INFO, FILE_SORT:
+			return infoFn(req)
+		case CREATE_FOLDER:
+			var body struct {
+				PdirFid  string `json:"pdir_fid"`
+				FileName string `json:"file_name"`
+			}
+			json.NewDecoder(req.Body).Decode(&body)
+			p := ""
+			for dirPath, info := range files {
+				if info["fid"] == body.PdirFid {
+					p = dirPath
+				}
+			}
+			p += "/" + body.FileName
+			if body.FileName == "bad" {
+				return jsonResponse(req, `{"status":400,"code":23008,"message":"file name not allowed"}`), nil
+			}
+			created = append(created, p)
+			fid := fmt.Sprintf("fid_%d", len(created))
+			files[p] = map[string]interface{}{"fid": fid, "file_name": body.FileName, "dir": true}
+			return jsonResponse(req, fmt.Sprintf(`{"status":200,"code":0,"data":{"fid":"%s"}}`, fid)), nil
+		}
+		return uploadFn(req)
+	})
+
+	var onFile []string
+	var last UploadBatchProgress
+	resp, err := client.UploadDir(localDir, "/backup/photos", UploadDirOptions{
+		Workers:    3,
+		OnFile:     func(result UploadResult) { onFile = append(onFile, result.Path) },
+		OnProgress: func(p UploadBatchProgress) { last = p },
+	})
+	if err != nil || !resp.Success {
+		t.Fatalf("UploadDir() = %+v, %v", resp, err)
+	}
+	if resp.Code != "PARTIAL_SUCCESS" {
+		t.Errorf("UploadDir() code = %s, want PARTIAL_SUCCESS", resp.Code)
+	}
+
+	sort.Strings(created)
+	if want := fmt.Sprint([]string{"/backup/photos", "/backup/photos/empty", "/backup/photos/sub", "/backup/photos/sub/deep"}); fmt.Sprint(created) != want {
+		t.Errorf("created folders %v, want %s", created, want)
+	}
+	uploaded, _ := resp.Data["uploaded"].([]UploadResult)
+	failed, _ := resp.Data["failed"].([]UploadResult)
+	var uploadedPaths, failedPaths []string
+	for _, r := range uploaded {
+		uploadedPaths = append(uploadedPaths, r.Path)
+	}
+	for _, r := range failed {
+		failedPaths = append(failedPaths, r.Path)
+	}
+	sort.Strings(uploadedPaths)
+	sort.Strings(failedPaths)
+	if want := "[/backup/photos/a.txt /backup/photos/sub/b.txt /backup/photos/sub/deep/c.txt]"; fmt.Sprint(uploadedPaths) != want {
+		t.Errorf("uploaded %v, want %s", uploadedPaths, want)
+	}
+	if want := "[/backup/photos/bad/d.txt /backup/photos/bad/inner/e.txt]"; fmt.Sprint(failedPaths) != want {
+		t.Errorf("failed %v, want %s", failedPaths, want)
+	}
+	if server.preCalls != 3 {
+		t.Errorf("pre-upload called %d times, want 3", server.preCalls)
+	}
+	if len(onFile) != 5 {
+		t.Errorf("OnFile called %d times, want 5", len(onFile))
+	}
+	wantBytes := int64(len("a.txt") + len("sub/b.txt") + len("sub/deep/c.txt"))
+	if last.FilesDone != 5 || last.FilesTotal != 5 || last.Failed != 2 || last.Uploaded != wantBytes || last.Total != wantBytes {
+		t.Errorf("last progress = %+v, want 5/5 files, 2 failed, %d/%d bytes", last, wantBytes, wantBytes)
+	}
+	// dest 本身可以新建，但上级目录不存在时默认整体失败
+	resp, err = client.UploadDir(localDir, "/missing/photos", UploadDirOptions{})
+	if err != nil || resp.Code != "PARENT_NOT_FOUND" || resp.Data["path"] != "/missing" {
+		t.Errorf("UploadDir() into a missing parent = %+v, %v, want PARENT_NOT_FOUND", resp, err)
+	}
+}
+
+func TestUploadExecutor(t *testing.T) {
+	isolateUploadState(t)
+	localPath := filepath.Join(t.TempDir(), "a.txt")
+	if err := os.WriteFile(localPath, []byte("hello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	server := &fakeUploadServer{partSize: 1024}
+	client := server.client(t)
+
+	queue := NewTaskQueue(2)
+	ok := queue.AddTask(TaskTypeUpload, map[string]interface{}{"file_path": localPath, "dest_path": "/a.txt", "size": int64(5)})
+	missing := queue.AddTask(TaskTypeUpload, map[string]interface{}{"file_path": filepath.Join(t.TempDir(), "missing.txt"), "dest_path": "/missing.txt"})
+	wrongType := queue.AddTask(TaskTypeDelete, map[string]interface{}{"file_path": localPath, "dest_path": "/a.txt"})
+	queue.Start(NewUploadExecutor(client, UploadOptions{}))
+	queue.Wait()
+	queue.Stop()
+
+	if ok.Status != TaskStatusCompleted {
+		t.Fatalf("upload task status = %s, error %v", ok.Status, ok.Error)
+	}
+	if result, _ := ok.Result.(UploadResult); result.Path != "/a.txt" || result.Size != 5 || result.Error != "" {
+		t.Errorf("upload task result = %+v", ok.Result)
+	}
+	for _, task := range []*Task{missing, wrongType} {
+		if task.Status != TaskStatusFailed || task.Error == nil {
+			t.Errorf("task %s status = %s, error %v, want failed with error", task.Type, task.Status, task.Error)
+		}
+	}
+}
+
+func TestPlanDirUpload_Links(t *testing.T) {
+	localDir, outside := t.TempDir(), t.TempDir()
+	for _, name := range []string{"a.txt", "sub/b.txt"} {
+		p := filepath.Join(localDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(outside, "c.txt"), []byte("c"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	links := map[string]string{
+		"file.lnk": "a.txt",
+		"ext":      outside,
+		"sub/loop": "..", // 指回 localDir，形成循环
+		"broken":   "missing.txt",
+	}
+	for name, target := range links {
+		if err := os.Symlink(target, filepath.Join(localDir, filepath.FromSlash(name))); err != nil {
+			t.Skipf("symbolic links not supported: %v", err)
+		}
+	}
+
+	// 每个条目的状态：ok 待上传，skip 跳过，fail 失败
+	tests := []struct {
+		links    UploadLinkPolicy
+		want     string
+		wantDirs string
+	}{
+		{links: "", want: "[/r/a.txt:ok /r/broken:skip /r/ext:skip /r/file.lnk:skip /r/sub/b.txt:ok /r/sub/loop:skip]", wantDirs: "[/r /r/sub]"},
+		{links: UploadLinksError, want: "[/r/a.txt:ok /r/broken:fail /r/ext:fail /r/file.lnk:fail /r/sub/b.txt:ok /r/sub/loop:fail]", wantDirs: "[/r /r/sub]"},
+		{links: UploadLinksFollow, want: "[/r/a.txt:ok /r/broken:fail /r/ext/c.txt:ok /r/file.lnk:ok /r/sub/b.txt:ok /r/sub/loop:skip]", wantDirs: "[/r /r/ext /r/sub]"},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.links), func(t *testing.T) {
+			jobs, dirs, _, err := planDirUpload(localDir, "/r", tt.links, nil, nil)
+			if err != nil {
+				t.Fatalf("planDirUpload() error = %v", err)
+			}
+			var got []string
+			for _, job := range jobs {
+				status := "ok"
+				switch {
+				case job.Error != "":
+					status = "fail"
+				case job.Skipped:
+					status = "skip"
+					if job.Reason == "" {
+						t.Errorf("skipped %s without a reason", job.Path)
+					}
+				}
+				got = append(got, job.Path+":"+status)
+			}
+			if fmt.Sprint(got) != tt.want {
+				t.Errorf("jobs = %v, want %s", got, tt.want)
+			}
+			sort.Strings(dirs)
+			if fmt.Sprint(dirs) != tt.wantDirs {
+				t.Errorf("dirs = %v, want %s", dirs, tt.wantDirs)
+			}
+		})
+	}
+}
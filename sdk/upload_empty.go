@@ -0,0 +1,77 @@
+package sdk
+
+import (
+	"context"
+	"fmt"
+)
+
+// 0 字节内容的 md5/sha1
+const (
+	emptyFileMD5  = "d41d8cd98f00b204e9800998ecf8427e"
+	emptyFileSHA1 = "da39a3ee5e6b4b0d3255bfef95601890afd80709"
+)
+
+// uploadEmptyFile 上传 0 字节文件：先用空内容的哈希调用 upHash，服务端已有空文件（通常如此）时直接 upFinish 完成秒传；
+// 未命中时上传一个 0 字节的分片 1 再 commit 和 upFinish（CompleteMultipartUpload 至少需要一个分片）
+func (qc *QuarkClient) uploadEmptyFile(pre *PreUploadResponse, mimeType string) *StandardResponse {
+	hashResp, err := qc.upHash(emptyFileMD5, emptyFileSHA1, pre.Data.TaskID)
+	if err != nil {
+		return &StandardResponse{
+			Success: false,
+			Code:    "UPLOAD_HASH_ERROR",
+			Message: fmt.Sprintf("hash check for empty file failed: %v", err),
+			Data:    nil,
+		}
+	}
+	if hashResp.Data.Finish {
+		return qc.finishRapidUpload(pre)
+	}
+
+	etag, _, err := qc.upPart(context.Background(), pre, mimeType, 1, []byte{}, nil, nil)
+	if err != nil {
+		return &StandardResponse{
+			Success: false,
+			Code:    "UPLOAD_PART_ERROR",
+			Message: fmt.Sprintf("failed to upload empty part: %v", err),
+			Data:    nil,
+		}
+	}
+	finish, err := qc.upCommit(pre, []string{etag})
+	if err == nil && (finish.Code != 0 || finish.Status != 200) {
+		err = fmt.Errorf("code=%d, status=%d", finish.Code, finish.Status)
+	}
+	if err != nil {
+		return &StandardResponse{
+			Success: false,
+			Code:    "COMMIT_UPLOAD_ERROR",
+			Message: fmt.Sprintf("commit upload failed: %v", err),
+			Data:    nil,
+		}
+	}
+	finishResp, err := qc.upFinish(pre)
+	if err == nil && (finishResp.Code != 0 || finishResp.Status != 200) {
+		err = fmt.Errorf("code=%d, status=%d", finishResp.Code, finishResp.Status)
+	}
+	if err != nil {
+		return &StandardResponse{
+			Success: false,
+			Code:    "FINISH_UPLOAD_ERROR",
+			Message: fmt.Sprintf("finish upload failed: %v", err),
+			Data:    nil,
+		}
+	}
+
+	responseData := make(map[string]interface{})
+	for k, v := range finishResp.Data {
+		if k != "preview_url" {
+			responseData[k] = v
+		}
+	}
+	responseData["rapid"] = false
+	return &StandardResponse{
+		Success: true,
+		Code:    "OK",
+		Message: "上传完成（空文件）",
+		Data:    responseData,
+	}
+}
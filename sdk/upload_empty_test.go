@@ -0,0 +1,67 @@
+package sdk
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestUploadFile_EmptyFile(t *testing.T) {
+	tests := []struct {
+		name       string
+		hashFinish bool
+		wantRapid  bool
+		wantPuts   string
+	}{
+		{name: "server has empty file", hashFinish: true, wantRapid: true, wantPuts: "[]"},
+		{name: "upload one empty part", hashFinish: false, wantRapid: false, wantPuts: "[1]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			isolateUploadState(t)
+			localPath := filepath.Join(t.TempDir(), ".gitkeep")
+			if err := os.WriteFile(localPath, nil, 0644); err != nil {
+				t.Fatal(err)
+			}
+			server := &fakeUploadServer{partSize: 1024, hashFinish: tt.hashFinish}
+			serve := server.roundTrip(t)
+			var hashBody string
+			client := createMockClient(t, func(req *http.Request) (*http.Response, error) {
+				if strings.HasSuffix(req.URL.Path, FILE_UPDATE_HASH) {
+					body, _ := io.ReadAll(req.Body)
+					hashBody = string(body)
+				}
+				return serve(req)
+			})
+
+			var last *UploadProgress
+			resp, err := client.UploadFile(localPath, "/.gitkeep", func(p *UploadProgress) { last = p }, nil)
+			if err != nil || !resp.Success {
+				t.Fatalf("UploadFile() = %+v, %v", resp, err)
+			}
+			if resp.Data["rapid"] != tt.wantRapid {
+				t.Errorf("rapid = %v, want %v", resp.Data["rapid"], tt.wantRapid)
+			}
+			if !strings.Contains(hashBody, emptyFileMD5) || !strings.Contains(hashBody, emptyFileSHA1) {
+				t.Errorf("upHash body = %s, want the empty file hashes", hashBody)
+			}
+			if got := fmt.Sprint(server.puts); got != tt.wantPuts {
+				t.Errorf("uploaded parts %s, want %s", got, tt.wantPuts)
+			}
+			if !tt.wantRapid {
+				if len(server.parts[1]) != 0 || !strings.Contains(server.committed, "<PartNumber>1</PartNumber>") {
+					t.Errorf("part 1 = %d bytes, commit %s", len(server.parts[1]), server.committed)
+				}
+			} else if server.committed != "" {
+				t.Errorf("rapid upload committed %s", server.committed)
+			}
+			if last == nil || last.Progress != 100 || last.Rapid != tt.wantRapid {
+				t.Errorf("last progress = %+v, want 100%% with Rapid %v", last, tt.wantRapid)
+			}
+		})
+	}
+}
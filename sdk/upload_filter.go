@@ -0,0 +1,77 @@
+package sdk
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// String 返回规则的文本形式，如 "include *.jpg"、"exclude node_modules/"
+func (r UploadFilterRule) String() string {
+	if r.Exclude {
+		return "exclude " + r.Pattern
+	}
+	return "include " + r.Pattern
+}
+
+// uploadFilter 递归上传的 include/exclude 过滤（类似 rsync）：规则按顺序匹配，第一条命中的规则决定结果；
+// 没有规则命中时目录总会进入，文件在存在 include 规则时排除，否则上传；被排除的目录整个子树都不遍历
+type uploadFilter struct {
+	rules      []uploadFilterMatcher
+	hasInclude bool
+}
+
+type uploadFilterMatcher struct {
+	ignoreRule
+	exclude bool
+	text    string
+}
+
+// newUploadFilter 解析过滤规则，模式语法与 .kuakeignore 相同但不支持 "!"；没有规则时返回 nil
+func newUploadFilter(rules []UploadFilterRule) (*uploadFilter, error) {
+	if len(rules) == 0 {
+		return nil, nil
+	}
+	f := &uploadFilter{}
+	for _, r := range rules {
+		rule, ok, err := parseIgnorePattern(r.Pattern)
+		if err != nil {
+			return nil, err
+		}
+		if !ok || rule.negate {
+			return nil, fmt.Errorf("invalid filter pattern %q", r.Pattern)
+		}
+		f.rules = append(f.rules, uploadFilterMatcher{ignoreRule: rule, exclude: r.Exclude, text: r.String()})
+		if !r.Exclude {
+			f.hasInclude = true
+		}
+	}
+	return f, nil
+}
+
+// match 判断 relPath（相对本地目录）是否上传（目录是否进入），rule 为决定结果的规则，没有规则命中时为空
+func (f *uploadFilter) match(relPath string, isDir bool) (included bool, rule string) {
+	if f == nil {
+		return true, ""
+	}
+	segments := strings.Split(strings.Trim(path.Clean(filepath.ToSlash(relPath)), "/"), "/")
+	for _, r := range f.rules {
+		if r.dirOnly && !isDir {
+			continue
+		}
+		if matchSegments(r.segments, segments) {
+			return !r.exclude, r.text
+		}
+	}
+	return isDir || !f.hasInclude, ""
+}
+
+// invalidFilterResponse 过滤规则无效时的错误响应
+func invalidFilterResponse(err error) *StandardResponse {
+	return &StandardResponse{
+		Success: false,
+		Code:    "INVALID_ARGS",
+		Message: err.Error(),
+	}
+}
@@ -0,0 +1,95 @@
+package sdk
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestUploadFilter(t *testing.T) {
+	rules := []UploadFilterRule{
+		{Exclude: true, Pattern: "node_modules/"},
+		{Exclude: true, Pattern: "*.tmp"},
+		{Pattern: "*.jpg"},
+		{Pattern: "raw/**/*.raw"},
+	}
+	filter, err := newUploadFilter(rules)
+	if err != nil {
+		t.Fatalf("newUploadFilter() error = %v", err)
+	}
+	tests := []struct {
+		path     string
+		isDir    bool
+		want     bool
+		wantRule string
+	}{
+		{path: "a.jpg", want: true, wantRule: "include *.jpg"},
+		{path: "sub/deep/b.jpg", want: true, wantRule: "include *.jpg"},
+		{path: "raw/2024/c.raw", want: true, wantRule: "include raw/**/*.raw"},
+		{path: "other/c.raw", want: false},
+		{path: "x.jpg.tmp", want: false, wantRule: "exclude *.tmp"},
+		{path: "web/node_modules", isDir: true, want: false, wantRule: "exclude node_modules/"},
+		{path: "node_modules", want: false}, // 同名文件不匹配只针对目录的规则，也不匹配 include
+		{path: "notes.txt", want: false},
+		{path: "sub", isDir: true, want: true},
+	}
+	for _, tt := range tests {
+		got, rule := filter.match(filepath.FromSlash(tt.path), tt.isDir)
+		if got != tt.want || rule != tt.wantRule {
+			t.Errorf("match(%s) = %v, %q, want %v, %q", tt.path, got, rule, tt.want, tt.wantRule)
+		}
+	}
+
+	// 第一条命中的规则生效
+	filter, _ = newUploadFilter([]UploadFilterRule{{Pattern: "keep.tmp"}, {Exclude: true, Pattern: "*.tmp"}})
+	if got, rule := filter.match("keep.tmp", false); !got || rule != "include keep.tmp" {
+		t.Errorf("match(keep.tmp) = %v, %q, want the include rule first", got, rule)
+	}
+	if got, _ := (*uploadFilter)(nil).match("any", false); !got {
+		t.Error("nil filter should include everything")
+	}
+	for _, pattern := range []string{"[abc", "!*.jpg", ""} {
+		if _, err := newUploadFilter([]UploadFilterRule{{Pattern: pattern}}); err == nil {
+			t.Errorf("newUploadFilter(%q) expected error", pattern)
+		}
+	}
+}
+
+func TestPlanDirUpload_Filters(t *testing.T) {
+	localDir := t.TempDir()
+	for _, name := range []string{"a.jpg", "b.tmp", "notes.txt", "web/c.jpg", "web/node_modules/d.jpg"} {
+		p := filepath.Join(localDir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	filter, err := newUploadFilter([]UploadFilterRule{{Exclude: true, Pattern: "node_modules/"}, {Exclude: true, Pattern: "*.tmp"}, {Pattern: "*.jpg"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	jobs, dirs, excluded, err := planDirUpload(localDir, "/r", UploadLinksSkip, nil, filter)
+	if err != nil {
+		t.Fatalf("planDirUpload() error = %v", err)
+	}
+	var got, gotExcluded []string
+	for _, job := range jobs {
+		got = append(got, job.Path+":"+job.Rule)
+	}
+	for _, job := range excluded {
+		gotExcluded = append(gotExcluded, job.Path+":"+job.Rule)
+	}
+	if want := "[/r/a.jpg:include *.jpg /r/web/c.jpg:include *.jpg]"; fmt.Sprint(got) != want {
+		t.Errorf("jobs = %v, want %s", got, want)
+	}
+	// node_modules 整个子树不遍历，只记下目录本身
+	if want := "[/r/b.tmp:exclude *.tmp /r/notes.txt: /r/web/node_modules:exclude node_modules/]"; fmt.Sprint(gotExcluded) != want {
+		t.Errorf("excluded = %v, want %s", gotExcluded, want)
+	}
+	if want := "[/r /r/web]"; fmt.Sprint(dirs) != want {
+		t.Errorf("dirs = %v, want %s", dirs, want)
+	}
+}
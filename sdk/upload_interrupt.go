@@ -0,0 +1,22 @@
+package sdk
+
+import "fmt"
+
+// uploadInterrupted UploadOptions.Context 被取消时 UploadFile 返回的结果：Code 为 INTERRUPTED，
+// 已上传的分片保存在 statePath 中，重新上传同一文件到同一路径时从断点继续
+func uploadInterrupted(statePath string, uploadedParts, totalParts int) *StandardResponse {
+	message := "上传已中断"
+	if uploadedParts > 0 {
+		message = fmt.Sprintf("上传已中断，已保存断点续传状态（%d/%d 个分片），重新执行相同的上传命令可继续上传", uploadedParts, totalParts)
+	}
+	return &StandardResponse{
+		Success: false,
+		Code:    "INTERRUPTED",
+		Message: message,
+		Data: map[string]interface{}{
+			"state_path":     statePath,
+			"uploaded_parts": uploadedParts,
+			"total_parts":    totalParts,
+		},
+	}
+}
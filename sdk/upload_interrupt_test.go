@@ -0,0 +1,85 @@
+package sdk
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+)
+
+// TestUploadFile_Interrupted 上传中取消 UploadOptions.Context：返回 INTERRUPTED 并保留状态文件，重新上传时续传
+func TestUploadFile_Interrupted(t *testing.T) {
+	const partSize = 1024
+	content := bytes.Repeat([]byte("0123456789abcdef"), 4*partSize/16+3) // 5 个分片
+
+	tests := []struct {
+		name       string
+		partThread int
+		wantParts  int // 中断时已保存的分片数，-1 表示不检查（并行上传时取决于调度）
+	}{
+		{name: "sequential", partThread: 1, wantParts: 2},
+		{name: "parallel", partThread: 3, wantParts: -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			isolateUploadState(t)
+			localPath := filepath.Join(t.TempDir(), "big.bin")
+			if err := os.WriteFile(localPath, content, 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			server := &fakeUploadServer{partSize: partSize, partThread: tt.partThread}
+			serve := server.roundTrip(t)
+			var interrupted atomic.Bool
+			client := createMockClient(t, func(req *http.Request) (*http.Response, error) {
+				// 模拟上传分片 3 时按下 Ctrl+C
+				if req.Method == "PUT" && req.URL.Query().Get("partNumber") == "3" && interrupted.CompareAndSwap(false, true) {
+					cancel()
+					return nil, context.Canceled
+				}
+				return serve(req)
+			})
+
+			resp, err := client.UploadFile(localPath, "/big.bin", nil, &UploadOptions{Context: ctx})
+			if err != nil || resp.Success || resp.Code != "INTERRUPTED" {
+				t.Fatalf("UploadFile() = %+v, %v, want INTERRUPTED", resp, err)
+			}
+			if tt.wantParts >= 0 && resp.Data["uploaded_parts"] != tt.wantParts {
+				t.Errorf("uploaded_parts = %v, want %d", resp.Data["uploaded_parts"], tt.wantParts)
+			}
+			statePath := client.uploadStatePath(localPath, "/big.bin")
+			if resp.Data["state_path"] != statePath {
+				t.Errorf("state_path = %v, want %s", resp.Data["state_path"], statePath)
+			}
+			if _, err := os.Stat(statePath); err != nil {
+				t.Fatalf("upload state not kept after interrupt: %v", err)
+			}
+
+			// 已取消的 context 不再开始新的上传
+			server.puts = nil
+			if resp, _ := client.UploadFile(localPath, "/big.bin", nil, &UploadOptions{Context: ctx}); resp.Code != "INTERRUPTED" || len(server.puts) != 0 {
+				t.Errorf("UploadFile() with canceled context = %s, sent parts %v", resp.Code, server.puts)
+			}
+
+			resp, err = client.UploadFile(localPath, "/big.bin", nil, nil)
+			if err != nil || !resp.Success {
+				t.Fatalf("resumed UploadFile() = %+v, %v", resp, err)
+			}
+			if server.preCalls != 1 {
+				t.Errorf("pre-upload called %d times, want 1 (resume the interrupted session)", server.preCalls)
+			}
+			var uploaded []byte
+			for pn := 1; pn <= 5; pn++ {
+				uploaded = append(uploaded, server.parts[pn]...)
+			}
+			if !bytes.Equal(uploaded, content) {
+				t.Errorf("uploaded %d bytes in %d parts, want the file content", len(uploaded), len(server.parts))
+			}
+		})
+	}
+}
@@ -0,0 +1,25 @@
+package sdk
+
+import (
+	"mime"
+	"net/http"
+	"path"
+)
+
+// uploadMimeType 返回上传使用的 MIME 类型（upPre 与 upPart 共用）：contentType 非空时直接使用，其次按文件扩展名，
+// 扩展名不认识时用 http.DetectContentType 嗅探 head 返回的内容开头（最多 MIME_SNIFF_BYTES 字节）；
+// head 为 nil 或没有内容时为 application/octet-stream
+func uploadMimeType(fileName, contentType string, head func() []byte) string {
+	if contentType != "" {
+		return contentType
+	}
+	if mimeType := mime.TypeByExtension(path.Ext(fileName)); mimeType != "" {
+		return mimeType
+	}
+	if head != nil {
+		if data := head(); len(data) > 0 {
+			return http.DetectContentType(data)
+		}
+	}
+	return "application/octet-stream"
+}
@@ -0,0 +1,113 @@
+package sdk
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"testing/iotest"
+)
+
+func TestUploadMimeType(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	tests := []struct {
+		name        string
+		fileName    string
+		contentType string
+		head        []byte
+		want        string
+	}{
+		{name: "explicit", fileName: "a.png", contentType: "text/markdown", head: png, want: "text/markdown"},
+		{name: "extension", fileName: "a.png", head: []byte("not a png"), want: "image/png"},
+		{name: "sniffed png", fileName: "scan", head: png, want: "image/png"},
+		{name: "sniffed text", fileName: "README", head: []byte("hello world\n"), want: "text/plain; charset=utf-8"},
+		{name: "empty", fileName: "empty", head: nil, want: "application/octet-stream"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sniffed := false
+			got := uploadMimeType(tt.fileName, tt.contentType, func() []byte {
+				sniffed = true
+				return tt.head
+			})
+			if got != tt.want {
+				t.Errorf("uploadMimeType() = %q, want %q", got, tt.want)
+			}
+			if sniffed && (tt.contentType != "" || strings.Contains(tt.fileName, ".")) {
+				t.Errorf("content sniffed although the type was already known")
+			}
+		})
+	}
+}
+
+func TestUpload_SniffedMimeType(t *testing.T) {
+	isolateUploadState(t)
+	const partSize = 1024
+	content := append([]byte("\x89PNG\r\n\x1a\n"), bytes.Repeat([]byte{0}, 2*partSize)...) // 3 个分片
+	localPath := filepath.Join(t.TempDir(), "scan")
+	if err := os.WriteFile(localPath, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	server := &fakeUploadServer{partSize: partSize}
+	serve := server.roundTrip(t)
+	var mu sync.Mutex
+	var preType string
+	var partTypes []string
+	client := createMockClient(t, func(req *http.Request) (*http.Response, error) {
+		mu.Lock()
+		switch {
+		case strings.HasSuffix(req.URL.Path, FILE_UPLOAD_PRE):
+			var body struct {
+				FormatType string `json:"format_type"`
+			}
+			json.NewDecoder(req.Body).Decode(&body)
+			preType = body.FormatType
+			req.Body = http.NoBody
+		case req.Method == "PUT":
+			partTypes = append(partTypes, req.Header.Get("Content-Type"))
+		}
+		mu.Unlock()
+		return serve(req)
+	})
+
+	uploads := []struct {
+		name   string
+		upload func(opts *UploadOptions) (*StandardResponse, error)
+	}{
+		{"UploadFile", func(opts *UploadOptions) (*StandardResponse, error) {
+			return client.UploadFile(localPath, "/scan", nil, opts)
+		}},
+		{"UploadReader", func(opts *UploadOptions) (*StandardResponse, error) {
+			return client.UploadReader(iotest.HalfReader(bytes.NewReader(content)), int64(len(content)), "/scan", opts, nil)
+		}},
+	}
+	for _, u := range uploads {
+		for _, tc := range []struct{ contentType, want string }{{"", "image/png"}, {"image/x-scan", "image/x-scan"}} {
+			preType, partTypes = "", nil
+			resp, err := u.upload(&UploadOptions{NoResume: true, ContentType: tc.contentType})
+			if err != nil || !resp.Success {
+				t.Fatalf("%s(%q) = %+v, %v", u.name, tc.contentType, resp, err)
+			}
+			if preType != tc.want || len(partTypes) != 3 {
+				t.Errorf("%s(%q): upPre format_type %q, %d parts, want %s", u.name, tc.contentType, preType, len(partTypes), tc.want)
+			}
+			for _, got := range partTypes {
+				if got != tc.want {
+					t.Errorf("%s(%q): part Content-Type %q, want %s", u.name, tc.contentType, got, tc.want)
+				}
+			}
+			var uploaded []byte
+			for pn := 1; pn <= 3; pn++ {
+				uploaded = append(uploaded, server.parts[pn]...)
+			}
+			if !bytes.Equal(uploaded, content) {
+				t.Errorf("%s(%q): uploaded %d bytes, want %d", u.name, tc.contentType, len(uploaded), len(content))
+			}
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package sdk
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// checkUploadFileName 检查上传的目标文件名，返回的错误指出第一个非法字符（控制字符、非法 UTF-8）或超长的字节数
+func checkUploadFileName(name string) error {
+	for i, r := range name {
+		if r == utf8.RuneError {
+			if _, size := utf8.DecodeRuneInString(name[i:]); size == 1 {
+				return fmt.Errorf("file name %q contains invalid UTF-8 byte 0x%02x at byte %d", name, name[i], i)
+			}
+		}
+		if unicode.IsControl(r) {
+			return fmt.Errorf("file name %q contains control character %q (U+%04X) at byte %d", name, r, r, i)
+		}
+	}
+	if len(name) > MAX_FILE_NAME_BYTES {
+		return fmt.Errorf("file name %q is %d bytes, longer than %d bytes", name, len(name), MAX_FILE_NAME_BYTES)
+	}
+	return nil
+}
+
+// sanitizeUploadFileName 把控制字符和非法 UTF-8 替换为 UPLOAD_NAME_REPLACEMENT，超长时保留扩展名截断到 MAX_FILE_NAME_BYTES 字节
+func sanitizeUploadFileName(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		if r == utf8.RuneError {
+			if _, size := utf8.DecodeRuneInString(name[i:]); size == 1 {
+				b.WriteString(UPLOAD_NAME_REPLACEMENT)
+				continue
+			}
+		}
+		if unicode.IsControl(r) {
+			b.WriteString(UPLOAD_NAME_REPLACEMENT)
+			continue
+		}
+		b.WriteRune(r)
+	}
+	name = b.String()
+	if len(name) <= MAX_FILE_NAME_BYTES {
+		return name
+	}
+	ext := path.Ext(name)
+	if len(ext) > MAX_FILE_NAME_BYTES/4 {
+		ext = ""
+	}
+	stem := name[:len(name)-len(ext)]
+	limit := MAX_FILE_NAME_BYTES - len(ext)
+	// 按字符边界截断，避免截出半个 UTF-8 字符
+	for limit > 0 && !utf8.RuneStart(stem[limit]) {
+		limit--
+	}
+	return stem[:limit] + ext
+}
+
+// uploadNamedDestPath 校验 destPath 的文件名；sanitize 为 true 时返回替换非法字符后的路径，否则文件名非法时返回错误
+func uploadNamedDestPath(destPath string, sanitize bool) (string, error) {
+	dir, name := path.Split(destPath)
+	if err := checkUploadFileName(name); err == nil {
+		return destPath, nil
+	} else if !sanitize {
+		return "", err
+	}
+	return dir + sanitizeUploadFileName(name), nil
+}
+
+// withSanitizedName 在文件名被替换的上传结果中记下实际路径（path）和原文件名（original_name）
+func withSanitizedName(resp *StandardResponse, err error, originalPath, namedPath string) (*StandardResponse, error) {
+	if err != nil || resp == nil {
+		return resp, err
+	}
+	if resp.Data == nil {
+		resp.Data = make(map[string]interface{})
+	}
+	if _, ok := resp.Data["path"]; !ok {
+		resp.Data["path"] = namedPath
+	}
+	resp.Data["original_name"] = path.Base(originalPath)
+	return resp, nil
+}
+
+// invalidFileNameResponse 文件名非法时的错误响应
+func invalidFileNameResponse(destPath string, err error) *StandardResponse {
+	return &StandardResponse{
+		Success: false,
+		Code:    "INVALID_FILE_NAME",
+		Message: fmt.Sprintf("invalid file name: %v", err),
+		Data:    map[string]interface{}{"path": destPath},
+	}
+}
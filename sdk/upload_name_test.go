@@ -0,0 +1,89 @@
+package sdk
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestSanitizeUploadFileName(t *testing.T) {
+	long := strings.Repeat("文", 100) + ".txt" // 304 字节
+	tests := []struct {
+		name    string
+		input   string
+		wantErr string
+		want    string
+	}{
+		{name: "valid", input: "report 2024.pdf", want: "report 2024.pdf"},
+		{name: "tab", input: "a\tb.txt", wantErr: `'\t' (U+0009) at byte 1`, want: "a_b.txt"},
+		{name: "newline and del", input: "x\ny\x7f.log", wantErr: `'\n'`, want: "x_y_.log"},
+		{name: "invalid utf-8", input: "bad\xff.bin", wantErr: "invalid UTF-8 byte 0xff at byte 3", want: "bad_.bin"},
+		{name: "too long", input: long, wantErr: "304 bytes", want: strings.Repeat("文", 83) + ".txt"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkUploadFileName(tt.input)
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("checkUploadFileName() = %v, want nil", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("checkUploadFileName() = %v, want error containing %s", err, tt.wantErr)
+			}
+			got := sanitizeUploadFileName(tt.input)
+			if got != tt.want {
+				t.Errorf("sanitizeUploadFileName() = %q, want %q", got, tt.want)
+			}
+			if len(got) > MAX_FILE_NAME_BYTES || !utf8.ValidString(got) || checkUploadFileName(got) != nil {
+				t.Errorf("sanitizeUploadFileName() = %q is still invalid", got)
+			}
+		})
+	}
+}
+
+func TestUploadFile_InvalidFileName(t *testing.T) {
+	isolateUploadState(t)
+	localPath := filepath.Join(t.TempDir(), "a\tb.txt")
+	if err := os.WriteFile(localPath, []byte("hello"), 0644); err != nil {
+		t.Skipf("file system does not allow tabs in file names: %v", err)
+	}
+	infoFn, _ := fakeFileInfoServer(map[string]map[string]interface{}{})
+	server := &fakeUploadServer{partSize: 1024}
+	serve := server.roundTrip(t)
+	var preName string
+	client := createMockClient(t, func(req *http.Request) (*http.Response, error) {
+		switch req.URL.Path {
+		case FILE_INFO_PATH_LIST, FILE_INFO, FILE_SORT:
+			return infoFn(req)
+		case FILE_UPLOAD_PRE:
+			var body struct {
+				FileName string `json:"file_name"`
+			}
+			json.NewDecoder(req.Body).Decode(&body)
+			preName = body.FileName
+			req.Body = http.NoBody
+		}
+		return serve(req)
+	})
+
+	resp, err := client.UploadFile(localPath, "/", nil, nil)
+	if err != nil || resp.Success || resp.Code != "INVALID_FILE_NAME" || !strings.Contains(resp.Message, `'\t'`) {
+		t.Fatalf("UploadFile() = %+v, %v, want INVALID_FILE_NAME naming the tab", resp, err)
+	}
+	if server.preCalls != 0 {
+		t.Errorf("pre-upload called %d times for an invalid name", server.preCalls)
+	}
+
+	for _, onConflict := range []UploadConflictPolicy{"", UploadConflictRename} {
+		resp, err = client.UploadFile(localPath, "/", nil, &UploadOptions{SanitizeNames: true, OnConflict: onConflict, NoResume: true})
+		if err != nil || !resp.Success {
+			t.Fatalf("UploadFile(SanitizeNames, %q) = %+v, %v", onConflict, resp, err)
+		}
+		if preName != "a_b.txt" || resp.Data["path"] != "/a_b.txt" || resp.Data["original_name"] != "a\tb.txt" {
+			t.Errorf("pre-upload name %q, data %v, want a_b.txt", preName, resp.Data)
+		}
+	}
+}
@@ -0,0 +1,276 @@
+package sdk
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strings"
+)
+
+// 上传预演（PlanUpload）的计划动作，写入 UploadPlanAction.Action
+const (
+	UploadPlanCreateDir = "create_dir" // 创建远程目录
+	UploadPlanUpload    = "upload"     // 上传新文件（--on-conflict rename 时 Path 为改名后的路径）
+	UploadPlanSkip      = "skip"       // 远端已有同名文件，按去重/冲突策略跳过；或本地为按 Links 跳过的符号链接
+	UploadPlanOverwrite = "overwrite"  // 远端已有同名文件，上传时覆盖
+	UploadPlanFail      = "fail"       // 上传会失败（本地文件不可读、按冲突策略不上传等），原因见 Reason
+	UploadPlanExclude   = "exclude"    // 被过滤或忽略规则排除（Rule 为命中的规则），目录被排除时其下的内容不再列出
+)
+
+// PlanUpload 上传预演：按 UploadFile（localPath 为目录时按 UploadDir）的规则计算会创建的目录和每个文件的动作，
+// 只列出目标目录判断冲突，不发出任何创建、删除或上传请求；opts 中只有 Policy、OnConflict、SanitizeNames、CreateParents、Links、Filters 和 Ignore 生效（本地目录下的 IGNORE_FILE_NAME 同样生效）
+// 返回 Data 包含 actions（[]UploadPlanAction，目录在前、文件按远程路径排序，被排除的条目在最后；Rule 为决定结果的过滤规则）、counts（各动作的数量）和 total_bytes（upload 与 overwrite 要传输的字节数）
+func (qc *QuarkClient) PlanUpload(localPath, destPath string, opts UploadOptions) (*StandardResponse, error) {
+	localPath = stripQuotes(localPath)
+	info, err := os.Stat(localPath)
+	if err != nil {
+		return &StandardResponse{
+			Success: false,
+			Code:    "FILE_INFO_ERROR",
+			Message: fmt.Sprintf("failed to get file info: %v", err),
+		}, nil
+	}
+
+	var jobs, excluded []UploadResult
+	var dirs []string
+	var parent string // 必须已存在的远程目录（CreateParents 为 false 时）
+	if info.IsDir() {
+		remoteRoot := normalizePath(destPath)
+		if remoteRoot == "" || remoteRoot == "." {
+			remoteRoot = "/"
+		}
+		filter, filterErr := newUploadFilter(opts.Filters)
+		if filterErr != nil {
+			return invalidFilterResponse(filterErr), nil
+		}
+		var walkErr error
+		jobs, dirs, excluded, walkErr = planDirUpload(localPath, remoteRoot, opts.Links, opts.Ignore, filter)
+		if walkErr != nil {
+			return &StandardResponse{
+				Success: false,
+				Code:    "READ_DIR_ERROR",
+				Message: fmt.Sprintf("failed to read local dir: %v", walkErr),
+			}, nil
+		}
+		parent = path.Dir(remoteRoot)
+	} else {
+		dest := uploadDestPath(destPath, normalizeNFC(info.Name()))
+		jobs = []UploadResult{{LocalPath: localPath, Path: dest, Size: info.Size()}}
+		dirs = []string{path.Dir(dest)}
+		parent = path.Dir(dest)
+	}
+
+	planner := &uploadPlanner{qc: qc, opts: opts, listed: make(map[string]map[string]QuarkFileInfo), failed: make(map[string]bool)}
+	if !opts.CreateParents {
+		exists, errResp := planner.dirExists(parent)
+		if errResp != nil {
+			return errResp, nil
+		}
+		if !exists {
+			return parentNotFoundResponse(parent), nil
+		}
+	}
+	for _, dir := range dirs {
+		if errResp := planner.ensureDir(dir); errResp != nil {
+			return errResp, nil
+		}
+	}
+	for _, job := range jobs {
+		if errResp := planner.planFile(job); errResp != nil {
+			return errResp, nil
+		}
+	}
+	for _, job := range excluded {
+		action := UploadPlanAction{Action: UploadPlanExclude, LocalPath: job.LocalPath, Path: job.Path, Rule: job.Rule}
+		if job.Rule == "" {
+			action.Reason = "未命中任何 include 规则"
+		}
+		planner.actions = append(planner.actions, action)
+	}
+
+	counts := map[string]int{UploadPlanCreateDir: 0, UploadPlanUpload: 0, UploadPlanSkip: 0, UploadPlanOverwrite: 0, UploadPlanFail: 0, UploadPlanExclude: 0}
+	var totalBytes int64
+	for _, action := range planner.actions {
+		counts[action.Action]++
+		if action.Action == UploadPlanUpload || action.Action == UploadPlanOverwrite {
+			totalBytes += action.Size
+		}
+	}
+	message := fmt.Sprintf("预演：将创建 %d 个目录，上传 %d 个文件（其中覆盖 %d 个），跳过 %d 个，共 %d 字节",
+		counts[UploadPlanCreateDir], counts[UploadPlanUpload]+counts[UploadPlanOverwrite], counts[UploadPlanOverwrite], counts[UploadPlanSkip], totalBytes)
+	if counts[UploadPlanFail] > 0 {
+		message += fmt.Sprintf("，%d 个文件会失败", counts[UploadPlanFail])
+	}
+	if counts[UploadPlanExclude] > 0 {
+		message += fmt.Sprintf("，按过滤规则排除 %d 项", counts[UploadPlanExclude])
+	}
+	return &StandardResponse{
+		Success: true,
+		Code:    "OK",
+		Message: message,
+		Data: map[string]interface{}{
+			"dry_run":     true,
+			"actions":     planner.actions,
+			"counts":      counts,
+			"total_bytes": totalBytes,
+		},
+	}, nil
+}
+
+// uploadPlanner 计算上传计划，每个远程目录最多列出一次
+type uploadPlanner struct {
+	qc      *QuarkClient
+	opts    UploadOptions
+	listed  map[string]map[string]QuarkFileInfo // 远程目录 -> 文件名 -> 条目；不存在或计划创建的目录为空
+	failed  map[string]bool                     // 无法创建的远程目录（远端同名的是文件），其下的目录和文件都会失败
+	actions []UploadPlanAction
+}
+
+// children 返回远程目录 dir 下的条目：父目录已列出时从其中找到 dir 的 fid 再列出，dir 不存在时返回空
+func (p *uploadPlanner) children(dir string) (map[string]QuarkFileInfo, *StandardResponse) {
+	if entries, ok := p.listed[dir]; ok {
+		return entries, nil
+	}
+	fid := "0"
+	if dir != "/" {
+		parent, errResp := p.children(path.Dir(dir))
+		if errResp != nil {
+			return nil, errResp
+		}
+		entry, ok := parent[path.Base(dir)]
+		if !ok || !entry.IsDirectory {
+			p.listed[dir] = map[string]QuarkFileInfo{}
+			return p.listed[dir], nil
+		}
+		fid = entry.Fid
+	}
+
+	entries := make(map[string]QuarkFileInfo)
+	resp, err := p.qc.ListByFidPages(fid, dir, ListOptions{}, func(files []QuarkFileInfo) bool {
+		for _, file := range files {
+			entries[file.Name] = file
+		}
+		return true
+	})
+	if err == nil && !resp.Success {
+		err = fmt.Errorf("%s", resp.Message)
+	}
+	if err != nil {
+		return nil, &StandardResponse{
+			Success: false,
+			Code:    "LIST_ERROR",
+			Message: fmt.Sprintf("failed to list remote dir %s: %v", dir, err),
+		}
+	}
+	p.listed[dir] = entries
+	return entries, nil
+}
+
+// dirExists 判断远程目录 dir 是否已存在（逐级列出上级目录）
+func (p *uploadPlanner) dirExists(dir string) (bool, *StandardResponse) {
+	if dir == "/" {
+		return true, nil
+	}
+	parent, errResp := p.children(path.Dir(dir))
+	if errResp != nil {
+		return false, errResp
+	}
+	entry, ok := parent[path.Base(dir)]
+	return ok && entry.IsDirectory, nil
+}
+
+// ensureDir 远程目录 dir（及其上级目录）不存在时记下 create_dir 动作；远端同名的是文件时记为 fail
+func (p *uploadPlanner) ensureDir(dir string) *StandardResponse {
+	if _, ok := p.listed[dir]; ok || dir == "/" {
+		return nil
+	}
+	if errResp := p.ensureDir(path.Dir(dir)); errResp != nil {
+		return errResp
+	}
+	if p.failed[path.Dir(dir)] {
+		p.failed[dir] = true
+		p.listed[dir] = map[string]QuarkFileInfo{}
+		return nil
+	}
+	parent, errResp := p.children(path.Dir(dir))
+	if errResp != nil {
+		return errResp
+	}
+	if entry, ok := parent[path.Base(dir)]; ok {
+		if entry.IsDirectory {
+			return nil
+		}
+		p.actions = append(p.actions, UploadPlanAction{Action: UploadPlanFail, Path: dir, Reason: "远端已有同名文件，无法创建目录"})
+		p.failed[dir] = true
+	} else {
+		p.actions = append(p.actions, UploadPlanAction{Action: UploadPlanCreateDir, Path: dir})
+	}
+	p.listed[dir] = map[string]QuarkFileInfo{}
+	return nil
+}
+
+// planFile 按 opts 的冲突策略记下单个文件的动作，与 uploadData 的冲突和去重检查一致
+func (p *uploadPlanner) planFile(job UploadResult) *StandardResponse {
+	if job.Error == "" {
+		if namedPath, nameErr := uploadNamedDestPath(job.Path, p.opts.SanitizeNames); nameErr != nil {
+			job.Error = nameErr.Error()
+		} else {
+			job.Path = namedPath
+		}
+	}
+	action := UploadPlanAction{Action: UploadPlanUpload, LocalPath: job.LocalPath, Path: job.Path, Size: job.Size, Rule: job.Rule}
+	if job.Error == "" && p.failed[path.Dir(job.Path)] {
+		job.Error = fmt.Sprintf("无法创建远程目录 %s", path.Dir(job.Path))
+	}
+	if job.Error != "" {
+		action.Action, action.Reason = UploadPlanFail, job.Error
+		p.actions = append(p.actions, action)
+		return nil
+	}
+	if job.Skipped {
+		action.Action, action.Reason = UploadPlanSkip, job.Reason
+		p.actions = append(p.actions, action)
+		return nil
+	}
+	siblings, errResp := p.children(path.Dir(job.Path))
+	if errResp != nil {
+		return errResp
+	}
+	existing, exists := siblings[path.Base(job.Path)]
+	if exists {
+		sameSize := !existing.IsDirectory && existing.Size == job.Size
+		switch {
+		case p.opts.OnConflict == UploadConflictRename:
+			action.Path = availablePlanPath(job.Path, siblings)
+			action.Reason = fmt.Sprintf("远端已存在 %s，上传为新名字", job.Path)
+		case existing.IsDirectory && p.opts.OnConflict != "":
+			action.Action, action.Reason = UploadPlanFail, "远端已有同名目录"
+		case p.opts.OnConflict == UploadConflictFail:
+			action.Action, action.Reason = UploadPlanFail, "远端文件已存在"
+		case p.opts.OnConflict == UploadConflictSkip && sameSize,
+			p.opts.OnConflict == "" && p.opts.Policy == UploadPolicySkip,
+			p.opts.OnConflict == "" && p.opts.Policy == UploadPolicyRsync && sameSize:
+			action.Action, action.Reason = UploadPlanSkip, fmt.Sprintf("远端已存在（%d 字节）", existing.Size)
+		default:
+			action.Action, action.Reason = UploadPlanOverwrite, fmt.Sprintf("远端已存在（%d 字节）", existing.Size)
+		}
+	}
+	p.actions = append(p.actions, action)
+	if action.Action == UploadPlanUpload {
+		// 同一次上传中后面的文件改名时不能再用这个名字
+		siblings[path.Base(action.Path)] = QuarkFileInfo{Name: path.Base(action.Path), Size: job.Size}
+	}
+	return nil
+}
+
+// availablePlanPath 与 availableRemotePath 相同，但在已列出的目录条目中查找未使用的 "name (N).ext"
+func availablePlanPath(remotePath string, siblings map[string]QuarkFileInfo) string {
+	ext := path.Ext(remotePath)
+	base := strings.TrimSuffix(remotePath, ext)
+	for i := 1; ; i++ {
+		candidate := fmt.Sprintf("%s (%d)%s", base, i, ext)
+		if _, ok := siblings[path.Base(candidate)]; !ok {
+			return candidate
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package sdk
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPlanUpload(t *testing.T) {
+	local := t.TempDir()
+	writeFile := func(rel, content string) {
+		p := filepath.Join(local, rel)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile("same.txt", "12345")
+	writeFile("diff.txt", "1234")
+	writeFile("new.txt", "12")
+	writeFile("newdir/x.txt", "123")
+
+	entry := func(fid, name string, size int, dir bool) map[string]interface{} {
+		return map[string]interface{}{"fid": fid, "file_name": name, "size": size, "dir": dir}
+	}
+	dirs := map[string][]map[string]interface{}{
+		"0":   {entry("dst", "dst", 0, true)},
+		"dst": {entry("s", "same.txt", 5, false), entry("d", "diff.txt", 3, false)},
+	}
+
+	tests := []struct {
+		name      string
+		local     string
+		dest      string
+		opts      UploadOptions
+		want      []string // action path
+		wantBytes int64
+	}{
+		{
+			name:  "dir with on-conflict skip",
+			local: local,
+			dest:  "/dst",
+			opts:  UploadOptions{OnConflict: UploadConflictSkip},
+			want: []string{"create_dir /dst/newdir", "overwrite /dst/diff.txt", "upload /dst/new.txt",
+				"upload /dst/newdir/x.txt", "skip /dst/same.txt"},
+			wantBytes: 4 + 2 + 3,
+		},
+		{
+			name:  "dir with on-conflict rename",
+			local: local,
+			dest:  "/dst",
+			opts:  UploadOptions{OnConflict: UploadConflictRename},
+			want: []string{"create_dir /dst/newdir", "upload /dst/diff (1).txt", "upload /dst/new.txt",
+				"upload /dst/newdir/x.txt", "upload /dst/same (1).txt"},
+			wantBytes: 4 + 2 + 3 + 5,
+		},
+		{
+			name:      "dir with policy rsync",
+			local:     local,
+			dest:      "/dst",
+			opts:      UploadOptions{Policy: UploadPolicyRsync},
+			want:      []string{"create_dir /dst/newdir", "overwrite /dst/diff.txt", "upload /dst/new.txt", "upload /dst/newdir/x.txt", "skip /dst/same.txt"},
+			wantBytes: 4 + 2 + 3,
+		},
+		{
+			name:      "dir with filters",
+			local:     local,
+			dest:      "/dst",
+			opts:      UploadOptions{Filters: []UploadFilterRule{{Exclude: true, Pattern: "newdir/"}, {Exclude: true, Pattern: "same.txt"}}},
+			want:      []string{"overwrite /dst/diff.txt", "upload /dst/new.txt", "exclude /dst/newdir", "exclude /dst/same.txt"},
+			wantBytes: 4 + 2,
+		},
+		{
+			name:      "file with on-conflict fail",
+			local:     filepath.Join(local, "same.txt"),
+			dest:      "/dst/same.txt",
+			opts:      UploadOptions{OnConflict: UploadConflictFail},
+			want:      []string{"fail /dst/same.txt"},
+			wantBytes: 0,
+		},
+		{
+			name:      "file into missing dirs with create parents",
+			local:     filepath.Join(local, "new.txt"),
+			dest:      "/missing/a/new.txt",
+			opts:      UploadOptions{CreateParents: true},
+			want:      []string{"create_dir /missing", "create_dir /missing/a", "upload /missing/a/new.txt"},
+			wantBytes: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			serve := fakeTreeServer(dirs, nil)
+			client := createMockClient(t, func(req *http.Request) (*http.Response, error) {
+				if req.URL.Path != FILE_SORT {
+					t.Errorf("dry run sent %s %s", req.Method, req.URL.Path)
+				}
+				return serve(req)
+			})
+			resp, err := client.PlanUpload(tt.local, tt.dest, tt.opts)
+			if err != nil || !resp.Success {
+				t.Fatalf("PlanUpload() = %+v, %v", resp, err)
+			}
+			var got []string
+			for _, action := range resp.Data["actions"].([]UploadPlanAction) {
+				got = append(got, action.Action+" "+action.Path)
+			}
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("actions =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+			if resp.Data["total_bytes"] != tt.wantBytes {
+				t.Errorf("total_bytes = %v, want %d", resp.Data["total_bytes"], tt.wantBytes)
+			}
+		})
+	}
+
+	// 默认不创建缺失的父目录：单个文件看目标目录，目录上传看 dest 的上级目录
+	client := createMockClient(t, fakeTreeServer(dirs, nil))
+	for _, tc := range []struct{ local, dest, wantPath string }{
+		{filepath.Join(local, "new.txt"), "/missing/a/new.txt", "/missing/a"},
+		{local, "/missing/dst", "/missing"},
+	} {
+		resp, err := client.PlanUpload(tc.local, tc.dest, UploadOptions{})
+		if err != nil || resp.Code != "PARENT_NOT_FOUND" || resp.Data["path"] != tc.wantPath {
+			t.Errorf("PlanUpload(%s) = %+v, %v, want PARENT_NOT_FOUND for %s", tc.dest, resp, err, tc.wantPath)
+		}
+	}
+	resp, err := client.PlanUpload(local, "/dst/newtree", UploadOptions{})
+	if err != nil || !resp.Success {
+		t.Errorf("PlanUpload() into a new dir under an existing one = %+v, %v", resp, err)
+	}
+}
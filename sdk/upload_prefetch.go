@@ -0,0 +1,99 @@
+package sdk
+
+import "io"
+
+// partPrefetcher 顺序上传的双缓冲流水线：后台 goroutine 按序把分片读入 UPLOAD_PREFETCH_BUFFERS 个缓冲，
+// 上传方每取走一片、上传完后调用 release 归还缓冲，磁盘读取与上一片的网络传输重叠；分片仍按顺序交给上传方
+type partPrefetcher struct {
+	ready chan prefetchedPart
+	free  chan []byte
+	stop  chan struct{}
+	done  chan struct{}
+}
+
+// prefetchedPart 预读到的一个分片，err 非 nil 时为读取错误（data 为 nil）
+type prefetchedPart struct {
+	data []byte
+	err  error
+}
+
+// newPartPrefetcher 从 r 的当前位置开始按 partSize 预读分片，用完后必须调用 close
+func newPartPrefetcher(r io.Reader, partSize int64) *partPrefetcher {
+	p := &partPrefetcher{
+		ready: make(chan prefetchedPart, UPLOAD_PREFETCH_BUFFERS),
+		free:  make(chan []byte, UPLOAD_PREFETCH_BUFFERS),
+		stop:  make(chan struct{}),
+		done:  make(chan struct{}),
+	}
+	for i := 0; i < UPLOAD_PREFETCH_BUFFERS; i++ {
+		p.free <- getPartBuffer(partSize)
+	}
+	go p.run(r)
+	return p
+}
+
+func (p *partPrefetcher) run(r io.Reader) {
+	defer close(p.done)
+	defer close(p.ready)
+	for {
+		var buf []byte
+		select {
+		case buf = <-p.free:
+		case <-p.stop:
+			return
+		}
+		n, err := io.ReadFull(r, buf)
+		if err == io.ErrUnexpectedEOF {
+			err = nil // 最后一片不满 partSize
+		}
+		part := prefetchedPart{data: buf[:n], err: err}
+		if n == 0 || err != nil {
+			putPartBuffer(buf)
+			part.data = nil
+			if err == nil {
+				err = io.EOF
+			}
+			part.err = err
+		}
+		select {
+		case p.ready <- part:
+		case <-p.stop:
+			putPartBuffer(part.data)
+			return
+		}
+		if part.err != nil {
+			return
+		}
+	}
+}
+
+// next 返回下一个分片（最后一片可能不满 partSize），读完时返回 io.EOF
+func (p *partPrefetcher) next() ([]byte, error) {
+	part, ok := <-p.ready
+	if !ok {
+		return nil, io.EOF
+	}
+	return part.data, part.err
+}
+
+// release 归还 next 返回的缓冲，供预读下一片
+func (p *partPrefetcher) release(buf []byte) {
+	p.free <- buf[:cap(buf)]
+}
+
+// close 停止预读并把缓冲放回分片缓冲池；上传方持有、未 release 的缓冲由其自行处理
+func (p *partPrefetcher) close() {
+	close(p.stop)
+	<-p.done
+	for part := range p.ready {
+		putPartBuffer(part.data)
+	}
+	for {
+		select {
+		case buf := <-p.free:
+			putPartBuffer(buf)
+		default:
+			return
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package sdk
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+// signalReader 每次 Read 前向 reads 发送一次信号
+type signalReader struct {
+	r     io.Reader
+	reads chan struct{}
+}
+
+func (s *signalReader) Read(p []byte) (int, error) {
+	select {
+	case s.reads <- struct{}{}:
+	default:
+	}
+	return s.r.Read(p)
+}
+
+func TestPartPrefetcher(t *testing.T) {
+	const partSize = 1000
+	content := bytes.Repeat([]byte("0123456789"), 250) // 3 个分片，最后一片 500 字节
+	reader := &signalReader{r: bytes.NewReader(content), reads: make(chan struct{}, 8)}
+	prefetch := newPartPrefetcher(reader, partSize)
+	defer prefetch.close()
+
+	var got []byte
+	for i := 0; ; i++ {
+		chunk, err := prefetch.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("next() error = %v", err)
+		}
+		if i == 0 {
+			// 第一片还没归还时，后台已经在读第二片
+			for reads := 0; reads < 2; reads++ {
+				select {
+				case <-reader.reads:
+				case <-time.After(time.Second):
+					t.Fatalf("part 2 was not read while part 1 was held")
+				}
+			}
+		}
+		got = append(got, chunk...)
+		prefetch.release(chunk)
+	}
+	if !bytes.Equal(got, content) {
+		t.Errorf("read %d bytes, want %d", len(got), len(content))
+	}
+
+	// 读取错误按顺序出现在已读到的分片之后；提前 close 不会阻塞
+	readErr := errors.New("disk error")
+	failing := newPartPrefetcher(io.MultiReader(bytes.NewReader(content[:partSize]), &errReader{readErr}), partSize)
+	if chunk, err := failing.next(); err != nil || len(chunk) != partSize {
+		t.Fatalf("next() = %d bytes, %v, want a full part", len(chunk), err)
+	}
+	if _, err := failing.next(); !errors.Is(err, readErr) {
+		t.Errorf("next() error = %v, want %v", err, readErr)
+	}
+	failing.close()
+
+	early := newPartPrefetcher(bytes.NewReader(content), partSize)
+	early.next()
+	early.close()
+}
+
+type errReader struct{ err error }
+
+func (e *errReader) Read([]byte) (int, error) { return 0, e.err }
@@ -0,0 +1,196 @@
+package sdk
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+)
+
+// UploadFromReader 上传 r 中的全部数据（如 stdin 管道）到 destPath，结果与 UploadFile 一致
+// 预上传需要预先知道文件大小，因此先把数据写入系统临时目录（TMPDIR）下的临时文件，再用 UploadReader 上传，结束后删除临时文件；
+// 大小已知的数据直接用 UploadReader，不落盘。destPath 必须包含文件名（不能是根目录）
+func (qc *QuarkClient) UploadFromReader(r io.Reader, destPath string, progressCallback func(*UploadProgress), opts *UploadOptions) (*StandardResponse, error) {
+	destPath = normalizePath(destPath)
+	if destPath == "" || destPath == "/" || destPath == "." {
+		return &StandardResponse{
+			Success: false,
+			Code:    "INVALID_ARGS",
+			Message: "destination must include a file name when uploading from a stream",
+			Data:    nil,
+		}, nil
+	}
+
+	tmp, err := os.CreateTemp("", "kuake-stdin-*")
+	if err != nil {
+		return &StandardResponse{
+			Success: false,
+			Code:    "TEMP_FILE_ERROR",
+			Message: fmt.Sprintf("failed to create temp file: %v", err),
+			Data:    nil,
+		}, nil
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+	size, err := io.Copy(tmp, r)
+	if err == nil {
+		_, err = tmp.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		return &StandardResponse{
+			Success: false,
+			Code:    "READ_FILE_ERROR",
+			Message: fmt.Sprintf("failed to read input: %v", err),
+			Data:    nil,
+		}, nil
+	}
+	return qc.UploadReader(tmp, size, destPath, opts, progressCallback)
+}
+
+// UploadReader 把 r 中恰好 size 字节的数据按分片边读边上传到 destPath（必须包含文件名），同时计算 upHash 所需的 MD5+SHA1，结果与 UploadFile 一致；
+// 内存中只保留在途的分片，不保存断点续传状态，中断或失败后需从头上传；r 不足 size 字节就结束时返回 READ_FILE_ERROR。
+// r 实现 io.Seeker 时先读一遍计算哈希尝试秒传，命中则不上传分片，未命中再回到原位置上传（opts.NoRapidCheck 关闭预读）；
+// 不可 Seek 时数据只读一遍，服务端已有相同文件时在分片上传后秒传完成。大小未知的数据用 UploadFromReader。
+// opts 中 NoResume 不适用，ModTime 非零时记为网盘文件的创建/修改时间
+func (qc *QuarkClient) UploadReader(r io.Reader, size int64, destPath string, opts *UploadOptions, progressCallback func(*UploadProgress)) (*StandardResponse, error) {
+	var o UploadOptions
+	if opts != nil {
+		o = *opts
+	}
+	ctx := o.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if size < 0 {
+		return &StandardResponse{
+			Success: false,
+			Code:    "INVALID_ARGS",
+			Message: fmt.Sprintf("invalid size %d, use UploadFromReader for data of unknown length", size),
+		}, nil
+	}
+	destPath = normalizePath(destPath)
+	if destPath == "" || destPath == "/" || destPath == "." {
+		return &StandardResponse{
+			Success: false,
+			Code:    "INVALID_ARGS",
+			Message: "destination must include a file name when uploading from a stream",
+		}, nil
+	}
+	namedPath, nameErr := uploadNamedDestPath(destPath, o.SanitizeNames)
+	if nameErr != nil {
+		return invalidFileNameResponse(destPath, nameErr), nil
+	}
+
+	result := qc.uploadStream(ctx, r, size, namedPath, progressCallback, &o)
+	if namedPath != destPath {
+		return withSanitizedName(result, nil, destPath, namedPath)
+	}
+	return result, nil
+}
+
+// uploadStream 把 r 中恰好 size 字节的数据按分片边读边上传到 destPath，不保存断点续传状态；r 可 Seek 时先预读哈希尝试秒传
+func (qc *QuarkClient) uploadStream(ctx context.Context, r io.Reader, size int64, destPath string, progressCallback func(*UploadProgress), opts *UploadOptions) *StandardResponse {
+	_, seekable := r.(io.Seeker)
+	src := &uploadSource{r: r, size: size, prehash: seekable}
+	if !opts.NoPreserveMtime && !opts.ModTime.IsZero() {
+		src.createdAt, src.updatedAt = opts.ModTime.UnixMilli(), opts.ModTime.UnixMilli()
+	}
+	return qc.uploadData(ctx, src, destPath, progressCallback, opts)
+}
+
+// peekStream 读取 r 开头最多 MIME_SNIFF_BYTES 字节（不超过 size）用于嗅探 MIME 类型，返回之后应继续读取的 reader：
+// r 可 Seek 时读完回到原位置，返回 r 本身；否则返回把已读数据接在前面的 reader
+func peekStream(r io.Reader, size int64) ([]byte, io.Reader, error) {
+	head := make([]byte, MIME_SNIFF_BYTES)
+	if size < int64(len(head)) {
+		head = head[:size]
+	}
+	if seeker, ok := r.(io.Seeker); ok {
+		start, err := seeker.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return nil, r, err
+		}
+		n, err := io.ReadFull(r, head)
+		if err == nil {
+			_, err = seeker.Seek(start, io.SeekStart)
+		}
+		return head[:n], r, err
+	}
+	n, err := io.ReadFull(r, head)
+	return head[:n], io.MultiReader(bytes.NewReader(head[:n]), r), err
+}
+
+// hashStream 读取 r 中的 size 字节计算 md5/sha1（十六进制），r 可 Seek 时读完后回到读取前的位置；read 为出错前读到的字节数
+func hashStream(r io.Reader, size int64) (md5Sum, sha1Sum string, read int64, err error) {
+	seeker, seekable := r.(io.Seeker)
+	var start int64
+	if seekable {
+		if start, err = seeker.Seek(0, io.SeekCurrent); err != nil {
+			return "", "", 0, err
+		}
+	}
+	hasher := newParallelHasher()
+	read, err = io.CopyN(hasher, r, size)
+	md5Sum, sha1Sum = hasher.Sum()
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return "", "", read, err
+	}
+	if seekable {
+		if _, err = seeker.Seek(start, io.SeekStart); err != nil {
+			return "", "", read, err
+		}
+	}
+	return md5Sum, sha1Sum, read, nil
+}
+
+// streamReadFailed 流式数据读取失败（含提前结束）时的结果
+func streamReadFailed(read, size int64, err error) *StandardResponse {
+	return &StandardResponse{
+		Success: false,
+		Code:    "READ_FILE_ERROR",
+		Message: fmt.Sprintf("failed to read input after %d of %d bytes: %v", read, size, err),
+		Data:    map[string]interface{}{"read": read},
+	}
+}
+
+// streamUploadInterrupted 流式上传被取消时的结果，没有可续传的状态
+func streamUploadInterrupted(read int64) *StandardResponse {
+	return &StandardResponse{
+		Success: false,
+		Code:    "INTERRUPTED",
+		Message: "上传已中断（流式上传无法断点续传，需要重新执行）",
+		Data:    map[string]interface{}{"read": read},
+	}
+}
+
+// streamReader 把流式数据按分片读满（网络读取常常只返回部分数据），最多读 size 字节；
+// 数据不足 size 字节就结束时返回 io.ErrUnexpectedEOF。read 和 err 只在读取方 goroutine 中修改，uploadPartsParallel 返回后可安全读取
+type streamReader struct {
+	r    io.Reader
+	size int64
+	read int64
+	err  error // 读取错误（含提前结束）
+}
+
+func (s *streamReader) Read(p []byte) (int, error) {
+	remaining := s.size - s.read
+	if remaining <= 0 {
+		return 0, io.EOF
+	}
+	if int64(len(p)) > remaining {
+		p = p[:remaining]
+	}
+	n, err := io.ReadFull(s.r, p)
+	s.read += int64(n)
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		s.err = err
+	}
+	return n, err
+}
@@ -0,0 +1,101 @@
+package sdk
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestUploadFromReader(t *testing.T) {
+	isolateUploadState(t)
+	const partSize = 1024
+	content := bytes.Repeat([]byte("stream-data-"), 200) // 3 个分片
+	server := &fakeUploadServer{partSize: partSize}
+	client := server.client(t)
+
+	resp, err := client.UploadFromReader(bytes.NewReader(content), "/dir.tar.gz", nil, nil)
+	if err != nil || !resp.Success {
+		t.Fatalf("UploadFromReader() = %+v, %v", resp, err)
+	}
+	var uploaded []byte
+	for pn := 1; pn <= len(server.parts); pn++ {
+		uploaded = append(uploaded, server.parts[pn]...)
+	}
+	if !bytes.Equal(uploaded, content) {
+		t.Errorf("uploaded %d bytes, want the %d input bytes", len(uploaded), len(content))
+	}
+	entries, _ := os.ReadDir(os.TempDir())
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Name(), "kuake-stdin-") {
+			t.Errorf("temp file %s left after upload", entry.Name())
+		}
+	}
+
+	resp, err = client.UploadFromReader(strings.NewReader("x"), "/", nil, nil)
+	if err != nil || resp.Success || resp.Code != "INVALID_ARGS" {
+		t.Errorf("UploadFromReader() to root = %+v, %v, want INVALID_ARGS", resp, err)
+	}
+}
+
+func TestUploadReader(t *testing.T) {
+	const partSize = 1024
+	content := bytes.Repeat([]byte("reader-data-"), 200) // 3 个分片
+	seekable := func() io.Reader { return bytes.NewReader(content) }
+	oneShot := func() io.Reader { return iotest.HalfReader(bytes.NewReader(content)) }
+
+	tests := []struct {
+		name       string
+		reader     func() io.Reader
+		size       int64
+		opts       *UploadOptions
+		hashFinish bool
+		wantCode   string
+		wantPuts   int
+		wantRapid  bool
+	}{
+		{name: "seekable rapid hit skips parts", reader: seekable, size: int64(len(content)), hashFinish: true, wantCode: "OK", wantPuts: 0, wantRapid: true},
+		{name: "seekable rapid miss uploads parts", reader: seekable, size: int64(len(content)), wantCode: "OK", wantPuts: 3},
+		{name: "rapid check disabled", reader: seekable, size: int64(len(content)), opts: &UploadOptions{NoRapidCheck: true}, hashFinish: true, wantCode: "OK", wantPuts: 3, wantRapid: true},
+		{name: "one-shot reader", reader: oneShot, size: int64(len(content)), wantCode: "OK", wantPuts: 3},
+		{name: "rapid only miss", reader: oneShot, size: int64(len(content)), opts: &UploadOptions{RapidOnly: true}, wantCode: "RAPID_UPLOAD_MISS", wantPuts: 0},
+		{name: "reader ends early", reader: oneShot, size: int64(len(content)) + 1, wantCode: "READ_FILE_ERROR"},
+		{name: "unknown size", reader: oneShot, size: -1, wantCode: "INVALID_ARGS"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := &fakeUploadServer{partSize: partSize, hashFinish: tt.hashFinish}
+			client := server.client(t)
+			resp, err := client.UploadReader(tt.reader(), tt.size, "/data.bin", tt.opts, nil)
+			if err != nil || resp.Code != tt.wantCode {
+				t.Fatalf("UploadReader() = %+v, %v, want %s", resp, err, tt.wantCode)
+			}
+			if tt.wantCode == "READ_FILE_ERROR" || tt.wantCode == "INVALID_ARGS" {
+				if server.committed != "" {
+					t.Errorf("failed upload committed %s", server.committed)
+				}
+				return
+			}
+			if len(server.puts) != tt.wantPuts || server.hashCalls != 1 {
+				t.Errorf("puts = %v, upHash calls = %d, want %d puts and one upHash", server.puts, server.hashCalls, tt.wantPuts)
+			}
+			if !resp.Success {
+				return
+			}
+			if resp.Data["rapid"] != tt.wantRapid || resp.Data["path"] != "/data.bin" {
+				t.Errorf("Data = %v, want rapid %v, path /data.bin", resp.Data, tt.wantRapid)
+			}
+			if tt.wantPuts > 0 && !tt.wantRapid {
+				var uploaded []byte
+				for pn := 1; pn <= tt.wantPuts; pn++ {
+					uploaded = append(uploaded, server.parts[pn]...)
+				}
+				if !bytes.Equal(uploaded, content) {
+					t.Errorf("uploaded %d bytes, want the %d input bytes", len(uploaded), len(content))
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,320 @@
+package sdk
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// isolateUploadState 让断点续传状态文件写到测试的临时目录
+func isolateUploadState(t testing.TB) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", dir)
+	t.Setenv("HOME", dir)
+	t.Setenv("TMPDIR", dir)
+}
+
+// fakeUploadServer 模拟上传相关接口和 OSS，failPart 大于 0 时该分片返回 500
+type fakeUploadServer struct {
+	mu         sync.Mutex
+	partSize   int
+	partThread int // 服务端返回的 part_thread，0 按 1 处理
+	failPart   int
+	hashFinish bool // upHash 返回 finish=true（服务端已有相同文件，秒传）
+	preCalls   int
+	hashCalls  int
+	puts       []int          // 按顺序收到的分片号
+	parts      map[int][]byte // OSS 端已接收的分片
+	committed  string
+}
+
+func (s *fakeUploadServer) client(t testing.TB) *QuarkClient {
+	return createMockClient(t, s.roundTrip(t))
+}
+
+// roundTrip 返回处理上传请求的 roundTripFunc，便于和其它模拟接口组合
+func (s *fakeUploadServer) roundTrip(t testing.TB) roundTripFunc {
+	return func(req *http.Request) (*http.Response, error) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		switch {
+		case strings.HasSuffix(req.URL.Path, FILE_UPLOAD_PRE):
+			s.preCalls++
+			s.parts = make(map[int][]byte)
+			partThread := s.partThread
+			if partThread == 0 {
+				partThread = 1
+			}
+			return jsonResponse(req, fmt.Sprintf(`{"status":200,"code":0,"data":{"task_id":"t%d","bucket":"b","obj_key":"obj","upload_id":"u%d","upload_url":"http://oss.example.com","auth_info":"a","callback":{}},"metadata":{"part_size":%d,"part_thread":%d}}`, s.preCalls, s.preCalls, s.partSize, partThread)), nil
+		case strings.HasSuffix(req.URL.Path, FILE_UPLOAD_AUTH):
+			return jsonResponse(req, `{"status":200,"code":0,"data":{"auth_key":"k"}}`), nil
+		case strings.HasSuffix(req.URL.Path, FILE_UPDATE_HASH):
+			s.hashCalls++
+			return jsonResponse(req, fmt.Sprintf(`{"status":200,"code":0,"data":{"finish":%t}}`, s.hashFinish)), nil
+		case strings.HasSuffix(req.URL.Path, FILE_UPLOAD_FINISH):
+			return jsonResponse(req, `{"status":200,"code":0,"data":{"fid":"new"}}`), nil
+		case req.URL.Host == "b.oss.example.com" && req.Method == "PUT":
+			pn, _ := strconv.Atoi(req.URL.Query().Get("partNumber"))
+			body, _ := io.ReadAll(req.Body)
+			s.puts = append(s.puts, pn)
+			if pn == s.failPart {
+				return &http.Response{StatusCode: 500, Header: make(http.Header), Body: io.NopCloser(strings.NewReader("<Error><Code>InternalError</Code></Error>")), Request: req}, nil
+			}
+			s.parts[pn] = body
+			header := make(http.Header)
+			header.Set("ETag", fmt.Sprintf(`"etag-%d"`, pn))
+			return &http.Response{StatusCode: 200, Header: header, Body: io.NopCloser(strings.NewReader("")), Request: req}, nil
+		case req.URL.Host == "b.oss.example.com" && req.Method == "POST":
+			body, _ := io.ReadAll(req.Body)
+			s.committed = string(body)
+			return &http.Response{StatusCode: 200, Header: make(http.Header), Body: io.NopCloser(strings.NewReader("<CompleteMultipartUploadResult/>")), Request: req}, nil
+		}
+		t.Errorf("unexpected request %s %s", req.Method, req.URL)
+		return jsonResponse(req, `{"status":404,"code":1}`), nil
+	}
+}
+
+func TestUploadFile_Resume(t *testing.T) {
+	isolateUploadState(t)
+
+	const partSize = 1024
+	content := bytes.Repeat([]byte("0123456789abcdef"), 4*partSize/16+3) // 5 个分片
+	localPath := filepath.Join(t.TempDir(), "big.bin")
+	if err := os.WriteFile(localPath, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+	server := &fakeUploadServer{partSize: partSize, failPart: 3}
+	client := server.client(t)
+
+	// 第一次上传在分片 3 失败，保留状态文件
+	resp, err := client.UploadFile(localPath, "/big.bin", nil, nil)
+	if err != nil || resp.Success {
+		t.Fatalf("first UploadFile() = %+v, %v, want failure at part 3", resp, err)
+	}
+	statePath := client.uploadStatePath(localPath, "/big.bin")
+	if _, err := os.Stat(statePath); err != nil {
+		t.Fatalf("upload state not kept after failure: %v", err)
+	}
+
+	// 重跑时不再预上传，从分片 3 继续
+	server.failPart = 0
+	server.puts = nil
+	resp, err = client.UploadFile(localPath, "/big.bin", nil, nil)
+	if err != nil || !resp.Success {
+		t.Fatalf("resumed UploadFile() = %+v, %v", resp, err)
+	}
+	if server.preCalls != 1 {
+		t.Errorf("pre-upload called %d times, want 1 (resume the saved session)", server.preCalls)
+	}
+	if fmt.Sprint(server.puts) != "[3 4 5]" {
+		t.Errorf("resumed upload sent parts %v, want [3 4 5]", server.puts)
+	}
+	if resp.Data["resumed_parts"] != 2 {
+		t.Errorf("resumed_parts = %v, want 2", resp.Data["resumed_parts"])
+	}
+	var uploaded []byte
+	for pn := 1; pn <= 5; pn++ {
+		uploaded = append(uploaded, server.parts[pn]...)
+	}
+	if !bytes.Equal(uploaded, content) || !strings.Contains(server.committed, "<PartNumber>5</PartNumber>") {
+		t.Errorf("uploaded %d bytes, commit %s", len(uploaded), server.committed)
+	}
+	if _, err := os.Stat(statePath); !os.IsNotExist(err) {
+		t.Errorf("upload state not removed after success: %v", err)
+	}
+}
+
+func TestUploadFile_ResumeDiscarded(t *testing.T) {
+	const partSize = 1024
+	content := bytes.Repeat([]byte("x"), 3*partSize)
+
+	tests := []struct {
+		name   string
+		modify func(t *testing.T, localPath string)
+		opts   *UploadOptions
+	}{
+		{
+			name: "local file modified",
+			modify: func(t *testing.T, localPath string) {
+				later := time.Now().Add(time.Hour)
+				if err := os.Chtimes(localPath, later, later); err != nil {
+					t.Fatal(err)
+				}
+			},
+		},
+		{name: "no resume", modify: func(t *testing.T, localPath string) {}, opts: &UploadOptions{NoResume: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			isolateUploadState(t)
+			localPath := filepath.Join(t.TempDir(), "big.bin")
+			if err := os.WriteFile(localPath, content, 0644); err != nil {
+				t.Fatal(err)
+			}
+			server := &fakeUploadServer{partSize: partSize, failPart: 2}
+			client := server.client(t)
+			if resp, _ := client.UploadFile(localPath, "/big.bin", nil, nil); resp.Success {
+				t.Fatal("first UploadFile() succeeded, want failure at part 2")
+			}
+
+			tt.modify(t, localPath)
+			server.failPart = 0
+			server.puts = nil
+			resp, err := client.UploadFile(localPath, "/big.bin", nil, tt.opts)
+			if err != nil || !resp.Success {
+				t.Fatalf("UploadFile() = %+v, %v", resp, err)
+			}
+			if server.preCalls != 2 || fmt.Sprint(server.puts) != "[1 2 3]" {
+				t.Errorf("pre-upload calls = %d, parts sent %v, want a fresh upload of [1 2 3]", server.preCalls, server.puts)
+			}
+		})
+	}
+}
+
+// countingUploadFile 统计 UploadFile 从本地文件读取的字节数
+type countingUploadFile struct {
+	uploadLocalFile
+	read *int64
+}
+
+func (f countingUploadFile) Read(p []byte) (int, error) {
+	n, err := f.uploadLocalFile.Read(p)
+	*f.read += int64(n)
+	return n, err
+}
+
+// countUploadReads 替换 openUploadFile，返回测试期间 UploadFile 从本地文件读取的总字节数
+func countUploadReads(t *testing.T) *int64 {
+	read := new(int64)
+	saved := openUploadFile
+	t.Cleanup(func() { openUploadFile = saved })
+	openUploadFile = func(name string) (uploadLocalFile, error) {
+		file, err := saved(name)
+		if err != nil {
+			return nil, err
+		}
+		return countingUploadFile{uploadLocalFile: file, read: read}, nil
+	}
+	return read
+}
+
+// TestUploadFile_SinglePass 分片读取时同时计算 md5/sha1，上传只读一遍本地文件
+func TestUploadFile_SinglePass(t *testing.T) {
+	const partSize = 256 * 1024
+	content := bytes.Repeat([]byte("0123456789abcdef"), 4*1024*1024/16) // 4MB，16 个分片
+	localPath := filepath.Join(t.TempDir(), "big.bin")
+	if err := os.WriteFile(localPath, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, partThread := range []int{1, 3} {
+		t.Run(fmt.Sprintf("part_thread %d", partThread), func(t *testing.T) {
+			isolateUploadState(t)
+			server := &fakeUploadServer{partSize: partSize, partThread: partThread}
+			client := server.client(t)
+			read := countUploadReads(t)
+
+			resp, err := client.UploadFile(localPath, "/big.bin", nil, nil)
+			if err != nil || !resp.Success {
+				t.Fatalf("UploadFile() = %+v, %v", resp, err)
+			}
+			if *read != int64(len(content)) {
+				t.Errorf("UploadFile() read %d bytes for a %d byte file, want a single pass", *read, len(content))
+			}
+		})
+	}
+}
+
+// TestUploadFile_RapidOnly 只尝试秒传：命中时完成上传，未命中时返回 RAPID_UPLOAD_MISS，两种情况都不上传分片
+func TestUploadFile_RapidOnly(t *testing.T) {
+	const partSize = 1024
+	content := bytes.Repeat([]byte("0123456789abcdef"), 2*partSize/16+5) // 3 个分片
+	localPath := filepath.Join(t.TempDir(), "big.bin")
+	if err := os.WriteFile(localPath, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		hashFinish bool
+		wantCode   string
+		wantRapid  bool
+	}{
+		{name: "hit", hashFinish: true, wantCode: "OK", wantRapid: true},
+		{name: "miss", hashFinish: false, wantCode: "RAPID_UPLOAD_MISS", wantRapid: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			isolateUploadState(t)
+			server := &fakeUploadServer{partSize: partSize, hashFinish: tt.hashFinish}
+			client := server.client(t)
+
+			resp, err := client.UploadFile(localPath, "/big.bin", nil, &UploadOptions{RapidOnly: true})
+			if err != nil {
+				t.Fatalf("UploadFile() error = %v", err)
+			}
+			if resp.Success != tt.hashFinish || resp.Code != tt.wantCode {
+				t.Fatalf("UploadFile() = %+v, want success %v with code %s", resp, tt.hashFinish, tt.wantCode)
+			}
+			if rapid, _ := resp.Data["rapid"].(bool); rapid != tt.wantRapid {
+				t.Errorf("Data[rapid] = %v, want %v", resp.Data["rapid"], tt.wantRapid)
+			}
+			if len(server.puts) != 0 || server.committed != "" {
+				t.Errorf("uploaded parts %v (commit %q), want none", server.puts, server.committed)
+			}
+			statePath := client.uploadStatePath(localPath, "/big.bin")
+			if _, err := os.Stat(statePath); !os.IsNotExist(err) {
+				t.Errorf("upload state %s exists after rapid-only upload", statePath)
+			}
+		})
+	}
+}
+
+func TestUploadByHash(t *testing.T) {
+	const md5Hex = "d41d8cd98f00b204e9800998ecf8427e"
+	const sha1Hex = "da39a3ee5e6b4b0d3255bfef95601890afd80709"
+	tests := []struct {
+		name       string
+		md5        string
+		sha1       string
+		hashFinish bool
+		wantCode   string
+		wantPre    int
+	}{
+		{name: "hit", md5: md5Hex, sha1: sha1Hex, hashFinish: true, wantCode: "OK", wantPre: 1},
+		{name: "hit with upper case hashes", md5: strings.ToUpper(md5Hex), sha1: strings.ToUpper(sha1Hex), hashFinish: true, wantCode: "OK", wantPre: 1},
+		{name: "miss", md5: md5Hex, sha1: sha1Hex, wantCode: "RAPID_UPLOAD_MISS", wantPre: 1},
+		{name: "invalid md5", md5: "xyz", sha1: sha1Hex, wantCode: "INVALID_ARGS"},
+		{name: "sha1 passed as md5", md5: sha1Hex, sha1: sha1Hex, wantCode: "INVALID_ARGS"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := &fakeUploadServer{partSize: 1024, hashFinish: tt.hashFinish}
+			client := server.client(t)
+
+			resp, err := client.UploadByHash("file.bin", "/", 1234, tt.md5, tt.sha1, nil)
+			if err != nil {
+				t.Fatalf("UploadByHash() error = %v", err)
+			}
+			if resp.Code != tt.wantCode || resp.Success != (tt.wantCode == "OK") {
+				t.Fatalf("UploadByHash() = %+v, want code %s", resp, tt.wantCode)
+			}
+			if server.preCalls != tt.wantPre || len(server.puts) != 0 {
+				t.Errorf("pre-upload called %d times, parts %v, want %d calls and no parts", server.preCalls, server.puts, tt.wantPre)
+			}
+			if tt.wantPre > 0 {
+				if rapid, _ := resp.Data["rapid"].(bool); rapid != tt.hashFinish || resp.Data["path"] != "/file.bin" {
+					t.Errorf("Data = %v, want rapid %v and path /file.bin", resp.Data, tt.hashFinish)
+				}
+			}
+		})
+	}
+}
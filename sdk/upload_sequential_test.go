@@ -0,0 +1,166 @@
+package sdk
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// TestUploadFile_PartNotSequential bucket 要求顺序上传时，并行上传收到 PartNotSequential 后退回串行并完成上传
+func TestUploadFile_PartNotSequential(t *testing.T) {
+	isolateUploadState(t)
+
+	const partSize = 1024
+	content := bytes.Repeat([]byte("0123456789abcdef"), 5*partSize/16+10) // 6 个分片
+	localPath := filepath.Join(t.TempDir(), "big.bin")
+	if err := os.WriteFile(localPath, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var mu sync.Mutex
+	parts := make(map[int][]byte) // OSS 端已接收的分片
+	var rejected int
+	var committed string
+	client := createMockClient(t, func(req *http.Request) (*http.Response, error) {
+		switch {
+		case strings.HasSuffix(req.URL.Path, FILE_UPLOAD_PRE):
+			return jsonResponse(req, fmt.Sprintf(`{"status":200,"code":0,"data":{"task_id":"t1","bucket":"b","obj_key":"obj","upload_id":"u1","upload_url":"http://oss.example.com","auth_info":"a","callback":{}},"metadata":{"part_size":%d,"part_thread":3}}`, partSize)), nil
+		case strings.HasSuffix(req.URL.Path, FILE_UPLOAD_AUTH):
+			return jsonResponse(req, `{"status":200,"code":0,"data":{"auth_key":"k"}}`), nil
+		case strings.HasSuffix(req.URL.Path, FILE_UPDATE_HASH):
+			return jsonResponse(req, `{"status":200,"code":0,"data":{"finish":false}}`), nil
+		case strings.HasSuffix(req.URL.Path, FILE_UPLOAD_FINISH):
+			return jsonResponse(req, `{"status":200,"code":0,"data":{"fid":"new"}}`), nil
+		case req.URL.Host == "b.oss.example.com" && req.Method == "PUT":
+			pn, _ := strconv.Atoi(req.URL.Query().Get("partNumber"))
+			body, _ := io.ReadAll(req.Body)
+			mu.Lock()
+			defer mu.Unlock()
+			// 只接受下一个分片；第一次收到分片 2 时总是拒绝，保证并行上传一定会遇到 PartNotSequential
+			if _, ok := parts[pn-1]; pn > 1 && (!ok || rejected == 0) {
+				rejected++
+				return &http.Response{
+					StatusCode: 400,
+					Header:     make(http.Header),
+					Body:       io.NopCloser(strings.NewReader(`<Error><Code>PartNotSequential</Code><Message>part not sequential</Message></Error>`)),
+					Request:    req,
+				}, nil
+			}
+			parts[pn] = body
+			header := make(http.Header)
+			header.Set("ETag", fmt.Sprintf(`"etag-%d"`, pn))
+			return &http.Response{StatusCode: 200, Header: header, Body: io.NopCloser(strings.NewReader("")), Request: req}, nil
+		case req.URL.Host == "b.oss.example.com" && req.Method == "POST":
+			body, _ := io.ReadAll(req.Body)
+			committed = string(body)
+			return &http.Response{StatusCode: 200, Header: make(http.Header), Body: io.NopCloser(strings.NewReader("<CompleteMultipartUploadResult/>")), Request: req}, nil
+		}
+		t.Errorf("unexpected request %s %s", req.Method, req.URL)
+		return jsonResponse(req, `{"status":404,"code":1}`), nil
+	})
+
+	resp, err := client.UploadFile(localPath, "/big.bin", nil, nil)
+	if err != nil || !resp.Success {
+		t.Fatalf("UploadFile() = %+v, %v", resp, err)
+	}
+	if rejected == 0 {
+		t.Fatal("no part was rejected, parallel upload was not exercised")
+	}
+	var uploaded []byte
+	for pn := 1; pn <= len(parts); pn++ {
+		uploaded = append(uploaded, parts[pn]...)
+	}
+	if len(parts) != 6 || !bytes.Equal(uploaded, content) {
+		t.Errorf("uploaded %d parts, %d bytes, want 6 parts with the file content", len(parts), len(uploaded))
+	}
+	for pn := 1; pn <= 6; pn++ {
+		want := fmt.Sprintf("<Part><PartNumber>%d</PartNumber><ETag>\"etag-%d\"</ETag></Part>", pn, pn)
+		if !strings.Contains(committed, want) {
+			t.Errorf("commit body missing %s: %s", want, committed)
+		}
+	}
+}
+
+func TestSequentialUploadedPrefix(t *testing.T) {
+	tests := []struct {
+		name      string
+		parts     map[int]string
+		wantEtags []string
+		wantNext  int
+	}{
+		{name: "none", parts: map[int]string{}, wantEtags: []string{}, wantNext: 1},
+		{name: "gap at first part", parts: map[int]string{2: "b", 3: "c"}, wantEtags: []string{}, wantNext: 1},
+		{name: "prefix then gap", parts: map[int]string{1: "a", 2: "b", 4: "d"}, wantEtags: []string{"a", "b"}, wantNext: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			etags, next := sequentialUploadedPrefix(tt.parts)
+			if next != tt.wantNext || strings.Join(etags, ",") != strings.Join(tt.wantEtags, ",") {
+				t.Errorf("sequentialUploadedPrefix() = %v, %d, want %v, %d", etags, next, tt.wantEtags, tt.wantNext)
+			}
+		})
+	}
+}
+
+func TestUploadPartSizeFor(t *testing.T) {
+	const mb = 1024 * 1024
+	tests := []struct {
+		name     string
+		partSize int64
+		fileSize int64
+		want     int64
+	}{
+		{name: "small file", partSize: 4 * mb, fileSize: 100 * mb, want: 4 * mb},
+		{name: "exactly max parts", partSize: 4 * mb, fileSize: OSS_MAX_PARTS * 4 * mb, want: 4 * mb},
+		{name: "one byte over max parts", partSize: 4 * mb, fileSize: OSS_MAX_PARTS*4*mb + 1, want: 8 * mb},
+		{name: "max parts plus one part", partSize: 4 * mb, fileSize: (OSS_MAX_PARTS + 1) * 4 * mb, want: 8 * mb},
+		{name: "needs several doublings", partSize: 4 * mb, fileSize: OSS_MAX_PARTS * 20 * mb, want: 32 * mb},
+		{name: "unknown part size", partSize: 0, fileSize: 100 * mb, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := uploadPartSizeFor(tt.partSize, tt.fileSize)
+			if got != tt.want {
+				t.Errorf("uploadPartSizeFor(%d, %d) = %d, want %d", tt.partSize, tt.fileSize, got, tt.want)
+			}
+			if got > 0 && (tt.fileSize+got-1)/got > OSS_MAX_PARTS {
+				t.Errorf("part size %d gives %d parts, more than %d", got, (tt.fileSize+got-1)/got, OSS_MAX_PARTS)
+			}
+		})
+	}
+}
+
+func TestUploadParallelFor(t *testing.T) {
+	tests := []struct {
+		name       string
+		parallel   int
+		env        string
+		partThread int
+		totalParts int
+		want       int
+	}{
+		{name: "server part_thread", partThread: 3, totalParts: 10, want: 3},
+		{name: "option overrides part_thread", parallel: 8, partThread: 3, totalParts: 10, want: 8},
+		{name: "env as fallback", env: "5", partThread: 3, totalParts: 10, want: 5},
+		{name: "option beats env", parallel: 2, env: "5", partThread: 3, totalParts: 10, want: 2},
+		{name: "invalid env ignored", env: "99", partThread: 3, totalParts: 10, want: 3},
+		{name: "sequential bucket", parallel: 8, partThread: 1, totalParts: 10, want: 1},
+		{name: "no part_thread", partThread: 0, totalParts: 10, want: 1},
+		{name: "capped by parts", parallel: 8, partThread: 3, totalParts: 2, want: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(UPLOAD_PARALLEL_ENV, tt.env)
+			if got := uploadParallelFor(tt.parallel, tt.partThread, tt.totalParts); got != tt.want {
+				t.Errorf("uploadParallelFor(%d, %d, %d) = %d, want %d", tt.parallel, tt.partThread, tt.totalParts, got, tt.want)
+			}
+		})
+	}
+}
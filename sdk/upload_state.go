@@ -0,0 +1,69 @@
+package sdk
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ListUploadStates 列出本地保存的断点续传状态（未完成的上传），按最后保存时间（UploadState.CreatedAt）从旧到新排序
+// 无法解析的状态文件跳过；状态目录不存在时返回空列表
+func (qc *QuarkClient) ListUploadStates() ([]UploadStateEntry, error) {
+	dir := qc.UploadStateDir()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return []UploadStateEntry{}, nil
+		}
+		return nil, fmt.Errorf("read upload state dir: %w", err)
+	}
+	states := make([]UploadStateEntry, 0, len(entries))
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		path := filepath.Join(dir, name)
+		state, err := loadUploadState(path)
+		if err != nil {
+			continue
+		}
+		states = append(states, UploadStateEntry{ID: strings.TrimSuffix(name, ".json"), Path: path, State: state})
+	}
+	sort.Slice(states, func(i, j int) bool {
+		return states[i].State.CreatedAt.Before(states[j].State.CreatedAt)
+	})
+	return states, nil
+}
+
+// PurgeUploadStates 删除最后保存时间早于 before 的断点续传状态文件，返回已删除的状态
+// 只清理本地文件，不取消 OSS 端的分片上传（需要时用 AbortUpload）；删除失败时返回已删除的部分和错误
+func (qc *QuarkClient) PurgeUploadStates(before time.Time) ([]UploadStateEntry, error) {
+	states, err := qc.ListUploadStates()
+	if err != nil {
+		return nil, err
+	}
+	purged := make([]UploadStateEntry, 0)
+	for _, entry := range states {
+		if !entry.State.CreatedAt.Before(before) {
+			continue
+		}
+		if err := deleteUploadState(entry.Path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return purged, fmt.Errorf("delete upload state %s: %w", entry.ID, err)
+		}
+		purged = append(purged, entry)
+	}
+	return purged, nil
+}
+
+// TotalParts 按文件大小和分片大小计算的分片总数，分片大小未知时返回 0
+func (s *UploadState) TotalParts() int {
+	if s.PartSize <= 0 {
+		return 0
+	}
+	return int((s.FileSize + s.PartSize - 1) / s.PartSize)
+}
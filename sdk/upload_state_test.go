@@ -0,0 +1,82 @@
+package sdk
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestUploadStateDir_Config(t *testing.T) {
+	isolateUploadState(t)
+	dir := filepath.Join(t.TempDir(), "states")
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(configPath, []byte(`{"transfer":{"upload_state_dir":"`+filepath.ToSlash(dir)+`"}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	client := NewQuarkClient(configPath, "__pus=test;")
+	if got := client.UploadStateDir(); got != filepath.ToSlash(dir) {
+		t.Fatalf("UploadStateDir() = %s, want %s", got, dir)
+	}
+	if statePath := client.uploadStatePath("/data/a.bin", "/a.bin"); filepath.Dir(statePath) != filepath.ToSlash(dir) {
+		t.Errorf("uploadStatePath() = %s, want a file in %s", statePath, dir)
+	}
+
+	info, err := os.Stat(dir)
+	if err != nil {
+		t.Fatalf("state dir not created: %v", err)
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm() != 0700 {
+		t.Errorf("state dir mode = %o, want 700", info.Mode().Perm())
+	}
+
+	// 环境变量优先于配置文件
+	envDir := filepath.Join(t.TempDir(), "env-states")
+	t.Setenv(UPLOAD_STATE_ENV, envDir)
+	if got := NewQuarkClient(configPath, "__pus=test;").UploadStateDir(); got != envDir {
+		t.Errorf("UploadStateDir() with %s = %s, want %s", UPLOAD_STATE_ENV, got, envDir)
+	}
+
+	client.SetUploadStateDir("")
+	if got := client.UploadStateDir(); got == filepath.ToSlash(dir) {
+		t.Errorf("UploadStateDir() after reset = %s, want the default directory", got)
+	}
+}
+
+func TestPurgeUploadStates(t *testing.T) {
+	client := createMockClient(t, nil)
+	client.SetUploadStateDir(t.TempDir())
+
+	old := &UploadState{FilePath: "/data/old.bin", DestPath: "/old.bin", UploadID: "u1", FileSize: 10 << 20, PartSize: 4 << 20, UploadedParts: map[int]string{1: "a"}}
+	fresh := &UploadState{FilePath: "/data/new.bin", DestPath: "/new.bin", UploadID: "u2"}
+	oldPath := client.uploadStatePath(old.FilePath, old.DestPath)
+	freshPath := client.uploadStatePath(fresh.FilePath, fresh.DestPath)
+	if err := saveUploadState(freshPath, fresh); err != nil {
+		t.Fatal(err)
+	}
+	// saveUploadState 总是记录当前时间，旧状态直接写文件
+	old.CreatedAt = time.Now().Add(-10 * 24 * time.Hour)
+	data, _ := json.Marshal(old)
+	if err := os.WriteFile(oldPath, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if old.TotalParts() != 3 || fresh.TotalParts() != 0 {
+		t.Errorf("TotalParts() = %d, %d, want 3, 0", old.TotalParts(), fresh.TotalParts())
+	}
+
+	purged, err := client.PurgeUploadStates(time.Now().Add(-7 * 24 * time.Hour))
+	if err != nil {
+		t.Fatalf("PurgeUploadStates() error = %v", err)
+	}
+	if len(purged) != 1 || purged[0].State.UploadID != "u1" {
+		t.Errorf("PurgeUploadStates() = %+v, want only the old state", purged)
+	}
+	if _, err := os.Stat(oldPath); !os.IsNotExist(err) {
+		t.Errorf("old state file still exists: %v", err)
+	}
+	if _, err := os.Stat(freshPath); err != nil {
+		t.Errorf("fresh state file removed: %v", err)
+	}
+}
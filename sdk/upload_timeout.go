@@ -0,0 +1,171 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// errUploadStalled 分片上传超过传输超时，且最近 UPLOAD_STALL_TIMEOUT 内没有进展，请求已取消（可重试）
+var errUploadStalled = errors.New("upload part stalled")
+
+// errUploadTooSlow 分片上传在一个统计窗口内的平均速度低于 transfer.upload_min_speed，请求已取消（可重试）
+var errUploadTooSlow = errors.New("upload part too slow")
+
+// uploadTransport 分片上传共用的 Transport：只限制建立连接和 TLS 握手的时间，传输时间由 transferWatchdog 控制
+var uploadTransport = &http.Transport{
+	Proxy: http.ProxyFromEnvironment,
+	DialContext: (&net.Dialer{
+		Timeout:   UPLOAD_CONNECT_TIMEOUT,
+		KeepAlive: 30 * time.Second,
+	}).DialContext,
+	TLSHandshakeTimeout: UPLOAD_CONNECT_TIMEOUT,
+	MaxIdleConnsPerHost: MAX_UPLOAD_PARALLEL,
+	IdleConnTimeout:     90 * time.Second,
+}
+
+// uploadHTTPClient 返回分片上传使用的客户端：不设置 Client.Timeout（主客户端的 30 秒会在大分片传输中途掐断请求）
+// HttpClient 设置了自定义 Transport（如代理、测试桩）时沿用该 Transport
+func (qc *QuarkClient) uploadHTTPClient() *http.Client {
+	transport := qc.HttpClient.Transport
+	if transport == nil {
+		transport = uploadTransport
+	}
+	return &http.Client{Transport: transport, Jar: qc.HttpClient.Jar, CheckRedirect: qc.HttpClient.CheckRedirect}
+}
+
+// uploadPartTimeoutFor 返回上传 size 字节的分片的传输超时：配置了 transfer.upload_part_timeout 时使用配置值，
+// 否则按 UPLOAD_PART_MIN_SPEED 估算传输时间再加上 UPLOAD_PART_TIMEOUT_MARGIN
+func (qc *QuarkClient) uploadPartTimeoutFor(size int64) time.Duration {
+	if qc.uploadPartTimeout > 0 {
+		return qc.uploadPartTimeout
+	}
+	return time.Duration(size)*time.Second/UPLOAD_PART_MIN_SPEED + UPLOAD_PART_TIMEOUT_MARGIN
+}
+
+// transferWatchdog 传输超时：到达 timeout 时若最近 stall 内仍有进展（发送了请求体数据）则继续等待，
+// 直到连续 stall 没有进展才调用 cancel 取消请求；传输中只要还在推进就不会被掐断
+type transferWatchdog struct {
+	cancel   context.CancelFunc
+	stall    time.Duration
+	done     chan struct{}
+	last     atomic.Int64 // 最近一次进展的时间（UnixNano）
+	sent     atomic.Int64 // 已发送的请求体字节数
+	timedOut atomic.Bool
+	tooSlow  atomic.Bool
+}
+
+// startTransferWatchdog 开始计时，结束时必须调用 stop
+func startTransferWatchdog(cancel context.CancelFunc, timeout, stall time.Duration) *transferWatchdog {
+	w := &transferWatchdog{cancel: cancel, stall: stall, done: make(chan struct{})}
+	w.touch()
+	go w.run(timeout)
+	return w
+}
+
+// run 到期后检查：最近 stall 内有进展时推迟到 stall 期满再检查，否则取消请求
+func (w *transferWatchdog) run(wait time.Duration) {
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-timer.C:
+		}
+		idle := time.Since(time.Unix(0, w.last.Load()))
+		if idle < w.stall {
+			timer.Reset(w.stall - idle)
+			continue
+		}
+		w.timedOut.Store(true)
+		w.cancel()
+		return
+	}
+}
+
+// watchSpeed 启用低速检测：从发出第一个字节起按 window 分段统计，某一段的平均速度低于 minSpeed（字节/秒）时取消请求
+// 第一段从发出第一个字节开始计时，建立连接的时间不计入；total 字节的请求体全部发出后（等待服务端响应）不再检测
+func (w *transferWatchdog) watchSpeed(minSpeed int64, window time.Duration, total int64) {
+	if minSpeed <= 0 || window <= 0 {
+		return
+	}
+	tick := window / 10
+	if tick <= 0 {
+		tick = window
+	}
+	go func() {
+		ticker := time.NewTicker(tick)
+		defer ticker.Stop()
+		var start time.Time
+		var startSent int64
+		for {
+			select {
+			case <-w.done:
+				return
+			case now := <-ticker.C:
+				sent := w.sent.Load()
+				if sent == 0 || sent >= total {
+					continue
+				}
+				if start.IsZero() {
+					start, startSent = now, sent
+					continue
+				}
+				elapsed := now.Sub(start)
+				if elapsed < window {
+					continue
+				}
+				if float64(sent-startSent)/elapsed.Seconds() < float64(minSpeed) {
+					w.tooSlow.Store(true)
+					w.cancel()
+					return
+				}
+				start, startSent = now, sent
+			}
+		}
+	}()
+}
+
+// touch 记录一次进展
+func (w *transferWatchdog) touch() {
+	w.last.Store(time.Now().UnixNano())
+}
+
+// stop 停止计时
+func (w *transferWatchdog) stop() {
+	close(w.done)
+}
+
+// expired 请求是否因传输超时被取消
+func (w *transferWatchdog) expired() bool {
+	return w.timedOut.Load()
+}
+
+// slow 请求是否因低于最低速度被取消
+func (w *transferWatchdog) slow() bool {
+	return w.tooSlow.Load()
+}
+
+// reader 包装请求体，每次读出数据时记录进展
+func (w *transferWatchdog) reader(r io.Reader) io.Reader {
+	return &watchdogReader{r: r, w: w}
+}
+
+type watchdogReader struct {
+	r io.Reader
+	w *transferWatchdog
+}
+
+func (r *watchdogReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if n > 0 {
+		r.w.sent.Add(int64(n))
+		r.w.touch()
+	}
+	return n, err
+}
@@ -0,0 +1,123 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestUploadPartTimeoutFor(t *testing.T) {
+	client := &QuarkClient{}
+	if got, want := client.uploadPartTimeoutFor(10*1024*1024), 160*time.Second+UPLOAD_PART_TIMEOUT_MARGIN; got != want {
+		t.Errorf("uploadPartTimeoutFor(10MB) = %s, want %s", got, want)
+	}
+	if got := client.uploadPartTimeoutFor(0); got != UPLOAD_PART_TIMEOUT_MARGIN {
+		t.Errorf("uploadPartTimeoutFor(0) = %s, want %s", got, UPLOAD_PART_TIMEOUT_MARGIN)
+	}
+	client.SetUploadPartTimeout(10 * time.Minute)
+	if got := client.uploadPartTimeoutFor(10 * 1024 * 1024); got != 10*time.Minute {
+		t.Errorf("uploadPartTimeoutFor() with configured timeout = %s, want 10m", got)
+	}
+}
+
+func TestTransferWatchdog(t *testing.T) {
+	t.Run("keeps running while making progress", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		w := startTransferWatchdog(cancel, 20*time.Millisecond, 50*time.Millisecond)
+		defer w.stop()
+		r := w.reader(strings.NewReader(strings.Repeat("x", 20)))
+		buf := make([]byte, 1)
+		// 超过 timeout 数倍的时间内持续有进展
+		for {
+			if _, err := r.Read(buf); err == io.EOF {
+				break
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		if ctx.Err() != nil || w.expired() {
+			t.Fatal("watchdog cancelled a transfer that was still making progress")
+		}
+	})
+
+	t.Run("cancels after stalling", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		w := startTransferWatchdog(cancel, 10*time.Millisecond, 20*time.Millisecond)
+		defer w.stop()
+		select {
+		case <-ctx.Done():
+		case <-time.After(time.Second):
+			t.Fatal("watchdog did not cancel a stalled transfer")
+		}
+		if !w.expired() {
+			t.Error("expired() = false after cancelling")
+		}
+	})
+}
+
+func TestTransferWatchdog_MinSpeed(t *testing.T) {
+	// readSlowly 每 interval 读 1 字节，持续 d
+	readSlowly := func(r io.Reader, interval, d time.Duration) {
+		buf := make([]byte, 1)
+		for deadline := time.Now().Add(d); time.Now().Before(deadline); {
+			r.Read(buf)
+			time.Sleep(interval)
+		}
+	}
+
+	t.Run("cancels a slow transfer", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		w := startTransferWatchdog(cancel, time.Hour, time.Hour)
+		defer w.stop()
+		w.watchSpeed(1000, 50*time.Millisecond, 1000)
+		readSlowly(w.reader(strings.NewReader(strings.Repeat("x", 1000))), 10*time.Millisecond, 300*time.Millisecond)
+		if ctx.Err() == nil || !w.slow() {
+			t.Fatal("watchdog did not cancel a transfer below the minimum speed")
+		}
+		if w.expired() {
+			t.Error("expired() = true for a transfer cancelled for being slow")
+		}
+	})
+
+	t.Run("grace period before the first byte", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		w := startTransferWatchdog(cancel, time.Hour, time.Hour)
+		defer w.stop()
+		w.watchSpeed(1000, 50*time.Millisecond, 1000)
+		time.Sleep(200 * time.Millisecond) // 建立连接阶段没有发送数据
+		if ctx.Err() != nil || w.slow() {
+			t.Fatal("watchdog cancelled a transfer before it sent any data")
+		}
+	})
+
+	t.Run("fast enough", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		w := startTransferWatchdog(cancel, time.Hour, time.Hour)
+		defer w.stop()
+		w.watchSpeed(10, 50*time.Millisecond, 1000)
+		readSlowly(w.reader(strings.NewReader(strings.Repeat("x", 1000))), 10*time.Millisecond, 300*time.Millisecond)
+		if ctx.Err() != nil || w.slow() {
+			t.Fatal("watchdog cancelled a transfer above the minimum speed")
+		}
+	})
+}
+
+func TestIsRetryableError_UploadStalled(t *testing.T) {
+	for _, sentinel := range []error{errUploadStalled, errUploadTooSlow} {
+		err := fmt.Errorf("failed to upload chunk: %w (part 3)", sentinel)
+		if !isRetryableError(err) {
+			t.Errorf("isRetryableError(%v) = false, want true", err)
+		}
+	}
+	if isRetryableError(errors.New("upload chunk failed with status 403")) {
+		t.Error("isRetryableError(403) = true, want false")
+	}
+}
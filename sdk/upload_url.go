@@ -0,0 +1,176 @@
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"sync/atomic"
+	"time"
+)
+
+// UploadFromURL 从 http/https 直链拉取数据并上传到 destPath（为根目录时文件名取响应的 Content-Disposition 或 URL 路径的最后一段），
+// 结果与 UploadFile 一致，Data 另带 source_url、fetched（从源站拉取的字节数）和 streamed；
+// 源站响应带 Content-Length 时边拉取边上传，内存中只保留在途的分片，不写本地文件（streamed 为 true）；
+// 长度未知（如 chunked 响应）时先写入 TMPDIR 下的临时文件再上传，同 UploadFromReader；流式上传见 UploadReader。
+// 流式上传无法断点续传，中断或失败后需重新拉取；progressCallback 的 Fetched 为已拉取的字节数
+func (qc *QuarkClient) UploadFromURL(sourceURL, destPath string, progressCallback func(*UploadProgress), opts *UploadOptions) (*StandardResponse, error) {
+	var o UploadOptions
+	if opts != nil {
+		o = *opts
+	}
+	ctx := o.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	u, err := url.Parse(stripQuotes(sourceURL))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return &StandardResponse{
+			Success: false,
+			Code:    "INVALID_ARGS",
+			Message: fmt.Sprintf("invalid source URL %q, must be an http or https URL", sourceURL),
+		}, nil
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
+	if err != nil {
+		return &StandardResponse{
+			Success: false,
+			Code:    "FETCH_ERROR",
+			Message: fmt.Sprintf("failed to create request: %v", err),
+		}, nil
+	}
+	// 源站与网盘无关：不带登录 cookie，复用主客户端的 Transport，不设整体超时（由 ctx 取消）
+	resp, err := (&http.Client{Transport: qc.HttpClient.Transport}).Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return urlUploadInterrupted(0), nil
+		}
+		return &StandardResponse{
+			Success: false,
+			Code:    "FETCH_ERROR",
+			Message: fmt.Sprintf("failed to fetch %s: %v", u.Redacted(), err),
+		}, nil
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return &StandardResponse{
+			Success: false,
+			Code:    "FETCH_ERROR",
+			Message: fmt.Sprintf("failed to fetch %s: HTTP %d", u.Redacted(), resp.StatusCode),
+			Data:    map[string]interface{}{"status": resp.StatusCode},
+		}, nil
+	}
+
+	destPath = uploadDestPath(destPath, urlFileName(resp))
+	if base := path.Base(destPath); base == "/" || base == "." || base == "" {
+		return &StandardResponse{
+			Success: false,
+			Code:    "INVALID_ARGS",
+			Message: "cannot tell the file name from the URL, destination must include a file name",
+		}, nil
+	}
+	namedPath, nameErr := uploadNamedDestPath(destPath, o.SanitizeNames)
+	if nameErr != nil {
+		return invalidFileNameResponse(destPath, nameErr), nil
+	}
+
+	startTime := time.Now()
+	fetch := &fetchReader{r: resp.Body}
+	var report func(*UploadProgress)
+	if progressCallback != nil {
+		report = func(p *UploadProgress) {
+			p.Fetched = fetch.n.Load()
+			progressCallback(p)
+		}
+	}
+
+	var result *StandardResponse
+	streamed := resp.ContentLength >= 0
+	if streamed {
+		if lastModified, parseErr := http.ParseTime(resp.Header.Get("Last-Modified")); parseErr == nil && o.ModTime.IsZero() {
+			o.ModTime = lastModified
+		}
+		result = qc.uploadStream(ctx, fetch, resp.ContentLength, namedPath, report, &o)
+	} else {
+		// 长度未知：预上传需要文件大小，先拉取到临时文件，期间每隔 URL_FETCH_PROGRESS_INTERVAL 报告一次拉取进度
+		if progressCallback != nil {
+			fetch.onRead = func(n int64) {
+				progressCallback(&UploadProgress{Fetched: n, Elapsed: time.Since(startTime)})
+			}
+		}
+		result, err = qc.UploadFromReader(fetch, namedPath, report, &o)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if !result.Success && ctx.Err() != nil {
+		return urlUploadInterrupted(fetch.n.Load()), nil
+	}
+	if result.Code == "READ_FILE_ERROR" {
+		// 读取的输入就是源站响应
+		result.Code = "FETCH_ERROR"
+		result.Message = fmt.Sprintf("failed to fetch %s: %s", u.Redacted(), result.Message)
+	}
+	if namedPath != destPath {
+		result, _ = withSanitizedName(result, nil, destPath, namedPath)
+	}
+	if result.Data == nil {
+		result.Data = make(map[string]interface{})
+	}
+	if _, ok := result.Data["path"]; !ok && result.Success {
+		result.Data["path"] = namedPath
+	}
+	result.Data["source_url"] = u.Redacted()
+	result.Data["fetched"] = fetch.n.Load()
+	result.Data["streamed"] = streamed
+	return result, nil
+}
+
+// urlUploadInterrupted 从 URL 上传被取消时的结果，流式上传没有可续传的状态
+func urlUploadInterrupted(fetched int64) *StandardResponse {
+	return &StandardResponse{
+		Success: false,
+		Code:    "INTERRUPTED",
+		Message: "上传已中断（从 URL 拉取的上传无法断点续传，需要重新执行）",
+		Data:    map[string]interface{}{"fetched": fetched},
+	}
+}
+
+// urlFileName 返回源站响应对应的文件名：优先 Content-Disposition 的 filename，其次（重定向后的）URL 路径的最后一段，都没有时为空
+func urlFileName(resp *http.Response) string {
+	if _, params, err := mime.ParseMediaType(resp.Header.Get("Content-Disposition")); err == nil {
+		if name := path.Base(params["filename"]); params["filename"] != "" && name != "/" && name != "." {
+			return normalizeNFC(name)
+		}
+	}
+	if resp.Request == nil || resp.Request.URL == nil {
+		return ""
+	}
+	name := path.Base(resp.Request.URL.Path)
+	if name == "/" || name == "." {
+		return ""
+	}
+	return normalizeNFC(name)
+}
+
+// fetchReader 统计从源站读取的字节数；onRead 非 nil 时最多每隔 URL_FETCH_PROGRESS_INTERVAL 以累计字节数回调一次
+type fetchReader struct {
+	r          io.Reader
+	n          atomic.Int64 // 进度回调可能在其它 goroutine 中读取
+	onRead     func(int64)
+	lastReport time.Time
+}
+
+func (f *fetchReader) Read(p []byte) (int, error) {
+	n, err := f.r.Read(p)
+	total := f.n.Add(int64(n))
+	if f.onRead != nil && time.Since(f.lastReport) >= URL_FETCH_PROGRESS_INTERVAL {
+		f.lastReport = time.Now()
+		f.onRead(total)
+	}
+	return n, err
+}
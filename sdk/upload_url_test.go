@@ -0,0 +1,96 @@
+package sdk
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestUploadFromURL(t *testing.T) {
+	const partSize = 1024
+	content := bytes.Repeat([]byte("0123456789abcdef"), 2*partSize/16+40) // 3 个分片，最后一片不满
+
+	tests := []struct {
+		name         string
+		partThread   int
+		length       int64 // 响应的 Content-Length，-1 表示未知
+		body         []byte
+		wantCode     string
+		wantStreamed bool
+	}{
+		{name: "streamed sequentially", partThread: 1, length: int64(len(content)), body: content, wantCode: "OK", wantStreamed: true},
+		{name: "streamed in parallel", partThread: 3, length: int64(len(content)), body: content, wantCode: "OK", wantStreamed: true},
+		{name: "unknown length spools to a temp file", partThread: 1, length: -1, body: content, wantCode: "OK", wantStreamed: false},
+		{name: "source ends early", partThread: 1, length: int64(len(content)), body: content[:partSize+10], wantCode: "FETCH_ERROR"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			isolateUploadState(t)
+			server := &fakeUploadServer{partSize: partSize, partThread: tt.partThread}
+			serve := server.roundTrip(t)
+			client := createMockClient(t, func(req *http.Request) (*http.Response, error) {
+				if req.URL.Host != "example.com" {
+					return serve(req)
+				}
+				if req.Header.Get("Cookie") != "" {
+					t.Errorf("source request sent cookies %q", req.Header.Get("Cookie"))
+				}
+				header := make(http.Header)
+				header.Set("Last-Modified", "Mon, 02 Jan 2006 15:04:05 GMT")
+				// 每次 Read 只返回一半数据，模拟网络读取的短读
+				return &http.Response{StatusCode: 200, Header: header, ContentLength: tt.length,
+					Body: io.NopCloser(iotest.HalfReader(bytes.NewReader(tt.body))), Request: req}, nil
+			})
+
+			var last UploadProgress
+			resp, err := client.UploadFromURL("https://example.com/files/big.bin?sig=1", "/", func(p *UploadProgress) { last = *p }, nil)
+			if err != nil || resp.Code != tt.wantCode {
+				t.Fatalf("UploadFromURL() = %+v, %v, want %s", resp, err, tt.wantCode)
+			}
+			if !resp.Success {
+				if server.committed != "" {
+					t.Errorf("failed upload committed %s", server.committed)
+				}
+				return
+			}
+			var uploaded []byte
+			for pn := 1; pn <= 3; pn++ {
+				uploaded = append(uploaded, server.parts[pn]...)
+			}
+			if !bytes.Equal(uploaded, content) || !strings.Contains(server.committed, "<PartNumber>3</PartNumber>") {
+				t.Errorf("uploaded %d bytes, commit %s", len(uploaded), server.committed)
+			}
+			if resp.Data["streamed"] != tt.wantStreamed || resp.Data["fetched"] != int64(len(content)) || resp.Data["path"] != "/big.bin" {
+				t.Errorf("Data = %v, want streamed %v, fetched %d, path /big.bin", resp.Data, tt.wantStreamed, len(content))
+			}
+			if last.Fetched != int64(len(content)) || last.Uploaded != int64(len(content)) {
+				t.Errorf("last progress = %+v, want everything fetched and uploaded", last)
+			}
+		})
+	}
+}
+
+func TestUploadFromURL_Errors(t *testing.T) {
+	client := createMockClient(t, func(req *http.Request) (*http.Response, error) {
+		if req.URL.Host == "example.com" {
+			return &http.Response{StatusCode: 404, Header: make(http.Header), Body: io.NopCloser(strings.NewReader("")), Request: req}, nil
+		}
+		t.Errorf("unexpected request %s %s", req.Method, req.URL)
+		return jsonResponse(req, `{"status":404,"code":1}`), nil
+	})
+	tests := []struct {
+		url, dest, wantCode string
+	}{
+		{"ftp://example.com/a.bin", "/a.bin", "INVALID_ARGS"},
+		{"https://example.com/missing.bin", "/a.bin", "FETCH_ERROR"},
+	}
+	for _, tt := range tests {
+		resp, err := client.UploadFromURL(tt.url, tt.dest, nil, nil)
+		if err != nil || resp.Success || resp.Code != tt.wantCode {
+			t.Errorf("UploadFromURL(%s) = %+v, %v, want %s", tt.url, resp, err, tt.wantCode)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package sdk
+
+import (
+	"fmt"
+)
+
+// verifyUpload 上传完成后校验远端文件（UploadOptions.Verify）：按路径重新查询文件信息比对大小，
+// 下载接口能返回 md5/sha1 时再比对哈希（拿不到哈希时只比对大小）；通过时返回 nil 和是否比对了哈希，
+// 文件缺失、大小或哈希不一致时返回 VERIFY_FAILED 响应，Data 中带本地和远端的大小、哈希
+func (qc *QuarkClient) verifyUpload(remotePath string, size int64, md5Sum, sha1Sum string) (bool, *StandardResponse) {
+	failed := func(message string, data map[string]interface{}) *StandardResponse {
+		data["path"] = remotePath
+		data["size"] = size
+		data["md5"] = md5Sum
+		data["sha1"] = sha1Sum
+		return &StandardResponse{
+			Success: false,
+			Code:    "VERIFY_FAILED",
+			Message: fmt.Sprintf("上传后校验失败: %s: %s", remotePath, message),
+			Data:    data,
+		}
+	}
+
+	// 路径缓存可能还是上传前的结果，先失效再查询
+	qc.InvalidatePathCache(remotePath)
+	info, err := qc.GetFileInfo(remotePath)
+	if err != nil {
+		return false, failed(fmt.Sprintf("query remote file: %v", err), map[string]interface{}{})
+	}
+	if !info.Success {
+		return false, failed(fmt.Sprintf("remote file not found (%s)", info.Code), map[string]interface{}{})
+	}
+	if isDir, _ := info.Data["dir"].(bool); isDir {
+		return false, failed("remote path is a directory", map[string]interface{}{})
+	}
+	remoteSize, _ := fileInfoSize(info.Data)
+	if remoteSize != size {
+		return false, failed(fmt.Sprintf("size mismatch: local %d bytes, remote %d bytes", size, remoteSize),
+			map[string]interface{}{"remote_size": remoteSize})
+	}
+
+	fid, _ := info.Data["fid"].(string)
+	if fid == "" {
+		return false, nil
+	}
+	remoteMD5, remoteSHA1, err := qc.GetFileHash(fid)
+	if err != nil {
+		// 拿不到哈希（接口不返回、文件超过下载大小限制等）时只校验大小
+		return false, nil
+	}
+	if (remoteMD5 != "" && remoteMD5 != md5Sum) || (remoteSHA1 != "" && remoteSHA1 != sha1Sum) {
+		return false, failed("hash mismatch",
+			map[string]interface{}{"remote_size": remoteSize, "remote_md5": remoteMD5, "remote_sha1": remoteSHA1})
+	}
+	return true, nil
+}
+
+// verifyUploadResult 在 verify 为 true 且上传成功时重新查询远端文件比对大小和哈希，通过时在 Data 中记下 verified、hash_verified，
+// 不一致时返回 VERIFY_FAILED
+func (qc *QuarkClient) verifyUploadResult(resp *StandardResponse, destPath string, size int64, md5Sum, sha1Sum string, verify bool) *StandardResponse {
+	if !verify || !resp.Success {
+		return resp
+	}
+	hashVerified, failed := qc.verifyUpload(destPath, size, md5Sum, sha1Sum)
+	if failed != nil {
+		return failed
+	}
+	resp.Data["verified"], resp.Data["hash_verified"] = true, hashVerified
+	return resp
+}
@@ -0,0 +1,79 @@
+package sdk
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestUploadFile_Verify(t *testing.T) {
+	content := []byte("verify me after upload")
+	localPath := filepath.Join(t.TempDir(), "v.bin")
+	if err := os.WriteFile(localPath, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+	md5Sum := md5.Sum(content)
+	sha1Sum := sha1.Sum(content)
+	goodMD5, goodSHA1 := hex.EncodeToString(md5Sum[:]), hex.EncodeToString(sha1Sum[:])
+
+	tests := []struct {
+		name          string
+		remote        map[string]interface{} // nil 表示远端查不到文件
+		md5           string
+		wantCode      string
+		wantHash      bool
+		wantKeepState bool
+	}{
+		{name: "size and hash match", remote: map[string]interface{}{"fid": "new", "size": len(content)}, md5: goodMD5, wantCode: "OK", wantHash: true},
+		{name: "hash unavailable", remote: map[string]interface{}{"fid": "new", "size": len(content)}, wantCode: "OK"},
+		{name: "size mismatch", remote: map[string]interface{}{"fid": "new", "size": 0}, wantCode: "VERIFY_FAILED", wantKeepState: true},
+		{name: "hash mismatch", remote: map[string]interface{}{"fid": "new", "size": len(content)}, md5: "00000000000000000000000000000000", wantCode: "VERIFY_FAILED", wantKeepState: true},
+		{name: "missing", wantCode: "VERIFY_FAILED", wantKeepState: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			isolateUploadState(t)
+			files := map[string]map[string]interface{}{}
+			if tt.remote != nil {
+				files["/v.bin"] = tt.remote
+			}
+			infoFn, _ := fakeFileInfoServer(files)
+			server := &fakeUploadServer{partSize: 1024}
+			uploadFn := server.roundTrip(t)
+			client := createMockClient(t, func(req *http.Request) (*http.Response, error) {
+				switch req.URL.Path {
+				case FILE_INFO_PATH_LIST, FILE_INFO, FILE_SORT:
+					return infoFn(req)
+				case FILE_DOWNLOAD:
+					item := map[string]interface{}{"fid": "new", "download_url": "http://dl.example.com/v.bin"}
+					if tt.md5 != "" {
+						item["md5"], item["sha1"] = tt.md5, goodSHA1
+					}
+					data, _ := json.Marshal(map[string]interface{}{"status": 200, "code": 0, "data": []interface{}{item}})
+					return jsonResponse(req, string(data)), nil
+				}
+				return uploadFn(req)
+			})
+
+			resp, err := client.UploadFile(localPath, "/v.bin", nil, &UploadOptions{Verify: true})
+			if err != nil {
+				t.Fatalf("UploadFile() error = %v", err)
+			}
+			if resp.Code != tt.wantCode {
+				t.Fatalf("UploadFile() = %+v, want code %s", resp, tt.wantCode)
+			}
+			if resp.Success && (resp.Data["verified"] != true || resp.Data["hash_verified"] != tt.wantHash) {
+				t.Errorf("Data = %v, want verified with hash_verified=%v", resp.Data, tt.wantHash)
+			}
+			_, statErr := os.Stat(client.uploadStatePath(localPath, "/v.bin"))
+			if keep := statErr == nil; keep != tt.wantKeepState {
+				t.Errorf("upload state kept = %v, want %v", keep, tt.wantKeepState)
+			}
+		})
+	}
+}
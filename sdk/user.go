@@ -9,8 +9,8 @@ import (
 // 先调用 /account/info 获取昵称头像，再调用 /1/clouddrive/member 获取容量和会员信息，
 // 两者合并后返回。
 func (qc *QuarkClient) GetUserInfo() (*StandardResponse, error) {
-	// 构建完整 URL（使用 PAN_DOMAIN，不是 baseURL）
-	reqURL := PAN_DOMAIN + USER_INFO
+	// 构建完整 URL（使用 panDomain，不是 baseURL）
+	reqURL := qc.panDomain + USER_INFO
 
 	// 解析 URL 并添加查询参数
 	parsedURL, err := url.Parse(reqURL)
@@ -86,10 +86,10 @@ func (qc *QuarkClient) GetUserInfo() (*StandardResponse, error) {
 }
 
 // getMemberInfo 获取会员和容量信息
-// 调用 DRIVE_DOMAIN + MEMBER_INFO（/1/clouddrive/member）
+// 调用 baseURL（DRIVE_DOMAIN）+ MEMBER_INFO（/1/clouddrive/member）
 // 返回包含 use_capacity、total_capacity、member_type 等字段的 data map
 func (qc *QuarkClient) getMemberInfo() (map[string]interface{}, error) {
-	reqURL := DRIVE_DOMAIN + MEMBER_INFO
+	reqURL := qc.baseURL + MEMBER_INFO
 
 	parsedURL, err := url.Parse(reqURL)
 	if err != nil {